@@ -22,12 +22,18 @@ import (
 	"github.com/nextlevelbuilder/goclaw/internal/channelmemory"
 	"github.com/nextlevelbuilder/goclaw/internal/channels"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/bitrix24"
+	bridgechannel "github.com/nextlevelbuilder/goclaw/internal/channels/bridge"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/discord"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/email"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/facebook"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/feishu"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/matrix"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/pancake"
+	signalchannel "github.com/nextlevelbuilder/goclaw/internal/channels/signal"
 	slackchannel "github.com/nextlevelbuilder/goclaw/internal/channels/slack"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/teams"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/telegram"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/twilio"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/whatsapp"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/zalo"
 	zalopersonal "github.com/nextlevelbuilder/goclaw/internal/channels/zalo/personal"
@@ -226,8 +232,11 @@ func runGateway() {
 			slog.Info("system_configs applied to in-memory config", "keys", len(sysConfigs))
 		}
 	}
-	setupMemoryEmbeddings(pgStores, providerRegistry)
+	embProvider := setupMemoryEmbeddings(pgStores, providerRegistry)
 	usageCapSvc := usagecaps.NewService(pgStores.UsageCaps, pgStores.Providers)
+	if stopBatch := startBackgroundBatchQueue(cfg, providerRegistry, usageCapSvc); stopBatch != nil {
+		defer stopBatch()
+	}
 
 	// Resolve background provider for consolidation + vault enrichment.
 	// Fallback: background.provider → agent.default_provider → first registered provider.
@@ -240,6 +249,7 @@ func runGateway() {
 			if pgStores.KnowledgeGraph != nil {
 				kgExtractor = kg.NewExtractor(bgProvider, bgModel, 0)
 				kgExtractor.SetUsageCapService(usageCapSvc)
+				kgExtractor.SetBatch(true)
 			}
 			cleanupConsolidation := consolidation.Register(consolidation.ConsolidationDeps{
 				EpisodicStore: pgStores.Episodic,
@@ -357,7 +367,7 @@ func runGateway() {
 	var mcpPool *mcpbridge.Pool
 	var mediaStore *media.Store
 	var postTurn tools.PostTurnProcessor
-	contextFileInterceptor, mcpPool, mediaStore, postTurn = wireExtras(pgStores, agentRouter, providerRegistry, modelReg, msgBus, pgStores.Sessions, toolsReg, toolPE, skillsLoader, hasMemory, traceCollector, workspace, cfg.Gateway.InjectionAction, cfg, sandboxMgr, redisClient, domainBus, usageCapSvc)
+	contextFileInterceptor, mcpPool, mediaStore, postTurn = wireExtras(pgStores, agentRouter, providerRegistry, modelReg, msgBus, pgStores.Sessions, toolsReg, toolPE, skillsLoader, hasMemory, traceCollector, workspace, cfg.Gateway.InjectionAction, cfg, sandboxMgr, redisClient, embProvider, domainBus, usageCapSvc)
 	if mcpPool != nil {
 		defer mcpPool.Stop()
 	}
@@ -371,6 +381,7 @@ func runGateway() {
 		providerRegistry: providerRegistry,
 		agentRouter:      agentRouter,
 		toolsReg:         toolsReg,
+		execApprovalMgr:  execApprovalMgr,
 		skillsLoader:     skillsLoader,
 		enrichProgress:   enrichProgress,
 		enrichWorker:     enrichWorker,
@@ -402,6 +413,10 @@ func runGateway() {
 		agentsH.SetPreviewStores(pgStores.Teams, pgStores.AgentLinks, skillAccess)
 	}
 
+	if tracesH != nil {
+		tracesH.SetReplay(agentRouter, providers.NewRecordingArchive(recordingsDir(dataDir)))
+	}
+
 	// External wake/trigger API
 	wakeH := httpapi.NewWakeHandler(agentRouter)
 	if postTurn != nil {
@@ -511,6 +526,10 @@ func runGateway() {
 		channelInstancesH.SetChannelManager(channelMgr)
 	}
 
+	if pgStores.SentMessages != nil {
+		channelMgr.SetSentMessageStore(pgStores.SentMessages)
+	}
+
 	// Wire channel sender + tenant checker on message tool (now that channelMgr exists)
 	if t, ok := toolsReg.Get("message"); ok {
 		if cs, ok := t.(tools.ChannelSenderAware); ok {
@@ -519,6 +538,14 @@ func runGateway() {
 		if tc, ok := t.(tools.ChannelTenantCheckerAware); ok {
 			tc.SetChannelTenantChecker(channelMgr.ChannelTenantID)
 		}
+		if me, ok := t.(tools.MessageEditorAware); ok && pgStores.SentMessages != nil {
+			me.SetMessageEditor(channelMgr)
+		}
+		if dr, ok := t.(tools.DMSessionKeyResolverAware); ok {
+			dr.SetDMSessionKeyResolver(func(agentKey, channel, chatID, userID string) string {
+				return inboundDMSessionKey(cfg, agentKey, channel, chatID, userID)
+			})
+		}
 	}
 	// Wire group member lister on list_group_members tool
 	if t, ok := toolsReg.Get("list_group_members"); ok {
@@ -543,6 +570,13 @@ func runGateway() {
 		instanceLoader.RegisterFactory(channels.TypeSlack, slackchannel.FactoryWithPendingStore(pgStores.PendingMessages))
 		instanceLoader.RegisterFactory(channels.TypeFacebook, facebook.Factory)
 		instanceLoader.RegisterFactory(channels.TypePancake, pancake.Factory)
+		instanceLoader.RegisterFactory(channels.TypeMatrix, matrix.FactoryWithPendingStore(pgStores.PendingMessages))
+		instanceLoader.RegisterFactory(channels.TypeSignal, signalchannel.FactoryWithPendingStore(pgStores.PendingMessages))
+		instanceLoader.RegisterFactory(channels.TypeEmail, email.Factory)
+		instanceLoader.RegisterFactory(channels.TypeTeams, teams.FactoryWithPendingStore(pgStores.PendingMessages))
+		instanceLoader.RegisterFactory(channels.TypeBridge, bridgechannel.FactoryWithPendingStore(pgStores.PendingMessages))
+		bridgechannel.SetAuthenticator(bridgePluginAuth)
+		instanceLoader.RegisterFactory(channels.TypeTwilio, twilio.FactoryWithAudio(audioMgr))
 		// Bitrix24: factory needs the portal store + encKey injected so each
 		// Channel can resolve its portal on Start(). The encKey here mirrors
 		// the one used by pg.NewPGStores → NewPGBitrixPortalStore.
@@ -759,3 +793,28 @@ func resolveBackgroundProvider(cfg *config.Config, reg *providers.Registry) (pro
 	}
 	return nil, ""
 }
+
+// startBackgroundBatchQueue enables batch API routing for background workers
+// when gateway.background_batch.enabled is set. Submitted batches are recorded
+// under the data dir so a restart resumes them. Returns the stop func, or nil.
+func startBackgroundBatchQueue(cfg *config.Config, reg *providers.Registry, usageCapSvc *usagecaps.Service) func() {
+	bb := cfg.Gateway.BackgroundBatch
+	if bb == nil || !bb.Enabled {
+		return nil
+	}
+	if usageCapSvc == nil {
+		slog.Warn("background batch mode skipped: usage cap service unavailable")
+		return nil
+	}
+	q := providers.NewBatchQueue(providers.BatchQueueConfig{
+		FlushInterval: time.Duration(bb.FlushIntervalSec) * time.Second,
+		PollInterval:  time.Duration(bb.PollIntervalSec) * time.Second,
+		MaxWait:       time.Duration(bb.MaxWaitMin) * time.Minute,
+		StatePath:     filepath.Join(cfg.ResolvedDataDir(), "batch", "inflight.json"),
+		Registry:      reg,
+	})
+	q.Start(context.Background())
+	usageCapSvc.SetBatchQueue(q)
+	slog.Info("background batch mode enabled", "max_wait", q.MaxWait())
+	return q.Stop
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+	"github.com/nextlevelbuilder/goclaw/internal/tools"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+// wireExecApprovalNotifier posts Approve/Deny buttons for exec approval
+// requests to the chat whose run asked, when that channel renders buttons.
+// Requests from the web UI or button-less channels stay dashboard-only.
+// "Always allow" is not offered: it adds the binary to the gateway-wide
+// allowlist, which stays an operator decision (exec.approval.approve).
+func wireExecApprovalNotifier(mgr *tools.ExecApprovalManager, msgBus *bus.MessageBus, channelMgr *channels.Manager) {
+	if mgr == nil || channelMgr == nil {
+		return
+	}
+	mgr.SetNotifier(func(pa *tools.PendingApproval) {
+		o := pa.Origin
+		if o.Channel == "" || o.ChatID == "" || !channelMgr.SupportsActions(o.Channel) {
+			return
+		}
+		prefix := bus.ActionPrefixExec
+		msgBus.PublishOutbound(bus.OutboundMessage{
+			Channel: o.Channel,
+			ChatID:  o.ChatID,
+			Content: fmt.Sprintf("🔐 Approval needed to run:\n%s", pa.Command),
+			Actions: []bus.MessageAction{
+				{ID: prefix + string(tools.ApprovalAllowOnce) + ":" + pa.ID, Label: "Approve", Style: bus.ActionStylePrimary},
+				{ID: prefix + string(tools.ApprovalDeny) + ":" + pa.ID, Label: "Deny", Style: bus.ActionStyleDanger},
+			},
+			Metadata: buildAnnounceOutMeta(o.LocalKey),
+			TenantID: o.TenantID,
+		})
+	})
+}
+
+// wireTaskReviewActions posts Approve/Reject buttons to the originating chat
+// when a team task is submitted for review, so it can be decided without the
+// dashboard. Only channels that render buttons are notified.
+func (d *gatewayDeps) wireTaskReviewActions() {
+	d.msgBus.Subscribe("consumer.team-review-actions", func(evt bus.Event) {
+		if evt.Name != protocol.EventTeamTaskReviewed {
+			return
+		}
+		payload, ok := evt.Payload.(protocol.TeamTaskEventPayload)
+		if !ok || payload.TaskID == "" || payload.Channel == "" || payload.ChatID == "" {
+			return
+		}
+		if payload.Channel == tools.ChannelSystem || payload.Channel == tools.ChannelTeammate {
+			return
+		}
+		if !d.channelMgr.SupportsActions(payload.Channel) {
+			return
+		}
+		d.msgBus.PublishOutbound(bus.OutboundMessage{
+			Channel: payload.Channel,
+			ChatID:  payload.ChatID,
+			Content: fmt.Sprintf("🔎 Task #%d \"%s\" is ready for review", payload.TaskNumber, payload.Subject),
+			Actions: []bus.MessageAction{
+				{ID: bus.ActionPrefixTask + "approve:" + payload.TaskID, Label: "Approve", Style: bus.ActionStylePrimary},
+				{ID: bus.ActionPrefixTask + "reject:" + payload.TaskID, Label: "Reject", Style: bus.ActionStyleDanger},
+			},
+			Metadata: buildAnnounceOutMeta(payload.LocalKey),
+		})
+	})
+}
+
+// handleActionCallback resolves clicks on gateway-issued buttons (exec and
+// task approvals). Other clicks fall through to the agent as a normal message.
+// Returns true if the message was handled (caller should continue).
+func handleActionCallback(ctx context.Context, msg bus.InboundMessage, deps *ConsumerDeps) bool {
+	if msg.Action == nil || !bus.IsReservedActionID(msg.Action.ActionID) {
+		return false
+	}
+
+	var feedback string
+	if rest, ok := strings.CutPrefix(msg.Action.ActionID, bus.ActionPrefixExec); ok {
+		feedback = resolveExecApprovalAction(msg, rest, deps.ExecApprovals, deps.Cfg)
+	} else if rest, ok := strings.CutPrefix(msg.Action.ActionID, bus.ActionPrefixTask); ok {
+		feedback = resolveTaskApprovalAction(ctx, msg, rest, deps)
+	}
+	if feedback != "" {
+		deps.MsgBus.PublishOutbound(bus.OutboundMessage{
+			Channel:  msg.Channel,
+			ChatID:   msg.ChatID,
+			Content:  feedback,
+			Metadata: msg.Metadata,
+			TenantID: msg.TenantID,
+		})
+	}
+	return true
+}
+
+// resolveExecApprovalAction handles "<decision>:<approval id>". Only the user
+// whose message started the run may answer, and only from the same chat and
+// tenant. allow-always widens the gateway-wide allowlist, so only a gateway
+// owner may send it.
+func resolveExecApprovalAction(msg bus.InboundMessage, rest string, mgr *tools.ExecApprovalManager, cfg *config.Config) string {
+	decision, id, ok := strings.Cut(rest, ":")
+	if !ok || mgr == nil {
+		return ""
+	}
+	switch tools.ApprovalDecision(decision) {
+	case tools.ApprovalAllowOnce, tools.ApprovalAllowAlways, tools.ApprovalDeny:
+	default:
+		return ""
+	}
+
+	pa, ok := mgr.Get(id)
+	if !ok {
+		return "This approval request has already been resolved or expired."
+	}
+	o := pa.Origin
+	if o.TenantID != msg.TenantID || o.Channel != msg.Channel || o.ChatID != msg.ChatID || senderKey(o.SenderID) != senderKey(msg.SenderID) {
+		slog.Warn("security.exec_approval_action_rejected",
+			"id", id, "channel", msg.Channel, "chat_id", msg.ChatID, "sender_id", msg.SenderID)
+		return "Only the user who started this run can answer this approval."
+	}
+	if tools.ApprovalDecision(decision) == tools.ApprovalAllowAlways && !isGatewayOwner(msg, cfg) {
+		slog.Warn("security.exec_approval_allow_always_rejected",
+			"id", id, "channel", msg.Channel, "chat_id", msg.ChatID, "sender_id", msg.SenderID)
+		return "Only a gateway owner can always allow a command. Approve it once or ask an operator."
+	}
+	if err := mgr.Resolve(id, tools.ApprovalDecision(decision)); err != nil {
+		return "This approval request has already been resolved or expired."
+	}
+	slog.Info("exec approval resolved from channel",
+		"id", id, "decision", decision, "channel", msg.Channel, "sender_id", msg.SenderID)
+	if tools.ApprovalDecision(decision) == tools.ApprovalDeny {
+		return "❌ Command denied."
+	}
+	return "✅ Command approved."
+}
+
+// resolveTaskApprovalAction handles "approve:<task id>" and "reject:<task id>"
+// for tasks in review that were created from the same chat. Only the task's
+// creator or a gateway owner may decide; see canDecideTask.
+func resolveTaskApprovalAction(ctx context.Context, msg bus.InboundMessage, rest string, deps *ConsumerDeps) string {
+	verb, rawID, ok := strings.Cut(rest, ":")
+	if !ok || (verb != "approve" && verb != "reject") || deps.TeamStore == nil {
+		return ""
+	}
+	taskID, err := uuid.Parse(rawID)
+	if err != nil {
+		return ""
+	}
+
+	if msg.TenantID != uuid.Nil {
+		ctx = store.WithTenantID(ctx, msg.TenantID)
+	}
+	task, err := deps.TeamStore.GetTask(ctx, taskID)
+	if err != nil || task == nil || task.Channel != msg.Channel || task.ChatID != msg.ChatID {
+		return "Task not found."
+	}
+	if !canDecideTask(task, msg, deps.Cfg) {
+		slog.Warn("security.task_approval_action_rejected",
+			"task_id", task.ID, "channel", msg.Channel, "chat_id", msg.ChatID, "sender_id", msg.SenderID)
+		return "Only the user who created this task can approve or reject it."
+	}
+	if task.Status != store.TeamTaskStatusInReview {
+		return fmt.Sprintf("Task #%d is no longer awaiting review.", task.TaskNumber)
+	}
+
+	payload := protocol.TeamTaskEventPayload{
+		TeamID:     task.TeamID.String(),
+		TaskID:     task.ID.String(),
+		TaskNumber: task.TaskNumber,
+		Subject:    task.Subject,
+		UserID:     msg.UserID,
+		Channel:    msg.Channel,
+		ChatID:     msg.ChatID,
+		PeerKind:   msg.PeerKind,
+		Timestamp:  time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		ActorType:  "human",
+		ActorID:    msg.SenderID,
+	}
+	if verb == "approve" {
+		if err := deps.TeamStore.ApproveTask(ctx, task.ID, task.TeamID, ""); err != nil {
+			slog.Warn("team task approve from channel failed", "task_id", task.ID, "error", err)
+			return "Failed to approve the task."
+		}
+		payload.Status = store.TeamTaskStatusCompleted
+		deps.MsgBus.Broadcast(bus.Event{Name: protocol.EventTeamTaskApproved, Payload: payload})
+		return fmt.Sprintf("✅ Task #%d approved.", task.TaskNumber)
+	}
+
+	reason := "Rejected from " + msg.Channel
+	if err := deps.TeamStore.RejectTask(ctx, task.ID, task.TeamID, reason); err != nil {
+		slog.Warn("team task reject from channel failed", "task_id", task.ID, "error", err)
+		return "Failed to reject the task."
+	}
+	payload.Status = store.TeamTaskStatusCancelled
+	payload.Reason = reason
+	deps.MsgBus.Broadcast(bus.Event{Name: protocol.EventTeamTaskRejected, Payload: payload})
+	return fmt.Sprintf("❌ Task #%d rejected.", task.TaskNumber)
+}
+
+// canDecideTask mirrors the write-role check of teams.tasks.approve for chat
+// clicks: the sender who created the task, the task's user when it is a
+// person rather than a group scope, or a configured gateway owner.
+func canDecideTask(task *store.TeamTaskData, msg bus.InboundMessage, cfg *config.Config) bool {
+	sender := senderKey(msg.SenderID)
+	if sender == "" || bus.IsInternalSender(sender) {
+		return false
+	}
+	if creator, _ := task.Metadata[tools.MetaOriginSenderID].(string); creator != "" && senderKey(creator) == sender {
+		return true
+	}
+	if uid := task.UserID; uid != "" && !strings.HasPrefix(uid, "group:") && !strings.HasPrefix(uid, "guild:") {
+		if uid == sender || (msg.UserID != "" && uid == msg.UserID) {
+			return true
+		}
+	}
+	return isGatewayOwner(msg, cfg)
+}
+
+// isGatewayOwner reports whether the click's sender is a gateway.owner_ids entry.
+func isGatewayOwner(msg bus.InboundMessage, cfg *config.Config) bool {
+	sender := senderKey(msg.SenderID)
+	if cfg == nil || sender == "" || bus.IsInternalSender(sender) {
+		return false
+	}
+	for _, id := range cfg.Gateway.OwnerIDs {
+		if id != "" && (id == sender || id == msg.UserID) {
+			return true
+		}
+	}
+	return false
+}
+
+// senderKey strips the "|username" suffix some channels append to sender IDs.
+func senderKey(senderID string) string {
+	id, _, _ := strings.Cut(senderID, "|")
+	return id
+}
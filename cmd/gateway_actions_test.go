@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+	"github.com/nextlevelbuilder/goclaw/internal/tools"
+)
+
+// requestExecApproval starts an approval from a run bound to telegram/chat-1
+// by user 42 and returns the pending request and the eventual decision.
+func requestExecApproval(t *testing.T, mgr *tools.ExecApprovalManager) (*tools.PendingApproval, <-chan tools.ApprovalDecision) {
+	t.Helper()
+	notified := make(chan *tools.PendingApproval, 1)
+	mgr.SetNotifier(func(pa *tools.PendingApproval) { notified <- pa })
+
+	ctx := tools.WithToolChannel(context.Background(), "telegram")
+	ctx = tools.WithToolChatID(ctx, "chat-1")
+	ctx = store.WithSenderID(ctx, "42")
+	decided := make(chan tools.ApprovalDecision, 1)
+	go func() {
+		d, _ := mgr.RequestApproval(ctx, "rm -rf build", "agent-1", 5*time.Second)
+		decided <- d
+	}()
+	select {
+	case pa := <-notified:
+		if pa.Origin.Channel != "telegram" || pa.Origin.ChatID != "chat-1" || pa.Origin.SenderID != "42" {
+			t.Fatalf("origin = %+v", pa.Origin)
+		}
+		return pa, decided
+	case <-time.After(time.Second):
+		t.Fatal("notifier not called")
+		return nil, nil
+	}
+}
+
+func actionClick(senderID, actionID string) bus.InboundMessage {
+	return bus.InboundMessage{
+		Channel:  "telegram",
+		ChatID:   "chat-1",
+		SenderID: senderID,
+		Action:   &bus.ActionCallback{ActionID: actionID},
+	}
+}
+
+func TestHandleActionCallback_ExecApproval(t *testing.T) {
+	mb := bus.New()
+	defer mb.Close()
+	mgr := tools.NewExecApprovalManager(tools.DefaultExecApprovalConfig())
+	deps := &ConsumerDeps{MsgBus: mb, ExecApprovals: mgr}
+	pa, decided := requestExecApproval(t, mgr)
+
+	// Another group member cannot answer.
+	if !handleActionCallback(context.Background(), actionClick("99", bus.ActionPrefixExec+"allow-once:"+pa.ID), deps) {
+		t.Fatal("expected reserved action to be handled")
+	}
+	if _, ok := mgr.Get(pa.ID); !ok {
+		t.Fatal("approval resolved by a different user")
+	}
+
+	if !handleActionCallback(context.Background(), actionClick("42|alice", bus.ActionPrefixExec+"deny:"+pa.ID), deps) {
+		t.Fatal("expected reserved action to be handled")
+	}
+	select {
+	case d := <-decided:
+		if d != tools.ApprovalDeny {
+			t.Fatalf("decision = %q, want deny", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("approval not resolved")
+	}
+}
+
+func TestHandleActionCallback_ExecApprovalAllowAlwaysOwnerOnly(t *testing.T) {
+	mb := bus.New()
+	defer mb.Close()
+	mgr := tools.NewExecApprovalManager(tools.DefaultExecApprovalConfig())
+	cfg := &config.Config{}
+	deps := &ConsumerDeps{MsgBus: mb, ExecApprovals: mgr, Cfg: cfg}
+	pa, decided := requestExecApproval(t, mgr)
+
+	// The run's own user cannot widen the gateway-wide allowlist.
+	handleActionCallback(context.Background(), actionClick("42", bus.ActionPrefixExec+"allow-always:"+pa.ID), deps)
+	if _, ok := mgr.Get(pa.ID); !ok {
+		t.Fatal("allow-always accepted from a non-owner")
+	}
+
+	// A click carrying another tenant is rejected.
+	other := actionClick("42", bus.ActionPrefixExec+"allow-once:"+pa.ID)
+	other.TenantID = uuid.New()
+	handleActionCallback(context.Background(), other, deps)
+	if _, ok := mgr.Get(pa.ID); !ok {
+		t.Fatal("approval resolved from another tenant")
+	}
+
+	cfg.Gateway.OwnerIDs = []string{"42"}
+	handleActionCallback(context.Background(), actionClick("42", bus.ActionPrefixExec+"allow-always:"+pa.ID), deps)
+	select {
+	case d := <-decided:
+		if d != tools.ApprovalAllowAlways {
+			t.Fatalf("decision = %q, want allow-always", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("approval not resolved")
+	}
+}
+
+func TestHandleActionCallback_AgentActionFallsThrough(t *testing.T) {
+	deps := &ConsumerDeps{MsgBus: bus.New()}
+	if handleActionCallback(context.Background(), actionClick("42", "option_b"), deps) {
+		t.Fatal("agent-defined action must reach the agent")
+	}
+	if handleActionCallback(context.Background(), bus.InboundMessage{Content: "hi"}, deps) {
+		t.Fatal("plain message must not be handled")
+	}
+}
+
+// reviewTaskStore serves one in-review task and records decisions on it.
+type reviewTaskStore struct {
+	store.TeamStore
+	task     *store.TeamTaskData
+	approved bool
+	rejected bool
+}
+
+func (s *reviewTaskStore) GetTask(_ context.Context, id uuid.UUID) (*store.TeamTaskData, error) {
+	if id != s.task.ID {
+		return nil, nil
+	}
+	return s.task, nil
+}
+
+func (s *reviewTaskStore) ApproveTask(context.Context, uuid.UUID, uuid.UUID, string) error {
+	s.approved = true
+	return nil
+}
+
+func (s *reviewTaskStore) RejectTask(context.Context, uuid.UUID, uuid.UUID, string) error {
+	s.rejected = true
+	return nil
+}
+
+func TestHandleActionCallback_TaskApprovalGroupChat(t *testing.T) {
+	mb := bus.New()
+	defer mb.Close()
+	ts := &reviewTaskStore{task: &store.TeamTaskData{
+		BaseModel: store.BaseModel{ID: uuid.New()},
+		TeamID:    uuid.New(),
+		UserID:    "group:telegram:chat-1",
+		Channel:   "telegram",
+		ChatID:    "chat-1",
+		Status:    store.TeamTaskStatusInReview,
+		Metadata:  map[string]any{tools.MetaOriginSenderID: "42|alice"},
+	}}
+	cfg := &config.Config{}
+	cfg.Gateway.OwnerIDs = []string{"7"}
+	deps := &ConsumerDeps{MsgBus: mb, TeamStore: ts, Cfg: cfg}
+	approve := bus.ActionPrefixTask + "approve:" + ts.task.ID.String()
+
+	// Another group member in the same chat cannot decide.
+	if !handleActionCallback(context.Background(), actionClick("99|mallory", approve), deps) {
+		t.Fatal("expected reserved action to be handled")
+	}
+	if ts.approved || ts.rejected {
+		t.Fatal("task decided by a member who did not create it")
+	}
+
+	// The creator can, even under a different username suffix.
+	handleActionCallback(context.Background(), actionClick("42", approve), deps)
+	if !ts.approved {
+		t.Fatal("creator's approval was not applied")
+	}
+
+	// A gateway owner can reject on anyone's behalf.
+	handleActionCallback(context.Background(), actionClick("7", bus.ActionPrefixTask+"reject:"+ts.task.ID.String()), deps)
+	if !ts.rejected {
+		t.Fatal("owner's rejection was not applied")
+	}
+}
@@ -3,6 +3,9 @@ package cmd
 import (
 	"context"
 	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/nextlevelbuilder/goclaw/internal/audio/elevenlabs"
 	geminiaudio "github.com/nextlevelbuilder/goclaw/internal/audio/gemini"
@@ -146,6 +149,15 @@ func buildEmbeddingProvider(
 				ep.WithDimensions(dims)
 				return ep
 			}
+			// Native Gemini chat still serves embeddings through the OpenAI-compat shim.
+			if gp, ok := regProv.(*providers.GeminiProvider); ok {
+				if apiBase == "" || apiBase == gp.APIBase() {
+					apiBase = gp.APIBase() + "/openai"
+				}
+				ep := memory.NewOpenAIEmbeddingProvider(dbp.Name, gp.APIKey(), apiBase, model)
+				ep.WithDimensions(dims)
+				return ep
+			}
 			slog.Debug("embedding provider in registry is not OpenAI-compatible, using DB record", "name", dbp.Name)
 		}
 	}
@@ -160,6 +172,47 @@ func buildEmbeddingProvider(
 	return nil
 }
 
+// rerankerCacheTTL bounds how long a tenant's resolved reranker is reused, so
+// rerank.* system config changes apply without a restart.
+const rerankerCacheTTL = time.Minute
+
+// resolveReranker selects the second-stage reranker for memory and vault
+// search from the tenant in ctx. Resolution order:
+//  1. system_configs "rerank.provider" → the tenant's DB provider whose API
+//     key calls a hosted rerank API ("rerank.model" and "rerank.api_base"
+//     optional; the API style is inferred from the endpoint or provider base URL)
+//  2. Local BM25 reranker (no network calls)
+//
+// Reranking itself stays off until an agent enables memory_config.rerank.
+func resolveReranker(ctx context.Context, providerStore store.ProviderStore, sysConfigs store.SystemConfigStore) store.Reranker {
+	if store.TenantIDFromContext(ctx) == uuid.Nil {
+		ctx = store.WithTenantID(ctx, store.MasterTenantID)
+	}
+	if sysConfigs != nil && providerStore != nil {
+		if name, err := sysConfigs.Get(ctx, "rerank.provider"); err == nil && name != "" {
+			tenantID := store.TenantIDFromContext(ctx)
+			model, _ := sysConfigs.Get(ctx, "rerank.model")
+			apiURL, _ := sysConfigs.Get(ctx, "rerank.api_base")
+			dbp, err := providerStore.GetProviderByName(ctx, name)
+			switch {
+			case err != nil:
+				slog.Warn("system_configs rerank.provider not found in DB", "name", name, "tenant_id", tenantID)
+			case !dbp.Enabled:
+				slog.Warn("rerank provider disabled", "name", name, "tenant_id", tenantID)
+			default:
+				styleHint := apiURL
+				if styleHint == "" {
+					styleHint = dbp.APIBase
+				}
+				rr := memory.NewHTTPReranker(dbp.Name, memory.RerankStyleForURL(styleHint), dbp.APIKey, apiURL, model)
+				slog.Debug("rerank provider from system_configs", "name", name, "model", rr.Model(), "tenant_id", tenantID)
+				return rr
+			}
+		}
+	}
+	return memory.NewBM25Reranker()
+}
+
 func setupSubagents(providerReg *providers.Registry, cfg *config.Config, msgBus *bus.MessageBus, toolsReg *tools.Registry, workspace string, sandboxMgr sandbox.Manager, secureCLIStore store.SecureCLIStore, usageCapSvc *usagecaps.Service) *tools.SubagentManager {
 	names := providerReg.List(context.Background())
 	if len(names) == 0 {
@@ -18,19 +18,28 @@ func builtinToolSeedData() []store.BuiltinToolDef {
 		{Name: "read_file", DisplayName: "Read File", Description: "Read the contents of a file from the agent's workspace by path", Category: "filesystem", Enabled: true},
 		{Name: "write_file", DisplayName: "Write File", Description: "Write content to a file in the workspace, creating directories as needed", Category: "filesystem", Enabled: true},
 		{Name: "list_files", DisplayName: "List Files", Description: "List files and directories in a given path within the workspace", Category: "filesystem", Enabled: true},
+		{Name: "search_files", DisplayName: "Search Files", Description: "Search workspace file contents by regex or find files by glob, ripgrep-style, honouring .gitignore and denied paths", Category: "filesystem", Enabled: true},
 		{Name: "edit", DisplayName: "Edit File", Description: "Apply targeted search-and-replace edits to existing files without rewriting the entire file", Category: "filesystem", Enabled: true},
+		{Name: "apply_patch", DisplayName: "Apply Patch", Description: "Apply unified diffs or *** Begin Patch blocks across multiple files atomically, with fuzzy hunk matching, dry runs and per-hunk conflict reports", Category: "filesystem", Enabled: true},
 
 		// runtime
 		{Name: "exec", DisplayName: "Execute Command", Description: "Execute a shell command in the workspace and return stdout/stderr", Category: "runtime", Enabled: true,
 			Settings: json.RawMessage(`{"timeout_seconds":60}`),
 			Metadata: json.RawMessage(`{"config_hint":"Config → Tools → Exec Approval"}`),
 		},
+		{Name: "code_interpreter", DisplayName: "Code Interpreter", Description: "Run Python or JavaScript cells in a persistent kernel inside the sandbox, saving plots and tables to the workspace", Category: "runtime", Enabled: true,
+			Settings: json.RawMessage(`{"timeout_seconds":60,"idle_timeout_minutes":30}`),
+		},
 		{Name: "wait", DisplayName: "Wait", Description: "Pause the current agent tool sequence for a bounded number of milliseconds", Category: "runtime", Enabled: true},
 
 		// web
 		{Name: "web_search", DisplayName: "Web Search", Description: "Search the web for information using a search engine (Brave or DuckDuckGo)", Category: "web", Enabled: true,
 			Metadata: json.RawMessage(`{"config_hint":"Config → Tools → Web Search"}`),
 		},
+		{Name: "http_request", DisplayName: "HTTP Request", Description: "Call REST APIs with any method, headers and JSON body, using credential-store auth profiles and pagination helpers", Category: "web", Enabled: true},
+		{Name: "sql_query", DisplayName: "SQL Query", Description: "Run read-only SQL against granted Postgres connections or workspace SQLite files, with schema introspection", Category: "data", Enabled: true,
+			Settings: json.RawMessage(`{"max_rows":200,"timeout_seconds":30}`),
+		},
 		{Name: "web_fetch", DisplayName: "Web Fetch", Description: "Fetch a web page or API endpoint and extract its text content", Category: "web", Enabled: true,
 			Settings: json.RawMessage(`{"extractors":[{"name":"defuddle","enabled":true,"base_url":"https://fetch.goclaw.sh/","max_retries":2},{"name":"html-to-markdown","enabled":true}]}`),
 		},
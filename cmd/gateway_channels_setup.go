@@ -14,6 +14,7 @@ import (
 	"github.com/nextlevelbuilder/goclaw/internal/channels"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/discord"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/feishu"
+	signalchannel "github.com/nextlevelbuilder/goclaw/internal/channels/signal"
 	slackchannel "github.com/nextlevelbuilder/goclaw/internal/channels/slack"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/telegram"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/whatsapp"
@@ -23,6 +24,8 @@ import (
 	"github.com/nextlevelbuilder/goclaw/internal/config"
 	"github.com/nextlevelbuilder/goclaw/internal/gateway"
 	"github.com/nextlevelbuilder/goclaw/internal/gateway/methods"
+	httpapi "github.com/nextlevelbuilder/goclaw/internal/http"
+	"github.com/nextlevelbuilder/goclaw/internal/permissions"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
 )
@@ -152,6 +155,7 @@ func wireChannelRPCMethods(server *gateway.Server, pgStores *store.Stores, chann
 	// Register channels RPC methods (after channelMgr is initialized with all channels)
 	methods.NewChannelsMethods(channelMgr).Register(server.Router())
 	methods.NewChatBehaviorMethods(cfg, channelMgr).Register(server.Router())
+	methods.NewMessagesMethods(channelMgr, msgBus).Register(server.Router())
 
 	// Register channel instances WS RPC methods
 	var chInstancesM *methods.ChannelInstancesMethods
@@ -161,6 +165,7 @@ func wireChannelRPCMethods(server *gateway.Server, pgStores *store.Stores, chann
 		zalomethods.NewQRMethods(pgStores.ChannelInstances, msgBus).Register(server.Router())
 		zalomethods.NewContactsMethods(pgStores.ChannelInstances).Register(server.Router())
 		whatsapp.NewQRMethods(pgStores.ChannelInstances, channelMgr, instanceLoader).Register(server.Router())
+		signalchannel.NewQRMethods(pgStores.ChannelInstances, msgBus).Register(server.Router())
 	}
 
 	// Register agent links WS RPC methods
@@ -291,3 +296,14 @@ func wireChannelEventSubscribers(
 		})
 	}
 }
+
+// bridgePluginAuth admits bridge channel plugins holding an API key with
+// operator access or above. The key's tenant limits which instances the
+// plugin may attach to (system keys: any).
+func bridgePluginAuth(ctx context.Context, token string) (uuid.UUID, bool) {
+	key, role := httpapi.ResolveAPIKey(ctx, token)
+	if key == nil || !permissions.HasMinRole(role, permissions.RoleOperator) {
+		return uuid.Nil, false
+	}
+	return key.TenantID, true
+}
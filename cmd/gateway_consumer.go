@@ -27,7 +27,7 @@ import (
 // and routes them through the scheduler/agent loop, then publishes the response back.
 // Also handles subagent announcements: routes them through the parent agent's session
 // (matching TS subagent-announce.ts pattern) so the agent can reformulate for the user.
-func consumeInboundMessages(ctx context.Context, msgBus *bus.MessageBus, agents *agent.Router, cfg *config.Config, sched *scheduler.Scheduler, channelMgr *channels.Manager, teamStore store.TeamStore, quotaChecker *channels.QuotaChecker, sessStore store.SessionStore, agentStore store.AgentStore, contactCollector *store.ContactCollector, postTurn tools.PostTurnProcessor, subagentMgr *tools.SubagentManager, usageCapSvc *usagecaps.Service, providerReg *providers.Registry) {
+func consumeInboundMessages(ctx context.Context, msgBus *bus.MessageBus, agents *agent.Router, cfg *config.Config, sched *scheduler.Scheduler, channelMgr *channels.Manager, teamStore store.TeamStore, quotaChecker *channels.QuotaChecker, sessStore store.SessionStore, agentStore store.AgentStore, contactCollector *store.ContactCollector, postTurn tools.PostTurnProcessor, subagentMgr *tools.SubagentManager, usageCapSvc *usagecaps.Service, providerReg *providers.Registry, execApprovals *tools.ExecApprovalManager) {
 	slog.Info("inbound message consumer started")
 
 	// Inbound message deduplication (matching TS src/infra/dedupe.ts + inbound-dedupe.ts).
@@ -51,6 +51,7 @@ func consumeInboundMessages(ctx context.Context, msgBus *bus.MessageBus, agents
 		Agents:           agents,
 		Sched:            sched,
 		ChannelMgr:       channelMgr,
+		ExecApprovals:    execApprovals,
 		MsgBus:           msgBus,
 		TeamStore:        teamStore,
 		AgentStore:       agentStore,
@@ -130,6 +131,9 @@ func consumeInboundMessages(ctx context.Context, msgBus *bus.MessageBus, agents
 		if handleTeammateMessage(ctx, msg, deps) {
 			continue
 		}
+		if handleActionCallback(ctx, msg, deps) {
+			continue
+		}
 		if handleResetCommand(msg, deps) {
 			continue
 		}
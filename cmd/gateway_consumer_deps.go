@@ -21,6 +21,7 @@ type ConsumerDeps struct {
 	Agents           *agent.Router
 	Sched            *scheduler.Scheduler
 	ChannelMgr       *channels.Manager
+	ExecApprovals    *tools.ExecApprovalManager // nil when exec approvals are off
 	MsgBus           *bus.MessageBus
 	TeamStore        store.TeamStore
 	AgentStore       store.AgentStore
@@ -91,6 +91,44 @@ func extractSessionMetadata(msg bus.InboundMessage, peerKind string) map[string]
 	return meta
 }
 
+// Session metadata keys recording where a cross-channel session was last active.
+const (
+	sessionMetaLastChannel     = "last_channel"
+	sessionMetaLastChannelType = "last_channel_type"
+)
+
+// inboundDMSessionKey resolves the session a DM from chatID on channel lands
+// in when no thread applies. sessions.dm_scope is deprecated and pinned to
+// per-channel-peer; with sessions.cross_channel, the DMs of a merged tenant
+// user share one session. The message tool's handoff resolves its target the
+// same way, so carried history is where the user's next reply is routed.
+func inboundDMSessionKey(cfg *config.Config, agentID, channel, chatID, tenantUserID string) string {
+	if tenantUserID != "" && cfg.Sessions.CrossChannel {
+		return sessions.BuildUserSessionKey(agentID, tenantUserID)
+	}
+	return sessions.BuildScopedSessionKey(agentID, channel, sessions.PeerDirect, chatID)
+}
+
+// crossChannelNote returns the system prompt hint for a cross-channel session
+// whose previous message arrived on another channel, or "" when it did not.
+func crossChannelNote(meta map[string]string, channel, channelType string) string {
+	prev := meta[sessionMetaLastChannel]
+	if prev == "" || prev == channel {
+		return ""
+	}
+	from, to := meta[sessionMetaLastChannelType], channelType
+	if from == "" {
+		from = prev
+	}
+	if to == "" {
+		to = channel
+	}
+	return fmt.Sprintf("The user switched channels: earlier messages in this conversation came through %s, "+
+		"and they now write from %s. It is the same person and the same conversation — "+
+		"continue where you left off, and mention the switch briefly if it helps (e.g. \"continuing from %s\").",
+		from, to, from)
+}
+
 // buildPancakeSessionLabel returns "Pancake:{senderName}:{pageName}" with non-empty parts only.
 func buildPancakeSessionLabel(senderName, pageName string) string {
 	label := "Pancake"
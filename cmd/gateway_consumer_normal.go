@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"strings"
 	"time"
 
@@ -130,6 +131,9 @@ func processNormalMessage(
 		displayName := sessionMeta["display_name"]
 		username := sessionMeta["username"]
 		deps.ContactCollector.EnsureContact(ctx, channelType, msg.Channel, senderNumericID, userID, displayName, username, peerKind, "user", "", "")
+		if peerKind == string(sessions.PeerDirect) && msg.ChatID != "" {
+			deps.ContactCollector.RecordDirectChat(ctx, channelType, msg.Channel, senderNumericID, msg.ChatID)
+		}
 
 		// Also collect group chat as a contact (for group permission management / merge).
 		// Group IDs (e.g., Telegram "-100456") differ from user IDs — no UNIQUE conflict.
@@ -143,6 +147,7 @@ func processNormalMessage(
 	// If the sender has been merged to a tenant_user, use the tenant user's ID
 	// for DM sessions. This enables per-user features (MCP creds, SecureCLI creds).
 	// Group sessions keep the group-scoped userID; sender resolution happens via SenderID.
+	var tenantUserID string
 	if deps.ContactCollector != nil && peerKind == string(sessions.PeerDirect) && msg.SenderID != "" && !bus.IsInternalSender(msg.SenderID) {
 		senderNumeric := msg.SenderID
 		if idx := strings.IndexByte(senderNumeric, '|'); idx > 0 {
@@ -155,9 +160,24 @@ func processNormalMessage(
 		if resolved, err := deps.ContactCollector.ResolveTenantUserID(ctx, chType, senderNumeric); err == nil && resolved != "" {
 			slog.Debug("contact.resolved_tenant_user", "sender", senderNumeric, "tenant_user", resolved)
 			userID = resolved
+			tenantUserID = resolved
 		}
 	}
 
+	// Cross-channel session: with sessions.cross_channel, a merged user's DMs
+	// share one session across channels. Threads keep their own sessions.
+	var channelSwitchNote string
+	if sessionKey == sessions.BuildScopedSessionKey(agentID, msg.Channel, sessions.PeerDirect, msg.ChatID) {
+		sessionKey = inboundDMSessionKey(deps.Cfg, agentID, msg.Channel, msg.ChatID, tenantUserID)
+	}
+	if sessions.IsUserSession(sessionKey) {
+		chType := resolveChannelType(deps.ChannelMgr, msg.Channel)
+		channelSwitchNote = crossChannelNote(deps.SessStore.GetSessionMetadata(ctx, sessionKey), msg.Channel, chType)
+		meta := map[string]string{sessionMetaLastChannel: msg.Channel, sessionMetaLastChannelType: chType}
+		maps.Copy(meta, sessionMeta)
+		deps.SessStore.SetSessionMetadata(ctx, sessionKey, meta)
+	}
+
 	// --- Quota check ---
 	if deps.QuotaChecker != nil {
 		qResult := deps.QuotaChecker.Check(ctx, userID, msg.Channel, agentLoop.ProviderName())
@@ -220,6 +240,7 @@ func processNormalMessage(
 	// Build outbound metadata for reply-to + thread routing BEFORE RegisterRun
 	// so block.reply handler can use it for routing intermediate messages.
 	outMeta := channels.CopyFinalRoutingMeta(msg.Metadata)
+	outMeta["run_id"] = runID
 	if isGroup {
 		if mid := msg.Metadata["message_id"]; mid != "" {
 			outMeta["reply_to_message_id"] = mid
@@ -284,6 +305,13 @@ func processNormalMessage(
 		extraPrompt += identity
 	}
 
+	if channelSwitchNote != "" {
+		if extraPrompt != "" {
+			extraPrompt += "\n\n"
+		}
+		extraPrompt += channelSwitchNote
+	}
+
 	// Append Bitrix24 entity binding hint so MCP-equipped agents can resolve
 	// "this deal/task/lead" deterministically. The channel layer (bitrix24/handle.go)
 	// forwards data[PARAMS][CHAT_ENTITY_TYPE] + CHAT_ENTITY_ID into Metadata
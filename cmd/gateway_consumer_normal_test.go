@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/sessions"
 )
 
 // TestIsSafeBitrixEntityToken pins the validation contract for webhook-sourced
@@ -65,3 +67,36 @@ func TestResolveSenderNameTruncatesLongMetadata(t *testing.T) {
 		t.Fatalf("resolveSenderName() length = %d, want 100", len([]rune(got)))
 	}
 }
+
+func TestInboundDMSessionKeyIgnoresDeprecatedDMScope(t *testing.T) {
+	perChannel := sessions.BuildScopedSessionKey("bot", "slack-main", sessions.PeerDirect, "D042")
+	for _, scope := range []string{"main", "per-peer", "per-account-channel-peer"} {
+		cfg := &config.Config{}
+		cfg.Sessions.DmScope = scope
+		if got := inboundDMSessionKey(cfg, "bot", "slack-main", "D042", "alice"); got != perChannel {
+			t.Fatalf("dm_scope=%s: got %q, want %q", scope, got, perChannel)
+		}
+		cfg.Sessions.CrossChannel = true
+		if got, want := inboundDMSessionKey(cfg, "bot", "slack-main", "D042", "alice"), sessions.BuildUserSessionKey("bot", "alice"); got != want {
+			t.Fatalf("dm_scope=%s cross_channel: got %q, want %q", scope, got, want)
+		}
+		if got := inboundDMSessionKey(cfg, "bot", "slack-main", "D042", ""); got != perChannel {
+			t.Fatalf("dm_scope=%s unmerged sender: got %q, want %q", scope, got, perChannel)
+		}
+	}
+}
+
+func TestCrossChannelNoteOnlyOnChannelSwitch(t *testing.T) {
+	if got := crossChannelNote(nil, "slack-main", "slack"); got != "" {
+		t.Fatalf("new session: got %q, want no note", got)
+	}
+	same := map[string]string{sessionMetaLastChannel: "slack-main", sessionMetaLastChannelType: "slack"}
+	if got := crossChannelNote(same, "slack-main", "slack"); got != "" {
+		t.Fatalf("same channel: got %q, want no note", got)
+	}
+	prev := map[string]string{sessionMetaLastChannel: "tg-bot", sessionMetaLastChannelType: "telegram"}
+	got := crossChannelNote(prev, "slack-main", "slack")
+	if !strings.Contains(got, "continuing from telegram") || !strings.Contains(got, "now write from slack") {
+		t.Fatalf("switch note = %q", got)
+	}
+}
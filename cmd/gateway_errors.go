@@ -97,6 +97,12 @@ func isExternalChannel(channelType string) bool {
 		channels.TypeZaloOA,
 		channels.TypeZaloPersonal,
 		channels.TypePancake,
+		channels.TypeMatrix,
+		channels.TypeSignal,
+		channels.TypeEmail,
+		channels.TypeTeams,
+		channels.TypeBridge,
+		channels.TypeTwilio,
 		channels.TypeSlack:
 		return true
 	}
@@ -25,6 +25,9 @@ var httpClient = &http.Client{Timeout: 10 * time.Second}
 // healthClient has a shorter timeout for quick health checks.
 var healthClient = &http.Client{Timeout: 3 * time.Second}
 
+// runClient waits for requests that execute a whole agent run (trace replay).
+var runClient = &http.Client{Timeout: 10 * time.Minute}
+
 const gatewayHTTPResponseLimit = 1 << 20
 
 // gatewayHTTPDo sends an HTTP request to the gateway with auth and returns the parsed JSON response.
@@ -81,6 +84,10 @@ func gatewayHTTPDoRaw(method, path string, body any) ([]byte, int, error) {
 }
 
 func gatewayHTTPDoRawWithLimit(method, path string, body any, limit int64) ([]byte, int, error) {
+	return gatewayHTTPDoRawWithClient(httpClient, method, path, body, limit)
+}
+
+func gatewayHTTPDoRawWithClient(client *http.Client, method, path string, body any, limit int64) ([]byte, int, error) {
 	base := resolveGatewayBaseURL()
 
 	var bodyReader io.Reader
@@ -102,7 +109,7 @@ func gatewayHTTPDoRawWithLimit(method, path string, body any, limit int64) ([]by
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
-	resp, err := httpClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, 0, fmt.Errorf("cannot reach gateway at %s: %w", base, err)
 	}
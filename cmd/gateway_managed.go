@@ -59,11 +59,22 @@ func wireExtras(
 	appCfg *config.Config,
 	sandboxMgr sandbox.Manager,
 	redisClient any, // nil when built without -tags redis or when Redis is unconfigured
+	embProvider memorypkg.EmbeddingProvider, // nil when no embedding provider is configured
 	domainBus eventbus.DomainEventBus,
 	usageCapSvc *usagecaps.Service,
 ) (*tools.ContextFileInterceptor, *mcpbridge.Pool, *media.Store, tools.PostTurnProcessor) {
 	// 1. Build cache instances (in-memory or Redis depending on build tags)
 	agentCtxCache, userCtxCache := makeCaches(redisClient)
+	respCache, respIndex := makeResponseCaches(redisClient)
+	var respEmbedder providers.ResponseCacheEmbedder
+	if embProvider != nil {
+		respEmbedder = embProvider
+	}
+	responseCache := providers.NewResponseCacheStore(respCache, respIndex, respEmbedder)
+	recordings := providers.NewRecordingArchive(recordingsDir(appCfg.ResolvedDataDir()))
+	if traceCollector != nil {
+		traceCollector.SetRecordingPruner(recordings)
+	}
 
 	// 1a. Context file interceptor (created before resolver so callbacks can reference it)
 	var contextFileInterceptor *tools.ContextFileInterceptor
@@ -104,13 +115,24 @@ func wireExtras(
 		slog.Info("media tools registered", "tools", "read_document,read_audio,read_video,create_video")
 	}
 
-	// 1e. Wire secure CLI store into exec tool for credentialed exec
+	// 1e. Wire secure CLI store into exec tool for credentialed exec, into
+	// http_request for auth profiles and into sql_query for connections
 	if stores.SecureCLI != nil {
 		if execTool, ok := toolsReg.Get("exec"); ok {
 			if et, ok := execTool.(*tools.ExecTool); ok {
 				et.SetSecureCLIStore(stores.SecureCLI)
 			}
 		}
+		if httpTool, ok := toolsReg.Get("http_request"); ok {
+			if ht, ok := httpTool.(*tools.HTTPRequestTool); ok {
+				ht.SetSecureCLIStore(stores.SecureCLI)
+			}
+		}
+		if sqlTool, ok := toolsReg.Get("sql_query"); ok {
+			if st, ok := sqlTool.(*tools.SQLQueryTool); ok {
+				st.SetSecureCLIStore(stores.SecureCLI)
+			}
+		}
 	}
 
 	// 2. Per-user profile + context file seeding callbacks
@@ -154,10 +176,16 @@ func wireExtras(
 		skillAccessStore = sas
 	}
 
+	// Second-stage reranker shared by auto-inject, memory_search and vault_search,
+	// resolved per tenant. Only used for agents with memory_config.rerank enabled.
+	reranker := memorypkg.NewTenantReranker(func(ctx context.Context) store.Reranker {
+		return resolveReranker(ctx, stores.Providers, stores.SystemConfigs)
+	}, rerankerCacheTTL)
+
 	// V3 auto-inject: create AutoInjector if episodic store is available.
 	var autoInjector memorypkg.AutoInjector
 	if stores.Episodic != nil {
-		autoInjector = memorypkg.NewAutoInjector(stores.Episodic, stores.EvolutionMetrics)
+		autoInjector = memorypkg.NewAutoInjector(stores.Episodic, stores.EvolutionMetrics, reranker)
 	}
 
 	// vaultIntc is set later by wireVault but captured by closure in OnTextUploaded.
@@ -222,6 +250,8 @@ func wireExtras(
 		SkillSlashCommands:     appCfg.Skills.SlashCommands,
 		HasMemory:              hasMemory,
 		TraceCollector:         traceCollector,
+		ResponseCache:          responseCache,
+		Recordings:             recordings,
 		EnsureUserProfile:      ensureUserProfile,
 		SeedUserFiles:          seedUserFiles,
 		ContextFileLoader:      contextFileLoader,
@@ -350,6 +380,16 @@ func wireExtras(
 			}
 		}
 	}
+	if patchTool, ok := toolsReg.Get("apply_patch"); ok {
+		if ia, ok := patchTool.(tools.InterceptorAware); ok {
+			if contextFileInterceptor != nil {
+				ia.SetContextFileInterceptor(contextFileInterceptor)
+			}
+			if writeMemIntc != nil {
+				ia.SetMemoryInterceptor(writeMemIntc)
+			}
+		}
+	}
 	if listTool, ok := toolsReg.Get("list_files"); ok {
 		if ia, ok := listTool.(tools.InterceptorAware); ok {
 			if stores.Memory != nil {
@@ -360,7 +400,7 @@ func wireExtras(
 
 	// Wire config perm store for file writer permission checks
 	if stores.ConfigPermissions != nil {
-		for _, toolName := range []string{"read_file", "write_file", "edit", "cron"} {
+		for _, toolName := range []string{"read_file", "write_file", "edit", "apply_patch", "cron"} {
 			if t, ok := toolsReg.Get(toolName); ok {
 				if cpa, ok := t.(tools.ConfigPermAware); ok {
 					cpa.SetConfigPermStore(stores.ConfigPermissions)
@@ -392,6 +432,7 @@ func wireExtras(
 		if searchTool, ok := toolsReg.Get("memory_search"); ok {
 			if mst, ok := searchTool.(*tools.MemorySearchTool); ok {
 				mst.SetEpisodicStore(stores.Episodic)
+				mst.SetReranker(reranker)
 				if stores.EvolutionMetrics != nil {
 					mst.SetEvolutionMetricsStore(stores.EvolutionMetrics)
 				}
@@ -422,7 +463,7 @@ func wireExtras(
 	}
 
 	// Wire vault tools and interceptors (conditional on vault store availability)
-	vaultIntc = wireVault(stores, toolsReg, workspace, domainBus)
+	vaultIntc = wireVault(stores, toolsReg, workspace, domainBus, reranker)
 
 	// Wire delegate tool for inter-agent delegation via agent_links.
 	if stores.AgentLinks != nil && stores.Agents != nil {
@@ -725,6 +766,12 @@ type kgSettings struct {
 // buildKGExtractFunc returns a callback that extracts entities from memory content.
 // Settings are read from the builtin_tools table on each invocation (not cached),
 // so changes take effect immediately without restart.
+// recordingsDir holds provider call recordings for trace replay, one JSONL
+// file per trace.
+func recordingsDir(dataDir string) string {
+	return filepath.Join(dataDir, "recordings")
+}
+
 func buildKGExtractFunc(kgStore store.KnowledgeGraphStore, bts store.BuiltinToolStore, providerReg *providers.Registry, usageCapSvc *usagecaps.Service) tools.KGExtractFunc {
 	return func(ctx context.Context, agentID, userID, content string) {
 		slog.Info("kg extract: triggered", "agent", agentID, "user", userID, "content_len", len(content))
@@ -750,6 +797,7 @@ func buildKGExtractFunc(kgStore store.KnowledgeGraphStore, bts store.BuiltinTool
 		}
 		extractor := kg.NewExtractor(p, settings.ExtractionModel, settings.MinConfidence)
 		extractor.SetUsageCapService(usageCapSvc)
+		extractor.SetBatch(true)
 		result, err := extractor.Extract(ctx, content)
 		if err != nil {
 			slog.Warn("kg extract: extraction failed", "agent", agentID, "error", err)
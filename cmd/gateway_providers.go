@@ -15,6 +15,7 @@ import (
 
 	"github.com/nextlevelbuilder/goclaw/internal/config"
 	"github.com/nextlevelbuilder/goclaw/internal/oauth"
+	"github.com/nextlevelbuilder/goclaw/internal/providerresolve"
 	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 	"github.com/nextlevelbuilder/goclaw/internal/tools"
@@ -61,7 +62,7 @@ func registerProviders(registry *providers.Registry, cfg *config.Config, modelRe
 	}
 
 	if cfg.Providers.Gemini.APIKey != "" {
-		registry.Register(providers.NewOpenAIProvider("gemini", cfg.Providers.Gemini.APIKey, "https://generativelanguage.googleapis.com/v1beta/openai", "gemini-2.0-flash"))
+		registry.Register(providers.NewGeminiProvider("gemini", cfg.Providers.Gemini.APIKey, cfg.Providers.Gemini.APIBase, "gemini-2.0-flash"))
 		slog.Info("registered provider", "name", "gemini")
 	}
 
@@ -124,11 +125,29 @@ func registerProviders(registry *providers.Registry, cfg *config.Config, modelRe
 	}
 
 	// Local / self-hosted Ollama — gated on Host, no API key required.
-	// Ollama's OpenAI-compat endpoint accepts any non-empty Bearer value.
-	if cfg.Providers.Ollama.Host != "" {
-		host := cfg.Providers.Ollama.Host
-		registry.Register(providers.NewOpenAIProvider("ollama", "ollama", host+"/v1", "llama3.3"))
-		slog.Info("registered provider", "name", "ollama")
+	// Uses the native API; installed models are discovered into the ModelRegistry.
+	if oc := cfg.Providers.Ollama; oc.Host != "" {
+		prov := providers.NewOllamaProvider("ollama", oc.Host, oc.Model).
+			WithKeepAlive(oc.KeepAlive).
+			WithNumCtx(oc.NumCtx).
+			WithRegistry(modelReg)
+		if oc.AutoPull != nil {
+			prov.WithAutoPull(*oc.AutoPull)
+		}
+		registry.Register(prov)
+		go discoverOllamaModels(prov)
+		slog.Info("registered provider", "name", "ollama", "api_base", prov.APIBase())
+	}
+
+	// Local / self-hosted llama.cpp server — gated on Host, API key optional.
+	// Served models are discovered (with the server's n_ctx) into the ModelRegistry.
+	if lc := cfg.Providers.LlamaCpp; lc.Host != "" {
+		prov := providers.NewLlamaCppProvider("llamacpp", lc.Host, lc.Model).
+			WithAPIKey(lc.APIKey).
+			WithRegistry(modelReg)
+		registry.Register(prov)
+		go discoverLlamaCppModels(prov)
+		slog.Info("registered provider", "name", "llamacpp", "api_base", prov.APIBase())
 	}
 
 	// Ollama Cloud — API key required (generate at ollama.com/settings/keys).
@@ -291,14 +310,20 @@ func registerProvidersFromDB(registry *providers.Registry, provStore store.Provi
 			continue
 		}
 		// Local Ollama requires no API key — handle before the key guard (same pattern as ClaudeCLI).
-		// api_base is stored with /v1 (normalized at write time), so no suffix appending needed.
+		// api_base is stored with /v1 (normalized at write time); the native provider strips it.
 		if p.ProviderType == store.ProviderOllama {
-			host := p.APIBase
-			if host == "" {
-				host = "http://localhost:11434/v1"
-			}
-			registry.RegisterForTenant(p.TenantID, providers.NewOpenAIProvider(p.Name, "ollama", config.DockerLocalhost(host), "llama3.3"))
-			slog.Info("registered provider from DB", "name", p.Name)
+			prov := ollamaProviderFromDB(p, modelReg)
+			registry.RegisterForTenant(p.TenantID, prov)
+			go discoverOllamaModels(prov)
+			slog.Info("registered provider from DB", "name", p.Name, "type", "ollama", "api_base", prov.APIBase())
+			continue
+		}
+		// llama.cpp servers only need a key when started with --api-key.
+		if p.ProviderType == store.ProviderLlamaCpp {
+			prov := llamaCppProviderFromDB(p, modelReg)
+			registry.RegisterForTenant(p.TenantID, prov)
+			go discoverLlamaCppModels(prov)
+			slog.Info("registered provider from DB", "name", p.Name, "type", "llamacpp", "api_base", prov.APIBase())
 			continue
 		}
 		// Vertex supports ADC (empty api_key) — handle before the generic key guard.
@@ -325,8 +350,24 @@ func registerProvidersFromDB(registry *providers.Registry, provStore store.Provi
 			slog.Info("registered provider from DB", "name", p.Name, "type", "vertex", "region", vsettings.Region)
 			continue
 		}
+		// Bedrock supports the AWS default credential chain (empty api_key) — handle before the key guard.
+		if p.ProviderType == store.ProviderBedrock {
+			bsettings := store.ParseBedrockProviderSettings(p.Settings)
+			if bsettings == nil {
+				slog.Warn("bedrock: missing region in settings, skipping", "name", p.Name)
+				continue
+			}
+			prov, err := providers.NewBedrockProviderWithTimeout(providerresolve.BedrockConfigFromDB(&p, bsettings))
+			if err != nil {
+				slog.Warn("bedrock: init from DB failed", "name", p.Name, "error", err)
+				continue
+			}
+			registry.RegisterForTenant(p.TenantID, prov.WithRegistry(modelReg))
+			slog.Info("registered provider from DB", "name", p.Name, "type", "bedrock", "region", bsettings.Region)
+			continue
+		}
 
-		if p.APIKey == "" {
+		if !providerresolve.HasAPIKey(&p, secretStore) {
 			continue
 		}
 		// Fall back to config/env api_base when DB provider has none set.
@@ -336,6 +377,10 @@ func registerProvidersFromDB(registry *providers.Registry, provStore store.Provi
 				slog.Info("provider api_base inherited from config", "name", p.Name, "api_base", base)
 			}
 		}
+		// Extra API keys (config_secrets) rotate through a per-key pool.
+		withKeyPool := func(prov providers.Provider) providers.Provider {
+			return providerresolve.WithKeyPool(prov, &p, secretStore)
+		}
 		switch p.ProviderType {
 		case store.ProviderChatGPTOAuth:
 			ts := oauth.NewDBTokenSource(provStore, secretStore, p.Name).WithTenantID(p.TenantID)
@@ -345,42 +390,45 @@ func registerProvidersFromDB(registry *providers.Registry, provStore store.Provi
 			}
 			registry.RegisterForTenant(p.TenantID, codex)
 		case store.ProviderAnthropicNative:
-			registry.RegisterForTenant(p.TenantID, providers.NewAnthropicProvider(p.APIKey,
+			registry.RegisterForTenant(p.TenantID, withKeyPool(providers.NewAnthropicProvider(p.APIKey,
 				providers.WithAnthropicName(p.Name),
 				providers.WithAnthropicBaseURL(p.APIBase),
-				providers.WithAnthropicRegistry(modelReg)))
+				providers.WithAnthropicRegistry(modelReg))))
+		case store.ProviderGeminiNative:
+			// Native generateContent; legacy ".../v1beta/openai" bases are normalized.
+			registry.RegisterForTenant(p.TenantID, withKeyPool(providers.NewGeminiProvider(p.Name, p.APIKey, p.APIBase, "")))
 		case store.ProviderDashScope:
-			registry.RegisterForTenant(p.TenantID, providers.NewDashScopeProvider(p.Name, p.APIKey, p.APIBase, ""))
+			registry.RegisterForTenant(p.TenantID, withKeyPool(providers.NewDashScopeProvider(p.Name, p.APIKey, p.APIBase, "")))
 		case store.ProviderBailian:
 			base := p.APIBase
 			if base == "" {
 				base = "https://coding-intl.dashscope.aliyuncs.com/v1"
 			}
-			registry.RegisterForTenant(p.TenantID, providers.NewOpenAIProvider(p.Name, p.APIKey, base, "qwen3.5-plus"))
+			registry.RegisterForTenant(p.TenantID, withKeyPool(providers.NewOpenAIProvider(p.Name, p.APIKey, base, "qwen3.5-plus")))
 		case store.ProviderZai:
 			base := p.APIBase
 			if base == "" {
 				base = "https://api.z.ai/api/paas/v4"
 			}
-			registry.RegisterForTenant(p.TenantID, providers.NewOpenAIProvider(p.Name, p.APIKey, base, "glm-5"))
+			registry.RegisterForTenant(p.TenantID, withKeyPool(providers.NewOpenAIProvider(p.Name, p.APIKey, base, "glm-5")))
 		case store.ProviderZaiCoding:
 			base := p.APIBase
 			if base == "" {
 				base = "https://api.z.ai/api/coding/paas/v4"
 			}
-			registry.RegisterForTenant(p.TenantID, providers.NewOpenAIProvider(p.Name, p.APIKey, base, "glm-5"))
+			registry.RegisterForTenant(p.TenantID, withKeyPool(providers.NewOpenAIProvider(p.Name, p.APIKey, base, "glm-5")))
 		case store.ProviderOllamaCloud:
 			base := p.APIBase
 			if base == "" {
 				base = "https://ollama.com/v1"
 			}
-			registry.RegisterForTenant(p.TenantID, providers.NewOpenAIProvider(p.Name, p.APIKey, base, "llama3.3"))
+			registry.RegisterForTenant(p.TenantID, withKeyPool(providers.NewOpenAIProvider(p.Name, p.APIKey, base, "llama3.3")))
 		case store.ProviderNovita:
 			base := p.APIBase
 			if base == "" {
 				base = store.NovitaDefaultAPIBase
 			}
-			registry.RegisterForTenant(p.TenantID, providers.NewOpenAIProvider(p.Name, p.APIKey, base, store.NovitaDefaultModel))
+			registry.RegisterForTenant(p.TenantID, withKeyPool(providers.NewOpenAIProvider(p.Name, p.APIKey, base, store.NovitaDefaultModel)))
 		case store.ProviderBytePlus:
 			base := p.APIBase
 			if base == "" {
@@ -388,7 +436,7 @@ func registerProvidersFromDB(registry *providers.Registry, provStore store.Provi
 			}
 			prov := providers.NewOpenAIProvider(p.Name, p.APIKey, base, store.BytePlusDefaultModel)
 			prov.WithProviderType(p.ProviderType)
-			registry.RegisterForTenant(p.TenantID, prov)
+			registry.RegisterForTenant(p.TenantID, withKeyPool(prov))
 		case store.ProviderBytePlusCoding:
 			base := p.APIBase
 			if base == "" {
@@ -396,7 +444,7 @@ func registerProvidersFromDB(registry *providers.Registry, provStore store.Provi
 			}
 			prov := providers.NewOpenAIProvider(p.Name, p.APIKey, base, store.BytePlusDefaultModel)
 			prov.WithProviderType(p.ProviderType)
-			registry.RegisterForTenant(p.TenantID, prov)
+			registry.RegisterForTenant(p.TenantID, withKeyPool(prov))
 		case store.ProviderKimiCoding:
 			// Moonshot Kimi Coding requires a fixed User-Agent on every request.
 			// OpenAI-compatible wire shape otherwise.
@@ -409,7 +457,7 @@ func registerProvidersFromDB(registry *providers.Registry, provStore store.Provi
 			prov.WithExtraHeaders(map[string]string{
 				"User-Agent": store.KimiCodingRequiredUserAgent,
 			})
-			registry.RegisterForTenant(p.TenantID, prov)
+			registry.RegisterForTenant(p.TenantID, withKeyPool(prov))
 		default:
 			prov := providers.NewOpenAIProvider(p.Name, p.APIKey, p.APIBase, "")
 			prov.WithProviderType(p.ProviderType)
@@ -419,12 +467,70 @@ func registerProvidersFromDB(registry *providers.Registry, provStore store.Provi
 			if p.ProviderType == store.ProviderOpenRouter {
 				prov.WithSiteInfo("https://goclaw.sh", "GoClaw")
 			}
-			registry.RegisterForTenant(p.TenantID, prov)
+			registry.RegisterForTenant(p.TenantID, withKeyPool(prov))
 		}
 		slog.Info("registered provider from DB", "name", p.Name)
 	}
 }
 
+// ollamaProviderFromDB builds a native Ollama provider from a DB row.
+// In Docker, localhost is swapped for host.docker.internal so the container
+// can reach the host. A non-empty api_key is sent as a Bearer token.
+func ollamaProviderFromDB(p store.LLMProviderData, modelReg providers.ModelRegistry) *providers.OllamaProvider {
+	s := store.ParseOllamaProviderSettings(p.Settings)
+	host := p.APIBase
+	if host == "" {
+		host = providers.OllamaDefaultAPIBase
+	}
+	prov := providers.NewOllamaProvider(p.Name, config.DockerLocalhost(host), s.Model).
+		WithAPIKey(p.APIKey).
+		WithKeepAlive(s.KeepAlive).
+		WithNumCtx(s.NumCtx).
+		WithRegistry(modelReg)
+	if s.AutoPull != nil {
+		prov.WithAutoPull(*s.AutoPull)
+	}
+	return prov
+}
+
+// discoverOllamaModels registers the server's installed models. An offline
+// server is not fatal: models still resolve lazily once it comes up.
+func discoverOllamaModels(prov *providers.OllamaProvider) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	specs, err := prov.DiscoverModels(ctx)
+	if err != nil {
+		slog.Warn("ollama: model discovery failed", "provider", prov.Name(), "error", err)
+		return
+	}
+	slog.Info("ollama: discovered models", "provider", prov.Name(), "count", len(specs))
+}
+
+// llamaCppProviderFromDB builds a llama.cpp provider from a DB row, with the
+// same Docker localhost swap as Ollama.
+func llamaCppProviderFromDB(p store.LLMProviderData, modelReg providers.ModelRegistry) *providers.LlamaCppProvider {
+	host := p.APIBase
+	if host == "" {
+		host = providers.LlamaCppDefaultAPIBase
+	}
+	return providers.NewLlamaCppProvider(p.Name, config.DockerLocalhost(host), "").
+		WithAPIKey(p.APIKey).
+		WithRegistry(modelReg)
+}
+
+// discoverLlamaCppModels registers the server's models. An offline server is
+// not fatal: models still resolve lazily once it comes up.
+func discoverLlamaCppModels(prov *providers.LlamaCppProvider) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	specs, err := prov.DiscoverModels(ctx)
+	if err != nil {
+		slog.Warn("llamacpp: model discovery failed", "provider", prov.Name(), "error", err)
+		return
+	}
+	slog.Info("llamacpp: discovered models", "provider", prov.Name(), "count", len(specs))
+}
+
 func registerClaudeCLIFromConfig(registry *providers.Registry, cfg *config.Config) {
 	if cfg == nil || cfg.Providers.ClaudeCLI.CLIPath == "" {
 		return
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+type listedProviderStore struct {
+	store.ProviderStore
+	rows []store.LLMProviderData
+}
+
+func (s *listedProviderStore) ListAllProviders(context.Context) ([]store.LLMProviderData, error) {
+	return s.rows, nil
+}
+
+type mapSecretsStore struct {
+	store.ConfigSecretsStore
+	data map[string]string
+}
+
+func (s *mapSecretsStore) Get(_ context.Context, key string) (string, error) {
+	return s.data[key], nil
+}
+
+func TestRegisterProvidersFromDBPooledKeysWithoutPrimary(t *testing.T) {
+	tenantID := uuid.New()
+	pooled := store.LLMProviderData{
+		BaseModel:    store.BaseModel{ID: uuid.New()},
+		TenantID:     tenantID,
+		Name:         "pooled",
+		ProviderType: store.ProviderOpenAICompat,
+		APIBase:      "https://api.example.test/v1",
+		Enabled:      true,
+	}
+	keyless := pooled
+	keyless.ID, keyless.Name = uuid.New(), "keyless"
+	secrets := &mapSecretsStore{data: map[string]string{
+		store.ProviderAPIKeysSecretKey(pooled.ID): `["sk-a","sk-b"]`,
+	}}
+
+	registry := providers.NewRegistry(nil)
+	registerProvidersFromDB(registry, &listedProviderStore{rows: []store.LLMProviderData{pooled, keyless}}, secrets, "", "", nil, nil, nil)
+
+	prov, err := registry.GetForTenant(tenantID, "pooled")
+	if err != nil {
+		t.Fatalf("pooled provider not registered: %v", err)
+	}
+	if oa, ok := prov.(*providers.OpenAIProvider); !ok || oa.KeyPool() == nil || oa.KeyPool().Len() != 2 {
+		t.Fatalf("provider = %T, want openai-compatible with a 2-key pool", prov)
+	}
+	if _, err := registry.GetForTenant(tenantID, "keyless"); err == nil {
+		t.Fatal("provider without any key was registered")
+	}
+}
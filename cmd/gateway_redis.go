@@ -4,11 +4,13 @@ package cmd
 
 import (
 	"log/slog"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 
 	"github.com/nextlevelbuilder/goclaw/internal/cache"
 	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
@@ -45,6 +47,21 @@ func makeCaches(raw any) (
 		cache.NewRedisCache[[]store.AgentContextFileData](client, "ctx:user")
 }
 
+// makeResponseCaches creates the LLM response cache and its semantic index,
+// backed by Redis (or in-memory if client is nil) so hits are shared across instances.
+func makeResponseCaches(raw any) (
+	responses cache.Cache[providers.CachedResponse],
+	index cache.Cache[[]providers.SemanticCacheEntry],
+) {
+	client, _ := raw.(*redis.Client)
+	if client == nil {
+		return cache.NewInMemoryCache(cache.WithMaxSize[providers.CachedResponse](10000), cache.WithSweepInterval[providers.CachedResponse](5*time.Minute)),
+			cache.NewInMemoryCache(cache.WithMaxSize[[]providers.SemanticCacheEntry](2000), cache.WithSweepInterval[[]providers.SemanticCacheEntry](5*time.Minute))
+	}
+	return cache.NewRedisCache[providers.CachedResponse](client, "llm:resp"),
+		cache.NewRedisCache[[]providers.SemanticCacheEntry](client, "llm:resp_idx")
+}
+
 // shutdownRedis closes the Redis client connection.
 func shutdownRedis(raw any) {
 	if client, ok := raw.(*redis.Client); ok && client != nil {
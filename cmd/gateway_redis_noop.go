@@ -4,9 +4,11 @@ package cmd
 
 import (
 	"log/slog"
+	"time"
 
 	"github.com/nextlevelbuilder/goclaw/internal/cache"
 	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
@@ -24,5 +26,14 @@ func makeCaches(_ any) (
 		cache.NewInMemoryCache[[]store.AgentContextFileData]()
 }
 
+// makeResponseCaches returns in-memory LLM response cache instances when Redis is not compiled in.
+func makeResponseCaches(_ any) (
+	responses cache.Cache[providers.CachedResponse],
+	index cache.Cache[[]providers.SemanticCacheEntry],
+) {
+	return cache.NewInMemoryCache(cache.WithMaxSize[providers.CachedResponse](10000), cache.WithSweepInterval[providers.CachedResponse](5*time.Minute)),
+		cache.NewInMemoryCache(cache.WithMaxSize[[]providers.SemanticCacheEntry](2000), cache.WithSweepInterval[[]providers.SemanticCacheEntry](5*time.Minute))
+}
+
 // shutdownRedis is a no-op when built without the "redis" tag.
 func shutdownRedis(_ any) {}
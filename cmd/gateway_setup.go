@@ -16,7 +16,9 @@ import (
 	"github.com/nextlevelbuilder/goclaw/internal/config"
 	"github.com/nextlevelbuilder/goclaw/internal/edition"
 	mcpbridge "github.com/nextlevelbuilder/goclaw/internal/mcp"
+	"github.com/nextlevelbuilder/goclaw/internal/memory"
 	"github.com/nextlevelbuilder/goclaw/internal/permissions"
+	"github.com/nextlevelbuilder/goclaw/internal/providerresolve"
 	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/sandbox"
 	"github.com/nextlevelbuilder/goclaw/internal/skills"
@@ -74,13 +76,18 @@ func setupToolRegistry(
 		toolsReg.Register(tools.NewSandboxedReadFileTool(workspace, agentCfg.RestrictToWorkspace, sandboxMgr))
 		toolsReg.Register(tools.NewSandboxedWriteFileTool(workspace, agentCfg.RestrictToWorkspace, sandboxMgr))
 		toolsReg.Register(tools.NewSandboxedListFilesTool(workspace, agentCfg.RestrictToWorkspace, sandboxMgr))
+		toolsReg.Register(tools.NewSandboxedSearchFilesTool(workspace, agentCfg.RestrictToWorkspace, sandboxMgr))
 		toolsReg.Register(tools.NewSandboxedEditTool(workspace, agentCfg.RestrictToWorkspace, sandboxMgr))
+		toolsReg.Register(tools.NewSandboxedApplyPatchTool(workspace, agentCfg.RestrictToWorkspace, sandboxMgr))
 		toolsReg.Register(tools.NewSandboxedExecTool(workspace, agentCfg.RestrictToWorkspace, sandboxMgr))
+		toolsReg.Register(tools.NewCodeInterpreterTool(workspace, sandboxMgr))
 	} else {
 		toolsReg.Register(tools.NewReadFileTool(workspace, agentCfg.RestrictToWorkspace))
 		toolsReg.Register(tools.NewWriteFileTool(workspace, agentCfg.RestrictToWorkspace))
 		toolsReg.Register(tools.NewListFilesTool(workspace, agentCfg.RestrictToWorkspace))
+		toolsReg.Register(tools.NewSearchFilesTool(workspace, agentCfg.RestrictToWorkspace))
 		toolsReg.Register(tools.NewEditTool(workspace, agentCfg.RestrictToWorkspace))
+		toolsReg.Register(tools.NewApplyPatchTool(workspace, agentCfg.RestrictToWorkspace))
 		toolsReg.Register(tools.NewExecTool(workspace, agentCfg.RestrictToWorkspace))
 	}
 
@@ -125,6 +132,8 @@ func setupToolRegistry(
 	})
 	toolsReg.Register(webFetchTool)
 	slog.Info("web_fetch tool enabled", "policy", cfg.Tools.WebFetch.Policy, "blocked", len(cfg.Tools.WebFetch.BlockedDomains))
+	toolsReg.Register(tools.NewHTTPRequestTool())
+	toolsReg.Register(tools.NewSQLQueryTool(workspace))
 
 	// Vision fallback tool (for non-vision providers like MiniMax)
 	toolsReg.Register(tools.NewReadImageTool(providerRegistry))
@@ -280,11 +289,21 @@ func setupToolRegistry(
 			t.DenyPaths(internalDenyPaths...)
 		}
 	}
+	if sf, ok := toolsReg.Get("search_files"); ok {
+		if t, ok := sf.(*tools.SearchFilesTool); ok {
+			t.DenyPaths(internalDenyPaths...)
+		}
+	}
 	if ed, ok := toolsReg.Get("edit"); ok {
 		if t, ok := ed.(*tools.EditTool); ok {
 			t.DenyPaths(internalDenyPaths...)
 		}
 	}
+	if ap, ok := toolsReg.Get("apply_patch"); ok {
+		if t, ok := ap.(*tools.ApplyPatchTool); ok {
+			t.DenyPaths(internalDenyPaths...)
+		}
+	}
 	if sf, ok := toolsReg.Get("send_file"); ok {
 		if t, ok := sf.(*tools.SendFileTool); ok {
 			t.DenyPaths(internalDenyPaths...)
@@ -324,6 +343,7 @@ func wireTracingAndCron(
 			})
 		})
 		traceCollector.Start()
+		go providerresolve.SeedRouteStats(context.Background(), stores.Tracing)
 		slog.Info("LLM tracing enabled")
 	}
 
@@ -369,10 +389,11 @@ func wireTracingAndCron(
 
 // setupMemoryEmbeddings wires embedding provider to PGMemoryStore and triggers backfill.
 // Resolves embedding provider from DB providers with settings.embedding.enabled.
+// Returns the provider (nil when none) for other semantic consumers.
 func setupMemoryEmbeddings(
 	pgStores *store.Stores,
 	providerRegistry *providers.Registry,
-) {
+) memory.EmbeddingProvider {
 	if pgStores.Memory != nil {
 		if embProvider := resolveEmbeddingProvider(pgStores.Providers, providerRegistry, pgStores.SystemConfigs); embProvider != nil {
 			pgStores.Memory.SetEmbeddingProvider(embProvider)
@@ -429,10 +450,11 @@ func setupMemoryEmbeddings(
 				pgStores.Episodic.SetEmbeddingProvider(embProvider)
 				slog.Info("episodic embeddings enabled", "provider", embProvider.Name())
 			}
-		} else {
-			slog.Warn("memory embeddings disabled (no API key), chunks stored without vectors")
+			return embProvider
 		}
+		slog.Warn("memory embeddings disabled (no API key), chunks stored without vectors")
 	}
+	return nil
 }
 
 // seedSystemConfigs ensures system_configs has all expected keys for all tenants.
@@ -113,6 +113,9 @@ func seedConfigForContext(ctx context.Context, sc store.SystemConfigStore, cfg *
 	// Background workers
 	set("background.provider", cfg.Gateway.BackgroundProvider)
 	set("background.model", cfg.Gateway.BackgroundModel)
+	if cfg.Gateway.BackgroundBatch != nil {
+		set("background.batch.enabled", fmt.Sprintf("%t", cfg.Gateway.BackgroundBatch.Enabled))
+	}
 
 	// Tools
 	set("tools.profile", cfg.Tools.Profile)
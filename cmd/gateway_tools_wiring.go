@@ -118,7 +118,13 @@ func wireExtraTools(
 			pa.AllowPaths(userAllowPaths...)
 		}
 	}
-	// Write and edit tools also get user-configured allowed paths for cross-drive access.
+	if searchTool, ok := toolsReg.Get("search_files"); ok {
+		if pa, ok := searchTool.(tools.PathAllowable); ok {
+			pa.AllowPaths(skillsAllowPaths...)
+			pa.AllowPaths(userAllowPaths...)
+		}
+	}
+	// Write, edit and apply_patch tools also get user-configured allowed paths for cross-drive access.
 	if writeTool, ok := toolsReg.Get("write_file"); ok {
 		if pa, ok := writeTool.(tools.PathAllowable); ok {
 			pa.AllowPaths(userAllowPaths...)
@@ -129,6 +135,11 @@ func wireExtraTools(
 			pa.AllowPaths(userAllowPaths...)
 		}
 	}
+	if patchTool, ok := toolsReg.Get("apply_patch"); ok {
+		if pa, ok := patchTool.(tools.PathAllowable); ok {
+			pa.AllowPaths(userAllowPaths...)
+		}
+	}
 	if sendFileTool, ok := toolsReg.Get("send_file"); ok {
 		if pa, ok := sendFileTool.(tools.PathAllowable); ok {
 			pa.AllowPaths(skillsAllowPaths...)
@@ -150,11 +161,18 @@ func wireExtraTools(
 			}
 		}
 	}
-	// Wire BusAware on message tool
+	// Wire BusAware on message tool, plus the session store and merged
+	// contacts used by the handoff action.
 	if t, ok := toolsReg.Get("message"); ok {
 		if ba, ok := t.(tools.BusAware); ok {
 			ba.SetMessageBus(msgBus)
 		}
+		if sa, ok := t.(tools.SessionStoreAware); ok {
+			sa.SetSessionStore(pgStores.Sessions)
+		}
+		if la, ok := t.(tools.LinkedContactListerAware); ok && pgStores.Contacts != nil {
+			la.SetLinkedContactLister(pgStores.Contacts.GetContactsByTenantUserID)
+		}
 	}
 
 	return heartbeatTool, hasMemory
@@ -15,7 +15,7 @@ import (
 // wireVault wires Knowledge Vault tools and interceptors into the tool registry.
 // Returns the shared VaultInterceptor for use by other subsystems (e.g. agent upload hook).
 // Returns nil if stores.Vault is nil.
-func wireVault(stores *store.Stores, toolsReg *tools.Registry, workspace string, bus eventbus.DomainEventBus) *tools.VaultInterceptor {
+func wireVault(stores *store.Stores, toolsReg *tools.Registry, workspace string, bus eventbus.DomainEventBus, reranker store.Reranker) *tools.VaultInterceptor {
 	if stores.Vault == nil {
 		return nil
 	}
@@ -41,6 +41,7 @@ func wireVault(stores *store.Stores, toolsReg *tools.Registry, workspace string,
 	// Build VaultSearchService: fan-out across vault + episodic + KG.
 	// Each store is nil-safe inside the service (skipped when absent).
 	searchSvc := vault.NewVaultSearchService(stores.Vault, stores.Episodic, stores.KnowledgeGraph)
+	searchSvc.SetReranker(reranker)
 	vaultSearchTool.SetSearchService(searchSvc)
 
 	// Build shared VaultInterceptor for read/write tool vault registration.
@@ -86,7 +87,12 @@ func wireVault(stores *store.Stores, toolsReg *tools.Registry, workspace string,
 			et.SetVaultInterceptor(vaultIntc)
 		}
 	}
+	if patchTool, ok := toolsReg.Get("apply_patch"); ok {
+		if pt, ok := patchTool.(*tools.ApplyPatchTool); ok {
+			pt.SetVaultInterceptor(vaultIntc)
+		}
+	}
 
-	slog.Info("vault tools registered", "tools", "vault_search,vault_read,create_image,create_video,create_audio,tts,edit")
+	slog.Info("vault tools registered", "tools", "vault_search,vault_read,create_image,create_video,create_audio,tts,edit,apply_patch")
 	return vaultIntc
 }
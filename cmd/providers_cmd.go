@@ -126,6 +126,7 @@ func runProvidersAdd() {
 		{"OpenRouter", "openrouter"},
 		{"DashScope (Alibaba)", "dashscope"},
 		{"OpenAI-compatible", "openai-compat"},
+		{"AWS Bedrock", "bedrock"},
 	}
 	providerType, err := promptSelect("Provider type", typeOptions, 0)
 	if err != nil {
@@ -140,6 +141,12 @@ func runProvidersAdd() {
 		return
 	}
 
+	// Bedrock: region + AWS credentials instead of a single API key.
+	if providerType == "bedrock" {
+		runProvidersAddBedrock(name)
+		return
+	}
+
 	// Step 3: API key
 	apiKey, err := promptPassword("API key", "will be encrypted at rest")
 	if err != nil || apiKey == "" {
@@ -168,6 +175,51 @@ func runProvidersAdd() {
 		body["base_url"] = baseURL
 	}
 
+	createProviderAndVerify(name, providerType, body)
+}
+
+// runProvidersAddBedrock collects the region and credentials for an AWS Bedrock
+// provider. Credentials are optional: with none, the gateway falls back to the
+// AWS default chain (env vars, shared profile, instance/task role).
+func runProvidersAddBedrock(name string) {
+	region, err := promptString("AWS region", "e.g. us-east-1, eu-central-1", "us-east-1")
+	if err != nil || region == "" {
+		fmt.Println("Cancelled.")
+		return
+	}
+	accessKeyID, err := promptString("Access key ID", "leave empty to use a Bedrock API key or the AWS default credential chain", "")
+	if err != nil {
+		fmt.Println("Cancelled.")
+		return
+	}
+	secretPrompt := "Bedrock API key (optional)"
+	if accessKeyID != "" {
+		secretPrompt = "Secret access key"
+	}
+	secret, err := promptPassword(secretPrompt, "will be encrypted at rest")
+	if err != nil || (accessKeyID != "" && secret == "") {
+		fmt.Println("Cancelled.")
+		return
+	}
+
+	settings := map[string]any{"region": region}
+	if accessKeyID != "" {
+		settings["access_key_id"] = accessKeyID
+	}
+	body := map[string]any{
+		"name":          name,
+		"provider_type": "bedrock",
+		"enabled":       true,
+		"settings":      settings,
+	}
+	if secret != "" {
+		body["api_key"] = secret
+	}
+	createProviderAndVerify(name, "bedrock", body)
+}
+
+// createProviderAndVerify POSTs the provider and offers a connection check.
+func createProviderAndVerify(name, providerType string, body map[string]any) {
 	resp, err := gatewayHTTPPost("/v1/providers", body)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating provider: %v\n", err)
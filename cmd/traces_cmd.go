@@ -15,6 +15,7 @@ func tracesCmd() *cobra.Command {
 	cmd.AddCommand(tracesExportCmd())
 	cmd.AddCommand(tracesFollowCmd())
 	cmd.AddCommand(tracesTimelineCmd())
+	cmd.AddCommand(tracesReplayCmd())
 	return cmd
 }
 
@@ -96,6 +97,26 @@ func tracesTimelineCmd() *cobra.Command {
 	return cmd
 }
 
+func tracesReplayCmd() *cobra.Command {
+	var inspect, asTraceUser bool
+	cmd := &cobra.Command{
+		Use:   "replay <trace-id>",
+		Short: "Re-run a recorded trace with its archived LLM responses",
+		Long: "Re-runs the trace's agent on the gateway, serving every LLM call and tool\n" +
+			"result from the trace's recording. No model is called and no tool executes.\n" +
+			"Runs as your own user unless --as-trace-user is set.\n" +
+			"Requires recording to be enabled on the agent when the trace ran.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			requireRunningGatewayHTTP()
+			return runTracesReplay(args[0], inspect, asTraceUser)
+		},
+	}
+	cmd.Flags().BoolVar(&inspect, "inspect", false, "list the recorded calls without replaying")
+	cmd.Flags().BoolVar(&asTraceUser, "as-trace-user", false, "run as the trace's user (their context files and memory) instead of yourself")
+	return cmd
+}
+
 func addTraceListFlags(cmd *cobra.Command, opts *traceListOptions) {
 	cmd.Flags().StringVarP(&opts.Query, "query", "q", "", "search trace text, IDs, labels, and span previews")
 	cmd.Flags().StringVar(&opts.AgentID, "agent-id", "", "filter by agent UUID")
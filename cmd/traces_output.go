@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
 )
 
 func outputFormatIsJSON() bool {
@@ -133,6 +135,70 @@ func printTraceTimeline(resp traceTimelineResponse) error {
 	return tw.Flush()
 }
 
+func printTraceRecording(rec traceRecordingForCLI) error {
+	if outputFormatIsJSON() {
+		return printJSON(rec)
+	}
+	if rec.Run != nil {
+		fmt.Printf("Input: %s\n\n", truncateStr(rec.Run.Input, 120))
+	}
+	if len(rec.Calls) == 0 {
+		fmt.Println("No recorded calls.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tPROVIDER\tMODEL\tMSGS\tDURATION\tRESULT\tSTARTED")
+	for i, call := range rec.Calls {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%dms\t%s\t%s\n",
+			i+1,
+			call.Provider,
+			call.Model,
+			len(call.Request.Messages),
+			call.DurationMS,
+			truncateStr(recordedCallResult(call), 60),
+			formatTraceTime(call.StartedAt),
+		)
+	}
+	return tw.Flush()
+}
+
+func recordedCallResult(call providers.RecordedCall) string {
+	switch {
+	case call.Error != "":
+		return "error: " + call.Error
+	case call.Response == nil:
+		return "-"
+	case len(call.Response.ToolCalls) > 0:
+		names := make([]string, 0, len(call.Response.ToolCalls))
+		for _, tc := range call.Response.ToolCalls {
+			names = append(names, tc.Name)
+		}
+		return "tools: " + strings.Join(names, ", ")
+	default:
+		return call.Response.Content
+	}
+}
+
+func printTraceReplay(resp traceReplayResponse) error {
+	if outputFormatIsJSON() {
+		return printJSON(resp)
+	}
+	fmt.Printf("Source:  %s\n", resp.SourceTraceID)
+	fmt.Printf("Replay:  %s\n", firstNonEmpty(resp.TraceID, "-"))
+	fmt.Printf("Run:     %s\n", resp.RunID)
+	if resp.UserID != "" {
+		fmt.Printf("User:    %s\n", resp.UserID)
+	}
+	fmt.Printf("Calls:   %d/%d served, %d diverged\n", resp.Replay.Served, resp.Replay.Recorded, resp.Replay.Diverged)
+	if resp.Error != "" {
+		fmt.Printf("Error:   %s\n", resp.Error)
+	}
+	if resp.Content != "" {
+		fmt.Printf("\n%s\n", resp.Content)
+	}
+	return nil
+}
+
 func printGzipJSON(raw []byte) error {
 	gr, err := gzip.NewReader(bytes.NewReader(raw))
 	if err != nil {
@@ -6,12 +6,14 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
 type traceDataForCLI = store.TraceData
 type spanDataForCLI = store.SpanData
 type timelineItemForCLI = store.RunTimelineItem
+type traceRecordingForCLI = providers.Recording
 
 type traceListOptions struct {
 	Query           string
@@ -82,6 +84,17 @@ type traceTimelineResponse struct {
 	Offset     int                  `json:"offset"`
 }
 
+type traceReplayResponse struct {
+	SourceTraceID string                `json:"source_trace_id"`
+	TraceID       string                `json:"trace_id,omitempty"`
+	RunID         string                `json:"run_id"`
+	UserID        string                `json:"user_id,omitempty"`
+	SessionKey    string                `json:"session_key"`
+	Content       string                `json:"content"`
+	Error         string                `json:"error,omitempty"`
+	Replay        providers.ReplayStats `json:"replay"`
+}
+
 func buildTraceListPath(opts traceListOptions) string {
 	values := url.Values{}
 	addQuery(values, "q", opts.Query)
@@ -141,6 +154,14 @@ func traceExportPath(traceID string) string {
 	return "/v1/traces/" + url.PathEscape(traceID) + "/export"
 }
 
+func traceRecordingPath(traceID string) string {
+	return "/v1/traces/" + url.PathEscape(traceID) + "/recording"
+}
+
+func traceReplayPath(traceID string) string {
+	return "/v1/traces/" + url.PathEscape(traceID) + "/replay"
+}
+
 func traceRunIDFromDetail(detail traceDetailResponse) (string, error) {
 	runID := strings.TrimSpace(detail.Trace.RunID)
 	if runID == "" {
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -91,3 +92,39 @@ func runTracesTimeline(traceID string, opts traceTimelineOptions) error {
 	}
 	return printTraceTimeline(resp)
 }
+
+func runTracesReplay(traceID string, inspect, asTraceUser bool) error {
+	if err := validateTraceOutputFormat(); err != nil {
+		return err
+	}
+	if inspect {
+		raw, status, err := gatewayHTTPDoRawWithLimit(http.MethodGet, traceRecordingPath(traceID), nil, traceExportResponseLimit)
+		if err != nil {
+			return err
+		}
+		if status >= 400 {
+			return parseHTTPError(raw, status)
+		}
+		var rec traceRecordingForCLI
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			return fmt.Errorf("unmarshal response: %w", err)
+		}
+		return printTraceRecording(rec)
+	}
+	var body any
+	if asTraceUser {
+		body = map[string]bool{"as_trace_user": true}
+	}
+	raw, status, err := gatewayHTTPDoRawWithClient(runClient, http.MethodPost, traceReplayPath(traceID), body, gatewayHTTPResponseLimit)
+	if err != nil {
+		return err
+	}
+	if status >= 400 {
+		return parseHTTPError(raw, status)
+	}
+	var resp traceReplayResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return fmt.Errorf("unmarshal response: %w", err)
+	}
+	return printTraceReplay(resp)
+}
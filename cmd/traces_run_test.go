@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"compress/gzip"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -124,6 +125,61 @@ func TestRunTracesFollowJSONOutput(t *testing.T) {
 	}
 }
 
+func TestRunTracesReplayPostsAndInspects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v1/traces/trace-1/replay":
+			var body map[string]bool
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || !body["as_trace_user"] {
+				t.Errorf("replay body = %v, %v; want as_trace_user", body, err)
+			}
+			_, _ = w.Write([]byte(`{
+				"source_trace_id": "trace-1",
+				"trace_id": "trace-2",
+				"run_id": "run-2",
+				"content": "done",
+				"replay": {"recorded": 2, "served": 2, "diverged": 0}
+			}`))
+		case "GET /v1/traces/trace-1/recording":
+			_, _ = w.Write([]byte(`{
+				"trace_key": "trace-1",
+				"run": {"input": "read a.txt", "recorded_at": "2026-06-12T01:00:00Z"},
+				"calls": [
+					{"provider": "openai", "model": "gpt-test", "fingerprint": "f1", "request": {"messages": []},
+					 "response": {"content": "", "finish_reason": "tool_calls", "tool_calls": [{"id": "c1", "name": "read_file", "arguments": {}}]},
+					 "started_at": "2026-06-12T01:00:00Z", "duration_ms": 12}
+				]
+			}`))
+		default:
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	withTraceTestGateway(t, srv)
+
+	gatewayOutputFormat = "table"
+	out, err := captureStdout(t, func() error {
+		return runTracesReplay("trace-1", false, true)
+	})
+	if err != nil {
+		t.Fatalf("runTracesReplay: %v", err)
+	}
+	if !strings.Contains(out, "2/2 served, 0 diverged") || !strings.Contains(out, "done") {
+		t.Fatalf("replay output: %s", out)
+	}
+
+	out, err = captureStdout(t, func() error {
+		return runTracesReplay("trace-1", true, false)
+	})
+	if err != nil {
+		t.Fatalf("runTracesReplay --inspect: %v", err)
+	}
+	if !strings.Contains(out, "read a.txt") || !strings.Contains(out, "tools: read_file") {
+		t.Fatalf("inspect output: %s", out)
+	}
+}
+
 func withTraceTestGateway(t *testing.T, srv *httptest.Server) {
 	t.Helper()
 	oldServer := gatewayServerOverride
@@ -188,10 +188,14 @@ func formatToolLabel(tool string) string {
 	switch {
 	case strings.HasPrefix(tool, "web"):
 		return "web search"
-	case tool == "exec":
+	case tool == "exec" || tool == "code_interpreter":
 		return "code execution"
 	case tool == "browser":
 		return "browser"
+	case tool == "http_request":
+		return "API request"
+	case tool == "sql_query":
+		return "database query"
 	case tool == "spawn":
 		return "delegation"
 	case strings.HasPrefix(tool, "memory"):
@@ -113,6 +113,11 @@ func (l *Loop) injectContext(ctx context.Context, req *RunRequest) (contextSetup
 		waitToolCfg = l.agentToolPolicy.Wait
 		ctx = tools.WithWaitToolConfig(ctx, waitToolCfg)
 	}
+	var httpRequestToolCfg *config.HTTPRequestToolPolicy
+	if l.agentToolPolicy != nil && l.agentToolPolicy.HTTPRequest != nil {
+		httpRequestToolCfg = l.agentToolPolicy.HTTPRequest
+		ctx = tools.WithHTTPRequestToolConfig(ctx, httpRequestToolCfg)
+	}
 	if l.sandboxCfg != nil {
 		ctx = tools.WithSandboxConfig(ctx, l.sandboxCfg)
 	}
@@ -398,6 +403,7 @@ func (l *Loop) injectContext(ctx context.Context, req *RunRequest) (contextSetup
 		MemoryCfg:           l.memoryCfg,
 		SandboxCfg:          l.sandboxCfg,
 		WaitToolCfg:         waitToolCfg,
+		HTTPRequestToolCfg:  httpRequestToolCfg,
 		ShellDenyGroups:     l.shellDenyGroups,
 		Workspace:           tools.ToolWorkspaceFromCtx(ctx),
 		TeamWorkspace:       tools.ToolTeamWorkspaceFromCtx(ctx),
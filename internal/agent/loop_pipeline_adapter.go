@@ -298,6 +298,7 @@ func (l *Loop) makeAutoInjectCallback(req *RunRequest) func(ctx context.Context,
 			TenantID:      store.TenantIDFromContext(ctx).String(),
 			UserMessage:   userMessage,
 			RecentContext: recentContext,
+			RerankTopK:    l.memoryCfg.RerankTopK(),
 		})
 		if err != nil || result == nil {
 			return "", err
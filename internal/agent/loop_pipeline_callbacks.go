@@ -53,7 +53,7 @@ func (l *Loop) pipelineCallbacks(req *RunRequest, bridgeRS *runState) pipelineCa
 		pruneMessages:      l.makePruneMessages(),
 		sanitizeHistory:    sanitizeHistory,
 		compactMessages:    l.makeCompactMessages(req),
-		runMemoryFlush:     l.makeRunMemoryFlush(),
+		runMemoryFlush:     l.makeRunMemoryFlush(req),
 		executeToolCall:    l.makeExecuteToolCall(req, bridgeRS),
 		executeToolRaw:     l.makeExecuteToolRaw(req),
 		processToolResult:  l.makeProcessToolResult(req, bridgeRS),
@@ -390,7 +390,7 @@ func (l *Loop) makeCallLLM(req *RunRequest, emitRun func(AgentEvent)) func(ctx c
 						} else {
 							opts = append(opts, withProvider(entry.ProviderName), withModel(actualReq.Model))
 						}
-						opts = append(opts, withModelFallbackAttempt(fallbackMeta))
+						opts = append(opts, withModelFallbackAttempt(fallbackMeta), withRouteDecision(info.Route))
 						if reservation != nil {
 							if info.Streamed {
 								reservation.ReconcileStream(callCtx, callResp, callErr, true)
@@ -577,10 +577,11 @@ func (l *Loop) markCacheTouched(sessionKey string) {
 	l.cacheTouchBySession.Store(sessionKey, time.Now())
 }
 
-func (l *Loop) makeRunMemoryFlush() func(ctx context.Context, state *pipeline.RunState) error {
+func (l *Loop) makeRunMemoryFlush(req *RunRequest) func(ctx context.Context, state *pipeline.RunState) error {
 	return func(ctx context.Context, state *pipeline.RunState) error {
 		settings := ResolveMemoryFlushSettings(l.compactionCfg)
-		if settings == nil {
+		if settings == nil || req.ToolOverride != nil {
+			// Flush executes memory tools directly; skip it when tools are overridden.
 			return nil
 		}
 		l.runMemoryFlush(ctx, state.Input.SessionKey, settings)
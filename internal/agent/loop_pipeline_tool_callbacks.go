@@ -47,12 +47,7 @@ func (l *Loop) makeExecuteToolCall(req *RunRequest, bridgeRS *runState) func(ctx
 		}
 		ctx = store.WithChannelContextScope(ctx, channelContextScopeForRun(req))
 
-		// C2 fix: route through executeToolForActor so per-user MCP tools
-		// resolve to the calling user's BridgeTool (not the first user's
-		// BridgeTool leaked via shared registry).
-		actorUserID := resolveActorUserID(req.UserID, req.SenderID, req.PeerKind, req.ChannelType)
-		result := l.executeToolForActor(ctx, registryName, tc.Arguments,
-			req.Channel, req.ChatID, req.PeerKind, req.SessionKey, actorUserID)
+		result := l.runToolCall(ctx, req, registryName, tc)
 		toolDuration := time.Since(toolStart)
 
 		l.emitToolSpanEnd(ctx, toolSpanID, toolStart, result)
@@ -118,11 +113,7 @@ func (l *Loop) makeExecuteToolRaw(req *RunRequest) func(ctx context.Context, tc
 		}
 		ctx = store.WithChannelContextScope(ctx, channelContextScopeForRun(req))
 
-		// C2 fix (parallel path): route through executeToolForActor for per-user
-		// MCP tool isolation. Same rationale as makeExecuteToolCall above.
-		actorUserID := resolveActorUserID(req.UserID, req.SenderID, req.PeerKind, req.ChannelType)
-		result := l.executeToolForActor(ctx, registryName, tc.Arguments,
-			req.Channel, req.ChatID, req.PeerKind, req.SessionKey, actorUserID)
+		result := l.runToolCall(ctx, req, registryName, tc)
 		dur := time.Since(start)
 
 		// Emit tool span end inside goroutine to prevent orphaned spans on ctx cancellation.
@@ -138,6 +129,20 @@ func (l *Loop) makeExecuteToolRaw(req *RunRequest) func(ctx context.Context, tc
 	}
 }
 
+// runToolCall executes one tool call for the run, or answers it from
+// req.ToolOverride when set.
+func (l *Loop) runToolCall(ctx context.Context, req *RunRequest, registryName string, tc providers.ToolCall) *tools.Result {
+	if req.ToolOverride != nil {
+		return req.ToolOverride(ctx, tc)
+	}
+	// C2 fix: route through executeToolForActor so per-user MCP tools
+	// resolve to the calling user's BridgeTool (not the first user's
+	// BridgeTool leaked via shared registry).
+	actorUserID := resolveActorUserID(req.UserID, req.SenderID, req.PeerKind, req.ChannelType)
+	return l.executeToolForActor(ctx, registryName, tc.Arguments,
+		req.Channel, req.ChatID, req.PeerKind, req.SessionKey, actorUserID)
+}
+
 func channelContextScopeForRun(req *RunRequest) store.ChannelContextScope {
 	if req == nil || req.Channel == "" {
 		return store.ChannelContextScope{}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 	"github.com/nextlevelbuilder/goclaw/internal/tools"
 	"github.com/nextlevelbuilder/goclaw/internal/tracing"
@@ -101,6 +102,7 @@ func (l *Loop) Run(ctx context.Context, req RunRequest) (*RunResult, error) {
 		} else {
 			ctx = tracing.WithTraceID(ctx, traceID)
 			ctx = tracing.WithCollector(ctx, l.traceCollector)
+			ctx = providers.WithRecordingInput(ctx, req.Message)
 			if trace.TeamID != nil {
 				ctx = tracing.WithTraceTeamID(ctx, *trace.TeamID)
 			}
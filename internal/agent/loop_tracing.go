@@ -56,6 +56,7 @@ type spanOverrides struct {
 	provider              string
 	usageCapAttempts      []usagecaps.TraceMetadata
 	modelFallbackAttempts []providers.ModelFallbackAttemptMetadata
+	routeDecision         *providers.RouteDecision
 }
 
 func withModel(m string) spanOption    { return func(o *spanOverrides) { o.model = m } }
@@ -76,6 +77,14 @@ func withModelFallbackAttempt(metadata providers.ModelFallbackAttemptMetadata) s
 	}
 }
 
+func withRouteDecision(decision *providers.RouteDecision) spanOption {
+	return func(o *spanOverrides) {
+		if decision != nil {
+			o.routeDecision = decision
+		}
+	}
+}
+
 // resolveSpan returns (model, provider) applying any overrides on top of agent defaults.
 func (l *Loop) resolveSpan(opts []spanOption) (string, string) {
 	o := l.resolveSpanOverrides(opts)
@@ -213,6 +222,9 @@ func (l *Loop) emitLLMSpanEnd(ctx context.Context, spanID uuid.UUID, start time.
 			}
 		}
 		updates["finish_reason"] = resp.FinishReason
+		if resp.CacheHit != nil {
+			spanMetadata = providers.MergeResponseCacheMetadata(spanMetadata, *resp.CacheHit)
+		}
 		limit := previewLimitForVerbose(collector.Verbose())
 		preview := resp.Content
 		if resp.Thinking != "" {
@@ -238,6 +250,9 @@ func (l *Loop) emitLLMSpanEnd(ctx context.Context, spanID uuid.UUID, start time.
 	if len(spanOpts.modelFallbackAttempts) > 0 {
 		spanMetadata = providers.MergeModelFallbackMetadata(spanMetadata, spanOpts.modelFallbackAttempts)
 	}
+	if spanOpts.routeDecision != nil {
+		spanMetadata = providers.MergeRouteDecisionMetadata(spanMetadata, *spanOpts.routeDecision)
+	}
 	if len(spanMetadata) > 0 {
 		updates["metadata"] = spanMetadata
 	}
@@ -646,6 +646,11 @@ type RunRequest struct {
 	// user follow-up messages into the running conversation.
 	InjectCh <-chan InjectedMessage
 
+	// ToolOverride, when set, answers every tool call instead of executing it
+	// and disables memory flush, which runs tools directly. Trace replay uses
+	// it to serve recorded tool results. Nil = execute tools normally.
+	ToolOverride func(ctx context.Context, tc providers.ToolCall) *tools.Result
+
 	// OnTraceCreated is called once the trace UUID is determined for this run.
 	// Used by the gateway to associate the trace ID with the active run entry
 	// so force-abort can mark the correct trace as cancelled. Nil = no-op.
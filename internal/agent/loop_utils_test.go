@@ -297,7 +297,7 @@ func TestAgentToolPolicyWithWorkspace_WithTeam_InjectsFileTools(t *testing.T) {
 	if got == nil {
 		t.Fatal("with team should return non-nil policy")
 	}
-	required := []string{"read_file", "write_file", "list_files"}
+	required := []string{"read_file", "write_file", "list_files", "search_files"}
 	for _, tool := range required {
 		found := false
 		for _, a := range got.AlsoAllow {
@@ -331,7 +331,7 @@ func defaultTeamGuidance() string {
 
 // fallbackPreviewToolNames used when tool registry is not available.
 var fallbackPreviewToolNames = []string{
-	"read_file", "write_file", "list_files", "edit", "exec",
+	"read_file", "write_file", "list_files", "search_files", "edit", "apply_patch", "exec",
 	"memory_search", "memory_get", "spawn",
 	"web_search", "web_fetch", "skill_search", "use_skill",
 	"datetime", "cron",
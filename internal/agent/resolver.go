@@ -42,6 +42,8 @@ type ResolverDeps struct {
 	HasMemory      bool
 	OnEvent        func(AgentEvent)
 	TraceCollector *tracing.Collector
+	ResponseCache  *providers.ResponseCacheStore // nil = per-agent response cache unavailable
+	Recordings     *providers.RecordingArchive   // nil = provider call recording unavailable
 
 	// Per-user profile + file seeding + dynamic context loading
 	EnsureUserProfile EnsureUserProfileFunc
@@ -164,7 +166,12 @@ func NewManagedResolver(deps ResolverDeps) ResolverFunc {
 		}
 
 		// Resolve provider (tenant-aware: tries tenant-specific first, falls back to master)
-		provider, err := providerresolve.ResolveAgentProvider(deps.ProviderReg, ag)
+		provider, err := providerresolve.ResolveAgentProvider(deps.ProviderReg, ag, providerresolve.AgentProviderOptions{
+			ResponseCache: deps.ResponseCache,
+			Recordings:    deps.Recordings,
+			ModelRegistry: deps.ModelRegistry,
+			Pricing:       routePricing(ctx, deps, ag.TenantID),
+		})
 		if err != nil {
 			// Fallback to any available provider for this tenant
 			names := deps.ProviderReg.ListForTenant(ag.TenantID)
@@ -615,6 +622,20 @@ func resolveTenantSlug(ts store.TenantStore, tenantID uuid.UUID) string {
 	return tenant.Slug
 }
 
+// routePricing resolves model prices for cost-aware routing: the tenant's
+// usage pricing catalog first, then config.json model_pricing.
+func routePricing(ctx context.Context, deps ResolverDeps, tenantID uuid.UUID) func(providerName, model string) (float64, float64, bool) {
+	return func(providerName, model string) (float64, float64, bool) {
+		if in, out, ok := deps.UsageCaps.TokenRates(store.WithTenantID(ctx, tenantID), tenantID, providerName, model); ok {
+			return in, out, true
+		}
+		if p := tracing.LookupPricing(deps.ModelPricing, providerName, model); p != nil {
+			return p.InputPerMillion, p.OutputPerMillion, true
+		}
+		return 0, 0, false
+	}
+}
+
 func derefInt(p *int) int {
 	if p == nil {
 		return 0
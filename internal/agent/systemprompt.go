@@ -185,12 +185,16 @@ var coreToolSummaries = map[string]string{
 	"write_file":             "Create or overwrite files (set deliver=true to also send as chat attachment)",
 	"send_file":              "Send an EXISTING workspace file as a chat attachment — use to resend/share files; does NOT create or modify the file (use write_file for that)",
 	"list_files":             "List directory contents",
+	"search_files":           "Regex search across workspace files (ripgrep-style; glob filters, context lines, respects .gitignore)",
 	"exec":                   "Run shell commands",
+	"code_interpreter":       "Run Python/JavaScript in a persistent sandbox kernel (state kept between calls; plots saved to interpreter/)",
 	"memory_search":          "Search indexed memory files (MEMORY.md + memory/*.md)",
 	"memory_get":             "Read specific sections of memory files",
 	"spawn":                  "Spawn a self-clone subagent to handle a task in the background",
 	"web_search":             "Search the web",
 	"web_fetch":              "Fetch and extract content from a URL",
+	"sql_query":              "Read-only SQL on granted Postgres connections or workspace SQLite files (list_tables/describe_schema first)",
+	"http_request":           "Call REST APIs (any method, JSON body, pagination); pass auth=<profile> for credentials instead of embedding tokens",
 	"datetime":               "Get current date/time with timezone — use before creating cron jobs",
 	"cron":                   "Manage scheduled jobs and reminders (e.g. 'remind me at 9am', 'check every morning')",
 	"heartbeat":              "Periodic background monitoring with HEARTBEAT.md. Unlike cron, auto-suppresses 'all OK' via HEARTBEAT_OK",
@@ -202,6 +206,7 @@ var coreToolSummaries = map[string]string{
 	"browser":                "Browse web pages interactively",
 	"tts":                    "Convert text to speech audio",
 	"edit":                   "Edit a file by replacing exact text matches",
+	"apply_patch":            "Apply a unified diff or *** Begin Patch block across multiple files (all-or-nothing, supports dry_run)",
 	"message":                "Send a PROACTIVE message to another channel/chat — do NOT use this to reply to the user, just respond directly",
 	"sessions_list":          "List sessions for this agent",
 	"session_status":         "Show session status (model, tokens, compaction count)",
@@ -767,7 +772,7 @@ func buildWorkspaceSection(workspace string, sandboxEnabled bool, containerDir s
 	if sandboxEnabled && containerDir != "" {
 		displayDir = containerDir
 		guidance = fmt.Sprintf(
-			"For read_file/write_file/list_files, file paths resolve against host workspace: %s. "+
+			"For read_file/write_file/list_files/search_files, file paths resolve against host workspace: %s. "+
 				"Prefer relative paths so both sandboxed exec and file tools work consistently.",
 			workspace,
 		)
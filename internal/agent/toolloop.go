@@ -37,7 +37,7 @@ const (
 // increments the read-only streak.
 // team_tasks is excluded: action-level classification in recordMutation.
 var mutatingTools = map[string]bool{
-	"write_file": true, "edit": true, "edit_file": true,
+	"write_file": true, "edit": true, "edit_file": true, "apply_patch": true,
 	"spawn": true, "message": true,
 	"create_image": true, "create_video": true, "create_audio": true,
 	"tts": true, "cron": true, "publish_skill": true,
@@ -167,11 +167,12 @@ func (s *toolLoopState) recordMutation(toolName string, args map[string]any) {
 		s.resetStreak()
 		return
 	}
-	// exec/bash: ambiguous (could be ls or rm).
+	// exec/bash/code_interpreter: ambiguous (could be ls or rm).
+	// http_request: ambiguous (GET reads, POST/DELETE mutate).
 	// wait: intentional delay, neither progress nor read-only scanning.
 	// mcp_*: user-defined external tools — GoClaw cannot determine read vs write.
 	// Neither reset nor increment the read-only streak.
-	if toolName == "exec" || toolName == "bash" || toolName == "wait" || strings.HasPrefix(toolName, "mcp_") {
+	if toolName == "exec" || toolName == "bash" || toolName == "code_interpreter" || toolName == "http_request" || toolName == "wait" || strings.HasPrefix(toolName, "mcp_") {
 		return
 	}
 	s.incrementReadOnly(toolName, args)
@@ -0,0 +1,117 @@
+// Package bm25 implements a minimal in-memory Okapi BM25 index. It is shared
+// by MCP tool search and the local memory reranker.
+package bm25
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Index scores a fixed set of documents against free-text queries.
+type Index struct {
+	docs  [][]string
+	df    map[string]int
+	avgDL float64
+	k1    float64
+	b     float64
+}
+
+// New returns an empty index with the standard parameters (k1=1.2, b=0.75).
+func New() *Index {
+	return &Index{
+		df: make(map[string]int),
+		k1: 1.2,
+		b:  0.75,
+	}
+}
+
+// Build replaces the indexed documents with texts (tokenized with Tokenize).
+func (idx *Index) Build(texts []string) {
+	idx.docs = make([][]string, 0, len(texts))
+	idx.df = make(map[string]int)
+	idx.avgDL = 0
+
+	totalTokens := 0
+	for _, text := range texts {
+		tokens := Tokenize(text)
+		idx.docs = append(idx.docs, tokens)
+
+		seen := make(map[string]bool)
+		for _, t := range tokens {
+			if !seen[t] {
+				idx.df[t]++
+				seen[t] = true
+			}
+		}
+		totalTokens += len(tokens)
+	}
+
+	if len(idx.docs) > 0 {
+		idx.avgDL = float64(totalTokens) / float64(len(idx.docs))
+	}
+}
+
+// Len returns the number of indexed documents.
+func (idx *Index) Len() int { return len(idx.docs) }
+
+// Score returns the BM25 score of every document for query, in index order.
+// Documents sharing no term with the query score 0. Returns nil for an empty
+// query or index.
+func (idx *Index) Score(query string) []float64 {
+	queryTokens := Tokenize(query)
+	if len(queryTokens) == 0 || len(idx.docs) == 0 || idx.avgDL == 0 {
+		return nil
+	}
+
+	N := float64(len(idx.docs))
+	scores := make([]float64, len(idx.docs))
+	for i, doc := range idx.docs {
+		dl := float64(len(doc))
+
+		tf := make(map[string]int)
+		for _, t := range doc {
+			tf[t]++
+		}
+
+		score := 0.0
+		for _, qt := range queryTokens {
+			termFreq := float64(tf[qt])
+			if termFreq == 0 {
+				continue
+			}
+
+			dfTerm := float64(idx.df[qt])
+			idf := math.Log((N-dfTerm+0.5)/(dfTerm+0.5) + 1)
+
+			numerator := termFreq * (idx.k1 + 1)
+			denominator := termFreq + idx.k1*(1-idx.b+idx.b*dl/idx.avgDL)
+			score += idf * numerator / denominator
+		}
+		scores[i] = score
+	}
+	return scores
+}
+
+// Tokenize splits text into lowercase letter/digit tokens, dropping
+// single-character tokens.
+func Tokenize(text string) []string {
+	lower := strings.ToLower(text)
+
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, lower)
+
+	fields := strings.Fields(cleaned)
+
+	var tokens []string
+	for _, f := range fields {
+		if len(f) > 1 {
+			tokens = append(tokens, f)
+		}
+	}
+	return tokens
+}
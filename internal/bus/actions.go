@@ -0,0 +1,93 @@
+package bus
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Action limits shared by all channels. MaxActionIDLen fits Telegram's
+// 64-byte callback_data after the channel's own prefix; MaxActions fits one
+// Discord message (5 rows of 5 buttons) and Slack's actions block.
+const (
+	MaxActions        = 10
+	MaxActionIDLen    = 56
+	MaxActionLabelLen = 40
+)
+
+// Action styles. An empty style renders as the platform's default button.
+const (
+	ActionStylePrimary = "primary"
+	ActionStyleDanger  = "danger"
+)
+
+// Reserved action ID prefixes handled by the gateway instead of the agent.
+// Agents cannot send actions with these prefixes (see ValidateActions).
+const (
+	ActionPrefixExec = "exec:" // exec:<decision>:<approval id>
+	ActionPrefixTask = "task:" // task:<approve|reject>:<task id>
+)
+
+// MessageAction is an interactive choice attached to an outbound message.
+// Interactive channels render actions natively (inline keyboards, buttons,
+// card actions) and report clicks back as an InboundMessage with Action set.
+// Other channels append the labels to the message text.
+type MessageAction struct {
+	ID    string `json:"id"`              // echoed back in ActionCallback.ActionID
+	Label string `json:"label"`           // button text
+	Style string `json:"style,omitempty"` // ActionStylePrimary, ActionStyleDanger or "" (default)
+	URL   string `json:"url,omitempty"`   // link button: opens the URL, never calls back
+}
+
+// ActionCallback describes a click on a MessageAction.
+type ActionCallback struct {
+	ActionID  string `json:"action_id"`
+	Label     string `json:"label,omitempty"`
+	MessageID string `json:"message_id,omitempty"` // platform ID of the message carrying the button
+}
+
+// IsReservedActionID reports whether id belongs to a gateway-handled action.
+func IsReservedActionID(id string) bool {
+	return strings.HasPrefix(id, ActionPrefixExec) || strings.HasPrefix(id, ActionPrefixTask)
+}
+
+// ValidateActions checks agent-supplied actions against the shared limits.
+// Reserved IDs are rejected so an agent cannot dress up an approval button.
+func ValidateActions(actions []MessageAction) error {
+	if len(actions) > MaxActions {
+		return fmt.Errorf("too many actions: %d (max %d)", len(actions), MaxActions)
+	}
+	seen := make(map[string]bool, len(actions))
+	for i, a := range actions {
+		if strings.TrimSpace(a.Label) == "" {
+			return fmt.Errorf("action %d: label is required", i)
+		}
+		if len(a.Label) > MaxActionLabelLen {
+			return fmt.Errorf("action %d: label longer than %d bytes", i, MaxActionLabelLen)
+		}
+		switch a.Style {
+		case "", ActionStylePrimary, ActionStyleDanger:
+		default:
+			return fmt.Errorf("action %d: unknown style %q", i, a.Style)
+		}
+		if a.URL != "" {
+			if !strings.HasPrefix(a.URL, "https://") && !strings.HasPrefix(a.URL, "http://") {
+				return fmt.Errorf("action %d: url must be http(s)", i)
+			}
+			continue
+		}
+		if a.ID == "" {
+			return fmt.Errorf("action %d: id or url is required", i)
+		}
+		if len(a.ID) > MaxActionIDLen {
+			return fmt.Errorf("action %d: id longer than %d bytes", i, MaxActionIDLen)
+		}
+		if IsReservedActionID(a.ID) {
+			return fmt.Errorf("action %d: id prefix %q is reserved", i, a.ID[:strings.IndexByte(a.ID, ':')+1])
+		}
+		if seen[a.ID] {
+			return fmt.Errorf("action %d: duplicate id %q", i, a.ID)
+		}
+		seen[a.ID] = true
+	}
+	return nil
+}
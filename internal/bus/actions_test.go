@@ -0,0 +1,51 @@
+package bus
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateActions(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions []MessageAction
+		wantErr string
+	}{
+		{"empty", nil, ""},
+		{"choices", []MessageAction{{ID: "yes", Label: "Yes", Style: ActionStylePrimary}, {ID: "no", Label: "No"}}, ""},
+		{"link without id", []MessageAction{{Label: "Docs", URL: "https://example.com"}}, ""},
+		{"missing label", []MessageAction{{ID: "a"}}, "label is required"},
+		{"missing id", []MessageAction{{Label: "A"}}, "id or url is required"},
+		{"bad style", []MessageAction{{ID: "a", Label: "A", Style: "success"}}, "unknown style"},
+		{"bad url", []MessageAction{{Label: "A", URL: "javascript:alert(1)"}}, "url must be http(s)"},
+		{"long id", []MessageAction{{ID: strings.Repeat("x", MaxActionIDLen+1), Label: "A"}}, "id longer"},
+		{"long label", []MessageAction{{ID: "a", Label: strings.Repeat("x", MaxActionLabelLen+1)}}, "label longer"},
+		{"duplicate", []MessageAction{{ID: "a", Label: "A"}, {ID: "a", Label: "B"}}, "duplicate"},
+		{"reserved exec", []MessageAction{{ID: "exec:allow-once:exec-1", Label: "Approve"}}, "reserved"},
+		{"reserved task", []MessageAction{{ID: "task:approve:1", Label: "Approve"}}, "reserved"},
+		{"too many", make([]MessageAction, MaxActions+1), "too many actions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateActions(tt.actions)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsReservedActionID(t *testing.T) {
+	if !IsReservedActionID("exec:deny:exec-3") || !IsReservedActionID("task:reject:abc") {
+		t.Fatal("expected gateway prefixes to be reserved")
+	}
+	if IsReservedActionID("option_1") || IsReservedActionID("executive") {
+		t.Fatal("expected agent IDs not to be reserved")
+	}
+}
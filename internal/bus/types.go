@@ -31,6 +31,7 @@ type InboundMessage struct {
 	UserID       string            `json:"user_id,omitempty"`       // external user ID for per-user scoping (memory, bootstrap)
 	HistoryLimit int               `json:"history_limit,omitempty"` // max turns to keep in context (0=unlimited, from channel config)
 	ToolAllow    []string          `json:"tool_allow,omitempty"`    // per-group tool allow list (nil = no restriction)
+	Action       *ActionCallback   `json:"action,omitempty"`        // set when the message is a click on a MessageAction
 	Metadata     map[string]string `json:"metadata,omitempty"`
 }
 
@@ -40,6 +41,7 @@ type OutboundMessage struct {
 	ChatID           string            `json:"chat_id"`
 	Content          string            `json:"content"`
 	Media            []MediaAttachment `json:"media,omitempty"`              // optional media attachments
+	Actions          []MessageAction   `json:"actions,omitempty"`            // optional buttons rendered by interactive channels
 	Metadata         map[string]string `json:"metadata,omitempty"`           // channel-specific metadata
 	TenantID         uuid.UUID         `json:"tenant_id,omitempty"`          // tenant scope for per-tenant TTS
 	AgentID          uuid.UUID         `json:"agent_id,omitempty"`           // agent scope for per-agent TTS voice override
@@ -51,42 +51,72 @@ func Extract(ctx context.Context, provider providers.Provider, model string, cap
 			{Role: "system", Content: extractionPrompt(allowed)},
 			{Role: "user", Content: sb.String()},
 		},
-		Options: map[string]any{"max_tokens": 1200, "temperature": 0.1},
+		Options:        map[string]any{"max_tokens": 1200, "temperature": 0.1},
+		ResponseFormat: providers.NewJSONSchemaFormat("channel_memory_items", extractionSchema),
 	}
-	var resp *providers.ChatResponse
-	var err error
+	var call providers.ChatFunc
 	if caps != nil {
-		resp, err = caps.Chat(ctx, provider, req, usagecaps.ChatOptions{
-			ModelID:         model,
-			Purpose:         "channel-memory-extraction",
-			MaxOutputTokens: 1200,
-		})
-	} else {
-		resp, err = provider.Chat(ctx, req)
+		call = func(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+			return caps.Chat(ctx, provider, req, usagecaps.ChatOptions{
+				ModelID:         model,
+				Purpose:         "channel-memory-extraction",
+				MaxOutputTokens: 1200,
+			})
+		}
 	}
+	resp, err := providers.ChatStructured(ctx, provider, req, call)
 	if err != nil {
 		return nil, err
 	}
 	return parseExtractionResponse(resp.Content)
 }
 
+// extractionSchema wraps extracted items in an object because structured-output
+// APIs require an object at the root.
+var extractionSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"items": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"type":       map[string]any{"type": "string"},
+					"summary":    map[string]any{"type": "string"},
+					"topics":     map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					"entities":   map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					"confidence": map[string]any{"type": "number"},
+				},
+				"required": []any{"type", "summary", "topics", "entities", "confidence"},
+			},
+		},
+	},
+	"required": []any{"items"},
+}
+
 func extractionPrompt(allowed []string) string {
 	return `Extract only durable, reusable work context from channel messages.
 Allowed item types: ` + strings.Join(allowed, ", ") + `.
 Never include secrets, credentials, tokens, payment data, private addresses, phone numbers, health/legal/financial sensitive details, casual chatter, jokes, or low-confidence guesses.
-Return strict JSON array only. Each item:
+Return strict JSON only: {"items":[...]}. Each item:
 {"type":"people|projects|decisions|todos|preferences|events","summary":"one concise redacted fact","topics":["..."],"entities":["..."],"confidence":0.0-1.0}
-If nothing durable remains, return [].`
+If nothing durable remains, return {"items":[]}.`
 }
 
+// parseExtractionResponse accepts the {"items": [...]} structured-output shape
+// as well as a bare array from models that ignore the wrapper.
 func parseExtractionResponse(content string) ([]ExtractedItem, error) {
-	raw := strings.TrimSpace(content)
-	raw = strings.TrimPrefix(raw, "```json")
-	raw = strings.TrimPrefix(raw, "```")
-	raw = strings.TrimSuffix(raw, "```")
-	raw = strings.TrimSpace(raw)
+	raw := providers.ExtractJSON(content)
 	var items []ExtractedItem
-	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+	if strings.HasPrefix(raw, "{") {
+		var wrapped struct {
+			Items []ExtractedItem `json:"items"`
+		}
+		if err := json.Unmarshal([]byte(raw), &wrapped); err != nil {
+			return nil, fmt.Errorf("parse extraction JSON: %w", err)
+		}
+		items = wrapped.Items
+	} else if err := json.Unmarshal([]byte(raw), &items); err != nil {
 		return nil, fmt.Errorf("parse extraction JSON: %w", err)
 	}
 	out := items[:0]
@@ -0,0 +1,101 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+)
+
+// ActionChannel is optionally implemented by channels that render
+// bus.MessageAction natively (inline keyboards, buttons, card actions) and
+// report clicks through BaseChannel.HandleAction. Actions sent to any other
+// channel are appended to the message text by the outbound dispatcher.
+type ActionChannel interface {
+	SupportsActions() bool
+}
+
+// SupportsActions reports whether the named channel renders actions natively.
+func (m *Manager) SupportsActions(channelName string) bool {
+	m.mu.RLock()
+	ch, ok := m.channels[channelName]
+	m.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	ac, ok := ch.(ActionChannel)
+	return ok && ac.SupportsActions()
+}
+
+// ActionRows splits actions into rows of at most perRow buttons, keeping
+// short choice lists (yes/no, approve/deny) on a single line.
+func ActionRows(actions []bus.MessageAction, perRow int) [][]bus.MessageAction {
+	var rows [][]bus.MessageAction
+	for len(actions) > 0 {
+		n := min(perRow, len(actions))
+		rows = append(rows, actions[:n])
+		actions = actions[n:]
+	}
+	return rows
+}
+
+// ActionsFallbackText renders actions as a plain list for channels without
+// interactive support: link actions keep their URL, choices become options
+// the user can reply with.
+func ActionsFallbackText(actions []bus.MessageAction) string {
+	if len(actions) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n")
+	for _, a := range actions {
+		if a.URL != "" {
+			fmt.Fprintf(&sb, "\n• %s: %s", a.Label, a.URL)
+		} else {
+			fmt.Fprintf(&sb, "\n• %s", a.Label)
+		}
+	}
+	return sb.String()
+}
+
+// ActionAllowed applies the channel's DM or group policy to a button click.
+// Unlike messages, clicks never trigger pairing replies: a sender who could
+// not have messaged the bot is simply ignored.
+func (c *BaseChannel) ActionAllowed(ctx context.Context, senderID, chatID, peerKind, dmPolicy, groupPolicy string) bool {
+	if peerKind == "group" {
+		return c.CheckGroupPolicy(ctx, senderID, chatID, groupPolicy) == PolicyAllow
+	}
+	return c.CheckDMPolicy(ctx, senderID, dmPolicy) == PolicyAllow
+}
+
+// HandleAction publishes a button click as an inbound message. The content
+// tells the agent which option was picked; Action keeps the structured ID for
+// gateway-handled actions such as exec approvals. Callers set
+// metadata["message_id"] to a per-click ID so retried callbacks are deduped.
+func (c *BaseChannel) HandleAction(senderID, chatID, displayName string, action bus.ActionCallback, metadata map[string]string, peerKind string) {
+	label := action.Label
+	if label == "" {
+		label = action.ActionID
+	}
+	content := fmt.Sprintf("[Selected option: %q (action_id: %s)]", label, action.ActionID)
+	if peerKind == "group" && displayName != "" {
+		content = fmt.Sprintf("[From: %s]\n%s", displayName, content)
+	}
+	userID := senderID
+	if idx := strings.IndexByte(senderID, '|'); idx > 0 {
+		userID = senderID[:idx]
+	}
+	c.bus.PublishInbound(bus.InboundMessage{
+		Channel:  c.name,
+		SenderID: senderID,
+		ChatID:   chatID,
+		Content:  content,
+		PeerKind: peerKind,
+		UserID:   userID,
+		Action:   &action,
+		Metadata: metadata,
+		TenantID: c.tenantID,
+		AgentID:  c.agentID,
+	})
+}
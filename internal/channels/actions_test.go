@@ -0,0 +1,60 @@
+package channels
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+)
+
+func TestActionRows(t *testing.T) {
+	actions := make([]bus.MessageAction, 7)
+	rows := ActionRows(actions, 3)
+	if len(rows) != 3 || len(rows[0]) != 3 || len(rows[2]) != 1 {
+		t.Fatalf("rows = %v, want 3/3/1", rows)
+	}
+	if ActionRows(nil, 3) != nil {
+		t.Fatal("expected no rows for no actions")
+	}
+}
+
+func TestActionsFallbackText(t *testing.T) {
+	got := ActionsFallbackText([]bus.MessageAction{
+		{ID: "yes", Label: "Yes"},
+		{Label: "Docs", URL: "https://example.com/docs"},
+	})
+	want := "\n\n• Yes\n• Docs: https://example.com/docs"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if ActionsFallbackText(nil) != "" {
+		t.Fatal("expected empty fallback for no actions")
+	}
+}
+
+func TestHandleAction_PublishesInbound(t *testing.T) {
+	mb := bus.New()
+	defer mb.Close()
+	bc := NewBaseChannel("tg", mb, nil)
+
+	bc.HandleAction("42|alice", "-100", "Alice", bus.ActionCallback{ActionID: "opt_b", Label: "Option B", MessageID: "7"},
+		map[string]string{"message_id": "7:opt_b"}, "group")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	got, ok := mb.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("expected inbound message")
+	}
+	if got.Action == nil || got.Action.ActionID != "opt_b" {
+		t.Fatalf("Action = %+v, want opt_b", got.Action)
+	}
+	if got.UserID != "42" || got.ChatID != "-100" || got.PeerKind != "group" {
+		t.Fatalf("routing = %q/%q/%q", got.UserID, got.ChatID, got.PeerKind)
+	}
+	want := "[From: Alice]\n[Selected option: \"Option B\" (action_id: opt_b)]"
+	if got.Content != want {
+		t.Fatalf("content = %q, want %q", got.Content, want)
+	}
+}
@@ -425,6 +425,10 @@ type fakeUpsertCall struct {
 	threadID        string
 }
 
+func (f *fakeContactStore) SetDirectChat(context.Context, string, string, string, string) error {
+	return nil
+}
+
 func (f *fakeContactStore) UpsertContact(_ context.Context, channelType, channelInstance, senderID, userID, _, _, peerKind, contactType, threadID, _ string) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -465,6 +469,9 @@ func (f *fakeContactStore) UnmergeContacts(_ context.Context, _ []uuid.UUID) err
 func (f *fakeContactStore) GetContactsByMergedID(_ context.Context, _ uuid.UUID) ([]store.ChannelContact, error) {
 	return nil, nil
 }
+func (f *fakeContactStore) GetContactsByTenantUserID(_ context.Context, _ string) ([]store.ChannelContact, error) {
+	return nil, nil
+}
 
 func (f *fakeContactStore) snapshot() []fakeUpsertCall {
 	f.mu.Lock()
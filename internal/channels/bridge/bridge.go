@@ -0,0 +1,246 @@
+// Package bridge implements a GoClaw channel backed by an out-of-process
+// plugin. The plugin connects to the gateway over WebSocket, authenticates
+// with an API key and speaks the bridge protocol (pkg/protocol/bridge.go),
+// which maps onto the Channel, StreamingChannel, ReactionChannel and
+// GroupMemberProvider interfaces. New platforms (LINE, Mattermost, ...) can
+// ship as plugins without a gateway rebuild.
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/safego"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+const (
+	defaultMediaMaxBytes int64 = 20 * 1024 * 1024 // 20MB
+	inboundQueueSize           = 64
+	pairingDebounce            = 60 * time.Second
+	dedupTTL                   = 10 * time.Minute
+	callTimeout                = 30 * time.Second
+)
+
+// Channel is a bridge channel instance. It is running while registered on
+// the router; it is healthy while a plugin is attached.
+type Channel struct {
+	*channels.BaseChannel
+	config        bridgeInstanceConfig
+	mediaMaxBytes int64
+
+	mu      sync.RWMutex
+	conn    *pluginConn // nil while no plugin is attached
+	inbound chan *protocol.BridgeInbound
+	dedup   sync.Map // chatID:messageID -> time.Time
+	streams sync.Map // chatID -> *bridgeStream (finalized, awaiting Send)
+
+	wg       sync.WaitGroup
+	cancelFn context.CancelFunc
+}
+
+// Compile-time interface assertions.
+var _ channels.Channel = (*Channel)(nil)
+var _ channels.StreamingChannel = (*Channel)(nil)
+var _ channels.ReactionChannel = (*Channel)(nil)
+var _ channels.GroupMemberProvider = (*Channel)(nil)
+var _ channels.BlockReplyChannel = (*Channel)(nil)
+var _ channels.WebhookChannel = (*Channel)(nil)
+
+// New creates a new bridge channel from instance config.
+func New(cfg bridgeInstanceConfig, msgBus *bus.MessageBus, pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (*Channel, error) {
+	base := channels.NewBaseChannel(channels.TypeBridge, msgBus, cfg.AllowFrom)
+	base.ValidatePolicy(cfg.DMPolicy, cfg.GroupPolicy)
+
+	requireMention := true
+	if cfg.RequireMention != nil {
+		requireMention = *cfg.RequireMention
+	}
+	historyLimit := cfg.HistoryLimit
+	if historyLimit == 0 {
+		historyLimit = channels.DefaultGroupHistoryLimit
+	}
+	mediaMax := int64(cfg.MediaMaxMB) * 1024 * 1024
+	if mediaMax <= 0 {
+		mediaMax = defaultMediaMaxBytes
+	}
+
+	ch := &Channel{
+		BaseChannel:   base,
+		config:        cfg,
+		mediaMaxBytes: mediaMax,
+		inbound:       make(chan *protocol.BridgeInbound, inboundQueueSize),
+	}
+	ch.SetRequireMention(requireMention)
+	ch.SetPairingService(pairingSvc)
+	ch.SetGroupHistory(channels.MakeHistory(channels.TypeBridge, pendingStore, base.TenantID()))
+	ch.SetHistoryLimit(historyLimit)
+	return ch, nil
+}
+
+// Start registers the instance on the plugin router. The channel stays
+// degraded until a plugin connects and sends bridge.hello.
+func (c *Channel) Start(_ context.Context) error {
+	if err := globalRouter.register(c); err != nil {
+		c.MarkFailed("Duplicate instance name", err.Error(), channels.ChannelFailureKindConfig, false)
+		return err
+	}
+	c.GroupHistory().StartFlusher()
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	c.cancelFn = cancel
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		defer safego.Recover(nil, "component", "bridge_inbound")
+		c.inboundLoop(runCtx)
+	}()
+	go func() {
+		defer c.wg.Done()
+		defer safego.Recover(nil, "component", "bridge_sweep")
+		ticker := time.NewTicker(2 * time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+				c.sweepMaps()
+			}
+		}
+	}()
+
+	c.SetRunning(true)
+	if c.plugin() == nil {
+		c.MarkDegraded("waiting for plugin", "no bridge plugin is connected to "+protocol.BridgePath, channels.ChannelFailureKindNetwork, true)
+	}
+	slog.Info("bridge channel started", "instance", c.Name(), "path", protocol.BridgePath)
+	return nil
+}
+
+// Stop unregisters the instance and disconnects the plugin.
+func (c *Channel) Stop(_ context.Context) error {
+	c.GroupHistory().StopFlusher()
+	slog.Info("stopping bridge channel", "instance", c.Name())
+	globalRouter.unregister(c)
+	c.mu.Lock()
+	pc := c.conn
+	c.conn = nil
+	c.mu.Unlock()
+	if pc != nil {
+		pc.close("channel stopped")
+	}
+	c.SetRunning(false)
+	if c.cancelFn != nil {
+		c.cancelFn()
+		c.wg.Wait()
+		c.cancelFn = nil
+	}
+	c.MarkStopped("stopped")
+	return nil
+}
+
+// WebhookHandler returns the shared plugin endpoint and the global router as handler.
+// Only the first instance returns a route; the rest share it.
+func (c *Channel) WebhookHandler() (string, http.Handler) {
+	return globalRouter.webhookRoute()
+}
+
+// attach makes pc the instance's plugin connection. A plugin reconnecting
+// before the old connection timed out replaces it.
+func (c *Channel) attach(pc *pluginConn) {
+	c.mu.Lock()
+	prev := c.conn
+	c.conn = pc
+	c.mu.Unlock()
+	if prev != nil {
+		prev.close("replaced by a new connection")
+	}
+	summary := "plugin connected"
+	if pc.platform != "" {
+		summary += ": " + pc.platform
+	}
+	c.MarkHealthy(summary)
+	slog.Info("bridge plugin connected", "instance", c.Name(), "platform", pc.platform,
+		"streaming", pc.caps.Streaming, "reactions", pc.caps.Reactions, "group_members", pc.caps.GroupMembers)
+}
+
+// detach clears pc once its read loop ended, unless it was already replaced
+// or the channel stopped.
+func (c *Channel) detach(pc *pluginConn, err error) {
+	pc.close("")
+	c.mu.Lock()
+	current := c.conn == pc
+	if current {
+		c.conn = nil
+	}
+	c.mu.Unlock()
+	if !current {
+		return
+	}
+	detail := "the bridge plugin closed the connection"
+	if err != nil {
+		detail = err.Error()
+	}
+	slog.Warn("bridge plugin disconnected", "instance", c.Name(), "error", err)
+	c.MarkDegraded("plugin disconnected", detail, channels.ChannelFailureKindNetwork, true)
+}
+
+// plugin returns the attached connection, or nil.
+func (c *Channel) plugin() *pluginConn {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.conn
+}
+
+// call sends a request to the attached plugin.
+func (c *Channel) call(ctx context.Context, method string, params, result any) error {
+	pc := c.plugin()
+	if pc == nil {
+		return fmt.Errorf("bridge plugin not connected")
+	}
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+	return pc.call(ctx, method, params, result)
+}
+
+// sweepMaps performs age-based eviction of the dedup cache.
+func (c *Channel) sweepMaps() {
+	now := time.Now()
+	c.dedup.Range(func(k, v any) bool {
+		if t, ok := v.(time.Time); ok && now.Sub(t) > dedupTTL {
+			c.dedup.Delete(k)
+		}
+		return true
+	})
+}
+
+// SetPendingCompaction configures LLM-based auto-compaction for pending messages.
+func (c *Channel) SetPendingCompaction(cfg *channels.CompactionConfig) {
+	if gh := c.GroupHistory(); gh != nil {
+		gh.SetCompactionConfig(cfg)
+	}
+}
+
+// SetPendingHistoryTenantID propagates tenant_id to the pending history for DB operations.
+func (c *Channel) SetPendingHistoryTenantID(id uuid.UUID) {
+	if gh := c.GroupHistory(); gh != nil {
+		gh.SetTenantID(id)
+	}
+}
+
+// BlockReplyEnabled returns the per-channel block_reply override (nil = inherit gateway default).
+func (c *Channel) BlockReplyEnabled() *bool { return c.config.BlockReply }
+
+// ChatBehaviorConfig returns the per-channel chat_behavior override.
+func (c *Channel) ChatBehaviorConfig() *config.ChatBehaviorConfig { return c.config.ChatBehavior }
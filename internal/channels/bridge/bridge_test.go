@@ -0,0 +1,452 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+const (
+	tenantKey = "tenant-key"
+	systemKey = "system-key"
+)
+
+var testTenant = uuid.MustParse("0193a5b0-7000-7000-8000-000000000001")
+
+func testAuth(_ context.Context, token string) (uuid.UUID, bool) {
+	switch token {
+	case tenantKey:
+		return testTenant, true
+	case systemKey:
+		return uuid.Nil, true
+	}
+	return uuid.Nil, false
+}
+
+func init() { SetAuthenticator(testAuth) }
+
+// fakePlugin is a bridge plugin driven by the test. Gateway requests are
+// answered from replies (method → payload) and recorded in calls.
+type fakePlugin struct {
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+	replies map[string]any
+	calls   chan protocol.RequestFrame
+	events  chan protocol.RequestFrame // event name in Method, payload in Params
+	resps   chan responseIn
+}
+
+func dial(t *testing.T, srv *httptest.Server, key string) (*fakePlugin, *http.Response, error) {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + protocol.BridgePath
+	ws, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Authorization": {"Bearer " + key}})
+	if err != nil {
+		return nil, resp, err
+	}
+	p := &fakePlugin{
+		ws:      ws,
+		replies: map[string]any{},
+		calls:   make(chan protocol.RequestFrame, 16),
+		events:  make(chan protocol.RequestFrame, 16),
+		resps:   make(chan responseIn, 16),
+	}
+	t.Cleanup(func() { ws.Close() })
+	return p, resp, nil
+}
+
+func (p *fakePlugin) run() {
+	go func() {
+		for {
+			_, data, err := p.ws.ReadMessage()
+			if err != nil {
+				close(p.resps)
+				return
+			}
+			var f struct {
+				Type    string          `json:"type"`
+				ID      string          `json:"id"`
+				Method  string          `json:"method"`
+				Event   string          `json:"event"`
+				Params  json.RawMessage `json:"params"`
+				Payload json.RawMessage `json:"payload"`
+			}
+			_ = json.Unmarshal(data, &f)
+			switch f.Type {
+			case protocol.FrameTypeResponse:
+				var res responseIn
+				_ = json.Unmarshal(data, &res)
+				p.resps <- res
+			case protocol.FrameTypeEvent:
+				p.events <- protocol.RequestFrame{Method: f.Event, Params: f.Payload}
+			case protocol.FrameTypeRequest:
+				p.calls <- protocol.RequestFrame{ID: f.ID, Method: f.Method, Params: f.Params}
+				p.send(protocol.NewOKResponse(f.ID, p.replies[f.Method]))
+			}
+		}
+	}()
+}
+
+func (p *fakePlugin) send(v any) {
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	_ = p.ws.WriteJSON(v)
+}
+
+// request sends a plugin → gateway request and returns the response.
+func (p *fakePlugin) request(t *testing.T, method string, params any) responseIn {
+	t.Helper()
+	raw, _ := json.Marshal(params)
+	p.send(protocol.RequestFrame{Type: protocol.FrameTypeRequest, ID: method, Method: method, Params: raw})
+	select {
+	case res, ok := <-p.resps:
+		if !ok {
+			t.Fatalf("%s: connection closed", method)
+		}
+		return res
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s: no response", method)
+	}
+	return responseIn{}
+}
+
+func (p *fakePlugin) hello(t *testing.T, instance string, caps protocol.BridgeCapabilities) responseIn {
+	t.Helper()
+	return p.request(t, protocol.BridgeMethodHello, protocol.BridgeHello{
+		Instance: instance, Protocol: protocol.BridgeProtocolVersion, Platform: "line", Capabilities: caps,
+	})
+}
+
+func nextCall(t *testing.T, p *fakePlugin, method string) json.RawMessage {
+	t.Helper()
+	select {
+	case f := <-p.calls:
+		if f.Method != method {
+			t.Fatalf("call = %s, want %s", f.Method, method)
+		}
+		return f.Params
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no %s call", method)
+	}
+	return nil
+}
+
+func startChannel(t *testing.T, cfg bridgeInstanceConfig) (*Channel, *bus.MessageBus, *httptest.Server) {
+	t.Helper()
+	if cfg.DMPolicy == "" {
+		cfg.DMPolicy = "open"
+	}
+	if cfg.GroupPolicy == "" {
+		cfg.GroupPolicy = "open"
+	}
+	mb := bus.New()
+	ch, err := New(cfg, mb, nil, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ch.SetName("bridge-" + strings.ToLower(t.Name()))
+	ch.SetTenantID(testTenant)
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = ch.Stop(context.Background()) })
+	srv := httptest.NewServer(globalRouter)
+	t.Cleanup(srv.Close)
+	return ch, mb, srv
+}
+
+func connect(t *testing.T, ch *Channel, srv *httptest.Server, caps protocol.BridgeCapabilities) *fakePlugin {
+	t.Helper()
+	p, _, err := dial(t, srv, tenantKey)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	p.run()
+	if res := p.hello(t, ch.Name(), caps); !res.OK {
+		t.Fatalf("hello rejected: %+v", res.Error)
+	}
+	return p
+}
+
+func consume(t *testing.T, mb *bus.MessageBus) bus.InboundMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msg, ok := mb.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("no inbound message")
+	}
+	return msg
+}
+
+func waitState(t *testing.T, ch *Channel, state channels.ChannelHealthState) channels.ChannelHealth {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		h := ch.HealthSnapshot()
+		if h.State == state {
+			return h
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("health state = %s (%s), want %s", h.State, h.Summary, state)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestConnectAuthAndHealth(t *testing.T) {
+	ch, _, srv := startChannel(t, bridgeInstanceConfig{})
+	waitState(t, ch, channels.ChannelHealthStateDegraded)
+
+	if _, resp, err := dial(t, srv, "wrong-key"); err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("dial with unknown key: err=%v resp=%v", err, resp)
+	}
+
+	// A key of another tenant sees the instance as missing.
+	SetAuthenticator(func(_ context.Context, token string) (uuid.UUID, bool) { return uuid.New(), true })
+	t.Cleanup(func() { SetAuthenticator(testAuth) })
+	p, _, err := dial(t, srv, tenantKey)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	p.run()
+	if res := p.hello(t, ch.Name(), protocol.BridgeCapabilities{}); res.OK || res.Error.Code != protocol.ErrNotFound {
+		t.Fatalf("hello with other tenant's key = %+v", res)
+	}
+	SetAuthenticator(testAuth)
+	p, _, err = dial(t, srv, systemKey)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	p.run()
+	if res := p.hello(t, ch.Name(), protocol.BridgeCapabilities{}); !res.OK {
+		t.Fatalf("hello with system key rejected: %+v", res.Error)
+	}
+	if h := waitState(t, ch, channels.ChannelHealthStateHealthy); h.Summary != "plugin connected: line" {
+		t.Fatalf("summary = %q", h.Summary)
+	}
+
+	res := p.request(t, protocol.BridgeMethodHealth, protocol.BridgeHealth{State: "failed", Summary: "LINE token revoked", FailureKind: "auth"})
+	if !res.OK {
+		t.Fatalf("health rejected: %+v", res.Error)
+	}
+	if h := ch.HealthSnapshot(); h.State != channels.ChannelHealthStateFailed || h.FailureKind != channels.ChannelFailureKindAuth {
+		t.Fatalf("health = %+v", h)
+	}
+
+	p.ws.Close()
+	if h := waitState(t, ch, channels.ChannelHealthStateDegraded); h.Summary != "plugin disconnected" {
+		t.Fatalf("summary = %q", h.Summary)
+	}
+	if err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "U1", Content: "hi"}); err == nil {
+		t.Fatal("Send without plugin succeeded")
+	}
+}
+
+func TestDuplicateInstanceNameRejected(t *testing.T) {
+	ch, _, _ := startChannel(t, bridgeInstanceConfig{})
+
+	dup, err := New(bridgeInstanceConfig{DMPolicy: "open", GroupPolicy: "open"}, bus.New(), nil, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	dup.SetName(ch.Name())
+	dup.SetTenantID(uuid.New())
+	if err := dup.Start(context.Background()); err == nil {
+		t.Fatal("second instance with the same name started")
+	}
+	if h := dup.HealthSnapshot(); h.State != channels.ChannelHealthStateFailed {
+		t.Fatalf("duplicate health = %+v", h)
+	}
+	// The failed start must not evict the running instance.
+	if got := globalRouter.lookup(ch.Name()); got != ch {
+		t.Fatalf("router serves %p, want the first instance %p", got, ch)
+	}
+
+	_ = ch.Stop(context.Background())
+	if err := dup.Start(context.Background()); err != nil {
+		t.Fatalf("Start after the first instance stopped: %v", err)
+	}
+	_ = dup.Stop(context.Background())
+}
+
+func TestInboundWithMediaAndSend(t *testing.T) {
+	ch, mb, srv := startChannel(t, bridgeInstanceConfig{})
+	p := connect(t, ch, srv, protocol.BridgeCapabilities{})
+	p.replies[protocol.BridgeMethodSend] = protocol.BridgeSendResult{MessageIDs: []string{"m-1"}}
+
+	res := p.request(t, protocol.BridgeMethodInbound, protocol.BridgeInbound{
+		SenderID: "U1", ChatID: "U1", PeerKind: "direct", MessageID: "in-1",
+		Content: "what is this?", DisplayName: "Alice",
+		Media:    []protocol.BridgeMedia{{Data: []byte("\x89PNG..."), ContentType: "image/png", Filename: "cat.png"}},
+		Metadata: map[string]string{"reply_token": "rt-1"},
+	})
+	if !res.OK {
+		t.Fatalf("inbound rejected: %+v", res.Error)
+	}
+	msg := consume(t, mb)
+	if msg.ChatID != "U1" || msg.PeerKind != "direct" || msg.SenderID != "U1" {
+		t.Fatalf("inbound = %+v", msg)
+	}
+	if msg.Metadata["message_id"] != "in-1" || msg.Metadata["display_name"] != "Alice" || msg.Metadata["reply_token"] != "rt-1" {
+		t.Fatalf("metadata = %v", msg.Metadata)
+	}
+	if !strings.Contains(msg.Content, "<media:image>") || len(msg.Media) != 1 {
+		t.Fatalf("media not attached: %q %v", msg.Content, msg.Media)
+	}
+
+	// Redelivered messages are dropped.
+	p.request(t, protocol.BridgeMethodInbound, protocol.BridgeInbound{SenderID: "U1", ChatID: "U1", PeerKind: "direct", MessageID: "in-1", Content: "again"})
+
+	file := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(file, []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ch.Send(context.Background(), bus.OutboundMessage{
+		ChatID: "U1", Content: "here you go",
+		Media: []bus.MediaAttachment{{URL: file}},
+	}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	var sent protocol.BridgeSend
+	_ = json.Unmarshal(nextCall(t, p, protocol.BridgeMethodSend), &sent)
+	if sent.ChatID != "U1" || sent.Content != "here you go" || len(sent.Media) != 1 || string(sent.Media[0].Data) != "hello" || sent.Media[0].Filename != "report.txt" {
+		t.Fatalf("channel.send = %+v", sent)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if msg, ok := mb.ConsumeInbound(ctx); ok {
+		t.Fatalf("duplicate delivered: %+v", msg)
+	}
+}
+
+func TestInvalidInboundRejected(t *testing.T) {
+	ch, _, srv := startChannel(t, bridgeInstanceConfig{})
+	p := connect(t, ch, srv, protocol.BridgeCapabilities{})
+
+	res := p.request(t, protocol.BridgeMethodInbound, protocol.BridgeInbound{SenderID: "U1", ChatID: "U1", PeerKind: "channel"})
+	if res.OK || res.Error.Code != protocol.ErrInvalidRequest {
+		t.Fatalf("inbound = %+v", res)
+	}
+	if res := p.request(t, "bridge.unknown", nil); res.OK || res.Error.Code != protocol.ErrNotImplemented {
+		t.Fatalf("unknown method = %+v", res)
+	}
+}
+
+func TestGroupMentionGating(t *testing.T) {
+	ch, mb, srv := startChannel(t, bridgeInstanceConfig{})
+	p := connect(t, ch, srv, protocol.BridgeCapabilities{})
+
+	p.request(t, protocol.BridgeMethodInbound, protocol.BridgeInbound{
+		SenderID: "U2", ChatID: "G1", PeerKind: "group", MessageID: "g-1", Content: "lunch at noon", DisplayName: "Bob",
+	})
+	p.request(t, protocol.BridgeMethodInbound, protocol.BridgeInbound{
+		SenderID: "U1", ChatID: "G1", PeerKind: "group", MessageID: "g-2", Content: "bot, summarize", DisplayName: "Alice", Mentioned: true,
+	})
+
+	msg := consume(t, mb)
+	if msg.PeerKind != "group" || msg.ChatID != "G1" {
+		t.Fatalf("inbound = %+v", msg)
+	}
+	if !strings.Contains(msg.Content, "lunch at noon") || !strings.Contains(msg.Content, "[From: Alice]") {
+		t.Fatalf("content = %q", msg.Content)
+	}
+}
+
+func TestStreamReactionsAndMembers(t *testing.T) {
+	ch, _, srv := startChannel(t, bridgeInstanceConfig{ReactionLevel: "full"})
+	if ch.StreamEnabled(false) {
+		t.Fatal("streaming enabled without a plugin")
+	}
+	p := connect(t, ch, srv, protocol.BridgeCapabilities{Streaming: true, Reactions: true, GroupMembers: true})
+	if !ch.StreamEnabled(false) || ch.StreamEnabled(true) {
+		t.Fatal("stream defaults: DMs on, groups off")
+	}
+	ctx := context.Background()
+
+	p.replies[protocol.BridgeMethodStreamCreate] = protocol.BridgeStreamResult{StreamID: "s-1"}
+	stream, err := ch.CreateStream(ctx, "U1", true)
+	if err != nil {
+		t.Fatalf("CreateStream: %v", err)
+	}
+	nextCall(t, p, protocol.BridgeMethodStreamCreate)
+	stream.Update(ctx, "Hel")
+	stream.Update(ctx, "Hel")
+	stream.Update(ctx, "Hello")
+	for _, want := range []string{"Hel", "Hello"} {
+		select {
+		case ev := <-p.events:
+			var u protocol.BridgeStreamUpdate
+			_ = json.Unmarshal(ev.Params, &u)
+			if ev.Method != protocol.BridgeEventStreamUpdate || u.StreamID != "s-1" || u.Text != want {
+				t.Fatalf("event = %s %+v, want %q", ev.Method, u, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no stream update %q", want)
+		}
+	}
+	if err := stream.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	nextCall(t, p, protocol.BridgeMethodStreamStop)
+	ch.FinalizeStream(ctx, "U1", stream)
+
+	if err := ch.Send(ctx, bus.OutboundMessage{ChatID: "U1", Content: "Hello there"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	var sent protocol.BridgeSend
+	_ = json.Unmarshal(nextCall(t, p, protocol.BridgeMethodSend), &sent)
+	if sent.StreamID != "s-1" || sent.Content != "Hello there" {
+		t.Fatalf("channel.send = %+v", sent)
+	}
+
+	_ = ch.OnReactionEvent(ctx, "U1", "in-1", "thinking")
+	var r protocol.BridgeReaction
+	_ = json.Unmarshal(nextCall(t, p, protocol.BridgeMethodReaction), &r)
+	if r.ChatID != "U1" || r.MessageID != "in-1" || r.Status != "thinking" {
+		t.Fatalf("reaction = %+v", r)
+	}
+	_ = ch.ClearReaction(ctx, "U1", "in-1")
+	nextCall(t, p, protocol.BridgeMethodReactionClear)
+
+	p.replies[protocol.BridgeMethodMembersList] = protocol.BridgeMembersResult{Members: []protocol.BridgeMember{
+		{MemberID: "U1", Name: "Alice"}, {MemberID: "U2", Name: "Bob"},
+	}}
+	members, err := ch.ListGroupMembers(ctx, "G1")
+	if err != nil || len(members) != 2 || members[1].Name != "Bob" {
+		t.Fatalf("members = %+v, err = %v", members, err)
+	}
+}
+
+func TestUndeclaredCapabilitiesNotCalled(t *testing.T) {
+	ch, _, srv := startChannel(t, bridgeInstanceConfig{ReactionLevel: "full"})
+	p := connect(t, ch, srv, protocol.BridgeCapabilities{})
+	ctx := context.Background()
+
+	if ch.StreamEnabled(false) {
+		t.Fatal("streaming enabled without the capability")
+	}
+	_ = ch.OnReactionEvent(ctx, "U1", "in-1", "thinking")
+	if _, err := ch.ListGroupMembers(ctx, "G1"); err == nil {
+		t.Fatal("ListGroupMembers succeeded without the capability")
+	}
+	select {
+	case f := <-p.calls:
+		t.Fatalf("unexpected call %s", f.Method)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
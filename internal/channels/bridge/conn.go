@@ -0,0 +1,172 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+const (
+	writeTimeout = 10 * time.Second
+	pingInterval = 30 * time.Second
+	pongTimeout  = 75 * time.Second
+)
+
+var errDisconnected = errors.New("bridge plugin disconnected")
+
+// responseIn is a response frame as received; the payload stays raw until
+// the caller decodes it into its result type.
+type responseIn struct {
+	ID      string               `json:"id"`
+	OK      bool                 `json:"ok"`
+	Payload json.RawMessage      `json:"payload,omitempty"`
+	Error   *protocol.ErrorShape `json:"error,omitempty"`
+}
+
+// pluginConn is one attached plugin connection. Writes are serialized;
+// gateway → plugin requests are correlated with their responses by ID.
+type pluginConn struct {
+	ws       *websocket.Conn
+	platform string
+	caps     protocol.BridgeCapabilities
+
+	writeMu   sync.Mutex
+	pending   sync.Map // request ID -> chan *responseIn
+	seq       atomic.Uint64
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newPluginConn(ws *websocket.Conn) *pluginConn {
+	return &pluginConn{ws: ws, done: make(chan struct{})}
+}
+
+// call sends a request and waits for the plugin's response.
+func (p *pluginConn) call(ctx context.Context, method string, params, result any) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	id := "gw-" + strconv.FormatUint(p.seq.Add(1), 10)
+	ch := make(chan *responseIn, 1)
+	p.pending.Store(id, ch)
+	defer p.pending.Delete(id)
+
+	if err := p.write(protocol.RequestFrame{Type: protocol.FrameTypeRequest, ID: id, Method: method, Params: raw}); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.done:
+		return errDisconnected
+	case res := <-ch:
+		if !res.OK {
+			if res.Error != nil {
+				return fmt.Errorf("%s: %s: %s", method, res.Error.Code, res.Error.Message)
+			}
+			return fmt.Errorf("%s failed", method)
+		}
+		if result != nil && len(res.Payload) > 0 {
+			return json.Unmarshal(res.Payload, result)
+		}
+		return nil
+	}
+}
+
+// notify sends an event frame; no reply is expected.
+func (p *pluginConn) notify(event string, payload any) error {
+	return p.write(protocol.NewEvent(event, payload))
+}
+
+func (p *pluginConn) respond(res *protocol.ResponseFrame) {
+	_ = p.write(res)
+}
+
+func (p *pluginConn) write(v any) error {
+	select {
+	case <-p.done:
+		return errDisconnected
+	default:
+	}
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	_ = p.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return p.ws.WriteJSON(v)
+}
+
+// readLoop reads frames until the connection fails, resolving responses and
+// passing requests to onRequest. It keeps the connection alive with pings.
+func (p *pluginConn) readLoop(onRequest func(*protocol.RequestFrame)) error {
+	_ = p.ws.SetReadDeadline(time.Now().Add(pongTimeout))
+	p.ws.SetPongHandler(func(string) error {
+		return p.ws.SetReadDeadline(time.Now().Add(pongTimeout))
+	})
+	go p.pingLoop()
+
+	for {
+		_, data, err := p.ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+		var head struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(data, &head); err != nil {
+			continue
+		}
+		switch head.Type {
+		case protocol.FrameTypeResponse:
+			var res responseIn
+			if err := json.Unmarshal(data, &res); err != nil {
+				continue
+			}
+			if ch, ok := p.pending.Load(res.ID); ok {
+				select {
+				case ch.(chan *responseIn) <- &res:
+				default: // duplicate response
+				}
+			}
+		case protocol.FrameTypeRequest:
+			var req protocol.RequestFrame
+			if err := json.Unmarshal(data, &req); err != nil {
+				continue
+			}
+			onRequest(&req)
+		}
+	}
+}
+
+func (p *pluginConn) pingLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			if err := p.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// close ends the connection; pending calls fail with errDisconnected.
+func (p *pluginConn) close(reason string) {
+	p.closeOnce.Do(func() {
+		close(p.done)
+		_ = p.ws.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason), time.Now().Add(time.Second))
+		_ = p.ws.Close()
+	})
+}
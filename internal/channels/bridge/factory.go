@@ -0,0 +1,65 @@
+package bridge
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// bridgeInstanceConfig maps the non-secret config JSONB from the channel_instances table.
+// The plugin authenticates with an API key, so the channel has no credentials.
+type bridgeInstanceConfig struct {
+	Platform       string                     `json:"platform,omitempty"` // informational: the platform the plugin serves
+	DMPolicy       string                     `json:"dm_policy,omitempty"`
+	GroupPolicy    string                     `json:"group_policy,omitempty"`
+	AllowFrom      []string                   `json:"allow_from,omitempty"`
+	RequireMention *bool                      `json:"require_mention,omitempty"`
+	HistoryLimit   int                        `json:"history_limit,omitempty"`
+	DMStream       *bool                      `json:"dm_stream,omitempty"`    // default true when the plugin streams
+	GroupStream    *bool                      `json:"group_stream,omitempty"` // default false
+	ReactionLevel  string                     `json:"reaction_level,omitempty"`
+	MediaMaxMB     int                        `json:"media_max_mb,omitempty"`
+	BlockReply     *bool                      `json:"block_reply,omitempty"`
+	ChatBehavior   *config.ChatBehaviorConfig `json:"chat_behavior,omitempty"`
+}
+
+// Factory creates a bridge channel from DB instance data.
+func Factory(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+	return build(name, cfg, msgBus, pairingSvc, nil)
+}
+
+// FactoryWithPendingStore returns a ChannelFactory with persistent history support.
+func FactoryWithPendingStore(pendingStore store.PendingMessageStore) channels.ChannelFactory {
+	return func(name string, creds json.RawMessage, cfg json.RawMessage,
+		msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+		return build(name, cfg, msgBus, pairingSvc, pendingStore)
+	}
+}
+
+func build(name string, cfg json.RawMessage, msgBus *bus.MessageBus,
+	pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (channels.Channel, error) {
+
+	var ic bridgeInstanceConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &ic); err != nil {
+			return nil, fmt.Errorf("decode bridge config: %w", err)
+		}
+	}
+
+	// Secure default: DB instances default to "pairing" for groups.
+	if ic.GroupPolicy == "" {
+		ic.GroupPolicy = "pairing"
+	}
+
+	ch, err := New(ic, msgBus, pairingSvc, pendingStore)
+	if err != nil {
+		return nil, err
+	}
+	ch.SetName(name)
+	return ch, nil
+}
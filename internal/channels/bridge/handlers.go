@@ -0,0 +1,283 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+// handleRequest serves a plugin → gateway request. It runs on the
+// connection's read loop, so inbound messages are queued for inboundLoop:
+// handling them may call back into the plugin (pairing replies).
+func (c *Channel) handleRequest(pc *pluginConn, req *protocol.RequestFrame) {
+	switch req.Method {
+	case protocol.BridgeMethodInbound:
+		var in protocol.BridgeInbound
+		if err := json.Unmarshal(req.Params, &in); err != nil {
+			pc.respond(protocol.NewErrorResponse(req.ID, protocol.ErrInvalidRequest, "invalid inbound message"))
+			return
+		}
+		if in.SenderID == "" || in.ChatID == "" || (in.PeerKind != "direct" && in.PeerKind != "group") {
+			pc.respond(protocol.NewErrorResponse(req.ID, protocol.ErrInvalidRequest, "sender_id, chat_id and peer_kind (direct|group) are required"))
+			return
+		}
+		select {
+		case c.inbound <- &in:
+			pc.respond(protocol.NewOKResponse(req.ID, nil))
+		default:
+			res := protocol.NewErrorResponse(req.ID, protocol.ErrResourceExhausted, "inbound queue full")
+			res.Error.Retryable = true
+			pc.respond(res)
+		}
+
+	case protocol.BridgeMethodHealth:
+		var h protocol.BridgeHealth
+		if err := json.Unmarshal(req.Params, &h); err != nil {
+			pc.respond(protocol.NewErrorResponse(req.ID, protocol.ErrInvalidRequest, "invalid health report"))
+			return
+		}
+		if err := c.applyHealth(h); err != nil {
+			pc.respond(protocol.NewErrorResponse(req.ID, protocol.ErrInvalidRequest, err.Error()))
+			return
+		}
+		pc.respond(protocol.NewOKResponse(req.ID, nil))
+
+	default:
+		pc.respond(protocol.NewErrorResponse(req.ID, protocol.ErrNotImplemented, "unknown method: "+req.Method))
+	}
+}
+
+// applyHealth maps a plugin health report onto the channel health snapshot.
+func (c *Channel) applyHealth(h protocol.BridgeHealth) error {
+	kind := channels.ChannelFailureKind(h.FailureKind)
+	switch kind {
+	case channels.ChannelFailureKindAuth, channels.ChannelFailureKindConfig, channels.ChannelFailureKindNetwork:
+	default:
+		kind = channels.ChannelFailureKindUnknown
+	}
+	switch channels.ChannelHealthState(h.State) {
+	case channels.ChannelHealthStateHealthy:
+		c.MarkHealthy(h.Summary)
+	case channels.ChannelHealthStateDegraded:
+		c.MarkDegraded(h.Summary, h.Detail, kind, h.Retryable)
+	case channels.ChannelHealthStateFailed:
+		c.MarkFailed(h.Summary, h.Detail, kind, h.Retryable)
+	default:
+		return fmt.Errorf("state must be healthy, degraded or failed")
+	}
+	return nil
+}
+
+func (c *Channel) inboundLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case in := <-c.inbound:
+			c.handleInbound(store.WithTenantID(ctx, c.TenantID()), in)
+		}
+	}
+}
+
+// handleInbound applies policies, mention gating and group history to a
+// plugin message, then publishes it to the bus.
+func (c *Channel) handleInbound(ctx context.Context, in *protocol.BridgeInbound) {
+	if in.MessageID != "" {
+		if _, loaded := c.dedup.LoadOrStore(in.ChatID+":"+in.MessageID, time.Now()); loaded {
+			return
+		}
+	}
+
+	isDM := in.PeerKind == "direct"
+	if isDM {
+		if !c.checkDMPolicy(ctx, in.SenderID, in.ChatID) {
+			return
+		}
+		if !c.IsAllowed(in.SenderID) {
+			slog.Debug("bridge message rejected by allowlist", "sender_id", in.SenderID)
+			return
+		}
+	} else if !c.checkGroupPolicy(ctx, in.SenderID, in.ChatID) {
+		return
+	}
+
+	displayName := in.DisplayName
+	if displayName == "" {
+		displayName = in.SenderID
+	}
+
+	content := strings.TrimSpace(in.Content)
+	var mediaPaths []string
+	if items := c.saveMedia(in.Media); len(items) > 0 {
+		for _, it := range items {
+			mediaPaths = append(mediaPaths, it.FilePath)
+			if it.Type == media.TypeDocument {
+				if doc, err := media.ExtractDocumentContent(it.FilePath, it.FileName); err != nil {
+					slog.Warn("bridge: document extraction failed", "file", it.FileName, "error", err)
+				} else if doc != "" {
+					content = strings.TrimSpace(content + "\n\n" + doc)
+				}
+			}
+		}
+		if tags := media.BuildMediaTags(items); tags != "" {
+			content = strings.TrimSpace(tags + "\n\n" + content)
+		}
+	}
+	if content == "" {
+		return
+	}
+
+	// Mention gating in groups: unmentioned messages become pending history.
+	if !isDM && c.RequireMention() && !in.Mentioned {
+		c.GroupHistory().Record(in.ChatID, channels.HistoryEntry{
+			Sender:    displayName,
+			SenderID:  in.SenderID,
+			Body:      content,
+			Media:     mediaPaths,
+			Timestamp: time.Now(),
+			MessageID: in.MessageID,
+		}, c.HistoryLimit())
+		if cc := c.ContactCollector(); cc != nil {
+			cc.EnsureContact(ctx, c.Type(), c.Name(), in.SenderID, in.SenderID, displayName, in.Username, "group", "user", "", "")
+		}
+		return
+	}
+
+	slog.Debug("bridge message received",
+		"sender_id", in.SenderID, "chat_id", in.ChatID,
+		"is_dm", isDM, "preview", channels.Truncate(content, 50))
+
+	finalContent := content
+	if !isDM {
+		annotated := fmt.Sprintf("[From: %s]\n%s", displayName, content)
+		if c.HistoryLimit() > 0 {
+			if histMedia := c.GroupHistory().CollectMedia(in.ChatID); len(histMedia) > 0 {
+				mediaPaths = append(mediaPaths, histMedia...)
+			}
+			finalContent = c.GroupHistory().BuildContext(in.ChatID, annotated, c.HistoryLimit())
+		} else {
+			finalContent = annotated
+		}
+	}
+
+	metadata := make(map[string]string, len(in.Metadata)+6)
+	for k, v := range in.Metadata {
+		metadata[k] = v
+	}
+	metadata["message_id"] = in.MessageID
+	metadata["user_id"] = in.SenderID
+	metadata["username"] = in.Username
+	metadata["display_name"] = channels.SanitizeDisplayName(displayName)
+	metadata["is_dm"] = fmt.Sprintf("%t", isDM)
+	metadata["local_key"] = in.ChatID
+
+	c.HandleMessage(in.SenderID, in.ChatID, finalContent, mediaPaths, metadata, in.PeerKind)
+
+	if !isDM {
+		c.GroupHistory().Clear(in.ChatID)
+	}
+}
+
+// saveMedia writes inline files to temp files. Oversized or empty files are
+// logged and skipped so the text of the message still goes through.
+func (c *Channel) saveMedia(files []protocol.BridgeMedia) []media.MediaInfo {
+	var items []media.MediaInfo
+	for _, f := range files {
+		if len(f.Data) == 0 || int64(len(f.Data)) > c.mediaMaxBytes {
+			slog.Warn("bridge: inbound file skipped", "file", f.Filename, "size", len(f.Data), "max", c.mediaMaxBytes)
+			continue
+		}
+		name := filepath.Base(f.Filename)
+		if f.Filename == "" {
+			name = "file"
+		}
+		mime := f.ContentType
+		if mime == "" {
+			mime = media.DetectMIMEType(name)
+		}
+		ext := filepath.Ext(name)
+		if ext == "" {
+			ext = ".dat"
+		}
+		tmp, err := os.CreateTemp("", "bridge-file-*"+ext)
+		if err != nil {
+			slog.Warn("bridge: create temp file failed", "error", err)
+			continue
+		}
+		_, err = tmp.Write(f.Data)
+		tmp.Close()
+		if err != nil {
+			os.Remove(tmp.Name())
+			slog.Warn("bridge: write temp file failed", "error", err)
+			continue
+		}
+		items = append(items, media.MediaInfo{
+			Type:        media.MediaKindFromMime(mime),
+			FilePath:    tmp.Name(),
+			ContentType: mime,
+			FileName:    name,
+			FileSize:    int64(len(f.Data)),
+		})
+	}
+	return items
+}
+
+// checkDMPolicy enforces DM policy for incoming messages.
+func (c *Channel) checkDMPolicy(ctx context.Context, senderID, chatID string) bool {
+	switch c.CheckDMPolicy(ctx, senderID, c.config.DMPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, senderID, chatID)
+		return false
+	default:
+		slog.Debug("bridge DM rejected by policy", "sender_id", senderID, "policy", c.config.DMPolicy)
+		return false
+	}
+}
+
+// checkGroupPolicy enforces group access policy; it does not check mention gating.
+func (c *Channel) checkGroupPolicy(ctx context.Context, senderID, chatID string) bool {
+	switch c.CheckGroupPolicy(ctx, senderID, chatID, c.config.GroupPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, "group:"+chatID, chatID)
+		return false
+	default:
+		slog.Debug("bridge group message rejected by policy", "chat_id", chatID, "policy", c.config.GroupPolicy)
+		return false
+	}
+}
+
+func (c *Channel) sendPairingReply(ctx context.Context, senderID, chatID string) {
+	ps := c.PairingService()
+	if ps == nil || !c.CanSendPairingNotif(senderID, pairingDebounce) {
+		return
+	}
+	code, err := ps.RequestPairing(ctx, senderID, c.Name(), chatID, "default", nil)
+	if err != nil {
+		slog.Debug("bridge pairing request failed", "sender_id", senderID, "error", err)
+		return
+	}
+	reply := fmt.Sprintf(
+		"GoClaw: access not configured.\n\nYour user ID: %s\n\nPairing code: %s\n\nAsk the bot owner to approve with:\n  goclaw pairing approve %s",
+		senderID, code, code,
+	)
+	if err := c.call(ctx, protocol.BridgeMethodSend, protocol.BridgeSend{ChatID: chatID, Content: reply}, nil); err != nil {
+		slog.Warn("bridge: failed to send pairing reply", "error", err)
+		return
+	}
+	c.MarkPairingNotifSent(senderID)
+	slog.Info("bridge pairing reply sent", "sender_id", senderID, "code", code)
+}
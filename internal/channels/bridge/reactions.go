@@ -0,0 +1,70 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+// OnReactionEvent forwards the agent status to the plugin, which picks the
+// emoji (channel.reaction.set).
+func (c *Channel) OnReactionEvent(ctx context.Context, chatID string, messageID string, status string) error {
+	if !c.reactionsEnabled() || messageID == "" {
+		return nil
+	}
+	// For "minimal" level, only show thinking and done
+	if c.config.ReactionLevel == "minimal" && status != "thinking" && status != "done" {
+		return nil
+	}
+	if err := c.call(ctx, protocol.BridgeMethodReaction, protocol.BridgeReaction{ChatID: chatID, MessageID: messageID, Status: status}, nil); err != nil {
+		slog.Debug("bridge: set reaction failed", "status", status, "error", err)
+	}
+	return nil
+}
+
+// ClearReaction removes the status reaction from a message (channel.reaction.clear).
+func (c *Channel) ClearReaction(ctx context.Context, chatID string, messageID string) error {
+	if !c.reactionsEnabled() || messageID == "" {
+		return nil
+	}
+	if err := c.call(ctx, protocol.BridgeMethodReactionClear, protocol.BridgeReaction{ChatID: chatID, MessageID: messageID}, nil); err != nil {
+		slog.Debug("bridge: clear reaction failed", "error", err)
+	}
+	return nil
+}
+
+func (c *Channel) reactionsEnabled() bool {
+	if c.config.ReactionLevel == "" || c.config.ReactionLevel == "off" {
+		return false
+	}
+	pc := c.plugin()
+	return pc != nil && pc.caps.Reactions
+}
+
+// ListGroupMembers asks the plugin for the members of a group chat
+// (channel.members.list).
+func (c *Channel) ListGroupMembers(ctx context.Context, chatID string) ([]channels.GroupMember, error) {
+	pc := c.plugin()
+	if pc == nil || !pc.caps.GroupMembers {
+		return nil, fmt.Errorf("bridge plugin does not list group members")
+	}
+	var res protocol.BridgeMembersResult
+	if err := c.call(ctx, protocol.BridgeMethodMembersList, protocol.BridgeMembersList{ChatID: chatID}, &res); err != nil {
+		slog.Warn("bridge.list_group_members", "chat_id", chatID, "error", err)
+		return nil, err
+	}
+	result := make([]channels.GroupMember, 0, len(res.Members))
+	for _, m := range res.Members {
+		if m.MemberID == "" {
+			continue
+		}
+		result = append(result, channels.GroupMember{MemberID: m.MemberID, Name: m.Name})
+		if cc := c.ContactCollector(); cc != nil {
+			cc.EnsureContact(ctx, channels.TypeBridge, c.Name(), m.MemberID, m.MemberID, m.Name, "", "group", "user", "", "")
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,174 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+const helloTimeout = 10 * time.Second
+
+var errHelloRequired = errors.New("first frame must be a bridge.hello request with an instance")
+
+// Authenticator resolves a plugin's API key to the tenant it belongs to
+// (uuid.Nil for system keys). ok is false for unknown keys or keys without
+// write access.
+type Authenticator func(ctx context.Context, token string) (tenantID uuid.UUID, ok bool)
+
+// SetAuthenticator installs the API key check used for plugin connections.
+// Until it is set, all connections are refused.
+func SetAuthenticator(a Authenticator) {
+	globalRouter.mu.Lock()
+	defer globalRouter.mu.Unlock()
+	globalRouter.auth = a
+}
+
+// pluginRouter accepts plugin WebSocket connections and attaches each to the
+// bridge instance named in its bridge.hello. A single handler is mounted on
+// the gateway mux and shared by all bridge instances.
+type pluginRouter struct {
+	mu           sync.RWMutex
+	instances    map[string]*Channel // instance name → channel
+	auth         Authenticator
+	routeHandled bool // true after first webhookRoute() call
+	upgrader     websocket.Upgrader
+}
+
+var globalRouter = &pluginRouter{
+	instances: make(map[string]*Channel),
+	upgrader: websocket.Upgrader{
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
+		// Plugins are not browsers; refusing browser origins keeps web pages
+		// from riding on an API key stored in the browser.
+		CheckOrigin: func(r *http.Request) bool { return r.Header.Get("Origin") == "" },
+	},
+}
+
+// register adds the instance to the router. bridge.hello names only the
+// instance, so names must be unique across tenants: a second instance with
+// the same name is rejected rather than taking over the first one's plugin.
+func (r *pluginRouter) register(ch *Channel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cur := r.instances[ch.Name()]; cur != nil && cur != ch {
+		return fmt.Errorf("bridge instance name %q is already in use; bridge instance names must be unique across tenants", ch.Name())
+	}
+	r.instances[ch.Name()] = ch
+	return nil
+}
+
+func (r *pluginRouter) unregister(ch *Channel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.instances[ch.Name()] == ch {
+		delete(r.instances, ch.Name())
+	}
+}
+
+// webhookRoute returns the path+handler for the first call; ("", nil) for subsequent calls.
+func (r *pluginRouter) webhookRoute() (string, http.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.routeHandled {
+		r.routeHandled = true
+		return protocol.BridgePath, r
+	}
+	return "", nil
+}
+
+func (r *pluginRouter) lookup(name string) *Channel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.instances[name]
+}
+
+// ServeHTTP authenticates the API key, upgrades the connection, waits for
+// bridge.hello and hands the connection to the named instance until it
+// disconnects.
+func (r *pluginRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.RLock()
+	auth := r.auth
+	r.mu.RUnlock()
+	token, _ := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if auth == nil || token == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	keyTenant, ok := auth(req.Context(), token)
+	if !ok {
+		slog.Warn("security.bridge_key_rejected", "remote", req.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	ws, err := r.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return // upgrader already wrote the error
+	}
+	pc := newPluginConn(ws)
+
+	hello, helloID, err := readHello(ws)
+	if err != nil {
+		pc.respond(protocol.NewErrorResponse(helloID, protocol.ErrInvalidRequest, err.Error()))
+		pc.close("hello required")
+		return
+	}
+	ch := r.lookup(hello.Instance)
+	// Same answer for unknown instances and other tenants' instances.
+	if ch == nil || (keyTenant != uuid.Nil && keyTenant != ch.TenantID()) {
+		slog.Warn("security.bridge_instance_rejected", "instance", hello.Instance, "remote", req.RemoteAddr)
+		pc.respond(protocol.NewErrorResponse(helloID, protocol.ErrNotFound, "bridge instance not found"))
+		pc.close("instance not found")
+		return
+	}
+	if hello.Protocol != protocol.BridgeProtocolVersion {
+		pc.respond(protocol.NewErrorResponse(helloID, protocol.ErrInvalidRequest, "unsupported bridge protocol version"))
+		pc.close("unsupported protocol")
+		return
+	}
+
+	pc.platform = hello.Platform
+	pc.caps = hello.Capabilities
+	ws.SetReadLimit(ch.mediaMaxBytes*2 + 1<<20) // base64 overhead + frame
+
+	pc.respond(protocol.NewOKResponse(helloID, protocol.BridgeHelloResult{
+		Protocol: protocol.BridgeProtocolVersion,
+		Instance: hello.Instance,
+	}))
+	ch.attach(pc)
+
+	err = pc.readLoop(func(f *protocol.RequestFrame) {
+		ch.handleRequest(pc, f)
+	})
+	ch.detach(pc, err)
+}
+
+// readHello reads the first frame, which must be a bridge.hello request.
+func readHello(ws *websocket.Conn) (*protocol.BridgeHello, string, error) {
+	_ = ws.SetReadDeadline(time.Now().Add(helloTimeout))
+	ws.SetReadLimit(64 << 10)
+	var req protocol.RequestFrame
+	if err := ws.ReadJSON(&req); err != nil {
+		return nil, "", err
+	}
+	if req.Type != protocol.FrameTypeRequest || req.Method != protocol.BridgeMethodHello {
+		return nil, req.ID, errHelloRequired
+	}
+	var hello protocol.BridgeHello
+	if err := json.Unmarshal(req.Params, &hello); err != nil || hello.Instance == "" {
+		return nil, req.ID, errHelloRequired
+	}
+	return &hello, req.ID, nil
+}
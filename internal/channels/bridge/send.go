@@ -0,0 +1,92 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+// Send delivers an outbound message through the plugin (channel.send). A
+// streamed preview for the same run is handed over by stream ID so the
+// plugin can replace it with the reply.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("bridge channel not running")
+	}
+	if msg.ChatID == "" {
+		return fmt.Errorf("empty chat ID for bridge send")
+	}
+	// No placeholder message to update: plugins own their progress UI.
+	if msg.Metadata["placeholder_update"] == "true" {
+		return nil
+	}
+
+	placeholderKey := msg.ChatID
+	if pk := msg.Metadata["placeholder_key"]; pk != "" {
+		placeholderKey = pk
+	}
+	var streamID string
+	if v, ok := c.streams.LoadAndDelete(placeholderKey); ok {
+		streamID = v.(*bridgeStream).id
+	}
+
+	content := msg.Content
+	var files []protocol.BridgeMedia
+	for _, m := range msg.Media {
+		f, err := c.loadMedia(m)
+		if err != nil {
+			slog.Warn("bridge: media send failed", "file", m.URL, "error", err)
+			content += fmt.Sprintf("\n[File upload failed: %s]", filepath.Base(m.URL))
+			continue
+		}
+		files = append(files, f)
+	}
+	// NO_REPLY without a preview to retract: nothing to tell the plugin.
+	if content == "" && len(files) == 0 && streamID == "" {
+		return nil
+	}
+
+	var res protocol.BridgeSendResult
+	if err := c.call(ctx, protocol.BridgeMethodSend, protocol.BridgeSend{
+		ChatID:   msg.ChatID,
+		Content:  content,
+		Media:    files,
+		Metadata: msg.Metadata,
+		StreamID: streamID,
+	}, &res); err != nil {
+		return fmt.Errorf("send bridge message: %w", err)
+	}
+	for _, id := range res.MessageIDs {
+		channels.RecordSentMessage(ctx, id)
+	}
+	return nil
+}
+
+// loadMedia reads a local outbound file for inline transfer; the plugin may
+// run on another host than the gateway.
+func (c *Channel) loadMedia(att bus.MediaAttachment) (protocol.BridgeMedia, error) {
+	stat, err := os.Stat(att.URL)
+	if err != nil {
+		return protocol.BridgeMedia{}, err
+	}
+	if stat.Size() > c.mediaMaxBytes {
+		return protocol.BridgeMedia{}, fmt.Errorf("file too large: %d bytes (max %d)", stat.Size(), c.mediaMaxBytes)
+	}
+	data, err := os.ReadFile(att.URL)
+	if err != nil {
+		return protocol.BridgeMedia{}, err
+	}
+	name := filepath.Base(att.URL)
+	mime := att.ContentType
+	if mime == "" {
+		mime = media.DetectMIMEType(name)
+	}
+	return protocol.BridgeMedia{Data: data, ContentType: mime, Filename: name, Caption: att.Caption}, nil
+}
@@ -0,0 +1,85 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+// bridgeStream implements channels.ChannelStream on top of the plugin's
+// channel.stream.* methods. Updates are fire-and-forget events; throttling
+// to the platform's edit rate limits is left to the plugin.
+type bridgeStream struct {
+	ch       *Channel
+	id       string
+	lastText string
+	mu       sync.Mutex
+}
+
+// Update sends the accumulated text to the plugin.
+func (s *bridgeStream) Update(_ context.Context, fullText string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if fullText == "" || fullText == s.lastText {
+		return
+	}
+	pc := s.ch.plugin()
+	if pc == nil {
+		return
+	}
+	if err := pc.notify(protocol.BridgeEventStreamUpdate, protocol.BridgeStreamUpdate{StreamID: s.id, Text: fullText}); err != nil {
+		slog.Debug("bridge stream update failed", "stream_id", s.id, "error", err)
+		return
+	}
+	s.lastText = fullText
+}
+
+// Stop asks the plugin to flush the stream.
+func (s *bridgeStream) Stop(ctx context.Context) error {
+	return s.ch.call(ctx, protocol.BridgeMethodStreamStop, protocol.BridgeStreamStop{StreamID: s.id}, nil)
+}
+
+// MessageID returns 0 — bridge message IDs are opaque strings.
+func (s *bridgeStream) MessageID() int {
+	return 0
+}
+
+// StreamEnabled reports whether streaming is active: the plugin must declare
+// the capability; DMs stream by default, groups opt in.
+func (c *Channel) StreamEnabled(isGroup bool) bool {
+	pc := c.plugin()
+	if pc == nil || !pc.caps.Streaming {
+		return false
+	}
+	if isGroup {
+		return c.config.GroupStream != nil && *c.config.GroupStream
+	}
+	return c.config.DMStream == nil || *c.config.DMStream
+}
+
+// CreateStream opens a stream on the plugin for the given chatID (local_key).
+func (c *Channel) CreateStream(ctx context.Context, chatID string, firstStream bool) (channels.ChannelStream, error) {
+	var res protocol.BridgeStreamResult
+	if err := c.call(ctx, protocol.BridgeMethodStreamCreate, protocol.BridgeStreamCreate{ChatID: chatID, FirstStream: firstStream}, &res); err != nil {
+		return nil, err
+	}
+	if res.StreamID == "" {
+		return nil, fmt.Errorf("bridge plugin returned no stream_id")
+	}
+	return &bridgeStream{ch: c, id: res.StreamID}, nil
+}
+
+// FinalizeStream hands the stream to Send() through c.streams so the final
+// reply carries its stream_id and replaces the preview.
+func (c *Channel) FinalizeStream(_ context.Context, chatID string, stream channels.ChannelStream) {
+	if bs, ok := stream.(*bridgeStream); ok {
+		c.streams.Store(chatID, bs)
+	}
+}
+
+// ReasoningStreamEnabled returns false — reasoning is not shown as a separate message.
+func (c *Channel) ReasoningStreamEnabled() bool { return false }
@@ -1,6 +1,10 @@
 package channels
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels/render"
+)
 
 // ErrMediaUnsupported is returned when a channel does not support media attachments.
 // Callers (e.g. webhook handler) should either degrade to text-only or return HTTP 501.
@@ -31,9 +35,15 @@ const (
 //   - zalo_personal: internal/channels/zalo/personal/send.go:42
 //   - pancake:  internal/channels/pancake/media_handler.go:18
 //   - facebook: internal/channels/facebook/facebook.go:205
+//   - matrix:   internal/channels/matrix/send.go:48
+//   - email:    internal/channels/email/send.go:56
+//   - signal:   internal/channels/signal/send.go:27
+//   - bridge:   internal/channels/bridge/send.go:42
+//   - twilio:   internal/channels/twilio/send.go:39 (MMS; needs public_url)
 //
 // NOT in this list:
 //   - zalo_oa: internal/channels/zalo/zalo.go:115 — Send() does NOT consume msg.Media
+//   - teams:   internal/channels/teams/send.go:50 — media is logged and skipped
 var mediaCapableTypes = map[string]bool{
 	TypeTelegram:     true,
 	TypeDiscord:      true,
@@ -43,6 +53,11 @@ var mediaCapableTypes = map[string]bool{
 	TypeZaloPersonal: true,
 	TypePancake:      true,
 	TypeFacebook:     true,
+	TypeMatrix:       true,
+	TypeEmail:        true,
+	TypeSignal:       true,
+	TypeBridge:       true,
+	TypeTwilio:       true,
 }
 
 var mediaBatchCapabilities = map[string]MediaBatchCapability{
@@ -61,6 +76,16 @@ var mediaBatchCapabilities = map[string]MediaBatchCapability{
 		MaxAttachments: 0,
 		Grouping:       MediaBatchGroupingOrdered,
 	},
+	TypeEmail: {
+		Supported:      true,
+		MaxAttachments: 0,
+		Grouping:       MediaBatchGroupingSingleMessage,
+	},
+	TypeTwilio: {
+		Supported:      true,
+		MaxAttachments: 10,
+		Grouping:       MediaBatchGroupingSingleMessage,
+	},
 }
 
 // IsMediaCapable reports whether the given channel platform type supports media attachments.
@@ -81,3 +106,32 @@ func MediaBatchCapabilityFor(channelType string) MediaBatchCapability {
 	}
 	return MediaBatchCapability{Grouping: MediaBatchGroupingNone}
 }
+
+// renderCapabilities declares how each platform shows rich markdown; the
+// outbound dispatcher renders the rest through render.Render. Chat apps get
+// monospace tables up to what fits a phone screen and images beyond that;
+// plain-text platforms get labeled lists. Types not listed (bitrix24, bridge)
+// keep their markdown for their own formatter or plugin.
+var renderCapabilities = map[string]render.Capabilities{
+	TypeTelegram:     {Tables: render.TableMonospace, MaxTableWidth: 48, MaxCodeLines: 80, Math: render.MathImage, Attachments: true},
+	TypeDiscord:      {Tables: render.TableMonospace, MaxTableWidth: 80, MaxCodeLines: 60, Math: render.MathImage, Attachments: true},
+	TypeSlack:        {Tables: render.TableMonospace, MaxTableWidth: 80, MaxCodeLines: 100, Math: render.MathImage, Attachments: true},
+	TypeWhatsApp:     {Tables: render.TableMonospace, MaxTableWidth: 40, MaxCodeLines: 60, Math: render.MathImage, Attachments: true},
+	TypeSignal:       {Tables: render.TableMonospace, MaxTableWidth: 40, MaxCodeLines: 60, Math: render.MathImage, Attachments: true},
+	TypeFeishu:       {MaxCodeLines: 150, Math: render.MathImage, Attachments: true},
+	TypeMatrix:       {MaxCodeLines: 150, Math: render.MathImage, Attachments: true},
+	TypeEmail:        {Math: render.MathImage, Attachments: true},
+	TypeTeams:        {Math: render.MathText},
+	TypeZaloPersonal: {Tables: render.TableImage, MaxCodeLines: 40, Math: render.MathImage, Attachments: true},
+	TypeFacebook:     {Tables: render.TableImage, MaxCodeLines: 40, Math: render.MathImage, Attachments: true},
+	TypePancake:      {Tables: render.TableImage, MaxCodeLines: 40, Math: render.MathImage, Attachments: true},
+	TypeZaloOA:       {Tables: render.TableList, Math: render.MathText},
+	// MMS needs a public URL and costs per message; SMS stays text-only.
+	TypeTwilio: {Tables: render.TableList, Math: render.MathText},
+}
+
+// RenderCapabilitiesFor reports which markdown features the channel type
+// renders natively. Unknown types get the zero value: everything is native.
+func RenderCapabilitiesFor(channelType string) render.Capabilities {
+	return renderCapabilities[channelType]
+}
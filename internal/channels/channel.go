@@ -73,12 +73,18 @@ const (
 // Channel type constants used across channel packages and gateway wiring.
 const (
 	TypeBitrix24     = "bitrix24"
+	TypeBridge       = "bridge"
 	TypeDiscord      = "discord"
+	TypeEmail        = "email"
 	TypeFacebook     = "facebook"
 	TypeFeishu       = "feishu"
+	TypeMatrix       = "matrix"
 	TypePancake      = "pancake"
+	TypeSignal       = "signal"
 	TypeSlack        = "slack"
+	TypeTeams        = "teams"
 	TypeTelegram     = "telegram"
+	TypeTwilio       = "twilio"
 	TypeWhatsApp     = "whatsapp"
 	TypeZaloOA       = "zalo_oa"
 	TypeZaloPersonal = "zalo_personal"
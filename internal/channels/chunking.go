@@ -2,6 +2,7 @@ package channels
 
 import (
 	"strings"
+	"unicode/utf16"
 	"unicode/utf8"
 )
 
@@ -121,3 +122,128 @@ func isInFence(window string) bool {
 func hasFencePrefix(s string) bool {
 	return len(s) >= 3 && s[0] == '`' && s[1] == '`' && s[2] == '`'
 }
+
+// SMS segment limits (3GPP TS 23.038/23.040). A message that does not fit in
+// one segment is sent concatenated, and each part loses room to the
+// concatenation header: 7 GSM-7 septets or 3 UCS-2 code units.
+const (
+	smsGSM7Single = 160
+	smsGSM7Part   = 153
+	smsUCS2Single = 70
+	smsUCS2Part   = 67
+)
+
+// gsm7Basic is the GSM 03.38 default alphabet (one septet per character,
+// escape code excluded); gsm7Extension holds the characters that take an
+// escape plus one septet.
+const (
+	gsm7Basic     = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+	gsm7Extension = "\f^{}\\[~]|€"
+)
+
+// gsm7Septets returns the number of septets r takes in GSM-7, or 0 if r
+// forces UCS-2 encoding.
+func gsm7Septets(r rune) int {
+	switch {
+	case strings.ContainsRune(gsm7Basic, r):
+		return 1
+	case strings.ContainsRune(gsm7Extension, r):
+		return 2
+	}
+	return 0
+}
+
+// SMSSegments returns the number of segments text is billed as and whether it
+// needs UCS-2 (any character outside GSM-7). UCS-2 length is counted in
+// UTF-16 code units, so emoji take two.
+func SMSSegments(text string) (segments int, ucs2 bool) {
+	if text == "" {
+		return 0, false
+	}
+	septets, units := 0, 0
+	for _, r := range text {
+		units += utf16.RuneLen(r)
+		if n := gsm7Septets(r); n > 0 && !ucs2 {
+			septets += n
+		} else {
+			ucs2 = true
+		}
+	}
+	if ucs2 {
+		return smsParts(units, smsUCS2Single, smsUCS2Part), true
+	}
+	return smsParts(septets, smsGSM7Single, smsGSM7Part), false
+}
+
+func smsParts(length, single, part int) int {
+	if length <= single {
+		return 1
+	}
+	return (length + part - 1) / part
+}
+
+// ChunkSMS splits plain text into messages of at most maxSegments segments
+// each. The encoding is decided per message, so a chunk without non-GSM
+// characters keeps the 160/153 limits. Prefers paragraph > line > space
+// boundaries in the second half of the window; short fragments would cost a
+// whole segment each.
+func ChunkSMS(text string, maxSegments int) []string {
+	text = strings.TrimSpace(text)
+	if text == "" || maxSegments <= 0 {
+		return nil
+	}
+
+	var chunks []string
+	for text != "" {
+		cutAt := smsFit(text, maxSegments)
+		if cutAt < len(text) {
+			if split := findSMSSplit(text[:cutAt]); split > 0 {
+				cutAt = split
+			}
+		}
+		if chunk := strings.TrimSpace(text[:cutAt]); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		text = strings.TrimSpace(text[cutAt:])
+	}
+	return chunks
+}
+
+// smsFit returns the byte length of the longest prefix of text that fits in
+// maxSegments segments. A prefix switches to UCS-2 limits as soon as it
+// contains a non-GSM character.
+func smsFit(text string, maxSegments int) int {
+	septets, units := 0, 0
+	ucs2 := false
+	for i, r := range text {
+		units += utf16.RuneLen(r)
+		if n := gsm7Septets(r); n > 0 && !ucs2 {
+			septets += n
+		} else {
+			ucs2 = true
+		}
+		fits := smsParts(septets, smsGSM7Single, smsGSM7Part) <= maxSegments
+		if ucs2 {
+			fits = smsParts(units, smsUCS2Single, smsUCS2Part) <= maxSegments
+		}
+		if !fits {
+			if i == 0 {
+				return len(string(r)) // cannot happen with real limits; avoid an endless loop
+			}
+			return i
+		}
+	}
+	return len(text)
+}
+
+// findSMSSplit returns the best split position in the second half of window,
+// or -1. Preference: paragraph (\n\n) > line (\n) > space.
+func findSMSSplit(window string) int {
+	half := len(window) / 2
+	for _, sep := range []string{"\n\n", "\n", " "} {
+		if i := strings.LastIndex(window, sep); i >= half {
+			return i + len(sep)
+		}
+	}
+	return -1
+}
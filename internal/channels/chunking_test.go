@@ -535,3 +535,84 @@ func TestChunkMarkdown_EmptyLines(t *testing.T) {
 		t.Fatal("content was lost with multiple empty lines")
 	}
 }
+
+// TestSMSSegments_Encoding tests GSM-7 and UCS-2 segment counting at the limits
+func TestSMSSegments_Encoding(t *testing.T) {
+	cases := []struct {
+		text     string
+		segments int
+		ucs2     bool
+	}{
+		{"", 0, false},
+		{strings.Repeat("a", 160), 1, false},
+		{strings.Repeat("a", 161), 2, false},
+		{strings.Repeat("a", 306), 2, false},
+		{strings.Repeat("a", 307), 3, false},
+		{strings.Repeat("€", 80), 1, false},  // extension chars take two septets
+		{strings.Repeat("€", 81), 2, false},  //
+		{strings.Repeat("é", 160), 1, false}, // é is in the GSM-7 basic set
+		{strings.Repeat("ă", 70), 1, true},
+		{strings.Repeat("ă", 71), 2, true},
+		{strings.Repeat("😀", 35), 1, true}, // surrogate pairs count twice
+		{strings.Repeat("😀", 36), 2, true},
+	}
+	for _, tc := range cases {
+		segments, ucs2 := SMSSegments(tc.text)
+		if segments != tc.segments || ucs2 != tc.ucs2 {
+			t.Errorf("SMSSegments(%d runes of %q) = %d, %v; want %d, %v",
+				len([]rune(tc.text)), []rune(tc.text + " ")[0], segments, ucs2, tc.segments, tc.ucs2)
+		}
+	}
+}
+
+// TestChunkSMS_RespectsSegmentLimit tests that every chunk fits maxSegments and no text is lost
+func TestChunkSMS_RespectsSegmentLimit(t *testing.T) {
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 40)
+	chunks := ChunkSMS(text, 2)
+	if len(chunks) < 2 {
+		t.Fatalf("expected multiple chunks, got %d", len(chunks))
+	}
+	for i, c := range chunks {
+		if n, _ := SMSSegments(c); n > 2 {
+			t.Errorf("chunk %d is %d segments", i, n)
+		}
+		if strings.HasPrefix(c, " ") || strings.HasSuffix(c, " ") {
+			t.Errorf("chunk %d not trimmed: %q", i, c)
+		}
+	}
+	if got := strings.Join(chunks, " "); got != strings.TrimSpace(text) {
+		t.Fatal("content was lost or reordered")
+	}
+}
+
+// TestChunkSMS_UCS2Limits tests that a non-GSM character switches the chunk to UCS-2 limits
+func TestChunkSMS_UCS2Limits(t *testing.T) {
+	text := strings.Repeat("xin chào ", 30) // "à" is GSM-7, so this stays 7-bit
+	chunks := ChunkSMS(text, 1)
+	if n, ucs2 := SMSSegments(chunks[0]); len(chunks) != 2 || n != 1 || ucs2 {
+		t.Fatalf("GSM-7 text: got %d chunks, first %d segments (ucs2=%v)", len(chunks), n, ucs2)
+	}
+	text = strings.Repeat("cảm ơn ", 30) // ả, ơ force UCS-2
+	for i, c := range ChunkSMS(text, 1) {
+		if n, ucs2 := SMSSegments(c); n != 1 || !ucs2 {
+			t.Errorf("chunk %d = %d segments (ucs2=%v): %q", i, n, ucs2, c)
+		}
+	}
+}
+
+// TestChunkSMS_PrefersParagraphBoundary tests splitting at a paragraph break in the second half
+func TestChunkSMS_PrefersParagraphBoundary(t *testing.T) {
+	first := strings.Repeat("a", 100)
+	second := strings.Repeat("b", 100)
+	chunks := ChunkSMS(first+"\n\n"+second, 1)
+	if len(chunks) != 2 || chunks[0] != first || chunks[1] != second {
+		t.Fatalf("unexpected chunks: %q", chunks)
+	}
+}
+
+// TestChunkSMS_EmptyOrZero tests that empty text or zero segments returns nil
+func TestChunkSMS_EmptyOrZero(t *testing.T) {
+	if ChunkSMS("  ", 3) != nil || ChunkSMS("hi", 0) != nil {
+		t.Fatal("expected nil")
+	}
+}
@@ -0,0 +1,154 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// actionCustomIDPrefix marks button custom_ids produced from bus.MessageAction.
+const actionCustomIDPrefix = "act:"
+
+// actionsPerRow is Discord's limit of buttons per action row.
+const actionsPerRow = 5
+
+// SupportsActions reports that actions render as message components.
+func (c *Channel) SupportsActions() bool { return true }
+
+// actionComponents renders actions as action rows of buttons.
+func actionComponents(actions []bus.MessageAction) []discordgo.MessageComponent {
+	var rows []discordgo.MessageComponent
+	for _, row := range channels.ActionRows(actions, actionsPerRow) {
+		buttons := make([]discordgo.MessageComponent, 0, len(row))
+		for _, a := range row {
+			btn := discordgo.Button{Label: a.Label, Style: discordgo.SecondaryButton}
+			switch {
+			case a.URL != "":
+				btn.Style = discordgo.LinkButton
+				btn.URL = a.URL
+			case a.Style == bus.ActionStylePrimary:
+				btn.Style = discordgo.PrimaryButton
+			case a.Style == bus.ActionStyleDanger:
+				btn.Style = discordgo.DangerButton
+			}
+			if a.URL == "" {
+				btn.CustomID = actionCustomIDPrefix + a.ID
+			}
+			buttons = append(buttons, btn)
+		}
+		rows = append(rows, discordgo.ActionsRow{Components: buttons})
+	}
+	return rows
+}
+
+// sendActions posts the buttons as a components-only message after the reply text.
+func (c *Channel) sendActions(channelID string, actions []bus.MessageAction) error {
+	if _, err := c.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Components: actionComponents(actions),
+	}); err != nil {
+		return fmt.Errorf("send discord actions: %w", err)
+	}
+	return nil
+}
+
+// handleInteraction routes clicks on our buttons into the bus.
+func (c *Channel) handleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+	data := i.MessageComponentData()
+	actionID, ok := strings.CutPrefix(data.CustomID, actionCustomIDPrefix)
+	if !ok || actionID == "" {
+		return
+	}
+	// Acknowledge within Discord's 3s window; the reply arrives as a new message.
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	}); err != nil {
+		slog.Debug("discord: interaction ack failed", "error", err)
+	}
+
+	user := i.User
+	if i.Member != nil && i.Member.User != nil {
+		user = i.Member.User
+	}
+	if user == nil || user.Bot {
+		return
+	}
+
+	ctx := store.WithTenantID(context.Background(), c.TenantID())
+	senderID := user.ID
+	channelID := i.ChannelID
+	isDM := i.GuildID == ""
+	peerKind := "group"
+	if isDM {
+		peerKind = "direct"
+	}
+	if !c.ActionAllowed(ctx, senderID, channelID, peerKind, c.config.DMPolicy, c.config.GroupPolicy) {
+		slog.Debug("discord action rejected by policy", "sender_id", senderID, "channel_id", channelID)
+		return
+	}
+
+	senderName := user.GlobalName
+	if i.Member != nil && i.Member.Nick != "" {
+		senderName = i.Member.Nick
+	}
+	if senderName == "" {
+		senderName = user.Username
+	}
+
+	var messageID, label string
+	if i.Message != nil {
+		messageID = i.Message.ID
+		label = buttonLabel(i.Message.Components, data.CustomID)
+	}
+	metadata := map[string]string{
+		"message_id":   messageID + ":" + actionID,
+		"user_id":      senderID,
+		"username":     user.Username,
+		"display_name": channels.SanitizeDisplayName(senderName),
+		"guild_id":     i.GuildID,
+		"channel_id":   channelID,
+		"is_dm":        fmt.Sprintf("%t", isDM),
+	}
+
+	c.HandleAction(senderID, channelID, senderName, bus.ActionCallback{
+		ActionID:  actionID,
+		Label:     label,
+		MessageID: messageID,
+	}, metadata, peerKind)
+}
+
+// buttonLabel finds the label of the clicked button on the original message.
+// Components decoded from the gateway are pointers; locally built ones are values.
+func buttonLabel(components []discordgo.MessageComponent, customID string) string {
+	for _, comp := range components {
+		var children []discordgo.MessageComponent
+		switch row := comp.(type) {
+		case *discordgo.ActionsRow:
+			children = row.Components
+		case discordgo.ActionsRow:
+			children = row.Components
+		}
+		for _, child := range children {
+			switch btn := child.(type) {
+			case *discordgo.Button:
+				if btn.CustomID == customID {
+					return btn.Label
+				}
+			case discordgo.Button:
+				if btn.CustomID == customID {
+					return btn.Label
+				}
+			}
+		}
+	}
+	return ""
+}
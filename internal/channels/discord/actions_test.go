@@ -0,0 +1,37 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+)
+
+func TestActionComponents(t *testing.T) {
+	actions := []bus.MessageAction{
+		{ID: "ok", Label: "OK", Style: bus.ActionStylePrimary},
+		{ID: "no", Label: "No", Style: bus.ActionStyleDanger},
+		{ID: "later", Label: "Later"},
+		{Label: "Docs", URL: "https://example.com"},
+		{ID: "e", Label: "E"},
+		{ID: "f", Label: "F"},
+	}
+	rows := actionComponents(actions)
+	if len(rows) != 2 {
+		t.Fatalf("rows = %d, want 2", len(rows))
+	}
+	first := rows[0].(discordgo.ActionsRow).Components
+	want := []discordgo.ButtonStyle{discordgo.PrimaryButton, discordgo.DangerButton, discordgo.SecondaryButton, discordgo.LinkButton}
+	for i, style := range want {
+		if got := first[i].(discordgo.Button).Style; got != style {
+			t.Errorf("button %d style = %v, want %v", i, got, style)
+		}
+	}
+	if link := first[3].(discordgo.Button); link.CustomID != "" || link.URL == "" {
+		t.Errorf("link button = %+v", link)
+	}
+	if got := buttonLabel(rows, "act:f"); got != "F" {
+		t.Errorf("buttonLabel = %q, want F", got)
+	}
+}
@@ -86,6 +86,7 @@ func (c *Channel) Start(_ context.Context) error {
 	slog.Info("starting discord bot")
 
 	c.session.AddHandler(c.handleMessage)
+	c.session.AddHandler(c.handleInteraction)
 
 	if err := c.session.Open(); err != nil {
 		return fmt.Errorf("open discord session: %w", err)
@@ -133,8 +134,19 @@ func (c *Channel) Stop(_ context.Context) error {
 	return c.session.Close()
 }
 
-// Send delivers an outbound message to a Discord channel.
-func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) (err error) {
+// Send delivers an outbound message to a Discord channel. Actions follow the
+// text as a components-only message.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if err := c.sendMessage(ctx, msg); err != nil {
+		return err
+	}
+	if len(msg.Actions) > 0 && msg.Metadata["placeholder_update"] != "true" {
+		return c.sendActions(msg.ChatID, msg.Actions)
+	}
+	return nil
+}
+
+func (c *Channel) sendMessage(ctx context.Context, msg bus.OutboundMessage) (err error) {
 	if !c.IsRunning() {
 		return fmt.Errorf("discord bot not running")
 	}
@@ -250,9 +262,10 @@ func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) (err error)
 			}
 
 			if _, editErr := c.session.ChannelMessageEdit(channelID, msgID, editContent); editErr == nil {
+				channels.RecordSentMessage(ctx, msgID)
 				// Send remaining content as follow-up messages
 				if remaining != "" {
-					return c.sendChunked(channelID, remaining)
+					return c.sendChunked(ctx, channelID, remaining)
 				}
 				return nil
 			} else {
@@ -264,18 +277,20 @@ func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) (err error)
 	}
 
 	// Send as new message(s), chunking if needed
-	return c.sendChunked(channelID, content)
+	return c.sendChunked(ctx, channelID, content)
 }
 
 // sendChunked sends a message, splitting into multiple messages if over 2000 chars.
 // Uses markdown-aware chunking to avoid splitting inside fenced code blocks.
-func (c *Channel) sendChunked(channelID, content string) error {
+func (c *Channel) sendChunked(ctx context.Context, channelID, content string) error {
 	const maxLen = 2000
 
 	for _, chunk := range channels.ChunkMarkdown(content, maxLen) {
-		if _, err := c.session.ChannelMessageSend(channelID, chunk); err != nil {
+		sent, err := c.session.ChannelMessageSend(channelID, chunk)
+		if err != nil {
 			return fmt.Errorf("send discord message: %w", err)
 		}
+		channels.RecordSentMessage(ctx, sent.ID)
 	}
 
 	return nil
@@ -0,0 +1,28 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+)
+
+// discordMaxMessageLen is Discord's per-message content limit.
+const discordMaxMessageLen = 2000
+
+// EditMessage replaces the content of a message the bot sent to a channel.
+func (c *Channel) EditMessage(_ context.Context, chatID, messageID, content string) error {
+	if len(content) > discordMaxMessageLen {
+		return fmt.Errorf("edited text exceeds discord message limit (%d)", discordMaxMessageLen)
+	}
+	if _, err := c.session.ChannelMessageEdit(chatID, messageID, content); err != nil {
+		return fmt.Errorf("edit discord message: %w", err)
+	}
+	return nil
+}
+
+// DeleteMessage removes a message the bot sent to a channel.
+func (c *Channel) DeleteMessage(_ context.Context, chatID, messageID string) error {
+	if err := c.session.ChannelMessageDelete(chatID, messageID); err != nil {
+		return fmt.Errorf("delete discord message: %w", err)
+	}
+	return nil
+}
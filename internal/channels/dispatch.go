@@ -70,6 +70,18 @@ func (m *Manager) dispatchOutbound(ctx context.Context) {
 				}
 			}
 
+			// Tables, long code and math the channel cannot show natively are
+			// rewritten or attached as files.
+			cleanupRendered := renderOutbound(channel.Type(), &msg)
+
+			// Channels without native buttons get the actions as a text list.
+			if len(msg.Actions) > 0 {
+				if ac, ok := channel.(ActionChannel); !ok || !ac.SupportsActions() {
+					msg.Content += ActionsFallbackText(msg.Actions)
+					msg.Actions = nil
+				}
+			}
+
 			// Add tenant context for per-tenant TTS auto-apply
 			sendCtx := ctx
 			if msg.TenantID != uuid.Nil {
@@ -84,6 +96,13 @@ func (m *Manager) dispatchOutbound(ctx context.Context) {
 				})
 			}
 
+			// Collect the platform IDs of messages sent for an agent run so the
+			// reply can be edited or retracted later.
+			var sent *sentRecorder
+			if msg.Metadata["run_id"] != "" && m.sentMessageStore() != nil {
+				sendCtx, sent = withSentRecorder(sendCtx)
+			}
+
 			if err := channel.Send(sendCtx, msg); err != nil {
 				slog.Error("error sending message to channel",
 					"channel", msg.Channel,
@@ -110,6 +129,10 @@ func (m *Manager) dispatchOutbound(ctx context.Context) {
 				}
 			}
 
+			if sent != nil {
+				m.recordSent(sendCtx, msg, sent.messageIDs())
+			}
+
 			// Clean up temp media files only. Workspace-generated files are preserved
 			// so they remain accessible via workspace/web UI after delivery.
 			tmpDir := os.TempDir()
@@ -120,6 +143,7 @@ func (m *Manager) dispatchOutbound(ctx context.Context) {
 					}
 				}
 			}
+			cleanupRendered()
 		}
 	}
 }
@@ -156,6 +180,8 @@ func (m *Manager) SendToChannel(ctx context.Context, channelName, chatID, conten
 		ChatID:  chatID,
 		Content: content,
 	}
+	cleanup := renderOutbound(channel.Type(), &msg)
+	defer cleanup()
 
 	return channel.Send(ctx, msg)
 }
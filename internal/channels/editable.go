@@ -0,0 +1,190 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+var (
+	// ErrNotEditable is returned when a channel cannot edit or delete sent messages.
+	ErrNotEditable = errors.New("channel does not support editing sent messages")
+	// ErrSentMessagesUnavailable is returned when sent message tracking is not configured.
+	ErrSentMessagesUnavailable = errors.New("sent message tracking not available")
+	// ErrMessageRetracted is returned when editing a message that was already retracted.
+	ErrMessageRetracted = errors.New("message was retracted")
+)
+
+// EditableChannel is optionally implemented by channels that can change or
+// remove messages after delivery. chatID is the outbound ChatID the message
+// was sent to; messageID is the platform ID reported via RecordSentMessage.
+type EditableChannel interface {
+	EditMessage(ctx context.Context, chatID, messageID, content string) error
+	DeleteMessage(ctx context.Context, chatID, messageID string) error
+}
+
+// sentRecorderKey is the context key for the per-send message ID recorder.
+type sentRecorderKey struct{}
+
+type sentRecorder struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func withSentRecorder(ctx context.Context) (context.Context, *sentRecorder) {
+	r := &sentRecorder{}
+	return context.WithValue(ctx, sentRecorderKey{}, r), r
+}
+
+func (r *sentRecorder) messageIDs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.ids...)
+}
+
+// RecordSentMessage notes the platform ID of a message produced while
+// delivering the current outbound message (including a placeholder edited
+// into the reply). Channels call it from their send paths; it is a no-op
+// outside the outbound dispatcher.
+func RecordSentMessage(ctx context.Context, messageID string) {
+	r, _ := ctx.Value(sentRecorderKey{}).(*sentRecorder)
+	if r == nil || messageID == "" {
+		return
+	}
+	r.mu.Lock()
+	r.ids = append(r.ids, messageID)
+	r.mu.Unlock()
+}
+
+// SetSentMessageStore enables per-run tracking of delivered message IDs.
+func (m *Manager) SetSentMessageStore(s store.SentMessageStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sentMessages = s
+}
+
+func (m *Manager) sentMessageStore() store.SentMessageStore {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sentMessages
+}
+
+// recordSent persists the message IDs collected while delivering msg.
+func (m *Manager) recordSent(ctx context.Context, msg bus.OutboundMessage, ids []string) {
+	s := m.sentMessageStore()
+	if s == nil {
+		return
+	}
+	var agentID *uuid.UUID
+	if msg.AgentID != uuid.Nil {
+		agentID = &msg.AgentID
+	}
+	preview := Truncate(msg.Content, 200)
+	for _, id := range ids {
+		if err := s.RecordSentMessage(ctx, &store.SentMessage{
+			RunID:     msg.Metadata["run_id"],
+			AgentID:   agentID,
+			Channel:   msg.Channel,
+			ChatID:    msg.ChatID,
+			MessageID: id,
+			Preview:   preview,
+		}); err != nil {
+			slog.Warn("record sent message failed", "channel", msg.Channel, "message_id", id, "error", err)
+		}
+	}
+}
+
+// EditMessage replaces the text of a message previously delivered to chatID.
+func (m *Manager) EditMessage(ctx context.Context, channelName, chatID, messageID, content string) error {
+	ec, err := m.editableChannel(channelName)
+	if err != nil {
+		return err
+	}
+	return ec.EditMessage(ctx, chatID, messageID, content)
+}
+
+// DeleteMessage removes a message previously delivered to chatID.
+func (m *Manager) DeleteMessage(ctx context.Context, channelName, chatID, messageID string) error {
+	ec, err := m.editableChannel(channelName)
+	if err != nil {
+		return err
+	}
+	return ec.DeleteMessage(ctx, chatID, messageID)
+}
+
+func (m *Manager) editableChannel(channelName string) (EditableChannel, error) {
+	m.mu.RLock()
+	ch, ok := m.channels[channelName]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("channel %s not found", channelName)
+	}
+	ec, ok := ch.(EditableChannel)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (%s)", ErrNotEditable, channelName, ch.Type())
+	}
+	return ec, nil
+}
+
+// ListSentMessages returns tracked messages for a run or a chat, newest first.
+func (m *Manager) ListSentMessages(ctx context.Context, opts store.SentMessageListOpts) ([]store.SentMessage, error) {
+	s := m.sentMessageStore()
+	if s == nil {
+		return nil, ErrSentMessagesUnavailable
+	}
+	return s.ListSentMessages(ctx, opts)
+}
+
+// EditSentMessage edits a tracked message in place and marks it edited.
+func (m *Manager) EditSentMessage(ctx context.Context, id uuid.UUID, content string) (*store.SentMessage, error) {
+	s := m.sentMessageStore()
+	if s == nil {
+		return nil, ErrSentMessagesUnavailable
+	}
+	sm, err := s.GetSentMessage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sm.Status == store.SentMessageStatusRetracted {
+		return nil, ErrMessageRetracted
+	}
+	if err := m.EditMessage(ctx, sm.Channel, sm.ChatID, sm.MessageID, content); err != nil {
+		return nil, err
+	}
+	if err := s.UpdateSentMessageStatus(ctx, sm.ID, store.SentMessageStatusEdited); err != nil {
+		slog.Warn("mark sent message edited failed", "id", sm.ID, "error", err)
+	}
+	sm.Status = store.SentMessageStatusEdited
+	return sm, nil
+}
+
+// RetractSentMessage deletes a tracked message from its chat and marks it
+// retracted. Retracting an already retracted message is a no-op.
+func (m *Manager) RetractSentMessage(ctx context.Context, id uuid.UUID) (*store.SentMessage, error) {
+	s := m.sentMessageStore()
+	if s == nil {
+		return nil, ErrSentMessagesUnavailable
+	}
+	sm, err := s.GetSentMessage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sm.Status == store.SentMessageStatusRetracted {
+		return sm, nil
+	}
+	if err := m.DeleteMessage(ctx, sm.Channel, sm.ChatID, sm.MessageID); err != nil {
+		return nil, err
+	}
+	if err := s.UpdateSentMessageStatus(ctx, sm.ID, store.SentMessageStatusRetracted); err != nil {
+		slog.Warn("mark sent message retracted failed", "id", sm.ID, "error", err)
+	}
+	sm.Status = store.SentMessageStatusRetracted
+	return sm, nil
+}
@@ -0,0 +1,128 @@
+package channels
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+type editableMockChannel struct {
+	*mockChannel
+	edits   map[string]string
+	deletes []string
+}
+
+func (e *editableMockChannel) EditMessage(_ context.Context, _, messageID, content string) error {
+	e.edits[messageID] = content
+	return nil
+}
+
+func (e *editableMockChannel) DeleteMessage(_ context.Context, _, messageID string) error {
+	e.deletes = append(e.deletes, messageID)
+	return nil
+}
+
+type memSentMessageStore struct {
+	msgs map[uuid.UUID]*store.SentMessage
+}
+
+func (s *memSentMessageStore) RecordSentMessage(_ context.Context, msg *store.SentMessage) error {
+	msg.ID = uuid.New()
+	msg.Status = store.SentMessageStatusSent
+	cp := *msg
+	s.msgs[msg.ID] = &cp
+	return nil
+}
+
+func (s *memSentMessageStore) GetSentMessage(_ context.Context, id uuid.UUID) (*store.SentMessage, error) {
+	msg, ok := s.msgs[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	cp := *msg
+	return &cp, nil
+}
+
+func (s *memSentMessageStore) ListSentMessages(_ context.Context, opts store.SentMessageListOpts) ([]store.SentMessage, error) {
+	var out []store.SentMessage
+	for _, msg := range s.msgs {
+		if msg.RunID == opts.RunID {
+			out = append(out, *msg)
+		}
+	}
+	return out, nil
+}
+
+func (s *memSentMessageStore) UpdateSentMessageStatus(_ context.Context, id uuid.UUID, status string) error {
+	msg, ok := s.msgs[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	msg.Status = status
+	return nil
+}
+
+func TestRecordSentMessage_OnlyInsideRecorder(t *testing.T) {
+	RecordSentMessage(context.Background(), "ignored")
+
+	ctx, rec := withSentRecorder(context.Background())
+	RecordSentMessage(ctx, "1")
+	RecordSentMessage(ctx, "")
+	RecordSentMessage(ctx, "2")
+	if got := rec.messageIDs(); len(got) != 2 || got[0] != "1" || got[1] != "2" {
+		t.Fatalf("messageIDs = %v, want [1 2]", got)
+	}
+}
+
+func TestManagerEditAndRetractSentMessage(t *testing.T) {
+	mgr := NewManager(bus.New())
+	ch := &editableMockChannel{mockChannel: newMockChannel("tg", TypeTelegram), edits: map[string]string{}}
+	mgr.channels["tg"] = ch
+	mgr.channels["plain"] = newMockChannel("plain", TypeTelegram)
+	sent := &memSentMessageStore{msgs: map[uuid.UUID]*store.SentMessage{}}
+	mgr.SetSentMessageStore(sent)
+
+	ctx := context.Background()
+	mgr.recordSent(ctx, bus.OutboundMessage{
+		Channel: "tg", ChatID: "42", Content: "hello",
+		Metadata: map[string]string{"run_id": "run-1"},
+	}, []string{"7"})
+	msgs, err := mgr.ListSentMessages(ctx, store.SentMessageListOpts{RunID: "run-1"})
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("ListSentMessages = %v, %v; want one message", msgs, err)
+	}
+	id := msgs[0].ID
+
+	if _, err := mgr.EditSentMessage(ctx, id, "hello, corrected"); err != nil {
+		t.Fatalf("EditSentMessage: %v", err)
+	}
+	if ch.edits["7"] != "hello, corrected" {
+		t.Fatalf("edits = %v", ch.edits)
+	}
+
+	for range 2 {
+		sm, err := mgr.RetractSentMessage(ctx, id)
+		if err != nil {
+			t.Fatalf("RetractSentMessage: %v", err)
+		}
+		if sm.Status != store.SentMessageStatusRetracted {
+			t.Fatalf("status = %q, want retracted", sm.Status)
+		}
+	}
+	if len(ch.deletes) != 1 {
+		t.Fatalf("deletes = %v, want one platform delete", ch.deletes)
+	}
+	if _, err := mgr.EditSentMessage(ctx, id, "again"); !errors.Is(err, ErrMessageRetracted) {
+		t.Fatalf("edit after retract err = %v, want ErrMessageRetracted", err)
+	}
+
+	if err := mgr.DeleteMessage(ctx, "plain", "42", "8"); !errors.Is(err, ErrNotEditable) {
+		t.Fatalf("plain channel err = %v, want ErrNotEditable", err)
+	}
+}
@@ -0,0 +1,82 @@
+package email
+
+import (
+	"slices"
+	"strings"
+)
+
+// senderVerified reports whether a trusted receiving server vouched for the
+// From address: an Authentication-Results header (RFC 8601) whose authserv-id
+// is in trusted, with dmarc=pass for the From domain or dkim=pass for a
+// signing domain aligned with it. Headers from other authserv-ids are ignored,
+// since anyone can add them before the mail reaches the trusted server.
+func senderVerified(results []string, trusted []string, from string) bool {
+	_, domain, ok := strings.Cut(from, "@")
+	if !ok || domain == "" {
+		return false
+	}
+	domain = strings.ToLower(domain)
+	for _, header := range results {
+		parts := strings.Split(stripComments(header), ";")
+		id := strings.Fields(parts[0])
+		if len(id) == 0 || !slices.ContainsFunc(trusted, func(t string) bool { return strings.EqualFold(t, id[0]) }) {
+			continue
+		}
+		for _, part := range parts[1:] {
+			method, result, props := parseAuthResult(part)
+			if result != "pass" {
+				continue
+			}
+			switch method {
+			case "dmarc":
+				if strings.EqualFold(props["header.from"], domain) {
+					return true
+				}
+			case "dkim":
+				d := props["header.d"]
+				if d == "" {
+					_, d, _ = strings.Cut(props["header.i"], "@")
+				}
+				if d = strings.ToLower(d); d != "" && (domain == d || strings.HasSuffix(domain, "."+d)) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// parseAuthResult splits one resinfo ("dkim=pass header.d=example.com") into
+// its method, result and ptype.property values.
+func parseAuthResult(s string) (method, result string, props map[string]string) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return "", "", nil
+	}
+	method, result, _ = strings.Cut(fields[0], "=")
+	props = make(map[string]string, len(fields)-1)
+	for _, f := range fields[1:] {
+		if k, v, ok := strings.Cut(f, "="); ok {
+			props[strings.ToLower(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return strings.ToLower(method), strings.ToLower(result), props
+}
+
+// stripComments removes RFC 5322 parenthesized comments, which may contain
+// ";" or "=" (e.g. "dmarc=pass (p=REJECT sp=REJECT dis=NONE)").
+func stripComments(s string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
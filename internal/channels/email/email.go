@@ -0,0 +1,351 @@
+// Package email implements a GoClaw channel over a mailbox: IMAP (IDLE with a
+// polling fallback) for inbound mail and SMTP for replies. Each email thread,
+// identified by its Message-ID chain, is one conversation.
+package email
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/safego"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	defaultPollInterval  = 60 * time.Second
+	idleRefresh          = 10 * time.Minute // RFC 2177: re-issue IDLE well within 29 minutes
+	reconnectBackoffMax  = 5 * time.Minute
+	defaultMediaMaxBytes = 20 * 1024 * 1024 // 20MB
+	pairingDebounce      = 60 * time.Second
+	threadIndexTTL       = 30 * 24 * time.Hour
+)
+
+// Channel watches one IMAP mailbox and answers over SMTP.
+type Channel struct {
+	*channels.BaseChannel
+	config        emailInstanceConfig
+	creds         emailCreds
+	fromAddress   string
+	mediaMaxBytes int64
+	pollInterval  time.Duration
+
+	threads sync.Map // local_key -> *threadState (reply headers for the next outbound mail)
+	index   sync.Map // Message-ID -> indexEntry (thread ID it belongs to)
+	dedup   sync.Map // Message-ID -> time.Time
+
+	imapMu sync.Mutex
+	imap   *imapClient // current connection, closed by Stop
+
+	wg       sync.WaitGroup
+	cancelFn context.CancelFunc
+}
+
+// Compile-time interface assertions.
+var _ channels.Channel = (*Channel)(nil)
+var _ channels.ChatBehaviorChannel = (*Channel)(nil)
+
+// New creates a new email channel from instance config and credentials.
+func New(cfg emailInstanceConfig, creds emailCreds, msgBus *bus.MessageBus, pairingSvc store.PairingStore) (*Channel, error) {
+	if cfg.Username == "" || creds.Password == "" {
+		return nil, fmt.Errorf("email username and password are required")
+	}
+	if cfg.IMAPHost == "" || cfg.SMTPHost == "" {
+		return nil, fmt.Errorf("email imap_host and smtp_host are required")
+	}
+	from := cfg.FromAddress
+	if from == "" {
+		from = cfg.Username
+	}
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("email from_address %q is not a valid address", from)
+	}
+
+	if cfg.IMAPSecurity == "" {
+		cfg.IMAPSecurity = "tls"
+	}
+	if cfg.IMAPPort == 0 {
+		cfg.IMAPPort = 993
+		if cfg.IMAPSecurity != "tls" {
+			cfg.IMAPPort = 143
+		}
+	}
+	if cfg.SMTPSecurity == "" {
+		cfg.SMTPSecurity = "starttls"
+		if cfg.SMTPPort == 465 {
+			cfg.SMTPSecurity = "tls"
+		}
+	}
+	if cfg.SMTPPort == 0 {
+		cfg.SMTPPort = 587
+		if cfg.SMTPSecurity == "tls" {
+			cfg.SMTPPort = 465
+		}
+	}
+	for _, mode := range []string{cfg.IMAPSecurity, cfg.SMTPSecurity} {
+		if mode != "tls" && mode != "starttls" && mode != "none" {
+			return nil, fmt.Errorf("email security must be tls, starttls or none, got %q", mode)
+		}
+	}
+	if cfg.Mailbox == "" {
+		cfg.Mailbox = "INBOX"
+	}
+	if len(cfg.TrustedAuthservIDs) == 0 && !cfg.AllowUnauthenticated {
+		slog.Warn("email: trusted_authserv_ids is empty, so no sender can be verified and all inbound mail is dropped", "username", cfg.Username)
+	}
+
+	base := channels.NewBaseChannel(channels.TypeEmail, msgBus, cfg.AllowFrom)
+	base.ValidatePolicy(cfg.DMPolicy, "")
+
+	mediaMax := int64(cfg.MediaMaxMB) * 1024 * 1024
+	if mediaMax <= 0 {
+		mediaMax = defaultMediaMaxBytes
+	}
+	poll := time.Duration(cfg.PollIntervalSec) * time.Second
+	if poll <= 0 {
+		poll = defaultPollInterval
+	}
+
+	ch := &Channel{
+		BaseChannel:   base,
+		config:        cfg,
+		creds:         creds,
+		fromAddress:   strings.ToLower(addr.Address),
+		mediaMaxBytes: mediaMax,
+		pollInterval:  poll,
+	}
+	ch.SetPairingService(pairingSvc)
+	return ch, nil
+}
+
+// Start logs in once to surface credential errors, then watches the mailbox
+// in the background.
+func (c *Channel) Start(ctx context.Context) error {
+	c.MarkStarting("connecting to mailbox")
+
+	cl, err := c.connect(ctx)
+	if err != nil {
+		kind := channels.ChannelFailureKindNetwork
+		if isAuthError(err) {
+			kind = channels.ChannelFailureKindAuth
+		}
+		c.MarkFailed("imap login failed", err.Error(), kind, kind != channels.ChannelFailureKindAuth)
+		return fmt.Errorf("email imap login failed: %w", err)
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	c.cancelFn = cancel
+
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		defer safego.Recover(nil, "component", "email_watch")
+		c.watchLoop(watchCtx, cl)
+	}()
+	go func() {
+		defer c.wg.Done()
+		defer safego.Recover(nil, "component", "email_sweep")
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-watchCtx.Done():
+				return
+			case <-ticker.C:
+				c.sweepMaps()
+			}
+		}
+	}()
+
+	c.SetRunning(true)
+	c.MarkHealthy("watching " + c.config.Mailbox + " as " + c.config.Username)
+	slog.Info("email channel connected", "username", c.config.Username, "imap_host", c.config.IMAPHost, "idle", cl.supportsIdle() && !c.config.DisableIdle)
+	return nil
+}
+
+// Stop cancels the watcher and closes the IMAP connection.
+func (c *Channel) Stop(_ context.Context) error {
+	slog.Info("stopping email channel")
+	c.SetRunning(false)
+
+	if c.cancelFn != nil {
+		c.cancelFn()
+	}
+	c.imapMu.Lock()
+	if c.imap != nil {
+		c.imap.Close() // unblocks a pending IDLE read
+	}
+	c.imapMu.Unlock()
+
+	doneCh := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(doneCh)
+	}()
+	select {
+	case <-doneCh:
+	case <-time.After(10 * time.Second):
+		slog.Warn("email channel stop timed out after 10s")
+	}
+	c.MarkStopped("stopped")
+	return nil
+}
+
+// ChatBehaviorConfig returns the per-channel chat_behavior override.
+func (c *Channel) ChatBehaviorConfig() *config.ChatBehaviorConfig { return c.config.ChatBehavior }
+
+// connect dials IMAP, logs in and selects the mailbox.
+func (c *Channel) connect(ctx context.Context) (*imapClient, error) {
+	// Whole-message literals include base64 overhead (~4/3) on top of attachments.
+	cl, err := dialIMAP(ctx, c.config.IMAPHost, c.config.IMAPPort, c.config.IMAPSecurity, c.mediaMaxBytes*2)
+	if err != nil {
+		return nil, err
+	}
+	if err := cl.login(c.config.Username, c.creds.Password); err != nil {
+		cl.Close()
+		return nil, err
+	}
+	if err := cl.selectMailbox(c.config.Mailbox); err != nil {
+		cl.Close()
+		return nil, err
+	}
+	c.imapMu.Lock()
+	c.imap = cl
+	c.imapMu.Unlock()
+	return cl, nil
+}
+
+// watchLoop runs mailbox sessions until ctx is cancelled, reconnecting with
+// exponential backoff. A rejected login stops the loop.
+func (c *Channel) watchLoop(ctx context.Context, cl *imapClient) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		if cl == nil {
+			var err error
+			if cl, err = c.connect(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if isAuthError(err) {
+					slog.Error("email: imap login rejected, stopping", "error", err)
+					c.MarkFailed("imap login rejected", err.Error(), channels.ChannelFailureKindAuth, false)
+					c.SetRunning(false)
+					return
+				}
+				slog.Warn("email: imap reconnect failed, retrying", "error", err, "backoff", backoff)
+				c.MarkDegraded("imap reconnect failed", err.Error(), channels.ChannelFailureKindNetwork, true)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+				backoff = min(backoff*2, reconnectBackoffMax)
+				continue
+			}
+			if backoff > time.Second {
+				c.MarkHealthy("watching " + c.config.Mailbox + " as " + c.config.Username)
+			}
+			backoff = time.Second
+		}
+
+		err := c.session(ctx, cl)
+		cl.Close()
+		cl = nil
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("email: imap session ended, reconnecting", "error", err, "backoff", backoff)
+		c.MarkDegraded("imap connection lost", fmt.Sprint(err), channels.ChannelFailureKindNetwork, true)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// session processes unseen mail, then waits for more via IDLE or polling,
+// until the connection fails or ctx is cancelled.
+func (c *Channel) session(ctx context.Context, cl *imapClient) error {
+	useIdle := cl.supportsIdle() && !c.config.DisableIdle
+	for {
+		if err := c.fetchUnseen(ctx, cl); err != nil {
+			return err
+		}
+		if useIdle {
+			if _, err := cl.idle(ctx, idleRefresh); err != nil {
+				return err
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
+
+// fetchUnseen handles every unseen message and flags it \Seen afterwards, so
+// mail that arrived while the gateway was down is answered on reconnect.
+func (c *Channel) fetchUnseen(ctx context.Context, cl *imapClient) error {
+	uids, err := cl.searchUnseen()
+	if err != nil {
+		return err
+	}
+	for _, uid := range uids {
+		if ctx.Err() != nil {
+			return nil
+		}
+		raw, err := cl.fetchRaw(uid)
+		switch {
+		case err == errLiteralTooLarge:
+			slog.Warn("email: message exceeds size limit, skipped", "uid", uid)
+		case err != nil:
+			return err
+		default:
+			c.handleRaw(ctx, raw)
+		}
+		if err := cl.markSeen(uid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Channel) sweepMaps() {
+	now := time.Now()
+	c.dedup.Range(func(k, v any) bool {
+		if now.Sub(v.(time.Time)) > 24*time.Hour {
+			c.dedup.Delete(k)
+		}
+		return true
+	})
+	c.index.Range(func(k, v any) bool {
+		if now.Sub(v.(indexEntry).seen) > threadIndexTTL {
+			c.index.Delete(k)
+		}
+		return true
+	})
+	c.threads.Range(func(k, v any) bool {
+		st := v.(*threadState)
+		st.mu.Lock()
+		stale := now.Sub(st.updated) > threadIndexTTL
+		st.mu.Unlock()
+		if stale {
+			c.threads.Delete(k)
+		}
+		return true
+	})
+}
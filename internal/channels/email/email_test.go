@@ -0,0 +1,513 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+)
+
+// fakeIMAP is a single-mailbox IMAP server speaking just enough of the
+// protocol for the client: LOGIN, CAPABILITY, SELECT, UID SEARCH/FETCH/STORE, IDLE.
+type fakeIMAP struct {
+	ln     net.Listener
+	mu     sync.Mutex
+	msgs   []fakeMessage
+	notify chan struct{}
+}
+
+type fakeMessage struct {
+	uid  int
+	raw  string
+	seen bool
+}
+
+func newFakeIMAP(t *testing.T) *fakeIMAP {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeIMAP{ln: ln, notify: make(chan struct{}, 1)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeIMAP) port() int { return f.ln.Addr().(*net.TCPAddr).Port }
+
+func (f *fakeIMAP) add(raw string) {
+	f.mu.Lock()
+	f.msgs = append(f.msgs, fakeMessage{uid: len(f.msgs) + 1, raw: strings.ReplaceAll(raw, "\n", "\r\n")})
+	f.mu.Unlock()
+	select {
+	case f.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (f *fakeIMAP) unseen() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, m := range f.msgs {
+		if !m.seen {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *fakeIMAP) serve(conn net.Conn) {
+	defer conn.Close()
+	var wmu sync.Mutex
+	write := func(format string, args ...any) {
+		wmu.Lock()
+		defer wmu.Unlock()
+		fmt.Fprintf(conn, format, args...)
+	}
+	write("* OK fake imap ready\r\n")
+
+	r := bufio.NewReader(conn)
+	var idleTag string
+	var idleStop chan struct{}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if idleTag != "" {
+			if line == "DONE" {
+				close(idleStop)
+				write("%s OK IDLE terminated\r\n", idleTag)
+				idleTag = ""
+			}
+			continue
+		}
+		tag, cmd, _ := strings.Cut(line, " ")
+		switch {
+		case strings.HasPrefix(cmd, "LOGIN "):
+			if strings.HasSuffix(cmd, `"secret"`) {
+				write("%s OK logged in\r\n", tag)
+			} else {
+				write("%s NO [AUTHENTICATIONFAILED] invalid credentials\r\n", tag)
+			}
+		case cmd == "CAPABILITY":
+			write("* CAPABILITY IMAP4rev1 IDLE\r\n%s OK done\r\n", tag)
+		case strings.HasPrefix(cmd, "SELECT "):
+			f.mu.Lock()
+			n := len(f.msgs)
+			f.mu.Unlock()
+			write("* %d EXISTS\r\n%s OK [READ-WRITE] selected\r\n", n, tag)
+		case cmd == "UID SEARCH UNSEEN":
+			f.mu.Lock()
+			var uids []string
+			for _, m := range f.msgs {
+				if !m.seen {
+					uids = append(uids, strconv.Itoa(m.uid))
+				}
+			}
+			f.mu.Unlock()
+			write("* SEARCH %s\r\n%s OK search done\r\n", strings.Join(uids, " "), tag)
+		case strings.HasPrefix(cmd, "UID FETCH "):
+			uid, _ := strconv.Atoi(strings.Fields(cmd)[2])
+			f.mu.Lock()
+			raw := f.msgs[uid-1].raw
+			f.mu.Unlock()
+			write("* %d FETCH (UID %d BODY[] {%d}\r\n%s)\r\n%s OK fetch done\r\n", uid, uid, len(raw), raw, tag)
+		case strings.HasPrefix(cmd, "UID STORE "):
+			uid, _ := strconv.Atoi(strings.Fields(cmd)[2])
+			f.mu.Lock()
+			f.msgs[uid-1].seen = true
+			f.mu.Unlock()
+			write("%s OK store done\r\n", tag)
+		case cmd == "IDLE":
+			idleTag, idleStop = tag, make(chan struct{})
+			write("+ idling\r\n")
+			go func(stop chan struct{}) {
+				select {
+				case <-f.notify:
+					f.mu.Lock()
+					n := len(f.msgs)
+					f.mu.Unlock()
+					write("* %d EXISTS\r\n", n)
+				case <-stop:
+				}
+			}(idleStop)
+		case cmd == "LOGOUT":
+			write("* BYE\r\n%s OK bye\r\n", tag)
+			return
+		default:
+			write("%s BAD unknown command\r\n", tag)
+		}
+	}
+}
+
+// fakeSMTP accepts mail and hands each DATA payload to sent.
+type fakeSMTP struct {
+	ln   net.Listener
+	sent chan string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeSMTP{ln: ln, sent: make(chan string, 10)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeSMTP) port() int { return f.ln.Addr().(*net.TCPAddr).Port }
+
+func (f *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	fmt.Fprint(conn, "220 fake smtp\r\n")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimRight(line, "\r\n"))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			fmt.Fprint(conn, "250-fake\r\n250-AUTH PLAIN\r\n250 8BITMIME\r\n")
+		case strings.HasPrefix(cmd, "AUTH PLAIN"):
+			fmt.Fprint(conn, "235 ok\r\n")
+		case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+			fmt.Fprint(conn, "250 ok\r\n")
+		case cmd == "DATA":
+			fmt.Fprint(conn, "354 go ahead\r\n")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(strings.TrimPrefix(l, "."))
+			}
+			f.sent <- b.String()
+			fmt.Fprint(conn, "250 queued\r\n")
+		case cmd == "QUIT":
+			fmt.Fprint(conn, "221 bye\r\n")
+			return
+		default:
+			fmt.Fprint(conn, "250 ok\r\n")
+		}
+	}
+}
+
+func newTestChannel(t *testing.T, imapPort, smtpPort int, cfg emailInstanceConfig) (*Channel, *bus.MessageBus) {
+	t.Helper()
+	cfg.Username = "bot@example.net"
+	cfg.FromName = "Support Bot"
+	cfg.IMAPHost, cfg.IMAPPort, cfg.IMAPSecurity = "127.0.0.1", imapPort, "none"
+	cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPSecurity = "127.0.0.1", smtpPort, "none"
+	if cfg.TrustedAuthservIDs == nil {
+		cfg.TrustedAuthservIDs = []string{"mx.example.net"}
+	}
+	mb := bus.New()
+	ch, err := New(cfg, emailCreds{Password: "secret"}, mb, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch.SetName("support-mail")
+	return ch, mb
+}
+
+func nextInbound(t *testing.T, mb *bus.MessageBus) bus.InboundMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msg, ok := mb.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("timed out waiting for inbound message")
+	}
+	return msg
+}
+
+const rootMail = `Authentication-Results: mx.example.net; dkim=pass header.d=example.com; dmarc=pass (p=REJECT) header.from=example.com
+From: Alice <alice@example.com>
+To: bot@example.net
+Subject: Invoice question
+Message-ID: <root@example.com>
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="outer"
+
+--outer
+Content-Type: text/plain; charset=utf-8
+
+Hello, where is my invoice?
+--outer
+Content-Type: image/png; name="receipt.png"
+Content-Disposition: attachment; filename="receipt.png"
+Content-Transfer-Encoding: base64
+
+iVBORw0KGgo=
+--outer--
+`
+
+const followUpMail = `Authentication-Results: mx.example.net; dmarc=pass header.from=example.com
+From: Alice <Alice@Example.com>
+To: bot@example.net
+Subject: Re: Invoice question
+Message-ID: <reply1@example.com>
+In-Reply-To: <root@example.com>
+References: <root@example.com>
+MIME-Version: 1.0
+Content-Type: text/plain; charset=iso-8859-1
+Content-Transfer-Encoding: quoted-printable
+
+Sent from the caf=E9.
+
+On Mon, 1 Jan 2024, Support Bot wrote:
+> Your invoice is attached.
+`
+
+func TestThreadedMailRoundTrip(t *testing.T) {
+	imapSrv := newFakeIMAP(t)
+	smtpSrv := newFakeSMTP(t)
+	imapSrv.add(rootMail)
+	imapSrv.add(followUpMail)
+
+	ch, mb := newTestChannel(t, imapSrv.port(), smtpSrv.port(), emailInstanceConfig{DMPolicy: "open"})
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer ch.Stop(context.Background())
+
+	first := nextInbound(t, mb)
+	if first.ChatID != "alice@example.com" || first.PeerKind != "direct" {
+		t.Fatalf("first: chat=%q peer=%q", first.ChatID, first.PeerKind)
+	}
+	if !strings.Contains(first.Content, "Subject: Invoice question") || !strings.Contains(first.Content, "where is my invoice") {
+		t.Errorf("first content = %q", first.Content)
+	}
+	if len(first.Media) != 1 || first.Media[0].Filename != "receipt.png" || first.Media[0].MimeType != "image/png" {
+		t.Fatalf("first media = %+v", first.Media)
+	}
+	if data, _ := os.ReadFile(first.Media[0].Path); string(data) != "\x89PNG\r\n\x1a\n" {
+		t.Errorf("attachment bytes = %q", data)
+	}
+	os.Remove(first.Media[0].Path)
+
+	second := nextInbound(t, mb)
+	localKey := first.Metadata["local_key"]
+	if !strings.HasPrefix(localKey, "alice@example.com:thread:") || second.Metadata["local_key"] != localKey {
+		t.Fatalf("thread keys differ: %q vs %q", localKey, second.Metadata["local_key"])
+	}
+	if second.Content != "Sent from the café." {
+		t.Errorf("second content = %q (quoted history should be stripped)", second.Content)
+	}
+
+	err := ch.Send(context.Background(), bus.OutboundMessage{
+		ChatID:   "alice@example.com",
+		Content:  "**Done** — see `INV-7`.",
+		Metadata: map[string]string{"local_key": localKey},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	var sent string
+	select {
+	case sent = <-smtpSrv.sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no mail delivered")
+	}
+	out, err := mail.ReadMessage(strings.NewReader(sent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.Header.Get("In-Reply-To"); got != "<reply1@example.com>" {
+		t.Errorf("In-Reply-To = %q", got)
+	}
+	if got := out.Header.Get("References"); !strings.Contains(got, "<root@example.com>") || !strings.Contains(got, "<reply1@example.com>") {
+		t.Errorf("References = %q", got)
+	}
+	if got := out.Header.Get("Subject"); got != "Re: Invoice question" {
+		t.Errorf("Subject = %q", got)
+	}
+	ctype, params, _ := mime.ParseMediaType(out.Header.Get("Content-Type"))
+	if ctype != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q", ctype)
+	}
+	parts := map[string]string{}
+	mr := multipart.NewReader(out.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(p)
+		pt, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		parts[pt] = string(body)
+	}
+	if !strings.Contains(parts["text/plain"], "**Done**") {
+		t.Errorf("plain part = %q", parts["text/plain"])
+	}
+	if !strings.Contains(parts["text/html"], "<strong>Done</strong>") || !strings.Contains(parts["text/html"], "INV-7</code>") {
+		t.Errorf("html part = %q", parts["text/html"])
+	}
+
+	// A reply quoting only our Message-ID (no References) stays in the thread;
+	// it arrives while the client is in IDLE.
+	imapSrv.add(fmt.Sprintf("Authentication-Results: mx.example.net; dmarc=pass header.from=example.com\nFrom: alice@example.com\nSubject: Re: Invoice question\nMessage-ID: <reply2@example.com>\nIn-Reply-To: %s\n\nThanks!\n",
+		out.Header.Get("Message-Id")))
+	third := nextInbound(t, mb)
+	if third.Metadata["local_key"] != localKey || third.Content != "Thanks!" {
+		t.Errorf("third: key=%q content=%q", third.Metadata["local_key"], third.Content)
+	}
+	// \Seen is stored right after the publish.
+	deadline := time.Now().Add(2 * time.Second)
+	for imapSrv.unseen() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := imapSrv.unseen(); n != 0 {
+		t.Errorf("%d messages left unseen", n)
+	}
+}
+
+func TestPolicyAndAutomatedMail(t *testing.T) {
+	ch, mb := newTestChannel(t, 1, 1, emailInstanceConfig{DMPolicy: "allowlist", AllowFrom: []string{"@example.com"}})
+
+	const evilAuth = "Authentication-Results: mx.example.net; dmarc=pass header.from=evil.test\r\n"
+	const exampleAuth = "Authentication-Results: mx.example.net; dmarc=pass header.from=example.com\r\n"
+	ch.handleRaw(context.Background(), []byte(evilAuth+"From: mallory@evil.test\r\nMessage-ID: <m1@evil.test>\r\n\r\nlet me in\r\n"))
+	ch.handleRaw(context.Background(), []byte(exampleAuth+"From: alice@example.com\r\nAuto-Submitted: auto-replied\r\nMessage-ID: <m2@example.com>\r\n\r\nOut of office\r\n"))
+	ch.handleRaw(context.Background(), []byte(exampleAuth+"From: bob@example.com\r\nMessage-ID: <m3@example.com>\r\nContent-Type: text/html\r\n\r\n<p>Hi &amp; welcome</p><style>p{}</style><div>second</div>\r\n"))
+	ch.handleRaw(context.Background(), []byte(exampleAuth+"From: bob@example.com\r\nMessage-ID: <m3@example.com>\r\n\r\nduplicate\r\n"))
+
+	msg := nextInbound(t, mb)
+	if msg.SenderID != "bob@example.com" {
+		t.Fatalf("sender = %q, want only the allowlisted domain through", msg.SenderID)
+	}
+	if msg.Content != "Hi & welcome\nsecond" {
+		t.Errorf("html body = %q", msg.Content)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if extra, ok := mb.ConsumeInbound(ctx); ok {
+		t.Errorf("unexpected inbound from %q: %q", extra.SenderID, extra.Content)
+	}
+}
+
+func TestSpoofedSenderDropped(t *testing.T) {
+	ch, mb := newTestChannel(t, 1, 1, emailInstanceConfig{DMPolicy: "allowlist", AllowFrom: []string{"ceo@example.com"}})
+
+	spoofs := []string{
+		"", // no verdict at all
+		"Authentication-Results: attacker.test; dmarc=pass header.from=example.com\r\n",  // untrusted authserv-id
+		"Authentication-Results: mx.example.net; dmarc=fail header.from=example.com\r\n", // failed DMARC
+		"Authentication-Results: mx.example.net; dkim=pass header.d=evil.test; spf=pass smtp.mailfrom=example.com\r\n",
+	}
+	for i, auth := range spoofs {
+		ch.handleRaw(context.Background(), fmt.Appendf(nil, "%sFrom: ceo@example.com\r\nMessage-ID: <s%d@evil.test>\r\n\r\nwire the money\r\n", auth, i))
+	}
+	// A DKIM pass in a folded header with comments verifies the sender.
+	ch.handleRaw(context.Background(), []byte("Authentication-Results: mx.example.net;\r\n dkim=pass (2048-bit key; unprotected) header.i=@example.com header.s=s1\r\n"+
+		"From: ceo@example.com\r\nMessage-ID: <ok@example.com>\r\n\r\nreal\r\n"))
+
+	if msg := nextInbound(t, mb); msg.Content != "real" {
+		t.Fatalf("content = %q, want only the verified mail", msg.Content)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if extra, ok := mb.ConsumeInbound(ctx); ok {
+		t.Errorf("spoofed mail got through: %q", extra.Content)
+	}
+
+	insecure, mb2 := newTestChannel(t, 1, 1, emailInstanceConfig{DMPolicy: "open", AllowUnauthenticated: true})
+	insecure.handleRaw(context.Background(), []byte("From: anyone@example.org\r\nMessage-ID: <u1@example.org>\r\n\r\nhi\r\n"))
+	if msg := nextInbound(t, mb2); msg.SenderID != "anyone@example.org" {
+		t.Fatalf("allow_unauthenticated sender = %q", msg.SenderID)
+	}
+}
+
+func TestStartRejectsBadCredentials(t *testing.T) {
+	imapSrv := newFakeIMAP(t)
+	cfg := emailInstanceConfig{
+		Username: "bot@example.net",
+		IMAPHost: "127.0.0.1", IMAPPort: imapSrv.port(), IMAPSecurity: "none",
+		SMTPHost: "127.0.0.1",
+	}
+	ch, err := New(cfg, emailCreds{Password: "wrong"}, bus.New(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ch.Start(context.Background())
+	if err == nil || !isAuthError(err) {
+		t.Fatalf("Start error = %v, want auth error", err)
+	}
+}
+
+func TestFactoryValidatesConfig(t *testing.T) {
+	creds := json.RawMessage(`{"password":"pw"}`)
+	if _, err := Factory("mail", creds, json.RawMessage(`{"username":"bot@example.net","imap_host":"imap.example.net"}`), bus.New(), nil); err == nil {
+		t.Error("expected error without smtp_host")
+	}
+	if _, err := Factory("mail", creds, json.RawMessage(`{"username":"bot","imap_host":"i","smtp_host":"s"}`), bus.New(), nil); err == nil {
+		t.Error("expected error for a username that is not an address and no from_address")
+	}
+	ch, err := Factory("mail", creds, json.RawMessage(`{"username":"bot@example.net","imap_host":"i","smtp_host":"s","smtp_port":465}`), bus.New(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec := ch.(*Channel)
+	if ch.Name() != "mail" || ec.config.IMAPPort != 993 || ec.config.SMTPSecurity != "tls" || ec.config.Mailbox != "INBOX" {
+		t.Errorf("defaults not applied: %+v", ec.config)
+	}
+}
+
+func TestMarkdownToEmailHTML(t *testing.T) {
+	got := renderBlocks(strings.Split("# Title\nSome **bold** & <raw>\nnext line\n\n- one\n- two\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n```\nx < y\n```", "\n"))
+	for _, want := range []string{
+		"<h1>Title</h1>",
+		"<p>Some <strong>bold</strong> &amp; &lt;raw&gt;<br>next line</p>",
+		"<ul><li>one</li><li>two</li></ul>",
+		">a</th>",
+		">2</td></tr></table>",
+		">x &lt; y</pre>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package email
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// emailCreds maps the credentials JSON from the channel_instances table.
+type emailCreds struct {
+	Password     string `json:"password"`                // IMAP password (also SMTP unless smtp_password is set)
+	SMTPPassword string `json:"smtp_password,omitempty"` // separate SMTP password, if any
+}
+
+// emailInstanceConfig maps the non-secret config JSONB from the channel_instances table.
+type emailInstanceConfig struct {
+	Username        string                     `json:"username"`               // mailbox login, usually the address
+	FromAddress     string                     `json:"from_address,omitempty"` // defaults to username
+	FromName        string                     `json:"from_name,omitempty"`    // display name on outgoing mail
+	IMAPHost        string                     `json:"imap_host"`
+	IMAPPort        int                        `json:"imap_port,omitempty"`     // default 993 (tls) or 143
+	IMAPSecurity    string                     `json:"imap_security,omitempty"` // "tls" (default), "starttls", "none"
+	Mailbox         string                     `json:"mailbox,omitempty"`       // default INBOX
+	SMTPHost        string                     `json:"smtp_host"`
+	SMTPPort        int                        `json:"smtp_port,omitempty"`     // default 587 (starttls) or 465 (tls)
+	SMTPSecurity    string                     `json:"smtp_security,omitempty"` // "starttls" (default), "tls", "none"
+	SMTPUsername    string                     `json:"smtp_username,omitempty"` // defaults to username
+	PollIntervalSec int                        `json:"poll_interval_sec,omitempty"`
+	DisableIdle     bool                       `json:"disable_idle,omitempty"` // poll even if the server supports IDLE
+	DefaultSubject  string                     `json:"default_subject,omitempty"`
+	DMPolicy        string                     `json:"dm_policy,omitempty"`
+	AllowFrom       []string                   `json:"allow_from,omitempty"` // addresses or "@domain"
+	MediaMaxMB      int                        `json:"media_max_mb,omitempty"`
+	ChatBehavior    *config.ChatBehaviorConfig `json:"chat_behavior,omitempty"`
+
+	// From is only trusted once a receiving server verified it. Mail without
+	// a DMARC or aligned DKIM pass in an Authentication-Results header from
+	// one of these authserv-ids (usually the MX host, e.g. "mx.google.com")
+	// is dropped. AllowUnauthenticated skips the check; it is insecure, since
+	// anyone can then pose as an allowed or paired sender.
+	TrustedAuthservIDs   []string `json:"trusted_authserv_ids,omitempty"`
+	AllowUnauthenticated bool     `json:"allow_unauthenticated,omitempty"`
+}
+
+// Factory creates an email channel from DB instance data.
+func Factory(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+
+	var c emailCreds
+	if len(creds) > 0 {
+		if err := json.Unmarshal(creds, &c); err != nil {
+			return nil, fmt.Errorf("decode email credentials: %w", err)
+		}
+	}
+
+	var ic emailInstanceConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &ic); err != nil {
+			return nil, fmt.Errorf("decode email config: %w", err)
+		}
+	}
+
+	ch, err := New(ic, c, msgBus, pairingSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	ch.SetName(name)
+	return ch, nil
+}
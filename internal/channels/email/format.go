@@ -0,0 +1,149 @@
+package email
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// --- Markdown to email HTML ---
+// Mail clients render full HTML, but only with inline styles and without
+// scripts, so the output is plain semantic markup. The text/plain alternative
+// carries the markdown source unchanged.
+
+var (
+	reFence      = regexp.MustCompile("^```")
+	reHeading    = regexp.MustCompile(`^(#{1,6})\s+(.+)$`)
+	reBullet     = regexp.MustCompile(`^\s*[-*+]\s+(.+)$`)
+	reOrdered    = regexp.MustCompile(`^\s*\d+[.)]\s+(.+)$`)
+	reTableSep   = regexp.MustCompile(`^\s*\|?\s*:?-{3,}:?\s*(\|\s*:?-{3,}:?\s*)*\|?\s*$`)
+	reRule       = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+	reInlineCode = regexp.MustCompile("`([^`\\n]+)`")
+	reLink       = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+	reBold       = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	reItalic     = regexp.MustCompile(`(^|[\s(])[*_]([^*_\s][^*_]*?)[*_]([\s).,!?:;]|$)`)
+	reStrike     = regexp.MustCompile(`~~(.+?)~~`)
+)
+
+const (
+	styleCode  = `background:#f4f4f4;padding:1px 4px;border-radius:3px;font-family:monospace`
+	stylePre   = `background:#f4f4f4;padding:10px;border-radius:4px;font-family:monospace;white-space:pre-wrap`
+	styleQuote = `margin:0 0 0 8px;padding-left:10px;border-left:3px solid #ccc;color:#555`
+	styleCell  = `border:1px solid #ddd;padding:4px 8px`
+)
+
+// markdownToEmailHTML renders markdown as a complete HTML document.
+func markdownToEmailHTML(text string) string {
+	var b strings.Builder
+	b.WriteString(`<!DOCTYPE html><html><body style="font-family:sans-serif;font-size:14px;line-height:1.5">`)
+	b.WriteString(renderBlocks(strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")))
+	b.WriteString("</body></html>")
+	return b.String()
+}
+
+// renderBlocks walks the lines once, emitting one HTML block per markdown block.
+func renderBlocks(lines []string) string {
+	var out strings.Builder
+	var para []string
+	flushPara := func() {
+		if len(para) > 0 {
+			out.WriteString("<p>" + strings.Join(para, "<br>") + "</p>")
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case reFence.MatchString(trimmed):
+			flushPara()
+			var code []string
+			for i++; i < len(lines) && !reFence.MatchString(strings.TrimSpace(lines[i])); i++ {
+				code = append(code, lines[i])
+			}
+			fmt.Fprintf(&out, `<pre style="%s">%s</pre>`, stylePre, html.EscapeString(strings.Join(code, "\n")))
+
+		case trimmed == "":
+			flushPara()
+
+		case reHeading.MatchString(trimmed):
+			flushPara()
+			m := reHeading.FindStringSubmatch(trimmed)
+			fmt.Fprintf(&out, "<h%d>%s</h%d>", len(m[1]), renderInline(m[2]), len(m[1]))
+
+		case reRule.MatchString(trimmed):
+			flushPara()
+			out.WriteString("<hr>")
+
+		case strings.HasPrefix(trimmed, ">"):
+			flushPara()
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(q, " "))
+			}
+			i--
+			fmt.Fprintf(&out, `<blockquote style="%s">%s</blockquote>`, styleQuote, renderBlocks(quoted))
+
+		case reBullet.MatchString(line), reOrdered.MatchString(line):
+			flushPara()
+			re, tag := reBullet, "ul"
+			if !reBullet.MatchString(line) {
+				re, tag = reOrdered, "ol"
+			}
+			out.WriteString("<" + tag + ">")
+			for ; i < len(lines) && re.MatchString(lines[i]); i++ {
+				out.WriteString("<li>" + renderInline(re.FindStringSubmatch(lines[i])[1]) + "</li>")
+			}
+			i--
+			out.WriteString("</" + tag + ">")
+
+		case strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && reTableSep.MatchString(lines[i+1]):
+			flushPara()
+			out.WriteString(`<table style="border-collapse:collapse">`)
+			writeRow(&out, "th", trimmed)
+			for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				writeRow(&out, "td", strings.TrimSpace(lines[i]))
+			}
+			i--
+			out.WriteString("</table>")
+
+		default:
+			para = append(para, renderInline(trimmed))
+		}
+	}
+	flushPara()
+	return out.String()
+}
+
+func writeRow(out *strings.Builder, cell, row string) {
+	out.WriteString("<tr>")
+	for c := range strings.SplitSeq(strings.Trim(row, "|"), "|") {
+		fmt.Fprintf(out, `<%s style="%s">%s</%s>`, cell, styleCell, renderInline(strings.TrimSpace(c)), cell)
+	}
+	out.WriteString("</tr>")
+}
+
+// renderInline escapes text and applies span-level markdown.
+func renderInline(text string) string {
+	var codes []string
+	text = reInlineCode.ReplaceAllStringFunc(text, func(s string) string {
+		m := reInlineCode.FindStringSubmatch(s)
+		codes = append(codes, fmt.Sprintf(`<code style="%s">%s</code>`, styleCode, html.EscapeString(m[1])))
+		return fmt.Sprintf("\x00C%d\x00", len(codes)-1)
+	})
+
+	text = html.EscapeString(text)
+	text = reLink.ReplaceAllString(text, `<a href="$2">$1</a>`)
+	text = reBold.ReplaceAllString(text, "<strong>$1</strong>")
+	text = reItalic.ReplaceAllString(text, "$1<em>$2</em>$3")
+	text = reStrike.ReplaceAllString(text, "<del>$1</del>")
+
+	for i, code := range codes {
+		text = strings.Replace(text, fmt.Sprintf("\x00C%d\x00", i), code, 1)
+	}
+	return text
+}
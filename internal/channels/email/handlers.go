@@ -0,0 +1,308 @@
+package email
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// threadState carries what the next reply in a thread needs for its headers.
+type threadState struct {
+	mu         sync.Mutex
+	to         string
+	subject    string
+	threadID   string
+	inReplyTo  string   // Message-ID of the latest inbound mail
+	references []string // full chain, oldest first
+	updated    time.Time
+}
+
+// indexEntry maps a known Message-ID (inbound or sent) to its thread.
+type indexEntry struct {
+	threadID string
+	seen     time.Time
+}
+
+// handleRaw parses one fetched message and publishes it to the bus.
+func (c *Channel) handleRaw(ctx context.Context, raw []byte) {
+	m, err := parseMail(raw, c.mediaMaxBytes)
+	if err != nil {
+		slog.Warn("email: unparseable message skipped", "error", err)
+		return
+	}
+	if m.From == nil || m.From.Address == "" {
+		return
+	}
+	sender := strings.ToLower(m.From.Address)
+	if sender == c.fromAddress {
+		return
+	}
+	if m.Automated {
+		slog.Debug("email: automated message ignored", "from", sender, "subject", m.Subject)
+		return
+	}
+	// From is unauthenticated; it only becomes the sender identity (policy,
+	// pairing, contacts) once the receiving server has verified it.
+	if !c.config.AllowUnauthenticated && !senderVerified(m.AuthResults, c.config.TrustedAuthservIDs, sender) {
+		slog.Warn("security.email_sender_unverified", "from", sender, "message_id", m.MessageID,
+			"hint", "needs DMARC or aligned DKIM pass from a trusted_authserv_ids server")
+		return
+	}
+	if m.MessageID != "" {
+		if _, loaded := c.dedup.LoadOrStore(m.MessageID, time.Now()); loaded {
+			return
+		}
+	}
+
+	ctx = store.WithTenantID(ctx, c.TenantID())
+	threadID := c.resolveThread(m)
+	localKey := sender + ":thread:" + threadID
+	st := c.rememberThread(localKey, sender, threadID, m)
+
+	if !c.checkSenderPolicy(ctx, sender, st) {
+		return
+	}
+
+	displayName := m.From.Name
+	if displayName == "" {
+		displayName = sender
+	}
+
+	body := stripQuotedReply(m.Text)
+	items, notes := c.saveAttachments(m.Attachments)
+	var mediaFiles []bus.MediaFile
+	for _, it := range items {
+		if it.Type == media.TypeDocument {
+			if doc, err := media.ExtractDocumentContent(it.FilePath, it.FileName); err != nil {
+				slog.Warn("email: document extraction failed", "file", it.FileName, "error", err)
+			} else if doc != "" {
+				body = strings.TrimSpace(body + "\n\n" + doc)
+			}
+		}
+		mediaFiles = append(mediaFiles, bus.MediaFile{Path: it.FilePath, MimeType: it.ContentType, Filename: it.FileName})
+	}
+	if len(notes) > 0 {
+		body = strings.TrimSpace(body + "\n\n" + strings.Join(notes, "\n"))
+	}
+	if body == "" && len(items) == 0 {
+		return
+	}
+
+	// The subject only carries information on the mail that opens a thread.
+	content := body
+	if m.Subject != "" && m.InReplyTo == "" && len(m.References) == 0 {
+		content = "Subject: " + m.Subject + "\n\n" + body
+	}
+	if tags := media.BuildMediaTags(items); tags != "" {
+		content = tags + "\n\n" + content
+	}
+
+	slog.Debug("email message received",
+		"sender", sender, "thread_id", threadID, "attachments", len(items),
+		"preview", channels.Truncate(body, 50))
+
+	if cc := c.ContactCollector(); cc != nil {
+		cc.EnsureContact(ctx, c.Type(), c.Name(), sender, sender, displayName, sender, "direct", "user", "", "")
+	}
+
+	// Published directly (not via HandleMessage) to keep attachment file names
+	// and MIME types, and because allow_from may match by "@domain".
+	c.Bus().PublishInbound(bus.InboundMessage{
+		Channel:  c.Name(),
+		SenderID: sender,
+		ChatID:   sender,
+		Content:  content,
+		Media:    mediaFiles,
+		PeerKind: "direct",
+		UserID:   sender,
+		AgentID:  c.AgentID(),
+		TenantID: c.TenantID(),
+		Metadata: map[string]string{
+			"message_id":   m.MessageID,
+			"user_id":      sender,
+			"username":     sender,
+			"display_name": channels.SanitizeDisplayName(displayName),
+			"is_dm":        "true",
+			"local_key":    localKey,
+		},
+	})
+}
+
+// resolveThread maps a message to a stable thread ID. Known Message-IDs in
+// In-Reply-To/References win (this also covers clients that drop References
+// but reply to one of our sent mails); otherwise the oldest reference — the
+// thread root — is hashed, so the mapping survives restarts.
+func (c *Channel) resolveThread(m *inboundMail) string {
+	candidates := []string{m.InReplyTo}
+	for _, ref := range slices.Backward(m.References) {
+		candidates = append(candidates, ref)
+	}
+
+	threadID := ""
+	for _, id := range candidates {
+		if id == "" {
+			continue
+		}
+		if e, ok := c.index.Load(id); ok {
+			threadID = e.(indexEntry).threadID
+			break
+		}
+	}
+	if threadID == "" {
+		root := m.MessageID
+		switch {
+		case len(m.References) > 0:
+			root = m.References[0]
+		case m.InReplyTo != "":
+			root = m.InReplyTo
+		case root == "":
+			root = uuid.NewString()
+		}
+		threadID = threadKey(root)
+	}
+	if m.MessageID != "" {
+		c.index.Store(m.MessageID, indexEntry{threadID: threadID, seen: time.Now()})
+	}
+	return threadID
+}
+
+// threadKey shortens a Message-ID into a session-key-safe token.
+func threadKey(messageID string) string {
+	sum := sha256.Sum256([]byte(strings.Trim(messageID, "<> ")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// rememberThread records reply headers for the thread's next outbound mail.
+func (c *Channel) rememberThread(localKey, sender, threadID string, m *inboundMail) *threadState {
+	v, _ := c.threads.LoadOrStore(localKey, &threadState{to: sender, threadID: threadID})
+	st := v.(*threadState)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.subject == "" || m.Subject != "" {
+		st.subject = m.Subject
+	}
+	refs := m.References
+	if len(refs) == 0 && m.InReplyTo != "" {
+		refs = []string{m.InReplyTo}
+	}
+	for _, id := range append(refs, m.MessageID) {
+		if id != "" && !slices.Contains(st.references, id) {
+			st.references = append(st.references, id)
+		}
+	}
+	if m.MessageID != "" {
+		st.inReplyTo = m.MessageID
+	}
+	st.updated = time.Now()
+	return st
+}
+
+// saveAttachments writes attachments to temp files. The agent loop persists
+// them into the media store like any other channel upload. Oversized parts
+// come back as notes for the agent instead of files.
+func (c *Channel) saveAttachments(atts []mailAttachment) ([]media.MediaInfo, []string) {
+	var items []media.MediaInfo
+	var notes []string
+	for _, a := range atts {
+		name := filepath.Base(a.FileName)
+		if a.Data == nil {
+			notes = append(notes, fmt.Sprintf("[Attachment %q skipped: larger than %d MB]", name, c.mediaMaxBytes/(1024*1024)))
+			continue
+		}
+		mime := a.ContentType
+		if mime == "" || mime == "application/octet-stream" {
+			mime = media.DetectMIMEType(name)
+		}
+		ext := filepath.Ext(name)
+		if ext == "" {
+			ext = ".dat"
+		}
+		tmp, err := os.CreateTemp("", "email-file-*"+ext)
+		if err != nil {
+			slog.Warn("email: create temp file failed", "error", err)
+			continue
+		}
+		_, err = tmp.Write(a.Data)
+		tmp.Close()
+		if err != nil {
+			os.Remove(tmp.Name())
+			slog.Warn("email: write attachment failed", "file", name, "error", err)
+			continue
+		}
+		items = append(items, media.MediaInfo{
+			Type:        media.MediaKindFromMime(mime),
+			FilePath:    tmp.Name(),
+			ContentType: mime,
+			FileName:    name,
+			FileSize:    int64(len(a.Data)),
+		})
+	}
+	return items, notes
+}
+
+// senderAllowed matches allow_from entries by full address or "@domain".
+func (c *Channel) senderAllowed(sender string) bool {
+	if c.IsAllowed(sender) {
+		return true
+	}
+	_, domain, ok := strings.Cut(sender, "@")
+	return ok && c.IsAllowed("@"+domain)
+}
+
+// checkSenderPolicy enforces dm_policy. Domain allowlist entries are checked
+// first because BaseChannel only knows exact IDs.
+func (c *Channel) checkSenderPolicy(ctx context.Context, sender string, st *threadState) bool {
+	if c.config.DMPolicy != "disabled" && c.HasAllowList() && c.senderAllowed(sender) {
+		return true
+	}
+	switch c.CheckDMPolicy(ctx, sender, c.config.DMPolicy) {
+	case channels.PolicyAllow:
+		// Same safety net as BaseChannel.HandleMessage: a non-empty allowlist
+		// always applies, and the sender did not match it above.
+		return !c.HasAllowList()
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, sender, st)
+		return false
+	default:
+		slog.Debug("email rejected by policy", "sender", sender, "policy", c.config.DMPolicy)
+		return false
+	}
+}
+
+func (c *Channel) sendPairingReply(ctx context.Context, sender string, st *threadState) {
+	ps := c.PairingService()
+	if ps == nil || !c.CanSendPairingNotif(sender, pairingDebounce) {
+		return
+	}
+	code, err := ps.RequestPairing(ctx, sender, c.Name(), sender, "default", nil)
+	if err != nil {
+		slog.Debug("email pairing request failed", "sender", sender, "error", err)
+		return
+	}
+	reply := fmt.Sprintf(
+		"GoClaw: access not configured.\n\nYour email address: %s\n\nPairing code: %s\n\nAsk the bot owner to approve with:\n  goclaw pairing approve %s",
+		sender, code, code,
+	)
+	if err := c.sendReply(ctx, st, reply, nil); err != nil {
+		slog.Warn("email: failed to send pairing reply", "error", err)
+		return
+	}
+	c.MarkPairingNotifSent(sender)
+	slog.Info("email pairing reply sent", "sender", sender, "code", code)
+}
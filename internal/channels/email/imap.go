@@ -0,0 +1,350 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	imapCommandTimeout = 60 * time.Second
+	imapDialTimeout    = 30 * time.Second
+)
+
+// imapClient is a minimal IMAP4rev1 client covering what the channel needs:
+// LOGIN, SELECT, UID SEARCH/FETCH/STORE and IDLE. Commands are issued
+// sequentially by one goroutine; Close may be called from another to unblock it.
+type imapClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+	caps map[string]bool
+
+	maxLiteral int64 // literals above this size are discarded
+	closeOnce  sync.Once
+}
+
+// imapResponse is one untagged server response. Literal payloads ({N}) are
+// collected separately; the line keeps only the surrounding syntax.
+type imapResponse struct {
+	line     string
+	literals [][]byte
+}
+
+// imapError is a NO or BAD completion for a tagged command.
+type imapError struct {
+	Command string
+	Status  string
+	Text    string
+}
+
+func (e *imapError) Error() string {
+	return fmt.Sprintf("imap %s: %s %s", e.Command, e.Status, e.Text)
+}
+
+// isAuthError reports whether err is a rejected LOGIN.
+func isAuthError(err error) bool {
+	var ie *imapError
+	return errors.As(err, &ie) && ie.Command == "LOGIN"
+}
+
+// errLiteralTooLarge marks a FETCH whose message exceeded maxLiteral.
+var errLiteralTooLarge = errors.New("message too large")
+
+// dialIMAP connects and reads the server greeting. security is "tls"
+// (implicit TLS), "starttls" or "none".
+func dialIMAP(ctx context.Context, host string, port int, security string, maxLiteral int64) (*imapClient, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	d := net.Dialer{Timeout: imapDialTimeout}
+	var conn net.Conn
+	var err error
+	if security == "tls" {
+		conn, err = (&tls.Dialer{NetDialer: &d, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dial imap %s: %w", addr, err)
+	}
+
+	c := &imapClient{conn: conn, r: bufio.NewReader(conn), maxLiteral: maxLiteral}
+	conn.SetDeadline(time.Now().Add(imapCommandTimeout))
+	greeting, err := c.readLine()
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("read imap greeting: %w", err)
+	}
+	if !strings.HasPrefix(greeting, "* OK") && !strings.HasPrefix(greeting, "* PREAUTH") {
+		c.Close()
+		return nil, fmt.Errorf("unexpected imap greeting: %s", greeting)
+	}
+
+	if security == "starttls" {
+		if _, err := c.command("STARTTLS"); err != nil {
+			c.Close()
+			return nil, err
+		}
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("imap starttls handshake: %w", err)
+		}
+		c.conn = tlsConn
+		c.r = bufio.NewReader(tlsConn)
+	}
+	return c, nil
+}
+
+// Close drops the connection without LOGOUT. Safe to call concurrently.
+func (c *imapClient) Close() error {
+	var err error
+	c.closeOnce.Do(func() { err = c.conn.Close() })
+	return err
+}
+
+// login authenticates and refreshes the capability list.
+func (c *imapClient) login(username, password string) error {
+	if _, err := c.command("LOGIN " + quoteIMAP(username) + " " + quoteIMAP(password)); err != nil {
+		return err
+	}
+	return c.capability()
+}
+
+func (c *imapClient) capability() error {
+	resps, err := c.command("CAPABILITY")
+	if err != nil {
+		return err
+	}
+	c.caps = make(map[string]bool)
+	for _, r := range resps {
+		if rest, ok := strings.CutPrefix(r.line, "* CAPABILITY "); ok {
+			for name := range strings.FieldsSeq(rest) {
+				c.caps[strings.ToUpper(name)] = true
+			}
+		}
+	}
+	return nil
+}
+
+// supportsIdle reports whether the server advertised IDLE (RFC 2177).
+func (c *imapClient) supportsIdle() bool { return c.caps["IDLE"] }
+
+// selectMailbox opens a mailbox read-write.
+func (c *imapClient) selectMailbox(name string) error {
+	_, err := c.command("SELECT " + quoteIMAP(name))
+	return err
+}
+
+// searchUnseen returns the UIDs of messages without the \Seen flag.
+func (c *imapClient) searchUnseen() ([]uint32, error) {
+	resps, err := c.command("UID SEARCH UNSEEN")
+	if err != nil {
+		return nil, err
+	}
+	var uids []uint32
+	for _, r := range resps {
+		rest, ok := strings.CutPrefix(r.line, "* SEARCH")
+		if !ok {
+			continue
+		}
+		for f := range strings.FieldsSeq(rest) {
+			if n, err := strconv.ParseUint(f, 10, 32); err == nil {
+				uids = append(uids, uint32(n))
+			}
+		}
+	}
+	return uids, nil
+}
+
+// fetchRaw returns the full RFC 5322 message without setting \Seen.
+func (c *imapClient) fetchRaw(uid uint32) ([]byte, error) {
+	resps, err := c.command(fmt.Sprintf("UID FETCH %d BODY.PEEK[]", uid))
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range resps {
+		if !strings.Contains(r.line, " FETCH ") {
+			continue
+		}
+		if strings.Contains(r.line, "{too-large}") {
+			return nil, errLiteralTooLarge
+		}
+		if len(r.literals) > 0 {
+			return r.literals[0], nil
+		}
+	}
+	return nil, fmt.Errorf("imap fetch %d: no body returned", uid)
+}
+
+// markSeen sets \Seen so the message is not picked up again.
+func (c *imapClient) markSeen(uid uint32) error {
+	_, err := c.command(fmt.Sprintf(`UID STORE %d +FLAGS.SILENT (\Seen)`, uid))
+	return err
+}
+
+// idle waits for mailbox changes for up to maxWait (RFC 2177). It returns true
+// when the server reported new messages. DONE is written from a helper
+// goroutine so the reader never has to abandon a half-read line.
+func (c *imapClient) idle(ctx context.Context, maxWait time.Duration) (bool, error) {
+	tag := c.nextTag()
+	c.conn.SetDeadline(time.Now().Add(imapCommandTimeout))
+	if _, err := fmt.Fprintf(c.conn, "%s IDLE\r\n", tag); err != nil {
+		return false, err
+	}
+	line, err := c.readLine()
+	if err != nil {
+		return false, err
+	}
+	if !strings.HasPrefix(line, "+") {
+		return false, parseCompletion("IDLE", tag, line)
+	}
+
+	// The server may stay silent for the whole window; only our DONE ends it.
+	c.conn.SetDeadline(time.Now().Add(maxWait + imapCommandTimeout))
+	var doneOnce sync.Once
+	sendDone := func() {
+		doneOnce.Do(func() { _, _ = io.WriteString(c.conn, "DONE\r\n") })
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		timer := time.NewTimer(maxWait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			sendDone()
+		case <-timer.C:
+			sendDone()
+		case <-stop:
+		}
+	}()
+
+	hasNew := false
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return hasNew, err
+		}
+		if strings.HasPrefix(resp.line, tag+" ") {
+			return hasNew, parseCompletion("IDLE", tag, resp.line)
+		}
+		if strings.HasSuffix(resp.line, " EXISTS") {
+			hasNew = true
+			sendDone()
+		}
+	}
+}
+
+// command sends one tagged command and collects untagged responses until its
+// completion. NO/BAD completions become *imapError.
+func (c *imapClient) command(cmd string) ([]imapResponse, error) {
+	tag := c.nextTag()
+	name := cmd
+	if i := strings.IndexByte(cmd, ' '); i > 0 {
+		name = cmd[:i]
+		if name == "UID" {
+			if j := strings.IndexByte(cmd[i+1:], ' '); j > 0 {
+				name = cmd[:i+1+j]
+			}
+		}
+	}
+
+	c.conn.SetDeadline(time.Now().Add(imapCommandTimeout))
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, cmd); err != nil {
+		return nil, fmt.Errorf("imap %s: %w", name, err)
+	}
+	var resps []imapResponse
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, fmt.Errorf("imap %s: %w", name, err)
+		}
+		if strings.HasPrefix(resp.line, tag+" ") {
+			return resps, parseCompletion(name, tag, resp.line)
+		}
+		resps = append(resps, resp)
+	}
+}
+
+func (c *imapClient) nextTag() string {
+	c.tag++
+	return "g" + strconv.Itoa(c.tag)
+}
+
+// readResponse reads one logical response line, following {N} literals.
+// Oversized literals are drained and replaced by the {too-large} marker.
+func (c *imapClient) readResponse() (imapResponse, error) {
+	var resp imapResponse
+	var b strings.Builder
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return resp, err
+		}
+		n, prefix, ok := literalSize(line)
+		if !ok {
+			b.WriteString(line)
+			resp.line = b.String()
+			return resp, nil
+		}
+		b.WriteString(prefix)
+		if c.maxLiteral > 0 && n > c.maxLiteral {
+			if _, err := io.CopyN(io.Discard, c.r, n); err != nil {
+				return resp, err
+			}
+			b.WriteString("{too-large}")
+			continue
+		}
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return resp, err
+		}
+		resp.literals = append(resp.literals, buf)
+	}
+}
+
+func (c *imapClient) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// literalSize parses a trailing "{N}" (or "{N+}") literal marker.
+func literalSize(line string) (int64, string, bool) {
+	if !strings.HasSuffix(line, "}") {
+		return 0, "", false
+	}
+	open := strings.LastIndexByte(line, '{')
+	if open < 0 {
+		return 0, "", false
+	}
+	n, err := strconv.ParseInt(strings.TrimSuffix(line[open+1:len(line)-1], "+"), 10, 64)
+	if err != nil || n < 0 {
+		return 0, "", false
+	}
+	return n, line[:open], true
+}
+
+func parseCompletion(command, tag, line string) error {
+	rest := strings.TrimPrefix(line, tag+" ")
+	status, text, _ := strings.Cut(rest, " ")
+	if strings.EqualFold(status, "OK") {
+		return nil
+	}
+	return &imapError{Command: command, Status: strings.ToUpper(status), Text: text}
+}
+
+// quoteIMAP renders s as an IMAP quoted string.
+func quoteIMAP(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,257 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// inboundMail is the parts of an RFC 5322 message the channel acts on.
+type inboundMail struct {
+	From        *mail.Address
+	Subject     string
+	MessageID   string
+	InReplyTo   string
+	References  []string
+	Date        time.Time
+	Text        string // best plain-text body (text/plain, else stripped text/html)
+	Attachments []mailAttachment
+	Automated   bool     // auto-replies, bounces and list traffic (never answered)
+	AuthResults []string // Authentication-Results header values, topmost first
+}
+
+type mailAttachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+var headerDecoder = &mime.WordDecoder{CharsetReader: charsetReader}
+
+// parseMail decodes a raw message. Bodies are converted to UTF-8; attachments
+// are kept in memory up to maxAttachment bytes each and dropped beyond that.
+func parseMail(raw []byte, maxAttachment int64) (*inboundMail, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("parse message: %w", err)
+	}
+	h := msg.Header
+
+	m := &inboundMail{
+		Subject:     decodeHeader(h.Get("Subject")),
+		MessageID:   firstMessageID(h.Get("Message-Id")),
+		InReplyTo:   firstMessageID(h.Get("In-Reply-To")),
+		References:  messageIDs(h.Get("References")),
+		Automated:   isAutomated(h),
+		AuthResults: h["Authentication-Results"],
+	}
+	if from, err := parseAddress(h.Get("From")); err == nil {
+		m.From = from
+	}
+	if d, err := h.Date(); err == nil {
+		m.Date = d
+	}
+
+	var plain, htmlBody string
+	var walk func(header textproto.MIMEHeader, body io.Reader)
+	walk = func(header textproto.MIMEHeader, body io.Reader) {
+		ctype, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+		if err != nil {
+			ctype, params = "text/plain", map[string]string{}
+		}
+		body = transferDecoder(header.Get("Content-Transfer-Encoding"), body)
+
+		if strings.HasPrefix(ctype, "multipart/") {
+			mr := multipart.NewReader(body, params["boundary"])
+			for {
+				part, err := mr.NextRawPart()
+				if err != nil {
+					return
+				}
+				walk(part.Header, part)
+			}
+		}
+
+		disposition, dparams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+		name := dparams["filename"]
+		if name == "" {
+			name = params["name"]
+		}
+		name = decodeHeader(name)
+
+		isBody := disposition != "attachment" && name == "" &&
+			(ctype == "text/plain" || ctype == "text/html")
+		if isBody {
+			text, err := readText(body, params["charset"])
+			if err != nil {
+				return
+			}
+			if ctype == "text/plain" && plain == "" {
+				plain = text
+			} else if ctype == "text/html" && htmlBody == "" {
+				htmlBody = text
+			}
+			return
+		}
+
+		if name == "" {
+			if ctype == "message/rfc822" {
+				name = "attached-message.eml"
+			} else {
+				return // unnamed inline parts (e.g. calendar alternatives) carry no file
+			}
+		}
+		data, err := io.ReadAll(io.LimitReader(body, maxAttachment+1))
+		if err != nil || int64(len(data)) > maxAttachment {
+			m.Attachments = append(m.Attachments, mailAttachment{FileName: name, ContentType: ctype})
+			return
+		}
+		m.Attachments = append(m.Attachments, mailAttachment{FileName: name, ContentType: ctype, Data: data})
+	}
+	walk(textproto.MIMEHeader(h), msg.Body)
+
+	switch {
+	case plain != "":
+		m.Text = plain
+	case htmlBody != "":
+		m.Text = htmlToText(htmlBody)
+	}
+	m.Text = strings.TrimSpace(strings.ReplaceAll(m.Text, "\r\n", "\n"))
+	return m, nil
+}
+
+func parseAddress(v string) (*mail.Address, error) {
+	p := mail.AddressParser{WordDecoder: headerDecoder}
+	return p.Parse(v)
+}
+
+func decodeHeader(v string) string {
+	if d, err := headerDecoder.DecodeHeader(v); err == nil {
+		return d
+	}
+	return v
+}
+
+// charsetReader converts legacy charsets (ISO-8859-x, windows-125x, GBK, ...) to UTF-8.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, err
+	}
+	return enc.NewDecoder().Reader(input), nil
+}
+
+func transferDecoder(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+func readText(body io.Reader, charset string) (string, error) {
+	charset = strings.ToLower(charset)
+	if charset != "" && charset != "utf-8" && charset != "us-ascii" {
+		if r, err := charsetReader(charset, body); err == nil {
+			body = r
+		}
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.ToValidUTF8(b, []byte("�"))), nil
+}
+
+var reMessageID = regexp.MustCompile(`<[^<>\s]+>`)
+
+// messageIDs extracts every <id> from a References-style header, in order.
+func messageIDs(v string) []string {
+	return reMessageID.FindAllString(v, -1)
+}
+
+func firstMessageID(v string) string {
+	if ids := messageIDs(v); len(ids) > 0 {
+		return ids[0]
+	}
+	return strings.TrimSpace(v)
+}
+
+// isAutomated flags mail that must never get a reply (RFC 3834 and common
+// bulk/bounce markers) so two auto-responders cannot loop.
+func isAutomated(h mail.Header) bool {
+	if v := strings.ToLower(h.Get("Auto-Submitted")); v != "" && v != "no" {
+		return true
+	}
+	switch strings.ToLower(h.Get("Precedence")) {
+	case "bulk", "list", "junk", "auto_reply":
+		return true
+	}
+	if h.Get("List-Id") != "" || h.Get("X-Autoreply") != "" || h.Get("X-Autorespond") != "" {
+		return true
+	}
+	from := strings.ToLower(h.Get("From"))
+	return strings.Contains(from, "mailer-daemon@") || strings.Contains(from, "postmaster@")
+}
+
+var (
+	reHTMLBreak   = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6]|blockquote)>`)
+	reHTMLDrop    = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	reHTMLTag     = regexp.MustCompile(`<[^>]+>`)
+	reBlankLines  = regexp.MustCompile(`\n{3,}`)
+	reAttribution = regexp.MustCompile(`(?m)^(On .{1,200}wrote:|-{2,}\s*Original Message\s*-{2,}|_{10,})\s*$`)
+)
+
+// htmlToText flattens an HTML-only body to readable text.
+func htmlToText(s string) string {
+	s = reHTMLDrop.ReplaceAllString(s, "")
+	s = reHTMLBreak.ReplaceAllString(s, "\n")
+	s = reHTMLTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	return reBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+}
+
+// stripQuotedReply drops the quoted history a mail client appends to a reply:
+// everything from the attribution line ("On ... wrote:") or an Outlook
+// separator onward, trailing "> " lines and the "-- " signature block. The
+// session already holds the earlier messages.
+func stripQuotedReply(text string) string {
+	if loc := reAttribution.FindStringIndex(text); loc != nil && loc[0] > 0 {
+		text = text[:loc[0]]
+	}
+	if i := strings.Index(text, "\n-- \n"); i >= 0 {
+		text = text[:i]
+	}
+	lines := strings.Split(strings.TrimRight(text, "\n "), "\n")
+	end := len(lines)
+	for end > 0 {
+		l := strings.TrimSpace(lines[end-1])
+		if l != "" && !strings.HasPrefix(l, ">") {
+			break
+		}
+		end--
+	}
+	if end == 0 {
+		return strings.TrimSpace(text) // the whole body is a quote; keep it
+	}
+	return strings.TrimSpace(strings.Join(lines[:end], "\n"))
+}
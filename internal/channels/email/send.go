@@ -0,0 +1,296 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+)
+
+const smtpTimeout = 2 * time.Minute
+
+// Send delivers an outbound message as an email. Replies within a thread
+// (local_key from the inbound message) carry In-Reply-To/References so mail
+// clients group them; anything else starts a new thread.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("email channel not running")
+	}
+	// No placeholders to update; empty content is a NO_REPLY.
+	if msg.Metadata["placeholder_update"] == "true" || (msg.Content == "" && len(msg.Media) == 0) {
+		return nil
+	}
+	to, err := mail.ParseAddress(msg.ChatID)
+	if err != nil {
+		return fmt.Errorf("invalid email recipient %q: %w", msg.ChatID, err)
+	}
+
+	var st *threadState
+	if lk := msg.Metadata["local_key"]; lk != "" {
+		if v, ok := c.threads.Load(lk); ok {
+			st = v.(*threadState)
+		}
+	}
+	if st == nil {
+		st = &threadState{to: strings.ToLower(to.Address)}
+	}
+	return c.sendReply(ctx, st, msg.Content, msg.Media)
+}
+
+// sendReply composes and delivers one mail in the given thread, then records
+// the new Message-ID so replies to it land in the same session.
+func (c *Channel) sendReply(ctx context.Context, st *threadState, text string, atts []bus.MediaAttachment) error {
+	st.mu.Lock()
+	hdr := outboundHeaders{
+		to:         st.to,
+		subject:    c.replySubject(st.subject),
+		inReplyTo:  st.inReplyTo,
+		references: append([]string(nil), st.references...),
+	}
+	threadID := st.threadID
+	st.mu.Unlock()
+
+	_, domain, _ := strings.Cut(c.fromAddress, "@")
+	hdr.messageID = "<" + uuid.NewString() + "@" + domain + ">"
+
+	raw, err := c.composeMail(hdr, text, atts)
+	if err != nil {
+		return err
+	}
+	if err := c.deliver(ctx, hdr.to, raw); err != nil {
+		return fmt.Errorf("email smtp send: %w", err)
+	}
+
+	st.mu.Lock()
+	st.references = append(st.references, hdr.messageID)
+	st.updated = time.Now()
+	st.mu.Unlock()
+	if threadID != "" {
+		c.index.Store(hdr.messageID, indexEntry{threadID: threadID, seen: time.Now()})
+	}
+	return nil
+}
+
+func (c *Channel) replySubject(subject string) string {
+	if subject == "" {
+		if c.config.DefaultSubject != "" {
+			return c.config.DefaultSubject
+		}
+		name := c.config.FromName
+		if name == "" {
+			name = "GoClaw"
+		}
+		return "Message from " + name
+	}
+	if len(subject) >= 3 && strings.EqualFold(subject[:3], "re:") {
+		return subject
+	}
+	return "Re: " + subject
+}
+
+type outboundHeaders struct {
+	to         string
+	subject    string
+	messageID  string
+	inReplyTo  string
+	references []string
+}
+
+// composeMail builds a MIME message: multipart/alternative (markdown as
+// text/plain, rendered text/html), wrapped in multipart/mixed when files are
+// attached.
+func (c *Channel) composeMail(h outboundHeaders, text string, atts []bus.MediaAttachment) ([]byte, error) {
+	var buf bytes.Buffer
+	from := mail.Address{Name: c.config.FromName, Address: c.fromAddress}
+	writeHeader(&buf, "From", from.String())
+	writeHeader(&buf, "To", (&mail.Address{Address: h.to}).String())
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", h.subject))
+	writeHeader(&buf, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", h.messageID)
+	if h.inReplyTo != "" {
+		writeHeader(&buf, "In-Reply-To", h.inReplyTo)
+	}
+	if len(h.references) > 0 {
+		writeHeader(&buf, "References", strings.Join(h.references, "\r\n "))
+	}
+	// RFC 3834: tells other auto-responders not to answer back.
+	writeHeader(&buf, "Auto-Submitted", "auto-replied")
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	if len(atts) == 0 {
+		alt := multipart.NewWriter(&buf)
+		writeHeader(&buf, "Content-Type", "multipart/alternative; boundary="+alt.Boundary())
+		buf.WriteString("\r\n")
+		if err := writeAlternatives(alt, text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	writeHeader(&buf, "Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	buf.WriteString("\r\n")
+
+	if text != "" {
+		var altBuf bytes.Buffer
+		alt := multipart.NewWriter(&altBuf)
+		if err := writeAlternatives(alt, text); err != nil {
+			return nil, err
+		}
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {"multipart/alternative; boundary=" + alt.Boundary()},
+		})
+		if err != nil {
+			return nil, err
+		}
+		part.Write(altBuf.Bytes())
+	}
+	for _, att := range atts {
+		if err := writeAttachment(mixed, att); err != nil {
+			slog.Warn("email: attachment skipped", "file", att.URL, "error", err)
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key + ": " + value + "\r\n")
+}
+
+func writeAlternatives(w *multipart.Writer, text string) error {
+	for _, p := range []struct{ ctype, body string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", markdownToEmailHTML(text)},
+	} {
+		part, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.ctype},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(strings.ReplaceAll(p.body, "\n", "\r\n"))); err != nil {
+			return err
+		}
+		if err := qp.Close(); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+func writeAttachment(w *multipart.Writer, att bus.MediaAttachment) error {
+	data, err := os.ReadFile(att.URL)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(att.URL)
+	ctype := att.ContentType
+	if ctype == "" {
+		ctype = media.DetectMIMEType(name)
+	}
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(ctype, map[string]string{"name": name})},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return err
+	}
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 76 {
+		part.Write([]byte(enc[:76] + "\r\n"))
+		enc = enc[76:]
+	}
+	_, err = part.Write([]byte(enc + "\r\n"))
+	return err
+}
+
+// deliver sends one message over SMTP. "tls" dials implicit TLS (port 465);
+// "starttls" requires the upgrade; "none" is for local relays only —
+// net/smtp refuses PLAIN auth over cleartext to non-local hosts.
+func (c *Channel) deliver(ctx context.Context, to string, raw []byte) error {
+	host := c.config.SMTPHost
+	addr := net.JoinHostPort(host, strconv.Itoa(c.config.SMTPPort))
+	d := net.Dialer{Timeout: imapDialTimeout}
+	var conn net.Conn
+	var err error
+	if c.config.SMTPSecurity == "tls" {
+		conn, err = (&tls.Dialer{NetDialer: &d, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("dial %s: %w", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	cl, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer cl.Close()
+
+	if c.config.SMTPSecurity == "starttls" {
+		if ok, _ := cl.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("server %s does not offer STARTTLS", addr)
+		}
+		if err := cl.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if ok, _ := cl.Extension("AUTH"); ok {
+		user := c.config.SMTPUsername
+		if user == "" {
+			user = c.config.Username
+		}
+		pass := c.creds.SMTPPassword
+		if pass == "" {
+			pass = c.creds.Password
+		}
+		if err := cl.Auth(smtp.PlainAuth("", user, pass, host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+	if err := cl.Mail(c.fromAddress); err != nil {
+		return err
+	}
+	if err := cl.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := cl.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return cl.Quit()
+}
@@ -612,15 +612,21 @@ func extractPayloadString(payload any, key string) string {
 // toolStatusMap maps builtin tool names to user-friendly status messages.
 var toolStatusMap = map[string]string{
 	// Filesystem
-	"read_file":  "📝 Reading file...",
-	"write_file": "📝 Writing file...",
-	"list_files": "📝 Listing files...",
-	"edit":       "📝 Editing file...",
+	"read_file":    "📝 Reading file...",
+	"write_file":   "📝 Writing file...",
+	"list_files":   "📝 Listing files...",
+	"search_files": "🔍 Searching files...",
+	"edit":         "📝 Editing file...",
+	"apply_patch":  "📝 Applying patch...",
 	// Runtime
-	"exec": "⚡ Running code...",
+	"exec":             "⚡ Running code...",
+	"code_interpreter": "⚡ Running code...",
 	// Web
 	"web_search": "🔍 Searching the web...",
 	"web_fetch":  "🔍 Fetching web content...",
+	// API & data
+	"http_request": "🌐 Calling API...",
+	"sql_query":    "🗄 Querying database...",
 	// Memory
 	"memory_search":          "🧠 Searching memory...",
 	"memory_get":             "🧠 Retrieving memory...",
@@ -695,9 +701,9 @@ func formatReasoningPreview(thinking string) string {
 // but previously unused reaction variants in channel implementations.
 func resolveToolReactionStatus(toolName string) string {
 	switch {
-	case strings.HasPrefix(toolName, "web") || toolName == "browser":
+	case strings.HasPrefix(toolName, "web") || toolName == "browser" || toolName == "http_request":
 		return "web"
-	case toolName == "exec":
+	case toolName == "exec" || toolName == "code_interpreter":
 		return "coding"
 	default:
 		return "tool"
@@ -0,0 +1,138 @@
+package feishu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+// eventTypeCardAction is the callback Lark sends when a card button is clicked.
+// The app must subscribe to it (Events & Callbacks → Callback configuration).
+const eventTypeCardAction = "card.action.trigger"
+
+// actionValueKey is the key of the button value carrying the action ID.
+const actionValueKey = "goclaw_action"
+
+// CardActionEvent is the parsed structure of a card.action.trigger callback.
+type CardActionEvent struct {
+	Schema string `json:"schema"`
+	Header struct {
+		EventID   string `json:"event_id"`
+		EventType string `json:"event_type"`
+		Token     string `json:"token"`
+	} `json:"header"`
+	Event struct {
+		Operator struct {
+			OpenID string `json:"open_id"`
+			UserID string `json:"user_id"`
+		} `json:"operator"`
+		Action struct {
+			Tag   string         `json:"tag"`
+			Value map[string]any `json:"value"`
+		} `json:"action"`
+		Context struct {
+			OpenMessageID string `json:"open_message_id"`
+			OpenChatID    string `json:"open_chat_id"`
+		} `json:"context"`
+	} `json:"event"`
+}
+
+// SupportsActions reports that actions render as card buttons.
+func (c *Channel) SupportsActions() bool { return true }
+
+// buildActionsCard renders actions as a schema 2.0 card of buttons. Callback
+// buttons echo their ID and label back in the click's action value.
+func buildActionsCard(actions []bus.MessageAction) map[string]any {
+	elements := make([]map[string]any, 0, len(actions))
+	for _, a := range actions {
+		btn := map[string]any{
+			"tag":  "button",
+			"text": map[string]any{"tag": "plain_text", "content": a.Label},
+			"type": "default",
+		}
+		switch a.Style {
+		case bus.ActionStylePrimary:
+			btn["type"] = "primary"
+		case bus.ActionStyleDanger:
+			btn["type"] = "danger"
+		}
+		if a.URL != "" {
+			btn["behaviors"] = []map[string]any{{"type": "open_url", "default_url": a.URL}}
+		} else {
+			btn["behaviors"] = []map[string]any{{
+				"type":  "callback",
+				"value": map[string]string{actionValueKey: a.ID, "label": a.Label},
+			}}
+		}
+		elements = append(elements, btn)
+	}
+	return map[string]any{
+		"schema": "2.0",
+		"body":   map[string]any{"elements": elements},
+	}
+}
+
+// sendActions posts the buttons as an interactive card after the reply text.
+func (c *Channel) sendActions(ctx context.Context, chatID, receiveIDType, replyTargetID string, actions []bus.MessageAction) error {
+	cardJSON, err := json.Marshal(buildActionsCard(actions))
+	if err != nil {
+		return fmt.Errorf("marshal actions card: %w", err)
+	}
+	if err := c.deliverMessage(ctx, chatID, receiveIDType, replyTargetID, "interactive", string(cardJSON)); err != nil {
+		return fmt.Errorf("feishu send actions: %w", err)
+	}
+	return nil
+}
+
+// handleCardAction turns a click on an action button into an inbound message.
+func (c *Channel) handleCardAction(ctx context.Context, event *CardActionEvent) {
+	if event == nil {
+		return
+	}
+	actionID, _ := event.Event.Action.Value[actionValueKey].(string)
+	label, _ := event.Event.Action.Value["label"].(string)
+	senderID := event.Event.Operator.OpenID
+	chatID := event.Event.Context.OpenChatID
+	messageID := event.Event.Context.OpenMessageID
+	if actionID == "" || senderID == "" || chatID == "" {
+		return
+	}
+	if c.isDuplicate(messageID + ":" + actionID + ":" + senderID) {
+		return
+	}
+
+	// Lark's callback carries no chat type; use what inbound messages recorded.
+	// Chats never seen before are treated as groups, the stricter policy.
+	chatType := "group"
+	if v, ok := c.chatTypes.Load(chatID); ok {
+		chatType = v.(string)
+	}
+	peerKind := "group"
+	if chatType == "p2p" {
+		peerKind = "direct"
+	}
+	if !c.ActionAllowed(ctx, senderID, chatID, peerKind, c.cfg.DMPolicy, c.cfg.GroupPolicy) {
+		slog.Debug("feishu action rejected by policy", "sender_id", senderID, "chat_id", chatID)
+		return
+	}
+
+	senderName := c.resolveSenderName(ctx, senderID)
+	metadata := map[string]string{
+		"message_id":     messageID + ":" + actionID,
+		"chat_type":      chatType,
+		"sender_name":    senderName,
+		"display_name":   channels.SanitizeDisplayName(senderName),
+		"platform":       channels.TypeFeishu,
+		"sender_open_id": senderID,
+	}
+
+	c.HandleAction(senderID, chatID, senderName, bus.ActionCallback{
+		ActionID:  actionID,
+		Label:     label,
+		MessageID: messageID,
+	}, metadata, peerKind)
+}
@@ -0,0 +1,38 @@
+package feishu
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+)
+
+func TestBuildActionsCard(t *testing.T) {
+	card := buildActionsCard([]bus.MessageAction{
+		{ID: "yes", Label: "Yes", Style: bus.ActionStylePrimary},
+		{Label: "Docs", URL: "https://example.com"},
+	})
+	raw, err := json.Marshal(card)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(raw)
+	for _, want := range []string{`"type":"primary"`, `"goclaw_action":"yes"`, `"type":"open_url"`, `"default_url":"https://example.com"`} {
+		if !strings.Contains(s, want) {
+			t.Errorf("card missing %s: %s", want, s)
+		}
+	}
+}
+
+func TestCardActionEventParse(t *testing.T) {
+	payload := `{"schema":"2.0","header":{"event_type":"card.action.trigger"},"event":{"operator":{"open_id":"ou_1"},"action":{"tag":"button","value":{"goclaw_action":"yes","label":"Yes"}},"context":{"open_message_id":"om_1","open_chat_id":"oc_1"}}}`
+	var evt CardActionEvent
+	if err := json.Unmarshal([]byte(payload), &evt); err != nil {
+		t.Fatal(err)
+	}
+	if evt.Header.EventType != eventTypeCardAction || evt.Event.Operator.OpenID != "ou_1" ||
+		evt.Event.Context.OpenChatID != "oc_1" || evt.Event.Action.Value[actionValueKey] != "yes" {
+		t.Fatalf("parsed = %+v", evt)
+	}
+}
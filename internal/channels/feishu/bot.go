@@ -59,6 +59,7 @@ func (c *Channel) handleMessageEvent(ctx context.Context, event *MessageEvent) {
 	if mc == nil {
 		return
 	}
+	c.chatTypes.Store(mc.ChatID, mc.ChatType)
 
 	// 2a. Slash commands in DMs are rejected early with a clear hint so
 	// they never reach the agent pipeline (otherwise users typing
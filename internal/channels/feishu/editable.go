@@ -0,0 +1,48 @@
+package feishu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// EditMessage replaces the content of a message the bot sent. Lark edits
+// text/post messages with PUT and cards with PATCH, so the original type is
+// looked up first; the new text keeps that type.
+func (c *Channel) EditMessage(ctx context.Context, _, messageID, content string) error {
+	orig, err := c.client.GetMessage(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("feishu edit: %w", err)
+	}
+	if len(orig.Items) == 0 {
+		return fmt.Errorf("feishu edit: message %s not found", messageID)
+	}
+
+	switch msgType := orig.Items[0].MsgType; msgType {
+	case "interactive":
+		card, err := json.Marshal(buildMarkdownCard(content))
+		if err != nil {
+			return fmt.Errorf("marshal card: %w", err)
+		}
+		err = c.client.PatchCardMessage(ctx, messageID, string(card))
+		if err != nil {
+			return fmt.Errorf("feishu edit card: %w", err)
+		}
+		return nil
+	case "text", "post":
+		if err := c.client.UpdateMessage(ctx, messageID, "post", buildPostContent(content)); err != nil {
+			return fmt.Errorf("feishu edit: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("feishu edit: %s messages cannot be edited", msgType)
+	}
+}
+
+// DeleteMessage recalls a message the bot sent.
+func (c *Channel) DeleteMessage(ctx context.Context, _, messageID string) error {
+	if err := c.client.RecallMessage(ctx, messageID); err != nil {
+		return fmt.Errorf("feishu recall: %w", err)
+	}
+	return nil
+}
@@ -43,6 +43,7 @@ type Channel struct {
 	senderCache     sync.Map                    // open_id → *senderCacheEntry
 	dedup           sync.Map                    // message_id → struct{}
 	reactions       sync.Map                    // chatID → *reactionState
+	chatTypes       sync.Map                    // chat_id → "p2p"/"group", seen on inbound; peer kind of card clicks
 	docCache        *docCache                   // LRU+TTL cache for Lark docx raw_content lookups
 	agentStore      store.AgentStore            // optional — agent key → UUID lookup for writer commands
 	configPermStore store.ConfigPermissionStore // optional — group file writer ACL for /addwriter et al.
@@ -256,6 +257,13 @@ func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
 		}
 	}
 
+	// Buttons follow as their own card so they sit below text and media.
+	if len(msg.Actions) > 0 && msg.Metadata["placeholder_update"] != "true" {
+		if err := c.sendActions(ctx, chatID, receiveIDType, replyTargetID, msg.Actions); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -272,8 +280,15 @@ func (a *wsEventAdapter) HandleEvent(ctx context.Context, payload []byte) error
 		slog.Debug("feishu ws: parse event failed", "error", err)
 		return fmt.Errorf("parse event: %w", err)
 	}
-	if event.Header.EventType == "im.message.receive_v1" {
+	switch event.Header.EventType {
+	case "im.message.receive_v1":
 		a.ch.handleMessageEvent(ctx, &event)
+	case eventTypeCardAction:
+		var action CardActionEvent
+		if err := json.Unmarshal(payload, &action); err != nil {
+			return fmt.Errorf("parse card action: %w", err)
+		}
+		a.ch.handleCardAction(ctx, &action)
 	}
 	return nil
 }
@@ -312,9 +327,12 @@ func (c *Channel) WebhookHandler() (string, http.Handler) {
 		path = defaultWebhookPath
 	}
 
-	handler := NewWebhookHandler(c.cfg.VerificationToken, c.cfg.EncryptKey, func(event *MessageEvent) {
+	handler := newWebhookHandler(c.cfg.VerificationToken, c.cfg.EncryptKey, func(event *MessageEvent) {
 		ctx := store.WithTenantID(context.Background(), c.TenantID())
 		c.handleMessageEvent(ctx, event)
+	}, func(event *CardActionEvent) {
+		ctx := store.WithTenantID(context.Background(), c.TenantID())
+		c.handleCardAction(ctx, event)
 	})
 
 	return path, http.HandlerFunc(handler)
@@ -333,9 +351,12 @@ func (c *Channel) startWebhook(ctx context.Context) error {
 
 	slog.Info("feishu: starting Webhook server", "port", port, "path", path)
 
-	handler := NewWebhookHandler(c.cfg.VerificationToken, c.cfg.EncryptKey, func(event *MessageEvent) {
+	handler := newWebhookHandler(c.cfg.VerificationToken, c.cfg.EncryptKey, func(event *MessageEvent) {
 		ctx := store.WithTenantID(context.Background(), c.TenantID())
 		c.handleMessageEvent(ctx, event)
+	}, func(event *CardActionEvent) {
+		ctx := store.WithTenantID(context.Background(), c.TenantID())
+		c.handleCardAction(ctx, event)
 	})
 
 	mux := http.NewServeMux()
@@ -389,7 +410,8 @@ func (c *Channel) sendChunkedText(ctx context.Context, chatID, receiveIDType, te
 // logs a warning so operators can diagnose stale thread references.
 func (c *Channel) deliverMessage(ctx context.Context, chatID, receiveIDType, replyTargetID, msgType, content string) error {
 	if replyTargetID != "" {
-		if _, err := c.client.ReplyMessage(ctx, replyTargetID, msgType, content, true); err == nil {
+		if resp, err := c.client.ReplyMessage(ctx, replyTargetID, msgType, content, true); err == nil {
+			channels.RecordSentMessage(ctx, resp.MessageID)
 			return nil
 		} else {
 			slog.Warn("feishu.reply_failed_fallback_send",
@@ -400,9 +422,11 @@ func (c *Channel) deliverMessage(ctx context.Context, chatID, receiveIDType, rep
 			// Fall through to new-message endpoint.
 		}
 	}
-	if _, err := c.client.SendMessage(ctx, receiveIDType, chatID, msgType, content); err != nil {
+	resp, err := c.client.SendMessage(ctx, receiveIDType, chatID, msgType, content)
+	if err != nil {
 		return err
 	}
+	channels.RecordSentMessage(ctx, resp.MessageID)
 	return nil
 }
 
@@ -73,6 +73,53 @@ func (c *LarkClient) ReplyMessage(ctx context.Context, rootMessageID, msgType, c
 	return &data, nil
 }
 
+// UpdateMessage replaces the content of a text or post message the bot sent.
+// Lark API: PUT /open-apis/im/v1/messages/{message_id}
+func (c *LarkClient) UpdateMessage(ctx context.Context, messageID, msgType, content string) error {
+	path := "/open-apis/im/v1/messages/" + url.PathEscape(messageID)
+	resp, err := c.doJSON(ctx, "PUT", path, map[string]string{
+		"msg_type": msgType,
+		"content":  content,
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("update message: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+// PatchCardMessage replaces the card of an interactive message the bot sent.
+// Lark API: PATCH /open-apis/im/v1/messages/{message_id}
+func (c *LarkClient) PatchCardMessage(ctx context.Context, messageID, cardJSON string) error {
+	path := "/open-apis/im/v1/messages/" + url.PathEscape(messageID)
+	resp, err := c.doJSON(ctx, "PATCH", path, map[string]string{
+		"content": cardJSON,
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("patch card message: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+// RecallMessage withdraws a message the bot sent.
+// Lark API: DELETE /open-apis/im/v1/messages/{message_id}
+func (c *LarkClient) RecallMessage(ctx context.Context, messageID string) error {
+	path := "/open-apis/im/v1/messages/" + url.PathEscape(messageID)
+	resp, err := c.doJSON(ctx, "DELETE", path, nil)
+	if err != nil {
+		return err
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("recall message: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	return nil
+}
+
 // --- IM API: Images ---
 
 func (c *LarkClient) DownloadImage(ctx context.Context, imageKey string) ([]byte, error) {
@@ -93,6 +93,12 @@ type webhookEvent struct {
 // NewWebhookHandler creates an http.HandlerFunc that handles Feishu webhook events.
 // Supports: URL verification challenge, event decryption, and message dispatch.
 func NewWebhookHandler(verificationToken, encryptKey string, onMessage func(event *MessageEvent)) http.HandlerFunc {
+	return newWebhookHandler(verificationToken, encryptKey, onMessage, nil)
+}
+
+// newWebhookHandler is NewWebhookHandler with an optional card action callback
+// (card.action.trigger); nil ignores button clicks.
+func newWebhookHandler(verificationToken, encryptKey string, onMessage func(event *MessageEvent), onCardAction func(event *CardActionEvent)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -172,9 +178,18 @@ func NewWebhookHandler(verificationToken, encryptKey string, onMessage func(even
 			return
 		}
 
-		// Only handle message events
-		if event.Header.EventType == "im.message.receive_v1" {
+		switch event.Header.EventType {
+		case "im.message.receive_v1":
 			go onMessage(&event)
+		case eventTypeCardAction:
+			var action CardActionEvent
+			if onCardAction != nil && json.Unmarshal(eventBody, &action) == nil {
+				go onCardAction(&action)
+			}
+			// Card callbacks expect a JSON body; an empty object leaves the card as-is.
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte("{}"))
+			return
 		}
 
 		w.WriteHeader(http.StatusOK)
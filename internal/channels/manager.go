@@ -69,6 +69,7 @@ type Manager struct {
 	dispatchTask     *asyncTask
 	mu               sync.RWMutex
 	contactCollector *store.ContactCollector
+	sentMessages     store.SentMessageStore
 }
 
 type asyncTask struct {
@@ -0,0 +1,294 @@
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	apiTimeout       = 30 * time.Second
+	maxRateLimitWait = 10 * time.Second
+)
+
+// client is a minimal Matrix client-server API client. Only the endpoints the
+// channel needs are implemented; end-to-end encryption is not supported.
+type client struct {
+	homeserver  string // base URL without trailing slash
+	accessToken string
+	http        *http.Client
+	txnPrefix   string
+	txnSeq      atomic.Int64
+}
+
+func newClient(homeserver, accessToken string) *client {
+	return &client{
+		homeserver:  strings.TrimRight(homeserver, "/"),
+		accessToken: accessToken,
+		http:        &http.Client{},
+		txnPrefix:   strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+}
+
+// apiError is a Matrix standard error response (errcode + error).
+type apiError struct {
+	Status       int    `json:"-"`
+	ErrCode      string `json:"errcode"`
+	Message      string `json:"error"`
+	RetryAfterMS int64  `json:"retry_after_ms,omitempty"`
+}
+
+func (e *apiError) Error() string {
+	if e.ErrCode == "" {
+		return fmt.Sprintf("matrix: HTTP %d", e.Status)
+	}
+	return fmt.Sprintf("matrix: HTTP %d %s: %s", e.Status, e.ErrCode, e.Message)
+}
+
+// isAuthError reports errors that retrying will not fix (revoked or invalid token).
+func isAuthError(err error) bool {
+	ae, ok := err.(*apiError)
+	return ok && (ae.ErrCode == "M_UNKNOWN_TOKEN" || ae.ErrCode == "M_MISSING_TOKEN" || ae.Status == http.StatusUnauthorized)
+}
+
+// nextTxnID returns a transaction ID unique for this client's lifetime so
+// homeservers can deduplicate retried sends.
+func (c *client) nextTxnID() string {
+	return fmt.Sprintf("goclaw.%s.%d", c.txnPrefix, c.txnSeq.Add(1))
+}
+
+// do sends a JSON request and decodes the JSON response into out (if non-nil).
+// One retry is made on M_LIMIT_EXCEEDED when the server asks for a short wait.
+func (c *client) do(ctx context.Context, method, path string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader
+		if payload != nil {
+			reader = bytes.NewReader(payload)
+		}
+		err := c.raw(ctx, method, path, "application/json", reader, out)
+		ae, ok := err.(*apiError)
+		if !ok || ae.Status != http.StatusTooManyRequests || attempt > 0 {
+			return err
+		}
+		wait := time.Duration(ae.RetryAfterMS) * time.Millisecond
+		if wait <= 0 {
+			wait = time.Second
+		}
+		if wait > maxRateLimitWait {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *client) raw(ctx context.Context, method, path, contentType string, body io.Reader, out any) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, apiTimeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.homeserver+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		ae := &apiError{Status: resp.StatusCode}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(ae)
+		return ae
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// --- Endpoints ---
+
+func (c *client) whoami(ctx context.Context) (string, error) {
+	var resp struct {
+		UserID string `json:"user_id"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/_matrix/client/v3/account/whoami", nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.UserID, nil
+}
+
+// syncResponse holds the subset of /sync the channel consumes.
+type syncResponse struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join map[string]struct {
+			Timeline struct {
+				Events []event `json:"events"`
+			} `json:"timeline"`
+		} `json:"join"`
+		Invite map[string]json.RawMessage `json:"invite"`
+	} `json:"rooms"`
+}
+
+// sync long-polls for new events. timeout is how long the server may hold the
+// request open when there is nothing new.
+func (c *client) sync(ctx context.Context, since string, timeout time.Duration) (*syncResponse, error) {
+	q := url.Values{}
+	q.Set("timeout", strconv.FormatInt(timeout.Milliseconds(), 10))
+	if since != "" {
+		q.Set("since", since)
+	}
+	// Lazy-load members so large rooms don't bloat every sync.
+	q.Set("filter", `{"room":{"state":{"lazy_load_members":true},"timeline":{"limit":50}}}`)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout+apiTimeout)
+	defer cancel()
+	var resp syncResponse
+	if err := c.do(ctx, http.MethodGet, "/_matrix/client/v3/sync?"+q.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *client) joinRoom(ctx context.Context, roomID string) error {
+	return c.do(ctx, http.MethodPost, "/_matrix/client/v3/join/"+url.PathEscape(roomID), map[string]any{}, nil)
+}
+
+// sendEvent sends a room event and returns its event ID.
+func (c *client) sendEvent(ctx context.Context, roomID, eventType string, content any) (string, error) {
+	path := fmt.Sprintf("/_matrix/client/v3/rooms/%s/send/%s/%s",
+		url.PathEscape(roomID), url.PathEscape(eventType), url.PathEscape(c.nextTxnID()))
+	var resp struct {
+		EventID string `json:"event_id"`
+	}
+	if err := c.do(ctx, http.MethodPut, path, content, &resp); err != nil {
+		return "", err
+	}
+	return resp.EventID, nil
+}
+
+func (c *client) redact(ctx context.Context, roomID, eventID string) error {
+	path := fmt.Sprintf("/_matrix/client/v3/rooms/%s/redact/%s/%s",
+		url.PathEscape(roomID), url.PathEscape(eventID), url.PathEscape(c.nextTxnID()))
+	return c.do(ctx, http.MethodPut, path, map[string]any{}, nil)
+}
+
+// roomMember is one entry of /joined_members.
+type roomMember struct {
+	DisplayName string `json:"display_name"`
+}
+
+func (c *client) joinedMembers(ctx context.Context, roomID string) (map[string]roomMember, error) {
+	var resp struct {
+		Joined map[string]roomMember `json:"joined"`
+	}
+	path := "/_matrix/client/v3/rooms/" + url.PathEscape(roomID) + "/joined_members"
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Joined, nil
+}
+
+// upload stores content in the homeserver's media repository and returns its mxc:// URI.
+func (c *client) upload(ctx context.Context, fileName, contentType string, body io.Reader) (string, error) {
+	var resp struct {
+		ContentURI string `json:"content_uri"`
+	}
+	path := "/_matrix/media/v3/upload?filename=" + url.QueryEscape(fileName)
+	if err := c.raw(ctx, http.MethodPost, path, contentType, body, &resp); err != nil {
+		return "", err
+	}
+	return resp.ContentURI, nil
+}
+
+// download streams an mxc:// URI into w, reading at most maxBytes. The
+// authenticated media endpoint is tried first, then the legacy one for
+// homeservers that predate it.
+func (c *client) download(ctx context.Context, mxcURI string, maxBytes int64, w io.Writer) error {
+	server, mediaID, ok := parseMXC(mxcURI)
+	if !ok {
+		return fmt.Errorf("matrix: invalid media URI %q", mxcURI)
+	}
+	ref := url.PathEscape(server) + "/" + url.PathEscape(mediaID)
+	paths := []string{
+		"/_matrix/client/v1/media/download/" + ref,
+		"/_matrix/media/v3/download/" + ref,
+	}
+	var lastErr error
+	for _, p := range paths {
+		lastErr = c.downloadFrom(ctx, p, maxBytes, w)
+		ae, ok := lastErr.(*apiError)
+		if !ok || (ae.Status != http.StatusNotFound && ae.ErrCode != "M_UNRECOGNIZED") {
+			return lastErr
+		}
+	}
+	return lastErr
+}
+
+func (c *client) downloadFrom(ctx context.Context, path string, maxBytes int64, w io.Writer) error {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.homeserver+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		ae := &apiError{Status: resp.StatusCode}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(ae)
+		return ae
+	}
+	n, err := io.Copy(w, io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return err
+	}
+	if n > maxBytes {
+		return fmt.Errorf("matrix: media exceeds %d bytes", maxBytes)
+	}
+	return nil
+}
+
+// parseMXC splits mxc://server/mediaID.
+func parseMXC(uri string) (server, mediaID string, ok bool) {
+	rest, found := strings.CutPrefix(uri, "mxc://")
+	if !found {
+		return "", "", false
+	}
+	server, mediaID, found = strings.Cut(rest, "/")
+	if !found || server == "" || mediaID == "" || strings.Contains(mediaID, "/") {
+		return "", "", false
+	}
+	return server, mediaID, true
+}
@@ -0,0 +1,22 @@
+package matrix
+
+import (
+	"context"
+	"fmt"
+)
+
+// EditMessage replaces the content of a sent event with an m.replace edit.
+func (c *Channel) EditMessage(ctx context.Context, chatID, messageID, content string) error {
+	if err := c.editMessage(ctx, extractRoomID(chatID), messageID, content); err != nil {
+		return fmt.Errorf("matrix edit: %w", err)
+	}
+	return nil
+}
+
+// DeleteMessage redacts a sent event.
+func (c *Channel) DeleteMessage(ctx context.Context, chatID, messageID string) error {
+	if err := c.client.redact(ctx, extractRoomID(chatID), messageID); err != nil {
+		return fmt.Errorf("matrix redact: %w", err)
+	}
+	return nil
+}
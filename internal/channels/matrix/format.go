@@ -0,0 +1,96 @@
+package matrix
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// --- Markdown to Matrix HTML (org.matrix.custom.html) ---
+// Matrix clients render a safe HTML subset; the plain markdown stays in body
+// as the fallback, so unsupported syntax is simply left as text.
+
+var (
+	reCodeBlock  = regexp.MustCompile("```([\\w+-]*)\\n?([\\s\\S]*?)```")
+	reInlineCode = regexp.MustCompile("`([^`\\n]+)`")
+	reHeader     = regexp.MustCompile(`(?m)^(#{1,6})\s+(.+)$`)
+	reQuote      = regexp.MustCompile(`(?m)^&gt;\s?(.*)$`)
+	reLink       = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+	reBold       = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	reItalic     = regexp.MustCompile(`(^|[\s(])[*_]([^*_\s][^*_]*?)[*_]([\s).,!?:;]|$)`)
+	reStrike     = regexp.MustCompile(`~~(.+?)~~`)
+	reListItem   = regexp.MustCompile(`(?m)^[-*]\s+(.+)$`)
+)
+
+func markdownToMatrixHTML(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	// Protect code from every other rule.
+	var codes []string
+	text = reCodeBlock.ReplaceAllStringFunc(text, func(s string) string {
+		m := reCodeBlock.FindStringSubmatch(s)
+		class := ""
+		if m[1] != "" {
+			class = fmt.Sprintf(` class="language-%s"`, html.EscapeString(m[1]))
+		}
+		codes = append(codes, fmt.Sprintf("<pre><code%s>%s</code></pre>", class, html.EscapeString(m[2])))
+		return fmt.Sprintf("\x00CB%d\x00", len(codes)-1)
+	})
+	text = reInlineCode.ReplaceAllStringFunc(text, func(s string) string {
+		m := reInlineCode.FindStringSubmatch(s)
+		codes = append(codes, "<code>"+html.EscapeString(m[1])+"</code>")
+		return fmt.Sprintf("\x00CB%d\x00", len(codes)-1)
+	})
+
+	text = html.EscapeString(text)
+	text = reHeader.ReplaceAllStringFunc(text, func(s string) string {
+		m := reHeader.FindStringSubmatch(s)
+		return fmt.Sprintf("<h%d>%s</h%d>", len(m[1]), m[2], len(m[1]))
+	})
+	text = reQuote.ReplaceAllString(text, "<blockquote>$1</blockquote>")
+	text = reLink.ReplaceAllString(text, `<a href="$2">$1</a>`)
+	text = reBold.ReplaceAllString(text, "<strong>$1</strong>")
+	text = reItalic.ReplaceAllString(text, "$1<em>$2</em>$3")
+	text = reStrike.ReplaceAllString(text, "<del>$1</del>")
+	text = reListItem.ReplaceAllString(text, "<li>$1</li>")
+	text = strings.ReplaceAll(text, "</blockquote>\n<blockquote>", "<br>")
+	text = wrapListItems(text)
+	text = strings.ReplaceAll(text, "\n", "<br>")
+	text = strings.NewReplacer("</h1><br>", "</h1>", "</h2><br>", "</h2>", "</h3><br>", "</h3>",
+		"</h4><br>", "</h4>", "</h5><br>", "</h5>", "</h6><br>", "</h6>", "</blockquote><br>", "</blockquote>", "</ul><br>", "</ul>").Replace(text)
+
+	for i, code := range codes {
+		text = strings.Replace(text, fmt.Sprintf("\x00CB%d\x00", i), code, 1)
+	}
+	return text
+}
+
+// wrapListItems groups consecutive <li> lines into a <ul>.
+func wrapListItems(text string) string {
+	lines := strings.Split(text, "\n")
+	var out []string
+	inList := false
+	for _, line := range lines {
+		isItem := strings.HasPrefix(line, "<li>")
+		switch {
+		case isItem && !inList:
+			out = append(out, "<ul>"+line)
+			inList = true
+		case isItem:
+			out[len(out)-1] += line
+		case inList:
+			out[len(out)-1] += "</ul>"
+			out = append(out, line)
+			inList = false
+		default:
+			out = append(out, line)
+		}
+	}
+	if inList {
+		out[len(out)-1] += "</ul>"
+	}
+	return strings.Join(out, "\n")
+}
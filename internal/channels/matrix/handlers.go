@@ -0,0 +1,345 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// event is a Matrix room event as delivered in a sync timeline.
+type event struct {
+	Type           string          `json:"type"`
+	EventID        string          `json:"event_id"`
+	Sender         string          `json:"sender"`
+	OriginServerTS int64           `json:"origin_server_ts"`
+	Content        json.RawMessage `json:"content"`
+}
+
+// messageContent is the content of an m.room.message event.
+type messageContent struct {
+	MsgType       string     `json:"msgtype"`
+	Body          string     `json:"body"`
+	Format        string     `json:"format,omitempty"`
+	FormattedBody string     `json:"formatted_body,omitempty"`
+	URL           string     `json:"url,omitempty"`      // mxc:// URI for media messages
+	FileName      string     `json:"filename,omitempty"` // when set, Body is a caption
+	Info          *mediaInfo `json:"info,omitempty"`
+	RelatesTo     *relatesTo `json:"m.relates_to,omitempty"`
+	Mentions      *mentions  `json:"m.mentions,omitempty"`
+}
+
+type mediaInfo struct {
+	MimeType string `json:"mimetype,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+}
+
+type relatesTo struct {
+	RelType       string     `json:"rel_type,omitempty"` // m.thread, m.replace, m.annotation
+	EventID       string     `json:"event_id,omitempty"`
+	Key           string     `json:"key,omitempty"` // annotation key (reaction emoji)
+	IsFallingBack bool       `json:"is_falling_back,omitempty"`
+	InReplyTo     *inReplyTo `json:"m.in_reply_to,omitempty"`
+}
+
+type inReplyTo struct {
+	EventID string `json:"event_id"`
+}
+
+type mentions struct {
+	UserIDs []string `json:"user_ids,omitempty"`
+}
+
+// handleEvent processes one timeline event from a joined room.
+func (c *Channel) handleEvent(ctx context.Context, roomID string, ev event) {
+	switch ev.Type {
+	case "m.room.member":
+		c.invalidateMembers(roomID)
+		return
+	case "m.room.encrypted":
+		slog.Debug("matrix: encrypted event ignored (E2EE not supported)", "room_id", roomID, "event_id", ev.EventID)
+		return
+	case "m.room.message":
+	default:
+		return
+	}
+	if ev.Sender == c.userID || ev.Sender == "" {
+		return
+	}
+	if _, loaded := c.dedup.LoadOrStore(ev.EventID, time.Now()); loaded {
+		return
+	}
+
+	var msg messageContent
+	if err := json.Unmarshal(ev.Content, &msg); err != nil {
+		slog.Debug("matrix: undecodable message content", "event_id", ev.EventID, "error", err)
+		return
+	}
+	// Edits arrive as new events; the original was already handled.
+	if msg.RelatesTo != nil && msg.RelatesTo.RelType == "m.replace" {
+		return
+	}
+	if msg.MsgType == "m.notice" {
+		return // bots talk to each other with notices; never answer them
+	}
+	c.handleMessage(store.WithTenantID(ctx, c.TenantID()), roomID, ev, msg)
+}
+
+func (c *Channel) handleMessage(ctx context.Context, roomID string, ev event, msg messageContent) {
+	senderID := ev.Sender
+
+	isDM := c.isDirectRoom(ctx, roomID)
+	peerKind := "group"
+	if isDM {
+		peerKind = "direct"
+	}
+
+	if isDM {
+		if !c.checkDMPolicy(ctx, senderID, roomID) {
+			return
+		}
+		if !c.IsAllowed(senderID) {
+			slog.Debug("matrix message rejected by allowlist", "user_id", senderID)
+			return
+		}
+	} else if !c.checkGroupPolicy(ctx, senderID, roomID) {
+		return
+	}
+
+	displayName := c.displayName(ctx, roomID, senderID)
+
+	var threadRoot string
+	if rt := msg.RelatesTo; rt != nil && rt.RelType == "m.thread" {
+		threadRoot = rt.EventID
+	}
+
+	content, items := c.messageText(ctx, msg)
+	var mediaPaths []string
+	if len(items) > 0 {
+		if tags := media.BuildMediaTags(items); tags != "" {
+			content = strings.TrimSpace(tags + "\n\n" + content)
+		}
+		for _, it := range items {
+			mediaPaths = append(mediaPaths, it.FilePath)
+		}
+	}
+	if content == "" {
+		return
+	}
+
+	localKey := roomID
+	if threadRoot != "" {
+		localKey = roomID + ":thread:" + threadRoot
+	}
+
+	// Mention gating in rooms: unmentioned messages become pending history.
+	if !isDM && c.RequireMention() && !c.isBotMentioned(ctx, roomID, msg) {
+		c.GroupHistory().Record(localKey, channels.HistoryEntry{
+			Sender:    displayName,
+			SenderID:  senderID,
+			Body:      content,
+			Media:     mediaPaths,
+			Timestamp: time.UnixMilli(ev.OriginServerTS),
+			MessageID: ev.EventID,
+		}, c.HistoryLimit())
+		if cc := c.ContactCollector(); cc != nil {
+			cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", "group", "user", "", "")
+		}
+		return
+	}
+	content = strings.TrimSpace(c.stripBotMention(ctx, roomID, content))
+
+	slog.Debug("matrix message received",
+		"sender_id", senderID, "room_id", roomID,
+		"is_dm", isDM, "preview", channels.Truncate(content, 50))
+
+	replyRoot := threadRoot
+	if !isDM && replyRoot == "" && c.config.ThreadReplies != nil && *c.config.ThreadReplies {
+		replyRoot = ev.EventID // start a thread from the triggering message
+	}
+
+	if id, err := c.client.sendEvent(ctx, roomID, "m.room.message",
+		textContent("Thinking...", replyRoot, ev.EventID)); err == nil {
+		c.placeholders.Store(localKey, id)
+	}
+
+	finalContent := content
+	if peerKind == "group" {
+		annotated := fmt.Sprintf("[From: %s]\n%s", displayName, content)
+		if c.HistoryLimit() > 0 {
+			if histMedia := c.GroupHistory().CollectMedia(localKey); len(histMedia) > 0 {
+				mediaPaths = append(mediaPaths, histMedia...)
+			}
+			finalContent = c.GroupHistory().BuildContext(localKey, annotated, c.HistoryLimit())
+		} else {
+			finalContent = annotated
+		}
+	}
+
+	metadata := map[string]string{
+		"message_id":      ev.EventID,
+		"user_id":         senderID,
+		"username":        localpart(senderID),
+		"display_name":    channels.SanitizeDisplayName(displayName),
+		"channel_id":      roomID,
+		"is_dm":           fmt.Sprintf("%t", isDM),
+		"local_key":       localKey,
+		"placeholder_key": localKey,
+	}
+	if replyRoot != "" {
+		metadata["message_thread_id"] = replyRoot
+	}
+
+	c.HandleMessage(senderID, roomID, finalContent, mediaPaths, metadata, peerKind)
+
+	if peerKind == "group" {
+		c.GroupHistory().Clear(localKey)
+	}
+}
+
+// messageText returns the user-visible text of a message and downloads any
+// attached media.
+func (c *Channel) messageText(ctx context.Context, msg messageContent) (string, []media.MediaInfo) {
+	switch msg.MsgType {
+	case "m.image", "m.file", "m.audio", "m.video":
+		item, err := c.downloadMedia(ctx, msg)
+		if err != nil {
+			slog.Warn("matrix: media download failed", "url", msg.URL, "error", err)
+			return msg.Body, nil
+		}
+		// Body is the file name unless a separate filename is given (then it's a caption).
+		text := ""
+		if msg.FileName != "" && msg.Body != msg.FileName {
+			text = msg.Body
+		}
+		if item.Type == media.TypeDocument {
+			if doc, err := media.ExtractDocumentContent(item.FilePath, item.FileName); err != nil {
+				slog.Warn("matrix: document extraction failed", "file", item.FileName, "error", err)
+			} else if doc != "" {
+				text = strings.TrimSpace(text + "\n\n" + doc)
+			}
+		}
+		return text, []media.MediaInfo{item}
+	default:
+		body := msg.Body
+		if msg.RelatesTo != nil && msg.RelatesTo.InReplyTo != nil && !msg.RelatesTo.IsFallingBack {
+			body = stripReplyFallback(body)
+		}
+		return strings.TrimSpace(body), nil
+	}
+}
+
+// stripReplyFallback removes the "> <@user> quoted text" lines clients prepend
+// to replies for backwards compatibility.
+func stripReplyFallback(body string) string {
+	if !strings.HasPrefix(body, "> ") {
+		return body
+	}
+	lines := strings.Split(body, "\n")
+	i := 0
+	for i < len(lines) && strings.HasPrefix(lines[i], ">") {
+		i++
+	}
+	return strings.TrimLeft(strings.Join(lines[i:], "\n"), "\n")
+}
+
+// isDirectRoom treats two-member rooms as DMs. Lookup failures count as group
+// so the stricter group policy applies.
+func (c *Channel) isDirectRoom(ctx context.Context, roomID string) bool {
+	members, err := c.roomMembers(ctx, roomID)
+	if err != nil {
+		slog.Debug("matrix: member lookup failed", "room_id", roomID, "error", err)
+		return false
+	}
+	return len(members) <= 2
+}
+
+// isBotMentioned checks intentional mentions (m.mentions), then falls back to
+// pills in formatted_body and the bot's ID or display name in the plain body.
+func (c *Channel) isBotMentioned(ctx context.Context, roomID string, msg messageContent) bool {
+	if msg.Mentions != nil {
+		return slices.Contains(msg.Mentions.UserIDs, c.userID)
+	}
+	if msg.FormattedBody != "" {
+		if strings.Contains(msg.FormattedBody, "matrix.to/#/"+c.userID) ||
+			strings.Contains(msg.FormattedBody, "matrix.to/#/"+url.PathEscape(c.userID)) {
+			return true
+		}
+	}
+	if strings.Contains(msg.Body, c.userID) {
+		return true
+	}
+	name := c.displayName(ctx, roomID, c.userID)
+	return name != "" && strings.HasPrefix(strings.ToLower(msg.Body), strings.ToLower(name)+":")
+}
+
+// stripBotMention removes the bot's ID and a leading "BotName:" addressing prefix.
+func (c *Channel) stripBotMention(ctx context.Context, roomID, content string) string {
+	content = strings.ReplaceAll(content, c.userID, "")
+	name := c.displayName(ctx, roomID, c.userID)
+	if name != "" && len(content) > len(name) && strings.EqualFold(content[:len(name)], name) {
+		rest := content[len(name):]
+		if strings.HasPrefix(rest, ":") {
+			content = rest[1:]
+		}
+	}
+	return content
+}
+
+// checkDMPolicy enforces DM policy for incoming messages.
+func (c *Channel) checkDMPolicy(ctx context.Context, senderID, roomID string) bool {
+	switch c.CheckDMPolicy(ctx, senderID, c.config.DMPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, senderID, roomID)
+		return false
+	default:
+		slog.Debug("matrix DM rejected by policy", "sender_id", senderID, "policy", c.config.DMPolicy)
+		return false
+	}
+}
+
+// checkGroupPolicy enforces room access policy; it does not check mention gating.
+func (c *Channel) checkGroupPolicy(ctx context.Context, senderID, roomID string) bool {
+	switch c.CheckGroupPolicy(ctx, senderID, roomID, c.config.GroupPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, "group:"+roomID, roomID)
+		return false
+	default:
+		slog.Debug("matrix room message rejected by policy", "room_id", roomID, "policy", c.config.GroupPolicy)
+		return false
+	}
+}
+
+func (c *Channel) sendPairingReply(ctx context.Context, senderID, roomID string) {
+	ps := c.PairingService()
+	if ps == nil || !c.CanSendPairingNotif(senderID, pairingDebounce) {
+		return
+	}
+	code, err := ps.RequestPairing(ctx, senderID, c.Name(), roomID, "default", nil)
+	if err != nil {
+		slog.Debug("matrix pairing request failed", "sender_id", senderID, "error", err)
+		return
+	}
+	reply := fmt.Sprintf(
+		"GoClaw: access not configured.\n\nYour Matrix ID: %s\n\nPairing code: %s\n\nAsk the bot owner to approve with:\n  goclaw pairing approve %s",
+		senderID, code, code,
+	)
+	if _, err := c.client.sendEvent(ctx, roomID, "m.room.message", noticeContent(reply)); err != nil {
+		slog.Warn("matrix: failed to send pairing reply", "error", err)
+		return
+	}
+	c.MarkPairingNotifSent(senderID)
+	slog.Info("matrix pairing reply sent", "sender_id", senderID, "code", code)
+}
@@ -0,0 +1,382 @@
+// Package matrix implements a GoClaw channel for Matrix homeservers using the
+// client-server API: /sync long-polling for inbound events, message edits for
+// streaming, annotation events for status reactions.
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/safego"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	maxMessageLen   = 16000 // keep events well below the 64 KiB PDU limit
+	syncTimeout     = 30 * time.Second
+	syncBackoffMax  = 60 * time.Second
+	memberCacheTTL  = 5 * time.Minute
+	pairingDebounce = 60 * time.Second
+)
+
+// Channel connects to a Matrix homeserver as a bot user.
+type Channel struct {
+	*channels.BaseChannel
+	client        *client
+	config        matrixInstanceConfig
+	userID        string // bot's own Matrix ID, resolved via whoami on Start()
+	mediaMaxBytes int64
+
+	placeholders sync.Map // localKey -> placeholder event ID
+	dedup        sync.Map // event ID -> time.Time
+	reactions    sync.Map // chatID:messageID -> *reactionState
+
+	membersMu sync.RWMutex
+	members   map[string]cachedMembers // roomID -> joined members
+
+	syncMu    sync.Mutex
+	nextBatch string
+
+	wg       sync.WaitGroup
+	cancelFn context.CancelFunc
+}
+
+type cachedMembers struct {
+	members   map[string]roomMember
+	fetchedAt time.Time
+}
+
+// Compile-time interface assertions.
+var _ channels.Channel = (*Channel)(nil)
+var _ channels.StreamingChannel = (*Channel)(nil)
+var _ channels.ReactionChannel = (*Channel)(nil)
+var _ channels.GroupMemberProvider = (*Channel)(nil)
+var _ channels.BlockReplyChannel = (*Channel)(nil)
+
+// New creates a new Matrix channel from instance config and credentials.
+func New(cfg matrixInstanceConfig, creds matrixCreds, msgBus *bus.MessageBus, pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (*Channel, error) {
+	if cfg.Homeserver == "" {
+		return nil, fmt.Errorf("matrix homeserver is required")
+	}
+	u, err := url.Parse(cfg.Homeserver)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("matrix homeserver must be an http(s) URL")
+	}
+	if creds.AccessToken == "" {
+		return nil, fmt.Errorf("matrix access_token is required")
+	}
+
+	base := channels.NewBaseChannel(channels.TypeMatrix, msgBus, cfg.AllowFrom)
+	base.ValidatePolicy(cfg.DMPolicy, cfg.GroupPolicy)
+
+	requireMention := true
+	if cfg.RequireMention != nil {
+		requireMention = *cfg.RequireMention
+	}
+	historyLimit := cfg.HistoryLimit
+	if historyLimit == 0 {
+		historyLimit = channels.DefaultGroupHistoryLimit
+	}
+	mediaMax := int64(cfg.MediaMaxMB) * 1024 * 1024
+	if mediaMax <= 0 {
+		mediaMax = defaultMediaMaxBytes
+	}
+
+	ch := &Channel{
+		BaseChannel:   base,
+		client:        newClient(cfg.Homeserver, creds.AccessToken),
+		config:        cfg,
+		mediaMaxBytes: mediaMax,
+		members:       make(map[string]cachedMembers),
+	}
+	ch.SetRequireMention(requireMention)
+	ch.SetPairingService(pairingSvc)
+	ch.SetGroupHistory(channels.MakeHistory(channels.TypeMatrix, pendingStore, base.TenantID()))
+	ch.SetHistoryLimit(historyLimit)
+	return ch, nil
+}
+
+// Start resolves the bot identity, skips room backlog with an initial sync and
+// then long-polls /sync in the background.
+func (c *Channel) Start(ctx context.Context) error {
+	c.GroupHistory().StartFlusher()
+	c.MarkStarting("connecting to homeserver")
+
+	userID, err := c.client.whoami(ctx)
+	if err != nil {
+		kind := channels.ChannelFailureKindNetwork
+		if isAuthError(err) {
+			kind = channels.ChannelFailureKindAuth
+		}
+		c.MarkFailed("whoami failed", err.Error(), kind, kind != channels.ChannelFailureKindAuth)
+		return fmt.Errorf("matrix whoami failed: %w", err)
+	}
+	c.userID = userID
+
+	// Initial sync: only the batch token matters. Replaying history would
+	// answer messages that were sent while the bot was offline.
+	initial, err := c.client.sync(ctx, "", 0)
+	if err != nil {
+		c.MarkFailed("initial sync failed", err.Error(), channels.ChannelFailureKindNetwork, true)
+		return fmt.Errorf("matrix initial sync failed: %w", err)
+	}
+	c.setNextBatch(initial.NextBatch)
+
+	syncCtx, cancel := context.WithCancel(context.Background())
+	c.cancelFn = cancel
+
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		defer safego.Recover(nil, "component", "matrix_sync")
+		c.handleInvites(syncCtx, initial)
+		c.syncLoop(syncCtx)
+	}()
+	go func() {
+		defer c.wg.Done()
+		defer safego.Recover(nil, "component", "matrix_sweep")
+		ticker := time.NewTicker(2 * time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-syncCtx.Done():
+				return
+			case <-ticker.C:
+				c.sweepMaps()
+			}
+		}
+	}()
+
+	c.SetRunning(true)
+	c.MarkHealthy("connected as " + userID)
+	slog.Info("matrix bot connected", "user_id", userID, "homeserver", c.config.Homeserver)
+	return nil
+}
+
+// syncLoop long-polls /sync until ctx is cancelled, backing off on errors.
+func (c *Channel) syncLoop(ctx context.Context) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		resp, err := c.client.sync(ctx, c.getNextBatch(), syncTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if isAuthError(err) {
+				slog.Error("matrix: access token rejected, stopping sync", "error", err)
+				c.MarkFailed("access token rejected", err.Error(), channels.ChannelFailureKindAuth, false)
+				c.SetRunning(false)
+				return
+			}
+			slog.Warn("matrix sync failed, retrying", "error", err, "backoff", backoff)
+			c.MarkDegraded("sync failed", err.Error(), channels.ChannelFailureKindNetwork, true)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, syncBackoffMax)
+			continue
+		}
+		if backoff > time.Second {
+			c.MarkHealthy("connected as " + c.userID)
+		}
+		backoff = time.Second
+		c.processSync(ctx, resp)
+		c.setNextBatch(resp.NextBatch)
+	}
+}
+
+// processSync dispatches timeline events from joined rooms and accepts invites.
+func (c *Channel) processSync(ctx context.Context, resp *syncResponse) {
+	c.handleInvites(ctx, resp)
+	for roomID, room := range resp.Rooms.Join {
+		for _, ev := range room.Timeline.Events {
+			c.handleEvent(ctx, roomID, ev)
+		}
+	}
+}
+
+func (c *Channel) handleInvites(ctx context.Context, resp *syncResponse) {
+	if c.config.AutoJoin != nil && !*c.config.AutoJoin {
+		return
+	}
+	for roomID := range resp.Rooms.Invite {
+		if err := c.client.joinRoom(ctx, roomID); err != nil {
+			slog.Warn("matrix: join invited room failed", "room_id", roomID, "error", err)
+			continue
+		}
+		slog.Info("matrix: joined room", "room_id", roomID)
+	}
+}
+
+func (c *Channel) getNextBatch() string {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	return c.nextBatch
+}
+
+func (c *Channel) setNextBatch(token string) {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	c.nextBatch = token
+}
+
+// sweepMaps performs age-based eviction of the dedup and member caches.
+func (c *Channel) sweepMaps() {
+	now := time.Now()
+	c.dedup.Range(func(k, v any) bool {
+		if t, ok := v.(time.Time); ok && now.Sub(t) > 10*time.Minute {
+			c.dedup.Delete(k)
+		}
+		return true
+	})
+
+	c.membersMu.Lock()
+	for roomID, cm := range c.members {
+		if now.Sub(cm.fetchedAt) > memberCacheTTL {
+			delete(c.members, roomID)
+		}
+	}
+	c.membersMu.Unlock()
+}
+
+// SetPendingCompaction configures LLM-based auto-compaction for pending messages.
+func (c *Channel) SetPendingCompaction(cfg *channels.CompactionConfig) {
+	if gh := c.GroupHistory(); gh != nil {
+		gh.SetCompactionConfig(cfg)
+	}
+}
+
+// SetPendingHistoryTenantID propagates tenant_id to the pending history for DB operations.
+func (c *Channel) SetPendingHistoryTenantID(id uuid.UUID) {
+	if gh := c.GroupHistory(); gh != nil {
+		gh.SetTenantID(id)
+	}
+}
+
+// BlockReplyEnabled returns the per-channel block_reply override (nil = inherit gateway default).
+func (c *Channel) BlockReplyEnabled() *bool { return c.config.BlockReply }
+
+// ChatBehaviorConfig returns the per-channel chat_behavior override.
+func (c *Channel) ChatBehaviorConfig() *config.ChatBehaviorConfig { return c.config.ChatBehavior }
+
+// Stop gracefully shuts down the Matrix channel.
+func (c *Channel) Stop(_ context.Context) error {
+	c.GroupHistory().StopFlusher()
+	slog.Info("stopping matrix bot")
+	c.SetRunning(false)
+
+	if c.cancelFn != nil {
+		c.cancelFn()
+	}
+
+	doneCh := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(doneCh)
+	}()
+	select {
+	case <-doneCh:
+	case <-time.After(10 * time.Second):
+		slog.Warn("matrix bot stop timed out after 10s")
+	}
+	c.MarkStopped("stopped")
+	return nil
+}
+
+// roomMembers returns the joined members of a room, cached for memberCacheTTL.
+func (c *Channel) roomMembers(ctx context.Context, roomID string) (map[string]roomMember, error) {
+	c.membersMu.RLock()
+	cm, ok := c.members[roomID]
+	c.membersMu.RUnlock()
+	if ok && time.Since(cm.fetchedAt) < memberCacheTTL {
+		return cm.members, nil
+	}
+
+	members, err := c.client.joinedMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	c.membersMu.Lock()
+	c.members[roomID] = cachedMembers{members: members, fetchedAt: time.Now()}
+	c.membersMu.Unlock()
+	return members, nil
+}
+
+// invalidateMembers drops the cached member list after a membership change.
+func (c *Channel) invalidateMembers(roomID string) {
+	c.membersMu.Lock()
+	delete(c.members, roomID)
+	c.membersMu.Unlock()
+}
+
+// displayName resolves a user's room display name, falling back to the localpart.
+func (c *Channel) displayName(ctx context.Context, roomID, userID string) string {
+	if members, err := c.roomMembers(ctx, roomID); err == nil {
+		if m, ok := members[userID]; ok && m.DisplayName != "" {
+			return m.DisplayName
+		}
+	}
+	return localpart(userID)
+}
+
+// localpart returns "alice" for "@alice:example.org".
+func localpart(userID string) string {
+	s := strings.TrimPrefix(userID, "@")
+	if i := strings.IndexByte(s, ':'); i > 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// ListGroupMembers returns the joined members of a room.
+func (c *Channel) ListGroupMembers(ctx context.Context, chatID string) ([]channels.GroupMember, error) {
+	members, err := c.roomMembers(ctx, extractRoomID(chatID))
+	if err != nil {
+		slog.Warn("matrix.list_group_members", "room_id", chatID, "error", err)
+		return nil, err
+	}
+	result := make([]channels.GroupMember, 0, len(members))
+	for id, m := range members {
+		if id == c.userID {
+			continue
+		}
+		name := m.DisplayName
+		if name == "" {
+			name = localpart(id)
+		}
+		result = append(result, channels.GroupMember{MemberID: id, Name: name})
+		if cc := c.ContactCollector(); cc != nil {
+			cc.EnsureContact(ctx, channels.TypeMatrix, c.Name(), id, id, name, "", "group", "user", "", "")
+		}
+	}
+	return result, nil
+}
+
+// extractRoomID gets the room ID from a local_key ("!room:server:thread:$event").
+func extractRoomID(localKey string) string {
+	if idx := strings.Index(localKey, ":thread:"); idx > 0 {
+		return localKey[:idx]
+	}
+	return localKey
+}
+
+// extractThreadRoot gets the thread root event ID from a local_key, or "" if not threaded.
+func extractThreadRoot(localKey string) string {
+	const marker = ":thread:"
+	if idx := strings.Index(localKey, marker); idx > 0 {
+		return localKey[idx+len(marker):]
+	}
+	return ""
+}
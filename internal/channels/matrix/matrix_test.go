@@ -0,0 +1,329 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+)
+
+const (
+	botID   = "@goclaw:hs.test"
+	groupID = "!group:hs.test"
+	dmID    = "!dm:hs.test"
+)
+
+type sentEvent struct {
+	RoomID  string
+	Type    string
+	Content map[string]any
+}
+
+// fakeHomeserver serves the client-server endpoints the channel uses.
+// Sync responses are queued by the test; an empty queue long-polls.
+type fakeHomeserver struct {
+	srv   *httptest.Server
+	syncs chan string
+
+	mu       sync.Mutex
+	sent     []sentEvent
+	redacted []string
+	joined   []string
+	media    map[string][]byte
+}
+
+func newFakeHomeserver(t *testing.T) *fakeHomeserver {
+	t.Helper()
+	hs := &fakeHomeserver{syncs: make(chan string, 8), media: map[string][]byte{"pic": []byte("PNGDATA")}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /_matrix/client/v3/account/whoami", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"errcode":"M_UNKNOWN_TOKEN","error":"bad token"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"user_id":"` + botID + `"}`))
+	})
+	mux.HandleFunc("GET /_matrix/client/v3/sync", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("since") == "" {
+			// Backlog from before the bot started must be skipped.
+			_, _ = w.Write([]byte(`{"next_batch":"s1","rooms":{"join":{"` + groupID + `":{"timeline":{"events":[
+				{"type":"m.room.message","event_id":"$old","sender":"@alice:hs.test","content":{"msgtype":"m.text","body":"old ` + botID + `"}}]}}}}}`))
+			return
+		}
+		select {
+		case body := <-hs.syncs:
+			_, _ = w.Write([]byte(body))
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+			_, _ = w.Write([]byte(`{"next_batch":"` + r.URL.Query().Get("since") + `"}`))
+		}
+	})
+	mux.HandleFunc("GET /_matrix/client/v3/rooms/{room}/joined_members", func(w http.ResponseWriter, r *http.Request) {
+		joined := map[string]any{
+			botID:            map[string]string{"display_name": "GoClaw"},
+			"@alice:hs.test": map[string]string{"display_name": "Alice"},
+		}
+		if r.PathValue("room") == groupID {
+			joined["@bob:hs.test"] = map[string]string{"display_name": "Bob"}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"joined": joined})
+	})
+	mux.HandleFunc("PUT /_matrix/client/v3/rooms/{room}/send/{type}/{txn}", func(w http.ResponseWriter, r *http.Request) {
+		var content map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&content)
+		hs.mu.Lock()
+		hs.sent = append(hs.sent, sentEvent{RoomID: r.PathValue("room"), Type: r.PathValue("type"), Content: content})
+		id := "$sent" + strconv.Itoa(len(hs.sent))
+		hs.mu.Unlock()
+		_, _ = w.Write([]byte(`{"event_id":"` + id + `"}`))
+	})
+	mux.HandleFunc("PUT /_matrix/client/v3/rooms/{room}/redact/{event}/{txn}", func(w http.ResponseWriter, r *http.Request) {
+		hs.mu.Lock()
+		hs.redacted = append(hs.redacted, r.PathValue("event"))
+		hs.mu.Unlock()
+		_, _ = w.Write([]byte(`{"event_id":"$redaction"}`))
+	})
+	mux.HandleFunc("POST /_matrix/client/v3/join/{room}", func(w http.ResponseWriter, r *http.Request) {
+		hs.mu.Lock()
+		hs.joined = append(hs.joined, r.PathValue("room"))
+		hs.mu.Unlock()
+		_, _ = w.Write([]byte(`{"room_id":"` + r.PathValue("room") + `"}`))
+	})
+	mux.HandleFunc("POST /_matrix/media/v3/upload", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		hs.mu.Lock()
+		hs.media["up"] = data
+		hs.mu.Unlock()
+		_, _ = w.Write([]byte(`{"content_uri":"mxc://hs.test/up"}`))
+	})
+	// Only the legacy media endpoint exists, exercising the fallback.
+	mux.HandleFunc("GET /_matrix/media/v3/download/{server}/{id}", func(w http.ResponseWriter, r *http.Request) {
+		hs.mu.Lock()
+		data, ok := hs.media[r.PathValue("id")]
+		hs.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errcode":"M_UNRECOGNIZED","error":"unrecognized"}`))
+	})
+	hs.srv = httptest.NewServer(mux)
+	t.Cleanup(hs.srv.Close)
+	return hs
+}
+
+func (hs *fakeHomeserver) sentEvents() []sentEvent {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	return append([]sentEvent(nil), hs.sent...)
+}
+
+func startChannel(t *testing.T, hs *fakeHomeserver, cfg matrixInstanceConfig) (*Channel, *bus.MessageBus) {
+	t.Helper()
+	cfg.Homeserver = hs.srv.URL
+	if cfg.DMPolicy == "" {
+		cfg.DMPolicy = "open"
+	}
+	if cfg.GroupPolicy == "" {
+		cfg.GroupPolicy = "open"
+	}
+	mb := bus.New()
+	ch, err := New(cfg, matrixCreds{AccessToken: "tok"}, mb, nil, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = ch.Stop(context.Background()) })
+	return ch, mb
+}
+
+func consume(t *testing.T, mb *bus.MessageBus) bus.InboundMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msg, ok := mb.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("no inbound message")
+	}
+	return msg
+}
+
+func TestSyncThreadedMentionRepliesInThread(t *testing.T) {
+	hs := newFakeHomeserver(t)
+	ch, mb := startChannel(t, hs, matrixInstanceConfig{})
+
+	hs.syncs <- `{"next_batch":"s2","rooms":{
+		"invite":{"!new:hs.test":{}},
+		"join":{"` + groupID + `":{"timeline":{"events":[
+			{"type":"m.room.message","event_id":"$chat","sender":"@bob:hs.test","content":{"msgtype":"m.text","body":"lunch?"}},
+			{"type":"m.room.message","event_id":"$ask","sender":"@alice:hs.test","content":{
+				"msgtype":"m.text","body":"GoClaw: summarize",
+				"m.mentions":{"user_ids":["` + botID + `"]},
+				"m.relates_to":{"rel_type":"m.thread","event_id":"$root","is_falling_back":true,"m.in_reply_to":{"event_id":"$root"}}}}
+		]}}}}}`
+
+	msg := consume(t, mb)
+	if msg.ChatID != groupID || msg.PeerKind != "group" || msg.SenderID != "@alice:hs.test" {
+		t.Fatalf("inbound = %+v", msg)
+	}
+	if msg.Metadata["local_key"] != groupID+":thread:$root" || msg.Metadata["message_thread_id"] != "$root" {
+		t.Fatalf("thread metadata = %v", msg.Metadata)
+	}
+	if !strings.Contains(msg.Content, "[From: Alice]\nsummarize") || strings.Contains(msg.Content, "lunch?") {
+		t.Fatalf("content = %q", msg.Content)
+	}
+
+	placeholder := hs.sentEvents()[0]
+	rel, _ := placeholder.Content["m.relates_to"].(map[string]any)
+	if placeholder.Content["body"] != "Thinking..." || rel["rel_type"] != "m.thread" || rel["event_id"] != "$root" {
+		t.Fatalf("placeholder = %+v", placeholder)
+	}
+	hs.mu.Lock()
+	joined := append([]string(nil), hs.joined...)
+	hs.mu.Unlock()
+	if len(joined) != 1 || joined[0] != "!new:hs.test" {
+		t.Errorf("joined = %v", joined)
+	}
+
+	err := ch.Send(context.Background(), bus.OutboundMessage{
+		ChatID:   groupID,
+		Content:  "**done**",
+		Metadata: map[string]string{"placeholder_key": msg.Metadata["local_key"], "message_thread_id": "$root"},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	sent := hs.sentEvents()
+	edit := sent[len(sent)-1].Content
+	newContent, _ := edit["m.new_content"].(map[string]any)
+	editRel, _ := edit["m.relates_to"].(map[string]any)
+	if editRel["rel_type"] != "m.replace" || editRel["event_id"] != "$sent1" {
+		t.Fatalf("edit relation = %v", edit)
+	}
+	if newContent["body"] != "**done**" || newContent["formatted_body"] != "<strong>done</strong>" {
+		t.Fatalf("new content = %v", newContent)
+	}
+}
+
+func TestDirectMediaMessageDownloadsFile(t *testing.T) {
+	hs := newFakeHomeserver(t)
+	_, mb := startChannel(t, hs, matrixInstanceConfig{})
+
+	hs.syncs <- `{"next_batch":"s2","rooms":{"join":{"` + dmID + `":{"timeline":{"events":[
+		{"type":"m.room.message","event_id":"$img","sender":"@alice:hs.test","content":{
+			"msgtype":"m.image","body":"what is this?","filename":"cat.png","url":"mxc://hs.test/pic",
+			"info":{"mimetype":"image/png","size":7}}}
+	]}}}}}`
+
+	msg := consume(t, mb)
+	if msg.PeerKind != "direct" || msg.Metadata["local_key"] != dmID {
+		t.Fatalf("inbound = %+v", msg)
+	}
+	if !strings.Contains(msg.Content, "<media:image>") || !strings.Contains(msg.Content, "what is this?") {
+		t.Fatalf("content = %q", msg.Content)
+	}
+	if len(msg.Media) != 1 {
+		t.Fatalf("media = %+v", msg.Media)
+	}
+	defer os.Remove(msg.Media[0].Path)
+	data, err := os.ReadFile(msg.Media[0].Path)
+	if err != nil || string(data) != "PNGDATA" {
+		t.Fatalf("downloaded = %q, %v", data, err)
+	}
+}
+
+func TestStreamReactionsAndMembers(t *testing.T) {
+	hs := newFakeHomeserver(t)
+	ch, _ := startChannel(t, hs, matrixInstanceConfig{ReactionLevel: "full"})
+	ctx := context.Background()
+
+	// No placeholder: the first update posts a message which Send later edits.
+	stream, err := ch.CreateStream(ctx, dmID, true)
+	if err != nil {
+		t.Fatalf("CreateStream: %v", err)
+	}
+	stream.Update(ctx, "partial")
+	ch.FinalizeStream(ctx, dmID, stream)
+	if id, ok := ch.placeholders.Load(dmID); !ok || id != "$sent1" {
+		t.Fatalf("finalized placeholder = %v, %v", id, ok)
+	}
+
+	if err := ch.OnReactionEvent(ctx, dmID, "$ask", "thinking"); err != nil {
+		t.Fatalf("OnReactionEvent: %v", err)
+	}
+	if err := ch.ClearReaction(ctx, dmID, "$ask"); err != nil {
+		t.Fatalf("ClearReaction: %v", err)
+	}
+	sent := hs.sentEvents()
+	reaction := sent[len(sent)-1]
+	rel, _ := reaction.Content["m.relates_to"].(map[string]any)
+	if reaction.Type != "m.reaction" || rel["rel_type"] != "m.annotation" || rel["key"] != "🤔" || rel["event_id"] != "$ask" {
+		t.Fatalf("reaction = %+v", reaction)
+	}
+	hs.mu.Lock()
+	redacted := append([]string(nil), hs.redacted...)
+	hs.mu.Unlock()
+	if len(redacted) != 1 || redacted[0] != "$sent2" {
+		t.Errorf("redacted = %v", redacted)
+	}
+
+	members, err := ch.ListGroupMembers(ctx, groupID+":thread:$root")
+	if err != nil {
+		t.Fatalf("ListGroupMembers: %v", err)
+	}
+	if len(members) != 2 {
+		t.Fatalf("members should exclude the bot: %+v", members)
+	}
+}
+
+func TestFactoryValidatesConfig(t *testing.T) {
+	mb := bus.New()
+	if _, err := Factory("mx", json.RawMessage(`{"access_token":"tok"}`), nil, mb, nil); err == nil {
+		t.Error("missing homeserver should fail")
+	}
+	if _, err := Factory("mx", nil, json.RawMessage(`{"homeserver":"https://hs.test"}`), mb, nil); err == nil {
+		t.Error("missing access_token should fail")
+	}
+	ch, err := Factory("mx", json.RawMessage(`{"access_token":"tok"}`), json.RawMessage(`{"homeserver":"https://hs.test"}`), mb, nil)
+	if err != nil {
+		t.Fatalf("Factory: %v", err)
+	}
+	if ch.Name() != "mx" {
+		t.Errorf("name = %s", ch.Name())
+	}
+}
+
+func TestMarkdownToMatrixHTML(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"**bold** and _it_", "<strong>bold</strong> and <em>it</em>"},
+		{"a < b", "a &lt; b"},
+		{"see [docs](https://x.test/a_b)", `see <a href="https://x.test/a_b">docs</a>`},
+		{"```go\nx := 1 < 2\n```", `<pre><code class="language-go">x := 1 &lt; 2` + "\n" + `</code></pre>`},
+		{"# Title\n- one\n- two", "<h1>Title</h1><ul><li>one</li><li>two</li></ul>"},
+		{"use `a**b**`", "use <code>a**b**</code>"},
+	}
+	for _, tt := range tests {
+		if got := markdownToMatrixHTML(tt.in); got != tt.want {
+			t.Errorf("markdownToMatrixHTML(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
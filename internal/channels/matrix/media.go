@@ -0,0 +1,113 @@
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+)
+
+const defaultMediaMaxBytes int64 = 20 * 1024 * 1024 // 20MB
+
+// downloadMedia fetches an inbound media message into a temp file. The agent
+// loop persists the file into the media store like any other channel upload.
+func (c *Channel) downloadMedia(ctx context.Context, msg messageContent) (media.MediaInfo, error) {
+	if msg.Info != nil && msg.Info.Size > c.mediaMaxBytes {
+		return media.MediaInfo{}, fmt.Errorf("file too large: %d bytes (max %d)", msg.Info.Size, c.mediaMaxBytes)
+	}
+
+	name := msg.FileName
+	if name == "" {
+		name = msg.Body
+	}
+	name = filepath.Base(name)
+	mime := ""
+	if msg.Info != nil {
+		mime = msg.Info.MimeType
+	}
+	if mime == "" {
+		mime = media.DetectMIMEType(name)
+	}
+	ext := filepath.Ext(name)
+	if ext == "" {
+		ext = ".dat"
+	}
+
+	tmp, err := os.CreateTemp("", "matrix-file-*"+ext)
+	if err != nil {
+		return media.MediaInfo{}, fmt.Errorf("create temp file: %w", err)
+	}
+	defer tmp.Close()
+	if err := c.client.download(ctx, msg.URL, c.mediaMaxBytes, tmp); err != nil {
+		os.Remove(tmp.Name())
+		return media.MediaInfo{}, err
+	}
+
+	info := media.MediaInfo{
+		Type:        media.MediaKindFromMime(mime),
+		FilePath:    tmp.Name(),
+		FileID:      msg.URL,
+		ContentType: mime,
+		FileName:    name,
+	}
+	if msg.Info != nil {
+		info.FileSize = msg.Info.Size
+	}
+	return info, nil
+}
+
+// sendMedia uploads a local file to the media repository and posts it.
+func (c *Channel) sendMedia(ctx context.Context, roomID, threadRoot string, att bus.MediaAttachment) error {
+	f, err := os.Open(att.URL)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", att.URL, err)
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(att.URL)
+	mime := att.ContentType
+	if mime == "" {
+		mime = media.DetectMIMEType(name)
+	}
+	uri, err := c.client.upload(ctx, name, mime, f)
+	if err != nil {
+		return fmt.Errorf("upload: %w", err)
+	}
+
+	msgType := "m.file"
+	switch media.MediaKindFromMime(mime) {
+	case media.TypeImage:
+		msgType = "m.image"
+	case media.TypeVideo:
+		msgType = "m.video"
+	case media.TypeAudio:
+		msgType = "m.audio"
+	}
+	content := map[string]any{
+		"msgtype":  msgType,
+		"body":     name,
+		"filename": name,
+		"url":      uri,
+		"info":     mediaInfo{MimeType: mime, Size: stat.Size()},
+	}
+	if att.Caption != "" {
+		content["body"] = att.Caption
+	}
+	if threadRoot != "" {
+		content["m.relates_to"] = relatesTo{
+			RelType:       "m.thread",
+			EventID:       threadRoot,
+			IsFallingBack: true,
+			InReplyTo:     &inReplyTo{EventID: threadRoot},
+		}
+	}
+	_, err = c.client.sendEvent(ctx, roomID, "m.room.message", content)
+	return err
+}
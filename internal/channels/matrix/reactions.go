@@ -0,0 +1,89 @@
+package matrix
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const reactionDebounceInterval = 700 * time.Millisecond
+
+// statusEmoji maps GoClaw agent status to reaction keys.
+var statusEmoji = map[string]string{
+	"thinking": "🤔",
+	"tool":     "🛠️",
+	"done":     "✅",
+	"error":    "❌",
+	"stall":    "⏳",
+}
+
+// reactionState tracks the bot's current status reaction on one message.
+// Matrix reactions are events; removing one means redacting its event ID.
+type reactionState struct {
+	emoji      string
+	eventID    string
+	lastUpdate time.Time
+	mu         sync.Mutex
+}
+
+// OnReactionEvent sets a status reaction (m.annotation) on the user's message.
+func (c *Channel) OnReactionEvent(ctx context.Context, chatID string, messageID string, status string) error {
+	if c.config.ReactionLevel == "" || c.config.ReactionLevel == "off" {
+		return nil
+	}
+	emoji, ok := statusEmoji[status]
+	if !ok {
+		return nil
+	}
+	// For "minimal" level, only show thinking and done
+	if c.config.ReactionLevel == "minimal" && status != "thinking" && status != "done" {
+		return nil
+	}
+
+	roomID := extractRoomID(chatID)
+	stateVal, _ := c.reactions.LoadOrStore(chatID+":"+messageID, &reactionState{})
+	st := stateVal.(*reactionState)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.emoji == emoji || time.Since(st.lastUpdate) < reactionDebounceInterval {
+		return nil
+	}
+	if st.eventID != "" {
+		if err := c.client.redact(ctx, roomID, st.eventID); err != nil {
+			slog.Debug("matrix: remove reaction failed", "emoji", st.emoji, "error", err)
+		}
+	}
+
+	id, err := c.client.sendEvent(ctx, roomID, "m.reaction", map[string]any{
+		"m.relates_to": relatesTo{RelType: "m.annotation", EventID: messageID, Key: emoji},
+	})
+	if err != nil {
+		slog.Debug("matrix: add reaction failed", "emoji", emoji, "error", err)
+		st.emoji, st.eventID = "", ""
+		return nil
+	}
+	st.emoji, st.eventID = emoji, id
+	st.lastUpdate = time.Now()
+	return nil
+}
+
+// ClearReaction redacts the current status reaction from a message.
+func (c *Channel) ClearReaction(ctx context.Context, chatID string, messageID string) error {
+	stateVal, ok := c.reactions.LoadAndDelete(chatID + ":" + messageID)
+	if !ok {
+		return nil
+	}
+	st := stateVal.(*reactionState)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.eventID != "" {
+		if err := c.client.redact(ctx, extractRoomID(chatID), st.eventID); err != nil {
+			slog.Debug("matrix: clear reaction failed", "emoji", st.emoji, "error", err)
+		}
+	}
+	return nil
+}
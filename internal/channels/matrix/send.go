@@ -0,0 +1,148 @@
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+// Send delivers an outbound message to a Matrix room.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("matrix bot not running")
+	}
+	roomID := extractRoomID(msg.ChatID)
+	if roomID == "" {
+		return fmt.Errorf("empty chat ID for matrix send")
+	}
+
+	placeholderKey := msg.ChatID
+	if pk := msg.Metadata["placeholder_key"]; pk != "" {
+		placeholderKey = pk
+	}
+	threadRoot := msg.Metadata["message_thread_id"]
+	if threadRoot == "" {
+		threadRoot = extractThreadRoot(msg.ChatID)
+	}
+
+	// Placeholder update (LLM retry notification)
+	if msg.Metadata["placeholder_update"] == "true" {
+		if id, ok := c.placeholders.Load(placeholderKey); ok {
+			_ = c.editMessage(ctx, roomID, id.(string), msg.Content)
+		}
+		return nil
+	}
+
+	// NO_REPLY: retract placeholder, return
+	if msg.Content == "" && len(msg.Media) == 0 {
+		if id, ok := c.placeholders.LoadAndDelete(placeholderKey); ok {
+			_ = c.client.redact(ctx, roomID, id.(string))
+		}
+		return nil
+	}
+
+	for _, m := range msg.Media {
+		if err := c.sendMedia(ctx, roomID, threadRoot, m); err != nil {
+			slog.Warn("matrix: media upload failed", "file", m.URL, "error", err)
+			_ = c.sendChunked(ctx, roomID, fmt.Sprintf("[File upload failed: %s]", m.URL), threadRoot)
+		}
+	}
+	if msg.Content == "" {
+		if id, ok := c.placeholders.LoadAndDelete(placeholderKey); ok {
+			_ = c.client.redact(ctx, roomID, id.(string))
+		}
+		return nil
+	}
+
+	// Edit placeholder with first chunk, send rest as follow-ups
+	if id, ok := c.placeholders.LoadAndDelete(placeholderKey); ok {
+		first, remaining := splitAtLimit(msg.Content, maxMessageLen)
+		if err := c.editMessage(ctx, roomID, id.(string), first); err == nil {
+			channels.RecordSentMessage(ctx, id.(string))
+			if remaining != "" {
+				return c.sendChunked(ctx, roomID, remaining, threadRoot)
+			}
+			return nil
+		} else {
+			slog.Warn("matrix placeholder edit failed, sending new message", "room_id", roomID, "error", err)
+		}
+	}
+
+	return c.sendChunked(ctx, roomID, msg.Content, threadRoot)
+}
+
+// sendChunked sends markdown-aware chunks as separate messages.
+func (c *Channel) sendChunked(ctx context.Context, roomID, content, threadRoot string) error {
+	for _, chunk := range channels.ChunkMarkdown(content, maxMessageLen) {
+		id, err := c.client.sendEvent(ctx, roomID, "m.room.message", textContent(chunk, threadRoot, ""))
+		if err != nil {
+			return fmt.Errorf("send matrix message: %w", err)
+		}
+		channels.RecordSentMessage(ctx, id)
+	}
+	return nil
+}
+
+// editMessage replaces the content of a previously sent message (m.replace).
+func (c *Channel) editMessage(ctx context.Context, roomID, eventID, text string) error {
+	_, err := c.client.sendEvent(ctx, roomID, "m.room.message", editContent(eventID, text))
+	return err
+}
+
+// textContent builds an m.text event with an HTML rendering of the markdown.
+// threadRoot puts the message in a thread; replyTo (inside a thread) is the
+// event clients without thread support show it as a reply to.
+func textContent(text, threadRoot, replyTo string) map[string]any {
+	content := formattedBody("m.text", text)
+	if threadRoot != "" {
+		if replyTo == "" {
+			replyTo = threadRoot
+		}
+		content["m.relates_to"] = relatesTo{
+			RelType:       "m.thread",
+			EventID:       threadRoot,
+			IsFallingBack: true,
+			InReplyTo:     &inReplyTo{EventID: replyTo},
+		}
+	}
+	return content
+}
+
+// noticeContent builds an m.notice event (bot-originated, clients don't notify).
+func noticeContent(text string) map[string]any {
+	return map[string]any{"msgtype": "m.notice", "body": text}
+}
+
+// editContent builds an m.replace event. The top-level body is the fallback
+// shown by clients that don't understand edits.
+func editContent(eventID, text string) map[string]any {
+	content := formattedBody("m.text", "* "+text)
+	content["m.new_content"] = formattedBody("m.text", text)
+	content["m.relates_to"] = relatesTo{RelType: "m.replace", EventID: eventID}
+	return content
+}
+
+func formattedBody(msgType, text string) map[string]any {
+	content := map[string]any{"msgtype": msgType, "body": text}
+	if html := markdownToMatrixHTML(text); html != "" && html != text {
+		content["format"] = "org.matrix.custom.html"
+		content["formatted_body"] = html
+	}
+	return content
+}
+
+// splitAtLimit splits content into first chunk + remaining using markdown-aware chunking.
+func splitAtLimit(content string, maxLen int) (chunk, remaining string) {
+	chunks := channels.ChunkMarkdown(content, maxLen)
+	if len(chunks) == 0 {
+		return "", ""
+	}
+	if len(chunks) == 1 {
+		return chunks[0], ""
+	}
+	return chunks[0], strings.Join(chunks[1:], "\n")
+}
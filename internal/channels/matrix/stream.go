@@ -0,0 +1,109 @@
+package matrix
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+// streamThrottleInterval bounds edit frequency; every edit is a new room event.
+const streamThrottleInterval = 1500 * time.Millisecond
+
+// matrixStream implements channels.ChannelStream by editing a message (m.replace)
+// as chunks arrive. The first update sends a new message when no placeholder exists.
+type matrixStream struct {
+	client     *client
+	roomID     string
+	threadRoot string
+	eventID    string // message being edited
+	lastUpdate time.Time
+	lastText   string
+	mu         sync.Mutex
+}
+
+// Update edits the streaming message with accumulated text, throttled.
+func (s *matrixStream) Update(ctx context.Context, fullText string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fullText == "" || fullText == s.lastText || time.Since(s.lastUpdate) < streamThrottleInterval {
+		return
+	}
+	text := fullText
+	if len(text) > maxMessageLen {
+		text = text[:maxMessageLen] + "..."
+	}
+
+	if s.eventID == "" {
+		id, err := s.client.sendEvent(ctx, s.roomID, "m.room.message", textContent(text, s.threadRoot, ""))
+		if err != nil {
+			slog.Debug("matrix stream send failed", "error", err)
+			return
+		}
+		s.eventID = id
+	} else if _, err := s.client.sendEvent(ctx, s.roomID, "m.room.message", editContent(s.eventID, text)); err != nil {
+		slog.Debug("matrix stream edit failed", "error", err)
+		return
+	}
+	s.lastText = fullText
+	s.lastUpdate = time.Now()
+}
+
+// Stop is a no-op: Send() makes the final edit via the placeholder map, which
+// FinalizeStream populates with this stream's event ID.
+func (s *matrixStream) Stop(_ context.Context) error {
+	return nil
+}
+
+// MessageID returns 0 — Matrix event IDs are strings.
+// FinalizeStream hands the event ID off via type assertion instead.
+func (s *matrixStream) MessageID() int {
+	return 0
+}
+
+// EventID returns the Matrix event ID being edited, or "" before the first send.
+func (s *matrixStream) EventID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.eventID
+}
+
+// StreamEnabled reports whether streaming is active for DMs or rooms.
+func (c *Channel) StreamEnabled(isGroup bool) bool {
+	if isGroup {
+		return c.config.GroupStream != nil && *c.config.GroupStream
+	}
+	return c.config.DMStream != nil && *c.config.DMStream
+}
+
+// CreateStream creates a per-run streaming handle for the given chatID (local_key).
+// The "Thinking..." placeholder sent in handleMessage, if any, becomes the edited message.
+func (c *Channel) CreateStream(_ context.Context, chatID string, _ bool) (channels.ChannelStream, error) {
+	s := &matrixStream{
+		client:     c.client,
+		roomID:     extractRoomID(chatID),
+		threadRoot: extractThreadRoot(chatID),
+	}
+	if id, ok := c.placeholders.Load(chatID); ok {
+		s.eventID = id.(string)
+	}
+	return s, nil
+}
+
+// FinalizeStream stores the stream's event ID back into c.placeholders so that
+// Send() edits it with the final response.
+func (c *Channel) FinalizeStream(_ context.Context, chatID string, stream channels.ChannelStream) {
+	ms, ok := stream.(*matrixStream)
+	if !ok {
+		return
+	}
+	if id := ms.EventID(); id != "" {
+		c.placeholders.Store(chatID, id)
+	}
+}
+
+// ReasoningStreamEnabled returns false — reasoning is not shown as a separate message.
+func (c *Channel) ReasoningStreamEnabled() bool { return false }
@@ -0,0 +1,214 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+)
+
+// BlockKind identifies a top-level markdown block.
+type BlockKind int
+
+const (
+	// BlockText is ordinary markdown (paragraphs, lists, headings, quotes).
+	// Its inline formatting is left to the channel's own formatter.
+	BlockText BlockKind = iota
+	// BlockCode is a fenced code block.
+	BlockCode
+	// BlockTable is a GitHub-style pipe table.
+	BlockTable
+	// BlockMath is display math ($$…$$ or \[…\]).
+	BlockMath
+)
+
+// Align is a table column alignment from the separator row.
+type Align int
+
+const (
+	AlignNone Align = iota
+	AlignLeft
+	AlignCenter
+	AlignRight
+)
+
+// Table is a parsed pipe table. Cells keep their inline markdown.
+type Table struct {
+	Header []string
+	Align  []Align
+	Rows   [][]string
+}
+
+// Block is one top-level block of a Document.
+type Block struct {
+	Kind BlockKind
+	// Raw is the block's source lines, emitted unchanged when the channel
+	// renders the block natively.
+	Raw string
+	// Lang is the info string of a code block.
+	Lang string
+	// Body is the code of a code block or the TeX source of a math block.
+	Body  string
+	Table *Table
+}
+
+// Document is agent markdown split into blocks. Joining the blocks' Raw
+// with "\n" reproduces the source exactly.
+type Document struct {
+	Blocks []Block
+}
+
+var (
+	reTableSep = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	reFence    = regexp.MustCompile("^\\s*(`{3,}|~{3,})\\s*([^`\\s]*)")
+)
+
+// Parse splits markdown into text, code, table and display-math blocks.
+func Parse(markdown string) *Document {
+	lines := strings.Split(markdown, "\n")
+	doc := &Document{}
+	var text []string
+	flush := func() {
+		if len(text) > 0 {
+			doc.Blocks = append(doc.Blocks, Block{Kind: BlockText, Raw: strings.Join(text, "\n")})
+			text = nil
+		}
+	}
+	for i := 0; i < len(lines); {
+		var b Block
+		next := 0
+		switch {
+		case reFence.MatchString(lines[i]):
+			b, next = parseFence(lines, i)
+		case i+1 < len(lines) && strings.Contains(lines[i], "|") && reTableSep.MatchString(lines[i+1]):
+			b, next = parseTable(lines, i)
+		default:
+			b, next = parseDisplayMath(lines, i)
+		}
+		if next == 0 {
+			text = append(text, lines[i])
+			i++
+			continue
+		}
+		flush()
+		doc.Blocks = append(doc.Blocks, b)
+		i = next
+	}
+	flush()
+	return doc
+}
+
+// parseFence reads a fenced code block starting at lines[start]. An
+// unclosed fence runs to the end of the text, as in CommonMark.
+func parseFence(lines []string, start int) (Block, int) {
+	m := reFence.FindStringSubmatch(lines[start])
+	fence := m[1]
+	body, end := lines[start+1:], len(lines)
+	for j := start + 1; j < len(lines); j++ {
+		t := strings.TrimSpace(lines[j])
+		if strings.HasPrefix(t, fence) && strings.Trim(t, fence[:1]) == "" {
+			body, end = lines[start+1:j], j+1
+			break
+		}
+	}
+	return Block{
+		Kind: BlockCode,
+		Raw:  strings.Join(lines[start:end], "\n"),
+		Lang: m[2],
+		Body: strings.Join(body, "\n"),
+	}, end
+}
+
+// parseTable reads a pipe table whose header is lines[start]. It returns
+// next == 0 when the header and separator disagree on the column count.
+func parseTable(lines []string, start int) (Block, int) {
+	header := splitRow(lines[start])
+	sep := splitRow(lines[start+1])
+	if len(header) != len(sep) {
+		return Block{}, 0
+	}
+	t := &Table{Header: header, Align: make([]Align, len(sep))}
+	for i, s := range sep {
+		left, right := strings.HasPrefix(s, ":"), strings.HasSuffix(s, ":")
+		switch {
+		case left && right:
+			t.Align[i] = AlignCenter
+		case right:
+			t.Align[i] = AlignRight
+		case left:
+			t.Align[i] = AlignLeft
+		}
+	}
+	end := start + 2
+	for ; end < len(lines) && strings.Contains(lines[end], "|") && strings.TrimSpace(lines[end]) != ""; end++ {
+		t.Rows = append(t.Rows, splitRow(lines[end]))
+	}
+	return Block{Kind: BlockTable, Raw: strings.Join(lines[start:end], "\n"), Table: t}, end
+}
+
+// splitRow splits a table row on unescaped pipes outside code spans.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case c == '`':
+			inCode = !inCode
+			cell.WriteByte(c)
+		case c == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(c)
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// parseDisplayMath reads a $$…$$ or \[…\] block starting at lines[start].
+// Unclosed delimiters are left as text.
+func parseDisplayMath(lines []string, start int) (Block, int) {
+	first := strings.TrimSpace(lines[start])
+	var open, closing string
+	switch {
+	case strings.HasPrefix(first, "$$"):
+		open, closing = "$$", "$$"
+	case strings.HasPrefix(first, `\[`):
+		open, closing = `\[`, `\]`
+	default:
+		return Block{}, 0
+	}
+	rest := strings.TrimPrefix(first, open)
+	if strings.HasSuffix(rest, closing) {
+		body := strings.TrimSuffix(rest, closing)
+		if strings.TrimSpace(body) == "" {
+			return Block{}, 0
+		}
+		return Block{Kind: BlockMath, Raw: lines[start], Body: strings.TrimSpace(body)}, start + 1
+	}
+	if strings.Contains(rest, closing) {
+		return Block{}, 0 // inline math followed by text, e.g. "$$a$$ and b"
+	}
+	body := []string{rest}
+	for j := start + 1; j < len(lines); j++ {
+		t := strings.TrimSpace(lines[j])
+		if strings.HasSuffix(t, closing) {
+			body = append(body, strings.TrimSuffix(t, closing))
+			return Block{
+				Kind: BlockMath,
+				Raw:  strings.Join(lines[start:j+1], "\n"),
+				Body: strings.TrimSpace(strings.Join(body, "\n")),
+			}, j + 1
+		}
+		body = append(body, lines[j])
+	}
+	return Block{}, 0
+}
@@ -0,0 +1,67 @@
+package render
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseRoundTrips(t *testing.T) {
+	inputs := []string{
+		"",
+		"plain\n\ntext\n",
+		"a\n```go\nx := 1\n```\nb",
+		"| a | b |\n|---|--:|\n| 1 | 2 |\nafter",
+		"$$\nx^2\n$$",
+		"```\nunclosed\nfence",
+		"$$ unclosed\nmath",
+	}
+	for _, in := range inputs {
+		doc := Parse(in)
+		raws := make([]string, len(doc.Blocks))
+		for i, b := range doc.Blocks {
+			raws[i] = b.Raw
+		}
+		if got := strings.Join(raws, "\n"); got != in {
+			t.Errorf("Parse(%q) round trip = %q", in, got)
+		}
+	}
+}
+
+func TestParseBlocks(t *testing.T) {
+	doc := Parse("intro\n```py\nprint(1)\n```\n| h1 | h2 |\n|:--|:-:|\n| `a|b` | c \\| d |\n\\[ E = mc^2 \\]\n~~~\nraw\n~~~")
+	var kinds []BlockKind
+	for _, b := range doc.Blocks {
+		kinds = append(kinds, b.Kind)
+	}
+	want := []BlockKind{BlockText, BlockCode, BlockTable, BlockMath, BlockCode}
+	if !slices.Equal(kinds, want) {
+		t.Fatalf("kinds = %v, want %v", kinds, want)
+	}
+	if b := doc.Blocks[1]; b.Lang != "py" || b.Body != "print(1)" {
+		t.Errorf("code = %q %q", b.Lang, b.Body)
+	}
+	tbl := doc.Blocks[2].Table
+	if !slices.Equal(tbl.Align, []Align{AlignLeft, AlignCenter}) {
+		t.Errorf("align = %v", tbl.Align)
+	}
+	if !slices.Equal(tbl.Rows[0], []string{"`a|b`", "c | d"}) {
+		t.Errorf("row = %q, pipes in code spans and escaped pipes must not split cells", tbl.Rows[0])
+	}
+	if doc.Blocks[3].Body != "E = mc^2" {
+		t.Errorf("math = %q", doc.Blocks[3].Body)
+	}
+}
+
+func TestParseIgnoresNonTables(t *testing.T) {
+	for _, in := range []string{
+		"a | b\n---",           // column count mismatch: setext heading
+		"use a|b pipes\nplain", // no separator row
+	} {
+		for _, b := range Parse(in).Blocks {
+			if b.Kind != BlockText {
+				t.Errorf("Parse(%q) produced block kind %v", in, b.Kind)
+			}
+		}
+	}
+}
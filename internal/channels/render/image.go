@@ -0,0 +1,203 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Images use the embedded Go fonts, so rendering needs no system fonts.
+// They cover Latin, Greek, Cyrillic and common symbols; text with other
+// scripts (CJK, Vietnamese tones, emoji) falls back to the text form
+// rather than rendering missing-glyph boxes.
+
+const (
+	tableFontSize = 22
+	mathFontSize  = 32
+	imagePadding  = 16
+	// maxImageSide keeps images within what chat apps accept without
+	// resizing them into illegibility.
+	maxImageSide = 4096
+)
+
+var (
+	inkColor    = color.Gray{Y: 0x20}
+	ruleColor   = color.Gray{Y: 0xc8}
+	headerColor = color.Gray{Y: 0xf0}
+)
+
+type fontID int
+
+const (
+	fontRegular fontID = iota
+	fontMono
+	fontMonoBold
+)
+
+var fontData = map[fontID][]byte{
+	fontRegular:  goregular.TTF,
+	fontMono:     gomono.TTF,
+	fontMonoBold: gomonobold.TTF,
+}
+
+var (
+	fontsMu sync.Mutex
+	fonts   = map[fontID]*opentype.Font{}
+)
+
+type faceKey struct {
+	font fontID
+	size int
+}
+
+// faceSet caches faces for one image. Faces keep per-face buffers and are
+// not safe for concurrent use, so they are not shared between renders.
+type faceSet map[faceKey]font.Face
+
+// get returns the face for id at size, rounded to whole pixels.
+func (fs faceSet) get(id fontID, size float64) font.Face {
+	key := faceKey{id, max(int(size+0.5), 6)}
+	if f, ok := fs[key]; ok {
+		return f
+	}
+	fontsMu.Lock()
+	f, ok := fonts[id]
+	if !ok {
+		// The embedded fonts are known-good; a parse error is a build defect.
+		var err error
+		if f, err = opentype.Parse(fontData[id]); err != nil {
+			fontsMu.Unlock()
+			panic("render: parse embedded font: " + err.Error())
+		}
+		fonts[id] = f
+	}
+	fontsMu.Unlock()
+	fc, err := opentype.NewFace(f, &opentype.FaceOptions{Size: float64(key.size), DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		panic("render: new face: " + err.Error())
+	}
+	fs[key] = fc
+	return fc
+}
+
+// covers reports whether f has a glyph for every printable rune of s.
+func covers(f font.Face, s string) bool {
+	for _, r := range s {
+		if r == ' ' || r == '\n' || r == '\t' {
+			continue
+		}
+		if _, ok := f.GlyphAdvance(r); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func drawText(dst draw.Image, f font.Face, s string, x, baseline int) {
+	d := &font.Drawer{Dst: dst, Src: image.NewUniform(inkColor), Face: f, Dot: fixed.P(x, baseline)}
+	d.DrawString(s)
+}
+
+func fillRect(dst draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(dst, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// newCanvas returns a white image, or nil when w×h is too large.
+func newCanvas(w, h int) *image.RGBA {
+	if w <= 0 || h <= 0 || w > maxImageSide || h > maxImageSide {
+		return nil
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	fillRect(img, img.Bounds(), color.White)
+	return img
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// tablePNG draws t as a grid with a shaded header row. It reports false
+// when the font lacks a glyph or the image would be too large.
+func tablePNG(t *Table) (image.Image, bool) {
+	faces := faceSet{}
+	body, bold := faces.get(fontMono, tableFontSize), faces.get(fontMonoBold, tableFontSize)
+	const padX, padY = 12, 8
+	m := body.Metrics()
+	ascent, rowH := m.Ascent.Ceil(), m.Ascent.Ceil()+m.Descent.Ceil()+2*padY
+
+	rows := append([][]string{t.Header}, t.Rows...)
+	widths := make([]int, len(t.Header))
+	for ri, row := range rows {
+		f := body
+		if ri == 0 {
+			f = bold
+		}
+		for i := range widths {
+			c := cell(row, i)
+			if !covers(f, c) {
+				return nil, false
+			}
+			widths[i] = max(widths[i], font.MeasureString(f, c).Ceil())
+		}
+	}
+	w := 1
+	for _, cw := range widths {
+		w += cw + 2*padX + 1
+	}
+	h := len(rows)*(rowH+1) + 1
+	img := newCanvas(w+2*imagePadding, h+2*imagePadding)
+	if img == nil {
+		return nil, false
+	}
+
+	x0, y0 := imagePadding, imagePadding
+	fillRect(img, image.Rect(x0, y0, x0+w, y0+rowH+1), headerColor)
+	for ri, row := range rows {
+		f := body
+		if ri == 0 {
+			f = bold
+		}
+		top := y0 + ri*(rowH+1)
+		fillRect(img, image.Rect(x0, top, x0+w, top+1), ruleColor)
+		x := x0 + 1
+		for i, cw := range widths {
+			c := cell(row, i)
+			tw := font.MeasureString(f, c).Ceil()
+			tx := x + padX
+			switch t.Align[i] {
+			case AlignRight:
+				tx += cw - tw
+			case AlignCenter:
+				tx += (cw - tw) / 2
+			}
+			drawText(img, f, c, tx, top+1+padY+ascent)
+			x += cw + 2*padX + 1
+		}
+	}
+	fillRect(img, image.Rect(x0, y0+h-1, x0+w, y0+h), ruleColor)
+	x := x0
+	fillRect(img, image.Rect(x, y0, x+1, y0+h), ruleColor)
+	for _, cw := range widths {
+		x += cw + 2*padX + 1
+		fillRect(img, image.Rect(x, y0, x+1, y0+h), ruleColor)
+	}
+	return img, true
+}
@@ -0,0 +1,371 @@
+package render
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A TeX subset parsed into a small tree, which is either flattened to
+// Unicode text (MathText) or laid out and rasterized (MathImage). Unknown
+// commands degrade to their name so nothing is silently dropped.
+
+type mathNode interface{ isMath() }
+
+type (
+	// mathText is a run of symbols, already mapped to Unicode.
+	mathText string
+	mathRow  []mathNode
+	// mathScript is a base with an optional superscript and subscript.
+	mathScript struct{ base, sup, sub mathNode }
+	mathFrac   struct{ num, den mathNode }
+	// mathSqrt is a radical; index is nil for a square root.
+	mathSqrt struct{ index, body mathNode }
+)
+
+func (mathText) isMath()   {}
+func (mathRow) isMath()    {}
+func (mathScript) isMath() {}
+func (mathFrac) isMath()   {}
+func (mathSqrt) isMath()   {}
+
+var mathSymbols = map[string]string{
+	// Greek
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ε", "varepsilon": "ε",
+	"zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ",
+	"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "pi": "π", "varpi": "ϖ", "rho": "ρ",
+	"sigma": "σ", "varsigma": "ς", "tau": "τ", "upsilon": "υ", "phi": "φ", "varphi": "φ",
+	"chi": "χ", "psi": "ψ", "omega": "ω",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+	"Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+	// Operators and relations
+	"cdot": "·", "times": "×", "div": "÷", "pm": "±", "mp": "∓", "ast": "∗",
+	"leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠", "approx": "≈",
+	"equiv": "≡", "sim": "∼", "simeq": "≃", "propto": "∝", "ll": "≪", "gg": "≫",
+	"sum": "∑", "prod": "∏", "int": "∫", "iint": "∬", "oint": "∮", "partial": "∂",
+	"nabla": "∇", "infty": "∞", "circ": "∘", "bullet": "•", "degree": "°",
+	"in": "∈", "notin": "∉", "ni": "∋", "subset": "⊂", "subseteq": "⊆", "supset": "⊃",
+	"supseteq": "⊇", "cup": "∪", "cap": "∩", "emptyset": "∅", "varnothing": "∅",
+	"forall": "∀", "exists": "∃", "neg": "¬", "lnot": "¬", "land": "∧", "wedge": "∧",
+	"lor": "∨", "vee": "∨", "oplus": "⊕", "otimes": "⊗", "perp": "⊥", "parallel": "∥",
+	"angle": "∠", "triangle": "△", "therefore": "∴", "because": "∵",
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "gets": "←", "leftrightarrow": "↔",
+	"Rightarrow": "⇒", "implies": "⇒", "Leftarrow": "⇐", "Leftrightarrow": "⇔", "iff": "⇔",
+	"mapsto": "↦", "uparrow": "↑", "downarrow": "↓",
+	"ldots": "…", "cdots": "⋯", "dots": "…", "vdots": "⋮", "ddots": "⋱",
+	"prime": "′", "hbar": "ℏ", "ell": "ℓ", "Re": "ℜ", "Im": "ℑ", "aleph": "ℵ",
+	"langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈", "rceil": "⌉",
+	"mid": "|", "vert": "|", "Vert": "‖", "lbrace": "{", "rbrace": "}", "backslash": "\\",
+	// Spacing
+	"quad": " ", "qquad": " ", ",": " ", ":": " ", ";": " ", " ": " ", "!": "",
+	// Escapes
+	"{": "{", "}": "}", "%": "%", "$": "$", "&": "&", "#": "#", "_": "_",
+}
+
+// mathBlackboard maps \mathbb letters.
+var mathBlackboard = map[rune]string{
+	'R': "ℝ", 'N': "ℕ", 'Z': "ℤ", 'Q': "ℚ", 'C': "ℂ", 'P': "ℙ", 'H': "ℍ",
+}
+
+var (
+	superMap = map[rune]rune{
+		'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶', '7': '⁷', '8': '⁸', '9': '⁹',
+		'+': '⁺', '-': '⁻', '−': '⁻', '=': '⁼', '(': '⁽', ')': '⁾', 'n': 'ⁿ', 'i': 'ⁱ',
+		'a': 'ᵃ', 'b': 'ᵇ', 'c': 'ᶜ', 'd': 'ᵈ', 'e': 'ᵉ', 'k': 'ᵏ', 'm': 'ᵐ', 'x': 'ˣ', 'y': 'ʸ', 'T': 'ᵀ',
+		'′': '′',
+	}
+	subMap = map[rune]rune{
+		'0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄', '5': '₅', '6': '₆', '7': '₇', '8': '₈', '9': '₉',
+		'+': '₊', '-': '₋', '−': '₋', '=': '₌', '(': '₍', ')': '₎',
+		'a': 'ₐ', 'e': 'ₑ', 'i': 'ᵢ', 'j': 'ⱼ', 'k': 'ₖ', 'n': 'ₙ', 'o': 'ₒ', 'r': 'ᵣ', 't': 'ₜ', 'x': 'ₓ',
+	}
+)
+
+// parseMath parses a TeX math expression.
+func parseMath(src string) mathNode {
+	p := &mathParser{src: src}
+	return p.row(false)
+}
+
+type mathParser struct {
+	src string
+	pos int
+}
+
+func (p *mathParser) eof() bool { return p.pos >= len(p.src) }
+
+// row parses nodes until the end of input or, inside a group, the closing brace.
+func (p *mathParser) row(inGroup bool) mathRow {
+	var row mathRow
+	for !p.eof() {
+		c := p.src[p.pos]
+		switch {
+		case c == '}':
+			p.pos++
+			if inGroup {
+				return row
+			}
+		case c == '^' || c == '_':
+			p.pos++
+			s := mathScript{base: mathText("")}
+			if n := len(row); n > 0 && row[n-1] != mathText(" ") {
+				s.base = row[n-1]
+				row = row[:n-1]
+				// x_i^2: both scripts attach to the same base.
+				if prev, ok := s.base.(mathScript); ok && (c == '^' && prev.sup == nil || c == '_' && prev.sub == nil) {
+					s = prev
+				}
+			}
+			if c == '^' {
+				s.sup = p.arg()
+			} else {
+				s.sub = p.arg()
+			}
+			row = append(row, s)
+		default:
+			if n := p.atom(); n != nil {
+				row = append(row, n)
+			}
+		}
+	}
+	return row
+}
+
+// arg parses one command argument: a braced group or a single token.
+func (p *mathParser) arg() mathNode {
+	p.skipSpace()
+	if p.eof() {
+		return mathText("")
+	}
+	if p.src[p.pos] == '{' {
+		p.pos++
+		return p.row(true)
+	}
+	if n := p.atom(); n != nil {
+		return n
+	}
+	return mathText("")
+}
+
+// rawArg returns the source of a braced argument, for \text and friends.
+func (p *mathParser) rawArg() string {
+	p.skipSpace()
+	if p.eof() {
+		return ""
+	}
+	if p.src[p.pos] != '{' {
+		_, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		p.pos += size
+		return p.src[p.pos-size : p.pos]
+	}
+	depth, start := 0, p.pos+1
+	for ; !p.eof(); p.pos++ {
+		switch p.src[p.pos] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return p.src[start : p.pos-1]
+			}
+		}
+	}
+	return p.src[start:]
+}
+
+func (p *mathParser) skipSpace() {
+	for !p.eof() && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t' || p.src[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+// atom parses one token. It returns nil for tokens that produce nothing.
+func (p *mathParser) atom() mathNode {
+	c := p.src[p.pos]
+	switch {
+	case c == '{':
+		p.pos++
+		return p.row(true)
+	case c == '\\':
+		return p.command()
+	case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		p.skipSpace()
+		return mathText(" ")
+	case c == '&':
+		p.pos++
+		return nil
+	case c == '-':
+		p.pos++
+		return mathText("−")
+	case c == '*':
+		p.pos++
+		return mathText("∗")
+	case c == '\'':
+		p.pos++
+		return mathText("′")
+	}
+	r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+	p.pos += size
+	return mathText(string(r))
+}
+
+// command parses a control sequence after the backslash.
+func (p *mathParser) command() mathNode {
+	p.pos++ // backslash
+	if p.eof() {
+		return mathText("\\")
+	}
+	start := p.pos
+	for !p.eof() && isASCIILetter(p.src[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start { // control symbol such as \, or \{
+		_, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		p.pos += size
+	}
+	name := p.src[start:p.pos]
+
+	switch name {
+	case "\\":
+		return mathText("\n")
+	case "frac", "dfrac", "tfrac", "cfrac":
+		num := p.arg()
+		return mathFrac{num: num, den: p.arg()}
+	case "binom":
+		n := p.arg()
+		return mathRow{mathText("("), n, mathText(" choose "), p.arg(), mathText(")")}
+	case "sqrt":
+		var index mathNode
+		if p.skipSpace(); !p.eof() && p.src[p.pos] == '[' {
+			end := strings.IndexByte(p.src[p.pos:], ']')
+			if end > 0 {
+				index = parseMath(p.src[p.pos+1 : p.pos+end])
+				p.pos += end + 1
+			}
+		}
+		return mathSqrt{index: index, body: p.arg()}
+	case "text", "textrm", "textit", "textbf", "mbox":
+		return mathText(p.rawArg())
+	case "mathrm", "mathit", "mathbf", "mathsf", "mathtt", "operatorname":
+		return p.arg()
+	case "mathbb":
+		arg := p.rawArg()
+		var b strings.Builder
+		for _, r := range arg {
+			if s, ok := mathBlackboard[r]; ok {
+				b.WriteString(s)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+		return mathText(b.String())
+	case "left", "right", "big", "Big", "bigg", "Bigg", "bigl", "bigr", "Bigl", "Bigr":
+		p.skipSpace()
+		if !p.eof() && p.src[p.pos] == '.' {
+			p.pos++
+			return nil
+		}
+		return nil // the delimiter that follows is parsed as a normal token
+	case "begin", "end":
+		p.rawArg()
+		return nil
+	case "displaystyle", "textstyle", "limits", "nolimits":
+		return nil
+	case "vec":
+		return mathRow{p.arg(), mathText("⃗")}
+	case "hat":
+		return mathRow{p.arg(), mathText("̂")}
+	case "bar", "overline":
+		return mathRow{p.arg(), mathText("̅")}
+	case "dot":
+		return mathRow{p.arg(), mathText("̇")}
+	case "tilde":
+		return mathRow{p.arg(), mathText("̃")}
+	}
+	if s, ok := mathSymbols[name]; ok {
+		return mathText(s)
+	}
+	// Operator names (\sin, \log, \lim) and unknown commands print as their name.
+	return mathText(name)
+}
+
+func isASCIILetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+
+// MathToText renders a TeX expression as plain Unicode, e.g.
+// "\frac{a}{b}^2" → "(a/b)²". Used where images are not available.
+func MathToText(tex string) string {
+	lines := strings.Split(flatten(parseMath(tex)), "\n")
+	for i, l := range lines {
+		lines[i] = strings.Join(strings.Fields(l), " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func flatten(n mathNode) string {
+	switch n := n.(type) {
+	case nil:
+		return ""
+	case mathText:
+		return string(n)
+	case mathRow:
+		var b strings.Builder
+		for _, k := range n {
+			b.WriteString(flatten(k))
+		}
+		return b.String()
+	case mathScript:
+		s := flatten(n.base)
+		if _, isFrac := n.base.(mathFrac); isFrac {
+			s = "(" + s + ")"
+		}
+		if n.sub != nil {
+			s += script(flatten(n.sub), subMap, "_")
+		}
+		if n.sup != nil {
+			s += script(flatten(n.sup), superMap, "^")
+		}
+		return s
+	case mathFrac:
+		return group(flatten(n.num)) + "/" + group(flatten(n.den))
+	case mathSqrt:
+		s := "√" + group(flatten(n.body))
+		if n.index != nil {
+			s = script(flatten(n.index), superMap, "") + s
+		}
+		return s
+	}
+	return ""
+}
+
+// script maps s to Unicode super/subscript characters, falling back to
+// "^(s)" when a character has no scripted form.
+func script(s string, table map[rune]rune, marker string) string {
+	s = strings.TrimSpace(s)
+	var b strings.Builder
+	for _, r := range s {
+		m, ok := table[r]
+		if !ok {
+			if utf8.RuneCountInString(s) > 1 {
+				s = "(" + s + ")"
+			}
+			return marker + s
+		}
+		b.WriteRune(m)
+	}
+	return b.String()
+}
+
+// group parenthesizes s unless it is a single symbol or a plain number or word.
+func group(s string) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= 1 {
+		return s
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '.' {
+			return "(" + s + ")"
+		}
+	}
+	return s
+}
@@ -0,0 +1,233 @@
+package render
+
+import (
+	"image"
+	"image/draw"
+	"strings"
+
+	"golang.org/x/image/font"
+)
+
+// mathBox is a laid-out math node: its width and extent above and below the
+// baseline, and a function drawing it with its baseline at (x, y).
+type mathBox struct {
+	w, ascent, descent int
+	draw               func(dst draw.Image, x, y int)
+}
+
+// mathLayout lays out a math tree. ok turns false when a glyph is missing.
+type mathLayout struct {
+	faces faceSet
+	ok    bool
+}
+
+// mathPNG renders a display formula. Lines split by \\ are stacked and
+// centered. It reports false when the font lacks a glyph or the image would
+// be too large.
+func mathPNG(tex string) (image.Image, bool) {
+	l := &mathLayout{faces: faceSet{}, ok: true}
+	var lines []mathBox
+	for _, row := range splitMathLines(parseMath(tex)) {
+		lines = append(lines, l.layout(row, mathFontSize))
+	}
+	if !l.ok || len(lines) == 0 {
+		return nil, false
+	}
+	gap := mathFontSize / 3
+	w, h := 0, gap*(len(lines)-1)
+	for _, b := range lines {
+		w = max(w, b.w)
+		h += b.ascent + b.descent
+	}
+	img := newCanvas(w+2*imagePadding, h+2*imagePadding)
+	if img == nil {
+		return nil, false
+	}
+	y := imagePadding
+	for _, b := range lines {
+		b.draw(img, imagePadding+(w-b.w)/2, y+b.ascent)
+		y += b.ascent + b.descent + gap
+	}
+	return img, true
+}
+
+// splitMathLines splits the top-level row on \\ line breaks.
+func splitMathLines(n mathNode) []mathRow {
+	row, ok := n.(mathRow)
+	if !ok {
+		return []mathRow{{n}}
+	}
+	var lines []mathRow
+	var cur mathRow
+	for _, k := range row {
+		if k == mathText("\n") {
+			lines = append(lines, cur)
+			cur = nil
+			continue
+		}
+		cur = append(cur, k)
+	}
+	lines = append(lines, cur)
+	out := lines[:0]
+	for _, l := range lines {
+		if len(l) > 0 {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func (l *mathLayout) layout(n mathNode, size float64) mathBox {
+	switch n := n.(type) {
+	case mathText:
+		return l.text(strings.TrimSuffix(string(n), "\n"), size)
+	case mathRow:
+		return l.row(n, size)
+	case mathScript:
+		return l.script(n, size)
+	case mathFrac:
+		return l.frac(n, size)
+	case mathSqrt:
+		return l.sqrt(n, size)
+	}
+	return mathBox{draw: func(draw.Image, int, int) {}}
+}
+
+func (l *mathLayout) text(s string, size float64) mathBox {
+	f := l.faces.get(fontRegular, size)
+	if !covers(f, s) {
+		l.ok = false
+	}
+	m := f.Metrics()
+	return mathBox{
+		w:       font.MeasureString(f, s).Ceil(),
+		ascent:  m.Ascent.Ceil(),
+		descent: m.Descent.Ceil(),
+		draw: func(dst draw.Image, x, y int) {
+			drawText(dst, f, s, x, y)
+		},
+	}
+}
+
+func (l *mathLayout) row(row mathRow, size float64) mathBox {
+	boxes := make([]mathBox, len(row))
+	var b mathBox
+	for i, k := range row {
+		boxes[i] = l.layout(k, size)
+		b.w += boxes[i].w
+		b.ascent = max(b.ascent, boxes[i].ascent)
+		b.descent = max(b.descent, boxes[i].descent)
+	}
+	b.draw = func(dst draw.Image, x, y int) {
+		for _, k := range boxes {
+			k.draw(dst, x, y)
+			x += k.w
+		}
+	}
+	return b
+}
+
+// script sets scripts at 70% size, raised or lowered from the baseline.
+func (l *mathLayout) script(n mathScript, size float64) mathBox {
+	base := l.layout(n.base, size)
+	b := base
+	supShift, subShift := int(size*0.42), int(size*0.22)
+	var sup, sub *mathBox
+	if n.sup != nil {
+		s := l.layout(n.sup, size*0.7)
+		sup = &s
+		b.ascent = max(b.ascent, supShift+s.ascent)
+	}
+	if n.sub != nil {
+		s := l.layout(n.sub, size*0.7)
+		sub = &s
+		b.descent = max(b.descent, subShift+s.descent)
+	}
+	scriptW := 0
+	if sup != nil {
+		scriptW = sup.w
+	}
+	if sub != nil {
+		scriptW = max(scriptW, sub.w)
+	}
+	b.w = base.w + scriptW + int(size*0.05)
+	b.draw = func(dst draw.Image, x, y int) {
+		base.draw(dst, x, y)
+		if sup != nil {
+			sup.draw(dst, x+base.w, y-supShift)
+		}
+		if sub != nil {
+			sub.draw(dst, x+base.w, y+subShift)
+		}
+	}
+	return b
+}
+
+// frac stacks the numerator over the denominator around a rule on the math
+// axis, roughly the height of a minus sign.
+func (l *mathLayout) frac(n mathFrac, size float64) mathBox {
+	num, den := l.layout(n.num, size*0.85), l.layout(n.den, size*0.85)
+	axis, gap, thick, pad := int(size*0.28), max(int(size*0.12), 2), max(int(size/16), 1), int(size*0.15)
+	w := max(num.w, den.w) + 2*pad
+	return mathBox{
+		w:       w,
+		ascent:  axis + gap + num.ascent + num.descent,
+		descent: max(den.ascent+den.descent+gap+thick-axis, 0),
+		draw: func(dst draw.Image, x, y int) {
+			num.draw(dst, x+(w-num.w)/2, y-axis-gap-num.descent)
+			fillRect(dst, image.Rect(x+pad/2, y-axis, x+w-pad/2, y-axis+thick), inkColor)
+			den.draw(dst, x+(w-den.w)/2, y-axis+thick+gap+den.ascent)
+		},
+	}
+}
+
+// sqrt draws a radical sign over the body, with an optional small index.
+func (l *mathLayout) sqrt(n mathSqrt, size float64) mathBox {
+	body := l.layout(n.body, size)
+	gap, thick := max(int(size*0.1), 2), max(int(size/16), 1)
+	signW := int(size * 0.55)
+	var index *mathBox
+	indent := 0
+	if n.index != nil {
+		ib := l.layout(n.index, size*0.55)
+		index = &ib
+		indent = max(ib.w-int(float64(signW)*0.4), 0)
+	}
+	w := indent + signW + body.w + int(size*0.1)
+	ascent := body.ascent + gap + thick
+	return mathBox{
+		w:       w,
+		ascent:  ascent,
+		descent: body.descent,
+		draw: func(dst draw.Image, x, y int) {
+			x += indent
+			top := y - ascent
+			tickY := y - body.ascent/3
+			drawLine(dst, x, tickY, x+signW/4, tickY-thick*2, thick)
+			drawLine(dst, x+signW/4, tickY-thick*2, x+signW/2, y+body.descent, thick)
+			drawLine(dst, x+signW/2, y+body.descent, x+signW, top, thick)
+			fillRect(dst, image.Rect(x+signW, top, x+w-indent, top+thick), inkColor)
+			if index != nil {
+				index.draw(dst, x-indent, tickY-thick*3-index.descent)
+			}
+			body.draw(dst, x+signW, y)
+		},
+	}
+}
+
+// drawLine draws a straight line with a square pen of the given thickness.
+func drawLine(dst draw.Image, x0, y0, x1, y1, thick int) {
+	steps := max(abs(x1-x0), abs(y1-y0), 1)
+	for i := 0; i <= steps; i++ {
+		x := x0 + (x1-x0)*i/steps
+		y := y0 + (y1-y0)*i/steps
+		fillRect(dst, image.Rect(x, y, x+thick, y+thick), inkColor)
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
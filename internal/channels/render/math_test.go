@@ -0,0 +1,45 @@
+package render
+
+import "testing"
+
+func TestMathToText(t *testing.T) {
+	tests := []struct{ tex, want string }{
+		{`x^2 + y^2 = z^2`, "x² + y² = z²"},
+		{`a_{ij}`, "aᵢⱼ"},
+		{`x_1^2`, "x₁²"},
+		{`e^{i\pi} + 1 = 0`, "e^(iπ) + 1 = 0"},
+		{`\frac{1}{2}`, "1/2"},
+		{`\frac{a+b}{c}`, "(a+b)/c"},
+		{`\frac{a}{b}^2`, "(a/b)²"},
+		{`\sqrt{x+1}`, "√(x+1)"},
+		{`\sqrt[3]{8} = 2`, "³√8 = 2"},
+		{`\alpha \leq \beta \cdot \gamma`, "α ≤ β · γ"},
+		{`\sum_{i=1}^{n} i`, "∑ᵢ₌₁ⁿ i"},
+		{`\lim_{x \to 0} \frac{\sin x}{x}`, "lim_(x → 0) (sin x)/x"},
+		{`\text{speed} = \frac{d}{t}`, "speed = d/t"},
+		{`x \in \mathbb{R}`, "x ∈ ℝ"},
+		{`\left( a \right)`, "( a )"},
+		{`\unknown{x}`, "unknownx"},
+		{`a \\ b`, "a\nb"},
+		{`50\%`, "50%"},
+	}
+	for _, tt := range tests {
+		if got := MathToText(tt.tex); got != tt.want {
+			t.Errorf("MathToText(%q) = %q, want %q", tt.tex, got, tt.want)
+		}
+	}
+}
+
+func TestMathPNG(t *testing.T) {
+	img, ok := mathPNG(`\frac{-b \pm \sqrt{b^2 - 4ac}}{2a}`)
+	if !ok {
+		t.Fatal("mathPNG failed")
+	}
+	if b := img.Bounds(); b.Dx() < 100 || b.Dy() < 60 {
+		t.Errorf("image %v too small for a fraction", b)
+	}
+	// Hebrew has no glyph in the embedded fonts.
+	if _, ok := mathPNG(`\text{שלום}`); ok {
+		t.Error("mathPNG should fail for text the font cannot draw")
+	}
+}
@@ -0,0 +1,264 @@
+// Package render adapts agent markdown to what a channel can display.
+//
+// The markdown is parsed once into blocks (Parse). Each channel type declares
+// its Capabilities and Render applies the fallbacks for everything else:
+// tables become monospace text, labeled lists or PNG images, long code
+// blocks become file attachments and TeX math becomes Unicode text or a PNG.
+// Inline formatting (bold, links, …) is left to the channel's own formatter.
+package render
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// TableMode is how a channel shows pipe tables.
+type TableMode string
+
+const (
+	// TableNative leaves tables to the channel (HTML, cards, plugin).
+	TableNative TableMode = ""
+	// TableMonospace aligns columns inside a code block.
+	TableMonospace TableMode = "monospace"
+	// TableList writes one "Header: value" line per cell, for channels
+	// without monospace text.
+	TableList TableMode = "list"
+	// TableImage sends the table as a PNG, falling back to TableList when
+	// attachments are unavailable or the font lacks a glyph.
+	TableImage TableMode = "image"
+)
+
+// MathMode is how a channel shows TeX math.
+type MathMode string
+
+const (
+	// MathNative leaves $…$ and $$…$$ as written.
+	MathNative MathMode = ""
+	// MathText converts math to a Unicode approximation ("x² + √(y+1)").
+	MathText MathMode = "text"
+	// MathImage sends display math as a PNG and converts inline math to
+	// text. Falls back to MathText like TableImage does.
+	MathImage MathMode = "image"
+)
+
+// Capabilities describes what a channel renders natively. The zero value
+// renders everything natively, so Render returns the markdown unchanged.
+type Capabilities struct {
+	Tables TableMode
+	// MaxTableWidth sends monospace tables wider than this many columns as
+	// images instead. 0 means no limit.
+	MaxTableWidth int
+	// MaxCodeLines sends longer code blocks as file attachments. 0 keeps
+	// them inline.
+	MaxCodeLines int
+	Math         MathMode
+	// Attachments reports whether the channel delivers files; the image and
+	// file fallbacks need it.
+	Attachments bool
+}
+
+// Attachment is a file produced by a fallback.
+type Attachment struct {
+	Path        string
+	Name        string
+	ContentType string
+}
+
+// Output is the rendered message.
+type Output struct {
+	Text        string
+	Attachments []Attachment
+	// Dir holds the attachment files. Call Cleanup after delivery.
+	Dir string
+}
+
+// Cleanup removes the attachment files.
+func (o Output) Cleanup() {
+	if o.Dir != "" {
+		os.RemoveAll(o.Dir)
+	}
+}
+
+// Render renders markdown for a channel with the given capabilities. A
+// fallback that cannot produce its file degrades to the text form, so
+// Render always returns a usable message.
+func Render(markdown string, caps Capabilities) Output {
+	if caps == (Capabilities{}) {
+		return Output{Text: markdown}
+	}
+	r := &renderer{caps: caps, counts: map[string]int{}}
+	doc := Parse(markdown)
+	parts := make([]string, len(doc.Blocks))
+	for i, b := range doc.Blocks {
+		switch b.Kind {
+		case BlockCode:
+			parts[i] = r.code(b)
+		case BlockTable:
+			parts[i] = r.table(b.Table, b.Raw)
+		case BlockMath:
+			parts[i] = r.math(b)
+		default:
+			parts[i] = r.inline(b.Raw)
+		}
+	}
+	r.out.Text = strings.Join(parts, "\n")
+	return r.out
+}
+
+type renderer struct {
+	caps   Capabilities
+	out    Output
+	counts map[string]int // attachments written per kind, for file names
+}
+
+func (r *renderer) code(b Block) string {
+	n := strings.Count(b.Body, "\n") + 1
+	if r.caps.MaxCodeLines <= 0 || n <= r.caps.MaxCodeLines || !r.caps.Attachments {
+		return b.Raw
+	}
+	name, ok := r.attach("snippet", codeExtension(b.Lang), "text/plain", func(path string) error {
+		return os.WriteFile(path, []byte(b.Body+"\n"), 0o644)
+	})
+	if !ok {
+		return b.Raw
+	}
+	return fmt.Sprintf("[attached: %s, %d lines]", name, n)
+}
+
+func (r *renderer) table(t *Table, raw string) string {
+	if r.caps.Math != MathNative {
+		t = mapCells(t, r.inline)
+	}
+	switch r.caps.Tables {
+	case TableMonospace:
+		text, width := tableMonospace(t)
+		if r.caps.MaxTableWidth > 0 && width > r.caps.MaxTableWidth {
+			if s, ok := r.tableImage(t); ok {
+				return s
+			}
+		}
+		return text
+	case TableList:
+		return tableList(t)
+	case TableImage:
+		if s, ok := r.tableImage(t); ok {
+			return s
+		}
+		return tableList(t)
+	}
+	return r.inline(raw)
+}
+
+func (r *renderer) tableImage(t *Table) (string, bool) {
+	if !r.caps.Attachments {
+		return "", false
+	}
+	img, ok := tablePNG(t)
+	if !ok {
+		return "", false
+	}
+	name, ok := r.attach("table", "png", "image/png", func(path string) error {
+		return writePNG(path, img)
+	})
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("[attached: %s]", name), true
+}
+
+func (r *renderer) math(b Block) string {
+	switch r.caps.Math {
+	case MathNative:
+		return b.Raw
+	case MathImage:
+		if r.caps.Attachments {
+			if img, ok := mathPNG(b.Body); ok {
+				if name, ok := r.attach("math", "png", "image/png", func(path string) error {
+					return writePNG(path, img)
+				}); ok {
+					return fmt.Sprintf("[attached: %s]", name)
+				}
+			}
+		}
+	}
+	return MathToText(b.Body)
+}
+
+// reInlineMath matches \(…\) and $…$. A $ span must not start or end with a
+// space, which keeps prices such as "$5 and $10" out.
+var reInlineMath = regexp.MustCompile(`\\\((.+?)\\\)|\$([^\s$](?:[^$\n]*[^\s$\\])?)\$`)
+
+// inline converts inline math in a text block, outside code spans.
+func (r *renderer) inline(text string) string {
+	if r.caps.Math == MathNative || (!strings.Contains(text, "$") && !strings.Contains(text, `\(`)) {
+		return text
+	}
+	segments := strings.Split(text, "`")
+	for i := 0; i < len(segments); i += 2 { // odd segments are code spans
+		segments[i] = replaceInlineMath(segments[i])
+	}
+	return strings.Join(segments, "`")
+}
+
+func replaceInlineMath(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range reInlineMath.FindAllStringSubmatchIndex(s, -1) {
+		tex := ""
+		if m[2] >= 0 {
+			tex = s[m[2]:m[3]]
+		} else {
+			// "$5$10" or "$x$1" reads as currency, not math.
+			if m[1] < len(s) && s[m[1]] >= '0' && s[m[1]] <= '9' {
+				continue
+			}
+			tex = s[m[4]:m[5]]
+		}
+		b.WriteString(s[last:m[0]])
+		b.WriteString(MathToText(tex))
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// attach writes an attachment named "<kind>-<n>.<ext>" with write and
+// records it. It reports false when the file could not be written.
+func (r *renderer) attach(kind, ext, contentType string, write func(path string) error) (string, bool) {
+	if r.out.Dir == "" {
+		dir, err := os.MkdirTemp("", "goclaw-render-")
+		if err != nil {
+			return "", false
+		}
+		r.out.Dir = dir
+	}
+	r.counts[kind]++
+	name := fmt.Sprintf("%s-%d.%s", kind, r.counts[kind], ext)
+	path := filepath.Join(r.out.Dir, name)
+	if err := write(path); err != nil {
+		r.counts[kind]--
+		return "", false
+	}
+	r.out.Attachments = append(r.out.Attachments, Attachment{Path: path, Name: name, ContentType: contentType})
+	return name, true
+}
+
+var codeExtensions = map[string]string{
+	"go": "go", "golang": "go", "python": "py", "py": "py", "javascript": "js", "js": "js",
+	"typescript": "ts", "ts": "ts", "tsx": "tsx", "jsx": "jsx", "json": "json", "yaml": "yaml",
+	"yml": "yaml", "toml": "toml", "xml": "xml", "html": "html", "css": "css", "sql": "sql",
+	"bash": "sh", "sh": "sh", "shell": "sh", "zsh": "sh", "java": "java", "kotlin": "kt",
+	"swift": "swift", "c": "c", "cpp": "cpp", "c++": "cpp", "csharp": "cs", "cs": "cs",
+	"rust": "rs", "rs": "rs", "ruby": "rb", "rb": "rb", "php": "php", "markdown": "md",
+	"md": "md", "dockerfile": "dockerfile", "diff": "diff", "csv": "csv",
+}
+
+func codeExtension(lang string) string {
+	if ext, ok := codeExtensions[strings.ToLower(lang)]; ok {
+		return ext
+	}
+	return "txt"
+}
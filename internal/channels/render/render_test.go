@@ -0,0 +1,112 @@
+package render
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const sampleTable = "| Item | Qty |\n|:-----|----:|\n| **Tea** | 2 |\n| Coffee | 10 |"
+
+func TestRenderZeroCapabilitiesIsIdentity(t *testing.T) {
+	in := "# Title\n\n" + sampleTable + "\n\n$$x^2$$\n\n```go\nx := 1\n```"
+	if out := Render(in, Capabilities{}); out.Text != in || len(out.Attachments) != 0 {
+		t.Fatalf("Render with zero capabilities changed the text: %q", out.Text)
+	}
+}
+
+func TestRenderTableModes(t *testing.T) {
+	tests := []struct {
+		mode TableMode
+		want string
+	}{
+		{TableNative, sampleTable},
+		{TableMonospace, "```\nItem   | Qty\n-------+----\nTea    |   2\nCoffee |  10\n```"},
+		{TableList, "• Item: Tea\n  Qty: 2\n\n• Item: Coffee\n  Qty: 10"},
+		// No attachments: the image mode falls back to a list.
+		{TableImage, "• Item: Tea\n  Qty: 2\n\n• Item: Coffee\n  Qty: 10"},
+	}
+	for _, tt := range tests {
+		out := Render(sampleTable, Capabilities{Tables: tt.mode, Math: MathText})
+		if out.Text != tt.want {
+			t.Errorf("%q:\ngot:\n%s\nwant:\n%s", tt.mode, out.Text, tt.want)
+		}
+	}
+}
+
+func TestRenderWideMonospaceTableBecomesImage(t *testing.T) {
+	caps := Capabilities{Tables: TableMonospace, MaxTableWidth: 10, Attachments: true}
+	out := Render("before\n"+sampleTable+"\nafter", caps)
+	defer out.Cleanup()
+	if out.Text != "before\n[attached: table-1.png]\nafter" {
+		t.Fatalf("text = %q", out.Text)
+	}
+	if len(out.Attachments) != 1 || out.Attachments[0].ContentType != "image/png" {
+		t.Fatalf("attachments = %+v", out.Attachments)
+	}
+	if _, err := os.Stat(out.Attachments[0].Path); err != nil {
+		t.Fatalf("attachment missing: %v", err)
+	}
+}
+
+func TestRenderImageFallsBackWhenGlyphsMissing(t *testing.T) {
+	table := "| Món | Giá |\n|---|---|\n| Phở bò | 50.000đ |"
+	out := Render(table, Capabilities{Tables: TableImage, Attachments: true})
+	defer out.Cleanup()
+	if len(out.Attachments) != 0 {
+		t.Fatalf("Vietnamese tones are not in the embedded font; want the list fallback, got %+v", out.Attachments)
+	}
+	if out.Text != "• Món: Phở bò\n  Giá: 50.000đ" {
+		t.Fatalf("text = %q", out.Text)
+	}
+}
+
+func TestRenderLongCodeBecomesAttachment(t *testing.T) {
+	code := "```go\n" + strings.Repeat("fmt.Println()\n", 5) + "```"
+	caps := Capabilities{MaxCodeLines: 3, Attachments: true}
+
+	out := Render("see:\n"+code, caps)
+	if out.Text != "see:\n[attached: snippet-1.go, 5 lines]" {
+		t.Fatalf("text = %q", out.Text)
+	}
+	data, err := os.ReadFile(out.Attachments[0].Path)
+	if err != nil || string(data) != strings.Repeat("fmt.Println()\n", 5) {
+		t.Fatalf("attachment = %q, %v", data, err)
+	}
+	out.Cleanup()
+	if _, err := os.Stat(out.Dir); !os.IsNotExist(err) {
+		t.Fatalf("Cleanup left %s behind", out.Dir)
+	}
+
+	caps.Attachments = false
+	if out := Render(code, caps); out.Text != code {
+		t.Fatalf("without attachments the code must stay inline, got %q", out.Text)
+	}
+}
+
+func TestRenderInlineMath(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{`area is $\pi r^2$.`, "area is π r²."},
+		{`area is \(\pi r^2\).`, "area is π r²."},
+		{"costs $5 and $10 today", "costs $5 and $10 today"},
+		{"between $5$10", "between $5$10"},
+		{"code `$x^2$` stays", "code `$x^2$` stays"},
+	}
+	for _, tt := range tests {
+		if got := Render(tt.in, Capabilities{Math: MathText}).Text; got != tt.want {
+			t.Errorf("Render(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRenderDisplayMath(t *testing.T) {
+	in := "$$\n\\frac{a}{b}\n$$"
+	if got := Render(in, Capabilities{Math: MathText}).Text; got != "a/b" {
+		t.Errorf("text mode = %q", got)
+	}
+	out := Render(in, Capabilities{Math: MathImage, Attachments: true})
+	defer out.Cleanup()
+	if out.Text != "[attached: math-1.png]" || len(out.Attachments) != 1 {
+		t.Errorf("image mode = %q %+v", out.Text, out.Attachments)
+	}
+}
@@ -0,0 +1,117 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+var (
+	reCellLink   = regexp.MustCompile(`\[([^\]]+)\]\([^)]+\)`)
+	reCellMarker = regexp.MustCompile("\\*\\*|__|~~|`")
+	reCellItalic = regexp.MustCompile(`(^|[\s(])[*_]([^*_\s][^*_]*)[*_]`)
+)
+
+// plainCell strips inline markdown from a table cell; the fallbacks show
+// cells as plain text.
+func plainCell(s string) string {
+	s = reCellLink.ReplaceAllString(s, "$1")
+	s = reCellMarker.ReplaceAllString(s, "")
+	s = reCellItalic.ReplaceAllString(s, "$1$2")
+	return strings.TrimSpace(s)
+}
+
+func mapCells(t *Table, f func(string) string) *Table {
+	out := &Table{Header: make([]string, len(t.Header)), Align: t.Align, Rows: make([][]string, len(t.Rows))}
+	for i, h := range t.Header {
+		out.Header[i] = f(h)
+	}
+	for i, row := range t.Rows {
+		out.Rows[i] = make([]string, len(row))
+		for j, c := range row {
+			out.Rows[i][j] = f(c)
+		}
+	}
+	return out
+}
+
+// cell returns the plain text of row[i], or "" past the end of a short row.
+func cell(row []string, i int) string {
+	if i < len(row) {
+		return plainCell(row[i])
+	}
+	return ""
+}
+
+// tableMonospace renders t as aligned columns in a code block and returns
+// the width of its widest line.
+func tableMonospace(t *Table) (string, int) {
+	widths := make([]int, len(t.Header))
+	for i := range t.Header {
+		widths[i] = runewidth.StringWidth(cell(t.Header, i))
+		for _, row := range t.Rows {
+			widths[i] = max(widths[i], runewidth.StringWidth(cell(row, i)))
+		}
+	}
+	line := func(row []string) string {
+		parts := make([]string, len(widths))
+		for i, w := range widths {
+			parts[i] = pad(cell(row, i), w, t.Align[i])
+		}
+		return strings.TrimRight(strings.Join(parts, " | "), " ")
+	}
+	rule := make([]string, len(widths))
+	for i, w := range widths {
+		rule[i] = strings.Repeat("-", w)
+	}
+
+	lines := []string{line(t.Header), strings.Join(rule, "-+-")}
+	for _, row := range t.Rows {
+		lines = append(lines, line(row))
+	}
+	width := 0
+	for _, l := range lines {
+		width = max(width, runewidth.StringWidth(l))
+	}
+	return "```\n" + strings.Join(lines, "\n") + "\n```", width
+}
+
+func pad(s string, width int, align Align) string {
+	gap := max(width-runewidth.StringWidth(s), 0)
+	switch align {
+	case AlignRight:
+		return strings.Repeat(" ", gap) + s
+	case AlignCenter:
+		return strings.Repeat(" ", gap/2) + s + strings.Repeat(" ", gap-gap/2)
+	}
+	return s + strings.Repeat(" ", gap)
+}
+
+// tableList renders each row as a record of "Header: value" lines, the
+// first starting with a bullet. Records are separated by blank lines.
+func tableList(t *Table) string {
+	var records []string
+	for _, row := range t.Rows {
+		var lines []string
+		for i := range t.Header {
+			label, value := cell(t.Header, i), cell(row, i)
+			if value == "" {
+				continue
+			}
+			prefix := "  "
+			if len(lines) == 0 {
+				prefix = "• "
+			}
+			if label == "" {
+				lines = append(lines, prefix+value)
+			} else {
+				lines = append(lines, prefix+label+": "+value)
+			}
+		}
+		if len(lines) > 0 {
+			records = append(records, strings.Join(lines, "\n"))
+		}
+	}
+	return strings.Join(records, "\n\n")
+}
@@ -0,0 +1,31 @@
+package channels
+
+import (
+	"slices"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/render"
+)
+
+// renderOutbound adapts msg.Content to what the channel type renders
+// natively (see RenderCapabilitiesFor). Fallback files such as table images
+// and long code are appended to msg.Media; the returned cleanup removes them
+// and must run after delivery.
+func renderOutbound(channelType string, msg *bus.OutboundMessage) (cleanup func()) {
+	caps := RenderCapabilitiesFor(channelType)
+	// Placeholder updates are short status text edited in place.
+	if msg.Content == "" || caps == (render.Capabilities{}) || msg.Metadata["placeholder_update"] == "true" {
+		return func() {}
+	}
+	out := render.Render(msg.Content, caps)
+	msg.Content = out.Text
+	if len(out.Attachments) > 0 {
+		// Clip so appending never writes into the publisher's backing array.
+		media := slices.Clip(msg.Media)
+		for _, a := range out.Attachments {
+			media = append(media, bus.MediaAttachment{URL: a.Path, ContentType: a.ContentType})
+		}
+		msg.Media = media
+	}
+	return out.Cleanup
+}
@@ -0,0 +1,115 @@
+package channels
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+)
+
+var allChannelTypes = []string{
+	TypeBitrix24, TypeBridge, TypeDiscord, TypeEmail, TypeFacebook, TypeFeishu,
+	TypeMatrix, TypePancake, TypeSignal, TypeSlack, TypeTeams, TypeTelegram,
+	TypeTwilio, TypeWhatsApp, TypeZaloOA, TypeZaloPersonal,
+}
+
+// TestRenderOutboundGolden renders one agent reply for every channel type
+// and compares the text and attachments with testdata/render/<type>.golden.
+// Missing golden files are created.
+func TestRenderOutboundGolden(t *testing.T) {
+	input, err := os.ReadFile(filepath.Join("testdata", "render", "input.md"))
+	if err != nil {
+		t.Fatalf("read input: %v", err)
+	}
+	for _, ct := range allChannelTypes {
+		t.Run(ct, func(t *testing.T) {
+			msg := bus.OutboundMessage{Content: string(input)}
+			cleanup := renderOutbound(ct, &msg)
+			got := describeRendered(t, msg)
+			cleanup()
+			for _, m := range msg.Media {
+				if _, err := os.Stat(m.URL); !os.IsNotExist(err) {
+					t.Errorf("attachment %s not cleaned up", m.URL)
+				}
+			}
+
+			goldenPath := filepath.Join("testdata", "render", ct+".golden")
+			want, err := os.ReadFile(goldenPath)
+			if os.IsNotExist(err) {
+				if err := os.WriteFile(goldenPath, []byte(got), 0644); err != nil {
+					t.Fatalf("write golden: %v", err)
+				}
+				t.Logf("golden file created: %s", goldenPath)
+				return
+			}
+			if err != nil {
+				t.Fatalf("read golden: %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("render mismatch (update %s if intentional):\n--- got ---\n%s\n--- want ---\n%s", goldenPath, got, want)
+			}
+		})
+	}
+}
+
+// describeRendered prints the message text followed by one line per
+// attachment; images are described by their size since PNG bytes depend on
+// the encoder version.
+func describeRendered(t *testing.T, msg bus.OutboundMessage) string {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString(msg.Content)
+	b.WriteString("\n")
+	for _, m := range msg.Media {
+		desc := m.ContentType
+		if m.ContentType == "image/png" {
+			f, err := os.Open(m.URL)
+			if err != nil {
+				t.Fatalf("open attachment: %v", err)
+			}
+			cfg, err := png.DecodeConfig(f)
+			f.Close()
+			if err != nil {
+				t.Fatalf("decode %s: %v", m.URL, err)
+			}
+			desc += fmt.Sprintf(" %dx%d", cfg.Width, cfg.Height)
+		} else {
+			data, err := os.ReadFile(m.URL)
+			if err != nil {
+				t.Fatalf("read attachment: %v", err)
+			}
+			desc += fmt.Sprintf(" %d lines", strings.Count(string(data), "\n"))
+		}
+		fmt.Fprintf(&b, "--- attachment %s (%s)\n", filepath.Base(m.URL), desc)
+	}
+	return b.String()
+}
+
+func TestRenderOutboundSkipsPlaceholderUpdates(t *testing.T) {
+	content := "| a | b |\n|---|---|\n| 1 | 2 |"
+	msg := bus.OutboundMessage{Content: content, Metadata: map[string]string{"placeholder_update": "true"}}
+	renderOutbound(TypeTelegram, &msg)()
+	if msg.Content != content {
+		t.Fatalf("placeholder update rewritten: %q", msg.Content)
+	}
+}
+
+func TestRenderOutboundKeepsExistingMedia(t *testing.T) {
+	existing := []bus.MediaAttachment{{URL: "/workspace/report.pdf", ContentType: "application/pdf"}}
+	msg := bus.OutboundMessage{
+		Content: "$$E = mc^2$$",
+		Media:   existing[:1:1],
+	}
+	cleanup := renderOutbound(TypeTelegram, &msg)
+	defer cleanup()
+	if len(msg.Media) != 2 || msg.Media[0].URL != "/workspace/report.pdf" || msg.Media[1].ContentType != "image/png" {
+		t.Fatalf("media = %+v, want the PDF followed by the formula image", msg.Media)
+	}
+	if msg.Content != "[attached: math-1.png]" {
+		t.Fatalf("content = %q", msg.Content)
+	}
+}
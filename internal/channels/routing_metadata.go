@@ -16,6 +16,7 @@ var routingMetaKeys = []string{
 	"pancake_mode",           // pancake inbox vs comment routing
 	"post_id",                // pancake: post id for template vars
 	"display_name",           // pancake: commenter display name for template vars
+	"run_id",                 // agent run that produced the message (sent message tracking)
 }
 
 var finalReplyMetaKeys = append([]string{
@@ -0,0 +1,242 @@
+package signal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	rpcTimeout     = 30 * time.Second
+	dialTimeout    = 10 * time.Second
+	maxRPCLineSize = 64 * 1024 * 1024 // getAttachment returns base64 file data inline
+)
+
+var errConnClosed = errors.New("signal-cli connection closed")
+
+// rpcError is a JSON-RPC error object returned by signal-cli.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("signal-cli: %s (code %d)", e.Message, e.Code)
+}
+
+// isMethodNotFound reports a JSON-RPC "method not found" error, e.g. a
+// multi-account method called on a single-account daemon.
+func isMethodNotFound(err error) bool {
+	var re *rpcError
+	return errors.As(err, &re) && re.Code == -32601
+}
+
+// rpcMessage is any line on the socket: a response (ID set) or a
+// notification (Method set, no ID).
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// client speaks newline-delimited JSON-RPC 2.0 to a signal-cli daemon
+// (`signal-cli daemon --socket` or `--tcp`).
+type client struct {
+	network string
+	address string
+	account string // added to every request; empty for single-account daemons
+
+	mu      sync.Mutex // guards conn and serializes writes
+	conn    net.Conn
+	nextID  atomic.Uint64
+	pending sync.Map // request id -> chan rpcMessage
+}
+
+func newClient(address, account string) *client {
+	network, addr := parseAddress(address)
+	return &client{network: network, address: addr, account: account}
+}
+
+// parseAddress accepts "unix:///path", "/path", "tcp://host:port" or "host:port".
+func parseAddress(address string) (network, addr string) {
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		return "unix", strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "/"):
+		return "unix", address
+	case strings.HasPrefix(address, "tcp://"):
+		return "tcp", strings.TrimPrefix(address, "tcp://")
+	default:
+		return "tcp", address
+	}
+}
+
+// connect dials the daemon and starts reading. onNotify receives every
+// notification; it runs on the reader goroutine and must not call back into
+// the client. The returned channel is closed when the connection drops.
+func (c *client) connect(ctx context.Context, onNotify func(method string, params json.RawMessage)) (<-chan struct{}, error) {
+	d := net.Dialer{Timeout: dialTimeout}
+	conn, err := d.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return nil, fmt.Errorf("dial signal-cli %s %s: %w", c.network, c.address, err)
+	}
+	c.mu.Lock()
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	c.conn = conn
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.readLoop(conn, onNotify)
+	}()
+	return done, nil
+}
+
+func (c *client) readLoop(conn net.Conn, onNotify func(string, json.RawMessage)) {
+	defer func() {
+		c.mu.Lock()
+		if c.conn == conn {
+			c.conn = nil
+		}
+		c.mu.Unlock()
+		conn.Close()
+		// Fail in-flight calls instead of leaving them to time out.
+		c.pending.Range(func(k, v any) bool {
+			if c.pending.CompareAndDelete(k, v) {
+				close(v.(chan rpcMessage))
+			}
+			return true
+		})
+	}()
+
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, 0, 64*1024), maxRPCLineSize)
+	for sc.Scan() {
+		var msg rpcMessage
+		if err := json.Unmarshal(sc.Bytes(), &msg); err != nil {
+			continue
+		}
+		if len(msg.ID) > 0 && msg.Method == "" {
+			if ch, ok := c.pending.LoadAndDelete(idKey(msg.ID)); ok {
+				ch.(chan rpcMessage) <- msg
+			}
+			continue
+		}
+		if msg.Method != "" && onNotify != nil {
+			onNotify(msg.Method, msg.Params)
+		}
+	}
+}
+
+// idKey normalizes a JSON-RPC id (string or number) for pending lookups.
+func idKey(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// call sends an account request and decodes the result into out (if non-nil).
+func (c *client) call(ctx context.Context, method string, params map[string]any, out any) error {
+	if params == nil {
+		params = map[string]any{}
+	}
+	if c.account != "" {
+		params["account"] = c.account
+	}
+	return c.callDaemon(ctx, method, params, out)
+}
+
+// callDaemon sends a daemon-level request (listAccounts, startLink,
+// finishLink) without the account parameter.
+func (c *client) callDaemon(ctx context.Context, method string, params map[string]any, out any) error {
+	if params == nil {
+		params = map[string]any{}
+	}
+	id := strconv.FormatUint(c.nextID.Add(1), 10)
+	line, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	respCh := make(chan rpcMessage, 1)
+	c.pending.Store(id, respCh)
+	defer c.pending.Delete(id)
+
+	c.mu.Lock()
+	conn := c.conn
+	if conn == nil {
+		c.mu.Unlock()
+		return errConnClosed
+	}
+	_, err = conn.Write(append(line, '\n'))
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("signal-cli %s: %w", method, err)
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, rpcTimeout)
+		defer cancel()
+	}
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("signal-cli %s: %w", method, ctx.Err())
+	case resp, ok := <-respCh:
+		if !ok {
+			return errConnClosed
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if out != nil && len(resp.Result) > 0 {
+			return json.Unmarshal(resp.Result, out)
+		}
+		return nil
+	}
+}
+
+// close drops the connection; the reader goroutine exits and fails pending calls.
+func (c *client) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+}
+
+// sendResult is the result of the send, remoteDelete and sendReaction methods.
+type sendResult struct {
+	Timestamp int64 `json:"timestamp"`
+}
+
+// target returns the recipient params for a chat: groups are addressed by
+// group ID, DMs by phone number or account UUID.
+func target(chatID string) map[string]any {
+	if isGroupChat(chatID) {
+		return map[string]any{"groupId": chatID}
+	}
+	return map[string]any{"recipient": []string{chatID}}
+}
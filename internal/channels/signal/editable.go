@@ -0,0 +1,34 @@
+package signal
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// EditMessage replaces the text of a sent message. Signal clients accept
+// edits for 24 hours after sending.
+func (c *Channel) EditMessage(ctx context.Context, chatID, messageID, content string) error {
+	ts, err := strconv.ParseInt(messageID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("signal edit: invalid message ID %q", messageID)
+	}
+	if _, err := c.sendText(ctx, chatID, content, map[string]any{"editTimestamp": ts}); err != nil {
+		return fmt.Errorf("signal edit: %w", err)
+	}
+	return nil
+}
+
+// DeleteMessage deletes a sent message for everyone (remote delete).
+func (c *Channel) DeleteMessage(ctx context.Context, chatID, messageID string) error {
+	ts, err := strconv.ParseInt(messageID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("signal delete: invalid message ID %q", messageID)
+	}
+	params := target(chatID)
+	params["targetTimestamp"] = ts
+	if err := c.client.call(ctx, "remoteDelete", params, nil); err != nil {
+		return fmt.Errorf("signal delete: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package signal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+// --- Markdown to Signal text styles ---
+// Signal has no markup: text is sent plain with style ranges
+// ("start:length:STYLE", in UTF-16 code units). Nested markup is not
+// supported; the outer style wins and inner markers stay as text.
+
+// reMarkdown matches one markdown span; exactly one capture group is set.
+var reMarkdown = regexp.MustCompile(
+	"```[\\w+-]*\\n?([\\s\\S]*?)```" + // 1: fenced code
+		"|`([^`\\n]+)`" + // 2: inline code
+		`|\*\*(.+?)\*\*|__(.+?)__` + // 3, 4: bold
+		`|~~(.+?)~~` + // 5: strikethrough
+		`|\*([^*\s][^*\n]*?)\*` + // 6: italic
+		`|(?m:^#{1,6}[ \t]+(.+)$)` + // 7: heading
+		`|\[([^\]]+)\]\((https?://[^)\s]+)\)`, // 8, 9: link
+)
+
+var groupStyles = map[int]string{
+	1: "MONOSPACE",
+	2: "MONOSPACE",
+	3: "BOLD",
+	4: "BOLD",
+	5: "STRIKETHROUGH",
+	6: "ITALIC",
+	7: "BOLD",
+}
+
+// markdownToSignal strips markdown from text and returns the plain text with
+// its Signal text styles.
+func markdownToSignal(text string) (string, []string) {
+	var out strings.Builder
+	var styles []string
+	offset := 0 // UTF-16 length of out
+	write := func(s string) {
+		out.WriteString(s)
+		offset += utf16Len(s)
+	}
+
+	last := 0
+	for _, m := range reMarkdown.FindAllStringSubmatchIndex(text, -1) {
+		write(text[last:m[0]])
+		last = m[1]
+
+		if m[16] >= 0 { // link
+			label, url := text[m[16]:m[17]], text[m[18]:m[19]]
+			if label == url {
+				write(url)
+			} else {
+				write(label + " (" + url + ")")
+			}
+			continue
+		}
+		for g := 1; g <= 7; g++ {
+			if m[2*g] < 0 {
+				continue
+			}
+			inner := text[m[2*g]:m[2*g+1]]
+			if g == 1 {
+				inner = strings.TrimSuffix(inner, "\n")
+			}
+			if n := utf16Len(inner); n > 0 {
+				styles = append(styles, fmt.Sprintf("%d:%d:%s", offset, n, groupStyles[g]))
+			}
+			write(inner)
+			break
+		}
+	}
+	write(text[last:])
+	return out.String(), styles
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
@@ -0,0 +1,315 @@
+package signal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/typing"
+)
+
+// mentionPlaceholder is the object replacement character signal-cli puts in
+// the message text where a mention is rendered.
+const mentionPlaceholder = '\uFFFC'
+
+// envelope is a signal-cli "receive" notification envelope.
+type envelope struct {
+	Source       string       `json:"source"`
+	SourceNumber string       `json:"sourceNumber"`
+	SourceUUID   string       `json:"sourceUuid"`
+	SourceName   string       `json:"sourceName"`
+	Timestamp    int64        `json:"timestamp"`
+	DataMessage  *dataMessage `json:"dataMessage,omitempty"`
+}
+
+type dataMessage struct {
+	Timestamp   int64        `json:"timestamp"`
+	Message     string       `json:"message"`
+	GroupInfo   *groupInfo   `json:"groupInfo,omitempty"`
+	Attachments []attachment `json:"attachments,omitempty"`
+	Mentions    []mention    `json:"mentions,omitempty"`
+	Quote       *quote       `json:"quote,omitempty"`
+	Reaction    *struct{}    `json:"reaction,omitempty"`
+}
+
+type groupInfo struct {
+	GroupID string `json:"groupId"`
+	Type    string `json:"type"`
+}
+
+type attachment struct {
+	ContentType string `json:"contentType"`
+	Filename    string `json:"filename"`
+	ID          string `json:"id"`
+	Size        int64  `json:"size"`
+}
+
+// mention marks a placeholder in the message text; Start and Length are in
+// UTF-16 code units.
+type mention struct {
+	Name   string `json:"name"`
+	Number string `json:"number"`
+	UUID   string `json:"uuid"`
+	Start  int    `json:"start"`
+	Length int    `json:"length"`
+}
+
+type quote struct {
+	ID           int64  `json:"id"`
+	AuthorNumber string `json:"authorNumber"`
+	AuthorUUID   string `json:"authorUuid"`
+	Text         string `json:"text"`
+}
+
+// handleEnvelope processes one inbound envelope. Receipts, typing and sync
+// messages carry no DataMessage and are ignored.
+func (c *Channel) handleEnvelope(ctx context.Context, env *envelope) {
+	dm := env.DataMessage
+	if dm == nil || dm.Reaction != nil {
+		return
+	}
+	if dm.Message == "" && len(dm.Attachments) == 0 {
+		return // group updates, expiration timer changes
+	}
+
+	senderID := env.SourceNumber
+	if senderID == "" {
+		senderID = env.SourceUUID // sender hides their number
+	}
+	if senderID == "" || senderID == c.config.Account {
+		return
+	}
+	if _, loaded := c.dedup.LoadOrStore(senderID+":"+strconv.FormatInt(env.Timestamp, 10), time.Now()); loaded {
+		return
+	}
+
+	isDM := dm.GroupInfo == nil || dm.GroupInfo.GroupID == ""
+	chatID := senderID
+	peerKind := "direct"
+	if !isDM {
+		chatID = dm.GroupInfo.GroupID
+		peerKind = "group"
+	}
+
+	if isDM {
+		if !c.checkDMPolicy(ctx, senderID) {
+			return
+		}
+		if !c.IsAllowed(senderID) {
+			slog.Debug("signal message rejected by allowlist", "sender_id", senderID)
+			return
+		}
+	} else if !c.checkGroupPolicy(ctx, senderID, chatID) {
+		return
+	}
+
+	messageID := strconv.FormatInt(env.Timestamp, 10)
+	c.authors.Store(chatID+":"+messageID, timedValue{value: senderID, at: time.Now()})
+
+	displayName := env.SourceName
+	if displayName == "" {
+		displayName = senderID
+	}
+
+	content := c.renderMentions(dm.Message, dm.Mentions)
+	var mediaPaths []string
+	if items := c.downloadAttachments(ctx, chatID, dm.Attachments); len(items) > 0 {
+		for _, it := range items {
+			mediaPaths = append(mediaPaths, it.FilePath)
+			if it.Type == media.TypeDocument {
+				if doc, err := media.ExtractDocumentContent(it.FilePath, it.FileName); err != nil {
+					slog.Warn("signal: document extraction failed", "file", it.FileName, "error", err)
+				} else if doc != "" {
+					content = strings.TrimSpace(content + "\n\n" + doc)
+				}
+			}
+		}
+		if tags := media.BuildMediaTags(items); tags != "" {
+			content = strings.TrimSpace(tags + "\n\n" + content)
+		}
+	}
+	if content == "" {
+		return
+	}
+
+	// Mention gating in groups: unmentioned messages become pending history.
+	if !isDM && c.RequireMention() && !c.isBotMentioned(dm) {
+		c.GroupHistory().Record(chatID, channels.HistoryEntry{
+			Sender:    displayName,
+			SenderID:  senderID,
+			Body:      content,
+			Media:     mediaPaths,
+			Timestamp: time.UnixMilli(env.Timestamp),
+			MessageID: messageID,
+		}, c.HistoryLimit())
+		if cc := c.ContactCollector(); cc != nil {
+			cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", "group", "user", "", "")
+		}
+		return
+	}
+
+	slog.Debug("signal message received",
+		"sender_id", senderID, "chat_id", chatID,
+		"is_dm", isDM, "preview", channels.Truncate(content, 50))
+
+	c.startTyping(chatID)
+
+	finalContent := content
+	if peerKind == "group" {
+		annotated := fmt.Sprintf("[From: %s]\n%s", displayName, content)
+		if c.HistoryLimit() > 0 {
+			if histMedia := c.GroupHistory().CollectMedia(chatID); len(histMedia) > 0 {
+				mediaPaths = append(mediaPaths, histMedia...)
+			}
+			finalContent = c.GroupHistory().BuildContext(chatID, annotated, c.HistoryLimit())
+		} else {
+			finalContent = annotated
+		}
+	}
+
+	metadata := map[string]string{
+		"message_id":   messageID,
+		"user_id":      senderID,
+		"username":     env.SourceNumber,
+		"display_name": channels.SanitizeDisplayName(displayName),
+		"is_dm":        fmt.Sprintf("%t", isDM),
+		"local_key":    chatID,
+	}
+
+	c.HandleMessage(senderID, chatID, finalContent, mediaPaths, metadata, peerKind)
+
+	if peerKind == "group" {
+		c.GroupHistory().Clear(chatID)
+	}
+}
+
+// isBotMentioned reports an @mention of the bot's account or a reply to one
+// of the bot's messages.
+func (c *Channel) isBotMentioned(dm *dataMessage) bool {
+	for _, m := range dm.Mentions {
+		if m.Number == c.config.Account {
+			return true
+		}
+	}
+	return dm.Quote != nil && dm.Quote.AuthorNumber == c.config.Account
+}
+
+// renderMentions replaces mention placeholders with "@name", dropping the
+// bot's own mention.
+func (c *Channel) renderMentions(text string, mentions []mention) string {
+	if len(mentions) == 0 {
+		return strings.TrimSpace(text)
+	}
+	units := utf16.Encode([]rune(text))
+	var out []uint16
+	pos := 0
+	for _, m := range mentions {
+		if m.Start < pos || m.Start+m.Length > len(units) {
+			continue
+		}
+		out = append(out, units[pos:m.Start]...)
+		if m.Number != c.config.Account {
+			name := m.Name
+			if name == "" {
+				name = m.Number
+			}
+			out = append(out, utf16.Encode([]rune("@"+name))...)
+		}
+		pos = m.Start + m.Length
+	}
+	out = append(out, units[pos:]...)
+	rendered := string(utf16.Decode(out))
+	// Placeholders without mention metadata.
+	rendered = strings.ReplaceAll(rendered, string(mentionPlaceholder), "")
+	return strings.TrimSpace(strings.ReplaceAll(rendered, "  ", " "))
+}
+
+// startTyping shows the typing indicator until the reply is sent. Signal
+// clears it after ~15s, so it is refreshed while the agent runs.
+func (c *Channel) startTyping(chatID string) {
+	if c.config.Typing != nil && !*c.config.Typing {
+		return
+	}
+	send := func(stop bool) error {
+		params := target(chatID)
+		if stop {
+			params["stop"] = true
+		}
+		return c.client.call(context.Background(), "sendTyping", params, nil)
+	}
+	ctrl := typing.New(typing.Options{
+		MaxDuration:       60 * time.Second,
+		KeepaliveInterval: 10 * time.Second,
+		StartFn:           func() error { return send(false) },
+		StopFn:            func() error { return send(true) },
+	})
+	if prev, ok := c.typingCtrls.Load(chatID); ok {
+		prev.(*typing.Controller).Stop()
+	}
+	c.typingCtrls.Store(chatID, ctrl)
+	ctrl.Start()
+}
+
+func (c *Channel) stopTyping(chatID string) {
+	if ctrl, ok := c.typingCtrls.LoadAndDelete(chatID); ok {
+		ctrl.(*typing.Controller).Stop()
+	}
+}
+
+// checkDMPolicy enforces DM policy for incoming messages.
+func (c *Channel) checkDMPolicy(ctx context.Context, senderID string) bool {
+	switch c.CheckDMPolicy(ctx, senderID, c.config.DMPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, senderID, senderID)
+		return false
+	default:
+		slog.Debug("signal DM rejected by policy", "sender_id", senderID, "policy", c.config.DMPolicy)
+		return false
+	}
+}
+
+// checkGroupPolicy enforces group access policy; it does not check mention gating.
+func (c *Channel) checkGroupPolicy(ctx context.Context, senderID, groupID string) bool {
+	switch c.CheckGroupPolicy(ctx, senderID, groupID, c.config.GroupPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, "group:"+groupID, groupID)
+		return false
+	default:
+		slog.Debug("signal group message rejected by policy", "group_id", groupID, "policy", c.config.GroupPolicy)
+		return false
+	}
+}
+
+func (c *Channel) sendPairingReply(ctx context.Context, senderID, chatID string) {
+	ps := c.PairingService()
+	if ps == nil || !c.CanSendPairingNotif(senderID, pairingDebounce) {
+		return
+	}
+	code, err := ps.RequestPairing(ctx, senderID, c.Name(), chatID, "default", nil)
+	if err != nil {
+		slog.Debug("signal pairing request failed", "sender_id", senderID, "error", err)
+		return
+	}
+	reply := fmt.Sprintf(
+		"GoClaw: access not configured.\n\nYour Signal ID: %s\n\nPairing code: %s\n\nAsk the bot owner to approve with:\n  goclaw pairing approve %s",
+		senderID, code, code,
+	)
+	params := target(chatID)
+	params["message"] = reply
+	if err := c.client.call(ctx, "send", params, nil); err != nil {
+		slog.Warn("signal: failed to send pairing reply", "error", err)
+		return
+	}
+	c.MarkPairingNotifSent(senderID)
+	slog.Info("signal pairing reply sent", "sender_id", senderID, "code", code)
+}
@@ -0,0 +1,108 @@
+package signal
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+)
+
+const defaultMediaMaxBytes int64 = 20 * 1024 * 1024 // 20MB
+
+// downloadAttachments fetches inbound attachments into temp files. Failures
+// are logged and skipped so the text of the message still goes through.
+func (c *Channel) downloadAttachments(ctx context.Context, chatID string, atts []attachment) []media.MediaInfo {
+	var items []media.MediaInfo
+	for _, att := range atts {
+		item, err := c.downloadAttachment(ctx, chatID, att)
+		if err != nil {
+			slog.Warn("signal: attachment download failed", "id", att.ID, "error", err)
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// downloadAttachment reads one attachment through the getAttachment RPC,
+// which returns the file base64-encoded.
+func (c *Channel) downloadAttachment(ctx context.Context, chatID string, att attachment) (media.MediaInfo, error) {
+	if att.Size > c.mediaMaxBytes {
+		return media.MediaInfo{}, fmt.Errorf("file too large: %d bytes (max %d)", att.Size, c.mediaMaxBytes)
+	}
+
+	params := target(chatID)
+	params["id"] = att.ID
+	var res struct {
+		Data string `json:"data"`
+	}
+	if err := c.client.call(ctx, "getAttachment", params, &res); err != nil {
+		return media.MediaInfo{}, err
+	}
+	data, err := base64.StdEncoding.DecodeString(res.Data)
+	if err != nil {
+		return media.MediaInfo{}, fmt.Errorf("decode attachment: %w", err)
+	}
+	if int64(len(data)) > c.mediaMaxBytes {
+		return media.MediaInfo{}, fmt.Errorf("file too large: %d bytes (max %d)", len(data), c.mediaMaxBytes)
+	}
+
+	name := filepath.Base(att.Filename)
+	if att.Filename == "" {
+		name = att.ID
+	}
+	mime := att.ContentType
+	if mime == "" {
+		mime = media.DetectMIMEType(name)
+	}
+	ext := filepath.Ext(name)
+	if ext == "" {
+		ext = ".dat"
+	}
+
+	tmp, err := os.CreateTemp("", "signal-file-*"+ext)
+	if err != nil {
+		return media.MediaInfo{}, fmt.Errorf("create temp file: %w", err)
+	}
+	defer tmp.Close()
+	if _, err := tmp.Write(data); err != nil {
+		os.Remove(tmp.Name())
+		return media.MediaInfo{}, fmt.Errorf("write temp file: %w", err)
+	}
+
+	return media.MediaInfo{
+		Type:        media.MediaKindFromMime(mime),
+		FilePath:    tmp.Name(),
+		FileID:      att.ID,
+		ContentType: mime,
+		FileName:    name,
+		FileSize:    int64(len(data)),
+	}, nil
+}
+
+// attachmentURI encodes a local file as a data URI for the "attachments"
+// param of send; signal-cli may run on another host than the gateway.
+func (c *Channel) attachmentURI(att bus.MediaAttachment) (string, error) {
+	stat, err := os.Stat(att.URL)
+	if err != nil {
+		return "", err
+	}
+	if stat.Size() > c.mediaMaxBytes {
+		return "", fmt.Errorf("file too large: %d bytes (max %d)", stat.Size(), c.mediaMaxBytes)
+	}
+	data, err := os.ReadFile(att.URL)
+	if err != nil {
+		return "", err
+	}
+	name := filepath.Base(att.URL)
+	mime := att.ContentType
+	if mime == "" {
+		mime = media.DetectMIMEType(name)
+	}
+	return fmt.Sprintf("data:%s;filename=%s;base64,%s", mime, name, base64.StdEncoding.EncodeToString(data)), nil
+}
@@ -0,0 +1,186 @@
+package signal
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	qrcode "github.com/skip2/go-qrcode"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/gateway"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+	goclawprotocol "github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+const (
+	qrSessionTimeout = 3 * time.Minute
+	linkedDeviceName = "GoClaw"
+)
+
+// cancelEntry wraps a CancelFunc so it can be stored in sync.Map.CompareAndDelete.
+type cancelEntry struct {
+	cancel context.CancelFunc
+}
+
+// QRMethods handles signal.qr.start: links signal-cli as a secondary device
+// of the user's Signal account and saves the account number to the instance.
+type QRMethods struct {
+	instanceStore  store.ChannelInstanceStore
+	msgBus         *bus.MessageBus
+	activeSessions sync.Map // instanceID (string) -> *cancelEntry
+}
+
+func NewQRMethods(s store.ChannelInstanceStore, msgBus *bus.MessageBus) *QRMethods {
+	return &QRMethods{instanceStore: s, msgBus: msgBus}
+}
+
+func (m *QRMethods) Register(router *gateway.MethodRouter) {
+	router.Register(goclawprotocol.MethodSignalQRStart, m.handleQRStart)
+}
+
+func (m *QRMethods) handleQRStart(ctx context.Context, client *gateway.Client, req *goclawprotocol.RequestFrame) {
+	var params struct {
+		InstanceID  string `json:"instance_id"`
+		ForceReauth bool   `json:"force_reauth"`
+	}
+	if req.Params != nil {
+		_ = json.Unmarshal(req.Params, &params)
+	}
+
+	instID, err := uuid.Parse(params.InstanceID)
+	if err != nil {
+		client.SendResponse(goclawprotocol.NewErrorResponse(req.ID, goclawprotocol.ErrInvalidRequest, "invalid instance_id"))
+		return
+	}
+
+	inst, err := m.instanceStore.Get(ctx, instID)
+	if err != nil || inst.ChannelType != channels.TypeSignal {
+		client.SendResponse(goclawprotocol.NewErrorResponse(req.ID, goclawprotocol.ErrNotFound, "signal instance not found"))
+		return
+	}
+
+	qrCtx, cancel := context.WithTimeout(ctx, qrSessionTimeout)
+	entry := &cancelEntry{cancel: cancel}
+
+	// Cancel any previous linking session for this instance so the user can retry.
+	if prev, loaded := m.activeSessions.Swap(params.InstanceID, entry); loaded {
+		if prevEntry, ok := prev.(*cancelEntry); ok {
+			prevEntry.cancel()
+		}
+	}
+
+	// ACK immediately — QR/done events arrive asynchronously.
+	client.SendResponse(goclawprotocol.NewOKResponse(req.ID, map[string]any{"status": "started"}))
+
+	go m.runLinkFlow(qrCtx, entry, client, instID, params.InstanceID, inst.Config, params.ForceReauth)
+}
+
+func (m *QRMethods) runLinkFlow(ctx context.Context, entry *cancelEntry, client *gateway.Client,
+	instanceID uuid.UUID, instanceIDStr string, rawConfig json.RawMessage, forceReauth bool) {
+
+	defer entry.cancel()
+	defer m.activeSessions.CompareAndDelete(instanceIDStr, entry)
+
+	done := func(payload map[string]any) {
+		payload["instance_id"] = instanceIDStr
+		client.SendEvent(*goclawprotocol.NewEvent(goclawprotocol.EventSignalQRDone, payload))
+	}
+	fail := func(err error) {
+		slog.Warn("signal QR: device linking failed", "instance", instanceIDStr, "error", err)
+		done(map[string]any{"success": false, "error": err.Error()})
+	}
+
+	// Keep unknown keys when writing the account back.
+	cfgMap := map[string]any{}
+	var cfg signalInstanceConfig
+	if len(rawConfig) > 0 {
+		if err := json.Unmarshal(rawConfig, &cfgMap); err != nil {
+			fail(fmt.Errorf("decode signal config: %w", err))
+			return
+		}
+		_ = json.Unmarshal(rawConfig, &cfg)
+	}
+	if cfg.RPCAddress == "" {
+		cfg.RPCAddress = defaultRPCAddress
+	}
+
+	cl := newClient(cfg.RPCAddress, "")
+	if _, err := cl.connect(ctx, nil); err != nil {
+		fail(err)
+		return
+	}
+	defer cl.close()
+
+	if cfg.Account != "" && !forceReauth {
+		if accounts, err := listAccounts(ctx, cl); err == nil && slices.Contains(accounts, cfg.Account) {
+			done(map[string]any{"success": true, "already_connected": true, "account": cfg.Account})
+			return
+		}
+	}
+
+	var link struct {
+		DeviceLinkURI string `json:"deviceLinkUri"`
+	}
+	if err := cl.callDaemon(ctx, "startLink", nil, &link); err != nil {
+		fail(fmt.Errorf("startLink (is signal-cli running in multi-account mode?): %w", err))
+		return
+	}
+	png, err := qrcode.Encode(link.DeviceLinkURI, qrcode.Medium, 256)
+	if err != nil {
+		fail(fmt.Errorf("encode QR: %w", err))
+		return
+	}
+	client.SendEvent(*goclawprotocol.NewEvent(goclawprotocol.EventSignalQRCode, map[string]any{
+		"instance_id": instanceIDStr,
+		"png_b64":     base64.StdEncoding.EncodeToString(png),
+	}))
+
+	// Blocks until the user scans the code in Signal > Linked devices.
+	var linked struct {
+		Number string `json:"number"`
+	}
+	if err := cl.callDaemon(ctx, "finishLink", map[string]any{
+		"deviceLinkUri": link.DeviceLinkURI,
+		"deviceName":    linkedDeviceName,
+	}, &linked); err != nil {
+		fail(err)
+		return
+	}
+	if linked.Number == "" {
+		fail(fmt.Errorf("signal-cli did not report the linked account"))
+		return
+	}
+
+	cfgMap["account"] = linked.Number
+	cfgJSON, err := json.Marshal(cfgMap)
+	if err != nil {
+		fail(fmt.Errorf("encode signal config: %w", err))
+		return
+	}
+	if err := m.instanceStore.Update(ctx, instanceID, map[string]any{
+		"config": json.RawMessage(cfgJSON),
+	}); err != nil {
+		slog.Error("signal QR: save account failed", "instance", instanceIDStr, "error", err)
+		done(map[string]any{"success": false, "error": "failed to save account"})
+		return
+	}
+
+	// Trigger instanceLoader reload via cache invalidation.
+	if m.msgBus != nil {
+		m.msgBus.Broadcast(bus.Event{
+			Name:    goclawprotocol.EventCacheInvalidate,
+			Payload: bus.CacheInvalidatePayload{Kind: bus.CacheKindChannelInstances},
+		})
+	}
+
+	done(map[string]any{"success": true, "account": linked.Number})
+	slog.Info("signal device linked", "instance", instanceIDStr, "account", linked.Number)
+}
@@ -0,0 +1,100 @@
+package signal
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const reactionDebounceInterval = 700 * time.Millisecond
+
+// statusEmoji maps GoClaw agent status to reaction emoji.
+var statusEmoji = map[string]string{
+	"thinking": "🤔",
+	"tool":     "🛠️",
+	"done":     "✅",
+	"error":    "❌",
+	"stall":    "⏳",
+}
+
+// reactionState tracks the bot's current status reaction on one message.
+// Signal allows one reaction per user per message, so a new emoji replaces
+// the previous one.
+type reactionState struct {
+	emoji      string
+	lastUpdate time.Time
+	mu         sync.Mutex
+}
+
+// OnReactionEvent sets a status reaction on the user's message.
+func (c *Channel) OnReactionEvent(ctx context.Context, chatID string, messageID string, status string) error {
+	if c.config.ReactionLevel == "" || c.config.ReactionLevel == "off" {
+		return nil
+	}
+	emoji, ok := statusEmoji[status]
+	if !ok {
+		return nil
+	}
+	// For "minimal" level, only show thinking and done
+	if c.config.ReactionLevel == "minimal" && status != "thinking" && status != "done" {
+		return nil
+	}
+
+	stateVal, _ := c.reactions.LoadOrStore(chatID+":"+messageID, &reactionState{})
+	st := stateVal.(*reactionState)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.emoji == emoji || time.Since(st.lastUpdate) < reactionDebounceInterval {
+		return nil
+	}
+	if err := c.sendReaction(ctx, chatID, messageID, emoji, false); err != nil {
+		slog.Debug("signal: add reaction failed", "emoji", emoji, "error", err)
+		return nil
+	}
+	st.emoji = emoji
+	st.lastUpdate = time.Now()
+	return nil
+}
+
+// ClearReaction removes the current status reaction from a message.
+func (c *Channel) ClearReaction(ctx context.Context, chatID string, messageID string) error {
+	stateVal, ok := c.reactions.LoadAndDelete(chatID + ":" + messageID)
+	if !ok {
+		return nil
+	}
+	st := stateVal.(*reactionState)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.emoji != "" {
+		if err := c.sendReaction(ctx, chatID, messageID, st.emoji, true); err != nil {
+			slog.Debug("signal: clear reaction failed", "emoji", st.emoji, "error", err)
+		}
+	}
+	return nil
+}
+
+// sendReaction reacts to an inbound message. Signal identifies the target by
+// author and timestamp, so only messages seen by handleEnvelope qualify.
+func (c *Channel) sendReaction(ctx context.Context, chatID, messageID, emoji string, remove bool) error {
+	author, ok := c.authors.Load(chatID + ":" + messageID)
+	if !ok {
+		return nil
+	}
+	ts, err := strconv.ParseInt(messageID, 10, 64)
+	if err != nil {
+		return err
+	}
+	params := target(chatID)
+	params["emoji"] = emoji
+	params["targetAuthor"] = author.(timedValue).value
+	params["targetTimestamp"] = ts
+	if remove {
+		params["remove"] = true
+	}
+	return c.client.call(ctx, "sendReaction", params, nil)
+}
@@ -0,0 +1,83 @@
+package signal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+// Send delivers an outbound message to a Signal DM or group.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("signal channel not running")
+	}
+	if msg.ChatID == "" {
+		return fmt.Errorf("empty chat ID for signal send")
+	}
+	// No placeholder message to update: progress shows as the typing indicator.
+	if msg.Metadata["placeholder_update"] == "true" {
+		return nil
+	}
+	c.stopTyping(msg.ChatID)
+
+	for _, m := range msg.Media {
+		if err := c.sendMedia(ctx, msg.ChatID, m); err != nil {
+			slog.Warn("signal: media send failed", "file", m.URL, "error", err)
+			_ = c.sendChunked(ctx, msg.ChatID, fmt.Sprintf("[File upload failed: %s]", m.URL))
+		}
+	}
+	if msg.Content == "" {
+		return nil
+	}
+	return c.sendChunked(ctx, msg.ChatID, msg.Content)
+}
+
+// sendChunked sends markdown-aware chunks as separate messages.
+func (c *Channel) sendChunked(ctx context.Context, chatID, content string) error {
+	for _, chunk := range channels.ChunkMarkdown(content, maxMessageLen) {
+		ts, err := c.sendText(ctx, chatID, chunk, nil)
+		if err != nil {
+			return fmt.Errorf("send signal message: %w", err)
+		}
+		channels.RecordSentMessage(ctx, strconv.FormatInt(ts, 10))
+	}
+	return nil
+}
+
+func (c *Channel) sendMedia(ctx context.Context, chatID string, att bus.MediaAttachment) error {
+	uri, err := c.attachmentURI(att)
+	if err != nil {
+		return err
+	}
+	ts, err := c.sendText(ctx, chatID, att.Caption, map[string]any{"attachments": []string{uri}})
+	if err != nil {
+		return err
+	}
+	channels.RecordSentMessage(ctx, strconv.FormatInt(ts, 10))
+	return nil
+}
+
+// sendText sends markdown as styled text and returns the message timestamp,
+// which is its ID on Signal.
+func (c *Channel) sendText(ctx context.Context, chatID, text string, extra map[string]any) (int64, error) {
+	params := target(chatID)
+	for k, v := range extra {
+		params[k] = v
+	}
+	if text != "" {
+		plain, styles := markdownToSignal(text)
+		params["message"] = plain
+		if len(styles) > 0 {
+			params["textStyle"] = styles
+		}
+	}
+	var res sendResult
+	if err := c.client.call(ctx, "send", params, &res); err != nil {
+		return 0, err
+	}
+	return res.Timestamp, nil
+}
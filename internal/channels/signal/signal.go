@@ -0,0 +1,330 @@
+// Package signal implements a GoClaw channel for Signal through a local
+// signal-cli daemon: JSON-RPC over its socket for sending, notifications for
+// inbound messages, device linking by QR code.
+package signal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/safego"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	defaultRPCAddress = "127.0.0.1:7583" // signal-cli daemon --tcp default
+	maxMessageLen     = 2000             // Signal shows longer texts as "read more" attachments
+	reconnectMax      = 60 * time.Second
+	pairingDebounce   = 60 * time.Second
+	inboundQueueSize  = 64
+)
+
+// Channel connects to a signal-cli daemon as a linked device or primary account.
+type Channel struct {
+	*channels.BaseChannel
+	client        *client
+	config        signalInstanceConfig
+	mediaMaxBytes int64
+
+	inbound     chan *envelope
+	dedup       sync.Map // sender:timestamp -> time.Time
+	authors     sync.Map // chatID:timestamp -> author (for reactions on inbound messages)
+	reactions   sync.Map // chatID:messageID -> *reactionState
+	typingCtrls sync.Map // chatID -> *typing.Controller
+
+	wg       sync.WaitGroup
+	cancelFn context.CancelFunc
+}
+
+// Compile-time interface assertions.
+var _ channels.Channel = (*Channel)(nil)
+var _ channels.ReactionChannel = (*Channel)(nil)
+var _ channels.BlockReplyChannel = (*Channel)(nil)
+var _ channels.EditableChannel = (*Channel)(nil)
+
+// New creates a new Signal channel from instance config.
+func New(cfg signalInstanceConfig, msgBus *bus.MessageBus, pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (*Channel, error) {
+	if cfg.RPCAddress == "" {
+		cfg.RPCAddress = defaultRPCAddress
+	}
+	if cfg.Account != "" && !strings.HasPrefix(cfg.Account, "+") {
+		return nil, fmt.Errorf("signal account must be an E.164 phone number (+15551234567)")
+	}
+
+	base := channels.NewBaseChannel(channels.TypeSignal, msgBus, cfg.AllowFrom)
+	base.ValidatePolicy(cfg.DMPolicy, cfg.GroupPolicy)
+
+	requireMention := true
+	if cfg.RequireMention != nil {
+		requireMention = *cfg.RequireMention
+	}
+	historyLimit := cfg.HistoryLimit
+	if historyLimit == 0 {
+		historyLimit = channels.DefaultGroupHistoryLimit
+	}
+	mediaMax := int64(cfg.MediaMaxMB) * 1024 * 1024
+	if mediaMax <= 0 {
+		mediaMax = defaultMediaMaxBytes
+	}
+
+	ch := &Channel{
+		BaseChannel:   base,
+		client:        newClient(cfg.RPCAddress, cfg.Account),
+		config:        cfg,
+		mediaMaxBytes: mediaMax,
+		inbound:       make(chan *envelope, inboundQueueSize),
+	}
+	ch.SetRequireMention(requireMention)
+	ch.SetPairingService(pairingSvc)
+	ch.SetGroupHistory(channels.MakeHistory(channels.TypeSignal, pendingStore, base.TenantID()))
+	ch.SetHistoryLimit(historyLimit)
+	return ch, nil
+}
+
+// Start connects to the daemon and checks the account is registered there.
+// Messages received while the bot was offline are delivered by signal-cli
+// on connect, like on any other linked device.
+func (c *Channel) Start(ctx context.Context) error {
+	c.GroupHistory().StartFlusher()
+	c.MarkStarting("connecting to signal-cli")
+
+	if c.config.Account == "" {
+		c.MarkFailed("device not linked", "link the Signal account by scanning the QR code", channels.ChannelFailureKindAuth, false)
+		return fmt.Errorf("signal account not linked")
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	done, err := c.client.connect(ctx, c.onNotify)
+	if err != nil {
+		cancel()
+		c.MarkFailed("daemon unreachable", err.Error(), channels.ChannelFailureKindNetwork, true)
+		return fmt.Errorf("signal-cli connect failed: %w", err)
+	}
+	if err := c.checkAccount(ctx); err != nil {
+		cancel()
+		c.client.close()
+		c.MarkFailed("account not registered", err.Error(), channels.ChannelFailureKindAuth, false)
+		return err
+	}
+	c.cancelFn = cancel
+
+	c.wg.Add(3)
+	go func() {
+		defer c.wg.Done()
+		defer safego.Recover(nil, "component", "signal_conn")
+		c.connLoop(runCtx, done)
+	}()
+	go func() {
+		defer c.wg.Done()
+		defer safego.Recover(nil, "component", "signal_inbound")
+		c.inboundLoop(runCtx)
+	}()
+	go func() {
+		defer c.wg.Done()
+		defer safego.Recover(nil, "component", "signal_sweep")
+		ticker := time.NewTicker(2 * time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+				c.sweepMaps()
+			}
+		}
+	}()
+
+	c.SetRunning(true)
+	c.MarkHealthy("connected as " + c.config.Account)
+	slog.Info("signal channel connected", "account", c.config.Account, "rpc_address", c.config.RPCAddress)
+	return nil
+}
+
+// checkAccount verifies a multi-account daemon knows the configured account.
+// Single-account daemons (`signal-cli -a NUMBER daemon`) lack listAccounts.
+func (c *Channel) checkAccount(ctx context.Context) error {
+	accounts, err := listAccounts(ctx, c.client)
+	if err != nil {
+		if isMethodNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("signal-cli listAccounts: %w", err)
+	}
+	if !slices.Contains(accounts, c.config.Account) {
+		return fmt.Errorf("account %s is not registered or linked in signal-cli", c.config.Account)
+	}
+	return nil
+}
+
+// connLoop reconnects with backoff whenever the daemon connection drops.
+func (c *Channel) connLoop(ctx context.Context, done <-chan struct{}) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+		}
+		slog.Warn("signal-cli connection lost, reconnecting", "rpc_address", c.config.RPCAddress)
+		c.MarkDegraded("connection lost", "signal-cli daemon disconnected", channels.ChannelFailureKindNetwork, true)
+
+		backoff := time.Second
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			var err error
+			if done, err = c.client.connect(ctx, c.onNotify); err == nil {
+				break
+			}
+			slog.Debug("signal-cli reconnect failed", "error", err, "backoff", backoff)
+			backoff = min(backoff*2, reconnectMax)
+		}
+		c.MarkHealthy("connected as " + c.config.Account)
+	}
+}
+
+// onNotify runs on the client's reader goroutine. Envelopes are handed to
+// inboundLoop because handling them makes RPC calls of its own.
+func (c *Channel) onNotify(method string, params json.RawMessage) {
+	if method != "receive" {
+		return
+	}
+	var p struct {
+		Envelope *envelope `json:"envelope"`
+		Account  string    `json:"account"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil || p.Envelope == nil {
+		return
+	}
+	// Multi-account daemons send notifications for every account.
+	if p.Account != "" && p.Account != c.config.Account {
+		return
+	}
+	select {
+	case c.inbound <- p.Envelope:
+	default:
+		slog.Warn("signal: inbound queue full, dropping message", "source", p.Envelope.SourceNumber, "timestamp", p.Envelope.Timestamp)
+	}
+}
+
+func (c *Channel) inboundLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case env := <-c.inbound:
+			c.handleEnvelope(store.WithTenantID(ctx, c.TenantID()), env)
+		}
+	}
+}
+
+// sweepMaps performs age-based eviction of the dedup and author maps.
+func (c *Channel) sweepMaps() {
+	now := time.Now()
+	c.dedup.Range(func(k, v any) bool {
+		if t, ok := v.(time.Time); ok && now.Sub(t) > 10*time.Minute {
+			c.dedup.Delete(k)
+		}
+		return true
+	})
+	// Status reactions only target messages of recent runs.
+	c.authors.Range(func(k, v any) bool {
+		if a, ok := v.(timedValue); ok && now.Sub(a.at) > time.Hour {
+			c.authors.Delete(k)
+		}
+		return true
+	})
+}
+
+// timedValue is a map value with its insertion time for sweeping.
+type timedValue struct {
+	value string
+	at    time.Time
+}
+
+// SetPendingCompaction configures LLM-based auto-compaction for pending messages.
+func (c *Channel) SetPendingCompaction(cfg *channels.CompactionConfig) {
+	if gh := c.GroupHistory(); gh != nil {
+		gh.SetCompactionConfig(cfg)
+	}
+}
+
+// SetPendingHistoryTenantID propagates tenant_id to the pending history for DB operations.
+func (c *Channel) SetPendingHistoryTenantID(id uuid.UUID) {
+	if gh := c.GroupHistory(); gh != nil {
+		gh.SetTenantID(id)
+	}
+}
+
+// BlockReplyEnabled returns the per-channel block_reply override (nil = inherit gateway default).
+func (c *Channel) BlockReplyEnabled() *bool { return c.config.BlockReply }
+
+// ChatBehaviorConfig returns the per-channel chat_behavior override.
+func (c *Channel) ChatBehaviorConfig() *config.ChatBehaviorConfig { return c.config.ChatBehavior }
+
+// Stop gracefully shuts down the Signal channel.
+func (c *Channel) Stop(_ context.Context) error {
+	c.GroupHistory().StopFlusher()
+	slog.Info("stopping signal channel")
+	c.SetRunning(false)
+
+	if c.cancelFn != nil {
+		c.cancelFn()
+	}
+	c.client.close()
+	c.typingCtrls.Range(func(k, v any) bool {
+		c.stopTyping(k.(string))
+		return true
+	})
+
+	doneCh := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(doneCh)
+	}()
+	select {
+	case <-doneCh:
+	case <-time.After(10 * time.Second):
+		slog.Warn("signal channel stop timed out after 10s")
+	}
+	c.MarkStopped("stopped")
+	return nil
+}
+
+// isGroupChat distinguishes group IDs (base64) from DM recipients, which are
+// E.164 numbers or account UUIDs.
+func isGroupChat(chatID string) bool {
+	if strings.HasPrefix(chatID, "+") {
+		return false
+	}
+	_, err := uuid.Parse(chatID)
+	return err != nil
+}
+
+// listAccounts returns the numbers registered in a multi-account daemon.
+func listAccounts(ctx context.Context, cl *client) ([]string, error) {
+	var res []struct {
+		Number string `json:"number"`
+	}
+	if err := cl.callDaemon(ctx, "listAccounts", nil, &res); err != nil {
+		return nil, err
+	}
+	numbers := make([]string, 0, len(res))
+	for _, a := range res {
+		numbers = append(numbers, a.Number)
+	}
+	return numbers, nil
+}
@@ -0,0 +1,375 @@
+package signal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+)
+
+const (
+	botNumber   = "+15550000001"
+	aliceNumber = "+15550000002"
+	groupID     = "Z3JvdXAtaWQtYmFzZTY0PT0="
+)
+
+type rpcCall struct {
+	Method string
+	Params map[string]any
+}
+
+// fakeDaemon speaks signal-cli's JSON-RPC over TCP. It answers every request
+// and lets the test push "receive" notifications.
+type fakeDaemon struct {
+	ln net.Listener
+
+	mu    sync.Mutex
+	conn  net.Conn
+	calls []rpcCall
+	ts    int64
+}
+
+func newFakeDaemon(t *testing.T) *fakeDaemon {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	d := &fakeDaemon{ln: ln, ts: 1700000000000}
+	go d.serve()
+	t.Cleanup(func() {
+		ln.Close()
+		d.mu.Lock()
+		if d.conn != nil {
+			d.conn.Close()
+		}
+		d.mu.Unlock()
+	})
+	return d
+}
+
+func (d *fakeDaemon) serve() {
+	for {
+		conn, err := d.ln.Accept()
+		if err != nil {
+			return
+		}
+		d.mu.Lock()
+		d.conn = conn
+		d.mu.Unlock()
+		go d.handle(conn)
+	}
+}
+
+func (d *fakeDaemon) handle(conn net.Conn) {
+	sc := bufio.NewScanner(conn)
+	for sc.Scan() {
+		var req struct {
+			ID     string         `json:"id"`
+			Method string         `json:"method"`
+			Params map[string]any `json:"params"`
+		}
+		if json.Unmarshal(sc.Bytes(), &req) != nil {
+			continue
+		}
+		d.mu.Lock()
+		d.calls = append(d.calls, rpcCall{Method: req.Method, Params: req.Params})
+		var result any = map[string]any{}
+		switch req.Method {
+		case "listAccounts":
+			result = []map[string]string{{"number": botNumber}}
+		case "send", "remoteDelete", "sendReaction":
+			d.ts++
+			result = map[string]int64{"timestamp": d.ts}
+		case "getAttachment":
+			result = map[string]string{"data": base64.StdEncoding.EncodeToString([]byte("PNGDATA"))}
+		}
+		line, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+		_, _ = conn.Write(append(line, '\n'))
+		d.mu.Unlock()
+	}
+}
+
+// receive pushes an inbound envelope notification.
+func (d *fakeDaemon) receive(t *testing.T, envelope string) {
+	t.Helper()
+	var line bytes.Buffer
+	if err := json.Compact(&line, []byte(`{"jsonrpc":"2.0","method":"receive","params":{"account":"`+botNumber+`","envelope":`+envelope+"}}")); err != nil {
+		t.Fatalf("bad envelope: %v", err)
+	}
+	line.WriteByte('\n')
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, err := d.conn.Write(line.Bytes()); err != nil {
+		t.Fatalf("push notification: %v", err)
+	}
+}
+
+// waitCall waits for the n-th (1-based) call of a method.
+func (d *fakeDaemon) waitCall(t *testing.T, method string, n int) rpcCall {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		d.mu.Lock()
+		seen := 0
+		for _, c := range d.calls {
+			if c.Method == method {
+				if seen++; seen == n {
+					d.mu.Unlock()
+					return c
+				}
+			}
+		}
+		d.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("no call #%d to %s", n, method)
+	return rpcCall{}
+}
+
+func (d *fakeDaemon) countCalls(method string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := 0
+	for _, c := range d.calls {
+		if c.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+func startChannel(t *testing.T, d *fakeDaemon, cfg signalInstanceConfig) (*Channel, *bus.MessageBus) {
+	t.Helper()
+	cfg.RPCAddress = d.ln.Addr().String()
+	cfg.Account = botNumber
+	if cfg.DMPolicy == "" {
+		cfg.DMPolicy = "open"
+	}
+	if cfg.GroupPolicy == "" {
+		cfg.GroupPolicy = "open"
+	}
+	mb := bus.New()
+	ch, err := New(cfg, mb, nil, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = ch.Stop(context.Background()) })
+	return ch, mb
+}
+
+func consume(t *testing.T, mb *bus.MessageBus) bus.InboundMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msg, ok := mb.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("no inbound message")
+	}
+	return msg
+}
+
+func TestDirectMessageWithAttachmentAndStyledReply(t *testing.T) {
+	d := newFakeDaemon(t)
+	ch, mb := startChannel(t, d, signalInstanceConfig{})
+
+	d.receive(t, `{"sourceNumber":"`+aliceNumber+`","sourceName":"Alice","timestamp":1700000000100,
+		"dataMessage":{"timestamp":1700000000100,"message":"what is this?",
+			"attachments":[{"contentType":"image/png","filename":"cat.png","id":"att1","size":7}]}}`)
+
+	msg := consume(t, mb)
+	if msg.ChatID != aliceNumber || msg.PeerKind != "direct" || msg.SenderID != aliceNumber {
+		t.Fatalf("inbound = %+v", msg)
+	}
+	if msg.Metadata["message_id"] != "1700000000100" || msg.Metadata["display_name"] != "Alice" {
+		t.Fatalf("metadata = %v", msg.Metadata)
+	}
+	if !strings.Contains(msg.Content, "<media:image>") || !strings.Contains(msg.Content, "what is this?") {
+		t.Fatalf("content = %q", msg.Content)
+	}
+	if len(msg.Media) != 1 {
+		t.Fatalf("media = %+v", msg.Media)
+	}
+	defer os.Remove(msg.Media[0].Path)
+	if data, err := os.ReadFile(msg.Media[0].Path); err != nil || string(data) != "PNGDATA" {
+		t.Fatalf("downloaded = %q, %v", data, err)
+	}
+	if got := d.waitCall(t, "getAttachment", 1).Params; got["id"] != "att1" || got["account"] != botNumber {
+		t.Fatalf("getAttachment params = %v", got)
+	}
+	if got := d.waitCall(t, "sendTyping", 1).Params; got["stop"] != nil {
+		t.Fatalf("typing start params = %v", got)
+	}
+
+	if err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: aliceNumber, Content: "**done** now"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got := d.waitCall(t, "sendTyping", 2).Params; got["stop"] != true {
+		t.Fatalf("typing stop params = %v", got)
+	}
+	send := d.waitCall(t, "send", 1).Params
+	if send["message"] != "done now" || !reflect.DeepEqual(send["textStyle"], []any{"0:4:BOLD"}) ||
+		!reflect.DeepEqual(send["recipient"], []any{aliceNumber}) {
+		t.Fatalf("send params = %v", send)
+	}
+}
+
+func TestGroupMentionGatingUsesHistory(t *testing.T) {
+	d := newFakeDaemon(t)
+	ch, mb := startChannel(t, d, signalInstanceConfig{})
+
+	d.receive(t, `{"sourceNumber":"+15550000003","sourceName":"Bob","timestamp":1700000000200,
+		"dataMessage":{"timestamp":1700000000200,"message":"lunch?","groupInfo":{"groupId":"`+groupID+`","type":"DELIVER"}}}`)
+	d.receive(t, `{"sourceNumber":"`+aliceNumber+`","sourceName":"Alice","timestamp":1700000000300,
+		"dataMessage":{"timestamp":1700000000300,"message":"\uFFFC summarize for \uFFFC","groupInfo":{"groupId":"`+groupID+`","type":"DELIVER"},
+			"mentions":[{"name":"`+botNumber+`","number":"`+botNumber+`","start":0,"length":1},
+				{"name":"Bob","number":"+15550000003","start":16,"length":1}]}}`)
+
+	msg := consume(t, mb)
+	if msg.ChatID != groupID || msg.PeerKind != "group" {
+		t.Fatalf("inbound = %+v", msg)
+	}
+	if !strings.Contains(msg.Content, "[From: Alice]\nsummarize for @Bob") || !strings.Contains(msg.Content, "lunch?") {
+		t.Fatalf("content = %q", msg.Content)
+	}
+
+	if err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: groupID, Content: "ok"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	send := d.waitCall(t, "send", 1).Params
+	if send["groupId"] != groupID || send["recipient"] != nil {
+		t.Fatalf("group send params = %v", send)
+	}
+}
+
+func TestReactionsEditAndDelete(t *testing.T) {
+	d := newFakeDaemon(t)
+	ch, mb := startChannel(t, d, signalInstanceConfig{ReactionLevel: "full", Typing: new(false)})
+	ctx := context.Background()
+
+	d.receive(t, `{"sourceNumber":"`+aliceNumber+`","timestamp":1700000000400,
+		"dataMessage":{"timestamp":1700000000400,"message":"hi"}}`)
+	msg := consume(t, mb)
+
+	if err := ch.OnReactionEvent(ctx, msg.ChatID, msg.Metadata["message_id"], "thinking"); err != nil {
+		t.Fatalf("OnReactionEvent: %v", err)
+	}
+	if err := ch.ClearReaction(ctx, msg.ChatID, msg.Metadata["message_id"]); err != nil {
+		t.Fatalf("ClearReaction: %v", err)
+	}
+	react := d.waitCall(t, "sendReaction", 1).Params
+	if react["emoji"] != "🤔" || react["targetAuthor"] != aliceNumber || react["targetTimestamp"] != float64(1700000000400) {
+		t.Fatalf("reaction params = %v", react)
+	}
+	if clear := d.waitCall(t, "sendReaction", 2).Params; clear["remove"] != true || clear["emoji"] != "🤔" {
+		t.Fatalf("clear params = %v", clear)
+	}
+
+	if err := ch.EditMessage(ctx, aliceNumber, "1700000000999", "*fixed*"); err != nil {
+		t.Fatalf("EditMessage: %v", err)
+	}
+	edit := d.waitCall(t, "send", 1).Params
+	if edit["editTimestamp"] != float64(1700000000999) || edit["message"] != "fixed" {
+		t.Fatalf("edit params = %v", edit)
+	}
+	if err := ch.DeleteMessage(ctx, aliceNumber, "1700000000999"); err != nil {
+		t.Fatalf("DeleteMessage: %v", err)
+	}
+	if del := d.waitCall(t, "remoteDelete", 1).Params; del["targetTimestamp"] != float64(1700000000999) {
+		t.Fatalf("remoteDelete params = %v", del)
+	}
+	if n := d.countCalls("sendTyping"); n != 0 {
+		t.Errorf("typing disabled but sendTyping called %d times", n)
+	}
+}
+
+func TestIgnoresOwnAndDuplicateMessages(t *testing.T) {
+	d := newFakeDaemon(t)
+	_, mb := startChannel(t, d, signalInstanceConfig{})
+
+	own := `{"sourceNumber":"` + botNumber + `","timestamp":1700000000500,"dataMessage":{"timestamp":1700000000500,"message":"echo"}}`
+	dm := `{"sourceNumber":"` + aliceNumber + `","timestamp":1700000000600,"dataMessage":{"timestamp":1700000000600,"message":"once"}}`
+	d.receive(t, own)
+	d.receive(t, dm)
+	d.receive(t, dm)
+
+	if msg := consume(t, mb); msg.Content != "once" {
+		t.Fatalf("content = %q", msg.Content)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if msg, ok := mb.ConsumeInbound(ctx); ok {
+		t.Fatalf("unexpected inbound %+v", msg)
+	}
+}
+
+func TestStartRequiresLinkedAccount(t *testing.T) {
+	d := newFakeDaemon(t)
+	ch, err := New(signalInstanceConfig{RPCAddress: d.ln.Addr().String()}, bus.New(), nil, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := ch.Start(context.Background()); err == nil {
+		t.Fatal("Start without account should fail")
+	}
+	if _, err := New(signalInstanceConfig{Account: "15550000001"}, bus.New(), nil, nil); err == nil {
+		t.Error("account without + should fail")
+	}
+
+	other, err := New(signalInstanceConfig{RPCAddress: d.ln.Addr().String(), Account: "+15559999999"}, bus.New(), nil, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := other.Start(context.Background()); err == nil {
+		t.Fatal("Start with an account unknown to the daemon should fail")
+	}
+}
+
+func TestMarkdownToSignal(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		styles []string
+	}{
+		{"plain", "plain", nil},
+		{"**bold** and *it*", "bold and it", []string{"0:4:BOLD", "9:2:ITALIC"}},
+		{"é **b**", "é b", []string{"2:1:BOLD"}},
+		{"😀 ~~x~~", "😀 x", []string{"3:1:STRIKETHROUGH"}},
+		{"use `a**b**`", "use a**b**", []string{"4:6:MONOSPACE"}},
+		{"```go\nx := 1\n```", "x := 1", []string{"0:6:MONOSPACE"}},
+		{"# Title\nbody", "Title\nbody", []string{"0:5:BOLD"}},
+		{"see [docs](https://x.test/a)", "see docs (https://x.test/a)", nil},
+		{"snake_case_name", "snake_case_name", nil},
+	}
+	for _, tt := range tests {
+		got, styles := markdownToSignal(tt.in)
+		if got != tt.want || !reflect.DeepEqual(styles, tt.styles) {
+			t.Errorf("markdownToSignal(%q) = %q %v, want %q %v", tt.in, got, styles, tt.want, tt.styles)
+		}
+	}
+}
+
+func TestIsGroupChat(t *testing.T) {
+	for id, want := range map[string]bool{
+		aliceNumber:                            false,
+		"0d9c1f6e-2b8a-4a8e-9d7c-3f1e2a4b5c6d": false,
+		groupID:                                true,
+	} {
+		if got := isGroupChat(id); got != want {
+			t.Errorf("isGroupChat(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	slackapi "github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// actionsBlockID identifies the Block Kit actions block built from
+// bus.MessageAction, so clicks on other apps' or legacy blocks are ignored.
+const actionsBlockID = "goclaw_actions"
+
+// actionsFallbackText is the notification text of the buttons message.
+const actionsFallbackText = "Choose an option"
+
+// SupportsActions reports that actions render as Block Kit buttons.
+func (c *Channel) SupportsActions() bool { return true }
+
+// actionBlock renders actions as one Block Kit actions block. Button values
+// carry the action ID; action_id only has to be unique within the message.
+func actionBlock(actions []bus.MessageAction) *slackapi.ActionBlock {
+	elements := make([]slackapi.BlockElement, 0, len(actions))
+	for i, a := range actions {
+		btn := slackapi.NewButtonBlockElement("act_"+strconv.Itoa(i), "",
+			slackapi.NewTextBlockObject(slackapi.PlainTextType, a.Label, false, false))
+		if a.URL != "" {
+			btn.URL = a.URL
+		} else {
+			btn.Value = a.ID
+		}
+		switch a.Style {
+		case bus.ActionStylePrimary:
+			btn.WithStyle(slackapi.StylePrimary)
+		case bus.ActionStyleDanger:
+			btn.WithStyle(slackapi.StyleDanger)
+		}
+		elements = append(elements, btn)
+	}
+	return slackapi.NewActionBlock(actionsBlockID, elements...)
+}
+
+// sendActions posts the buttons as their own message after the reply text.
+func (c *Channel) sendActions(channelID, threadTS string, actions []bus.MessageAction) error {
+	opts := []slackapi.MsgOption{
+		slackapi.MsgOptionText(actionsFallbackText, false),
+		slackapi.MsgOptionBlocks(actionBlock(actions)),
+	}
+	if threadTS != "" {
+		opts = append(opts, slackapi.MsgOptionTS(threadTS))
+	}
+	if _, _, err := c.api.PostMessage(channelID, opts...); err != nil {
+		return fmt.Errorf("send slack actions: %w", err)
+	}
+	return nil
+}
+
+// handleInteractive routes block_actions payloads from our buttons into the bus.
+func (c *Channel) handleInteractive(evt socketmode.Event) {
+	cb, ok := evt.Data.(slackapi.InteractionCallback)
+	if !ok {
+		return
+	}
+	c.sm.Ack(*evt.Request)
+	if cb.Type != slackapi.InteractionTypeBlockActions {
+		return
+	}
+	for _, a := range cb.ActionCallback.BlockActions {
+		if a.BlockID != actionsBlockID || a.Value == "" {
+			continue // link buttons report a click too but carry no callback
+		}
+		c.handleActionClick(cb, a)
+	}
+}
+
+func (c *Channel) handleActionClick(cb slackapi.InteractionCallback, a *slackapi.BlockAction) {
+	ctx := store.WithTenantID(context.Background(), c.TenantID())
+	senderID := cb.User.ID
+	channelID := cb.Container.ChannelID
+	if channelID == "" {
+		channelID = cb.Channel.ID
+	}
+	if senderID == "" || channelID == "" {
+		return
+	}
+
+	isDM := strings.HasPrefix(channelID, "D")
+	peerKind := "group"
+	if isDM {
+		peerKind = "direct"
+	}
+	if !c.actionAllowed(ctx, senderID, channelID, isDM) {
+		slog.Debug("slack action rejected by policy", "user_id", senderID, "channel_id", channelID)
+		return
+	}
+
+	// Same thread routing as a message posted next to the buttons.
+	threadTS := cb.Container.ThreadTs
+	localKey := channelID
+	if threadTS != "" {
+		localKey = fmt.Sprintf("%s:thread:%s", channelID, threadTS)
+	}
+	replyThreadTS := threadTS
+	if !isDM && replyThreadTS == "" {
+		replyThreadTS = cb.Container.MessageTs
+	}
+
+	displayName := c.resolveDisplayName(senderID)
+	metadata := map[string]string{
+		"message_id":      cb.Container.MessageTs + ":" + a.Value,
+		"user_id":         senderID,
+		"username":        displayName,
+		"display_name":    channels.SanitizeDisplayName(displayName),
+		"channel_id":      channelID,
+		"is_dm":           fmt.Sprintf("%t", isDM),
+		"local_key":       localKey,
+		"placeholder_key": localKey,
+	}
+	if replyThreadTS != "" {
+		metadata["message_thread_id"] = replyThreadTS
+	}
+
+	c.HandleAction(senderID, channelID, displayName, bus.ActionCallback{
+		ActionID:  a.Value,
+		Label:     a.Text.Text,
+		MessageID: cb.Container.MessageTs,
+	}, metadata, peerKind)
+}
+
+// actionAllowed applies the DM/group policy without pairing replies. Slack's
+// group allowlist also accepts the channel ID, as checkGroupPolicy does.
+func (c *Channel) actionAllowed(ctx context.Context, senderID, channelID string, isDM bool) bool {
+	if isDM {
+		return c.CheckDMPolicy(ctx, senderID, c.config.DMPolicy) == channels.PolicyAllow && c.IsAllowed(senderID)
+	}
+	if c.config.GroupPolicy == "allowlist" {
+		return c.HasAllowList() && (c.IsAllowed(senderID) || c.IsAllowed(channelID))
+	}
+	return c.ActionAllowed(ctx, senderID, channelID, "group", c.config.DMPolicy, c.config.GroupPolicy)
+}
@@ -0,0 +1,27 @@
+package slack
+
+import (
+	"testing"
+
+	slackapi "github.com/slack-go/slack"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+)
+
+func TestActionBlock(t *testing.T) {
+	block := actionBlock([]bus.MessageAction{
+		{ID: "yes", Label: "Yes", Style: bus.ActionStylePrimary},
+		{Label: "Docs", URL: "https://example.com"},
+	})
+	if block.BlockID != actionsBlockID || len(block.Elements.ElementSet) != 2 {
+		t.Fatalf("block = %+v", block)
+	}
+	yes := block.Elements.ElementSet[0].(*slackapi.ButtonBlockElement)
+	if yes.Value != "yes" || yes.Style != slackapi.StylePrimary {
+		t.Errorf("choice button = %+v", yes)
+	}
+	docs := block.Elements.ElementSet[1].(*slackapi.ButtonBlockElement)
+	if docs.Value != "" || docs.URL != "https://example.com" {
+		t.Errorf("link button = %+v", docs)
+	}
+}
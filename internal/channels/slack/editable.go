@@ -0,0 +1,29 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+
+	slackapi "github.com/slack-go/slack"
+)
+
+// EditMessage replaces the text of a message the bot posted (chat.update).
+// messageID is the message ts.
+func (c *Channel) EditMessage(ctx context.Context, chatID, messageID, content string) error {
+	text := markdownToSlackMrkdwn(content)
+	if len(text) > maxMessageLen {
+		return fmt.Errorf("edited text exceeds slack message limit (%d)", maxMessageLen)
+	}
+	if _, _, _, err := c.api.UpdateMessageContext(ctx, chatID, messageID, slackapi.MsgOptionText(text, false)); err != nil {
+		return fmt.Errorf("update slack message: %w", err)
+	}
+	return nil
+}
+
+// DeleteMessage removes a message the bot posted (chat.delete).
+func (c *Channel) DeleteMessage(ctx context.Context, chatID, messageID string) error {
+	if _, _, err := c.api.DeleteMessageContext(ctx, chatID, messageID); err != nil {
+		return fmt.Errorf("delete slack message: %w", err)
+	}
+	return nil
+}
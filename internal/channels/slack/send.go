@@ -12,16 +12,26 @@ import (
 	"github.com/nextlevelbuilder/goclaw/internal/bus"
 )
 
-// Send delivers an outbound message to Slack.
-func (c *Channel) Send(_ context.Context, msg bus.OutboundMessage) error {
+// Send delivers an outbound message to Slack. Actions follow the text as a
+// separate Block Kit message in the same thread.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
 	if !c.IsRunning() {
 		return fmt.Errorf("slack bot not running")
 	}
-
-	channelID := msg.ChatID
-	if channelID == "" {
+	if msg.ChatID == "" {
 		return fmt.Errorf("empty chat ID for slack send")
 	}
+	if err := c.sendMessage(ctx, msg); err != nil {
+		return err
+	}
+	if len(msg.Actions) > 0 && msg.Metadata["placeholder_update"] != "true" {
+		return c.sendActions(msg.ChatID, msg.Metadata["message_thread_id"], msg.Actions)
+	}
+	return nil
+}
+
+func (c *Channel) sendMessage(ctx context.Context, msg bus.OutboundMessage) error {
+	channelID := msg.ChatID
 
 	placeholderKey := channelID
 	if pk := msg.Metadata["placeholder_key"]; pk != "" {
@@ -66,8 +76,9 @@ func (c *Channel) Send(_ context.Context, msg bus.OutboundMessage) error {
 		}
 
 		if _, _, _, editErr := c.api.UpdateMessage(channelID, ts, opts...); editErr == nil {
+			channels.RecordSentMessage(ctx, ts)
 			if remaining != "" {
-				return c.sendChunked(channelID, remaining, threadTS)
+				return c.sendChunked(ctx, channelID, remaining, threadTS)
 			}
 			return nil
 		} else {
@@ -81,24 +92,26 @@ func (c *Channel) Send(_ context.Context, msg bus.OutboundMessage) error {
 		if err := c.uploadFile(channelID, threadTS, media); err != nil {
 			slog.Warn("slack: file upload failed",
 				"file", media.URL, "error", err)
-			c.sendChunked(channelID, fmt.Sprintf("[File upload failed: %s]", media.URL), threadTS)
+			c.sendChunked(ctx, channelID, fmt.Sprintf("[File upload failed: %s]", media.URL), threadTS)
 		}
 	}
 
-	return c.sendChunked(channelID, content, threadTS)
+	return c.sendChunked(ctx, channelID, content, threadTS)
 }
 
 // sendChunked sends message chunks using markdown-aware splitting.
-func (c *Channel) sendChunked(channelID, content, threadTS string) error {
+func (c *Channel) sendChunked(ctx context.Context, channelID, content, threadTS string) error {
 	for _, chunk := range channels.ChunkMarkdown(content, maxMessageLen) {
 		opts := []slackapi.MsgOption{slackapi.MsgOptionText(chunk, false)}
 		if threadTS != "" {
 			opts = append(opts, slackapi.MsgOptionTS(threadTS))
 		}
 
-		if _, _, err := c.api.PostMessage(channelID, opts...); err != nil {
+		_, ts, err := c.api.PostMessage(channelID, opts...)
+		if err != nil {
 			return fmt.Errorf("send slack message: %w", err)
 		}
+		channels.RecordSentMessage(ctx, ts)
 	}
 	return nil
 }
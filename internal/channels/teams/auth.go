@@ -0,0 +1,265 @@
+package teams
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bot Framework endpoints. Variables so tests can point them at a local server.
+var (
+	openIDMetadataURL = "https://login.botframework.com/v1/.well-known/openidconfiguration"
+	tokenURLTemplate  = "https://login.microsoftonline.com/%s/oauth2/v2.0/token"
+)
+
+const (
+	botFrameworkIssuer = "https://api.botframework.com"
+	connectorScope     = "https://api.botframework.com/.default"
+	jwksRefresh        = 24 * time.Hour
+	jwksMinRefetch     = time.Minute // unknown kid refetch rate limit
+	clockSkew          = 5 * time.Minute
+)
+
+var errUnauthorized = errors.New("teams: unauthorized")
+
+// --- Inbound: Bot Framework JWT verification ---
+
+// keySet caches the Bot Framework signing keys (JWKS) for the whole process.
+type keySet struct {
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+	http    *http.Client
+}
+
+var botKeys = &keySet{http: &http.Client{Timeout: 15 * time.Second}}
+
+// tokenClaims are the claims checked on channel → bot requests.
+type tokenClaims struct {
+	Issuer     string   `json:"iss"`
+	Audience   audience `json:"aud"`
+	Expiry     int64    `json:"exp"`
+	NotBefore  int64    `json:"nbf"`
+	ServiceURL string   `json:"serviceurl"`
+}
+
+// audience accepts both the string and array forms of "aud".
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = []string{s}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// verify checks an "Authorization: Bearer <jwt>" header: RS256 signature
+// against the published keys, issuer, lifetime. Audience (the app ID) is
+// returned for the caller to match against its registered bots.
+func (k *keySet) verify(ctx context.Context, authHeader string, now time.Time) (*tokenClaims, error) {
+	token, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok || token == "" {
+		return nil, errUnauthorized
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errUnauthorized
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "RS256" || header.Kid == "" {
+		return nil, errUnauthorized
+	}
+	var claims tokenClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errUnauthorized
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errUnauthorized
+	}
+
+	pub, err := k.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+		return nil, errUnauthorized
+	}
+
+	if claims.Issuer != botFrameworkIssuer {
+		return nil, fmt.Errorf("%w: issuer %q", errUnauthorized, claims.Issuer)
+	}
+	if claims.Expiry == 0 || now.After(time.Unix(claims.Expiry, 0).Add(clockSkew)) {
+		return nil, fmt.Errorf("%w: token expired", errUnauthorized)
+	}
+	if claims.NotBefore != 0 && now.Add(clockSkew).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, fmt.Errorf("%w: token not yet valid", errUnauthorized)
+	}
+	return &claims, nil
+}
+
+func decodeSegment(seg string, out any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// key returns the signing key for kid, refreshing the key set when it is
+// stale or the kid is unknown (Microsoft rotates keys without notice).
+func (k *keySet) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if pub, ok := k.keys[kid]; ok && time.Since(k.fetched) < jwksRefresh {
+		return pub, nil
+	}
+	if time.Since(k.fetched) < jwksMinRefetch {
+		if pub, ok := k.keys[kid]; ok {
+			return pub, nil
+		}
+		return nil, fmt.Errorf("%w: unknown signing key", errUnauthorized)
+	}
+	keys, err := k.fetch(ctx)
+	if err != nil {
+		if pub, ok := k.keys[kid]; ok {
+			return pub, nil // keep serving with the cached set
+		}
+		return nil, fmt.Errorf("teams: fetch signing keys: %w", err)
+	}
+	k.keys, k.fetched = keys, time.Now()
+	if pub, ok := keys[kid]; ok {
+		return pub, nil
+	}
+	return nil, fmt.Errorf("%w: unknown signing key", errUnauthorized)
+}
+
+func (k *keySet) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	var meta struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := k.getJSON(ctx, openIDMetadataURL, &meta); err != nil {
+		return nil, err
+	}
+	if meta.JWKSURI == "" {
+		return nil, errors.New("openid metadata has no jwks_uri")
+	}
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := k.getJSON(ctx, meta.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jk := range set.Keys {
+		if jk.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(jk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jk.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[jk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
+
+func (k *keySet) getJSON(ctx context.Context, u string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := k.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: HTTP %d", u, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+// --- Outbound: client-credentials token for the Connector API ---
+
+// tokenSource obtains and caches the bot's access token for the Connector API.
+type tokenSource struct {
+	appID    string
+	secret   string
+	tenantID string // "botframework.com" for multi-tenant bots
+	http     *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (t *tokenSource) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token != "" && time.Until(t.expires) > 5*time.Minute {
+		return t.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {t.appID},
+		"client_secret": {t.secret},
+		"scope":         {connectorScope},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(tokenURLTemplate, url.PathEscape(t.tenantID)), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := t.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("teams token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+	if resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return "", &apiError{Status: resp.StatusCode, Code: body.Error, Message: body.Description, auth: true}
+	}
+	t.token = body.AccessToken
+	t.expires = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	return t.token, nil
+}
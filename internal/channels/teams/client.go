@@ -0,0 +1,221 @@
+package teams
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	apiTimeout       = 30 * time.Second
+	maxRateLimitWait = 10 * time.Second
+)
+
+// connector is a minimal Bot Framework Connector API client. Every call goes
+// to the serviceUrl the conversation's inbound activity came from.
+type connector struct {
+	tokens *tokenSource
+	http   *http.Client
+}
+
+func newConnector(appID, secret, tenantID string) *connector {
+	hc := &http.Client{}
+	return &connector{
+		tokens: &tokenSource{appID: appID, secret: secret, tenantID: tenantID, http: hc},
+		http:   hc,
+	}
+}
+
+// apiError is a Connector (or token endpoint) error response.
+type apiError struct {
+	Status     int
+	Code       string
+	Message    string
+	RetryAfter time.Duration
+	auth       bool // raised while obtaining the bot token
+}
+
+func (e *apiError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("teams: HTTP %d", e.Status)
+	}
+	return fmt.Sprintf("teams: HTTP %d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// isAuthError reports errors that retrying will not fix (bad app ID or secret).
+func isAuthError(err error) bool {
+	ae, ok := err.(*apiError)
+	return ok && ((ae.auth && ae.Status/100 == 4) || ae.Status == http.StatusUnauthorized)
+}
+
+// do sends a JSON request and decodes the JSON response into out (if non-nil).
+// One retry is made on 429 when the service asks for a short wait.
+func (c *connector) do(ctx context.Context, method, serviceURL, path string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader
+		if payload != nil {
+			reader = bytes.NewReader(payload)
+		}
+		err := c.raw(ctx, method, strings.TrimRight(serviceURL, "/")+path, reader, out)
+		ae, ok := err.(*apiError)
+		if !ok || ae.Status != http.StatusTooManyRequests || attempt > 0 {
+			return err
+		}
+		wait := ae.RetryAfter
+		if wait <= 0 {
+			wait = time.Second
+		}
+		if wait > maxRateLimitWait {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *connector) raw(ctx context.Context, method, fullURL string, body io.Reader, out any) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, apiTimeout)
+		defer cancel()
+	}
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		ae := &apiError{Status: resp.StatusCode}
+		var eb struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&eb) == nil {
+			ae.Code, ae.Message = eb.Error.Code, eb.Error.Message
+		}
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			ae.RetryAfter = time.Duration(secs) * time.Second
+		}
+		return ae
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	// Some endpoints answer 201/202 with an empty body.
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil || len(bytes.TrimSpace(data)) == 0 {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// --- Endpoints ---
+
+func conversationPath(conversationID string) string {
+	return "/v3/conversations/" + url.PathEscape(conversationID)
+}
+
+type resourceResponse struct {
+	ID string `json:"id"`
+}
+
+// sendActivity posts an activity to a conversation. For channel threads the
+// conversation ID carries ";messageid=<root>", which places it in the thread.
+func (c *connector) sendActivity(ctx context.Context, serviceURL, conversationID string, act *activity) (string, error) {
+	var resp resourceResponse
+	if err := c.do(ctx, http.MethodPost, serviceURL, conversationPath(conversationID)+"/activities", act, &resp); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// updateActivity replaces a previously sent activity (used for streaming in
+// group chats and channels, where the streaming protocol is not available).
+func (c *connector) updateActivity(ctx context.Context, serviceURL, conversationID, activityID string, act *activity) error {
+	act.ID = activityID
+	return c.do(ctx, http.MethodPut, serviceURL, conversationPath(conversationID)+"/activities/"+url.PathEscape(activityID), act, nil)
+}
+
+func (c *connector) deleteActivity(ctx context.Context, serviceURL, conversationID, activityID string) error {
+	return c.do(ctx, http.MethodDelete, serviceURL, conversationPath(conversationID)+"/activities/"+url.PathEscape(activityID), nil, nil)
+}
+
+// members returns the conversation roster. For channel conversations this is
+// the team roster.
+func (c *connector) members(ctx context.Context, serviceURL, conversationID string) ([]channelAccount, error) {
+	var out []channelAccount
+	if err := c.do(ctx, http.MethodGet, serviceURL, conversationPath(conversationID)+"/members", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// download fetches an attachment into w. Teams-hosted inline images need the
+// bot token; pre-authenticated file download URLs must not receive it.
+func (c *connector) download(ctx context.Context, fileURL string, withToken bool, maxBytes int64, w io.Writer) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return err
+	}
+	if withToken {
+		token, err := c.tokens.Token(ctx)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &apiError{Status: resp.StatusCode}
+	}
+	if resp.ContentLength > maxBytes {
+		return fmt.Errorf("file too large: %d bytes (max %d)", resp.ContentLength, maxBytes)
+	}
+	n, err := io.Copy(w, io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return err
+	}
+	if n > maxBytes {
+		return fmt.Errorf("file too large (max %d bytes)", maxBytes)
+	}
+	return nil
+}
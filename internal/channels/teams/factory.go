@@ -0,0 +1,77 @@
+package teams
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// teamsCreds maps the credentials JSON from the channel_instances table.
+type teamsCreds struct {
+	AppPassword string `json:"app_password"` // Azure bot client secret
+}
+
+// teamsInstanceConfig maps the non-secret config JSONB from the channel_instances table.
+type teamsInstanceConfig struct {
+	AppID          string                     `json:"app_id"`                  // Azure bot (Microsoft App) ID
+	AppTenantID    string                     `json:"app_tenant_id,omitempty"` // single-tenant bots; default "botframework.com"
+	AllowedTenants []string                   `json:"allowed_tenants,omitempty"`
+	DMPolicy       string                     `json:"dm_policy,omitempty"`
+	GroupPolicy    string                     `json:"group_policy,omitempty"`
+	AllowFrom      []string                   `json:"allow_from,omitempty"`
+	RequireMention *bool                      `json:"require_mention,omitempty"`
+	HistoryLimit   int                        `json:"history_limit,omitempty"`
+	DMStream       *bool                      `json:"dm_stream,omitempty"`
+	GroupStream    *bool                      `json:"group_stream,omitempty"`
+	MediaMaxMB     int                        `json:"media_max_mb,omitempty"`
+	BlockReply     *bool                      `json:"block_reply,omitempty"`
+	ChatBehavior   *config.ChatBehaviorConfig `json:"chat_behavior,omitempty"`
+}
+
+// Factory creates a Teams channel from DB instance data.
+func Factory(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+	return build(name, creds, cfg, msgBus, pairingSvc, nil)
+}
+
+// FactoryWithPendingStore returns a ChannelFactory with persistent history support.
+func FactoryWithPendingStore(pendingStore store.PendingMessageStore) channels.ChannelFactory {
+	return func(name string, creds json.RawMessage, cfg json.RawMessage,
+		msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+		return build(name, creds, cfg, msgBus, pairingSvc, pendingStore)
+	}
+}
+
+func build(name string, creds, cfg json.RawMessage, msgBus *bus.MessageBus,
+	pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (channels.Channel, error) {
+
+	var c teamsCreds
+	if len(creds) > 0 {
+		if err := json.Unmarshal(creds, &c); err != nil {
+			return nil, fmt.Errorf("decode teams credentials: %w", err)
+		}
+	}
+
+	var ic teamsInstanceConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &ic); err != nil {
+			return nil, fmt.Errorf("decode teams config: %w", err)
+		}
+	}
+
+	// Secure default: DB instances default to "pairing" for groups.
+	if ic.GroupPolicy == "" {
+		ic.GroupPolicy = "pairing"
+	}
+
+	ch, err := New(ic, c, msgBus, pairingSvc, pendingStore)
+	if err != nil {
+		return nil, err
+	}
+	ch.SetName(name)
+	return ch, nil
+}
@@ -0,0 +1,172 @@
+package teams
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// --- Markdown to Adaptive Card ---
+// TextBlock already renders Teams' markdown subset (bold, italic, links,
+// lists), so the markdown is only split into separate elements where that
+// subset falls short: headings, code fences, tables, quotes and rules.
+
+var (
+	reFence    = regexp.MustCompile("^```")
+	reHeading  = regexp.MustCompile(`^(#{1,6})\s+(.+)$`)
+	reTableSep = regexp.MustCompile(`^\s*\|?\s*:?-{3,}:?\s*(\|\s*:?-{3,}:?\s*)*\|?\s*$`)
+	reRule     = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+)
+
+// buildAdaptiveCard renders markdown as an Adaptive Card 1.5 (the highest
+// version Teams clients support), stretched to the full message width.
+func buildAdaptiveCard(text string) map[string]any {
+	return map[string]any{
+		"type":    "AdaptiveCard",
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"version": "1.5",
+		"msteams": map[string]any{"width": "Full"},
+		"body":    cardBody(text),
+	}
+}
+
+// cardAttachment wraps a card for an activity's attachments list.
+func cardAttachment(text string) attachment {
+	return attachment{ContentType: contentTypeAdaptiveCard, Content: mustJSON(buildAdaptiveCard(text))}
+}
+
+func mustJSON(v any) json.RawMessage {
+	b, _ := json.Marshal(v)
+	return b
+}
+
+// cardBody walks the lines once, emitting one card element per markdown block.
+func cardBody(text string) []map[string]any {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	body := []map[string]any{}
+	separator := false
+	add := func(el map[string]any) {
+		if separator {
+			el["separator"] = true
+			separator = false
+		}
+		body = append(body, el)
+	}
+
+	var para []string
+	flushPara := func() {
+		if len(para) > 0 {
+			add(textBlock(strings.Join(para, "\n")))
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case reFence.MatchString(trimmed):
+			flushPara()
+			var code []string
+			for i++; i < len(lines) && !reFence.MatchString(strings.TrimSpace(lines[i])); i++ {
+				code = append(code, lines[i])
+			}
+			// RichTextBlock: TextRun content is not parsed as markdown.
+			add(map[string]any{
+				"type":  "Container",
+				"style": "emphasis",
+				"items": []map[string]any{{
+					"type":    "RichTextBlock",
+					"inlines": []map[string]any{{"type": "TextRun", "text": strings.Join(code, "\n"), "fontType": "Monospace"}},
+				}},
+			})
+
+		case trimmed == "":
+			flushPara()
+
+		case reHeading.MatchString(trimmed):
+			flushPara()
+			m := reHeading.FindStringSubmatch(trimmed)
+			el := textBlock(m[2])
+			el["weight"] = "Bolder"
+			switch len(m[1]) {
+			case 1:
+				el["size"] = "Large"
+			case 2:
+				el["size"] = "Medium"
+			}
+			add(el)
+
+		case reRule.MatchString(trimmed):
+			flushPara()
+			separator = true
+
+		case strings.HasPrefix(trimmed, ">"):
+			flushPara()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quote = append(quote, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")))
+			}
+			i--
+			el := textBlock(strings.Join(quote, "\n"))
+			el["isSubtle"] = true
+			add(map[string]any{"type": "Container", "style": "emphasis", "items": []map[string]any{el}})
+
+		case strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && reTableSep.MatchString(lines[i+1]):
+			flushPara()
+			rows := [][]string{splitRow(trimmed)}
+			for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				rows = append(rows, splitRow(strings.TrimSpace(lines[i])))
+			}
+			i--
+			add(table(rows))
+
+		default:
+			para = append(para, line)
+		}
+	}
+	flushPara()
+	return body
+}
+
+func textBlock(text string) map[string]any {
+	return map[string]any{"type": "TextBlock", "text": text, "wrap": true}
+}
+
+func splitRow(line string) []string {
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	cells := strings.Split(line, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// table renders rows (header first) as an Adaptive Card Table element.
+func table(rows [][]string) map[string]any {
+	width := len(rows[0])
+	columns := make([]map[string]any, width)
+	for i := range columns {
+		columns[i] = map[string]any{"width": 1}
+	}
+	out := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		cells := make([]map[string]any, width)
+		for i := range cells {
+			text := ""
+			if i < len(row) {
+				text = row[i]
+			}
+			cells[i] = map[string]any{"type": "TableCell", "items": []map[string]any{textBlock(text)}}
+		}
+		out = append(out, map[string]any{"type": "TableRow", "cells": cells})
+	}
+	return map[string]any{
+		"type":             "Table",
+		"columns":          columns,
+		"rows":             out,
+		"firstRowAsHeader": true,
+		"showGridLines":    true,
+	}
+}
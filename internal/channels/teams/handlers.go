@@ -0,0 +1,346 @@
+package teams
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+	"github.com/nextlevelbuilder/goclaw/internal/tools"
+)
+
+// checkDownloadURL guards attachment downloads against SSRF. Variable so
+// tests can allow their loopback server.
+var checkDownloadURL = tools.CheckSSRF
+
+// handleActivity processes one authenticated activity from the router.
+func (c *Channel) handleActivity(ctx context.Context, act *activity) {
+	if act.Conversation == nil || act.From == nil || act.Recipient == nil {
+		return
+	}
+	if !c.tenantAllowed(act.Conversation.TenantID) {
+		slog.Debug("teams activity from disallowed tenant", "tenant_id", act.Conversation.TenantID)
+		return
+	}
+
+	convID := baseConversationID(act.Conversation.ID)
+	convType := act.Conversation.ConversationType
+	if convType == "" {
+		convType = "personal"
+		if act.Conversation.IsGroup {
+			convType = "groupChat"
+		}
+	}
+	c.convs.Store(convID, convRef{serviceURL: act.ServiceURL, convType: convType, tenantID: act.Conversation.TenantID})
+
+	switch act.Type {
+	case "message":
+	case "conversationUpdate":
+		for _, m := range act.MembersAdded {
+			if m.ID == act.Recipient.ID {
+				slog.Info("teams: bot added to conversation", "conversation_id", convID, "type", convType)
+			}
+		}
+		return
+	default:
+		return
+	}
+	if act.From.ID == act.Recipient.ID {
+		return
+	}
+	if act.ID != "" {
+		if _, loaded := c.dedup.LoadOrStore(convID+"/"+act.ID, time.Now()); loaded {
+			return // connector retry of an activity we already acknowledged
+		}
+	}
+	c.handleMessage(ctx, act, convID, convType)
+}
+
+func (c *Channel) handleMessage(ctx context.Context, act *activity, convID, convType string) {
+	senderID := act.From.AADObjectID
+	if senderID == "" {
+		senderID = act.From.ID
+	}
+	displayName := act.From.Name
+	if displayName == "" {
+		displayName = senderID
+	}
+
+	isDM := convType == "personal"
+	peerKind := "group"
+	if isDM {
+		peerKind = "direct"
+	}
+
+	// Channel posts are threads: a reply carries ";messageid=<root>", a new
+	// post is the root of its own thread.
+	var threadRoot string
+	if convType == "channel" {
+		if _, root, ok := strings.Cut(act.Conversation.ID, ";messageid="); ok {
+			threadRoot = root
+		} else {
+			threadRoot = act.ID
+		}
+	}
+	replyConv := threadConversationID(convID, threadRoot)
+
+	if isDM {
+		if !c.checkDMPolicy(ctx, senderID, replyConv, act.ServiceURL) {
+			return
+		}
+		if !c.IsAllowed(senderID) {
+			slog.Debug("teams message rejected by allowlist", "user_id", senderID)
+			return
+		}
+	} else if !c.checkGroupPolicy(ctx, senderID, convID, replyConv, act.ServiceURL) {
+		return
+	}
+
+	mentioned := c.isBotMentioned(act)
+	content := cleanText(act.Text, act.Entities, act.Recipient.ID)
+	items := c.downloadAttachments(ctx, act.Attachments, act.ServiceURL)
+	var mediaPaths []string
+	if len(items) > 0 {
+		for _, it := range items {
+			if it.Type == media.TypeDocument {
+				if doc, err := media.ExtractDocumentContent(it.FilePath, it.FileName); err != nil {
+					slog.Warn("teams: document extraction failed", "file", it.FileName, "error", err)
+				} else if doc != "" {
+					content = strings.TrimSpace(content + "\n\n" + doc)
+				}
+			}
+			mediaPaths = append(mediaPaths, it.FilePath)
+		}
+		if tags := media.BuildMediaTags(items); tags != "" {
+			content = strings.TrimSpace(tags + "\n\n" + content)
+		}
+	}
+	if content == "" {
+		return
+	}
+
+	localKey := convID
+	if threadRoot != "" {
+		localKey = convID + ":thread:" + threadRoot
+	}
+
+	// Mention gating in group chats and channels: unmentioned messages become pending history.
+	if !isDM && c.RequireMention() && !mentioned {
+		c.GroupHistory().Record(localKey, channels.HistoryEntry{
+			Sender:    displayName,
+			SenderID:  senderID,
+			Body:      content,
+			Media:     mediaPaths,
+			Timestamp: time.Now(),
+			MessageID: act.ID,
+		}, c.HistoryLimit())
+		if cc := c.ContactCollector(); cc != nil {
+			cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", "group", "user", "", "")
+		}
+		return
+	}
+
+	slog.Debug("teams message received",
+		"sender_id", senderID, "conversation_id", convID, "type", act.Conversation.ConversationType,
+		"preview", channels.Truncate(content, 50))
+
+	// Typing indicator while the agent works; streaming replaces it.
+	if _, err := c.connector.sendActivity(ctx, act.ServiceURL, replyConv, &activity{Type: "typing"}); err != nil {
+		slog.Debug("teams: typing indicator failed", "error", err)
+	}
+
+	finalContent := content
+	if peerKind == "group" {
+		annotated := fmt.Sprintf("[From: %s]\n%s", displayName, content)
+		if c.HistoryLimit() > 0 {
+			if histMedia := c.GroupHistory().CollectMedia(localKey); len(histMedia) > 0 {
+				mediaPaths = append(mediaPaths, histMedia...)
+			}
+			finalContent = c.GroupHistory().BuildContext(localKey, annotated, c.HistoryLimit())
+		} else {
+			finalContent = annotated
+		}
+	}
+
+	metadata := map[string]string{
+		"message_id":      act.ID,
+		"user_id":         senderID,
+		"username":        act.From.Name,
+		"display_name":    channels.SanitizeDisplayName(displayName),
+		"channel_id":      convID,
+		"is_dm":           fmt.Sprintf("%t", isDM),
+		"local_key":       localKey,
+		"placeholder_key": localKey,
+	}
+	if threadRoot != "" {
+		metadata["message_thread_id"] = threadRoot
+	}
+
+	c.HandleMessage(senderID, convID, finalContent, mediaPaths, metadata, peerKind)
+
+	if peerKind == "group" {
+		c.GroupHistory().Clear(localKey)
+	}
+}
+
+// isBotMentioned checks the activity's mention entities for the bot account.
+func (c *Channel) isBotMentioned(act *activity) bool {
+	for _, e := range act.Entities {
+		if e.Type == "mention" && e.Mentioned != nil && e.Mentioned.ID == act.Recipient.ID {
+			return true
+		}
+	}
+	return false
+}
+
+var atTagRe = regexp.MustCompile(`(?s)<at[^>]*>(.*?)</at>`)
+
+// cleanText removes the bot's own <at> mention, turns other mentions into
+// "@Name" and decodes the HTML entities Teams puts in message text.
+func cleanText(text string, entities []entity, botID string) string {
+	for _, e := range entities {
+		if e.Type == "mention" && e.Mentioned != nil && e.Mentioned.ID == botID && e.Text != "" {
+			text = strings.ReplaceAll(text, e.Text, "")
+		}
+	}
+	text = atTagRe.ReplaceAllString(text, "@$1")
+	return strings.TrimSpace(html.UnescapeString(text))
+}
+
+// downloadAttachments saves file and inline image attachments to temp files.
+// The agent loop persists them into the media store like any other upload.
+func (c *Channel) downloadAttachments(ctx context.Context, atts []attachment, serviceURL string) []media.MediaInfo {
+	var items []media.MediaInfo
+	for _, a := range atts {
+		var fileURL, name string
+		withToken := false
+		switch {
+		case a.ContentType == contentTypeFileDownload:
+			var info fileDownloadInfo
+			if err := json.Unmarshal(a.Content, &info); err != nil || info.DownloadURL == "" {
+				continue
+			}
+			fileURL, name = info.DownloadURL, a.Name
+		case strings.HasPrefix(a.ContentType, "image/") && a.ContentURL != "":
+			// Inline images hosted by the Teams service need the bot token; it is
+			// never sent to any other host.
+			fileURL, name, withToken = a.ContentURL, a.Name, sameHost(a.ContentURL, serviceURL)
+			if name == "" {
+				name = "image" + extensionFor(a.ContentType)
+			}
+		default:
+			continue // text/html mirrors of the message, cards, etc.
+		}
+		item, err := c.downloadFile(ctx, fileURL, name, withToken)
+		if err != nil {
+			slog.Warn("teams: attachment download failed", "name", name, "error", err)
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func (c *Channel) downloadFile(ctx context.Context, fileURL, name string, withToken bool) (media.MediaInfo, error) {
+	if err := checkDownloadURL(fileURL); err != nil {
+		return media.MediaInfo{}, err
+	}
+	name = filepath.Base(name)
+	mime := media.DetectMIMEType(name)
+	ext := filepath.Ext(name)
+	if ext == "" {
+		ext = ".dat"
+	}
+	tmp, err := os.CreateTemp("", "teams-file-*"+ext)
+	if err != nil {
+		return media.MediaInfo{}, fmt.Errorf("create temp file: %w", err)
+	}
+	defer tmp.Close()
+	if err := c.connector.download(ctx, fileURL, withToken, c.mediaMaxBytes, tmp); err != nil {
+		os.Remove(tmp.Name())
+		return media.MediaInfo{}, err
+	}
+	return media.MediaInfo{
+		Type:        media.MediaKindFromMime(mime),
+		FilePath:    tmp.Name(),
+		FileID:      fileURL,
+		ContentType: mime,
+		FileName:    name,
+	}, nil
+}
+
+func sameHost(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	return errA == nil && errB == nil && ua.Host != "" && strings.EqualFold(ua.Host, ub.Host)
+}
+
+func extensionFor(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	default:
+		return ".png"
+	}
+}
+
+// checkDMPolicy enforces DM policy for incoming messages.
+func (c *Channel) checkDMPolicy(ctx context.Context, senderID, replyConv, serviceURL string) bool {
+	switch c.CheckDMPolicy(ctx, senderID, c.config.DMPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, senderID, replyConv, serviceURL)
+		return false
+	default:
+		slog.Debug("teams DM rejected by policy", "sender_id", senderID, "policy", c.config.DMPolicy)
+		return false
+	}
+}
+
+// checkGroupPolicy enforces group chat/channel access policy; it does not check mention gating.
+func (c *Channel) checkGroupPolicy(ctx context.Context, senderID, convID, replyConv, serviceURL string) bool {
+	switch c.CheckGroupPolicy(ctx, senderID, convID, c.config.GroupPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, "group:"+convID, replyConv, serviceURL)
+		return false
+	default:
+		slog.Debug("teams group message rejected by policy", "conversation_id", convID, "policy", c.config.GroupPolicy)
+		return false
+	}
+}
+
+func (c *Channel) sendPairingReply(ctx context.Context, senderID, replyConv, serviceURL string) {
+	ps := c.PairingService()
+	if ps == nil || !c.CanSendPairingNotif(senderID, pairingDebounce) {
+		return
+	}
+	code, err := ps.RequestPairing(ctx, senderID, c.Name(), baseConversationID(replyConv), "default", nil)
+	if err != nil {
+		slog.Debug("teams pairing request failed", "sender_id", senderID, "error", err)
+		return
+	}
+	reply := fmt.Sprintf(
+		"GoClaw: access not configured.\n\nYour Teams ID: %s\n\nPairing code: %s\n\nAsk the bot owner to approve with:\n  goclaw pairing approve %s",
+		senderID, code, code,
+	)
+	if _, err := c.connector.sendActivity(ctx, serviceURL, replyConv, &activity{Type: "message", Text: reply}); err != nil {
+		slog.Warn("teams: failed to send pairing reply", "error", err)
+		return
+	}
+	c.MarkPairingNotifSent(senderID)
+	slog.Info("teams pairing reply sent", "sender_id", senderID, "code", code)
+}
@@ -0,0 +1,127 @@
+package teams
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/safego"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	webhookPath  = "/channels/teams/messages"
+	maxBodyBytes = 1 << 20 // 1 MB — activities are small; attachments are fetched by URL
+)
+
+// webhookRouter routes Bot Framework activities to the channel instance whose
+// app ID the token was issued for. A single handler is mounted on the gateway
+// mux and shared by all Teams instances, so every bot's messaging endpoint is
+// https://<gateway>/channels/teams/messages.
+type webhookRouter struct {
+	mu           sync.RWMutex
+	instances    map[string]*Channel // appID → channel
+	routeHandled bool                // true after first webhookRoute() call
+}
+
+var globalRouter = &webhookRouter{
+	instances: make(map[string]*Channel),
+}
+
+func (r *webhookRouter) register(ch *Channel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.instances[ch.config.AppID] = ch
+}
+
+func (r *webhookRouter) unregister(appID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.instances, appID)
+}
+
+// webhookRoute returns the path+handler for the first call; ("", nil) for subsequent calls.
+func (r *webhookRouter) webhookRoute() (string, http.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.routeHandled {
+		r.routeHandled = true
+		return webhookPath, r
+	}
+	return "", nil
+}
+
+func (r *webhookRouter) lookup(aud []string) *Channel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, appID := range aud {
+		if ch := r.instances[appID]; ch != nil {
+			return ch
+		}
+	}
+	return nil
+}
+
+// ServeHTTP authenticates the Bot Framework JWT, picks the target instance by
+// audience and acknowledges immediately: the connector retries deliveries that
+// are not answered within 15 seconds, so activities are processed async.
+func (r *webhookRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, err := botKeys.verify(req.Context(), req.Header.Get("Authorization"), time.Now())
+	if err != nil {
+		slog.Warn("security.teams_token_rejected", "error", err, "remote", req.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	ch := r.lookup(claims.Audience)
+	if ch == nil {
+		slog.Warn("security.teams_unknown_audience", "aud", strings.Join(claims.Audience, ","))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBodyBytes+1))
+	if err != nil || len(body) > maxBodyBytes {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+	var act activity
+	if err := json.Unmarshal(body, &act); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	// The token is bound to the service URL it was issued for; replies go to
+	// act.ServiceURL, so a mismatch could redirect the bot's credentials.
+	if !sameServiceURL(claims.ServiceURL, act.ServiceURL) {
+		slog.Warn("security.teams_service_url_mismatch", "claim", claims.ServiceURL, "activity", act.ServiceURL)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	go func() {
+		defer safego.Recover(nil, "component", "teams_activity")
+		ch.handleActivity(store.WithTenantID(context.Background(), ch.TenantID()), &act)
+	}()
+}
+
+func sameServiceURL(claim, actual string) bool {
+	norm := func(s string) string { return strings.ToLower(strings.TrimRight(s, "/")) }
+	return claim != "" && norm(claim) == norm(actual)
+}
+
+// tenantAllowed applies the allowed_tenants restriction (empty = any tenant).
+func (c *Channel) tenantAllowed(tenantID string) bool {
+	return len(c.config.AllowedTenants) == 0 || slices.Contains(c.config.AllowedTenants, tenantID)
+}
@@ -0,0 +1,91 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+var errNoStream = errors.New("teams: stream has no message to finish")
+
+// Send delivers an outbound message to a Teams conversation as Adaptive Card
+// activities. A streamed preview for the same run is replaced by the first chunk.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("teams bot not running")
+	}
+	convID := baseConversationID(extractConversationID(msg.ChatID))
+	if convID == "" {
+		return fmt.Errorf("empty chat ID for teams send")
+	}
+
+	placeholderKey := msg.ChatID
+	if pk := msg.Metadata["placeholder_key"]; pk != "" {
+		placeholderKey = pk
+	}
+	threadRoot := msg.Metadata["message_thread_id"]
+	if threadRoot == "" {
+		threadRoot = extractThreadRoot(msg.ChatID)
+	}
+	ref := c.conversation(convID)
+	target := threadConversationID(convID, threadRoot)
+
+	// No placeholder message exists (typing indicators stand in for it).
+	if msg.Metadata["placeholder_update"] == "true" {
+		return nil
+	}
+
+	var stream *teamsStream
+	if v, ok := c.streams.LoadAndDelete(placeholderKey); ok {
+		stream = v.(*teamsStream)
+	}
+
+	// Outbound files need the Teams file consent flow (personal chats) or
+	// Graph/SharePoint access (channels); neither is available to a plain bot.
+	for _, m := range msg.Media {
+		slog.Warn("teams: outbound media not supported, skipped", "file", filepath.Base(m.URL))
+	}
+
+	// NO_REPLY: retract the preview, return
+	if msg.Content == "" {
+		if stream != nil {
+			stream.discard(ctx)
+		}
+		return nil
+	}
+
+	chunks := channels.ChunkMarkdown(msg.Content, maxMessageLen)
+	if stream != nil && len(chunks) > 0 {
+		if err := stream.finish(ctx, chunks[0]); err == nil {
+			chunks = chunks[1:]
+		} else {
+			slog.Warn("teams stream finish failed, sending new message", "conversation_id", convID, "error", err)
+		}
+	}
+	for _, chunk := range chunks {
+		if _, err := c.connector.sendActivity(ctx, ref.serviceURL, target, cardActivity(chunk)); err != nil {
+			return fmt.Errorf("send teams message: %w", err)
+		}
+	}
+	return nil
+}
+
+// cardActivity builds a message activity carrying the Adaptive Card rendering.
+// Summary is what notifications and clients without card support show.
+func cardActivity(text string) *activity {
+	return &activity{
+		Type:        "message",
+		Summary:     channels.Truncate(text, 200),
+		Attachments: []attachment{cardAttachment(text)},
+	}
+}
+
+// textActivity builds a plain markdown message (streaming previews).
+func textActivity(text string) *activity {
+	return &activity{Type: "message", Text: text, TextFormat: "markdown"}
+}
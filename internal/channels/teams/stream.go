@@ -0,0 +1,170 @@
+package teams
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+// streamThrottleInterval bounds update frequency; Teams throttles streaming
+// updates to about one per second.
+const streamThrottleInterval = 1500 * time.Millisecond
+
+// teamsStream implements channels.ChannelStream. Personal chats use the Teams
+// streaming protocol (typing activities carrying a streaminfo entity, closed
+// by a "final" message); group chats and channels post one message and
+// update it in place.
+type teamsStream struct {
+	connector  *connector
+	serviceURL string
+	convID     string // thread-addressed for channel posts
+	personal   bool
+
+	streamID   string // personal: ID returned for the first streaming activity
+	seq        int
+	activityID string // group/channel: message being updated
+	failed     bool   // stop updating after an error; Send delivers normally
+	lastUpdate time.Time
+	lastText   string
+	mu         sync.Mutex
+}
+
+// Update sends the accumulated text as the next streaming chunk, throttled.
+func (s *teamsStream) Update(ctx context.Context, fullText string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failed || fullText == "" || fullText == s.lastText || time.Since(s.lastUpdate) < streamThrottleInterval {
+		return
+	}
+	text := fullText
+	if len(text) > maxMessageLen {
+		text = text[:maxMessageLen] + "..."
+	}
+
+	var err error
+	switch {
+	case s.personal:
+		s.seq++
+		info := streamInfo(s.streamID, "streaming", s.seq)
+		var id string
+		id, err = s.connector.sendActivity(ctx, s.serviceURL, s.convID, &activity{
+			Type:        "typing",
+			Text:        text,
+			Entities:    []entity{info},
+			ChannelData: &channelData{StreamID: s.streamID, StreamType: "streaming", StreamSequence: s.seq},
+		})
+		if err == nil && s.streamID == "" {
+			s.streamID = id
+		}
+	case s.activityID == "":
+		s.activityID, err = s.connector.sendActivity(ctx, s.serviceURL, s.convID, textActivity(text))
+	default:
+		err = s.connector.updateActivity(ctx, s.serviceURL, s.convID, s.activityID, textActivity(text))
+	}
+	if err != nil {
+		slog.Debug("teams stream update failed", "error", err)
+		s.failed = true
+		return
+	}
+	s.lastText = fullText
+	s.lastUpdate = time.Now()
+}
+
+// finish replaces the streamed preview with the final reply. Personal
+// streams are closed with a "final" message; elsewhere the message is
+// updated with the Adaptive Card rendering.
+func (s *teamsStream) finish(ctx context.Context, text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.personal {
+		if s.streamID == "" {
+			return errNoStream
+		}
+		act := textActivity(text)
+		act.Entities = []entity{streamInfo(s.streamID, "final", 0)}
+		act.ChannelData = &channelData{StreamID: s.streamID, StreamType: "final"}
+		_, err := s.connector.sendActivity(ctx, s.serviceURL, s.convID, act)
+		return err
+	}
+	if s.activityID == "" {
+		return errNoStream
+	}
+	return s.connector.updateActivity(ctx, s.serviceURL, s.convID, s.activityID, cardActivity(text))
+}
+
+// discard removes the preview when the run ends without a reply.
+func (s *teamsStream) discard(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case s.personal && s.streamID != "":
+		// An open stream must be closed or the client shows it as failed.
+		act := textActivity(s.lastText)
+		act.Entities = []entity{streamInfo(s.streamID, "final", 0)}
+		act.ChannelData = &channelData{StreamID: s.streamID, StreamType: "final"}
+		_, _ = s.connector.sendActivity(ctx, s.serviceURL, s.convID, act)
+	case !s.personal && s.activityID != "":
+		_ = s.connector.deleteActivity(ctx, s.serviceURL, s.convID, s.activityID)
+	}
+}
+
+func streamInfo(streamID, streamType string, seq int) entity {
+	return entity{Type: "streaminfo", StreamID: streamID, StreamType: streamType, StreamSequence: seq}
+}
+
+// Stop is a no-op: Send() finishes the stream via c.streams, which
+// FinalizeStream populates.
+func (s *teamsStream) Stop(_ context.Context) error {
+	return nil
+}
+
+// MessageID returns 0 — Teams activity IDs are strings.
+func (s *teamsStream) MessageID() int {
+	return 0
+}
+
+// started reports whether anything was shown to the user yet.
+func (s *teamsStream) started() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.streamID != "" || s.activityID != ""
+}
+
+// StreamEnabled reports whether streaming is active. Personal chats stream by
+// default (native Teams streaming); group chats and channels opt in, since
+// every update there is a message edit other members see.
+func (c *Channel) StreamEnabled(isGroup bool) bool {
+	if isGroup {
+		return c.config.GroupStream != nil && *c.config.GroupStream
+	}
+	return c.config.DMStream == nil || *c.config.DMStream
+}
+
+// CreateStream creates a per-run streaming handle for the given chatID (local_key).
+func (c *Channel) CreateStream(_ context.Context, chatID string, _ bool) (channels.ChannelStream, error) {
+	convID := baseConversationID(extractConversationID(chatID))
+	ref := c.conversation(convID)
+	return &teamsStream{
+		connector:  c.connector,
+		serviceURL: ref.serviceURL,
+		convID:     threadConversationID(convID, extractThreadRoot(chatID)),
+		personal:   ref.convType == "personal",
+	}, nil
+}
+
+// FinalizeStream hands a started stream to Send() through c.streams so the
+// final reply replaces the preview instead of being posted separately.
+func (c *Channel) FinalizeStream(_ context.Context, chatID string, stream channels.ChannelStream) {
+	ts, ok := stream.(*teamsStream)
+	if !ok || !ts.started() {
+		return
+	}
+	c.streams.Store(chatID, ts)
+}
+
+// ReasoningStreamEnabled returns false — reasoning is not shown as a separate message.
+func (c *Channel) ReasoningStreamEnabled() bool { return false }
@@ -0,0 +1,273 @@
+// Package teams implements a GoClaw channel for Microsoft Teams through the
+// Bot Framework: activities arrive on a webhook mounted on the gateway mux
+// (JWT-authenticated), replies go out as Adaptive Cards via the Connector API,
+// and streaming uses Teams' streaming protocol in personal chats and
+// update-activity elsewhere.
+package teams
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/safego"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	maxMessageLen        = 12000 // Adaptive Card payloads are capped around 28 KB
+	defaultMediaMaxBytes = int64(20 * 1024 * 1024)
+	pairingDebounce      = 60 * time.Second
+	dedupTTL             = 10 * time.Minute
+)
+
+// defaultServiceURL is used for conversations the bot has not heard from since
+// start (e.g. cron deliveries after a restart). It is the global Teams endpoint.
+var defaultServiceURL = "https://smba.trafficmanager.net/teams/"
+
+// Channel is one Azure bot registration connected to Microsoft Teams.
+type Channel struct {
+	*channels.BaseChannel
+	connector     *connector
+	config        teamsInstanceConfig
+	mediaMaxBytes int64
+
+	convs   sync.Map // base conversation ID -> convRef
+	dedup   sync.Map // activity ID -> time.Time
+	streams sync.Map // local_key -> *teamsStream (finalized, awaiting Send)
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// convRef is what outbound calls need to reach a conversation.
+type convRef struct {
+	serviceURL string
+	convType   string // "personal", "groupChat", "channel"
+	tenantID   string
+}
+
+// Compile-time interface assertions.
+var _ channels.Channel = (*Channel)(nil)
+var _ channels.WebhookChannel = (*Channel)(nil)
+var _ channels.StreamingChannel = (*Channel)(nil)
+var _ channels.GroupMemberProvider = (*Channel)(nil)
+var _ channels.BlockReplyChannel = (*Channel)(nil)
+
+// New creates a new Teams channel from instance config and credentials.
+func New(cfg teamsInstanceConfig, creds teamsCreds, msgBus *bus.MessageBus, pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (*Channel, error) {
+	if cfg.AppID == "" {
+		return nil, fmt.Errorf("teams app_id is required")
+	}
+	if creds.AppPassword == "" {
+		return nil, fmt.Errorf("teams app_password is required")
+	}
+	if cfg.AppTenantID == "" {
+		cfg.AppTenantID = "botframework.com"
+	}
+
+	base := channels.NewBaseChannel(channels.TypeTeams, msgBus, cfg.AllowFrom)
+	base.ValidatePolicy(cfg.DMPolicy, cfg.GroupPolicy)
+
+	requireMention := true
+	if cfg.RequireMention != nil {
+		requireMention = *cfg.RequireMention
+	}
+	historyLimit := cfg.HistoryLimit
+	if historyLimit == 0 {
+		historyLimit = channels.DefaultGroupHistoryLimit
+	}
+	mediaMax := int64(cfg.MediaMaxMB) * 1024 * 1024
+	if mediaMax <= 0 {
+		mediaMax = defaultMediaMaxBytes
+	}
+
+	ch := &Channel{
+		BaseChannel:   base,
+		connector:     newConnector(cfg.AppID, creds.AppPassword, cfg.AppTenantID),
+		config:        cfg,
+		mediaMaxBytes: mediaMax,
+	}
+	ch.SetRequireMention(requireMention)
+	ch.SetPairingService(pairingSvc)
+	ch.SetGroupHistory(channels.MakeHistory(channels.TypeTeams, pendingStore, base.TenantID()))
+	ch.SetHistoryLimit(historyLimit)
+	return ch, nil
+}
+
+// Start validates the app credentials by obtaining a Connector token, then
+// registers the instance with the shared webhook router.
+func (c *Channel) Start(ctx context.Context) error {
+	c.GroupHistory().StartFlusher()
+	c.MarkStarting("obtaining bot token")
+
+	if _, err := c.connector.tokens.Token(ctx); err != nil {
+		kind := channels.ChannelFailureKindNetwork
+		if isAuthError(err) {
+			kind = channels.ChannelFailureKindAuth
+		}
+		c.MarkFailed("token request failed", err.Error(), kind, kind != channels.ChannelFailureKindAuth)
+		return fmt.Errorf("teams token request failed: %w", err)
+	}
+
+	c.stopCh = make(chan struct{})
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer safego.Recover(nil, "component", "teams_sweep")
+		ticker := time.NewTicker(2 * time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stopCh:
+				return
+			case <-ticker.C:
+				c.sweepMaps()
+			}
+		}
+	}()
+
+	globalRouter.register(c)
+	c.SetRunning(true)
+	c.MarkHealthy("webhook " + webhookPath)
+	slog.Info("teams bot started", "app_id", c.config.AppID, "path", webhookPath)
+	return nil
+}
+
+// Stop unregisters the instance from the webhook router.
+func (c *Channel) Stop(_ context.Context) error {
+	c.GroupHistory().StopFlusher()
+	slog.Info("stopping teams bot", "app_id", c.config.AppID)
+	globalRouter.unregister(c.config.AppID)
+	c.SetRunning(false)
+	if c.stopCh != nil {
+		close(c.stopCh)
+		c.wg.Wait()
+		c.stopCh = nil
+	}
+	c.MarkStopped("stopped")
+	return nil
+}
+
+// WebhookHandler returns the shared webhook path and the global router as handler.
+// Only the first instance returns a route; the rest share it.
+func (c *Channel) WebhookHandler() (string, http.Handler) {
+	return globalRouter.webhookRoute()
+}
+
+// sweepMaps performs age-based eviction of the dedup cache.
+func (c *Channel) sweepMaps() {
+	now := time.Now()
+	c.dedup.Range(func(k, v any) bool {
+		if t, ok := v.(time.Time); ok && now.Sub(t) > dedupTTL {
+			c.dedup.Delete(k)
+		}
+		return true
+	})
+}
+
+// conversation returns the reference for a base conversation ID. Unknown
+// conversations fall back to the global service URL; the type is inferred
+// from the ID shape ("a:…" personal, "19:…@thread…" group chat or channel).
+func (c *Channel) conversation(convID string) convRef {
+	if v, ok := c.convs.Load(convID); ok {
+		return v.(convRef)
+	}
+	ref := convRef{serviceURL: defaultServiceURL, convType: "personal"}
+	if strings.HasPrefix(convID, "19:") {
+		ref.convType = "groupChat"
+	}
+	return ref
+}
+
+// SetPendingCompaction configures LLM-based auto-compaction for pending messages.
+func (c *Channel) SetPendingCompaction(cfg *channels.CompactionConfig) {
+	if gh := c.GroupHistory(); gh != nil {
+		gh.SetCompactionConfig(cfg)
+	}
+}
+
+// SetPendingHistoryTenantID propagates tenant_id to the pending history for DB operations.
+func (c *Channel) SetPendingHistoryTenantID(id uuid.UUID) {
+	if gh := c.GroupHistory(); gh != nil {
+		gh.SetTenantID(id)
+	}
+}
+
+// BlockReplyEnabled returns the per-channel block_reply override (nil = inherit gateway default).
+func (c *Channel) BlockReplyEnabled() *bool { return c.config.BlockReply }
+
+// ChatBehaviorConfig returns the per-channel chat_behavior override.
+func (c *Channel) ChatBehaviorConfig() *config.ChatBehaviorConfig { return c.config.ChatBehavior }
+
+// ListGroupMembers returns the conversation roster (team roster for channels),
+// excluding the bot itself.
+func (c *Channel) ListGroupMembers(ctx context.Context, chatID string) ([]channels.GroupMember, error) {
+	convID := baseConversationID(extractConversationID(chatID))
+	ref := c.conversation(convID)
+	members, err := c.connector.members(ctx, ref.serviceURL, convID)
+	if err != nil {
+		slog.Warn("teams.list_group_members", "conversation_id", convID, "error", err)
+		return nil, err
+	}
+	result := make([]channels.GroupMember, 0, len(members))
+	for _, m := range members {
+		if strings.HasSuffix(m.ID, ":"+c.config.AppID) {
+			continue // bot accounts are "28:<app id>"
+		}
+		id := m.AADObjectID
+		if id == "" {
+			id = m.ID
+		}
+		result = append(result, channels.GroupMember{MemberID: id, Name: m.Name})
+		if cc := c.ContactCollector(); cc != nil {
+			username := m.UserPrincipalName
+			if username == "" {
+				username = m.Email
+			}
+			cc.EnsureContact(ctx, channels.TypeTeams, c.Name(), id, id, m.Name, username, "group", "user", "", "")
+		}
+	}
+	return result, nil
+}
+
+// extractConversationID gets the conversation ID from a local_key ("19:…:thread:<root>").
+func extractConversationID(localKey string) string {
+	if idx := strings.Index(localKey, ":thread:"); idx > 0 {
+		return localKey[:idx]
+	}
+	return localKey
+}
+
+// extractThreadRoot gets the channel thread root message ID from a local_key, or "".
+func extractThreadRoot(localKey string) string {
+	const marker = ":thread:"
+	if idx := strings.Index(localKey, marker); idx > 0 {
+		return localKey[idx+len(marker):]
+	}
+	return ""
+}
+
+// baseConversationID strips the ";messageid=" thread suffix Teams appends to
+// channel conversation IDs.
+func baseConversationID(convID string) string {
+	base, _, _ := strings.Cut(convID, ";messageid=")
+	return base
+}
+
+// threadConversationID addresses a reply to a channel thread.
+func threadConversationID(convID, root string) string {
+	if root == "" {
+		return convID
+	}
+	return convID + ";messageid=" + root
+}
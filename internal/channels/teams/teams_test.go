@@ -0,0 +1,465 @@
+package teams
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+const (
+	appID   = "11111111-2222-3333-4444-555555555555"
+	botAcct = "28:" + appID
+	dmConv  = "a:1dm"
+	chanID  = "19:general@thread.tacv2"
+	chatID  = "19:meeting@thread.v2"
+)
+
+type recordedCall struct {
+	Method string
+	Path   string
+	Body   activity
+}
+
+// fakeBotFramework serves the OpenID metadata, JWKS, token endpoint and the
+// Connector API from one test server.
+type fakeBotFramework struct {
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	calls []recordedCall
+	seq   int
+}
+
+func newFakeBotFramework(t *testing.T) *fakeBotFramework {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeBotFramework{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openid", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"jwks_uri": f.srv.URL + "/keys"})
+	})
+	mux.HandleFunc("GET /keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /{tenant}/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"bad secret"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"bot-token","expires_in":3600}`))
+	})
+	mux.HandleFunc("GET /v3/conversations/{conv}/members", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id":"29:alice","name":"Alice","aadObjectId":"aad-alice","userPrincipalName":"alice@contoso.com"},
+			{"id":"` + botAcct + `","name":"GoClaw"}]`))
+	})
+	mux.HandleFunc("GET /files/report.txt", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Error("bot token leaked to pre-authenticated download URL")
+		}
+		_, _ = w.Write([]byte("quarterly numbers"))
+	})
+	mux.HandleFunc("/v3/conversations/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer bot-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var act activity
+		_ = json.NewDecoder(r.Body).Decode(&act)
+		f.mu.Lock()
+		f.calls = append(f.calls, recordedCall{Method: r.Method, Path: r.URL.EscapedPath(), Body: act})
+		f.seq++
+		id := "act" + strconv.Itoa(f.seq)
+		f.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(resourceResponse{ID: id})
+	})
+	f.srv = httptest.NewServer(mux)
+	t.Cleanup(f.srv.Close)
+
+	oldMeta, oldToken, oldKeys, oldCheck := openIDMetadataURL, tokenURLTemplate, botKeys, checkDownloadURL
+	openIDMetadataURL = f.srv.URL + "/openid"
+	tokenURLTemplate = f.srv.URL + "/%s/token"
+	botKeys = &keySet{http: f.srv.Client()}
+	checkDownloadURL = func(string) error { return nil }
+	t.Cleanup(func() {
+		openIDMetadataURL, tokenURLTemplate, botKeys, checkDownloadURL = oldMeta, oldToken, oldKeys, oldCheck
+	})
+	return f
+}
+
+// sign issues a channel → bot token like the Bot Framework connector does.
+func (f *fakeBotFramework) sign(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	enc := func(v any) string {
+		b, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signing := enc(map[string]string{"alg": "RS256", "kid": "k1", "typ": "JWT"}) + "." + enc(claims)
+	digest := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, f.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signing + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func (f *fakeBotFramework) validClaims() map[string]any {
+	return map[string]any{
+		"iss":        botFrameworkIssuer,
+		"aud":        appID,
+		"exp":        time.Now().Add(time.Hour).Unix(),
+		"nbf":        time.Now().Add(-time.Minute).Unix(),
+		"serviceurl": f.srv.URL + "/",
+	}
+}
+
+func (f *fakeBotFramework) callsSnapshot() []recordedCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]recordedCall(nil), f.calls...)
+}
+
+// waitCall waits for a connector call matching pred.
+func (f *fakeBotFramework) waitCall(t *testing.T, pred func(recordedCall) bool) recordedCall {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, c := range f.callsSnapshot() {
+			if pred(c) {
+				return c
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected connector call not made; got %+v", f.callsSnapshot())
+	return recordedCall{}
+}
+
+func newTestChannel(t *testing.T, f *fakeBotFramework, extra string) (*Channel, *bus.MessageBus) {
+	t.Helper()
+	mb := bus.New()
+	cfg := `{"app_id":"` + appID + `","dm_policy":"open","group_policy":"open"` + extra + `}`
+	ch, err := Factory("teams", json.RawMessage(`{"app_password":"secret"}`), json.RawMessage(cfg), mb, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := ch.(*Channel)
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Stop(context.Background()) })
+	return c, mb
+}
+
+// post delivers an activity through the shared webhook router.
+func (f *fakeBotFramework) post(t *testing.T, token string, act map[string]any) int {
+	t.Helper()
+	if _, ok := act["serviceUrl"]; !ok {
+		act["serviceUrl"] = f.srv.URL + "/"
+	}
+	body, _ := json.Marshal(act)
+	req := httptest.NewRequest(http.MethodPost, webhookPath, strings.NewReader(string(body)))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	globalRouter.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func nextInbound(t *testing.T, mb *bus.MessageBus) bus.InboundMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	msg, ok := mb.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("no inbound message published")
+	}
+	return msg
+}
+
+func messageActivity(id, convID, convType, text string) map[string]any {
+	return map[string]any{
+		"type": "message", "id": id, "channelId": "msteams", "text": text,
+		"from":         map[string]any{"id": "29:alice", "name": "Alice", "aadObjectId": "aad-alice"},
+		"recipient":    map[string]any{"id": botAcct, "name": "GoClaw"},
+		"conversation": map[string]any{"id": convID, "conversationType": convType, "tenantId": "contoso"},
+	}
+}
+
+func cardText(t *testing.T, act activity) string {
+	t.Helper()
+	if len(act.Attachments) != 1 || act.Attachments[0].ContentType != contentTypeAdaptiveCard {
+		t.Fatalf("expected one adaptive card attachment, got %+v", act.Attachments)
+	}
+	return string(act.Attachments[0].Content)
+}
+
+func TestPersonalMessageRoundTrip(t *testing.T) {
+	f := newFakeBotFramework(t)
+	ch, mb := newTestChannel(t, f, "")
+
+	act := messageActivity("m1", dmConv, "personal", "hello &amp; welcome")
+	act["attachments"] = []map[string]any{{
+		"contentType": contentTypeFileDownload, "name": "report.txt",
+		"content": map[string]string{"downloadUrl": f.srv.URL + "/files/report.txt", "fileType": "txt"},
+	}}
+	if code := f.post(t, f.sign(t, f.validClaims()), act); code != http.StatusOK {
+		t.Fatalf("webhook status = %d", code)
+	}
+
+	in := nextInbound(t, mb)
+	if in.ChatID != dmConv || in.SenderID != "aad-alice" || in.PeerKind != "direct" {
+		t.Fatalf("unexpected routing: chat=%q sender=%q peer=%q", in.ChatID, in.SenderID, in.PeerKind)
+	}
+	if !strings.Contains(in.Content, "hello & welcome") || len(in.Media) != 1 {
+		t.Fatalf("unexpected content/media: %q %+v", in.Content, in.Media)
+	}
+	if in.Metadata["local_key"] != dmConv || in.Metadata["display_name"] != "Alice" {
+		t.Fatalf("unexpected metadata: %+v", in.Metadata)
+	}
+	f.waitCall(t, func(c recordedCall) bool { return c.Body.Type == "typing" })
+
+	// Connector retries the same activity: processed once.
+	f.post(t, f.sign(t, f.validClaims()), messageActivity("m1", dmConv, "personal", "hello"))
+
+	if err := ch.Send(context.Background(), bus.OutboundMessage{
+		ChatID: dmConv, Content: "# Title\n\nSome **bold** text", Metadata: in.Metadata,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sent := f.waitCall(t, func(c recordedCall) bool { return c.Body.Type == "message" })
+	if sent.Method != http.MethodPost || sent.Path != "/v3/conversations/a:1dm/activities" {
+		t.Fatalf("unexpected send: %s %s", sent.Method, sent.Path)
+	}
+	if card := cardText(t, sent.Body); !strings.Contains(card, `"Some **bold** text"`) || !strings.Contains(card, `"weight":"Bolder"`) {
+		t.Fatalf("unexpected card: %s", card)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if extra, ok := mb.ConsumeInbound(ctx); ok {
+		t.Fatalf("duplicate delivery published: %+v", extra)
+	}
+}
+
+func TestChannelThreadMentionGating(t *testing.T) {
+	f := newFakeBotFramework(t)
+	ch, mb := newTestChannel(t, f, "")
+
+	// A new post without a mention is kept as pending history only.
+	f.post(t, f.sign(t, f.validClaims()), messageActivity("100", chanID, "channel", "numbers are up"))
+
+	act := messageActivity("101", chanID+";messageid=100", "channel", "<at>GoClaw</at> summarize please")
+	act["entities"] = []map[string]any{{
+		"type": "mention", "text": "<at>GoClaw</at>",
+		"mentioned": map[string]string{"id": botAcct, "name": "GoClaw"},
+	}}
+	f.post(t, f.sign(t, f.validClaims()), act)
+
+	in := nextInbound(t, mb)
+	if in.PeerKind != "group" || in.ChatID != chanID {
+		t.Fatalf("unexpected routing: peer=%q chat=%q", in.PeerKind, in.ChatID)
+	}
+	if in.Metadata["local_key"] != chanID+":thread:100" || in.Metadata["message_thread_id"] != "100" {
+		t.Fatalf("unexpected thread metadata: %+v", in.Metadata)
+	}
+	if !strings.Contains(in.Content, "numbers are up") || !strings.Contains(in.Content, "[From: Alice]\nsummarize please") {
+		t.Fatalf("expected pending history and stripped mention, got %q", in.Content)
+	}
+
+	if err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: chanID, Content: "Done", Metadata: in.Metadata}); err != nil {
+		t.Fatal(err)
+	}
+	sent := f.waitCall(t, func(c recordedCall) bool { return c.Body.Type == "message" })
+	if sent.Path != "/v3/conversations/19:general@thread.tacv2%3Bmessageid=100/activities" {
+		t.Fatalf("reply not addressed to the thread: %s", sent.Path)
+	}
+}
+
+func TestWebhookRejectsInvalidTokens(t *testing.T) {
+	f := newFakeBotFramework(t)
+	_, mb := newTestChannel(t, f, "")
+	act := func() map[string]any { return messageActivity("x", dmConv, "personal", "hi") }
+
+	cases := map[string]func(map[string]any){
+		"wrong issuer":  func(c map[string]any) { c["iss"] = "https://evil.example" },
+		"wrong app":     func(c map[string]any) { c["aud"] = "someone-else" },
+		"expired":       func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"service url":   func(c map[string]any) { c["serviceurl"] = "https://evil.example/" },
+		"not yet valid": func(c map[string]any) { c["nbf"] = time.Now().Add(time.Hour).Unix() },
+	}
+	for name, mutate := range cases {
+		claims := f.validClaims()
+		mutate(claims)
+		if code := f.post(t, f.sign(t, claims), act()); code != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", name, code)
+		}
+	}
+	if code := f.post(t, "", act()); code != http.StatusUnauthorized {
+		t.Errorf("missing token: status = %d", code)
+	}
+	tampered := f.sign(t, f.validClaims())
+	tampered = tampered[:len(tampered)-4] + "AAAA"
+	if code := f.post(t, tampered, act()); code != http.StatusUnauthorized {
+		t.Errorf("bad signature: status = %d", code)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if msg, ok := mb.ConsumeInbound(ctx); ok {
+		t.Fatalf("rejected request was published: %+v", msg)
+	}
+}
+
+func TestAllowedTenants(t *testing.T) {
+	f := newFakeBotFramework(t)
+	_, mb := newTestChannel(t, f, `,"allowed_tenants":["fabrikam"]`)
+	f.post(t, f.sign(t, f.validClaims()), messageActivity("t1", dmConv, "personal", "hi"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if msg, ok := mb.ConsumeInbound(ctx); ok {
+		t.Fatalf("message from disallowed tenant published: %+v", msg)
+	}
+}
+
+func TestStreamingPersonalAndGroup(t *testing.T) {
+	f := newFakeBotFramework(t)
+	ch, _ := newTestChannel(t, f, "")
+	ctx := context.Background()
+	ch.convs.Store(dmConv, convRef{serviceURL: f.srv.URL, convType: "personal"})
+	ch.convs.Store(chatID, convRef{serviceURL: f.srv.URL, convType: "groupChat"})
+
+	if !ch.StreamEnabled(false) || ch.StreamEnabled(true) {
+		t.Fatal("default streaming should be on for personal chats only")
+	}
+
+	// Personal: streaming protocol, closed by a final message.
+	s, _ := ch.CreateStream(ctx, dmConv, true)
+	s.Update(ctx, "Partial answer")
+	first := f.waitCall(t, func(c recordedCall) bool { return c.Body.Type == "typing" && c.Body.Text != "" })
+	if len(first.Body.Entities) != 1 || first.Body.Entities[0].StreamType != "streaming" || first.Body.Entities[0].StreamSequence != 1 {
+		t.Fatalf("unexpected streaminfo: %+v", first.Body.Entities)
+	}
+	ch.FinalizeStream(ctx, dmConv, s)
+	if err := ch.Send(ctx, bus.OutboundMessage{ChatID: dmConv, Content: "Full answer"}); err != nil {
+		t.Fatal(err)
+	}
+	final := f.waitCall(t, func(c recordedCall) bool { return c.Body.Type == "message" })
+	if final.Body.Text != "Full answer" || len(final.Body.Entities) != 1 ||
+		final.Body.Entities[0].StreamType != "final" || final.Body.Entities[0].StreamID == "" {
+		t.Fatalf("unexpected final stream message: %+v", final.Body)
+	}
+
+	// Group chat: post, then update in place with the card.
+	s, _ = ch.CreateStream(ctx, chatID, true)
+	s.Update(ctx, "Working")
+	f.waitCall(t, func(c recordedCall) bool {
+		return c.Method == http.MethodPost && strings.Contains(c.Path, "meeting") && c.Body.Text == "Working"
+	})
+	ch.FinalizeStream(ctx, chatID, s)
+	if err := ch.Send(ctx, bus.OutboundMessage{ChatID: chatID, Content: "Finished"}); err != nil {
+		t.Fatal(err)
+	}
+	put := f.waitCall(t, func(c recordedCall) bool { return c.Method == http.MethodPut })
+	if !strings.HasSuffix(put.Path, "/activities/"+put.Body.ID) || !strings.Contains(cardText(t, put.Body), "Finished") {
+		t.Fatalf("unexpected update: %s %+v", put.Path, put.Body)
+	}
+}
+
+func TestListGroupMembers(t *testing.T) {
+	f := newFakeBotFramework(t)
+	ch, _ := newTestChannel(t, f, "")
+	ch.convs.Store(chanID, convRef{serviceURL: f.srv.URL, convType: "channel"})
+
+	members, err := ch.ListGroupMembers(context.Background(), chanID+":thread:100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 1 || members[0].MemberID != "aad-alice" || members[0].Name != "Alice" {
+		t.Fatalf("unexpected members: %+v", members)
+	}
+}
+
+func TestStartRejectsBadSecret(t *testing.T) {
+	newFakeBotFramework(t)
+	ch, err := New(teamsInstanceConfig{AppID: appID}, teamsCreds{AppPassword: "wrong"}, bus.New(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ch.Start(context.Background()); err == nil {
+		t.Fatal("expected start to fail with a bad client secret")
+	}
+	if snap := ch.HealthSnapshot(); snap.FailureKind != channels.ChannelFailureKindAuth {
+		t.Fatalf("expected auth failure, got %+v", snap)
+	}
+}
+
+func TestFactoryValidatesConfig(t *testing.T) {
+	if _, err := Factory("teams", json.RawMessage(`{"app_password":"s"}`), json.RawMessage(`{}`), bus.New(), nil); err == nil {
+		t.Fatal("expected error without app_id")
+	}
+	if _, err := Factory("teams", json.RawMessage(`{}`), json.RawMessage(`{"app_id":"x"}`), bus.New(), nil); err == nil {
+		t.Fatal("expected error without app_password")
+	}
+	ch, err := Factory("teams", json.RawMessage(`{"app_password":"s"}`), json.RawMessage(`{"app_id":"x"}`), bus.New(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := ch.(*Channel)
+	if c.config.GroupPolicy != "pairing" || c.config.AppTenantID != "botframework.com" || !c.RequireMention() {
+		t.Fatalf("unexpected defaults: %+v", c.config)
+	}
+}
+
+func TestBuildAdaptiveCard(t *testing.T) {
+	md := "## Plan\n\n- one\n- two\n\n```go\nx := **y\n```\n\n| A | B |\n|---|---|\n| 1 | 2 |\n\n---\n> quoted"
+	body := cardBody(md)
+	types := make([]string, len(body))
+	for i, el := range body {
+		types[i] = el["type"].(string)
+	}
+	want := []string{"TextBlock", "TextBlock", "Container", "Table", "Container"}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("element types = %v, want %v", types, want)
+	}
+	if body[0]["size"] != "Medium" || body[1]["text"] != "- one\n- two" {
+		t.Fatalf("unexpected heading/list: %+v %+v", body[0], body[1])
+	}
+	code, _ := json.Marshal(body[2])
+	if !strings.Contains(string(code), `"text":"x := **y"`) || !strings.Contains(string(code), "Monospace") {
+		t.Fatalf("code block not rendered verbatim: %s", code)
+	}
+	if rows := body[3]["rows"].([]map[string]any); len(rows) != 2 {
+		t.Fatalf("table rows = %d", len(rows))
+	}
+	if body[4]["separator"] != true {
+		t.Fatal("horizontal rule should separate the next element")
+	}
+}
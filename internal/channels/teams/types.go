@@ -0,0 +1,77 @@
+package teams
+
+import "encoding/json"
+
+// activity is the Bot Framework activity envelope (only the fields GoClaw uses).
+type activity struct {
+	Type         string               `json:"type"`
+	ID           string               `json:"id,omitempty"`
+	Timestamp    string               `json:"timestamp,omitempty"`
+	ServiceURL   string               `json:"serviceUrl,omitempty"`
+	ChannelID    string               `json:"channelId,omitempty"`
+	From         *channelAccount      `json:"from,omitempty"`
+	Conversation *conversationAccount `json:"conversation,omitempty"`
+	Recipient    *channelAccount      `json:"recipient,omitempty"`
+	Text         string               `json:"text,omitempty"`
+	TextFormat   string               `json:"textFormat,omitempty"`
+	Summary      string               `json:"summary,omitempty"`
+	ReplyToID    string               `json:"replyToId,omitempty"`
+	Attachments  []attachment         `json:"attachments,omitempty"`
+	Entities     []entity             `json:"entities,omitempty"`
+	ChannelData  *channelData         `json:"channelData,omitempty"`
+	MembersAdded []channelAccount     `json:"membersAdded,omitempty"`
+}
+
+type channelAccount struct {
+	ID                string `json:"id"`
+	Name              string `json:"name,omitempty"`
+	AADObjectID       string `json:"aadObjectId,omitempty"`
+	Email             string `json:"email,omitempty"`             // roster API only
+	UserPrincipalName string `json:"userPrincipalName,omitempty"` // roster API only
+}
+
+type conversationAccount struct {
+	ID               string `json:"id"`
+	Name             string `json:"name,omitempty"`
+	ConversationType string `json:"conversationType,omitempty"` // "personal", "groupChat", "channel"
+	TenantID         string `json:"tenantId,omitempty"`
+	IsGroup          bool   `json:"isGroup,omitempty"`
+}
+
+type attachment struct {
+	ContentType string          `json:"contentType"`
+	ContentURL  string          `json:"contentUrl,omitempty"`
+	Content     json.RawMessage `json:"content,omitempty"`
+	Name        string          `json:"name,omitempty"`
+}
+
+// entity covers the two entity kinds GoClaw reads or writes: "mention" and
+// "streaminfo" (Teams streaming protocol).
+type entity struct {
+	Type           string          `json:"type"`
+	Mentioned      *channelAccount `json:"mentioned,omitempty"`
+	Text           string          `json:"text,omitempty"`
+	StreamID       string          `json:"streamId,omitempty"`
+	StreamType     string          `json:"streamType,omitempty"`
+	StreamSequence int             `json:"streamSequence,omitempty"`
+}
+
+type channelData struct {
+	Tenant *struct {
+		ID string `json:"id"`
+	} `json:"tenant,omitempty"`
+	StreamID       string `json:"streamId,omitempty"`
+	StreamType     string `json:"streamType,omitempty"`
+	StreamSequence int    `json:"streamSequence,omitempty"`
+}
+
+// fileDownloadInfo is the content of a Teams file attachment in personal chats.
+type fileDownloadInfo struct {
+	DownloadURL string `json:"downloadUrl"`
+	FileType    string `json:"fileType"`
+}
+
+const (
+	contentTypeAdaptiveCard = "application/vnd.microsoft.card.adaptive"
+	contentTypeFileDownload = "application/vnd.microsoft.teams.file.download.info"
+)
@@ -0,0 +1,142 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/tools"
+)
+
+// actionCallbackPrefix marks callback_data produced from bus.MessageAction
+// (as opposed to the "td:"/"sa:" buttons of the built-in commands).
+const actionCallbackPrefix = "act:"
+
+// actionPromptText carries the keyboard when there is no text message to attach it to.
+const actionPromptText = "Choose an option:"
+
+// actionsPerRow keeps up to three buttons side by side; longer lists stack.
+const actionsPerRow = 3
+
+// SupportsActions reports that actions render as inline keyboards.
+func (c *Channel) SupportsActions() bool { return true }
+
+// actionKeyboard renders actions as an inline keyboard, or nil without actions.
+func actionKeyboard(actions []bus.MessageAction) *telego.InlineKeyboardMarkup {
+	if len(actions) == 0 {
+		return nil
+	}
+	var rows [][]telego.InlineKeyboardButton
+	for _, row := range channels.ActionRows(actions, actionsPerRow) {
+		buttons := make([]telego.InlineKeyboardButton, 0, len(row))
+		for _, a := range row {
+			btn := tu.InlineKeyboardButton(a.Label)
+			if a.URL != "" {
+				btn = btn.WithURL(a.URL)
+			} else {
+				btn = btn.WithCallbackData(actionCallbackPrefix + a.ID)
+			}
+			btn.Style = a.Style // Telegram accepts "primary" and "danger" as-is
+			buttons = append(buttons, btn)
+		}
+		rows = append(rows, buttons)
+	}
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// setKeyboard attaches an inline keyboard to an already sent message.
+func (c *Channel) setKeyboard(ctx context.Context, chatID int64, messageID int, keyboard *telego.InlineKeyboardMarkup) error {
+	return c.retrySend(ctx, "editMessageReplyMarkup", nil, func(ctx context.Context) error {
+		_, err := c.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+			ChatID:      tu.ID(chatID),
+			MessageID:   messageID,
+			ReplyMarkup: keyboard,
+		})
+		if err != nil && messageNotModifiedRe.MatchString(err.Error()) {
+			return nil
+		}
+		return err
+	})
+}
+
+// handleActionCallback turns a click on an action button into an inbound
+// message. The callback query has already been answered by the caller.
+func (c *Channel) handleActionCallback(ctx context.Context, query *telego.CallbackQuery) {
+	actionID := strings.TrimPrefix(query.Data, actionCallbackPrefix)
+	if actionID == "" || query.Message == nil {
+		return
+	}
+	msg := query.Message.Message()
+	if msg == nil {
+		return // buttons on messages older than 48h arrive inaccessible
+	}
+
+	chatIDStr := fmt.Sprintf("%d", msg.Chat.ID)
+	senderID := fmt.Sprintf("%d", query.From.ID)
+	isGroup := msg.Chat.Type == "group" || msg.Chat.Type == "supergroup"
+	peerKind := "direct"
+	if isGroup {
+		peerKind = "group"
+	}
+	if !c.ActionAllowed(ctx, senderID, chatIDStr, peerKind, c.config.DMPolicy, c.config.GroupPolicy) {
+		slog.Debug("telegram action rejected by policy", "sender_id", senderID, "chat_id", chatIDStr)
+		return
+	}
+
+	// Same topic/thread routing as a message posted next to the buttons.
+	localKey := chatIDStr
+	metadata := map[string]string{
+		"message_id":       fmt.Sprintf("%d:%s", msg.MessageID, actionID),
+		"user_id":          senderID,
+		tools.MetaUsername: query.From.Username,
+		"first_name":       query.From.FirstName,
+		"is_group":         fmt.Sprintf("%t", isGroup),
+	}
+	switch {
+	case isGroup && msg.Chat.IsForum:
+		topicID := msg.MessageThreadID
+		if topicID == 0 {
+			topicID = telegramGeneralTopicID
+		}
+		localKey = fmt.Sprintf("%s:topic:%d", chatIDStr, topicID)
+		metadata[tools.MetaIsForum] = "true"
+		metadata[tools.MetaMessageThreadID] = fmt.Sprintf("%d", topicID)
+	case !isGroup && msg.MessageThreadID > 0:
+		localKey = fmt.Sprintf("%s:thread:%d", chatIDStr, msg.MessageThreadID)
+		metadata[tools.MetaDMThreadID] = fmt.Sprintf("%d", msg.MessageThreadID)
+		metadata[tools.MetaMessageThreadID] = fmt.Sprintf("%d", msg.MessageThreadID)
+	}
+	metadata["local_key"] = localKey
+
+	displayName := strings.TrimSpace(query.From.FirstName + " " + query.From.LastName)
+	if displayName == "" {
+		displayName = query.From.Username
+	}
+
+	c.HandleAction(senderID, chatIDStr, displayName, bus.ActionCallback{
+		ActionID:  actionID,
+		Label:     buttonLabel(msg.ReplyMarkup, query.Data),
+		MessageID: fmt.Sprintf("%d", msg.MessageID),
+	}, metadata, peerKind)
+}
+
+// buttonLabel finds the text of the clicked button on the original message.
+func buttonLabel(markup *telego.InlineKeyboardMarkup, data string) string {
+	if markup == nil {
+		return ""
+	}
+	for _, row := range markup.InlineKeyboard {
+		for _, btn := range row {
+			if btn.CallbackData == data {
+				return btn.Text
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+)
+
+func TestActionKeyboard(t *testing.T) {
+	if actionKeyboard(nil) != nil {
+		t.Fatal("expected nil keyboard without actions")
+	}
+	kb := actionKeyboard([]bus.MessageAction{
+		{ID: "a", Label: "A", Style: bus.ActionStylePrimary},
+		{ID: "b", Label: "B"},
+		{ID: "c", Label: "C", Style: bus.ActionStyleDanger},
+		{Label: "Docs", URL: "https://example.com"},
+	})
+	if len(kb.InlineKeyboard) != 2 || len(kb.InlineKeyboard[0]) != actionsPerRow {
+		t.Fatalf("rows = %d, want 2 with %d buttons first", len(kb.InlineKeyboard), actionsPerRow)
+	}
+	first := kb.InlineKeyboard[0][0]
+	if first.CallbackData != "act:a" || first.Style != bus.ActionStylePrimary {
+		t.Fatalf("first button = %+v", first)
+	}
+	link := kb.InlineKeyboard[1][0]
+	if link.URL != "https://example.com" || link.CallbackData != "" {
+		t.Fatalf("link button = %+v", link)
+	}
+	if got := buttonLabel(kb, "act:c"); got != "C" {
+		t.Fatalf("buttonLabel = %q, want C", got)
+	}
+	if got := buttonLabel(kb, "act:missing"); got != "" {
+		t.Fatalf("buttonLabel for unknown = %q", got)
+	}
+}
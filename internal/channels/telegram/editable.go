@@ -0,0 +1,43 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// EditMessage replaces the text of a message the bot sent to chatID.
+// Telegram keeps a single message per edit, so text must fit one message.
+func (c *Channel) EditMessage(ctx context.Context, chatID, messageID, content string) error {
+	rawChatID, msgID, err := parseMessageRef(chatID, messageID)
+	if err != nil {
+		return err
+	}
+	htmlContent := markdownToTelegramHTML(content)
+	if len(htmlContent) > telegramMaxMessageLen {
+		return fmt.Errorf("edited text exceeds telegram message limit (%d)", telegramMaxMessageLen)
+	}
+	return c.editMessage(ctx, rawChatID, msgID, htmlContent)
+}
+
+// DeleteMessage removes a message the bot sent to chatID. Telegram only
+// allows bots to delete their own messages within 48 hours.
+func (c *Channel) DeleteMessage(ctx context.Context, chatID, messageID string) error {
+	rawChatID, msgID, err := parseMessageRef(chatID, messageID)
+	if err != nil {
+		return err
+	}
+	return c.deleteMessage(ctx, rawChatID, msgID)
+}
+
+func parseMessageRef(chatID, messageID string) (int64, int, error) {
+	rawChatID, err := parseRawChatID(chatID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid chat ID: %w", err)
+	}
+	msgID, err := strconv.Atoi(messageID)
+	if err != nil || msgID <= 0 {
+		return 0, 0, fmt.Errorf("invalid telegram message ID %q", messageID)
+	}
+	return rawChatID, msgID, nil
+}
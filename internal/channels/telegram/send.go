@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	tu "github.com/mymmrac/telego/telegoutil"
 
 	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/typing"
 )
 
@@ -259,9 +261,15 @@ func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
 				slog.Info("telegram: group migrated to supergroup (media send-path)",
 					"old_chat_id", chatID, "new_chat_id", newChatID)
 				c.migrateGroupChat(ctx, chatID, newChatID)
-				return c.sendMediaMessage(ctx, newChatID, msg, replyToMsgID, threadID)
+				chatID = newChatID
+				err = c.sendMediaMessage(ctx, chatID, msg, replyToMsgID, threadID)
 			}
 		}
+		// Media captions cannot carry a keyboard reliably (albums have none),
+		// so actions follow as their own message.
+		if err == nil && len(msg.Actions) > 0 {
+			err = c.sendHTMLWithKeyboard(ctx, chatID, actionPromptText, 0, threadID, actionKeyboard(msg.Actions))
+		}
 		return err
 	}
 
@@ -301,11 +309,13 @@ func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
 	// Text-only message
 	htmlContent := markdownToTelegramHTML(msg.Content)
 	chunks := chunkHTML(htmlContent, telegramMaxMessageLen)
+	keyboard := actionKeyboard(msg.Actions)
 
 	// If a stream message exists (stored by FinalizeStream), edit the first chunk
 	// into it instead of deleting. This prevents the message from vanishing
 	// when HTML conversion makes content exceed the size limit.
 	startChunk := 0
+	editedMsgID := 0
 	if pID, ok := c.placeholders.Load(localKey); ok {
 		c.placeholders.Delete(localKey)
 		msgID := pID.(int)
@@ -322,6 +332,8 @@ func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
 			err := c.editMessage(ctx, chatID, msgID, chunks[0])
 			if err == nil {
 				startChunk = 1 // first chunk edited into stream message
+				editedMsgID = msgID
+				channels.RecordSentMessage(ctx, strconv.Itoa(msgID))
 			} else if isPostConnectNetworkErr(err) && len(chunks) > 1 {
 				// Mid-stream timeout/lost connection: the edit likely reached Telegram
 				// but the response was lost. Swallow and skip chunk 0 ONLY for multi-chunk
@@ -345,13 +357,26 @@ func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
 		}
 	}
 
+	// A single-chunk reply that went into the stream message gets its
+	// keyboard by editing the markup.
+	if keyboard != nil && startChunk == len(chunks) && editedMsgID > 0 {
+		if err := c.setKeyboard(ctx, chatID, editedMsgID, keyboard); err != nil {
+			slog.Warn("telegram: attaching actions to stream message failed, sending separately", "chat_id", chatID, "error", err)
+			return c.sendHTMLWithKeyboard(ctx, chatID, actionPromptText, 0, threadID, keyboard)
+		}
+	}
+
 	// Send remaining chunks (or all chunks if no stream message was edited).
 	for i := startChunk; i < len(chunks); i++ {
 		replyTo := 0
 		if i == 0 {
 			replyTo = replyToMsgID // only first chunk replies to user's message
 		}
-		if err := c.sendHTML(ctx, chatID, chunks[i], replyTo, threadID); err != nil {
+		var kb *telego.InlineKeyboardMarkup
+		if i == len(chunks)-1 {
+			kb = keyboard // actions ride on the last chunk
+		}
+		if err := c.sendHTMLWithKeyboard(ctx, chatID, chunks[i], replyTo, threadID, kb); err != nil {
 			return err
 		}
 	}
@@ -420,7 +445,13 @@ func (c *Channel) outboundMediaMaxBytes() int64 {
 // sendHTML sends a single HTML message, falling back to plain text if Telegram rejects the HTML.
 // replyTo and threadID are optional (0 = omit). General topic (1) is handled by resolveThreadIDForSend.
 func (c *Channel) sendHTML(ctx context.Context, chatID int64, htmlContent string, replyTo, threadID int) error {
-	return c.sendHTMLWithDepth(ctx, chatID, htmlContent, replyTo, threadID, 0)
+	return c.sendHTMLWithDepth(ctx, chatID, htmlContent, replyTo, threadID, nil, 0)
+}
+
+// sendHTMLWithKeyboard is sendHTML with an inline keyboard attached. When the
+// message has to be split, the keyboard goes on the last part.
+func (c *Channel) sendHTMLWithKeyboard(ctx context.Context, chatID int64, htmlContent string, replyTo, threadID int, keyboard *telego.InlineKeyboardMarkup) error {
+	return c.sendHTMLWithDepth(ctx, chatID, htmlContent, replyTo, threadID, keyboard, 0)
 }
 
 func (c *Channel) updatePlaceholder(ctx context.Context, localKey string, chatID int64, content string, replyTo, threadID int) error {
@@ -473,9 +504,12 @@ func (c *Channel) sendPlaceholder(ctx context.Context, chatID int64, htmlContent
 	return sent, err
 }
 
-func (c *Channel) sendHTMLWithDepth(ctx context.Context, chatID int64, htmlContent string, replyTo, threadID, depth int) error {
+func (c *Channel) sendHTMLWithDepth(ctx context.Context, chatID int64, htmlContent string, replyTo, threadID int, keyboard *telego.InlineKeyboardMarkup, depth int) error {
 	tgMsg := tu.Message(tu.ID(chatID), htmlContent)
 	tgMsg.ParseMode = telego.ModeHTML
+	if keyboard != nil {
+		tgMsg.ReplyMarkup = keyboard
+	}
 
 	// TS ref: buildTelegramThreadParams() — General topic (1) must be omitted.
 	if sendThreadID := resolveThreadIDForSend(threadID); sendThreadID > 0 {
@@ -489,7 +523,8 @@ func (c *Channel) sendHTMLWithDepth(ctx context.Context, chatID int64, htmlConte
 	}
 
 	err := c.retrySend(ctx, "sendMessage", nil, func(ctx context.Context) error {
-		_, e := c.bot.SendMessage(ctx, tgMsg)
+		sent, e := c.bot.SendMessage(ctx, tgMsg)
+		recordSent(ctx, sent)
 		return e
 	})
 
@@ -500,7 +535,7 @@ func (c *Channel) sendHTMLWithDepth(ctx context.Context, chatID int64, htmlConte
 				"old_chat_id", chatID, "new_chat_id", newChatID)
 			c.migrateGroupChat(ctx, chatID, newChatID)
 			if depth < maxSplitDepth {
-				return c.sendHTMLWithDepth(ctx, newChatID, htmlContent, replyTo, threadID, depth+1)
+				return c.sendHTMLWithDepth(ctx, newChatID, htmlContent, replyTo, threadID, keyboard, depth+1)
 			}
 			return fmt.Errorf("migration retry depth exceeded: %w", err)
 		}
@@ -524,7 +559,11 @@ func (c *Channel) sendHTMLWithDepth(ctx context.Context, chatID int64, htmlConte
 				if i == 0 {
 					r = replyTo
 				}
-				if sendErr := c.sendHTMLWithDepth(ctx, chatID, chunk, r, threadID, depth+1); sendErr != nil {
+				var kb *telego.InlineKeyboardMarkup
+				if i == len(innerChunks)-1 {
+					kb = keyboard
+				}
+				if sendErr := c.sendHTMLWithDepth(ctx, chatID, chunk, r, threadID, kb, depth+1); sendErr != nil {
 					return sendErr
 				}
 			}
@@ -536,7 +575,9 @@ func (c *Channel) sendHTMLWithDepth(ctx context.Context, chatID int64, htmlConte
 			slog.Warn("HTML parse failed, falling back to plain text", "error", err)
 			tgMsg.ParseMode = ""
 			tgMsg.Text = stripHTML(htmlContent)
-			_, err = c.bot.SendMessage(ctx, tgMsg)
+			var sent *telego.Message
+			sent, err = c.bot.SendMessage(ctx, tgMsg)
+			recordSent(ctx, sent)
 
 			// If plain text is STILL too long, split it.
 			if err != nil && messageTooLongRe.MatchString(err.Error()) {
@@ -549,10 +590,14 @@ func (c *Channel) sendHTMLWithDepth(ctx context.Context, chatID int64, htmlConte
 						msg.ReplyParameters = nil
 					}
 					msg.MessageThreadID = tgMsg.MessageThreadID
-					_, err = c.bot.SendMessage(ctx, msg)
+					if i == len(innerChunks)-1 && keyboard != nil {
+						msg.ReplyMarkup = keyboard
+					}
+					sent, err := c.bot.SendMessage(ctx, msg)
 					if err != nil {
 						return err
 					}
+					recordSent(ctx, sent)
 				}
 				return nil
 			}
@@ -562,12 +607,21 @@ func (c *Channel) sendHTMLWithDepth(ctx context.Context, chatID int64, htmlConte
 		if err != nil && tgMsg.MessageThreadID != 0 && threadNotFoundRe.MatchString(err.Error()) {
 			slog.Warn("thread not found, retrying without message_thread_id", "thread_id", tgMsg.MessageThreadID)
 			tgMsg.MessageThreadID = 0
-			_, err = c.bot.SendMessage(ctx, tgMsg)
+			var sent *telego.Message
+			sent, err = c.bot.SendMessage(ctx, tgMsg)
+			recordSent(ctx, sent)
 		}
 	}
 	return err
 }
 
+// recordSent reports a delivered message for sent message tracking.
+func recordSent(ctx context.Context, sent *telego.Message) {
+	if sent != nil && sent.MessageID > 0 {
+		channels.RecordSentMessage(ctx, strconv.Itoa(sent.MessageID))
+	}
+}
+
 // sendPhoto sends a photo message.
 func (c *Channel) sendPhoto(ctx context.Context, chatID telego.ChatID, filePath, caption string, replyTo, threadID int) error {
 	file, err := os.Open(filePath)
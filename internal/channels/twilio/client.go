@@ -0,0 +1,155 @@
+package twilio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	apiTimeout      = 30 * time.Second
+	downloadTimeout = 60 * time.Second
+)
+
+// restClient is a minimal client for the Twilio 2010-04-01 REST API,
+// authenticated with the account SID and auth token (HTTP basic auth).
+type restClient struct {
+	base      string
+	accountID string
+	authToken string
+	http      *http.Client
+}
+
+func newRESTClient(base, accountSID, authToken string) *restClient {
+	return &restClient{
+		base:      strings.TrimRight(base, "/"),
+		accountID: accountSID,
+		authToken: authToken,
+		http:      &http.Client{},
+	}
+}
+
+// apiError is a Twilio REST error response.
+type apiError struct {
+	Status  int
+	Code    int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	if e.Code == 0 {
+		return fmt.Sprintf("twilio: HTTP %d", e.Status)
+	}
+	return fmt.Sprintf("twilio: HTTP %d (code %d): %s", e.Status, e.Code, e.Message)
+}
+
+// isAuthError reports errors that retrying will not fix (bad SID or token).
+func isAuthError(err error) bool {
+	ae, ok := err.(*apiError)
+	return ok && (ae.Status == http.StatusUnauthorized || ae.Status == http.StatusForbidden)
+}
+
+// fetchAccount reads the account resource; used to validate credentials.
+func (c *restClient) fetchAccount(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/2010-04-01/Accounts/"+c.accountID+".json", nil, nil)
+}
+
+// sendMessage creates an outbound SMS/MMS and returns its SID.
+func (c *restClient) sendMessage(ctx context.Context, from, to, body string, mediaURLs []string) (string, error) {
+	form := url.Values{"From": {from}, "To": {to}}
+	if body != "" {
+		form.Set("Body", body)
+	}
+	for _, u := range mediaURLs {
+		form.Add("MediaUrl", u)
+	}
+	var res struct {
+		SID string `json:"sid"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/2010-04-01/Accounts/"+c.accountID+"/Messages.json", form, &res); err != nil {
+		return "", err
+	}
+	return res.SID, nil
+}
+
+// do sends a form-encoded request and decodes the JSON response into out (if non-nil).
+func (c *restClient) do(ctx context.Context, method, path string, form url.Values, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, apiTimeout)
+	defer cancel()
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.accountID, c.authToken)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		ae := &apiError{Status: resp.StatusCode}
+		var eb struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&eb) == nil {
+			ae.Code, ae.Message = eb.Code, eb.Message
+		}
+		return ae
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// download streams a media or recording URL into w, up to maxBytes. The
+// credentials are only sent to the API host; Twilio media URLs redirect to
+// a storage host, and the HTTP client drops the Authorization header when
+// following a redirect to another domain.
+func (c *restClient) download(ctx context.Context, fileURL string, maxBytes int64, w io.Writer) error {
+	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return err
+	}
+	if sameHost(fileURL, c.base) {
+		req.SetBasicAuth(c.accountID, c.authToken)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return &apiError{Status: resp.StatusCode}
+	}
+	n, err := io.Copy(w, io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return err
+	}
+	if n > maxBytes {
+		return fmt.Errorf("file too large (max %d bytes)", maxBytes)
+	}
+	return nil
+}
+
+func sameHost(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	return errA == nil && errB == nil && ua.Host != "" && strings.EqualFold(ua.Host, ub.Host)
+}
@@ -0,0 +1,76 @@
+package twilio
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nextlevelbuilder/goclaw/internal/audio"
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// twilioCreds maps the credentials JSON from the channel_instances table.
+type twilioCreds struct {
+	AccountSID string `json:"account_sid"`
+	AuthToken  string `json:"auth_token"` // also the webhook signing key
+}
+
+// twilioInstanceConfig maps the non-secret config JSONB from the channel_instances table.
+type twilioInstanceConfig struct {
+	PhoneNumber   string                     `json:"phone_number"`         // E.164, e.g. "+14155550100"
+	APIBase       string                     `json:"api_base,omitempty"`   // default https://api.twilio.com
+	PublicURL     string                     `json:"public_url,omitempty"` // external gateway URL; required for MMS
+	DMPolicy      string                     `json:"dm_policy,omitempty"`
+	AllowFrom     []string                   `json:"allow_from,omitempty"`
+	MaxSegments   int                        `json:"max_segments,omitempty"` // per SMS; default 10
+	MediaMaxMB    int                        `json:"media_max_mb,omitempty"`
+	VoiceEnabled  bool                       `json:"voice_enabled,omitempty"`
+	VoiceGreeting string                     `json:"voice_greeting,omitempty"`
+	VoiceLanguage string                     `json:"voice_language,omitempty"` // <Say> locale, e.g. "en-US"
+	TTSVoice      string                     `json:"tts_voice,omitempty"`      // provider voice ID for call replies
+	BlockReply    *bool                      `json:"block_reply,omitempty"`
+	ChatBehavior  *config.ChatBehaviorConfig `json:"chat_behavior,omitempty"`
+}
+
+// Factory creates a Twilio channel from DB instance data. Calls are not
+// answered without an audio manager.
+func Factory(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+	return build(name, creds, cfg, msgBus, pairingSvc, nil)
+}
+
+// FactoryWithAudio returns a ChannelFactory that transcribes and speaks
+// phone calls through audioMgr.
+func FactoryWithAudio(audioMgr *audio.Manager) channels.ChannelFactory {
+	return func(name string, creds json.RawMessage, cfg json.RawMessage,
+		msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+		return build(name, creds, cfg, msgBus, pairingSvc, audioMgr)
+	}
+}
+
+func build(name string, creds, cfg json.RawMessage, msgBus *bus.MessageBus,
+	pairingSvc store.PairingStore, audioMgr *audio.Manager) (channels.Channel, error) {
+
+	var c twilioCreds
+	if len(creds) > 0 {
+		if err := json.Unmarshal(creds, &c); err != nil {
+			return nil, fmt.Errorf("decode twilio credentials: %w", err)
+		}
+	}
+
+	var ic twilioInstanceConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &ic); err != nil {
+			return nil, fmt.Errorf("decode twilio config: %w", err)
+		}
+	}
+
+	ch, err := New(ic, c, msgBus, pairingSvc, audioMgr)
+	if err != nil {
+		return nil, err
+	}
+	ch.SetName(name)
+	return ch, nil
+}
@@ -0,0 +1,21 @@
+package twilio
+
+import (
+	"strings"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels/zalo"
+)
+
+// gsmFold maps typographic characters common in model output to GSM-7
+// equivalents. One such character would otherwise switch the whole message
+// to UCS-2 and more than double its segment count.
+var gsmFold = strings.NewReplacer(
+	"‘", "'", "’", "'", "“", `"`, "”", `"`,
+	"–", "-", "—", "-", "…", "...", "\u00a0", " ",
+	"• ", "- ", // bullets from StripMarkdown
+)
+
+// toPlainText renders agent markdown as SMS/voice text.
+func toPlainText(text string) string {
+	return gsmFold.Replace(zalo.StripMarkdown(text))
+}
@@ -0,0 +1,242 @@
+package twilio
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/safego"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	webhookPrefix = "/channels/twilio/"
+	smsPath       = webhookPrefix + "sms"
+	voicePath     = webhookPrefix + "voice"
+	recordingPath = webhookPrefix + "voice/recording"
+	pollPath      = webhookPrefix + "voice/poll"
+	statusPath    = webhookPrefix + "voice/status"
+	mediaPath     = webhookPrefix + "media/"
+	maxBodyBytes  = 64 * 1024 // webhook bodies are small form posts
+	mediaTTL      = 15 * time.Minute
+)
+
+// webhookRouter routes Twilio webhooks to the channel instance that owns the
+// called number ("To"). A single handler is mounted on the gateway mux and
+// shared by all Twilio instances, so every number is configured with
+// https://<gateway>/channels/twilio/sms and .../voice.
+type webhookRouter struct {
+	mu           sync.RWMutex
+	instances    map[string]*Channel // phone number → channel
+	routeHandled bool                // true after first webhookRoute() call
+}
+
+var globalRouter = &webhookRouter{
+	instances: make(map[string]*Channel),
+}
+
+// register routes the instance's number to it. A number already served by
+// another instance is rejected: webhooks carry only the called number, so
+// the second instance would silently take over the first one's traffic.
+func (r *webhookRouter) register(ch *Channel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cur := r.instances[ch.config.PhoneNumber]; cur != nil && cur != ch {
+		return fmt.Errorf("phone number %s is already served by another twilio instance", ch.config.PhoneNumber)
+	}
+	r.instances[ch.config.PhoneNumber] = ch
+	return nil
+}
+
+func (r *webhookRouter) unregister(ch *Channel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.instances[ch.config.PhoneNumber] == ch {
+		delete(r.instances, ch.config.PhoneNumber)
+	}
+}
+
+// webhookRoute returns the path+handler for the first call; ("", nil) for subsequent calls.
+func (r *webhookRouter) webhookRoute() (string, http.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.routeHandled {
+		r.routeHandled = true
+		return webhookPrefix, r
+	}
+	return "", nil
+}
+
+func (r *webhookRouter) lookup(number string) *Channel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.instances[normalizeNumber(number)]
+}
+
+// ServeHTTP verifies the request signature with the auth token of the
+// number's instance, then dispatches by path. Media URLs are unsigned
+// (Twilio fetches them with a plain GET) and rely on unguessable tokens.
+func (r *webhookRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodGet && strings.HasPrefix(req.URL.Path, mediaPath) {
+		globalMedia.serve(w, strings.TrimPrefix(req.URL.Path, mediaPath))
+		return
+	}
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	req.Body = http.MaxBytesReader(w, req.Body, maxBodyBytes)
+	if err := req.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ch := r.lookup(req.PostForm.Get("To"))
+	if ch == nil {
+		slog.Warn("security.twilio_unknown_number", "to", req.PostForm.Get("To"), "remote", req.RemoteAddr)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if !validSignature(ch.client.authToken, ch.requestURL(req), req.PostForm, req.Header.Get("X-Twilio-Signature")) ||
+		req.PostForm.Get("AccountSid") != ch.client.accountID {
+		slog.Warn("security.twilio_signature_rejected", "to", req.PostForm.Get("To"), "remote", req.RemoteAddr)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	ctx := store.WithTenantID(context.Background(), ch.TenantID())
+	switch req.URL.Path {
+	case smsPath:
+		// Reply asynchronously through the REST API; an empty TwiML
+		// response tells Twilio not to send anything itself.
+		writeTwiML(w, newTwiML())
+		go func() {
+			defer safego.Recover(nil, "component", "twilio_sms")
+			ch.handleSMS(ctx, req.PostForm)
+		}()
+	case voicePath:
+		writeTwiML(w, ch.handleCall(ctx, req))
+	case recordingPath:
+		writeTwiML(w, ch.handleRecording(ctx, req))
+	case pollPath:
+		writeTwiML(w, ch.handlePoll(ctx, req))
+	case statusPath:
+		ch.handleCallStatus(req.PostForm)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// requestURL reconstructs the URL Twilio requested, which the signature
+// covers. public_url wins over the Host header so the check also holds
+// behind proxies that rewrite it.
+func (c *Channel) requestURL(req *http.Request) string {
+	return c.baseURL(req) + req.URL.RequestURI()
+}
+
+// baseURL is the externally visible scheme://host of the gateway.
+func (c *Channel) baseURL(req *http.Request) string {
+	if c.config.PublicURL != "" {
+		return c.config.PublicURL
+	}
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	if p := req.Header.Get("X-Forwarded-Proto"); p != "" {
+		scheme = strings.TrimSpace(strings.Split(p, ",")[0])
+	}
+	return scheme + "://" + req.Host
+}
+
+// validSignature checks X-Twilio-Signature: base64(HMAC-SHA1(auth token,
+// URL + POST parameters sorted by name, each name followed by its value)).
+func validSignature(authToken, fullURL string, params url.Values, signature string) bool {
+	if signature == "" {
+		return false
+	}
+	expected := sign(authToken, fullURL, params)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func sign(authToken, fullURL string, params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	var b strings.Builder
+	b.WriteString(fullURL)
+	for _, k := range keys {
+		values := slices.Clone(params[k])
+		slices.Sort(values)
+		for _, v := range values {
+			b.WriteString(k)
+			b.WriteString(v)
+		}
+	}
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(b.String()))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// mediaStore holds outbound files (MMS attachments, TTS audio for calls)
+// that Twilio fetches by URL. Entries expire after mediaTTL.
+type mediaStore struct {
+	mu    sync.Mutex
+	files map[string]mediaFile // token → file
+}
+
+type mediaFile struct {
+	data     []byte
+	mimeType string
+	expires  time.Time
+}
+
+var globalMedia = &mediaStore{files: make(map[string]mediaFile)}
+
+// put stores data and returns its URL path.
+func (s *mediaStore) put(data []byte, mimeType string) string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	token := hex.EncodeToString(buf)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[token] = mediaFile{data: data, mimeType: mimeType, expires: time.Now().Add(mediaTTL)}
+	return mediaPath + token
+}
+
+func (s *mediaStore) serve(w http.ResponseWriter, token string) {
+	s.mu.Lock()
+	f, ok := s.files[token]
+	s.mu.Unlock()
+	if !ok || time.Now().After(f.expires) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", f.mimeType)
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(f.data)
+}
+
+func (s *mediaStore) sweep(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for token, f := range s.files {
+		if now.After(f.expires) {
+			delete(s.files, token)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package twilio
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+)
+
+// Send delivers an outbound message. During a call with the recipient the
+// text is spoken on the call; otherwise it goes out as SMS, split into
+// messages of at most max_segments segments. Attachments are sent as MMS,
+// which needs public_url so Twilio can fetch them.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("twilio channel not running")
+	}
+	to := normalizeNumber(msg.ChatID)
+	if to == "" {
+		return fmt.Errorf("empty chat ID for twilio send")
+	}
+	// SMS cannot be edited: there is no placeholder to update.
+	if msg.Metadata["placeholder_update"] == "true" {
+		return nil
+	}
+
+	text := toPlainText(msg.Content)
+	if call := c.activeCall(to); call != nil && text != "" {
+		call.reply(text)
+		text = ""
+	}
+
+	var mediaURLs []string
+	for _, m := range msg.Media {
+		u, err := c.publishMedia(m)
+		if err != nil {
+			slog.Warn("twilio: media send failed", "file", m.URL, "error", err)
+			text += fmt.Sprintf("\n[File upload failed: %s]", filepath.Base(m.URL))
+			continue
+		}
+		mediaURLs = append(mediaURLs, u)
+	}
+
+	chunks := channels.ChunkSMS(text, c.maxSegments)
+	if len(chunks) == 0 && len(mediaURLs) > 0 {
+		chunks = []string{""}
+	}
+	for i, chunk := range chunks {
+		var urls []string
+		if i == 0 {
+			urls = mediaURLs
+		}
+		sid, err := c.client.sendMessage(ctx, c.config.PhoneNumber, to, chunk, urls)
+		if err != nil {
+			return fmt.Errorf("send twilio message: %w", err)
+		}
+		channels.RecordSentMessage(ctx, sid)
+	}
+	return nil
+}
+
+// publishMedia makes a local outbound file fetchable by Twilio and returns its URL.
+func (c *Channel) publishMedia(att bus.MediaAttachment) (string, error) {
+	if c.config.PublicURL == "" {
+		return "", fmt.Errorf("public_url is not configured")
+	}
+	stat, err := os.Stat(att.URL)
+	if err != nil {
+		return "", err
+	}
+	if stat.Size() > c.mediaMaxBytes {
+		return "", fmt.Errorf("file too large: %d bytes (max %d)", stat.Size(), c.mediaMaxBytes)
+	}
+	data, err := os.ReadFile(att.URL)
+	if err != nil {
+		return "", err
+	}
+	mime := att.ContentType
+	if mime == "" {
+		mime = media.DetectMIMEType(filepath.Base(att.URL))
+	}
+	return c.config.PublicURL + globalMedia.put(data, mime), nil
+}
@@ -0,0 +1,173 @@
+package twilio
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+	"github.com/nextlevelbuilder/goclaw/internal/tools"
+)
+
+// checkDownloadURL guards media downloads against SSRF. Variable so tests
+// can allow their loopback server.
+var checkDownloadURL = tools.CheckSSRF
+
+// maxInboundMedia caps the MediaUrlN parameters read from one message
+// (Twilio delivers at most 10).
+const maxInboundMedia = 10
+
+// handleSMS processes one verified inbound SMS/MMS webhook.
+func (c *Channel) handleSMS(ctx context.Context, form url.Values) {
+	sid := form.Get("MessageSid")
+	if sid != "" {
+		if _, loaded := c.dedup.LoadOrStore(sid, time.Now()); loaded {
+			return
+		}
+	}
+	from := normalizeNumber(form.Get("From"))
+	if from == "" {
+		return
+	}
+	if !c.checkDMPolicy(ctx, from) {
+		return
+	}
+	if !c.IsAllowed(from) {
+		slog.Debug("twilio message rejected by allowlist", "from", from)
+		return
+	}
+
+	content := strings.TrimSpace(form.Get("Body"))
+	var mediaPaths []string
+	if items := c.downloadMedia(ctx, form); len(items) > 0 {
+		for _, it := range items {
+			mediaPaths = append(mediaPaths, it.FilePath)
+		}
+		if tags := media.BuildMediaTags(items); tags != "" {
+			content = strings.TrimSpace(tags + "\n\n" + content)
+		}
+	}
+	if content == "" {
+		return
+	}
+
+	slog.Debug("twilio message received", "from", from, "preview", channels.Truncate(content, 50))
+
+	metadata := map[string]string{
+		"message_id":   sid,
+		"user_id":      from,
+		"display_name": from,
+		"is_dm":        "true",
+		"local_key":    from,
+	}
+	if city := form.Get("FromCity"); city != "" {
+		metadata["from_city"] = city
+	}
+	if country := form.Get("FromCountry"); country != "" {
+		metadata["from_country"] = country
+	}
+	c.HandleMessage(from, from, content, mediaPaths, metadata, "direct")
+}
+
+// downloadMedia saves MMS attachments to temp files. Failures are logged and
+// skipped so the text of the message still goes through.
+func (c *Channel) downloadMedia(ctx context.Context, form url.Values) []media.MediaInfo {
+	n, _ := strconv.Atoi(form.Get("NumMedia"))
+	n = min(n, maxInboundMedia)
+	var items []media.MediaInfo
+	for i := range n {
+		mediaURL := form.Get(fmt.Sprintf("MediaUrl%d", i))
+		contentType := form.Get(fmt.Sprintf("MediaContentType%d", i))
+		if mediaURL == "" {
+			continue
+		}
+		path, err := c.downloadFile(ctx, mediaURL, extensionFor(contentType))
+		if err != nil {
+			slog.Warn("twilio: media download failed", "index", i, "error", err)
+			continue
+		}
+		items = append(items, media.MediaInfo{
+			Type:        media.MediaKindFromMime(contentType),
+			FilePath:    path,
+			FileID:      mediaURL,
+			ContentType: contentType,
+			FileName:    fmt.Sprintf("mms-%d%s", i+1, extensionFor(contentType)),
+		})
+	}
+	return items
+}
+
+// downloadFile fetches an API-hosted file (MMS media, call recording) into a temp file.
+func (c *Channel) downloadFile(ctx context.Context, fileURL, ext string) (string, error) {
+	if err := checkDownloadURL(fileURL); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp("", "twilio-file-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+	defer tmp.Close()
+	if err := c.client.download(ctx, fileURL, c.mediaMaxBytes, tmp); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+func extensionFor(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "audio/mpeg":
+		return ".mp3"
+	case "":
+		return ".dat"
+	}
+	if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ".dat"
+}
+
+// checkDMPolicy enforces DM policy for incoming messages and calls.
+func (c *Channel) checkDMPolicy(ctx context.Context, from string) bool {
+	switch c.CheckDMPolicy(ctx, from, c.config.DMPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, from)
+		return false
+	default:
+		slog.Debug("twilio message rejected by policy", "from", from, "policy", c.config.DMPolicy)
+		return false
+	}
+}
+
+func (c *Channel) sendPairingReply(ctx context.Context, from string) {
+	ps := c.PairingService()
+	if ps == nil || !c.CanSendPairingNotif(from, pairingDebounce) {
+		return
+	}
+	code, err := ps.RequestPairing(ctx, from, c.Name(), from, "default", nil)
+	if err != nil {
+		slog.Debug("twilio pairing request failed", "from", from, "error", err)
+		return
+	}
+	reply := fmt.Sprintf(
+		"GoClaw: access not configured.\n\nYour user ID: %s\n\nPairing code: %s\n\nAsk the bot owner to approve with:\n  goclaw pairing approve %s",
+		from, code, code,
+	)
+	if _, err := c.client.sendMessage(ctx, c.config.PhoneNumber, from, reply, nil); err != nil {
+		slog.Warn("twilio: failed to send pairing reply", "error", err)
+		return
+	}
+	c.MarkPairingNotifSent(from)
+	slog.Info("twilio pairing reply sent", "from", from, "code", code)
+}
@@ -0,0 +1,190 @@
+// Package twilio implements a GoClaw channel for SMS/MMS and phone calls
+// through Twilio (or any API-compatible provider). Inbound messages and calls
+// arrive on signed webhooks mounted on the gateway mux; replies go out through
+// the Messages REST API, and calls are driven with TwiML: the caller's speech
+// is recorded and transcribed by the audio manager, the agent's reply is
+// played back with TTS (or Twilio's <Say> when no TTS provider is set).
+package twilio
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/audio"
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/safego"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	defaultAPIBase       = "https://api.twilio.com"
+	defaultMaxSegments   = 10
+	defaultMediaMaxBytes = int64(5 * 1024 * 1024) // Twilio's MMS limit
+	pairingDebounce      = 60 * time.Second
+	dedupTTL             = 10 * time.Minute
+	callIdleTTL          = 2 * time.Hour
+)
+
+// Channel is one Twilio phone number.
+type Channel struct {
+	*channels.BaseChannel
+	client        *restClient
+	config        twilioInstanceConfig
+	audioMgr      *audio.Manager // STT for calls, optional TTS for replies (nil = SMS only)
+	maxSegments   int
+	mediaMaxBytes int64
+
+	dedup sync.Map // MessageSid -> time.Time
+	calls sync.Map // caller number -> *callState
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// Compile-time interface assertions.
+var _ channels.Channel = (*Channel)(nil)
+var _ channels.WebhookChannel = (*Channel)(nil)
+var _ channels.BlockReplyChannel = (*Channel)(nil)
+
+// New creates a new Twilio channel from instance config and credentials.
+func New(cfg twilioInstanceConfig, creds twilioCreds, msgBus *bus.MessageBus, pairingSvc store.PairingStore, audioMgr *audio.Manager) (*Channel, error) {
+	if creds.AccountSID == "" || creds.AuthToken == "" {
+		return nil, fmt.Errorf("twilio account_sid and auth_token are required")
+	}
+	cfg.PhoneNumber = normalizeNumber(cfg.PhoneNumber)
+	if cfg.PhoneNumber == "" {
+		return nil, fmt.Errorf("twilio phone_number is required")
+	}
+	if cfg.APIBase == "" {
+		cfg.APIBase = defaultAPIBase
+	}
+	cfg.PublicURL = strings.TrimRight(cfg.PublicURL, "/")
+
+	base := channels.NewBaseChannel(channels.TypeTwilio, msgBus, cfg.AllowFrom)
+	base.ValidatePolicy(cfg.DMPolicy, "")
+
+	maxSegments := cfg.MaxSegments
+	if maxSegments <= 0 {
+		maxSegments = defaultMaxSegments
+	}
+	mediaMax := int64(cfg.MediaMaxMB) * 1024 * 1024
+	if mediaMax <= 0 {
+		mediaMax = defaultMediaMaxBytes
+	}
+
+	ch := &Channel{
+		BaseChannel:   base,
+		client:        newRESTClient(cfg.APIBase, creds.AccountSID, creds.AuthToken),
+		config:        cfg,
+		audioMgr:      audioMgr,
+		maxSegments:   maxSegments,
+		mediaMaxBytes: mediaMax,
+	}
+	ch.SetPairingService(pairingSvc)
+	return ch, nil
+}
+
+// Start validates the credentials by fetching the account, then registers
+// the number with the shared webhook router.
+func (c *Channel) Start(ctx context.Context) error {
+	c.MarkStarting("checking account")
+
+	if err := c.client.fetchAccount(ctx); err != nil {
+		kind := channels.ChannelFailureKindNetwork
+		if isAuthError(err) {
+			kind = channels.ChannelFailureKindAuth
+		}
+		c.MarkFailed("account check failed", err.Error(), kind, kind != channels.ChannelFailureKindAuth)
+		return fmt.Errorf("twilio account check failed: %w", err)
+	}
+	if err := globalRouter.register(c); err != nil {
+		c.MarkFailed("duplicate phone number", err.Error(), channels.ChannelFailureKindConfig, false)
+		return err
+	}
+
+	c.stopCh = make(chan struct{})
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer safego.Recover(nil, "component", "twilio_sweep")
+		ticker := time.NewTicker(2 * time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stopCh:
+				return
+			case <-ticker.C:
+				c.sweepMaps()
+			}
+		}
+	}()
+
+	c.SetRunning(true)
+	c.MarkHealthy("webhook " + webhookPrefix)
+	slog.Info("twilio channel started", "number", c.config.PhoneNumber, "voice", c.voiceEnabled(), "path", webhookPrefix)
+	return nil
+}
+
+// Stop unregisters the number from the webhook router. Calls in progress
+// hang up at their next webhook.
+func (c *Channel) Stop(_ context.Context) error {
+	slog.Info("stopping twilio channel", "number", c.config.PhoneNumber)
+	globalRouter.unregister(c)
+	c.SetRunning(false)
+	if c.stopCh != nil {
+		close(c.stopCh)
+		c.wg.Wait()
+		c.stopCh = nil
+	}
+	c.MarkStopped("stopped")
+	return nil
+}
+
+// WebhookHandler returns the shared webhook prefix and the global router as handler.
+// Only the first instance returns a route; the rest share it.
+func (c *Channel) WebhookHandler() (string, http.Handler) {
+	return globalRouter.webhookRoute()
+}
+
+// sweepMaps performs age-based eviction of the dedup cache and of calls
+// whose status callback never arrived.
+func (c *Channel) sweepMaps() {
+	now := time.Now()
+	c.dedup.Range(func(k, v any) bool {
+		if t, ok := v.(time.Time); ok && now.Sub(t) > dedupTTL {
+			c.dedup.Delete(k)
+		}
+		return true
+	})
+	c.calls.Range(func(k, v any) bool {
+		if now.Sub(v.(*callState).lastSeen()) > callIdleTTL {
+			c.calls.Delete(k)
+		}
+		return true
+	})
+	globalMedia.sweep(now)
+}
+
+// BlockReplyEnabled returns the per-channel block_reply override (nil = inherit gateway default).
+func (c *Channel) BlockReplyEnabled() *bool { return c.config.BlockReply }
+
+// ChatBehaviorConfig returns the per-channel chat_behavior override.
+func (c *Channel) ChatBehaviorConfig() *config.ChatBehaviorConfig { return c.config.ChatBehavior }
+
+// normalizeNumber strips formatting from an E.164 number ("+1 (415) 555-0100" → "+14155550100").
+func normalizeNumber(s string) string {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(s) {
+		if (r >= '0' && r <= '9') || (r == '+' && i == 0) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,294 @@
+package twilio
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/audio"
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+const (
+	testSID   = "AC00000000000000000000000000000001"
+	testToken = "test-auth-token"
+)
+
+func init() { checkDownloadURL = func(string) error { return nil } }
+
+// fakeAPI is a stand-in for the Twilio REST API.
+type fakeAPI struct {
+	*httptest.Server
+	mu   sync.Mutex
+	sent []url.Values // Messages.json form posts
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	t.Helper()
+	api := &fakeAPI{}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != testSID || pass != testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":20003,"message":"Authenticate"}`))
+			return
+		}
+		switch {
+		case r.URL.Path == "/2010-04-01/Accounts/"+testSID+".json":
+			_, _ = w.Write([]byte(`{"sid":"` + testSID + `"}`))
+		case r.URL.Path == "/2010-04-01/Accounts/"+testSID+"/Messages.json":
+			_ = r.ParseForm()
+			api.mu.Lock()
+			api.sent = append(api.sent, r.PostForm)
+			api.mu.Unlock()
+			_, _ = w.Write([]byte(`{"sid":"SM1"}`))
+		case r.URL.Path == "/media/cat":
+			w.Header().Set("Content-Type", "image/jpeg")
+			_, _ = w.Write([]byte("\xff\xd8\xff jpeg"))
+		case r.URL.Path == "/recordings/RE1.wav":
+			_, _ = w.Write([]byte("RIFF wav"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
+
+func (a *fakeAPI) messages() []url.Values {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]url.Values(nil), a.sent...)
+}
+
+// fakeSTT transcribes every recording to the same text.
+type fakeSTT struct{ text string }
+
+func (f *fakeSTT) Name() string { return "fake" }
+func (f *fakeSTT) Transcribe(context.Context, audio.STTInput, audio.STTOptions) (*audio.TranscriptResult, error) {
+	return &audio.TranscriptResult{Text: f.text}, nil
+}
+
+func startChannel(t *testing.T, api *fakeAPI, cfg twilioInstanceConfig, audioMgr *audio.Manager) (*Channel, *bus.MessageBus, *httptest.Server) {
+	t.Helper()
+	srv := httptest.NewServer(globalRouter)
+	t.Cleanup(srv.Close)
+	cfg.PhoneNumber = "+1 415 555 0100"
+	cfg.APIBase = api.URL
+	cfg.PublicURL = srv.URL
+	if cfg.DMPolicy == "" {
+		cfg.DMPolicy = "open"
+	}
+	mb := bus.New()
+	ch, err := New(cfg, twilioCreds{AccountSID: testSID, AuthToken: testToken}, mb, nil, audioMgr)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ch.SetName("twilio-" + strings.ToLower(t.Name()))
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = ch.Stop(context.Background()) })
+	return ch, mb, srv
+}
+
+// post sends a webhook signed with token, as Twilio would.
+func post(t *testing.T, srv *httptest.Server, path, token string, params url.Values) (int, string) {
+	t.Helper()
+	params.Set("AccountSid", testSID)
+	if params.Get("To") == "" {
+		params.Set("To", "+14155550100")
+	}
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+path, strings.NewReader(params.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Twilio-Signature", sign(token, srv.URL+path, params))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func consume(t *testing.T, mb *bus.MessageBus) bus.InboundMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msg, ok := mb.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("no inbound message")
+	}
+	return msg
+}
+
+func TestStartRejectsBadCredentials(t *testing.T) {
+	api := newFakeAPI(t)
+	ch, err := New(twilioInstanceConfig{PhoneNumber: "+14155550100", APIBase: api.URL},
+		twilioCreds{AccountSID: testSID, AuthToken: "wrong"}, bus.New(), nil, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := ch.Start(context.Background()); err == nil {
+		t.Fatal("Start succeeded with a bad token")
+	}
+	if h := ch.HealthSnapshot(); h.State != channels.ChannelHealthStateFailed || h.FailureKind != channels.ChannelFailureKindAuth {
+		t.Fatalf("health = %+v", h)
+	}
+}
+
+func TestStartRejectsDuplicateNumber(t *testing.T) {
+	api := newFakeAPI(t)
+	ch, _, _ := startChannel(t, api, twilioInstanceConfig{}, nil)
+
+	dup, err := New(twilioInstanceConfig{PhoneNumber: "+14155550100", APIBase: api.URL},
+		twilioCreds{AccountSID: testSID, AuthToken: testToken}, bus.New(), nil, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := dup.Start(context.Background()); err == nil {
+		t.Fatal("second instance on the same number started")
+	}
+	if h := dup.HealthSnapshot(); h.State != channels.ChannelHealthStateFailed || h.FailureKind != channels.ChannelFailureKindConfig {
+		t.Fatalf("health = %+v", h)
+	}
+	if got := globalRouter.lookup("+14155550100"); got != ch {
+		t.Fatalf("number routed to %p, want the first instance %p", got, ch)
+	}
+}
+
+func TestWebhookSignatureRequired(t *testing.T) {
+	api := newFakeAPI(t)
+	_, mb, srv := startChannel(t, api, twilioInstanceConfig{}, nil)
+	params := url.Values{"From": {"+15550001111"}, "Body": {"hi"}, "MessageSid": {"SM-in-1"}}
+
+	if code, _ := post(t, srv, smsPath, "not-the-token", params); code != http.StatusForbidden {
+		t.Fatalf("bad signature: status %d", code)
+	}
+	if code, _ := post(t, srv, smsPath, testToken, url.Values{"From": {"+15550001111"}, "To": {"+19999999999"}}); code != http.StatusForbidden {
+		t.Fatalf("unknown number: status %d", code)
+	}
+	// A valid signature does not cover tampered parameters.
+	params.Set("AccountSid", testSID)
+	params.Set("To", "+14155550100")
+	sig := sign(testToken, srv.URL+smsPath, params)
+	params.Set("Body", "tampered")
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+smsPath, strings.NewReader(params.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Twilio-Signature", sig)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("tampered body: status %d", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if msg, ok := mb.ConsumeInbound(ctx); ok {
+		t.Fatalf("unverified webhook delivered: %+v", msg)
+	}
+}
+
+func TestInboundMMSAndSegmentedReply(t *testing.T) {
+	api := newFakeAPI(t)
+	ch, mb, srv := startChannel(t, api, twilioInstanceConfig{MaxSegments: 2}, nil)
+
+	code, body := post(t, srv, smsPath, testToken, url.Values{
+		"From": {"+15550001111"}, "Body": {"what is this?"}, "MessageSid": {"MM-1"},
+		"NumMedia": {"1"}, "MediaUrl0": {api.URL + "/media/cat"}, "MediaContentType0": {"image/jpeg"},
+	})
+	if code != http.StatusOK || !strings.Contains(body, "<Response></Response>") {
+		t.Fatalf("webhook = %d %q", code, body)
+	}
+	msg := consume(t, mb)
+	if msg.ChatID != "+15550001111" || msg.PeerKind != "direct" || msg.Metadata["message_id"] != "MM-1" {
+		t.Fatalf("inbound = %+v", msg)
+	}
+	if !strings.Contains(msg.Content, "<media:image>") || !strings.Contains(msg.Content, "what is this?") || len(msg.Media) != 1 {
+		t.Fatalf("media not attached: %q %v", msg.Content, msg.Media)
+	}
+
+	reply := "**Sure** — " + strings.Repeat("here is a rather long answer that goes on. ", 20)
+	if err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "+15550001111", Content: reply}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	sent := api.messages()
+	if len(sent) < 2 {
+		t.Fatalf("expected a multi-part reply, got %d messages", len(sent))
+	}
+	for i, m := range sent {
+		if m.Get("From") != "+14155550100" || m.Get("To") != "+15550001111" {
+			t.Fatalf("message %d addressed %s → %s", i, m.Get("From"), m.Get("To"))
+		}
+		if n, ucs2 := channels.SMSSegments(m.Get("Body")); n > 2 || ucs2 {
+			t.Fatalf("message %d: %d segments (ucs2=%v)", i, n, ucs2)
+		}
+	}
+	if !strings.HasPrefix(sent[0].Get("Body"), "Sure - here") {
+		t.Fatalf("markdown not stripped: %q", sent[0].Get("Body"))
+	}
+}
+
+func TestVoiceCallTurn(t *testing.T) {
+	api := newFakeAPI(t)
+	mgr := audio.NewManager(audio.ManagerConfig{})
+	mgr.RegisterSTT(&fakeSTT{text: "what's the weather"})
+	mgr.SetSTTChain([]string{"fake"})
+	ch, mb, srv := startChannel(t, api, twilioInstanceConfig{VoiceEnabled: true, VoiceLanguage: "en-US"}, mgr)
+	call := url.Values{"From": {"+15550001111"}, "CallSid": {"CA1"}}
+
+	_, twiml := post(t, srv, voicePath, testToken, call)
+	if !strings.Contains(twiml, `<Say language="en-US">Hello! How can I help you?</Say>`) ||
+		!strings.Contains(twiml, `<Record action="`+srv.URL+recordingPath+`"`) {
+		t.Fatalf("answer TwiML = %s", twiml)
+	}
+
+	rec := url.Values{"From": {"+15550001111"}, "CallSid": {"CA1"}, "RecordingSid": {"RE1"}, "RecordingUrl": {api.URL + "/recordings/RE1"}}
+	if _, twiml = post(t, srv, recordingPath, testToken, rec); !strings.Contains(twiml, "<Redirect method=\"POST\">"+srv.URL+pollPath) {
+		t.Fatalf("recording TwiML = %s", twiml)
+	}
+	msg := consume(t, mb)
+	if msg.Content != "what's the weather" || msg.Metadata["call_sid"] != "CA1" {
+		t.Fatalf("inbound = %q %v", msg.Content, msg.Metadata)
+	}
+
+	// The reply is spoken on the call (no TTS provider: <Say>), not texted.
+	if err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "+15550001111", Content: "Sunny & **warm**."}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if _, twiml = post(t, srv, pollPath, testToken, call); !strings.Contains(twiml, "<Say language=\"en-US\">Sunny &amp; warm.</Say><Record") {
+		t.Fatalf("poll TwiML = %s", twiml)
+	}
+	if n := len(api.messages()); n != 0 {
+		t.Fatalf("%d SMS sent during the call", n)
+	}
+
+	// After the call ends, replies go out as SMS again.
+	post(t, srv, statusPath, testToken, url.Values{"From": {"+15550001111"}, "CallSid": {"CA1"}, "CallStatus": {"completed"}})
+	if _, twiml = post(t, srv, pollPath, testToken, call); !strings.Contains(twiml, "<Hangup/>") {
+		t.Fatalf("poll after hangup = %s", twiml)
+	}
+	if err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "+15550001111", Content: "Bye"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if sent := api.messages(); len(sent) != 1 || sent[0].Get("Body") != "Bye" {
+		t.Fatalf("messages = %v", sent)
+	}
+}
+
+func TestVoiceDisabledRejectsCalls(t *testing.T) {
+	api := newFakeAPI(t)
+	_, _, srv := startChannel(t, api, twilioInstanceConfig{VoiceEnabled: true}, nil) // no audio manager
+	if _, twiml := post(t, srv, voicePath, testToken, url.Values{"From": {"+15550001111"}, "CallSid": {"CA2"}}); !strings.Contains(twiml, "<Reject/>") {
+		t.Fatalf("TwiML = %s", twiml)
+	}
+}
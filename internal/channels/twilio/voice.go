@@ -0,0 +1,332 @@
+package twilio
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/audio"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/safego"
+)
+
+const (
+	defaultGreeting  = "Hello! How can I help you?"
+	recordMaxSeconds = 60
+	pollWait         = 8 * time.Second // Twilio waits 15s for a webhook response
+	ttsBudget        = 5 * time.Second
+	sttTimeout       = 30 * time.Second
+	turnTimeout      = 2 * time.Minute
+	maxSayLen        = 4000 // <Say> accepts up to 4096 characters
+)
+
+// A call alternates between listening (<Record>) and waiting for the agent.
+// After a recording the caller hears silence while Twilio polls voice/poll;
+// each poll blocks up to pollWait for replies that Send routes into the call.
+type callState struct {
+	sid     string
+	replies chan string
+
+	mu        sync.Mutex
+	turnStart time.Time // zero while listening
+	seen      time.Time
+}
+
+func newCallState(sid string) *callState {
+	return &callState{sid: sid, replies: make(chan string, 16), seen: time.Now()}
+}
+
+// reply queues text to be spoken on the call.
+func (s *callState) reply(text string) {
+	select {
+	case s.replies <- text:
+	default:
+		slog.Warn("twilio: call reply queue full, dropping reply", "call_sid", s.sid)
+	}
+}
+
+// wait returns the queued replies, blocking up to d for the first one.
+func (s *callState) wait(ctx context.Context, d time.Duration) []string {
+	var texts []string
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case t := <-s.replies:
+		texts = append(texts, t)
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return nil
+	}
+	for {
+		select {
+		case t := <-s.replies:
+			texts = append(texts, t)
+		default:
+			return texts
+		}
+	}
+}
+
+func (s *callState) setTurn(start time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.turnStart = start
+	s.seen = time.Now()
+}
+
+func (s *callState) turnAge() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.turnStart.IsZero() {
+		return 0
+	}
+	return time.Since(s.turnStart)
+}
+
+func (s *callState) lastSeen() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seen
+}
+
+// voiceEnabled reports whether calls are answered: voice_enabled must be set
+// and an audio manager must be available for transcription.
+func (c *Channel) voiceEnabled() bool {
+	return c.config.VoiceEnabled && c.audioMgr != nil
+}
+
+// activeCall returns the call in progress with number, or nil.
+func (c *Channel) activeCall(number string) *callState {
+	if v, ok := c.calls.Load(number); ok {
+		return v.(*callState)
+	}
+	return nil
+}
+
+// callFor returns the call a voice webhook belongs to.
+func (c *Channel) callFor(form url.Values) *callState {
+	call := c.activeCall(normalizeNumber(form.Get("From")))
+	if call == nil || call.sid != form.Get("CallSid") {
+		return nil
+	}
+	return call
+}
+
+// handleCall answers an incoming call with the greeting and starts listening.
+// Callers rejected by policy are refused; those who need pairing get the
+// code by SMS.
+func (c *Channel) handleCall(ctx context.Context, req *http.Request) *twiml {
+	t := newTwiML()
+	from := normalizeNumber(req.PostForm.Get("From"))
+	if !c.voiceEnabled() || from == "" {
+		return t.reject()
+	}
+	if !c.checkDMPolicy(ctx, from) || !c.IsAllowed(from) {
+		return t.reject()
+	}
+
+	c.calls.Store(from, newCallState(req.PostForm.Get("CallSid")))
+	slog.Info("twilio call answered", "from", from, "call_sid", req.PostForm.Get("CallSid"))
+
+	greeting := c.config.VoiceGreeting
+	if greeting == "" {
+		greeting = defaultGreeting
+	}
+	t.say(greeting, c.config.VoiceLanguage)
+	return c.listen(t, c.baseURL(req))
+}
+
+// handleRecording takes the caller's recorded turn: transcription and the
+// agent run happen in the background while Twilio polls for the reply.
+func (c *Channel) handleRecording(ctx context.Context, req *http.Request) *twiml {
+	t := newTwiML()
+	call := c.callFor(req.PostForm)
+	if call == nil {
+		return t.hangup()
+	}
+	base := c.baseURL(req)
+	recURL := req.PostForm.Get("RecordingUrl")
+	if recURL == "" {
+		return c.listen(t, base)
+	}
+	call.setTurn(time.Now())
+	from := normalizeNumber(req.PostForm.Get("From"))
+	recordingSID := req.PostForm.Get("RecordingSid")
+	go func() {
+		defer safego.Recover(nil, "component", "twilio_call_turn")
+		c.handleTurn(ctx, call, from, recURL, recordingSID)
+	}()
+	return t.redirect(base + pollPath)
+}
+
+// handleTurn transcribes a recording and publishes it as the caller's message.
+func (c *Channel) handleTurn(ctx context.Context, call *callState, from, recURL, recordingSID string) {
+	// Twilio serves recordings as WAV when no extension is given; ask explicitly.
+	path, err := c.downloadFile(ctx, recURL+".wav", ".wav")
+	if err != nil {
+		slog.Warn("twilio: recording download failed", "call_sid", call.sid, "error", err)
+		call.reply("Sorry, something went wrong. Please try again.")
+		return
+	}
+	defer os.Remove(path)
+
+	sttCtx, cancel := context.WithTimeout(audio.WithChannel(ctx, c.Type()), sttTimeout)
+	res, err := c.audioMgr.Transcribe(sttCtx, audio.STTInput{FilePath: path, MimeType: "audio/wav", Filename: "call.wav"},
+		audio.STTOptions{Language: sttLanguage(c.config.VoiceLanguage)})
+	cancel()
+	if err != nil || res == nil || strings.TrimSpace(res.Text) == "" {
+		if err != nil {
+			slog.Warn("twilio: call transcription failed", "call_sid", call.sid, "error", err)
+		}
+		call.reply("Sorry, I didn't catch that.")
+		return
+	}
+
+	text := strings.TrimSpace(res.Text)
+	slog.Debug("twilio call turn", "from", from, "call_sid", call.sid, "preview", channels.Truncate(text, 50))
+	c.HandleMessage(from, from, text, nil, map[string]string{
+		"message_id":   recordingSID,
+		"user_id":      from,
+		"display_name": from,
+		"is_dm":        "true",
+		"local_key":    from,
+		"call_sid":     call.sid,
+	}, "direct")
+}
+
+// handlePoll speaks the agent's replies once they arrive and listens for
+// the next turn; until then it keeps Twilio polling.
+func (c *Channel) handlePoll(ctx context.Context, req *http.Request) *twiml {
+	t := newTwiML()
+	call := c.callFor(req.PostForm)
+	if call == nil {
+		return t.hangup()
+	}
+	base := c.baseURL(req)
+	texts := call.wait(req.Context(), pollWait)
+	if len(texts) == 0 {
+		if call.turnAge() > turnTimeout {
+			call.setTurn(time.Time{})
+			t.say("Sorry, this is taking too long. Please try again.", c.config.VoiceLanguage)
+			return c.listen(t, base)
+		}
+		return t.redirect(base + pollPath)
+	}
+	call.setTurn(time.Time{})
+
+	// One TTS budget for the whole response keeps it inside Twilio's timeout;
+	// replies past the budget fall back to <Say>.
+	ttsCtx, cancel := context.WithTimeout(ctx, ttsBudget)
+	defer cancel()
+	for _, text := range texts {
+		c.speak(ttsCtx, t, base, text)
+	}
+	return c.listen(t, base)
+}
+
+// handleCallStatus forgets a call once Twilio reports it ended.
+func (c *Channel) handleCallStatus(form url.Values) {
+	switch form.Get("CallStatus") {
+	case "completed", "busy", "failed", "no-answer", "canceled":
+		from := normalizeNumber(form.Get("From"))
+		if call := c.callFor(form); call != nil {
+			c.calls.CompareAndDelete(from, call)
+			slog.Info("twilio call ended", "from", from, "call_sid", call.sid)
+		}
+	}
+}
+
+// speak adds text to the response as TTS audio, or as <Say> when no TTS
+// provider is configured or synthesis fails.
+func (c *Channel) speak(ctx context.Context, t *twiml, base, text string) {
+	if c.audioMgr.HasProviders() && ctx.Err() == nil {
+		res, err := c.audioMgr.SynthesizeWithFallback(ctx, text, audio.TTSOptions{Voice: c.config.TTSVoice, Format: "mp3"})
+		if err == nil && len(res.Audio) > 0 {
+			t.play(base + globalMedia.put(res.Audio, res.MimeType))
+			return
+		}
+		slog.Warn("twilio: call TTS failed, using <Say>", "error", err)
+	}
+	t.say(channels.Truncate(text, maxSayLen), c.config.VoiceLanguage)
+}
+
+// listen records the caller's next turn. Twilio skips the action URL when
+// nothing was said and continues with the verbs after <Record>.
+func (c *Channel) listen(t *twiml, base string) *twiml {
+	t.record(base + recordingPath)
+	t.say("I didn't hear anything. Goodbye.", c.config.VoiceLanguage)
+	return t.hangup()
+}
+
+// sttLanguage turns a <Say> locale ("en-US") into the ISO-639-1 hint STT expects.
+func sttLanguage(locale string) string {
+	lang, _, _ := strings.Cut(locale, "-")
+	return strings.ToLower(lang)
+}
+
+// twiml builds a TwiML response document.
+type twiml struct {
+	b strings.Builder
+}
+
+func newTwiML() *twiml { return &twiml{} }
+
+func (t *twiml) say(text, language string) *twiml {
+	if language != "" {
+		fmt.Fprintf(&t.b, `<Say language="%s">`, xmlEscape(language))
+	} else {
+		t.b.WriteString("<Say>")
+	}
+	t.b.WriteString(xmlEscape(text))
+	t.b.WriteString("</Say>")
+	return t
+}
+
+func (t *twiml) play(u string) *twiml {
+	t.b.WriteString("<Play>" + xmlEscape(u) + "</Play>")
+	return t
+}
+
+func (t *twiml) record(action string) *twiml {
+	fmt.Fprintf(&t.b, `<Record action="%s" method="POST" maxLength="%d" timeout="3" playBeep="false" trim="trim-silence"/>`,
+		xmlEscape(action), recordMaxSeconds)
+	return t
+}
+
+func (t *twiml) redirect(u string) *twiml {
+	t.b.WriteString(`<Redirect method="POST">` + xmlEscape(u) + "</Redirect>")
+	return t
+}
+
+func (t *twiml) hangup() *twiml {
+	t.b.WriteString("<Hangup/>")
+	return t
+}
+
+func (t *twiml) reject() *twiml {
+	t.b.WriteString("<Reject/>")
+	return t
+}
+
+func (t *twiml) String() string {
+	return xml.Header + "<Response>" + t.b.String() + "</Response>"
+}
+
+func writeTwiML(w http.ResponseWriter, t *twiml) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	_, _ = w.Write([]byte(t.String()))
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
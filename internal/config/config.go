@@ -325,6 +325,28 @@ type MemoryConfig struct {
 	// Dreaming configures the episodic → long-term consolidation worker.
 	// nil = use hardcoded defaults (threshold=5, debounce=10min, enabled).
 	Dreaming *DreamingConfig `json:"dreaming,omitempty"`
+
+	// Rerank enables second-stage reranking of memory_search, vault_search and
+	// auto-inject results. nil = disabled.
+	Rerank *RerankConfig `json:"rerank,omitempty"`
+}
+
+// RerankConfig controls per-agent reranking. The reranker itself comes from
+// the tenant's system config (rerank.provider / rerank.model, else local BM25).
+type RerankConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	TopK    int  `json:"top_k,omitempty"` // results kept after reranking (default 5)
+}
+
+// RerankTopK returns the rerank budget, or 0 when reranking is disabled.
+func (c *MemoryConfig) RerankTopK() int {
+	if c == nil || c.Rerank == nil || !c.Rerank.Enabled {
+		return 0
+	}
+	if c.Rerank.TopK > 0 {
+		return c.Rerank.TopK
+	}
+	return 5
 }
 
 // DreamingConfig controls per-agent behaviour of the consolidation dreaming
@@ -268,6 +268,7 @@ type ProvidersConfig struct {
 	BytePlus       ProviderConfig  `json:"byteplus"`        // BytePlus ModelArk (Seed 2.0)
 	BytePlusCoding ProviderConfig  `json:"byteplus_coding"` // BytePlus ModelArk Coding Plan
 	Vertex         VertexConfig    `json:"vertex"`          // Google Cloud Vertex AI (OAuth2 service account + ADC)
+	LlamaCpp       LlamaCppConfig  `json:"llamacpp"`        // local llama.cpp server (no API key needed)
 }
 
 // VertexConfig configures Google Cloud Vertex AI.
@@ -284,7 +285,19 @@ type VertexConfig struct {
 // OllamaConfig configures a local (or self-hosted) Ollama instance.
 // No API key is required — Ollama accepts any Bearer token value.
 type OllamaConfig struct {
-	Host string `json:"host"` // Ollama server base URL, e.g. http://localhost:11434
+	Host      string `json:"host"`                 // Ollama server base URL, e.g. http://localhost:11434
+	Model     string `json:"model,omitempty"`      // default model (default "llama3.3")
+	KeepAlive string `json:"keep_alive,omitempty"` // how long models stay loaded, e.g. "10m", "-1"
+	NumCtx    int    `json:"num_ctx,omitempty"`    // context length requested per call (0 = Modelfile default)
+	AutoPull  *bool  `json:"auto_pull,omitempty"`  // pull missing models on demand (default true)
+}
+
+// LlamaCppConfig configures a local (or self-hosted) llama.cpp server (llama-server).
+// Models are loaded by the server at startup; GoClaw discovers them and their n_ctx.
+type LlamaCppConfig struct {
+	Host   string `json:"host"`              // server base URL, e.g. http://localhost:8080
+	Model  string `json:"model,omitempty"`   // model name sent on requests (default "default": the loaded model)
+	APIKey string `json:"api_key,omitempty"` // only when llama-server runs with --api-key
 }
 
 // ClaudeCLIConfig configures the Claude CLI provider (uses subscription, not API key).
@@ -347,6 +360,8 @@ func (p *ProvidersConfig) APIBaseForType(providerType string) string {
 		return p.ZaiCoding.APIBase
 	case "ollama_cloud":
 		return p.OllamaCloud.APIBase
+	case "llamacpp":
+		return p.LlamaCpp.Host
 	case "novita":
 		return p.Novita.APIBase
 	case "byteplus":
@@ -380,6 +395,7 @@ func (c *Config) HasAnyProvider() bool {
 		p.Zai.APIKey != "" ||
 		p.ZaiCoding.APIKey != "" ||
 		p.Ollama.Host != "" ||
+		p.LlamaCpp.Host != "" ||
 		p.OllamaCloud.APIKey != "" ||
 		p.ClaudeCLI.CLIPath != "" ||
 		p.ACP.Binary != "" ||
@@ -427,6 +443,17 @@ type GatewayConfig struct {
 	TaskRecoveryIntervalSec int                 `json:"task_recovery_interval_sec,omitempty"` // team task recovery ticker interval in seconds (default 300 = 5min)
 	BackgroundProvider      string              `json:"background_provider,omitempty"`        // LLM provider for background workers (vault enrichment, consolidation)
 	BackgroundModel         string              `json:"background_model,omitempty"`           // LLM model for background workers
+	BackgroundBatch         *BackgroundBatch    `json:"background_batch,omitempty"`           // submit background LLM calls via provider batch APIs (default disabled)
+}
+
+// BackgroundBatch routes background worker LLM calls (consolidation, vault
+// enrichment, KG extraction) through OpenAI/Anthropic batch APIs at batch
+// pricing. Providers without a batch API keep calling synchronously.
+type BackgroundBatch struct {
+	Enabled          bool `json:"enabled,omitempty"`
+	FlushIntervalSec int  `json:"flush_interval_sec,omitempty"` // accumulate requests this long before submitting (default 30)
+	PollIntervalSec  int  `json:"poll_interval_sec,omitempty"`  // batch status poll cadence (default 60)
+	MaxWaitMin       int  `json:"max_wait_min,omitempty"`       // give up on a batch after this long (default 1440 = 24h)
 }
 
 // ToolsConfig controls tool availability, policy, and web search.
@@ -534,6 +561,7 @@ type ToolPolicySpec struct {
 	AlsoAllow      []string                   `json:"alsoAllow,omitempty"`
 	ByProvider     map[string]*ToolPolicySpec `json:"byProvider,omitempty"`
 	Wait           *WaitToolPolicy            `json:"wait,omitempty"`
+	HTTPRequest    *HTTPRequestToolPolicy     `json:"http_request,omitempty"`
 	ToolCallPrefix string                     `json:"toolCallPrefix,omitempty"` // prefix to strip from model's tool call names before registry lookup
 }
 
@@ -543,12 +571,24 @@ type WaitToolPolicy struct {
 	MaxMs int `json:"max_ms,omitempty"`
 }
 
+// HTTPRequestToolPolicy restricts where the http_request tool may send
+// requests for one agent.
+type HTTPRequestToolPolicy struct {
+	// AllowedHosts limits requests to these hosts ("api.example.com" or
+	// "*.example.com"). Empty allows any public host.
+	AllowedHosts []string `json:"allowed_hosts,omitempty"`
+}
+
 // SessionsConfig controls session behavior.
 // Matching TS src/config/sessions/types.ts + src/config/types.base.ts.
 type SessionsConfig struct {
 	Scope   string `json:"scope,omitempty"`    // "per-sender" (default), "global"
 	DmScope string `json:"dm_scope,omitempty"` // "main", "per-peer", "per-channel-peer" (default), "per-account-channel-peer"
 	MainKey string `json:"main_key,omitempty"` // main session key suffix (default "main", used when dm_scope="main")
+
+	// CrossChannel gives contacts merged into one tenant user a single DM
+	// session per agent across all their channels (default false: per channel).
+	CrossChannel bool `json:"cross_channel,omitempty"`
 }
 
 // TtsConfig configures text-to-speech.
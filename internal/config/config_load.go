@@ -172,6 +172,8 @@ func (c *Config) applyEnvOverrides() {
 	envStr("GOCLAW_OLLAMA_HOST", &c.Providers.Ollama.Host)
 	envStr("GOCLAW_OLLAMA_CLOUD_API_KEY", &c.Providers.OllamaCloud.APIKey)
 	envStr("GOCLAW_OLLAMA_CLOUD_API_BASE", &c.Providers.OllamaCloud.APIBase)
+	envStr("GOCLAW_LLAMACPP_HOST", &c.Providers.LlamaCpp.Host)
+	envStr("GOCLAW_LLAMACPP_API_KEY", &c.Providers.LlamaCpp.APIKey)
 	// Google Cloud Vertex AI (OAuth2 service account + ADC).
 	// APIKey may hold inline SA JSON; CredentialsFile is a path to SA JSON.
 	// If both empty, ADC (GOOGLE_APPLICATION_CREDENTIALS / gcloud / GCE metadata) is used.
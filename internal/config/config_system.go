@@ -56,6 +56,12 @@ func (c *Config) ApplySystemConfigs(configs map[string]string) {
 	// Background workers (vault enrichment, consolidation)
 	str("background.provider", &c.Gateway.BackgroundProvider)
 	str("background.model", &c.Gateway.BackgroundModel)
+	if v, ok := configs["background.batch.enabled"]; ok && v != "" {
+		if c.Gateway.BackgroundBatch == nil {
+			c.Gateway.BackgroundBatch = &BackgroundBatch{}
+		}
+		c.Gateway.BackgroundBatch.Enabled = v == "true" || v == "1"
+	}
 
 	// Tools
 	str("tools.profile", &c.Tools.Profile)
@@ -226,6 +226,7 @@ func (w *dreamingWorker) synthesize(ctx context.Context, provider providers.Prov
 		ModelID:         model,
 		Purpose:         "dreaming-synthesis",
 		MaxOutputTokens: dreamingMaxTokens,
+		Batch:           true,
 	})
 	if err != nil {
 		return "", fmt.Errorf("dreaming chat: %w", err)
@@ -168,9 +168,6 @@ func (w *episodicWorker) summarizeFromMessages(ctx context.Context, provider pro
 		}
 	}
 
-	sctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
 	req := providers.ChatRequest{
 		Messages: []providers.Message{
 			{Role: "system", Content: summarizationPrompt},
@@ -179,11 +176,16 @@ func (w *episodicWorker) summarizeFromMessages(ctx context.Context, provider pro
 		Model:   model,
 		Options: map[string]any{"max_tokens": 1024, "temperature": 0.3},
 	}
-	resp, err := w.usageCaps.Chat(sctx, provider, req, usagecaps.ChatOptions{
+	opts := usagecaps.ChatOptions{
 		ModelID:         model,
 		Purpose:         "episodic-summary",
 		MaxOutputTokens: 1024,
-	})
+		Batch:           true,
+	}
+	sctx, cancel := context.WithTimeout(ctx, w.usageCaps.ChatTimeout(provider, opts, 30*time.Second))
+	defer cancel()
+
+	resp, err := w.usageCaps.Chat(sctx, provider, req, opts)
 	if err != nil {
 		return "", err
 	}
@@ -16,6 +16,10 @@ import (
 	usagecaps "github.com/nextlevelbuilder/goclaw/internal/usage/caps"
 )
 
+// detachedLimit caps how many events each worker keeps waiting on batched LLM
+// results at once. Waiting is cheap, and more concurrent requests fill batches.
+const detachedLimit = 16
+
 // ConsolidationDeps bundles all dependencies for the consolidation pipeline.
 type ConsolidationDeps struct {
 	EpisodicStore store.EpisodicStore
@@ -68,10 +72,18 @@ func Register(deps ConsolidationDeps) func() {
 		resolveConfig: newAgentStoreResolver(deps.AgentStore),
 	}
 
-	unsub1 := deps.EventBus.Subscribe(eventbus.EventSessionCompleted, episodic.Handle)
-	unsub2 := deps.EventBus.Subscribe(eventbus.EventEpisodicCreated, semantic.Handle)
+	episodicHandle, semanticHandle, dreamingHandle := episodic.Handle, semantic.Handle, dreaming.Handle
+	if deps.UsageCaps.Batching() {
+		// Batched LLM calls can take hours; keep them off the bus workers.
+		episodicHandle = eventbus.Detached(detachedLimit, episodicHandle)
+		semanticHandle = eventbus.Detached(detachedLimit, semanticHandle)
+		dreamingHandle = eventbus.Detached(detachedLimit, dreamingHandle)
+	}
+
+	unsub1 := deps.EventBus.Subscribe(eventbus.EventSessionCompleted, episodicHandle)
+	unsub2 := deps.EventBus.Subscribe(eventbus.EventEpisodicCreated, semanticHandle)
 	unsub3 := deps.EventBus.Subscribe(eventbus.EventEntityUpserted, dedup.Handle)
-	unsub4 := deps.EventBus.Subscribe(eventbus.EventEpisodicCreated, dreaming.Handle)
+	unsub4 := deps.EventBus.Subscribe(eventbus.EventEpisodicCreated, dreamingHandle)
 
 	// Periodic pruning of expired episodic summaries (runs every 6 hours).
 	pruneStop := make(chan struct{})
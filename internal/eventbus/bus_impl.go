@@ -140,15 +140,19 @@ func (b *busImpl) dispatch(event DomainEvent) {
 
 // callWithRetry calls handler with exponential backoff retry on error.
 func (b *busImpl) callWithRetry(handler DomainEventHandler, event DomainEvent) {
-	delay := b.cfg.RetryDelay
-	for attempt := range b.cfg.RetryAttempts {
-		err := b.safeCall(handler, event)
+	callWithRetry(b.ctx, b.cfg, handler, event)
+}
+
+func callWithRetry(ctx context.Context, cfg Config, handler DomainEventHandler, event DomainEvent) {
+	delay := cfg.RetryDelay
+	for attempt := range cfg.RetryAttempts {
+		err := safeCall(ctx, handler, event)
 		if err == nil {
 			return
 		}
 		slog.Warn("eventbus: handler error",
 			"type", event.Type, "attempt", attempt+1, "err", err)
-		if attempt < b.cfg.RetryAttempts-1 {
+		if attempt < cfg.RetryAttempts-1 {
 			time.Sleep(delay)
 			delay *= 2
 		}
@@ -156,12 +160,12 @@ func (b *busImpl) callWithRetry(handler DomainEventHandler, event DomainEvent) {
 }
 
 // safeCall invokes handler with panic recovery.
-func (b *busImpl) safeCall(handler DomainEventHandler, event DomainEvent) (err error) {
+func safeCall(ctx context.Context, handler DomainEventHandler, event DomainEvent) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("eventbus: handler panic: %v", r)
 			slog.Error("eventbus: handler panic", "type", event.Type, "panic", r)
 		}
 	}()
-	return handler(b.ctx, event)
+	return handler(ctx, event)
 }
@@ -202,3 +202,54 @@ func TestEmptySourceIDNeverDeduped(t *testing.T) {
 	}
 	_ = bus.Drain(time.Second)
 }
+
+func TestDetachedBoundsConcurrencyAndRetries(t *testing.T) {
+	release := make(chan struct{})
+	var running, peak, attempts atomic.Int32
+	handler := Detached(2, func(_ context.Context, e DomainEvent) error {
+		if e.SourceID == "flaky" {
+			if attempts.Add(1) == 1 {
+				return fmt.Errorf("transient")
+			}
+			return nil
+		}
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		return nil
+	})
+
+	ctx := t.Context()
+	for i := range 2 {
+		if err := handler(ctx, DomainEvent{Type: EventRunCompleted, SourceID: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Both slots are taken: a third event waits for one to free up.
+	blocked, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := handler(blocked, DomainEvent{Type: EventRunCompleted, SourceID: "2"}); err == nil {
+		t.Fatal("third event should wait for a free slot")
+	}
+	close(release)
+
+	if err := handler(ctx, DomainEvent{Type: EventRunCompleted, SourceID: "flaky"}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for attempts.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("flaky handler attempts = %d, want 2 (retried)", got)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", got)
+	}
+}
@@ -43,3 +43,28 @@ func DefaultConfig() Config {
 		DedupTTL:      5 * time.Minute,
 	}
 }
+
+// Detached wraps handler to run off the bus worker so a slow handler (e.g.
+// one waiting on a provider batch API) does not hold it. At most limit calls
+// run at once; beyond that the bus worker waits for a free slot, so the bus
+// queue applies backpressure instead of goroutines piling up. Errors are
+// retried with DefaultConfig's attempts and backoff, as the bus would.
+func Detached(limit int, handler DomainEventHandler) DomainEventHandler {
+	if limit <= 0 {
+		limit = 1
+	}
+	slots := make(chan struct{}, limit)
+	cfg := DefaultConfig()
+	return func(ctx context.Context, event DomainEvent) error {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		go func() {
+			defer func() { <-slots }()
+			callWithRetry(context.WithoutCancel(ctx), cfg, handler, event)
+		}()
+		return nil
+	}
+}
@@ -345,7 +345,7 @@ func maskInstance(inst store.ChannelInstanceData) map[string]any {
 // channels neither API accepts.
 func isValidChannelType(ct string) bool {
 	switch ct {
-	case "telegram", "discord", "slack", "whatsapp", "zalo_oa", "zalo_personal", "feishu", "facebook", "pancake", "bitrix24":
+	case "telegram", "discord", "slack", "whatsapp", "zalo_oa", "zalo_personal", "feishu", "facebook", "pancake", "bitrix24", "matrix", "email", "teams", "signal", "bridge", "twilio":
 		return true
 	}
 	return false
@@ -0,0 +1,156 @@
+package methods
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/gateway"
+	"github.com/nextlevelbuilder/goclaw/internal/i18n"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+// sentMessageRetractor is the subset of channels.Manager used by MessagesMethods.
+type sentMessageRetractor interface {
+	ListSentMessages(ctx context.Context, opts store.SentMessageListOpts) ([]store.SentMessage, error)
+	RetractSentMessage(ctx context.Context, id uuid.UUID) (*store.SentMessage, error)
+}
+
+// MessagesMethods lets operators inspect and retract agent replies already
+// delivered to channels.
+type MessagesMethods struct {
+	manager  sentMessageRetractor
+	eventPub bus.EventPublisher
+}
+
+func NewMessagesMethods(manager *channels.Manager, eventPub bus.EventPublisher) *MessagesMethods {
+	return &MessagesMethods{manager: manager, eventPub: eventPub}
+}
+
+func (m *MessagesMethods) Register(router *gateway.MethodRouter) {
+	router.Register(protocol.MethodMessagesSentList, m.handleList)
+	router.Register(protocol.MethodMessagesRetract, m.handleRetract)
+}
+
+type messagesSentListParams struct {
+	RunID   string `json:"runId"`
+	Channel string `json:"channel"`
+	ChatID  string `json:"chatId"`
+	Limit   int    `json:"limit"`
+}
+
+func (m *MessagesMethods) handleList(ctx context.Context, client *gateway.Client, req *protocol.RequestFrame) {
+	locale := store.LocaleFromContext(ctx)
+	var params messagesSentListParams
+	if req.Params != nil {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrInvalidRequest, i18n.T(locale, i18n.MsgInvalidJSON)))
+			return
+		}
+	}
+	if params.RunID == "" && (params.Channel == "" || params.ChatID == "") {
+		client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrInvalidRequest, i18n.T(locale, i18n.MsgRequired, "runId or channel+chatId")))
+		return
+	}
+
+	msgs, err := m.manager.ListSentMessages(ctx, store.SentMessageListOpts{
+		RunID:   params.RunID,
+		Channel: params.Channel,
+		ChatID:  params.ChatID,
+		Limit:   params.Limit,
+	})
+	if err != nil {
+		m.sendStoreError(client, req, locale, "list", "", err)
+		return
+	}
+	if msgs == nil {
+		msgs = []store.SentMessage{}
+	}
+	client.SendResponse(protocol.NewOKResponse(req.ID, map[string]any{"messages": msgs}))
+}
+
+type messagesRetractParams struct {
+	ID    string `json:"id"`
+	RunID string `json:"runId"`
+}
+
+// handleRetract deletes one tracked message (id) or every visible message of
+// a run (runId) from its chat.
+func (m *MessagesMethods) handleRetract(ctx context.Context, client *gateway.Client, req *protocol.RequestFrame) {
+	locale := store.LocaleFromContext(ctx)
+	var params messagesRetractParams
+	if req.Params != nil {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrInvalidRequest, i18n.T(locale, i18n.MsgInvalidJSON)))
+			return
+		}
+	}
+
+	var ids []uuid.UUID
+	switch {
+	case params.ID != "":
+		id, err := uuid.Parse(params.ID)
+		if err != nil {
+			client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrInvalidRequest, i18n.T(locale, i18n.MsgInvalidID, "message")))
+			return
+		}
+		ids = []uuid.UUID{id}
+	case params.RunID != "":
+		msgs, err := m.manager.ListSentMessages(ctx, store.SentMessageListOpts{RunID: params.RunID, Limit: 200})
+		if err != nil {
+			m.sendStoreError(client, req, locale, "list", "", err)
+			return
+		}
+		for _, sm := range msgs {
+			if sm.Status != store.SentMessageStatusRetracted {
+				ids = append(ids, sm.ID)
+			}
+		}
+	default:
+		client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrInvalidRequest, i18n.T(locale, i18n.MsgRequired, "id or runId")))
+		return
+	}
+
+	retracted := make([]string, 0, len(ids))
+	failed := map[string]string{}
+	for _, id := range ids {
+		if _, err := m.manager.RetractSentMessage(ctx, id); err != nil {
+			// A single-message retract reports the error directly.
+			if params.ID != "" {
+				m.sendStoreError(client, req, locale, "retract", params.ID, err)
+				return
+			}
+			slog.Warn("messages.retract failed", "id", id, "run_id", params.RunID, "error", err)
+			failed[id.String()] = err.Error()
+			continue
+		}
+		retracted = append(retracted, id.String())
+		emitAudit(m.eventPub, client, "messages.retract", "sent_message", id.String())
+	}
+
+	client.SendResponse(protocol.NewOKResponse(req.ID, map[string]any{
+		"retracted": retracted,
+		"failed":    failed,
+	}))
+}
+
+func (m *MessagesMethods) sendStoreError(client *gateway.Client, req *protocol.RequestFrame, locale, op, id string, err error) {
+	switch {
+	case errors.Is(err, channels.ErrSentMessagesUnavailable):
+		client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrInvalidRequest, i18n.T(locale, i18n.MsgSentMessagesUnavailable)))
+	case errors.Is(err, sql.ErrNoRows):
+		client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrNotFound, i18n.T(locale, i18n.MsgNotFound, "message", id)))
+	case errors.Is(err, channels.ErrNotEditable):
+		client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrInvalidRequest, err.Error()))
+	default:
+		slog.Warn("messages."+op+" failed", "error", err)
+		client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrInternal, i18n.T(locale, i18n.MsgInternalError, "messages")))
+	}
+}
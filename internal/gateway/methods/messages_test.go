@@ -0,0 +1,91 @@
+package methods
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/gateway"
+	"github.com/nextlevelbuilder/goclaw/internal/permissions"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+type stubRetractor struct {
+	msgs      []store.SentMessage
+	retracted []uuid.UUID
+	err       error
+}
+
+func (s *stubRetractor) ListSentMessages(_ context.Context, opts store.SentMessageListOpts) ([]store.SentMessage, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	var out []store.SentMessage
+	for _, sm := range s.msgs {
+		if sm.RunID == opts.RunID {
+			out = append(out, sm)
+		}
+	}
+	return out, nil
+}
+
+func (s *stubRetractor) RetractSentMessage(_ context.Context, id uuid.UUID) (*store.SentMessage, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.retracted = append(s.retracted, id)
+	return &store.SentMessage{ID: id, Status: store.SentMessageStatusRetracted}, nil
+}
+
+func TestMessagesRetractRunSkipsRetracted(t *testing.T) {
+	visible, gone := uuid.New(), uuid.New()
+	stub := &stubRetractor{msgs: []store.SentMessage{
+		{ID: visible, RunID: "run-1", Status: store.SentMessageStatusSent},
+		{ID: gone, RunID: "run-1", Status: store.SentMessageStatusRetracted},
+	}}
+	m := &MessagesMethods{manager: stub}
+	tenantID := uuid.Must(uuid.NewV7())
+	client, responses := gateway.NewCapturingTestClient(permissions.RoleAdmin, tenantID, "op", 1)
+	ctx := store.WithTenantID(context.Background(), tenantID)
+	m.handleRetract(ctx, client, sessionReqFrame(t, protocol.MethodMessagesRetract, map[string]any{"runId": "run-1"}))
+
+	resp := readTimelineResponse(t, responses)
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+	if len(stub.retracted) != 1 || stub.retracted[0] != visible {
+		t.Fatalf("retracted = %v, want [%s]", stub.retracted, visible)
+	}
+}
+
+func TestMessagesRetractErrors(t *testing.T) {
+	tenantID := uuid.Must(uuid.NewV7())
+	ctx := store.WithTenantID(context.Background(), tenantID)
+
+	tests := []struct {
+		name     string
+		err      error
+		params   map[string]any
+		wantCode string
+	}{
+		{"missing params", nil, map[string]any{}, protocol.ErrInvalidRequest},
+		{"bad id", nil, map[string]any{"id": "nope"}, protocol.ErrInvalidRequest},
+		{"tracking disabled", channels.ErrSentMessagesUnavailable, map[string]any{"id": uuid.NewString()}, protocol.ErrInvalidRequest},
+		{"store failure", errors.New("pq: boom"), map[string]any{"id": uuid.NewString()}, protocol.ErrInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MessagesMethods{manager: &stubRetractor{err: tt.err}}
+			client, responses := gateway.NewCapturingTestClient(permissions.RoleAdmin, tenantID, "op", 1)
+			m.handleRetract(ctx, client, sessionReqFrame(t, protocol.MethodMessagesRetract, tt.params))
+			resp := readTimelineResponse(t, responses)
+			if resp.Error == nil || resp.Error.Code != tt.wantCode {
+				t.Fatalf("error = %+v, want %s", resp.Error, tt.wantCode)
+			}
+		})
+	}
+}
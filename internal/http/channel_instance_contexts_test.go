@@ -23,6 +23,10 @@ func (s *fakeChannelContextContactStore) UpsertContact(context.Context, string,
 	return nil
 }
 
+func (s *fakeChannelContextContactStore) SetDirectChat(context.Context, string, string, string, string) error {
+	return nil
+}
+
 func (s *fakeChannelContextContactStore) ListContacts(_ context.Context, opts store.ContactListOpts) ([]store.ChannelContact, error) {
 	s.seenOpts = append(s.seenOpts, opts)
 	var out []store.ChannelContact
@@ -72,6 +76,10 @@ func (s *fakeChannelContextContactStore) GetContactsByMergedID(context.Context,
 	return nil, nil
 }
 
+func (s *fakeChannelContextContactStore) GetContactsByTenantUserID(context.Context, string) ([]store.ChannelContact, error) {
+	return nil, nil
+}
+
 func (s *fakeChannelContextContactStore) ResolveTenantUserID(context.Context, string, string) (string, error) {
 	return "", nil
 }
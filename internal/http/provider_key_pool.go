@@ -0,0 +1,71 @@
+package http
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/nextlevelbuilder/goclaw/internal/providerresolve"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// providerDetailResponse is GET /v1/providers/{id}: the provider row plus
+// per-key usage when it rotates several API keys.
+type providerDetailResponse struct {
+	store.LLMProviderData
+	KeyPool *providers.APIKeyPoolStatus `json:"key_pool,omitempty"`
+}
+
+// withKeyPool installs the provider's extra API keys (if any) on prov.
+func (h *ProvidersHandler) withKeyPool(prov providers.Provider, p *store.LLMProviderData) providers.Provider {
+	return providerresolve.WithKeyPool(prov, p, h.secretStore)
+}
+
+// hasAPIKey reports whether p has a primary or pooled API key.
+func (h *ProvidersHandler) hasAPIKey(p *store.LLMProviderData) bool {
+	return providerresolve.HasAPIKey(p, h.secretStore)
+}
+
+// runtimeKeyPool reports the registered provider's key pool, or nil when it
+// isn't registered or uses a single key.
+func (h *ProvidersHandler) runtimeKeyPool(p *store.LLMProviderData) *providers.APIKeyPoolStatus {
+	if h.providerReg == nil {
+		return nil
+	}
+	prov, err := h.providerReg.GetForTenant(p.TenantID, p.Name)
+	if err != nil {
+		return nil
+	}
+	kp, ok := prov.(providers.KeyPooled)
+	if !ok || kp.KeyPool() == nil {
+		return nil
+	}
+	status := kp.KeyPool().Status()
+	return &status
+}
+
+// parseAPIKeys reads the "api_keys" request field: extra keys on top of api_key.
+func parseAPIKeys(v any) ([]string, error) {
+	list, ok := v.([]any)
+	if !ok && v != nil {
+		return nil, errors.New("api_keys must be an array of strings")
+	}
+	keys := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, errors.New("api_keys must be an array of strings")
+		}
+		keys = append(keys, s)
+	}
+	return keys, nil
+}
+
+// saveAPIKeys replaces a provider's extra API keys in config_secrets.
+func (h *ProvidersHandler) saveAPIKeys(ctx context.Context, providerID uuid.UUID, keys []string) error {
+	if h.secretStore == nil {
+		return errors.New("api_keys requires the config secrets store")
+	}
+	return store.SaveProviderAPIKeys(ctx, h.secretStore, providerID, keys)
+}
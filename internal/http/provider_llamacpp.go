@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// newLlamaCppProvider builds the llama.cpp provider for a DB row (same as
+// startup) and discovers its served models in the background.
+// In Docker, localhost is swapped for host.docker.internal.
+func (h *ProvidersHandler) newLlamaCppProvider(p *store.LLMProviderData) *providers.LlamaCppProvider {
+	host := p.APIBase
+	if host == "" {
+		host = providers.LlamaCppDefaultAPIBase
+	}
+	prov := h.llamaCppClient(p, config.DockerLocalhost(host))
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if _, err := prov.DiscoverModels(ctx); err != nil {
+			slog.Warn("providers.llamacpp.discover", "provider", p.Name, "error", err)
+		}
+	}()
+	return prov
+}
+
+// llamaCppClient builds a llama.cpp provider for a DB row against host,
+// without starting discovery.
+func (h *ProvidersHandler) llamaCppClient(p *store.LLMProviderData, host string) *providers.LlamaCppProvider {
+	prov := providers.NewLlamaCppProvider(p.Name, host, "").
+		WithAPIKey(p.APIKey)
+	if h.modelReg != nil {
+		prov.WithRegistry(h.modelReg)
+	}
+	return prov
+}
+
+// fetchLlamaCppModels lists the server's models with the context window it
+// runs each one with (/v1/models + /props). An empty api_base falls back to
+// config/env, then the local default.
+func (h *ProvidersHandler) fetchLlamaCppModels(ctx context.Context, p *store.LLMProviderData) ([]ModelInfo, error) {
+	specs, err := h.llamaCppClient(p, h.resolveAPIBase(p)).DiscoverModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]ModelInfo, 0, len(specs))
+	for _, s := range specs {
+		models = append(models, ModelInfo{ID: s.ID, Name: s.ID, ContextWindow: s.ContextWindow})
+	}
+	return models, nil
+}
@@ -15,14 +15,16 @@ import (
 
 // ModelInfo is a normalized model entry returned by the list-models endpoint.
 type ModelInfo struct {
-	ID        string                         `json:"id"`
-	Name      string                         `json:"name,omitempty"`
-	Reasoning *providers.ReasoningCapability `json:"reasoning,omitempty"`
+	ID            string                         `json:"id"`
+	Name          string                         `json:"name,omitempty"`
+	Reasoning     *providers.ReasoningCapability `json:"reasoning,omitempty"`
+	ContextWindow int                            `json:"context_window,omitempty"` // local Ollama / llama.cpp: effective n_ctx
 }
 
 type ProviderModelsResponse struct {
 	Models            []ModelInfo                    `json:"models"`
 	ReasoningDefaults *store.ProviderReasoningConfig `json:"reasoning_defaults,omitempty"`
+	Pulls             []providers.OllamaPullStatus   `json:"pulls,omitempty"` // local Ollama: recent model pulls
 }
 
 // handleListProviderModels proxies to the upstream provider API to list
@@ -44,10 +46,14 @@ func (h *ProvidersHandler) handleListProviderModels(w http.ResponseWriter, r *ht
 	}
 
 	respond := func(models []ModelInfo) {
-		writeJSON(w, http.StatusOK, ProviderModelsResponse{
+		resp := ProviderModelsResponse{
 			Models:            models,
 			ReasoningDefaults: reasoningDefaultsForModels(p.Settings, models),
-		})
+		}
+		if op := h.runtimeOllama(p); op != nil {
+			resp.Pulls = op.Pulls().List()
+		}
+		writeJSON(w, http.StatusOK, resp)
 	}
 
 	// Claude CLI doesn't need an API key — return hardcoded models
@@ -82,10 +88,33 @@ func (h *ProvidersHandler) handleListProviderModels(w http.ResponseWriter, r *ht
 			respond([]ModelInfo{})
 			return
 		}
+		if p.ProviderType == store.ProviderOllama {
+			models = h.withOllamaContextWindows(p.Name, models)
+		}
+		respond(models)
+		return
+	}
+
+	// llama.cpp: /v1/models plus /props for the context window the server runs.
+	if p.ProviderType == store.ProviderLlamaCpp {
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+		models, err := h.fetchLlamaCppModels(ctx, p)
+		if err != nil {
+			slog.Warn("providers.models.llamacpp", "provider", p.Name, "error", err)
+			respond([]ModelInfo{})
+			return
+		}
 		respond(models)
 		return
 	}
 
+	// Bedrock may authenticate via the AWS default credential chain (no API key).
+	if p.ProviderType == store.ProviderBedrock {
+		respond(bedrockModels())
+		return
+	}
+
 	if p.APIKey == "" {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": i18n.T(locale, i18n.MsgRequired, "API key")})
 		return
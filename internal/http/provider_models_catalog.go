@@ -101,3 +101,21 @@ func chatGPTOAuthModels() []ModelInfo {
 		{ID: "gpt-5.1", Name: "GPT-5.1"},
 	})
 }
+
+// bedrockModels returns a hardcoded list of common Bedrock Converse model IDs.
+// Listing foundation models needs the separate control-plane API (bedrock, not
+// bedrock-runtime) and extra IAM permissions, so a static catalog is used.
+// Claude entries use US cross-region inference profiles, which newer models require.
+func bedrockModels() []ModelInfo {
+	return []ModelInfo{
+		{ID: "us.anthropic.claude-opus-4-6-v1:0", Name: "Claude Opus 4.6"},
+		{ID: "us.anthropic.claude-sonnet-4-6-v1:0", Name: "Claude Sonnet 4.6"},
+		{ID: "us.anthropic.claude-sonnet-4-5-20250929-v1:0", Name: "Claude Sonnet 4.5"},
+		{ID: "us.anthropic.claude-haiku-4-5-20251001-v1:0", Name: "Claude Haiku 4.5"},
+		{ID: "us.amazon.nova-premier-v1:0", Name: "Amazon Nova Premier"},
+		{ID: "us.amazon.nova-pro-v1:0", Name: "Amazon Nova Pro"},
+		{ID: "us.amazon.nova-lite-v1:0", Name: "Amazon Nova Lite"},
+		{ID: "us.meta.llama4-maverick-17b-instruct-v1:0", Name: "Llama 4 Maverick 17B"},
+		{ID: "mistral.mistral-large-2407-v1:0", Name: "Mistral Large (24.07)"},
+	}
+}
@@ -2,11 +2,15 @@ package http
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
@@ -596,3 +600,125 @@ func TestProvidersHandlerListProviderModelsOllamaCloudSendsAuthHeader(t *testing
 		t.Errorf("models = %#v, want [{ID: cloud-model:latest}]", result.Models)
 	}
 }
+
+// TestProvidersHandlerPullOllamaModelReportsProgress verifies that
+// POST /v1/providers/{id}/models/pull starts a pull on the registered native
+// provider and that its progress shows up in the models listing.
+func TestProvidersHandlerPullOllamaModelReportsProgress(t *testing.T) {
+	token := setupProvidersAdminToken(t)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/pull" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, `{"status":"pulling manifest"}`+"\n"+`{"status":"success"}`+"\n")
+	}))
+	t.Cleanup(upstream.Close)
+
+	providerStore := newMockProviderStore()
+	provider := newOllamaProvider(upstream.URL + "/v1")
+	provider.TenantID = store.MasterTenantID
+	if err := providerStore.CreateProvider(t.Context(), provider); err != nil {
+		t.Fatalf("CreateProvider() error = %v", err)
+	}
+	providerReg := providers.NewRegistry(nil)
+	providerReg.RegisterForTenant(provider.TenantID, providers.NewOllamaProvider(provider.Name, upstream.URL, ""))
+
+	handler := NewProvidersHandler(providerStore, newMockSecretsStore(), providerReg, "")
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/providers/"+provider.ID.String()+"/models/pull", strings.NewReader(`{"model":"qwen3:8b"}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("pull status code = %d, want %d: %s", w.Code, http.StatusAccepted, w.Body.String())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		result, code := ollamaModelsRequest(t, mux, provider.ID, token)
+		if code != http.StatusOK {
+			t.Fatalf("models status code = %d", code)
+		}
+		if len(result.Pulls) == 1 && result.Pulls[0].Done {
+			if result.Pulls[0].Model != "qwen3:8b" || result.Pulls[0].Error != "" {
+				t.Fatalf("pull = %+v", result.Pulls[0])
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pull did not finish: %+v", result.Pulls)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestProvidersHandlerPullModelRejectsNonOllama(t *testing.T) {
+	token := setupProvidersAdminToken(t)
+	providerStore := newMockProviderStore()
+	provider := &store.LLMProviderData{
+		BaseModel:    store.BaseModel{ID: uuid.New()},
+		Name:         "openai",
+		ProviderType: store.ProviderOpenAICompat,
+		APIKey:       "sk-test",
+		Enabled:      true,
+	}
+	if err := providerStore.CreateProvider(t.Context(), provider); err != nil {
+		t.Fatalf("CreateProvider() error = %v", err)
+	}
+	handler := NewProvidersHandler(providerStore, newMockSecretsStore(), providers.NewRegistry(nil), "")
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/providers/"+provider.ID.String()+"/models/pull", strings.NewReader(`{"model":"gpt-4o"}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status code = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestProvidersHandlerListProviderModelsLlamaCppReportsContextWindow(t *testing.T) {
+	token := setupProvidersAdminToken(t)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/models":
+			_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"qwen3-8b","meta":{"n_ctx_train":40960}}]}`))
+		case "/props":
+			_, _ = w.Write([]byte(`{"default_generation_settings":{"n_ctx":32768}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(upstream.Close)
+
+	providerStore := newMockProviderStore()
+	provider := &store.LLMProviderData{
+		BaseModel:    store.BaseModel{ID: uuid.New()},
+		Name:         "local-llamacpp",
+		ProviderType: store.ProviderLlamaCpp,
+		APIBase:      upstream.URL + "/v1",
+		Enabled:      true,
+	}
+	if err := providerStore.CreateProvider(t.Context(), provider); err != nil {
+		t.Fatalf("CreateProvider() error = %v", err)
+	}
+
+	handler := NewProvidersHandler(providerStore, newMockSecretsStore(), nil, "")
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	// No API key: llama.cpp must not hit the generic key guard.
+	result, code := ollamaModelsRequest(t, mux, provider.ID, token)
+	if code != http.StatusOK {
+		t.Fatalf("status code = %d, want %d", code, http.StatusOK)
+	}
+	if len(result.Models) != 1 || result.Models[0].ID != "qwen3-8b" || result.Models[0].ContextWindow != 32768 {
+		t.Fatalf("models = %#v, want qwen3-8b with context_window 32768", result.Models)
+	}
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/i18n"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// newOllamaProvider builds the native Ollama provider for a DB row (same as
+// startup) and discovers its installed models in the background.
+func (h *ProvidersHandler) newOllamaProvider(p *store.LLMProviderData) *providers.OllamaProvider {
+	s := store.ParseOllamaProviderSettings(p.Settings)
+	host := p.APIBase
+	if host == "" {
+		host = providers.OllamaDefaultAPIBase
+	}
+	prov := providers.NewOllamaProvider(p.Name, config.DockerLocalhost(host), s.Model).
+		WithAPIKey(p.APIKey).
+		WithKeepAlive(s.KeepAlive).
+		WithNumCtx(s.NumCtx)
+	if h.modelReg != nil {
+		prov.WithRegistry(h.modelReg)
+	}
+	if s.AutoPull != nil {
+		prov.WithAutoPull(*s.AutoPull)
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if _, err := prov.DiscoverModels(ctx); err != nil {
+			slog.Warn("providers.ollama.discover", "provider", p.Name, "error", err)
+		}
+	}()
+	return prov
+}
+
+// runtimeOllama returns the registered native provider for an Ollama row, or
+// nil when it isn't registered (or the registry is unavailable).
+func (h *ProvidersHandler) runtimeOllama(p *store.LLMProviderData) *providers.OllamaProvider {
+	if h.providerReg == nil || p.ProviderType != store.ProviderOllama {
+		return nil
+	}
+	prov, err := h.providerReg.GetForTenant(p.TenantID, p.Name)
+	if err != nil {
+		return nil
+	}
+	op, _ := prov.(*providers.OllamaProvider)
+	return op
+}
+
+// withOllamaContextWindows fills each model's effective context window from
+// the ModelRegistry (discovered, or resolved on first lookup via /api/show).
+func (h *ProvidersHandler) withOllamaContextWindows(providerName string, models []ModelInfo) []ModelInfo {
+	if h.modelReg == nil {
+		return models
+	}
+	for i := range models {
+		if spec := h.modelReg.Resolve(providerName, models[i].ID); spec != nil {
+			models[i].ContextWindow = spec.ContextWindow
+		}
+	}
+	return models
+}
+
+// handlePullProviderModel starts pulling a model on a local Ollama provider.
+// Progress is reported in the "pulls" field of GET /v1/providers/{id}/models.
+//
+//	POST /v1/providers/{id}/models/pull
+//	Body: {"model": "qwen3:8b"}
+//	Response: 202 {"pull": {...status...}}
+func (h *ProvidersHandler) handlePullProviderModel(w http.ResponseWriter, r *http.Request) {
+	locale := extractLocale(r)
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": i18n.T(locale, i18n.MsgInvalidID, "provider")})
+		return
+	}
+
+	var req struct {
+		Model string `json:"model"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": i18n.T(locale, i18n.MsgInvalidJSON)})
+		return
+	}
+	req.Model = strings.TrimSpace(req.Model)
+	if req.Model == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": i18n.T(locale, i18n.MsgRequired, "model")})
+		return
+	}
+
+	p, err := h.store.GetProvider(r.Context(), id)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": i18n.T(locale, i18n.MsgNotFound, "provider", id.String())})
+		return
+	}
+	if p.ProviderType != store.ProviderOllama {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "model pull is only supported for local Ollama providers"})
+		return
+	}
+	prov := h.runtimeOllama(p)
+	if prov == nil {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "provider not registered: " + p.Name})
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]any{"pull": prov.StartPull(req.Model)})
+}
@@ -114,6 +114,20 @@ func (h *ProvidersHandler) handleVerifyProvider(w http.ResponseWriter, r *http.R
 	}
 
 	if pingMode {
+		// Local Ollama / llama.cpp: registration alone doesn't prove the server is up.
+		if hp, ok := provider.(interface {
+			Health(context.Context) (string, error)
+		}); ok {
+			ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+			defer cancel()
+			version, err := hp.Health(ctx)
+			if err != nil {
+				writeJSON(w, http.StatusOK, map[string]any{"valid": false, "error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"valid": true, "version": version})
+			return
+		}
 		writeJSON(w, http.StatusOK, map[string]any{"valid": true})
 		return
 	}
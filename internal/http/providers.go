@@ -18,10 +18,10 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/nextlevelbuilder/goclaw/internal/bus"
-	"github.com/nextlevelbuilder/goclaw/internal/config"
 	"github.com/nextlevelbuilder/goclaw/internal/i18n"
 	"github.com/nextlevelbuilder/goclaw/internal/oauth"
 	"github.com/nextlevelbuilder/goclaw/internal/permissions"
+	"github.com/nextlevelbuilder/goclaw/internal/providerresolve"
 	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/security"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
@@ -166,6 +166,7 @@ func (h *ProvidersHandler) RegisterRoutes(mux *http.ServeMux) {
 
 	// Model listing (proxied to upstream provider API)
 	mux.HandleFunc("GET /v1/providers/{id}/models", h.auth(h.handleListProviderModels))
+	mux.HandleFunc("POST /v1/providers/{id}/models/pull", h.auth(h.handlePullProviderModel))
 
 	// Provider + model verification (pre-flight check)
 	mux.HandleFunc("POST /v1/providers/{id}/reconnect", h.auth(h.handleReconnectProvider))
@@ -247,13 +248,14 @@ func (h *ProvidersHandler) registerInMemory(p *store.LLMProviderData) providerRu
 	}
 	// Ollama doesn't need an API key — handle before the key guard (same as startup).
 	// In Docker, swap localhost → host.docker.internal so the container can reach the host.
-	// api_base is stored with /v1 (normalized at write time), so no suffix appending needed.
+	// api_base is stored with /v1 (normalized at write time); the native provider strips it.
 	if p.ProviderType == store.ProviderOllama {
-		host := p.APIBase
-		if host == "" {
-			host = "http://localhost:11434/v1"
-		}
-		h.providerReg.RegisterForTenant(p.TenantID, providers.NewOpenAIProvider(p.Name, "ollama", config.DockerLocalhost(host), "llama3.3"))
+		h.providerReg.RegisterForTenant(p.TenantID, h.newOllamaProvider(p))
+		return providerRuntimeRegistered
+	}
+	// llama.cpp only needs a key when the server runs with --api-key.
+	if p.ProviderType == store.ProviderLlamaCpp {
+		h.providerReg.RegisterForTenant(p.TenantID, h.newLlamaCppProvider(p))
 		return providerRuntimeRegistered
 	}
 	// Vertex supports ADC (empty api_key) — handle before the generic key guard.
@@ -279,7 +281,25 @@ func (h *ProvidersHandler) registerInMemory(p *store.LLMProviderData) providerRu
 		h.providerReg.RegisterForTenant(p.TenantID, prov)
 		return providerRuntimeRegistered
 	}
-	if p.APIKey == "" {
+	// Bedrock supports the AWS default credential chain (empty api_key) — handle before the key guard.
+	if p.ProviderType == store.ProviderBedrock {
+		bsettings := store.ParseBedrockProviderSettings(p.Settings)
+		if bsettings == nil {
+			slog.Warn("bedrock: missing region in settings, cannot register", "name", p.Name)
+			return providerRuntimeInvalidConfig
+		}
+		prov, err := providers.NewBedrockProviderWithTimeout(providerresolve.BedrockConfigFromDB(p, bsettings))
+		if err != nil {
+			slog.Warn("bedrock: register in-memory failed", "name", p.Name, "error", err)
+			return providerRuntimeInvalidConfig
+		}
+		if h.modelReg != nil {
+			prov.WithRegistry(h.modelReg)
+		}
+		h.providerReg.RegisterForTenant(p.TenantID, prov)
+		return providerRuntimeRegistered
+	}
+	if !h.hasAPIKey(p) {
 		return providerRuntimeMissingCredential
 	}
 	apiBase := h.resolveAPIBase(p)
@@ -299,21 +319,23 @@ func (h *ProvidersHandler) registerInMemory(p *store.LLMProviderData) providerRu
 		if h.modelReg != nil {
 			anthOpts = append(anthOpts, providers.WithAnthropicRegistry(h.modelReg))
 		}
-		h.providerReg.RegisterForTenant(p.TenantID, providers.NewAnthropicProvider(p.APIKey, anthOpts...))
+		h.providerReg.RegisterForTenant(p.TenantID, h.withKeyPool(providers.NewAnthropicProvider(p.APIKey, anthOpts...), p))
+	case store.ProviderGeminiNative:
+		h.providerReg.RegisterForTenant(p.TenantID, h.withKeyPool(providers.NewGeminiProvider(p.Name, p.APIKey, apiBase, ""), p))
 	case store.ProviderDashScope:
-		h.providerReg.RegisterForTenant(p.TenantID, providers.NewDashScopeProvider(p.Name, p.APIKey, apiBase, ""))
+		h.providerReg.RegisterForTenant(p.TenantID, h.withKeyPool(providers.NewDashScopeProvider(p.Name, p.APIKey, apiBase, ""), p))
 	case store.ProviderBailian:
 		base := apiBase
 		if base == "" {
 			base = "https://coding-intl.dashscope.aliyuncs.com/v1"
 		}
-		h.providerReg.RegisterForTenant(p.TenantID, providers.NewOpenAIProvider(p.Name, p.APIKey, base, "qwen3.5-plus"))
+		h.providerReg.RegisterForTenant(p.TenantID, h.withKeyPool(providers.NewOpenAIProvider(p.Name, p.APIKey, base, "qwen3.5-plus"), p))
 	case store.ProviderNovita:
 		base := apiBase
 		if base == "" {
 			base = store.NovitaDefaultAPIBase
 		}
-		h.providerReg.RegisterForTenant(p.TenantID, providers.NewOpenAIProvider(p.Name, p.APIKey, base, store.NovitaDefaultModel))
+		h.providerReg.RegisterForTenant(p.TenantID, h.withKeyPool(providers.NewOpenAIProvider(p.Name, p.APIKey, base, store.NovitaDefaultModel), p))
 	case store.ProviderKimiCoding:
 		// Moonshot Kimi Coding requires a fixed User-Agent on every request.
 		base := apiBase
@@ -325,13 +347,13 @@ func (h *ProvidersHandler) registerInMemory(p *store.LLMProviderData) providerRu
 		prov.WithExtraHeaders(map[string]string{
 			"User-Agent": store.KimiCodingRequiredUserAgent,
 		})
-		h.providerReg.RegisterForTenant(p.TenantID, prov)
+		h.providerReg.RegisterForTenant(p.TenantID, h.withKeyPool(prov, p))
 	default:
 		prov := providers.NewOpenAIProvider(p.Name, p.APIKey, apiBase, "")
 		if p.ProviderType == store.ProviderMiniMax {
 			prov.WithChatPath("/text/chatcompletion_v2")
 		}
-		h.providerReg.RegisterForTenant(p.TenantID, prov)
+		h.providerReg.RegisterForTenant(p.TenantID, h.withKeyPool(prov, p))
 	}
 	return providerRuntimeRegistered
 }
@@ -356,8 +378,9 @@ func normalizeOllamaAPIBase(p *store.LLMProviderData) {
 // They are restricted to an explicit localhost allowlist
 // rather than skipping SSRF validation entirely.
 var localURLProviderTypes = map[string]bool{
-	store.ProviderOllama: true,
-	store.ProviderACP:    true,
+	store.ProviderOllama:   true,
+	store.ProviderLlamaCpp: true,
+	store.ProviderACP:      true,
 }
 
 // allowedLocalHosts are the only hosts permitted for local provider types.
@@ -385,7 +408,7 @@ var allowPrivateProviderURLsFn = sync.OnceValue(func() bool {
 //  2. Claude CLI → api_base is an executable path/command, not a URL.
 //  3. Scheme check (http/https only) → enforced for URL-based types, including
 //     local URL types. Blocks file://, gopher://, dict://, etc.
-//  4. Local URL types (ollama, acp) → host must be in allowedLocalHosts
+//  4. Local URL types (ollama, llamacpp, acp) → host must be in allowedLocalHosts
 //     (explicit allowlist prevents reaching 169.254.169.254 or internal services
 //     via the local-type bypass).
 //  5. Remote types → if GOCLAW_ALLOW_PRIVATE_PROVIDER_URLS is set, allow and log.
@@ -508,11 +531,15 @@ func (h *ProvidersHandler) handleListProviders(w http.ResponseWriter, r *http.Re
 
 func (h *ProvidersHandler) handleCreateProvider(w http.ResponseWriter, r *http.Request) {
 	locale := extractLocale(r)
-	var p store.LLMProviderData
-	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&p); err != nil {
+	var req struct {
+		store.LLMProviderData
+		APIKeys []string `json:"api_keys"` // extra keys rotated alongside api_key
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": i18n.T(locale, i18n.MsgInvalidJSON)})
 		return
 	}
+	p := req.LLMProviderData
 
 	if p.Name == "" {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": i18n.T(locale, i18n.MsgRequired, "name")})
@@ -567,6 +594,13 @@ func (h *ProvidersHandler) handleCreateProvider(w http.ResponseWriter, r *http.R
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
+	if len(req.APIKeys) > 0 {
+		if err := h.saveAPIKeys(r.Context(), p.ID, req.APIKeys); err != nil {
+			slog.Error("providers.create.api_keys", "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+	}
 
 	// Register in-memory so verify/chat work without restart
 	h.registerInMemory(&p)
@@ -592,9 +626,12 @@ func (h *ProvidersHandler) handleGetProvider(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	keyPool := h.runtimeKeyPool(p)
 	maskAPIKey(p)
-	publicProvider := canonicalizeProviderForResponse(p)
-	writeJSON(w, http.StatusOK, publicProvider)
+	writeJSON(w, http.StatusOK, providerDetailResponse{
+		LLMProviderData: canonicalizeProviderForResponse(p),
+		KeyPool:         keyPool,
+	})
 }
 
 func (h *ProvidersHandler) handleReconnectProvider(w http.ResponseWriter, r *http.Request) {
@@ -682,6 +719,16 @@ func (h *ProvidersHandler) handleUpdateProvider(w http.ResponseWriter, r *http.R
 		}
 	}
 
+	// Extra API keys live in config_secrets, not in llm_providers.
+	var apiKeys []string
+	rawAPIKeys, hasAPIKeys := updates["api_keys"]
+	if hasAPIKeys {
+		if apiKeys, err = parseAPIKeys(rawAPIKeys); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": i18n.T(locale, i18n.MsgInvalidRequest, err.Error())})
+			return
+		}
+	}
+
 	// Allowlist: only permit known provider columns.
 	updates = filterAllowedKeys(updates, providerAllowedFields)
 
@@ -772,6 +819,13 @@ func (h *ProvidersHandler) handleUpdateProvider(w http.ResponseWriter, r *http.R
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
+	if hasAPIKeys {
+		if err := h.saveAPIKeys(r.Context(), id, apiKeys); err != nil {
+			slog.Error("providers.update.api_keys", "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+	}
 
 	// Sync in-memory registry with updated provider
 	if h.providerReg != nil {
@@ -825,6 +879,9 @@ func (h *ProvidersHandler) handleDeleteProvider(w http.ResponseWriter, r *http.R
 	if h.providerReg != nil && providerName != "" {
 		h.providerReg.UnregisterForTenant(providerTenantID, providerName)
 	}
+	if h.secretStore != nil {
+		_ = h.secretStore.Delete(r.Context(), store.ProviderAPIKeysSecretKey(id))
+	}
 	if providerName != "" {
 		h.emitProviderCacheInvalidate(r.Context(), providerTenantID, providerName)
 	}
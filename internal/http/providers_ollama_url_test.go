@@ -216,8 +216,8 @@ func TestResolveAPIBaseUsesAPIBaseFallback(t *testing.T) {
 }
 
 // TestRegisterInMemoryOllamaURLNormalization verifies that registerInMemory registers the
-// Ollama provider with a correct base URL (already normalized with /v1 at write time)
-// and does NOT produce a doubled /v1 in the registered provider.
+// native Ollama provider at the API root: the /v1 stored at write time is stripped
+// because /api/chat, /api/tags and /api/pull live outside /v1.
 func TestRegisterInMemoryOllamaURLNormalization(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -225,14 +225,14 @@ func TestRegisterInMemoryOllamaURLNormalization(t *testing.T) {
 		wantURL string // expected URLin the registered provider
 	}{
 		{
-			name:    "normalized /v1 is registered unchanged",
+			name:    "stored /v1 is stripped to the native root",
 			apiBase: "http://host:11434/v1",
-			wantURL: "http://host:11434/v1",
+			wantURL: "http://host:11434",
 		},
 		{
-			name:    "empty api_base falls back to default with /v1",
+			name:    "empty api_base falls back to the default root",
 			apiBase: "",
-			wantURL: "http://localhost:11434/v1",
+			wantURL: "http://localhost:11434",
 		},
 	}
 
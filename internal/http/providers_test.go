@@ -417,3 +417,38 @@ func TestProvidersHandlerUpdateRejectsIncompatibleEmbeddingDimensions(t *testing
 		t.Fatalf("embedding dimensions = %+v, want 1536 preserved", es)
 	}
 }
+
+// TestProvidersHandlerRegisterInMemoryPooledKeysWithoutPrimary guards the
+// api_keys-only setup: an empty api_key must not block registration when
+// config_secrets holds extra keys for the pool to rotate through.
+func TestProvidersHandlerRegisterInMemoryPooledKeysWithoutPrimary(t *testing.T) {
+	providerReg := providers.NewRegistry(nil)
+	secrets := newMockSecretsStore()
+	handler := NewProvidersHandler(newMockProviderStore(), secrets, providerReg, "")
+
+	provider := &store.LLMProviderData{
+		BaseModel:    store.BaseModel{ID: uuid.New()},
+		TenantID:     uuid.New(),
+		Name:         "pooled-anthropic",
+		ProviderType: store.ProviderAnthropicNative,
+		Enabled:      true,
+	}
+	if got := handler.registerInMemory(provider); got != providerRuntimeMissingCredential {
+		t.Fatalf("registerInMemory without keys = %v, want missing credential", got)
+	}
+
+	if err := store.SaveProviderAPIKeys(context.Background(), secrets, provider.ID, []string{"sk-ant-a", "sk-ant-b"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := handler.registerInMemory(provider); got != providerRuntimeRegistered {
+		t.Fatalf("registerInMemory with api_keys = %v, want registered", got)
+	}
+	prov, err := providerReg.GetForTenant(provider.TenantID, provider.Name)
+	if err != nil {
+		t.Fatalf("GetForTenant: %v", err)
+	}
+	anth, ok := prov.(*providers.AnthropicProvider)
+	if !ok || anth.KeyPool() == nil || anth.KeyPool().Len() != 2 {
+		t.Fatalf("provider = %T, want anthropic with a 2-key pool", prov)
+	}
+}
@@ -13,8 +13,10 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/nextlevelbuilder/goclaw/internal/agent"
 	"github.com/nextlevelbuilder/goclaw/internal/i18n"
 	"github.com/nextlevelbuilder/goclaw/internal/permissions"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
@@ -22,6 +24,8 @@ import (
 type TracesHandler struct {
 	tracing     store.TracingStore
 	runTimeline store.RunTimelineStore
+	agents      *agent.Router               // nil = replay unavailable
+	recordings  *providers.RecordingArchive // nil = recordings unavailable
 }
 
 // NewTracesHandler creates a handler for trace management endpoints.
@@ -38,6 +42,8 @@ func (h *TracesHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /v1/traces", h.authMiddleware(h.handleList))
 	mux.HandleFunc("GET /v1/traces/follow", h.authMiddleware(h.handleFollow))
 	mux.HandleFunc("GET /v1/traces/{traceID}/export", h.authMiddleware(h.handleExport))
+	mux.HandleFunc("GET /v1/traces/{traceID}/recording", h.authMiddleware(h.handleRecording))
+	mux.HandleFunc("POST /v1/traces/{traceID}/replay", h.authMiddleware(h.handleReplay))
 	mux.HandleFunc("GET /v1/traces/{traceID}", h.authMiddleware(h.handleGet))
 	mux.HandleFunc("GET /v1/runs/{runID}/timeline", h.authMiddleware(h.handleRunTimeline))
 	mux.HandleFunc("GET /v1/costs/summary", h.authMiddleware(h.handleCostSummary))
@@ -70,6 +70,9 @@ func (m *mockTracingStore) ListCodexPoolSpans(context.Context, uuid.UUID, uuid.U
 func (m *mockTracingStore) ListCodexPoolSpansByProviders(context.Context, uuid.UUID, []string, int) ([]store.CodexPoolProviderSpan, error) {
 	return nil, nil
 }
+func (m *mockTracingStore) ListRecentLLMSpans(context.Context, time.Time, int) ([]store.LLMSpanSample, error) {
+	return nil, nil
+}
 
 func setupTraceReadToken(t *testing.T, ownerID string) string {
 	t.Helper()
@@ -0,0 +1,189 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/agent"
+	"github.com/nextlevelbuilder/goclaw/internal/i18n"
+	"github.com/nextlevelbuilder/goclaw/internal/permissions"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/sessions"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+	"github.com/nextlevelbuilder/goclaw/internal/tools"
+)
+
+// traceReplayRequest is the optional body of POST /v1/traces/{traceID}/replay.
+type traceReplayRequest struct {
+	// AsTraceUser runs the replay as the trace's user, so context files and
+	// memory load from their scope. Default: the caller's own user.
+	AsTraceUser bool `json:"as_trace_user,omitempty"`
+}
+
+// traceReplayResponse is returned by POST /v1/traces/{traceID}/replay.
+type traceReplayResponse struct {
+	SourceTraceID string                `json:"source_trace_id"`
+	TraceID       string                `json:"trace_id,omitempty"` // trace of the replay run
+	RunID         string                `json:"run_id"`
+	UserID        string                `json:"user_id"` // user the replay ran as
+	SessionKey    string                `json:"session_key"`
+	Content       string                `json:"content"`
+	Error         string                `json:"error,omitempty"`
+	Replay        providers.ReplayStats `json:"replay"`
+}
+
+// SetReplay enables the recording and replay endpoints. agents may be nil,
+// in which case recordings can be read but not replayed.
+func (h *TracesHandler) SetReplay(agents *agent.Router, recordings *providers.RecordingArchive) {
+	h.agents = agents
+	h.recordings = recordings
+}
+
+// handleRecording returns the provider call archive linked to a trace.
+func (h *TracesHandler) handleRecording(w http.ResponseWriter, r *http.Request) {
+	locale := store.LocaleFromContext(r.Context())
+	if h.recordings == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": i18n.T(locale, i18n.MsgTraceReplayUnavailable)})
+		return
+	}
+	trace, ok := h.ownedTrace(w, r)
+	if !ok {
+		return
+	}
+	rec, ok := h.loadRecording(w, r, trace)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, rec)
+}
+
+// handleReplay re-runs the trace's agent offline: a ReplayProvider serves the
+// recorded provider calls and tool calls get their recorded results, so no
+// model is called and no tool executes.
+func (h *TracesHandler) handleReplay(w http.ResponseWriter, r *http.Request) {
+	locale := store.LocaleFromContext(r.Context())
+	if h.recordings == nil || h.agents == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": i18n.T(locale, i18n.MsgTraceReplayUnavailable)})
+		return
+	}
+	var opts traceReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": i18n.T(locale, i18n.MsgInvalidRequest, err.Error())})
+		return
+	}
+	trace, ok := h.ownedTrace(w, r)
+	if !ok {
+		return
+	}
+	if trace.AgentID == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": i18n.T(locale, i18n.MsgInvalidRequest, "trace has no agent")})
+		return
+	}
+	rec, ok := h.loadRecording(w, r, trace)
+	if !ok {
+		return
+	}
+	loop, err := h.agents.Get(r.Context(), trace.AgentID.String())
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": i18n.T(locale, i18n.MsgNotFound, "agent", trace.AgentID.String())})
+		return
+	}
+
+	input := trace.InputPreview
+	if rec.Run != nil {
+		input = rec.Run.Input
+	}
+	replay := providers.NewReplayProvider(rec)
+	runUser := store.UserIDFromContext(r.Context())
+	if opts.AsTraceUser {
+		runUser = trace.UserID
+	}
+	resp := traceReplayResponse{
+		SourceTraceID: trace.ID.String(),
+		RunID:         uuid.NewString(),
+		UserID:        runUser,
+		SessionKey:    sessions.SessionKey(loop.ID(), "replay-"+uuid.NewString()[:8]),
+	}
+	slog.Info("traces.replay", "trace_id", trace.ID, "agent", loop.ID(), "calls", len(rec.Calls), "user", runUser, "as_trace_user", opts.AsTraceUser)
+
+	result, err := loop.Run(store.WithUserID(r.Context(), runUser), agent.RunRequest{
+		SessionKey:       resp.SessionKey,
+		Message:          input,
+		Channel:          "replay",
+		ChatID:           "api",
+		RunID:            resp.RunID,
+		UserID:           runUser,
+		ProviderOverride: replay,
+		ToolOverride:     recordedToolResult(replay),
+		TraceName:        "replay " + trace.ID.String()[:8],
+		TraceTags:        []string{"replay"},
+		OnTraceCreated:   func(id uuid.UUID) { resp.TraceID = id.String() },
+	})
+	if err != nil {
+		resp.Error = err.Error()
+	} else if result != nil {
+		resp.Content = result.Content
+	}
+	resp.Replay = replay.Stats()
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// recordedToolResult answers a replayed tool call with the output the
+// recorded run got. Calls the recording never answered fail instead of
+// executing, so a diverged replay cannot reach live tools.
+func recordedToolResult(replay *providers.ReplayProvider) func(context.Context, providers.ToolCall) *tools.Result {
+	return func(_ context.Context, tc providers.ToolCall) *tools.Result {
+		msg, ok := replay.ToolResult(tc.ID)
+		if !ok {
+			return tools.ErrorResult(fmt.Sprintf("replay: no recorded result for %s call %s; tools do not run during replay", tc.Name, tc.ID))
+		}
+		if msg.IsError {
+			return tools.ErrorResult(msg.Content)
+		}
+		return tools.NewResult(msg.Content)
+	}
+}
+
+// ownedTrace loads the path trace, hiding other users' traces from non-admins.
+func (h *TracesHandler) ownedTrace(w http.ResponseWriter, r *http.Request) (*store.TraceData, bool) {
+	locale := store.LocaleFromContext(r.Context())
+	traceIDStr := r.PathValue("traceID")
+	traceID, err := uuid.Parse(traceIDStr)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": i18n.T(locale, i18n.MsgInvalidID, "trace")})
+		return nil, false
+	}
+	trace, err := h.tracing.GetTrace(r.Context(), traceID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": i18n.T(locale, i18n.MsgNotFound, "trace", traceIDStr)})
+		return nil, false
+	}
+	auth := resolveAuth(r)
+	if !permissions.HasMinRole(auth.Role, permissions.RoleAdmin) && trace.UserID != store.UserIDFromContext(r.Context()) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": i18n.T(locale, i18n.MsgNotFound, "trace", traceIDStr)})
+		return nil, false
+	}
+	return trace, true
+}
+
+func (h *TracesHandler) loadRecording(w http.ResponseWriter, r *http.Request, trace *store.TraceData) (*providers.Recording, bool) {
+	locale := store.LocaleFromContext(r.Context())
+	rec, err := h.recordings.Load(trace.ID.String())
+	if errors.Is(err, providers.ErrRecordingNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": i18n.T(locale, i18n.MsgNotFound, "recording", trace.ID.String())})
+		return nil, false
+	}
+	if err != nil {
+		slog.Error("traces.load_recording_failed", "trace_id", trace.ID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return nil, false
+	}
+	return rec, true
+}
@@ -67,24 +67,26 @@ func init() {
 		MsgSenderIDRequired:      "sender_id is required",
 
 		// HTTP API
-		MsgInvalidAuth:            "invalid authentication",
-		MsgMsgsRequired:           "messages is required",
-		MsgUserIDHeader:           "X-GoClaw-User-Id header is required",
-		MsgFileTooLarge:           "file too large or invalid multipart form",
-		MsgMissingFileField:       "missing 'file' field",
-		MsgInvalidFilename:        "invalid filename",
-		MsgChannelKeyReq:          "channel and key are required",
-		MsgMethodNotAllowed:       "method not allowed",
-		MsgStreamingNotSupported:  "streaming not supported",
-		MsgOwnerOnly:              "only owner can %s",
-		MsgNoAccess:               "no access to this %s",
-		MsgAlreadySummoning:       "agent is already being summoned",
-		MsgSummoningUnavailable:   "summoning not available",
-		MsgRunTimelineUnavailable: "run timeline not available",
-		MsgNoDescription:          "agent has no description to resummon from",
-		MsgSummonCancelled:        "summon cancelled by user",
-		MsgCannotCancel:           "agent is not being summoned",
-		MsgInvalidPath:            "invalid path",
+		MsgInvalidAuth:             "invalid authentication",
+		MsgMsgsRequired:            "messages is required",
+		MsgUserIDHeader:            "X-GoClaw-User-Id header is required",
+		MsgFileTooLarge:            "file too large or invalid multipart form",
+		MsgMissingFileField:        "missing 'file' field",
+		MsgInvalidFilename:         "invalid filename",
+		MsgChannelKeyReq:           "channel and key are required",
+		MsgMethodNotAllowed:        "method not allowed",
+		MsgStreamingNotSupported:   "streaming not supported",
+		MsgOwnerOnly:               "only owner can %s",
+		MsgNoAccess:                "no access to this %s",
+		MsgAlreadySummoning:        "agent is already being summoned",
+		MsgSummoningUnavailable:    "summoning not available",
+		MsgRunTimelineUnavailable:  "run timeline not available",
+		MsgTraceReplayUnavailable:  "trace replay not available",
+		MsgSentMessagesUnavailable: "sent message tracking not available",
+		MsgNoDescription:           "agent has no description to resummon from",
+		MsgSummonCancelled:         "summon cancelled by user",
+		MsgCannotCancel:            "agent is not being summoned",
+		MsgInvalidPath:             "invalid path",
 
 		// Browser cookies
 		MsgBrowserCookieTooMany:            "too many browser cookies in one sync request",
@@ -67,24 +67,26 @@ func init() {
 		MsgSenderIDRequired:      "sender_id là bắt buộc",
 
 		// HTTP API
-		MsgInvalidAuth:            "xác thực không hợp lệ",
-		MsgMsgsRequired:           "messages là bắt buộc",
-		MsgUserIDHeader:           "header X-GoClaw-User-Id là bắt buộc",
-		MsgFileTooLarge:           "tệp quá lớn hoặc form multipart không hợp lệ",
-		MsgMissingFileField:       "thiếu trường 'file'",
-		MsgInvalidFilename:        "tên tệp không hợp lệ",
-		MsgChannelKeyReq:          "channel và key là bắt buộc",
-		MsgMethodNotAllowed:       "phương thức không được phép",
-		MsgStreamingNotSupported:  "streaming không được hỗ trợ",
-		MsgOwnerOnly:              "chỉ chủ sở hữu mới có thể %s",
-		MsgNoAccess:               "không có quyền truy cập %s này",
-		MsgAlreadySummoning:       "agent đang được triệu hồi",
-		MsgSummoningUnavailable:   "triệu hồi không khả dụng",
-		MsgRunTimelineUnavailable: "timeline lượt chạy không khả dụng",
-		MsgNoDescription:          "agent không có mô tả để triệu hồi lại",
-		MsgSummonCancelled:        "đã huỷ triệu hồi",
-		MsgCannotCancel:           "agent không trong trạng thái đang triệu hồi",
-		MsgInvalidPath:            "đường dẫn không hợp lệ",
+		MsgInvalidAuth:             "xác thực không hợp lệ",
+		MsgMsgsRequired:            "messages là bắt buộc",
+		MsgUserIDHeader:            "header X-GoClaw-User-Id là bắt buộc",
+		MsgFileTooLarge:            "tệp quá lớn hoặc form multipart không hợp lệ",
+		MsgMissingFileField:        "thiếu trường 'file'",
+		MsgInvalidFilename:         "tên tệp không hợp lệ",
+		MsgChannelKeyReq:           "channel và key là bắt buộc",
+		MsgMethodNotAllowed:        "phương thức không được phép",
+		MsgStreamingNotSupported:   "streaming không được hỗ trợ",
+		MsgOwnerOnly:               "chỉ chủ sở hữu mới có thể %s",
+		MsgNoAccess:                "không có quyền truy cập %s này",
+		MsgAlreadySummoning:        "agent đang được triệu hồi",
+		MsgSummoningUnavailable:    "triệu hồi không khả dụng",
+		MsgRunTimelineUnavailable:  "timeline lượt chạy không khả dụng",
+		MsgTraceReplayUnavailable:  "phát lại trace không khả dụng",
+		MsgSentMessagesUnavailable: "theo dõi tin nhắn đã gửi không khả dụng",
+		MsgNoDescription:           "agent không có mô tả để triệu hồi lại",
+		MsgSummonCancelled:         "đã huỷ triệu hồi",
+		MsgCannotCancel:            "agent không trong trạng thái đang triệu hồi",
+		MsgInvalidPath:             "đường dẫn không hợp lệ",
 
 		// Browser cookies
 		MsgBrowserCookieTooMany:            "quá nhiều cookie trình duyệt trong một yêu cầu đồng bộ",
@@ -67,24 +67,26 @@ func init() {
 		MsgSenderIDRequired:      "sender_id 是必填项",
 
 		// HTTP API
-		MsgInvalidAuth:            "身份验证无效",
-		MsgMsgsRequired:           "messages 是必填项",
-		MsgUserIDHeader:           "需要 X-GoClaw-User-Id 请求头",
-		MsgFileTooLarge:           "文件过大或 multipart 表单无效",
-		MsgMissingFileField:       "缺少 'file' 字段",
-		MsgInvalidFilename:        "文件名无效",
-		MsgChannelKeyReq:          "channel 和 key 是必填项",
-		MsgMethodNotAllowed:       "不允许的请求方法",
-		MsgStreamingNotSupported:  "不支持流式传输",
-		MsgOwnerOnly:              "只有所有者才能%s",
-		MsgNoAccess:               "无权访问此%s",
-		MsgAlreadySummoning:       "Agent正在被召唤中",
-		MsgSummoningUnavailable:   "召唤功能不可用",
-		MsgRunTimelineUnavailable: "运行时间线不可用",
-		MsgNoDescription:          "Agent没有可供重新召唤的描述",
-		MsgSummonCancelled:        "已取消召唤",
-		MsgCannotCancel:           "Agent 未处于召唤状态",
-		MsgInvalidPath:            "路径无效",
+		MsgInvalidAuth:             "身份验证无效",
+		MsgMsgsRequired:            "messages 是必填项",
+		MsgUserIDHeader:            "需要 X-GoClaw-User-Id 请求头",
+		MsgFileTooLarge:            "文件过大或 multipart 表单无效",
+		MsgMissingFileField:        "缺少 'file' 字段",
+		MsgInvalidFilename:         "文件名无效",
+		MsgChannelKeyReq:           "channel 和 key 是必填项",
+		MsgMethodNotAllowed:        "不允许的请求方法",
+		MsgStreamingNotSupported:   "不支持流式传输",
+		MsgOwnerOnly:               "只有所有者才能%s",
+		MsgNoAccess:                "无权访问此%s",
+		MsgAlreadySummoning:        "Agent正在被召唤中",
+		MsgSummoningUnavailable:    "召唤功能不可用",
+		MsgRunTimelineUnavailable:  "运行时间线不可用",
+		MsgTraceReplayUnavailable:  "追踪重放不可用",
+		MsgSentMessagesUnavailable: "已发送消息跟踪不可用",
+		MsgNoDescription:           "Agent没有可供重新召唤的描述",
+		MsgSummonCancelled:         "已取消召唤",
+		MsgCannotCancel:            "Agent 未处于召唤状态",
+		MsgInvalidPath:             "路径无效",
 
 		// Browser cookies
 		MsgBrowserCookieTooMany:            "单次同步请求中的浏览器 Cookie 过多",
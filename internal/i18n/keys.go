@@ -68,24 +68,26 @@ const (
 	MsgSenderIDRequired      = "error.sender_id_required"      // "sender_id is required"
 
 	// --- HTTP API ---
-	MsgInvalidAuth            = "error.invalid_auth"             // "invalid authentication"
-	MsgMsgsRequired           = "error.messages_required"        // "messages is required"
-	MsgUserIDHeader           = "error.user_id_header"           // "X-GoClaw-User-Id header is required"
-	MsgFileTooLarge           = "error.file_too_large"           // "file too large or invalid multipart form"
-	MsgMissingFileField       = "error.missing_file_field"       // "missing 'file' field"
-	MsgInvalidFilename        = "error.invalid_filename"         // "invalid filename"
-	MsgChannelKeyReq          = "error.channel_key_required"     // "channel and key are required"
-	MsgMethodNotAllowed       = "error.method_not_allowed"       // "method not allowed"
-	MsgStreamingNotSupported  = "error.streaming_not_supported"  // "streaming not supported"
-	MsgOwnerOnly              = "error.owner_only"               // "only owner can %s"
-	MsgNoAccess               = "error.no_access"                // "no access to this %s"
-	MsgAlreadySummoning       = "error.already_summoning"        // "agent is already being summoned"
-	MsgSummoningUnavailable   = "error.summoning_unavailable"    // "summoning not available"
-	MsgRunTimelineUnavailable = "error.run_timeline_unavailable" // "run timeline not available"
-	MsgNoDescription          = "error.no_description"           // "agent has no description to resummon from"
-	MsgSummonCancelled        = "info.summon_cancelled"          // "summon cancelled by user"
-	MsgCannotCancel           = "error.cannot_cancel_summon"     // "agent is not being summoned"
-	MsgInvalidPath            = "error.invalid_path"             // "invalid path"
+	MsgInvalidAuth             = "error.invalid_auth"              // "invalid authentication"
+	MsgMsgsRequired            = "error.messages_required"         // "messages is required"
+	MsgUserIDHeader            = "error.user_id_header"            // "X-GoClaw-User-Id header is required"
+	MsgFileTooLarge            = "error.file_too_large"            // "file too large or invalid multipart form"
+	MsgMissingFileField        = "error.missing_file_field"        // "missing 'file' field"
+	MsgInvalidFilename         = "error.invalid_filename"          // "invalid filename"
+	MsgChannelKeyReq           = "error.channel_key_required"      // "channel and key are required"
+	MsgMethodNotAllowed        = "error.method_not_allowed"        // "method not allowed"
+	MsgStreamingNotSupported   = "error.streaming_not_supported"   // "streaming not supported"
+	MsgOwnerOnly               = "error.owner_only"                // "only owner can %s"
+	MsgNoAccess                = "error.no_access"                 // "no access to this %s"
+	MsgAlreadySummoning        = "error.already_summoning"         // "agent is already being summoned"
+	MsgSummoningUnavailable    = "error.summoning_unavailable"     // "summoning not available"
+	MsgRunTimelineUnavailable  = "error.run_timeline_unavailable"  // "run timeline not available"
+	MsgTraceReplayUnavailable  = "error.trace_replay_unavailable"  // "trace replay not available"
+	MsgSentMessagesUnavailable = "error.sent_messages_unavailable" // "sent message tracking not available"
+	MsgNoDescription           = "error.no_description"            // "agent has no description to resummon from"
+	MsgSummonCancelled         = "info.summon_cancelled"           // "summon cancelled by user"
+	MsgCannotCancel            = "error.cannot_cancel_summon"      // "agent is not being summoned"
+	MsgInvalidPath             = "error.invalid_path"              // "invalid path"
 
 	// --- Browser cookies ---
 	MsgBrowserCookieTooMany            = "error.browser_cookie_too_many"            // "too many browser cookies in one sync request"
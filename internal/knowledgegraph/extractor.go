@@ -3,6 +3,7 @@ package knowledgegraph
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -24,6 +25,7 @@ type Extractor struct {
 	model         string
 	minConfidence float64
 	usageCaps     *usagecaps.Service
+	batch         bool
 }
 
 // NewExtractor creates a new Extractor with the given provider, model, and confidence threshold.
@@ -39,6 +41,12 @@ func (e *Extractor) SetUsageCapService(s *usagecaps.Service) {
 	e.usageCaps = s
 }
 
+// SetBatch routes extraction calls through the provider batch API when the
+// usage cap service has a batch queue. Only for non-interactive callers.
+func (e *Extractor) SetBatch(batch bool) {
+	e.batch = batch
+}
+
 const maxChunkChars = 12000
 
 // Extract calls the LLM to extract entities and relations from text.
@@ -77,36 +85,31 @@ func (e *Extractor) extractChunk(ctx context.Context, text string) (*ExtractionR
 			"max_tokens":  8192,
 			"temperature": 0.2,
 		},
+		ResponseFormat: providers.NewJSONSchemaFormat("knowledge_graph_extraction", extractionSchema),
 	}
 
-	resp, err := e.usageCaps.Chat(ctx, e.provider, req, usagecaps.ChatOptions{
-		ModelID:         e.model,
-		Purpose:         "knowledge-graph-extract",
-		MaxOutputTokens: 8192,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("kg extraction LLM call: %w", err)
-	}
+	resp, err := providers.ChatStructured(ctx, e.provider, req, e.chatFn("knowledge-graph-extract"))
 
 	// If response was truncated, retry with shorter input
-	if resp.FinishReason == "length" {
+	if resp != nil && resp.FinishReason == "length" {
 		slog.Warn("kg extraction: response truncated, retrying with shorter input")
 		const retryMaxChars = 8000
 		if len(text) > retryMaxChars {
 			text = text[:retryMaxChars] + "\n\n[...truncated]"
 		}
 		req.Messages[1].Content = text
-		resp, err = e.usageCaps.Chat(ctx, e.provider, req, usagecaps.ChatOptions{
-			ModelID:         e.model,
-			Purpose:         "knowledge-graph-extract-retry",
-			MaxOutputTokens: 8192,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("kg extraction LLM retry: %w", err)
-		}
-		if resp.FinishReason == "length" {
+		resp, err = providers.ChatStructured(ctx, e.provider, req, e.chatFn("knowledge-graph-extract-retry"))
+		if resp != nil && resp.FinishReason == "length" {
 			return nil, fmt.Errorf("kg extraction: response still truncated after retry")
 		}
+		if err != nil && (resp == nil || !errors.Is(err, providers.ErrStructuredOutputInvalid)) {
+			return nil, fmt.Errorf("kg extraction LLM retry: %w", err)
+		}
+	}
+	// Exhausted structured-output retries still hand back the last response;
+	// fall through so sanitizeJSON gets a chance to salvage it.
+	if err != nil && (resp == nil || !errors.Is(err, providers.ErrStructuredOutputInvalid)) {
+		return nil, fmt.Errorf("kg extraction LLM call: %w", err)
 	}
 
 	// Parse JSON response
@@ -158,6 +161,19 @@ func (e *Extractor) extractChunk(ctx context.Context, text string) (*ExtractionR
 	return filtered, nil
 }
 
+// chatFn routes each structured-output attempt through usage caps so retries
+// are reserved and reconciled like any other background LLM call.
+func (e *Extractor) chatFn(purpose string) providers.ChatFunc {
+	return func(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+		return e.usageCaps.Chat(ctx, e.provider, req, usagecaps.ChatOptions{
+			ModelID:         e.model,
+			Purpose:         purpose,
+			MaxOutputTokens: 8192,
+			Batch:           e.batch,
+		})
+	}
+}
+
 // sanitizeJSON fixes common LLM JSON issues while preserving string values.
 // It walks the JSON character-by-character, only applying fixes outside quoted strings:
 //   - Malformed decimals: "0. 85" → "0.85"
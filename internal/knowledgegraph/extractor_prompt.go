@@ -91,3 +91,40 @@ Output:
     {"source_entity_id": "migration-guide", "relation_type": "references", "target_entity_id": "goclaw-migration", "confidence": 1.0}
   ]
 }`
+
+// extractionSchema mirrors the JSON shape described in extractionSystemPrompt.
+// Sent as ChatRequest.ResponseFormat so providers with native structured output
+// enforce it; others fall back to validate-and-retry.
+var extractionSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"entities": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"external_id": map[string]any{"type": "string"},
+					"name":        map[string]any{"type": "string"},
+					"entity_type": map[string]any{"type": "string"},
+					"description": map[string]any{"type": "string"},
+					"confidence":  map[string]any{"type": "number"},
+				},
+				"required": []any{"external_id", "name", "entity_type", "confidence"},
+			},
+		},
+		"relations": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"source_entity_id": map[string]any{"type": "string"},
+					"relation_type":    map[string]any{"type": "string"},
+					"target_entity_id": map[string]any{"type": "string"},
+					"confidence":       map[string]any{"type": "number"},
+				},
+				"required": []any{"source_entity_id", "relation_type", "target_entity_id", "confidence"},
+			},
+		},
+	},
+	"required": []any{"entities", "relations"},
+}
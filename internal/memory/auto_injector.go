@@ -35,6 +35,7 @@ type InjectParams struct {
 	MaxEntries  int     // default 5
 	MaxTokens   int     // default 200
 	Threshold   float64 // relevance threshold (default 0.3)
+	RerankTopK  int     // > 0 = rerank matches and inject at most this many (per-agent memory.rerank)
 }
 
 // InjectResult contains the injection output + observability data.
@@ -16,11 +16,13 @@ import (
 type pgAutoInjector struct {
 	episodicStore store.EpisodicStore
 	metricsStore  store.EvolutionMetricsStore // nil = metrics disabled
+	reranker      store.Reranker              // nil = no reranking
 }
 
 // NewAutoInjector creates an AutoInjector backed by episodic store search.
-func NewAutoInjector(es store.EpisodicStore, ms store.EvolutionMetricsStore) AutoInjector {
-	return &pgAutoInjector{episodicStore: es, metricsStore: ms}
+// rr reranks matches for calls with RerankTopK set (nil = never rerank).
+func NewAutoInjector(es store.EpisodicStore, ms store.EvolutionMetricsStore, rr store.Reranker) AutoInjector {
+	return &pgAutoInjector{episodicStore: es, metricsStore: ms, reranker: rr}
 }
 
 // Inject searches episodic memory for relevant L0 abstracts and formats a prompt section.
@@ -48,10 +50,17 @@ func (a *pgAutoInjector) Inject(ctx context.Context, params InjectParams) (*Inje
 	// follow-up semantics and returns materially better matches.
 	searchQuery := buildRecallQuery(params.UserMessage, params.RecentContext)
 
+	candidates := maxEntries * 2 // fetch more, filter by threshold
+	rerankTopK := 0
+	if a.reranker != nil && params.RerankTopK > 0 {
+		rerankTopK = min(params.RerankTopK, maxEntries)
+		candidates = RerankCandidateCount(rerankTopK)
+	}
+
 	// Search with FTS bias (faster than vector for auto-inject)
 	results, err := a.episodicStore.Search(ctx, searchQuery, params.AgentID, params.UserID,
 		store.EpisodicSearchOptions{
-			MaxResults:   maxEntries * 2, // fetch more, filter by threshold
+			MaxResults:   candidates,
 			MinScore:     threshold,
 			VectorWeight: 0.3,
 			TextWeight:   0.7,
@@ -62,6 +71,14 @@ func (a *pgAutoInjector) Inject(ctx context.Context, params InjectParams) (*Inje
 	if len(results) == 0 {
 		return &InjectResult{}, nil
 	}
+	matchCount := len(results)
+	if rerankTopK > 0 {
+		// Rerank against the user message itself: the recall query may carry
+		// extra context that helps first-stage recall but dilutes precision.
+		results = ApplyRerank(ctx, a.reranker, params.UserMessage, results, rerankTopK,
+			func(r *store.EpisodicSearchResult) string { return r.L0Abstract },
+			func(r *store.EpisodicSearchResult, score float64) { r.Score = score })
+	}
 
 	// Build prompt section from L0 abstracts
 	var sb strings.Builder
@@ -86,12 +103,12 @@ func (a *pgAutoInjector) Inject(ctx context.Context, params InjectParams) (*Inje
 	}
 
 	if injected == 0 {
-		return &InjectResult{MatchCount: len(results)}, nil
+		return &InjectResult{MatchCount: matchCount}, nil
 	}
 
 	result := &InjectResult{
 		Section:    sb.String(),
-		MatchCount: len(results),
+		MatchCount: matchCount,
 		Injected:   injected,
 		TopScore:   topScore,
 	}
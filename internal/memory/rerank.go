@@ -0,0 +1,285 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bm25"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// Reranker API styles. Cohere and Jina share a wire format (top_n in,
+// results[].relevance_score out); Voyage uses top_k in and data[] out.
+const (
+	RerankStyleCohere = "cohere"
+	RerankStyleVoyage = "voyage"
+	RerankStyleJina   = "jina"
+)
+
+// rerankDefaults holds the endpoint and model used when none is configured.
+var rerankDefaults = map[string]struct{ url, model string }{
+	RerankStyleCohere: {"https://api.cohere.com/v2/rerank", "rerank-v3.5"},
+	RerankStyleVoyage: {"https://api.voyageai.com/v1/rerank", "rerank-2"},
+	RerankStyleJina:   {"https://api.jina.ai/v1/rerank", "jina-reranker-v2-base-multilingual"},
+}
+
+// RerankStyleForURL guesses the API style from an endpoint or provider base
+// URL. Unknown hosts default to the Cohere format, which most self-hosted
+// rerank servers (TEI, Infinity) also accept.
+func RerankStyleForURL(url string) string {
+	switch u := strings.ToLower(url); {
+	case strings.Contains(u, "voyageai"):
+		return RerankStyleVoyage
+	case strings.Contains(u, "jina.ai"):
+		return RerankStyleJina
+	default:
+		return RerankStyleCohere
+	}
+}
+
+// HTTPReranker calls a hosted rerank API (Cohere, Voyage, or Jina).
+type HTTPReranker struct {
+	name   string
+	style  string
+	model  string
+	apiKey string
+	apiURL string // full endpoint URL, e.g. https://api.cohere.com/v2/rerank
+	client *http.Client
+}
+
+// NewHTTPReranker creates a reranker for the given API style. Empty apiURL
+// and model fall back to the style's public endpoint and default model.
+func NewHTTPReranker(name, style, apiKey, apiURL, model string) *HTTPReranker {
+	def, ok := rerankDefaults[style]
+	if !ok {
+		style = RerankStyleCohere
+		def = rerankDefaults[style]
+	}
+	if apiURL == "" {
+		apiURL = def.url
+	}
+	if model == "" {
+		model = def.model
+	}
+	return &HTTPReranker{
+		name:   name,
+		style:  style,
+		model:  model,
+		apiKey: apiKey,
+		apiURL: apiURL,
+		client: http.DefaultClient,
+	}
+}
+
+func (r *HTTPReranker) Name() string  { return r.name }
+func (r *HTTPReranker) Model() string { return r.model }
+
+func (r *HTTPReranker) Rerank(ctx context.Context, query string, docs []string, topN int) ([]store.RerankResult, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	if topN <= 0 || topN > len(docs) {
+		topN = len(docs)
+	}
+
+	reqBody := map[string]any{
+		"model":     r.model,
+		"query":     query,
+		"documents": docs,
+	}
+	if r.style == RerankStyleVoyage {
+		reqBody["top_k"] = topN
+	} else {
+		reqBody["top_n"] = topN
+	}
+	bodyJSON, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.apiURL, bytes.NewReader(bodyJSON))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+r.apiKey)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("rerank request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("rerank API error %d: %s", resp.StatusCode, string(body))
+	}
+
+	type rankedDoc struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	}
+	var result struct {
+		Results []rankedDoc `json:"results"` // Cohere, Jina
+		Data    []rankedDoc `json:"data"`    // Voyage
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	ranked := result.Results
+	if r.style == RerankStyleVoyage {
+		ranked = result.Data
+	}
+
+	out := make([]store.RerankResult, 0, len(ranked))
+	for _, d := range ranked {
+		if d.Index < 0 || d.Index >= len(docs) {
+			continue
+		}
+		out = append(out, store.RerankResult{Index: d.Index, Score: d.RelevanceScore})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	if len(out) > topN {
+		out = out[:topN]
+	}
+	return out, nil
+}
+
+// BM25Reranker is the local fallback used when no rerank API is configured.
+// It scores candidates by term overlap with the query, which mostly helps
+// when the first stage leaned on vector similarity. Scores are normalized so
+// the best candidate gets 1.
+type BM25Reranker struct{}
+
+func NewBM25Reranker() *BM25Reranker { return &BM25Reranker{} }
+
+func (BM25Reranker) Name() string  { return "bm25" }
+func (BM25Reranker) Model() string { return "bm25" }
+
+func (BM25Reranker) Rerank(_ context.Context, query string, docs []string, topN int) ([]store.RerankResult, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	if topN <= 0 || topN > len(docs) {
+		topN = len(docs)
+	}
+
+	idx := bm25.New()
+	idx.Build(docs)
+	scores := idx.Score(query)
+
+	out := make([]store.RerankResult, len(docs))
+	var maxScore float64
+	for i := range docs {
+		out[i].Index = i
+		if scores != nil {
+			out[i].Score = scores[i]
+			maxScore = max(maxScore, scores[i])
+		}
+	}
+	if maxScore > 0 {
+		for i := range out {
+			out[i].Score /= maxScore
+		}
+	}
+	// Stable: candidates with equal scores keep their first-stage order.
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out[:topN], nil
+}
+
+// TenantReranker picks the reranker of the calling tenant (from ctx), so a
+// tenant's rerank provider and API key only serve that tenant's searches.
+// Resolutions are cached for ttl, so config changes apply without a restart.
+type TenantReranker struct {
+	resolve func(ctx context.Context) store.Reranker
+	ttl     time.Duration
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]tenantRerankerEntry
+}
+
+type tenantRerankerEntry struct {
+	reranker store.Reranker
+	expires  time.Time
+}
+
+// NewTenantReranker creates a reranker that calls resolve with a context
+// carrying only the tenant ID, at most once per tenant per ttl.
+func NewTenantReranker(resolve func(ctx context.Context) store.Reranker, ttl time.Duration) *TenantReranker {
+	return &TenantReranker{resolve: resolve, ttl: ttl, cache: make(map[uuid.UUID]tenantRerankerEntry)}
+}
+
+func (t *TenantReranker) Name() string  { return "tenant" }
+func (t *TenantReranker) Model() string { return "tenant" }
+
+func (t *TenantReranker) Rerank(ctx context.Context, query string, docs []string, topN int) ([]store.RerankResult, error) {
+	r := t.forTenant(store.TenantIDFromContext(ctx))
+	ranked, err := r.Rerank(ctx, query, docs, topN)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", r.Name(), err)
+	}
+	return ranked, nil
+}
+
+func (t *TenantReranker) forTenant(tenantID uuid.UUID) store.Reranker {
+	t.mu.Lock()
+	e, ok := t.cache[tenantID]
+	t.mu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e.reranker
+	}
+	// Resolve outside the lock: it reads the tenant's config from the DB.
+	r := t.resolve(store.WithTenantID(context.Background(), tenantID))
+	if r == nil {
+		r = NewBM25Reranker()
+	}
+	t.mu.Lock()
+	t.cache[tenantID] = tenantRerankerEntry{reranker: r, expires: time.Now().Add(t.ttl)}
+	t.mu.Unlock()
+	return r
+}
+
+// RerankCandidateCount is how many first-stage candidates to fetch for a
+// top-k rerank budget.
+func RerankCandidateCount(topK int) int {
+	return min(max(topK*4, 20), 100)
+}
+
+// ApplyRerank reorders items by reranker relevance and keeps the top k,
+// writing each new score through setScore. On a reranker error the
+// first-stage order is kept (truncated to k) so search never fails on it.
+func ApplyRerank[T any](ctx context.Context, r store.Reranker, query string, items []T, topK int, text func(*T) string, setScore func(*T, float64)) []T {
+	if r == nil || len(items) == 0 || topK <= 0 {
+		return items
+	}
+	docs := make([]string, len(items))
+	for i := range items {
+		docs[i] = text(&items[i])
+	}
+	ranked, err := r.Rerank(ctx, query, docs, topK)
+	if err != nil {
+		slog.Warn("memory.rerank_failed", "reranker", r.Name(), "error", err)
+		if len(items) > topK {
+			items = items[:topK]
+		}
+		return items
+	}
+	out := make([]T, 0, len(ranked))
+	for _, rr := range ranked {
+		item := items[rr.Index]
+		setScore(&item, rr.Score)
+		out = append(out, item)
+	}
+	return out
+}
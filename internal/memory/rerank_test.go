@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+func TestHTTPReranker_CohereStyle(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer k" {
+			t.Errorf("missing bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"results":[{"index":2,"relevance_score":0.9},{"index":0,"relevance_score":0.4}]}`))
+	}))
+	defer srv.Close()
+
+	rr := NewHTTPReranker("cohere", RerankStyleCohere, "k", srv.URL, "")
+	res, err := rr.Rerank(context.Background(), "q", []string{"a", "b", "c"}, 2)
+	if err != nil {
+		t.Fatalf("Rerank: %v", err)
+	}
+	if got["top_n"] != float64(2) || got["model"] != "rerank-v3.5" {
+		t.Errorf("unexpected request body: %v", got)
+	}
+	if len(res) != 2 || res[0].Index != 2 || res[1].Index != 0 {
+		t.Errorf("unexpected results: %+v", res)
+	}
+}
+
+func TestHTTPReranker_VoyageStyle(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"data":[{"index":0,"relevance_score":0.2},{"index":1,"relevance_score":0.8}]}`))
+	}))
+	defer srv.Close()
+
+	rr := NewHTTPReranker("voyage", RerankStyleForURL("https://api.voyageai.com/v1"), "k", srv.URL, "")
+	res, err := rr.Rerank(context.Background(), "q", []string{"a", "b"}, 5)
+	if err != nil {
+		t.Fatalf("Rerank: %v", err)
+	}
+	if got["top_k"] != float64(2) {
+		t.Errorf("top_k should be clamped to doc count, got %v", got["top_k"])
+	}
+	if len(res) != 2 || res[0].Index != 1 {
+		t.Errorf("results should be sorted by score, got %+v", res)
+	}
+}
+
+func TestBM25Reranker_PrefersTermOverlap(t *testing.T) {
+	docs := []string{
+		"user enjoys hiking in the mountains",
+		"favorite coffee shop is Blue Bottle downtown",
+		"meeting notes about the quarterly roadmap",
+	}
+	res, err := NewBM25Reranker().Rerank(context.Background(), "favorite coffee shop", docs, 2)
+	if err != nil {
+		t.Fatalf("Rerank: %v", err)
+	}
+	if len(res) != 2 || res[0].Index != 1 || res[0].Score != 1 {
+		t.Errorf("coffee doc should rank first with score 1, got %+v", res)
+	}
+}
+
+type failingReranker struct{}
+
+func (failingReranker) Name() string  { return "fail" }
+func (failingReranker) Model() string { return "fail" }
+func (failingReranker) Rerank(context.Context, string, []string, int) ([]store.RerankResult, error) {
+	return nil, errors.New("boom")
+}
+
+func TestApplyRerank_ErrorKeepsFirstStageOrder(t *testing.T) {
+	items := []string{"a", "b", "c"}
+	got := ApplyRerank(context.Background(), failingReranker{}, "q", items, 2,
+		func(s *string) string { return *s },
+		func(*string, float64) {})
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("expected first-stage top 2, got %v", got)
+	}
+}
+
+func TestApplyRerank_ReordersAndRescores(t *testing.T) {
+	type item struct {
+		text  string
+		score float64
+	}
+	items := []item{{"alpha", 0.9}, {"coffee downtown", 0.1}}
+	got := ApplyRerank(context.Background(), NewBM25Reranker(), "coffee", items, 1,
+		func(it *item) string { return it.text },
+		func(it *item, s float64) { it.score = s })
+	if len(got) != 1 || got[0].text != "coffee downtown" || got[0].score != 1 {
+		t.Errorf("unexpected rerank output: %+v", got)
+	}
+}
+
+func TestTenantReranker_ResolvesPerTenantAndCaches(t *testing.T) {
+	tenantA, tenantB := uuid.New(), uuid.New()
+	var resolved []uuid.UUID
+	tr := NewTenantReranker(func(ctx context.Context) store.Reranker {
+		tenantID := store.TenantIDFromContext(ctx)
+		resolved = append(resolved, tenantID)
+		if tenantID == tenantA {
+			return failingReranker{}
+		}
+		return nil // no rerank provider: local BM25
+	}, time.Hour)
+
+	ctxA := store.WithTenantID(context.Background(), tenantA)
+	ctxB := store.WithTenantID(context.Background(), tenantB)
+	if _, err := tr.Rerank(ctxA, "q", []string{"a"}, 1); err == nil {
+		t.Fatal("tenant A should use its own (failing) reranker")
+	}
+	for range 2 {
+		if got, err := tr.Rerank(ctxB, "coffee", []string{"tea", "coffee"}, 1); err != nil || len(got) != 1 || got[0].Index != 1 {
+			t.Fatalf("tenant B rerank = %+v, %v; want BM25", got, err)
+		}
+	}
+	if len(resolved) != 2 || resolved[0] != tenantA || resolved[1] != tenantB {
+		t.Fatalf("resolved = %v, want one resolution per tenant", resolved)
+	}
+}
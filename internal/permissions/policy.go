@@ -230,6 +230,7 @@ func isAdminMethod(method string) bool {
 		protocol.MethodChannelInstancesCreate,
 		protocol.MethodChannelInstancesUpdate,
 		protocol.MethodChannelInstancesDelete,
+		protocol.MethodMessagesRetract,
 
 		// Bitrix24 portal management — admin-only writes (credentials + delete).
 		protocol.MethodBitrixPortalsCreate,
@@ -337,6 +338,7 @@ func isWriteMethod(method string) bool {
 		// Channel pairing starts (QR scan flows).
 		protocol.MethodZaloPersonalQRStart,
 		protocol.MethodWhatsAppQRStart,
+		protocol.MethodSignalQRStart,
 
 		// Workstations — connection test invokes SSH side-effects.
 		protocol.MethodWorkstationsTest,
@@ -383,6 +385,7 @@ func isReadMethod(method string) bool {
 		protocol.MethodChannelsStatus,
 		protocol.MethodChannelInstancesList,
 		protocol.MethodChannelInstancesGet,
+		protocol.MethodMessagesSentList,
 
 		// Bitrix24 portal read — any tenant member can list portals to populate
 		// the channel-form dropdown; get_install_url is needed to resume a
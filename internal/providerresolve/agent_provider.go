@@ -1,10 +1,16 @@
 package providerresolve
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
+	"github.com/nextlevelbuilder/goclaw/internal/tracing"
 )
 
 // ResolveConfiguredProvider resolves the provider an agent should actually use.
@@ -49,9 +55,58 @@ func ResolveConfiguredProvider(registry *providers.Registry, agent *store.AgentD
 	return nil, baseErr
 }
 
-// ResolveAgentProvider resolves the agent runtime provider, including generic
-// per-agent model fallback when configured.
-func ResolveAgentProvider(registry *providers.Registry, agent *store.AgentData) (providers.Provider, error) {
+// routeStats is shared by every agent's router so latency samples for a
+// tenant's provider/model accumulate process-wide.
+var routeStats = providers.NewRouteStats()
+
+const (
+	routeSeedWindow = 24 * time.Hour // how far back SeedRouteStats reads spans
+	routeSeedLimit  = 5000
+)
+
+// SeedRouteStats preloads the shared latency stats from recent LLM spans so
+// lowest_latency routing doesn't start blind after a restart.
+func SeedRouteStats(ctx context.Context, ts store.TracingStore) {
+	if ts == nil {
+		return
+	}
+	spans, err := ts.ListRecentLLMSpans(ctx, time.Now().Add(-routeSeedWindow), routeSeedLimit)
+	if err != nil {
+		slog.Warn("route stats: load recent LLM spans failed", "error", err)
+		return
+	}
+	observations := make([]providers.RouteObservation, 0, len(spans))
+	for i := len(spans) - 1; i >= 0; i-- { // spans are newest first
+		sp := spans[i]
+		observations = append(observations, providers.RouteObservation{
+			TenantID:     sp.TenantID,
+			Provider:     sp.Provider,
+			Model:        sp.Model,
+			Latency:      time.Duration(sp.DurationMS) * time.Millisecond,
+			OutputTokens: sp.OutputTokens,
+			Failed:       sp.Status == store.SpanStatusError,
+		})
+	}
+	routeStats.Seed(observations)
+	slog.Debug("route stats: seeded from recent LLM spans", "spans", len(spans))
+}
+
+// AgentProviderOptions carries optional dependencies for ResolveAgentProvider.
+// Any field may be nil.
+type AgentProviderOptions struct {
+	ResponseCache *providers.ResponseCacheStore
+	ModelRegistry providers.ModelRegistry // context windows (and fallback pricing) for routing
+	// Pricing returns USD per 1M input/output tokens for a provider model.
+	// Consulted before ModelRegistry costs by cost-aware routing.
+	Pricing func(providerName, model string) (input, output float64, ok bool)
+	// Recordings archives provider calls for agents with recording enabled.
+	Recordings *providers.RecordingArchive
+}
+
+// ResolveAgentProvider resolves the agent runtime provider, including the
+// per-agent response cache, call recording and generic model fallback when
+// configured.
+func ResolveAgentProvider(registry *providers.Registry, agent *store.AgentData, opts AgentProviderOptions) (providers.Provider, error) {
 	baseProvider, err := ResolveConfiguredProvider(registry, agent)
 	if err != nil {
 		return nil, err
@@ -59,6 +114,27 @@ func ResolveAgentProvider(registry *providers.Registry, agent *store.AgentData)
 	if registry == nil || agent == nil {
 		return baseProvider, nil
 	}
+	// The cache wraps the primary only, inside the fallback chain, so callers
+	// that type-assert *ModelFallbackProvider keep working and fallback
+	// answers are never served as the primary model's.
+	if cacheCfg := agent.ParseResponseCache(); cacheCfg != nil && opts.ResponseCache != nil {
+		baseProvider = providers.NewResponseCacheProvider(baseProvider, opts.ResponseCache, providers.ResponseCacheOptions{
+			Scope:               agent.TenantID.String() + ":" + agent.ID.String(),
+			Mode:                cacheCfg.Mode,
+			TTL:                 time.Duration(cacheCfg.TTLSeconds) * time.Second,
+			SimilarityThreshold: cacheCfg.SimilarityThreshold,
+		})
+	}
+	// Recording wraps each chain member rather than the chain itself, for the
+	// same type-assertion reason, and sits outside the cache so the archive
+	// holds what the agent was actually served.
+	record := func(p providers.Provider) providers.Provider { return p }
+	if agent.ParseRecording() && opts.Recordings != nil {
+		record = func(p providers.Provider) providers.Provider {
+			return providers.NewRecordingProvider(p, opts.Recordings, recordingTraceKey)
+		}
+	}
+	baseProvider = record(baseProvider)
 	fallbackCfg := agent.ParseModelFallback()
 	if fallbackCfg == nil {
 		return baseProvider, nil
@@ -72,7 +148,7 @@ func ResolveAgentProvider(registry *providers.Registry, agent *store.AgentData)
 		candidates = append(candidates, providers.FallbackCandidate{
 			ProviderName: candidate.Provider,
 			Model:        candidate.Model,
-			Provider:     provider,
+			Provider:     record(provider),
 		})
 	}
 	if len(candidates) == 0 {
@@ -82,9 +158,51 @@ func ResolveAgentProvider(registry *providers.Registry, agent *store.AgentData)
 	if fallbackCfg.CooldownEnabled != nil {
 		cooldownEnabled = *fallbackCfg.CooldownEnabled
 	}
-	return providers.NewModelFallbackProvider(providers.FallbackCandidate{
+	fallback := providers.NewModelFallbackProvider(providers.FallbackCandidate{
 		ProviderName: agent.Provider,
 		Model:        agent.Model,
 		Provider:     baseProvider,
-	}, candidates, fallbackCfg.MaxAttempts, cooldownEnabled), nil
+	}, candidates, fallbackCfg.MaxAttempts, cooldownEnabled)
+	if fallbackCfg.Strategy != store.ModelFallbackStrategyPriority {
+		fallback.SetRouter(providers.NewModelRouter(agent.TenantID, fallbackCfg.Strategy, routeProfiles(agent, fallbackCfg, opts), routeStats))
+	}
+	return fallback, nil
+}
+
+// recordingTraceKey keys recordings by trace ID; untraced calls aren't recorded.
+func recordingTraceKey(ctx context.Context) string {
+	if id := tracing.TraceIDFromContext(ctx); id != uuid.Nil {
+		return id.String()
+	}
+	return ""
+}
+
+// routeProfiles builds the static routing data for the primary and every
+// fallback candidate once, at resolve time.
+func routeProfiles(agent *store.AgentData, cfg *store.ModelFallbackConfig, opts AgentProviderOptions) map[string]providers.CandidateProfile {
+	profiles := make(map[string]providers.CandidateProfile, len(cfg.Candidates)+1)
+	add := func(providerName, model string, weight int) {
+		profile := providers.CandidateProfile{Weight: weight}
+		var spec *providers.ModelSpec
+		if opts.ModelRegistry != nil {
+			spec = opts.ModelRegistry.Resolve(providerName, model)
+		}
+		if spec != nil {
+			profile.ContextWindow = spec.ContextWindow
+		}
+		if cfg.Strategy == store.ModelFallbackStrategyCheapestFitsContext {
+			if opts.Pricing != nil {
+				profile.InputPer1M, profile.OutputPer1M, profile.Priced = opts.Pricing(providerName, model)
+			}
+			if !profile.Priced && spec != nil && (spec.Cost.InputPer1M > 0 || spec.Cost.OutputPer1M > 0) {
+				profile.InputPer1M, profile.OutputPer1M, profile.Priced = spec.Cost.InputPer1M, spec.Cost.OutputPer1M, true
+			}
+		}
+		profiles[providers.CooldownKey(providerName, model)] = profile
+	}
+	add(agent.Provider, agent.Model, cfg.PrimaryWeight)
+	for _, c := range cfg.Candidates {
+		add(c.Provider, c.Model, c.Weight)
+	}
+	return profiles
 }
@@ -248,7 +248,7 @@ func TestResolveAgentProviderWrapsModelFallback(t *testing.T) {
 		}`),
 	}
 
-	resolved, err := ResolveAgentProvider(registry, agent)
+	resolved, err := ResolveAgentProvider(registry, agent, AgentProviderOptions{})
 	if err != nil {
 		t.Fatalf("ResolveAgentProvider() error = %v", err)
 	}
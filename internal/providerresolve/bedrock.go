@@ -0,0 +1,25 @@
+package providerresolve
+
+import (
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// BedrockConfigFromDB maps a Bedrock llm_providers row to BedrockConfig.
+// api_key is the secret access key when settings carry access_key_id,
+// otherwise a Bedrock API key (or empty for the default credential chain).
+func BedrockConfigFromDB(p *store.LLMProviderData, s *store.BedrockProviderSettings) providers.BedrockConfig {
+	cfg := providers.BedrockConfig{
+		Name:            p.Name,
+		Region:          s.Region,
+		DefaultModel:    s.Model,
+		APIBaseOverride: p.APIBase,
+	}
+	if s.AccessKeyID != "" {
+		cfg.AccessKeyID = s.AccessKeyID
+		cfg.SecretAccessKey = p.APIKey
+	} else {
+		cfg.APIKey = p.APIKey
+	}
+	return cfg
+}
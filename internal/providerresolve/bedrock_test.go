@@ -0,0 +1,24 @@
+package providerresolve
+
+import (
+	"testing"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+func TestBedrockConfigFromDBMapsAPIKeyByCredentialKind(t *testing.T) {
+	p := &store.LLMProviderData{Name: "bedrock", APIKey: "secret", APIBase: "https://bedrock.example"}
+
+	cfg := BedrockConfigFromDB(p, &store.BedrockProviderSettings{Region: "us-east-1", Model: "m", AccessKeyID: "AKIA1"})
+	if cfg.AccessKeyID != "AKIA1" || cfg.SecretAccessKey != "secret" || cfg.APIKey != "" {
+		t.Fatalf("access key config = %+v", cfg)
+	}
+	if cfg.Name != "bedrock" || cfg.Region != "us-east-1" || cfg.DefaultModel != "m" || cfg.APIBaseOverride != "https://bedrock.example" {
+		t.Fatalf("config = %+v", cfg)
+	}
+
+	cfg = BedrockConfigFromDB(p, &store.BedrockProviderSettings{Region: "us-east-1"})
+	if cfg.APIKey != "secret" || cfg.AccessKeyID != "" || cfg.SecretAccessKey != "" {
+		t.Fatalf("api key config = %+v", cfg)
+	}
+}
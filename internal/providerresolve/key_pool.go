@@ -0,0 +1,40 @@
+package providerresolve
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// HasAPIKey reports whether p can authenticate with an API key: its own, or
+// extra keys in config_secrets that the key pool supplies when api_key is empty.
+func HasAPIKey(p *store.LLMProviderData, secrets store.ConfigSecretsStore) bool {
+	if p.APIKey != "" {
+		return true
+	}
+	ctx := store.WithTenantID(context.Background(), p.TenantID)
+	for _, k := range store.LoadProviderAPIKeys(ctx, secrets, p)[1:] {
+		if k != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// WithKeyPool installs an API key pool on prov when the DB provider has extra
+// API keys in config_secrets. Provider types without key rotation (OAuth, CLI,
+// cloud credential chains) are returned unchanged. Returns prov for chaining.
+func WithKeyPool(prov providers.Provider, p *store.LLMProviderData, secrets store.ConfigSecretsStore) providers.Provider {
+	ctx := store.WithTenantID(context.Background(), p.TenantID)
+	keys := store.LoadProviderAPIKeys(ctx, secrets, p)
+	if len(keys) < 2 {
+		return prov
+	}
+	pool := providers.NewAPIKeyPool(p.Name, store.ParseKeyPoolSettings(p.Settings).Strategy, keys)
+	if providers.AttachKeyPool(prov, pool) {
+		slog.Info("provider key pool enabled", "name", p.Name, "keys", pool.Len(), "strategy", pool.Strategy())
+	}
+	return prov
+}
@@ -10,6 +10,10 @@ import (
 // Delegates to AnthropicProvider's buildRequestBody/parseResponse for DRY.
 // Used by Pipeline to separate serialization from transport.
 //
+// Structured output (ChatRequest.ResponseFormat) is serialized as a forced tool;
+// FromResponse has no access to the request, so the result surfaces as a
+// ToolCall named ResponseFormat.SchemaName() rather than as Content.
+//
 // Note: FromStreamChunk handles text/thinking deltas only. Tool call argument
 // accumulation (input_json_delta) and signature tracking (signature_delta) are
 // stateful — Pipeline must handle those externally when wiring adapters.
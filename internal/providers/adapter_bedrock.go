@@ -0,0 +1,121 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// bedrockAdapterDefaultRegion is used when ProviderConfig.ExtraOpts has no "region".
+const bedrockAdapterDefaultRegion = "us-east-1"
+
+// BedrockAdapter implements ProviderAdapter for the Bedrock Converse API.
+// Delegates to BedrockProvider's buildRequestBody/parseBedrockResponse.
+//
+// SigV4 signs the final URL, timestamp and payload, so ToRequest cannot sign:
+// it only sets Authorization when cfg.APIKey is a Bedrock API key (Bearer).
+// Callers using IAM credentials must sign the request in their transport.
+//
+// ConverseStream frames are binary event-stream messages whose event type is
+// a header; FromStreamChunk receives only the JSON payload and infers the
+// event from its shape (delta vs stopReason).
+type BedrockAdapter struct {
+	provider *BedrockProvider
+}
+
+// NewBedrockAdapter creates an adapter from ProviderConfig.
+// ExtraOpts["region"] selects the region for the default base URL.
+func NewBedrockAdapter(cfg ProviderConfig) (ProviderAdapter, error) {
+	region, _ := cfg.ExtraOpts["region"].(string)
+	if region == "" {
+		region = bedrockAdapterDefaultRegion
+	}
+	if err := validateBedrockRegion(region); err != nil {
+		return nil, err
+	}
+	p := &BedrockProvider{
+		name:         "bedrock",
+		region:       region,
+		baseURL:      BedrockDefaultAPIBase(region),
+		defaultModel: BedrockDefaultModel,
+		bearerToken:  cfg.APIKey,
+		signer:       v4.NewSigner(),
+		retryConfig:  DefaultRetryConfig(),
+	}
+	if cfg.BaseURL != "" {
+		p.baseURL = strings.TrimRight(cfg.BaseURL, "/")
+	}
+	if cfg.Model != "" {
+		p.defaultModel = cfg.Model
+	}
+	return &BedrockAdapter{provider: p}, nil
+}
+
+func (a *BedrockAdapter) Name() string { return "bedrock" }
+
+// Capabilities delegates to the wrapped provider for single source of truth.
+func (a *BedrockAdapter) Capabilities() ProviderCapabilities {
+	return a.provider.Capabilities()
+}
+
+// Endpoint returns the Converse (or ConverseStream) URL for the request model.
+func (a *BedrockAdapter) Endpoint(req ChatRequest, stream bool) string {
+	action := "converse"
+	if stream {
+		action = "converse-stream"
+	}
+	return a.provider.baseURL + "/model/" + bedrockEscapeModelID(a.provider.resolveModel(req.Model)) + "/" + action
+}
+
+// ToRequest converts ChatRequest to a Converse JSON body + headers.
+// The model ID is not part of the body; see Endpoint.
+func (a *BedrockAdapter) ToRequest(req ChatRequest) ([]byte, http.Header, error) {
+	model := a.provider.resolveModel(req.Model)
+	data, err := json.Marshal(a.provider.buildRequestBody(model, req))
+	if err != nil {
+		return nil, nil, fmt.Errorf("bedrock adapter: marshal: %w", err)
+	}
+
+	h := make(http.Header)
+	h.Set("Content-Type", "application/json")
+	if a.provider.bearerToken != "" {
+		h.Set("Authorization", "Bearer "+a.provider.bearerToken)
+	}
+	return data, h, nil
+}
+
+// FromResponse parses a Converse response JSON into ChatResponse.
+func (a *BedrockAdapter) FromResponse(data []byte) (*ChatResponse, error) {
+	var resp bedrockConverseResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("bedrock adapter: decode: %w", err)
+	}
+	return parseBedrockResponse(&resp), nil
+}
+
+// FromStreamChunk parses a single ConverseStream event payload.
+// Returns content/thinking for text and reasoning deltas, Done for messageStop.
+// Tool input deltas are stateful and must be accumulated by the caller.
+func (a *BedrockAdapter) FromStreamChunk(data []byte) (*StreamChunk, error) {
+	var ev struct {
+		bedrockContentBlockDeltaEvent
+		StopReason string `json:"stopReason"`
+	}
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return nil, nil
+	}
+	if ev.StopReason != "" {
+		return &StreamChunk{Done: true}, nil
+	}
+	d := ev.Delta
+	switch {
+	case d.Text != nil:
+		return &StreamChunk{Content: *d.Text}, nil
+	case d.ReasoningContent != nil && d.ReasoningContent.Text != "":
+		return &StreamChunk{Thinking: d.ReasoningContent.Text}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,87 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// GeminiAdapter implements ProviderAdapter for the native Gemini
+// generateContent API. Delegates to GeminiProvider's buildRequestBody and
+// parseGeminiResponse.
+//
+// The model is part of the URL, not the body; see Endpoint. Streaming events
+// are whole GenerateContentResponse objects, so FromStreamChunk is stateless
+// except for usage, which the caller should take from the last chunk.
+type GeminiAdapter struct {
+	provider *GeminiProvider
+}
+
+// NewGeminiAdapter creates an adapter from ProviderConfig. BaseURL may be the
+// native base or the legacy OpenAI-compat one.
+func NewGeminiAdapter(cfg ProviderConfig) (ProviderAdapter, error) {
+	p := NewGeminiProvider(cfg.Name, cfg.APIKey, cfg.BaseURL, cfg.Model)
+	p.readFile = nil // adapters serialize only what the request carries
+	return &GeminiAdapter{provider: p}, nil
+}
+
+func (a *GeminiAdapter) Name() string { return "gemini" }
+
+// Capabilities delegates to the wrapped provider for single source of truth.
+func (a *GeminiAdapter) Capabilities() ProviderCapabilities {
+	return a.provider.Capabilities()
+}
+
+// Endpoint returns the generateContent (or streamGenerateContent SSE) URL.
+func (a *GeminiAdapter) Endpoint(req ChatRequest, stream bool) string {
+	return a.provider.endpoint(a.provider.resolveModel(req.Model), stream)
+}
+
+// ToRequest converts ChatRequest to a generateContent JSON body + headers.
+func (a *GeminiAdapter) ToRequest(req ChatRequest) ([]byte, http.Header, error) {
+	model := a.provider.resolveModel(req.Model)
+	data, err := json.Marshal(a.provider.buildRequestBody(model, req))
+	if err != nil {
+		return nil, nil, fmt.Errorf("gemini adapter: marshal: %w", err)
+	}
+
+	h := make(http.Header)
+	h.Set("Content-Type", "application/json")
+	h.Set("x-goog-api-key", a.provider.apiKey)
+	return data, h, nil
+}
+
+// FromResponse parses a GenerateContentResponse JSON into ChatResponse.
+func (a *GeminiAdapter) FromResponse(data []byte) (*ChatResponse, error) {
+	var resp geminiResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("gemini adapter: decode: %w", err)
+	}
+	return parseGeminiResponse(&resp), nil
+}
+
+// FromStreamChunk parses one SSE event. Returns content/thinking for text
+// parts and Done once the candidate reports a finishReason.
+func (a *GeminiAdapter) FromStreamChunk(data []byte) (*StreamChunk, error) {
+	var resp geminiResponse
+	if err := json.Unmarshal(data, &resp); err != nil || len(resp.Candidates) == 0 {
+		return nil, nil
+	}
+	cand := resp.Candidates[0]
+	chunk := &StreamChunk{Done: cand.FinishReason != ""}
+	for _, raw := range cand.Content.Parts {
+		var part geminiPart
+		if json.Unmarshal(raw, &part) != nil || part.FunctionCall != nil {
+			continue
+		}
+		if part.Thought {
+			chunk.Thinking += part.Text
+		} else {
+			chunk.Content += part.Text
+		}
+	}
+	if chunk.Content == "" && chunk.Thinking == "" && !chunk.Done {
+		return nil, nil
+	}
+	return chunk, nil
+}
@@ -8,5 +8,7 @@ func DefaultAdapterRegistry() *AdapterRegistry {
 	r.Register("openai", NewOpenAIAdapter)
 	r.Register("dashscope", NewDashScopeAdapter)
 	r.Register("codex", NewCodexAdapter)
+	r.Register("bedrock", NewBedrockAdapter)
+	r.Register("gemini", NewGeminiAdapter)
 	return r
 }
@@ -50,7 +50,9 @@ type AnthropicProvider struct {
 	client       *http.Client
 	retryConfig  RetryConfig
 	middlewares  RequestMiddleware // composed middleware chain (nil = no-op)
-	registry     ModelRegistry    // model resolution registry (nil = skip)
+	registry     ModelRegistry     // model resolution registry (nil = skip)
+	batchAPI     bool              // baseURL serves Message Batches even though it isn't api.anthropic.com
+	keyPool      *APIKeyPool       // nil = single API key
 }
 
 // NewAnthropicProvider creates a new Anthropic provider.
@@ -71,6 +73,14 @@ func NewAnthropicProvider(apiKey string, opts ...AnthropicOption) *AnthropicProv
 	return p
 }
 
+func (p *AnthropicProvider) setKeyPool(pool *APIKeyPool) {
+	p.keyPool = pool
+	p.client = pool.WrapClient(p.client, "x-api-key", "")
+}
+
+// KeyPool returns the API key pool, or nil for a single key.
+func (p *AnthropicProvider) KeyPool() *APIKeyPool { return p.keyPool }
+
 type AnthropicOption func(*AnthropicProvider)
 
 // WithAnthropicName overrides the provider name (default: "anthropic").
@@ -115,6 +125,7 @@ func (p *AnthropicProvider) Capabilities() ProviderCapabilities {
 		Thinking:         true,
 		Vision:           true,
 		CacheControl:     true,
+		StructuredOutput: true,
 		MaxContextWindow: 200_000,
 		TokenizerID:      "cl100k_base",
 	}
@@ -150,7 +161,9 @@ func (p *AnthropicProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRes
 			return nil, fmt.Errorf("anthropic: decode response: %w", err)
 		}
 
-		return p.parseResponse(&parsed), nil
+		result := p.parseResponse(&parsed)
+		applyAnthropicResponseFormat(req.ResponseFormat, result)
+		return result, nil
 	})
 	// Drop user-visible reasoning after parsing for models flagged as leakers.
 	// Usage.ThinkingTokens and RawAssistantContent remain intact so billing
@@ -175,8 +188,7 @@ func (p *AnthropicProvider) doRequest(ctx context.Context, body any) (io.ReadClo
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("x-api-key", p.apiKey)
-	httpReq.Header.Set("anthropic-version", anthropicAPIVersion)
+	p.setRequestHeaders(httpReq)
 
 	// Add beta header for interleaved thinking when thinking is enabled
 	if bodyMap, ok := body.(map[string]any); ok {
@@ -204,6 +216,12 @@ func (p *AnthropicProvider) doRequest(ctx context.Context, body any) (io.ReadClo
 	return resp.Body, nil
 }
 
+// setRequestHeaders applies authentication and API version headers.
+func (p *AnthropicProvider) setRequestHeaders(httpReq *http.Request) {
+	httpReq.Header.Set("x-api-key", p.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicAPIVersion)
+}
+
 func (p *AnthropicProvider) parseResponse(resp *anthropicResponse) *ChatResponse {
 	result := &ChatResponse{}
 	thinkingChars := 0
@@ -209,6 +209,27 @@ func (p *AnthropicProvider) buildRequestBody(model string, req ChatRequest, stre
 		body["tools"] = tools
 	}
 
+	// Structured output: Anthropic has no response_format, so the schema becomes a
+	// single tool the model is forced to call. parseResponse/ChatStream fold the
+	// tool input back into Content (see applyAnthropicResponseFormat).
+	if rf := req.ResponseFormat; rf != nil {
+		schema := map[string]any{"type": "object"}
+		if rf.HasSchema() {
+			schema = CleanSchemaForProvider("anthropic", rf.Schema)
+		}
+		desc := rf.Description
+		if desc == "" {
+			desc = "Return the final answer as structured JSON by calling this tool."
+		}
+		tools, _ := body["tools"].([]map[string]any)
+		body["tools"] = append(tools, map[string]any{
+			"name":         rf.SchemaName(),
+			"description":  desc,
+			"input_schema": schema,
+		})
+		body["tool_choice"] = map[string]any{"type": "tool", "name": rf.SchemaName()}
+	}
+
 	// Merge options
 	if v, ok := req.Options[OptMaxTokens]; ok {
 		body["max_tokens"] = v
@@ -219,8 +240,9 @@ func (p *AnthropicProvider) buildRequestBody(model string, req ChatRequest, stre
 		}
 	}
 
-	// Enable extended thinking if thinking_level is set
-	if level, ok := req.Options[OptThinkingLevel].(string); ok && level != "" && level != "off" {
+	// Enable extended thinking if thinking_level is set.
+	// Forced tool_choice (structured output) is rejected when thinking is enabled.
+	if level, ok := req.Options[OptThinkingLevel].(string); ok && level != "" && level != "off" && req.ResponseFormat == nil {
 		budget := anthropicThinkingBudget(level)
 		body["thinking"] = map[string]any{
 			"type":          "enabled",
@@ -274,3 +296,30 @@ func anthropicThinkingBudget(level string) int {
 		return 10000
 	}
 }
+
+// applyAnthropicResponseFormat moves the forced structured-output tool call into
+// Content as a JSON string so callers see the same shape as OpenAI's
+// response_format. Other tool calls (if any) are left untouched.
+func applyAnthropicResponseFormat(rf *ResponseFormat, resp *ChatResponse) {
+	if rf == nil || resp == nil {
+		return
+	}
+	name := rf.SchemaName()
+	kept := resp.ToolCalls[:0]
+	for _, tc := range resp.ToolCalls {
+		if tc.Name != name {
+			kept = append(kept, tc)
+			continue
+		}
+		if b, err := json.Marshal(tc.Arguments); err == nil {
+			resp.Content = string(b)
+		}
+	}
+	resp.ToolCalls = kept
+	if len(kept) == 0 {
+		resp.RawAssistantContent = nil
+		if resp.FinishReason == "tool_calls" {
+			resp.FinishReason = "stop"
+		}
+	}
+}
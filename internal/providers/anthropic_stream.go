@@ -180,6 +180,7 @@ func (p *AnthropicProvider) ChatStream(ctx context.Context, req ChatRequest, onC
 	}
 
 	result.ThinkingSignature = thinkingSignature.String()
+	applyAnthropicResponseFormat(req.ResponseFormat, result)
 
 	if onChunk != nil {
 		onChunk(StreamChunk{Done: true})
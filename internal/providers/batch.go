@@ -0,0 +1,61 @@
+package providers
+
+import (
+	"context"
+	"errors"
+)
+
+// BatchDiscountPercent is the discount OpenAI Batch and Anthropic Message
+// Batches apply to every token class, relative to synchronous pricing.
+const BatchDiscountPercent = 50
+
+// ErrBatchUnsupported is returned when a provider has no batch API.
+var ErrBatchUnsupported = errors.New("provider does not support batch requests")
+
+// BatchCapable is implemented by providers with an asynchronous batch API.
+// Results can take minutes to hours; callers should go through BatchQueue.
+type BatchCapable interface {
+	Provider
+	SupportsBatch() bool
+	SubmitBatch(ctx context.Context, reqs []BatchRequest) (batchID string, err error)
+	PollBatch(ctx context.Context, batchID string) (*BatchPoll, error)
+}
+
+// BatchRequest is one request in a batch. CustomID is unique within the
+// batch and matches [A-Za-z0-9_-]{1,64} (the stricter Anthropic rule).
+type BatchRequest struct {
+	CustomID string
+	Request  ChatRequest
+}
+
+// BatchPoll is a batch status snapshot. Results is set only once Done.
+type BatchPoll struct {
+	Status  string // provider status string, for logs
+	Done    bool
+	Results []BatchResult
+}
+
+// BatchResult is the outcome of one batched request. Response.Usage.Batch is
+// set so cost accounting applies BatchDiscountPercent.
+type BatchResult struct {
+	CustomID string
+	Response *ChatResponse
+	Err      error
+}
+
+// AsBatchCapable returns p's batch API when it has a usable one.
+func AsBatchCapable(p Provider) (BatchCapable, bool) {
+	bc, ok := p.(BatchCapable)
+	if !ok || !bc.SupportsBatch() {
+		return nil, false
+	}
+	return bc, true
+}
+
+// markBatchUsage flags a batched response's usage as billed at batch rates.
+func markBatchUsage(resp *ChatResponse) {
+	if resp.Usage == nil {
+		resp.Usage = &Usage{}
+	}
+	resp.Usage.Batch = true
+}
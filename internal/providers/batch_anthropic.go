@@ -0,0 +1,145 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WithAnthropicBatchAPI marks a non-api.anthropic.com base URL as serving
+// the Message Batches API (e.g. a compatible proxy or a test server).
+func WithAnthropicBatchAPI() AnthropicOption {
+	return func(p *AnthropicProvider) { p.batchAPI = true }
+}
+
+// SupportsBatch reports whether baseURL serves the Message Batches API.
+func (p *AnthropicProvider) SupportsBatch() bool {
+	return p.batchAPI || strings.Contains(strings.ToLower(p.baseURL), "api.anthropic.com")
+}
+
+type anthropicBatch struct {
+	ID               string `json:"id"`
+	ProcessingStatus string `json:"processing_status"`
+	ResultsURL       string `json:"results_url"`
+}
+
+type anthropicBatchResultLine struct {
+	CustomID string `json:"custom_id"`
+	Result   struct {
+		Type    string             `json:"type"` // succeeded, errored, canceled, expired
+		Message *anthropicResponse `json:"message"`
+		Error   *struct {
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"error"`
+	} `json:"result"`
+}
+
+// SubmitBatch creates a Message Batch with one entry per request.
+func (p *AnthropicProvider) SubmitBatch(ctx context.Context, reqs []BatchRequest) (string, error) {
+	entries := make([]map[string]any, 0, len(reqs))
+	for _, br := range reqs {
+		model := resolveAnthropicModel(br.Request.Model, p.defaultModel, p.registry)
+		body := p.buildRequestBody(model, br.Request, false)
+		body = ApplyMiddlewares(body, p.middlewares, p.middlewareConfig(model, br.Request))
+		entries = append(entries, map[string]any{"custom_id": br.CustomID, "params": body})
+	}
+	data, err := json.Marshal(map[string]any{"requests": entries})
+	if err != nil {
+		return "", fmt.Errorf("%s: marshal batch: %w", p.name, err)
+	}
+	respData, err := p.batchDo(ctx, http.MethodPost, p.baseURL+"/messages/batches", bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	var batch anthropicBatch
+	if err := json.Unmarshal(respData, &batch); err != nil || batch.ID == "" {
+		return "", fmt.Errorf("%s: decode batch: %s", p.name, strings.TrimSpace(string(respData)))
+	}
+	return batch.ID, nil
+}
+
+// PollBatch reads the batch status and, once ended, streams its results.
+func (p *AnthropicProvider) PollBatch(ctx context.Context, batchID string) (*BatchPoll, error) {
+	data, err := p.batchDo(ctx, http.MethodGet, p.baseURL+"/messages/batches/"+batchID, nil)
+	if err != nil {
+		return nil, err
+	}
+	var batch anthropicBatch
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return nil, fmt.Errorf("%s: decode batch: %w", p.name, err)
+	}
+	poll := &BatchPoll{Status: batch.ProcessingStatus}
+	if batch.ProcessingStatus != "ended" {
+		return poll, nil
+	}
+	poll.Done = true
+	if batch.ResultsURL == "" {
+		return poll, nil
+	}
+	data, err = p.batchDo(ctx, http.MethodGet, batch.ResultsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 32*1024*1024)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var out anthropicBatchResultLine
+		if err := json.Unmarshal(line, &out); err != nil {
+			return nil, fmt.Errorf("%s: decode batch result: %w", p.name, err)
+		}
+		result := BatchResult{CustomID: out.CustomID}
+		switch {
+		case out.Result.Type == "succeeded" && out.Result.Message != nil:
+			result.Response = p.parseResponse(out.Result.Message)
+			markBatchUsage(result.Response)
+		case out.Result.Error != nil:
+			result.Err = fmt.Errorf("%s: batch request %s: %s", p.name, out.Result.Error.Error.Type, out.Result.Error.Error.Message)
+		default:
+			result.Err = fmt.Errorf("%s: batch request %s", p.name, out.Result.Type)
+		}
+		poll.Results = append(poll.Results, result)
+	}
+	return poll, sc.Err()
+}
+
+// batchDo performs one Message Batches API call and returns the response body.
+// The key pool is pinned to one key: a batch is only visible to its workspace.
+func (p *AnthropicProvider) batchDo(ctx context.Context, method, url string, body io.Reader) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(WithPinnedPoolKey(ctx), method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: create request: %w", p.name, err)
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	p.setRequestHeaders(httpReq)
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s: request failed: %w", p.name, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: read response: %w", p.name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPError{
+			Status:     resp.StatusCode,
+			Body:       fmt.Sprintf("%s: %s", p.name, strings.TrimSpace(string(data))),
+			RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,207 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// openAIBatchEndpoint is the per-line URL and batch endpoint for chat requests.
+const openAIBatchEndpoint = "/v1/chat/completions"
+
+// WithBatchAPI marks a non-api.openai.com endpoint as serving the OpenAI
+// Files + Batches API (e.g. a compatible proxy or a test server).
+func (p *OpenAIProvider) WithBatchAPI() *OpenAIProvider {
+	p.batchAPI = true
+	return p
+}
+
+// SupportsBatch reports whether the endpoint serves the OpenAI Batch API.
+// OpenAI-compatible backends generally don't, so only native OpenAI (or an
+// endpoint opted in via WithBatchAPI) qualifies.
+func (p *OpenAIProvider) SupportsBatch() bool {
+	return p.chatPath == "/chat/completions" && (p.batchAPI || isOpenAINativeEndpoint(p.apiBase))
+}
+
+type openAIBatchLine struct {
+	CustomID string         `json:"custom_id"`
+	Method   string         `json:"method"`
+	URL      string         `json:"url"`
+	Body     map[string]any `json:"body"`
+}
+
+type openAIBatch struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"`
+	OutputFileID string `json:"output_file_id"`
+	ErrorFileID  string `json:"error_file_id"`
+}
+
+type openAIBatchOutputLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// SubmitBatch uploads the requests as a JSONL file and creates a batch.
+func (p *OpenAIProvider) SubmitBatch(ctx context.Context, reqs []BatchRequest) (string, error) {
+	var jsonl bytes.Buffer
+	enc := json.NewEncoder(&jsonl)
+	for _, br := range reqs {
+		model := p.resolveModel(br.Request.Model)
+		body := p.buildRequestBody(model, br.Request, false)
+		body = ApplyMiddlewares(body, p.middlewares, p.middlewareConfig(model, br.Request))
+		if err := enc.Encode(openAIBatchLine{CustomID: br.CustomID, Method: "POST", URL: openAIBatchEndpoint, Body: body}); err != nil {
+			return "", fmt.Errorf("%s: encode batch line: %w", p.name, err)
+		}
+	}
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	_ = mw.WriteField("purpose", "batch")
+	part, err := mw.CreateFormFile("file", "batch.jsonl")
+	if err != nil {
+		return "", fmt.Errorf("%s: build batch upload: %w", p.name, err)
+	}
+	_, _ = part.Write(jsonl.Bytes())
+	if err := mw.Close(); err != nil {
+		return "", fmt.Errorf("%s: build batch upload: %w", p.name, err)
+	}
+	data, err := p.batchDo(ctx, http.MethodPost, p.apiBase+"/files", &form, mw.FormDataContentType())
+	if err != nil {
+		return "", err
+	}
+	var file struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil || file.ID == "" {
+		return "", fmt.Errorf("%s: decode batch file upload: %s", p.name, strings.TrimSpace(string(data)))
+	}
+
+	create, _ := json.Marshal(map[string]any{
+		"input_file_id":     file.ID,
+		"endpoint":          openAIBatchEndpoint,
+		"completion_window": "24h",
+	})
+	data, err = p.batchDo(ctx, http.MethodPost, p.apiBase+"/batches", bytes.NewReader(create), "application/json")
+	if err != nil {
+		return "", err
+	}
+	var batch openAIBatch
+	if err := json.Unmarshal(data, &batch); err != nil || batch.ID == "" {
+		return "", fmt.Errorf("%s: decode batch: %s", p.name, strings.TrimSpace(string(data)))
+	}
+	return batch.ID, nil
+}
+
+// PollBatch reads the batch status and, once terminal, its output and error files.
+func (p *OpenAIProvider) PollBatch(ctx context.Context, batchID string) (*BatchPoll, error) {
+	data, err := p.batchDo(ctx, http.MethodGet, p.apiBase+"/batches/"+batchID, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	var batch openAIBatch
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return nil, fmt.Errorf("%s: decode batch: %w", p.name, err)
+	}
+	poll := &BatchPoll{Status: batch.Status}
+	switch batch.Status {
+	case "completed", "failed", "expired", "cancelled":
+		poll.Done = true
+	default:
+		return poll, nil
+	}
+	for _, fileID := range []string{batch.OutputFileID, batch.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+		data, err := p.batchDo(ctx, http.MethodGet, p.apiBase+"/files/"+fileID+"/content", nil, "")
+		if err != nil {
+			return nil, err
+		}
+		results, err := p.parseBatchOutput(data)
+		if err != nil {
+			return nil, err
+		}
+		poll.Results = append(poll.Results, results...)
+	}
+	return poll, nil
+}
+
+func (p *OpenAIProvider) parseBatchOutput(data []byte) ([]BatchResult, error) {
+	var results []BatchResult
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 32*1024*1024)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var out openAIBatchOutputLine
+		if err := json.Unmarshal(line, &out); err != nil {
+			return nil, fmt.Errorf("%s: decode batch output: %w", p.name, err)
+		}
+		result := BatchResult{CustomID: out.CustomID}
+		switch {
+		case out.Error != nil:
+			result.Err = fmt.Errorf("%s: batch request %s: %s", p.name, out.Error.Code, out.Error.Message)
+		case out.Response == nil:
+			result.Err = fmt.Errorf("%s: batch request returned no response", p.name)
+		case out.Response.StatusCode != http.StatusOK:
+			result.Err = &HTTPError{Status: out.Response.StatusCode, Body: fmt.Sprintf("%s: %s", p.name, string(out.Response.Body))}
+		default:
+			var parsed openAIResponse
+			if err := json.Unmarshal(out.Response.Body, &parsed); err != nil {
+				result.Err = fmt.Errorf("%s: decode batch response: %w", p.name, err)
+				break
+			}
+			result.Response = p.parseResponse(&parsed)
+			markBatchUsage(result.Response)
+		}
+		results = append(results, result)
+	}
+	return results, sc.Err()
+}
+
+// batchDo performs one Files/Batches API call and returns the response body.
+// The key pool is pinned to one key: uploaded files, batches and their output
+// belong to the key's project, so every call of a batch must use the same key.
+func (p *OpenAIProvider) batchDo(ctx context.Context, method, url string, body io.Reader, contentType string) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(WithPinnedPoolKey(ctx), method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: create request: %w", p.name, err)
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	p.setRequestHeaders(httpReq)
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s: request failed: %w", p.name, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: read response: %w", p.name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPError{
+			Status:     resp.StatusCode,
+			Body:       fmt.Sprintf("%s: %s", p.name, strings.TrimSpace(string(data))),
+			RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return data, nil
+}
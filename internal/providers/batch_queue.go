@@ -0,0 +1,460 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrBatchSubmit wraps failures to queue or submit a batch. Callers should
+// retry such requests synchronously; nothing was sent to the model.
+var ErrBatchSubmit = errors.New("batch submit failed")
+
+// BatchQueueConfig tunes BatchQueue. Zero values use the defaults.
+type BatchQueueConfig struct {
+	FlushInterval time.Duration // how long requests accumulate before submit (default 30s)
+	PollInterval  time.Duration // batch status poll cadence (default 60s)
+	MaxWait       time.Duration // give up on a submitted batch after this long (default 24h)
+	MaxBatchSize  int           // submit early once this many requests are pending (default 1000)
+
+	// StatePath and Registry together make submitted batches survive a
+	// restart: batch and request IDs are written to StatePath, and Start
+	// resumes polling them with the provider Registry resolves. Optional.
+	StatePath string
+	Registry  *Registry
+}
+
+func (c BatchQueueConfig) withDefaults() BatchQueueConfig {
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = 30 * time.Second
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Minute
+	}
+	if c.MaxWait <= 0 {
+		c.MaxWait = 24 * time.Hour
+	}
+	if c.MaxBatchSize <= 0 {
+		c.MaxBatchSize = 1000
+	}
+	return c
+}
+
+// BatchQueue groups non-interactive requests per provider, submits them via
+// the provider's batch API and hands each caller its own result. Callers
+// block until the batch completes, so use it only where latency of minutes
+// to hours is acceptable.
+//
+// With persistence configured, batches submitted before a restart keep being
+// polled. Their original callers are gone, so each result is held until a
+// caller sends the identical request again (workers redo unfinished work) or
+// the batch's MaxWait passes; the retry then gets the already-paid result
+// instead of submitting a new batch.
+type BatchQueue struct {
+	cfg BatchQueueConfig
+
+	mu       sync.Mutex
+	running  bool
+	seq      uint64
+	pending  map[BatchCapable][]*batchItem
+	inflight []*inflightBatch
+	orphans  map[string]*batchItem // resumed items awaiting a caller, by orphanKey
+	flushNow chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+
+	saveMu sync.Mutex // serializes state file writes
+}
+
+type batchItem struct {
+	id     string
+	req    ChatRequest
+	tenant uuid.UUID
+	key    string           // request fingerprint; set when persistence is on
+	result chan BatchResult // buffered(1); abandoned items are never read
+	expiry time.Time        // orphans only: drop after this if unclaimed
+}
+
+type inflightBatch struct {
+	provider  BatchCapable
+	tenant    uuid.UUID
+	id        string
+	items     map[string]*batchItem
+	submitted time.Time
+}
+
+// batchState is the StatePath file format.
+type batchState struct {
+	Batches []persistedBatch `json:"batches"`
+}
+
+type persistedBatch struct {
+	Provider  string            `json:"provider"`
+	TenantID  uuid.UUID         `json:"tenant_id"`
+	ID        string            `json:"id"`
+	Submitted time.Time         `json:"submitted"`
+	Items     map[string]string `json:"items"` // custom ID → request fingerprint
+}
+
+// NewBatchQueue creates a stopped queue. Call Start before Chat.
+func NewBatchQueue(cfg BatchQueueConfig) *BatchQueue {
+	return &BatchQueue{
+		cfg:      cfg.withDefaults(),
+		pending:  make(map[BatchCapable][]*batchItem),
+		orphans:  make(map[string]*batchItem),
+		flushNow: make(chan struct{}, 1),
+	}
+}
+
+func (q *BatchQueue) persistent() bool {
+	return q.cfg.StatePath != "" && q.cfg.Registry != nil
+}
+
+// MaxWait is the longest a caller may block in Chat.
+func (q *BatchQueue) MaxWait() time.Duration {
+	return q.cfg.FlushInterval + q.cfg.MaxWait
+}
+
+// Start launches the flush/poll loop, first resuming any batches recorded in
+// StatePath. It stops when ctx ends or Stop is called.
+func (q *BatchQueue) Start(ctx context.Context) {
+	q.mu.Lock()
+	if q.running {
+		q.mu.Unlock()
+		return
+	}
+	q.running = true
+	q.stop = make(chan struct{})
+	q.done = make(chan struct{})
+	q.mu.Unlock()
+	q.resume()
+	go q.loop(ctx)
+}
+
+// Stop ends the loop and fails every waiting caller with ErrBatchSubmit.
+func (q *BatchQueue) Stop() {
+	q.mu.Lock()
+	if !q.running {
+		q.mu.Unlock()
+		return
+	}
+	q.running = false
+	close(q.stop)
+	done := q.done
+	q.mu.Unlock()
+	<-done
+}
+
+// Chat queues req for the next batch submitted to provider and waits for its
+// result. Errors wrapping ErrBatchSubmit mean the request was never sent.
+func (q *BatchQueue) Chat(ctx context.Context, provider BatchCapable, req ChatRequest) (*ChatResponse, error) {
+	q.mu.Lock()
+	if !q.running {
+		q.mu.Unlock()
+		return nil, fmt.Errorf("%w: batch queue is not running", ErrBatchSubmit)
+	}
+	var tenant uuid.UUID
+	var key string
+	if q.persistent() {
+		tenant = q.cfg.Registry.tenantFromContext(ctx)
+		key = requestFingerprint(req)
+		if orphan, ok := q.orphans[orphanKey(tenant, provider.Name(), key)]; ok {
+			// Same request was submitted before a restart; wait for that result.
+			delete(q.orphans, orphanKey(tenant, provider.Name(), key))
+			q.mu.Unlock()
+			return q.wait(ctx, orphan, nil)
+		}
+	}
+	q.seq++
+	item := &batchItem{id: fmt.Sprintf("req-%d", q.seq), req: req, tenant: tenant, key: key, result: make(chan BatchResult, 1)}
+	q.pending[provider] = append(q.pending[provider], item)
+	full := len(q.pending[provider]) >= q.cfg.MaxBatchSize
+	q.mu.Unlock()
+
+	if full {
+		select {
+		case q.flushNow <- struct{}{}:
+		default:
+		}
+	}
+
+	return q.wait(ctx, item, func() { q.abandon(provider, item) })
+}
+
+func (q *BatchQueue) wait(ctx context.Context, item *batchItem, onCancel func()) (*ChatResponse, error) {
+	select {
+	case res := <-item.result:
+		return res.Response, res.Err
+	case <-ctx.Done():
+		if onCancel != nil {
+			onCancel()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// abandon drops a still-pending item so it is not submitted. Items already
+// submitted complete normally and their result is discarded.
+func (q *BatchQueue) abandon(provider BatchCapable, item *batchItem) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := q.pending[provider]
+	for i, it := range items {
+		if it == item {
+			q.pending[provider] = append(items[:i:i], items[i+1:]...)
+			return
+		}
+	}
+}
+
+func (q *BatchQueue) loop(ctx context.Context) {
+	defer close(q.done)
+	flush := time.NewTicker(q.cfg.FlushInterval)
+	defer flush.Stop()
+	poll := time.NewTicker(q.cfg.PollInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			q.shutdown()
+			return
+		case <-q.stop:
+			q.shutdown()
+			return
+		case <-flush.C:
+			q.flush(ctx)
+		case <-q.flushNow:
+			q.flush(ctx)
+		case <-poll.C:
+			q.poll(ctx)
+		}
+	}
+}
+
+func (q *BatchQueue) flush(ctx context.Context) {
+	q.mu.Lock()
+	pending := q.pending
+	q.pending = make(map[BatchCapable][]*batchItem)
+	q.mu.Unlock()
+
+	for provider, items := range pending {
+		for len(items) > 0 {
+			n := min(len(items), q.cfg.MaxBatchSize)
+			q.submit(ctx, provider, items[:n])
+			items = items[n:]
+		}
+	}
+}
+
+func (q *BatchQueue) submit(ctx context.Context, provider BatchCapable, items []*batchItem) {
+	reqs := make([]BatchRequest, len(items))
+	byID := make(map[string]*batchItem, len(items))
+	for i, it := range items {
+		reqs[i] = BatchRequest{CustomID: it.id, Request: it.req}
+		byID[it.id] = it
+	}
+	batchID, err := provider.SubmitBatch(ctx, reqs)
+	if err != nil {
+		slog.Warn("batch: submit failed", "provider", provider.Name(), "requests", len(items), "error", err)
+		for _, it := range items {
+			it.result <- BatchResult{CustomID: it.id, Err: fmt.Errorf("%w: %v", ErrBatchSubmit, err)}
+		}
+		return
+	}
+	slog.Info("batch: submitted", "provider", provider.Name(), "batch_id", batchID, "requests", len(items))
+	q.mu.Lock()
+	q.inflight = append(q.inflight, &inflightBatch{provider: provider, tenant: items[0].tenant, id: batchID, items: byID, submitted: time.Now()})
+	q.mu.Unlock()
+	q.saveState()
+}
+
+func (q *BatchQueue) poll(ctx context.Context) {
+	q.mu.Lock()
+	batches := q.inflight
+	q.inflight = nil
+	q.mu.Unlock()
+
+	var still []*inflightBatch
+	for _, b := range batches {
+		if !q.pollOne(ctx, b) {
+			still = append(still, b)
+		}
+	}
+	now := time.Now()
+	q.mu.Lock()
+	q.inflight = append(q.inflight, still...)
+	for k, it := range q.orphans {
+		if now.After(it.expiry) {
+			delete(q.orphans, k)
+		}
+	}
+	q.mu.Unlock()
+	if len(still) != len(batches) {
+		q.saveState()
+	}
+}
+
+// pollOne checks one batch and reports whether it is finished with.
+func (q *BatchQueue) pollOne(ctx context.Context, b *inflightBatch) bool {
+	st, err := b.provider.PollBatch(ctx, b.id)
+	if err == nil && st.Done {
+		for _, res := range st.Results {
+			if it, ok := b.items[res.CustomID]; ok {
+				it.result <- res
+				delete(b.items, res.CustomID)
+			}
+		}
+		for id, it := range b.items {
+			it.result <- BatchResult{CustomID: id, Err: fmt.Errorf("%s: batch %s ended (%s) without a result", b.provider.Name(), b.id, st.Status)}
+		}
+		slog.Info("batch: completed", "provider", b.provider.Name(), "batch_id", b.id, "status", st.Status, "results", len(st.Results))
+		return true
+	}
+	if err != nil {
+		slog.Warn("batch: poll failed", "provider", b.provider.Name(), "batch_id", b.id, "error", err)
+	}
+	if time.Since(b.submitted) > q.cfg.MaxWait {
+		for id, it := range b.items {
+			it.result <- BatchResult{CustomID: id, Err: fmt.Errorf("%s: batch %s not finished after %s", b.provider.Name(), b.id, q.cfg.MaxWait)}
+		}
+		return true
+	}
+	return false
+}
+
+// shutdown fails all waiting callers. Submitted batches keep running upstream;
+// with persistence on they stay in StatePath for the next Start.
+func (q *BatchQueue) shutdown() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.running = false
+	stopped := fmt.Errorf("%w: batch queue stopped", ErrBatchSubmit)
+	for _, items := range q.pending {
+		for _, it := range items {
+			it.result <- BatchResult{CustomID: it.id, Err: stopped}
+		}
+	}
+	for _, b := range q.inflight {
+		for id, it := range b.items {
+			it.result <- BatchResult{CustomID: id, Err: fmt.Errorf("batch %s abandoned: batch queue stopped", b.id)}
+		}
+	}
+	q.pending = make(map[BatchCapable][]*batchItem)
+	q.inflight = nil
+	q.orphans = make(map[string]*batchItem)
+}
+
+// resume reloads batches recorded in StatePath. Batches past MaxWait or whose
+// provider is no longer registered are dropped.
+func (q *BatchQueue) resume() {
+	if !q.persistent() {
+		return
+	}
+	data, err := os.ReadFile(q.cfg.StatePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("batch: read state failed", "path", q.cfg.StatePath, "error", err)
+		}
+		return
+	}
+	var st batchState
+	if err := json.Unmarshal(data, &st); err != nil {
+		slog.Warn("batch: parse state failed", "path", q.cfg.StatePath, "error", err)
+		return
+	}
+	var resumed []*inflightBatch
+	for _, pb := range st.Batches {
+		expiry := pb.Submitted.Add(q.cfg.MaxWait)
+		if time.Now().After(expiry) {
+			slog.Warn("batch: dropping expired batch", "provider", pb.Provider, "batch_id", pb.ID)
+			continue
+		}
+		p, err := q.cfg.Registry.GetForTenant(pb.TenantID, pb.Provider)
+		bc, ok := AsBatchCapable(p)
+		if err != nil || !ok {
+			slog.Warn("batch: dropping batch for unavailable provider", "provider", pb.Provider, "batch_id", pb.ID)
+			continue
+		}
+		b := &inflightBatch{provider: bc, tenant: pb.TenantID, id: pb.ID, items: make(map[string]*batchItem, len(pb.Items)), submitted: pb.Submitted}
+		for id, key := range pb.Items {
+			b.items[id] = &batchItem{id: id, tenant: pb.TenantID, key: key, result: make(chan BatchResult, 1), expiry: expiry}
+		}
+		resumed = append(resumed, b)
+	}
+	q.mu.Lock()
+	for _, b := range resumed {
+		for _, it := range b.items {
+			q.orphans[orphanKey(b.tenant, b.provider.Name(), it.key)] = it
+		}
+	}
+	q.inflight = append(q.inflight, resumed...)
+	q.mu.Unlock()
+	if len(resumed) > 0 {
+		slog.Info("batch: resumed submitted batches", "batches", len(resumed))
+	}
+	if len(resumed) != len(st.Batches) {
+		q.saveState()
+	}
+}
+
+// saveState writes the in-flight batches to StatePath (tmp file + rename).
+// Failures are logged: the queue still works, it just cannot resume.
+func (q *BatchQueue) saveState() {
+	if !q.persistent() {
+		return
+	}
+	q.saveMu.Lock()
+	defer q.saveMu.Unlock()
+
+	st := batchState{Batches: []persistedBatch{}}
+	q.mu.Lock()
+	if !q.running {
+		// Stopping: keep the file as last written so the next Start resumes.
+		q.mu.Unlock()
+		return
+	}
+	for _, b := range q.inflight {
+		items := make(map[string]string, len(b.items))
+		for id, it := range b.items {
+			items[id] = it.key
+		}
+		st.Batches = append(st.Batches, persistedBatch{Provider: b.provider.Name(), TenantID: b.tenant, ID: b.id, Submitted: b.submitted, Items: items})
+	}
+	q.mu.Unlock()
+
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(q.cfg.StatePath), 0o755)
+	}
+	if err == nil {
+		tmp := q.cfg.StatePath + ".tmp"
+		if err = os.WriteFile(tmp, data, 0o600); err == nil {
+			err = os.Rename(tmp, q.cfg.StatePath)
+		}
+	}
+	if err != nil {
+		slog.Warn("batch: write state failed", "path", q.cfg.StatePath, "error", err)
+	}
+}
+
+// requestFingerprint identifies a request across restarts.
+func requestFingerprint(req ChatRequest) string {
+	data, _ := json.Marshal(req)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func orphanKey(tenant uuid.UUID, provider, fingerprint string) string {
+	return tenant.String() + "/" + provider + "/" + fingerprint
+}
@@ -0,0 +1,495 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// AWS Bedrock constants. Like the Vertex constants, these live in providers
+// (not store) to keep the providers package free of a store import.
+const (
+	// BedrockDefaultModel is a cross-region inference profile for Claude Sonnet 4.5.
+	// Newer Claude models are only invokable on-demand through inference profiles.
+	BedrockDefaultModel = "us.anthropic.claude-sonnet-4-5-20250929-v1:0"
+
+	// BedrockSigningService is the SigV4 service name for the Bedrock runtime API.
+	BedrockSigningService = "bedrock"
+
+	// ProviderTypeBedrock mirrors store.ProviderBedrock; kept in sync by convention.
+	ProviderTypeBedrock = "bedrock"
+)
+
+// BedrockDefaultAPIBase returns the Bedrock runtime endpoint for a region.
+func BedrockDefaultAPIBase(region string) string {
+	if region == "" {
+		return ""
+	}
+	return "https://bedrock-runtime." + region + ".amazonaws.com"
+}
+
+// BedrockConfig is the input needed to build a Bedrock provider instance.
+// Credentials precedence:
+//  1. AccessKeyID + SecretAccessKey (+ optional SessionToken) — SigV4 with static keys
+//  2. APIKey — Bedrock API key sent as a Bearer token (no SigV4)
+//  3. none — the AWS default credential chain (env, shared config/profile, IMDS, IRSA)
+type BedrockConfig struct {
+	Name            string // registry name; defaults to "bedrock"
+	Region          string // required — AWS region (e.g. "us-east-1")
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	APIKey          string // Bedrock API key (bearer); ignored when AccessKeyID is set
+	DefaultModel    string // model ID or inference profile ID/ARN; defaults to BedrockDefaultModel
+	APIBaseOverride string // optional — e.g. a VPC endpoint; must be https on amazonaws.com
+}
+
+// AWS region format, e.g. "us-east-1", "ap-southeast-2", "us-gov-west-1".
+var bedrockRegionRe = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-\d$`)
+
+func validateBedrockRegion(region string) error {
+	if !bedrockRegionRe.MatchString(region) {
+		return fmt.Errorf("bedrock: invalid region %q (expected e.g. us-east-1)", region)
+	}
+	return nil
+}
+
+// validateBedrockAPIBaseOverride requires https and an amazonaws.com host so a
+// crafted DB row cannot send signed requests (and prompts) elsewhere.
+func validateBedrockAPIBaseOverride(base string) error {
+	u, err := url.Parse(base)
+	if err != nil {
+		return fmt.Errorf("bedrock: invalid api_base_override %q: %w", base, err)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("bedrock: api_base_override must use https scheme, got %q", u.Scheme)
+	}
+	host := strings.ToLower(u.Hostname())
+	if !strings.HasSuffix(host, ".amazonaws.com") {
+		return fmt.Errorf("bedrock: api_base_override host %q is not an amazonaws.com endpoint", host)
+	}
+	return nil
+}
+
+// BedrockProvider implements Provider against the Bedrock Converse and
+// ConverseStream APIs. Requests are signed with SigV4 (or carry a Bedrock API
+// key); bodies use the model-agnostic Converse shape, with Claude-specific
+// extras (extended thinking, prompt caching) enabled per model.
+type BedrockProvider struct {
+	name         string
+	region       string
+	baseURL      string
+	defaultModel string
+	creds        aws.CredentialsProvider // nil when bearerToken is used
+	bearerToken  string
+	signer       *v4.Signer
+	client       *http.Client
+	retryConfig  RetryConfig
+	registry     ModelRegistry
+	now          func() time.Time
+}
+
+// NewBedrockProvider constructs a Bedrock provider. When no static keys or API
+// key are configured, the AWS default credential chain is resolved using ctx.
+func NewBedrockProvider(ctx context.Context, cfg BedrockConfig) (*BedrockProvider, error) {
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("bedrock: region is required")
+	}
+	if err := validateBedrockRegion(cfg.Region); err != nil {
+		return nil, err
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(cfg.APIBaseOverride), "/")
+	if baseURL != "" {
+		if err := validateBedrockAPIBaseOverride(baseURL); err != nil {
+			return nil, err
+		}
+	} else {
+		baseURL = BedrockDefaultAPIBase(cfg.Region)
+	}
+
+	p := &BedrockProvider{
+		name:         cfg.Name,
+		region:       cfg.Region,
+		baseURL:      baseURL,
+		defaultModel: cfg.DefaultModel,
+		signer:       v4.NewSigner(),
+		client:       NewDefaultHTTPClient(),
+		retryConfig:  DefaultRetryConfig(),
+		now:          time.Now,
+	}
+	if p.name == "" {
+		p.name = "bedrock"
+	}
+	if p.defaultModel == "" {
+		p.defaultModel = BedrockDefaultModel
+	}
+
+	switch {
+	case cfg.AccessKeyID != "":
+		if cfg.SecretAccessKey == "" {
+			return nil, fmt.Errorf("bedrock: secret access key is required with access_key_id")
+		}
+		p.creds = aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(
+			cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken))
+	case cfg.APIKey != "":
+		p.bearerToken = cfg.APIKey
+	default:
+		awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(cfg.Region))
+		if err != nil {
+			return nil, fmt.Errorf("bedrock: load default AWS credentials: %w", err)
+		}
+		if awsCfg.Credentials == nil {
+			return nil, fmt.Errorf("bedrock: no AWS credentials found (set access_key_id, an API key, or AWS_* env/profile)")
+		}
+		p.creds = awsCfg.Credentials
+	}
+	return p, nil
+}
+
+// bedrockInitTimeout caps default-chain discovery so IMDS lookups on non-AWS
+// hosts don't stall gateway startup.
+const bedrockInitTimeout = 10 * time.Second
+
+// NewBedrockProviderWithTimeout wraps NewBedrockProvider with a bounded context.
+func NewBedrockProviderWithTimeout(cfg BedrockConfig) (*BedrockProvider, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bedrockInitTimeout)
+	defer cancel()
+	return NewBedrockProvider(ctx, cfg)
+}
+
+// WithHTTPClient replaces the HTTP client (tests, custom proxies).
+func (p *BedrockProvider) WithHTTPClient(c *http.Client) *BedrockProvider {
+	if c != nil {
+		p.client = c
+	}
+	return p
+}
+
+// WithBaseURL overrides the runtime endpoint without the amazonaws.com check.
+// Intended for tests and operator-controlled wiring only.
+func (p *BedrockProvider) WithBaseURL(baseURL string) *BedrockProvider {
+	if baseURL != "" {
+		p.baseURL = strings.TrimRight(baseURL, "/")
+	}
+	return p
+}
+
+// WithRetryConfig overrides the retry policy.
+func (p *BedrockProvider) WithRetryConfig(cfg RetryConfig) *BedrockProvider {
+	p.retryConfig = cfg
+	return p
+}
+
+// WithRegistry enables forward-compat model resolution for Claude model IDs.
+func (p *BedrockProvider) WithRegistry(r ModelRegistry) *BedrockProvider {
+	p.registry = r
+	return p
+}
+
+func (p *BedrockProvider) Name() string           { return p.name }
+func (p *BedrockProvider) DefaultModel() string   { return p.defaultModel }
+func (p *BedrockProvider) SupportsThinking() bool { return true }
+func (p *BedrockProvider) Region() string         { return p.region }
+
+// Capabilities implements CapabilitiesAware. Values describe the Claude family
+// (the default); non-Claude models simply ignore thinking/cache extras.
+func (p *BedrockProvider) Capabilities() ProviderCapabilities {
+	return ProviderCapabilities{
+		Streaming:        true,
+		ToolCalling:      true,
+		StreamWithTools:  true,
+		Thinking:         true,
+		Vision:           true,
+		CacheControl:     true,
+		StructuredOutput: true,
+		MaxContextWindow: 200_000,
+		TokenizerID:      "cl100k_base",
+	}
+}
+
+// resolveModel applies the default model and triggers forward-compat resolution
+// for the underlying Claude model so token counting has specs.
+func (p *BedrockProvider) resolveModel(model string) string {
+	if model == "" {
+		return p.defaultModel
+	}
+	if p.registry != nil {
+		if base := bedrockClaudeBaseModel(model); base != "" {
+			_ = p.registry.Resolve("anthropic", base)
+		}
+	}
+	return model
+}
+
+func (p *BedrockProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	model := p.resolveModel(req.Model)
+	body := p.buildRequestBody(model, req)
+
+	resp, err := RetryDo(ctx, p.retryConfig, func() (*ChatResponse, error) {
+		respBody, err := p.doRequest(ctx, model, "converse", body)
+		if err != nil {
+			return nil, err
+		}
+		defer respBody.Close()
+
+		var parsed bedrockConverseResponse
+		if err := json.NewDecoder(respBody).Decode(&parsed); err != nil {
+			return nil, fmt.Errorf("bedrock: decode response: %w", err)
+		}
+		result := parseBedrockResponse(&parsed)
+		applyAnthropicResponseFormat(req.ResponseFormat, result)
+		return result, nil
+	})
+	if resp != nil {
+		if strip, _ := req.Options[OptStripThinking].(bool); strip {
+			resp.Thinking = ""
+		}
+	}
+	return resp, err
+}
+
+// doRequest POSTs body to /model/{modelId}/{action} and returns the response
+// body on 200. The request is rebuilt and re-signed on every call, so RetryDo
+// retries never reuse an expired signature.
+func (p *BedrockProvider) doRequest(ctx context.Context, model, action string, body any) (io.ReadCloser, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("bedrock: marshal request: %w", err)
+	}
+
+	endpoint := p.baseURL + "/model/" + bedrockEscapeModelID(model) + "/" + action
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("bedrock: create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if action == "converse-stream" {
+		httpReq.Header.Set("Accept", "application/vnd.amazon.eventstream")
+	} else {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+
+	if err := p.authorize(ctx, httpReq, data); err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("bedrock: request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, &HTTPError{
+			Status:     resp.StatusCode,
+			Body:       "bedrock: " + bedrockErrorMessage(resp.Header.Get("X-Amzn-Errortype"), respBody),
+			RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return resp.Body, nil
+}
+
+// bedrockEscapeModelID percent-encodes a model ID (or inference-profile ARN)
+// as a single path segment. Every byte outside RFC 3986 unreserved characters
+// is encoded — including ':' and '/', which url.PathEscape leaves or mangles —
+// so the SigV4 canonical URI matches what the Bedrock service computes.
+func bedrockEscapeModelID(model string) string {
+	var b strings.Builder
+	for i := 0; i < len(model); i++ {
+		c := model[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// authorize adds either a Bearer API key or a SigV4 signature to req.
+func (p *BedrockProvider) authorize(ctx context.Context, req *http.Request, payload []byte) error {
+	if p.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+p.bearerToken)
+		return nil
+	}
+	creds, err := p.creds.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("bedrock: retrieve credentials: %w", err)
+	}
+	sum := sha256.Sum256(payload)
+	if err := p.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]),
+		BedrockSigningService, p.region, p.now()); err != nil {
+		return fmt.Errorf("bedrock: sign request: %w", err)
+	}
+	return nil
+}
+
+// bedrockErrorMessage formats an error body ({"message": "..."}) with the
+// x-amzn-ErrorType prefix (e.g. "ThrottlingException:http://...").
+func bedrockErrorMessage(errType string, body []byte) string {
+	errType, _, _ = strings.Cut(errType, ":")
+	var parsed struct {
+		Message string `json:"message"`
+	}
+	msg := string(body)
+	if json.Unmarshal(body, &parsed) == nil && parsed.Message != "" {
+		msg = parsed.Message
+	}
+	if errType == "" {
+		return msg
+	}
+	return errType + ": " + msg
+}
+
+// bedrockClaudeBaseModel returns the Anthropic model name embedded in a
+// Bedrock model/profile ID ("us.anthropic.claude-sonnet-4-5-20250929-v1:0" →
+// "claude-sonnet-4-5-20250929"), or "" for non-Claude models.
+func bedrockClaudeBaseModel(model string) string {
+	m := strings.ToLower(model)
+	idx := strings.Index(m, "anthropic.claude-")
+	if idx < 0 {
+		return ""
+	}
+	base := m[idx+len("anthropic."):]
+	if v := strings.LastIndex(base, "-v"); v > 0 {
+		base = base[:v]
+	}
+	return base
+}
+
+// bedrockSupportsPromptCache reports whether the model accepts cachePoint blocks.
+// Unsupported models reject the request outright, so this is an allowlist.
+func bedrockSupportsPromptCache(model string) bool {
+	m := strings.ToLower(model)
+	return strings.Contains(m, "anthropic.claude") || strings.Contains(m, "amazon.nova")
+}
+
+// --- Converse API types (internal) ---
+
+type bedrockConverseResponse struct {
+	Output struct {
+		Message struct {
+			Role    string                `json:"role"`
+			Content []bedrockContentBlock `json:"content"`
+		} `json:"message"`
+	} `json:"output"`
+	StopReason string       `json:"stopReason"`
+	Usage      bedrockUsage `json:"usage"`
+}
+
+type bedrockContentBlock struct {
+	Text             string                   `json:"text,omitempty"`
+	ToolUse          *bedrockToolUse          `json:"toolUse,omitempty"`
+	ReasoningContent *bedrockReasoningContent `json:"reasoningContent,omitempty"`
+}
+
+type bedrockToolUse struct {
+	ToolUseID string          `json:"toolUseId"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input,omitempty"`
+}
+
+type bedrockReasoningContent struct {
+	ReasoningText   *bedrockReasoningText `json:"reasoningText,omitempty"`
+	RedactedContent string                `json:"redactedContent,omitempty"` // base64
+}
+
+type bedrockReasoningText struct {
+	Text      string `json:"text"`
+	Signature string `json:"signature,omitempty"`
+}
+
+type bedrockUsage struct {
+	InputTokens           int `json:"inputTokens"`
+	OutputTokens          int `json:"outputTokens"`
+	TotalTokens           int `json:"totalTokens"`
+	CacheReadInputTokens  int `json:"cacheReadInputTokens,omitempty"`
+	CacheWriteInputTokens int `json:"cacheWriteInputTokens,omitempty"`
+}
+
+// toUsage maps Converse usage to Usage. Converse inputTokens excludes cached
+// segments (same convention as Anthropic), so PromptTokens is left as-is.
+func (u bedrockUsage) toUsage() *Usage {
+	total := u.TotalTokens
+	if total == 0 {
+		total = u.InputTokens + u.OutputTokens
+	}
+	return &Usage{
+		PromptTokens:        u.InputTokens,
+		CompletionTokens:    u.OutputTokens,
+		TotalTokens:         total,
+		CacheCreationTokens: u.CacheWriteInputTokens,
+		CacheReadTokens:     u.CacheReadInputTokens,
+	}
+}
+
+func bedrockFinishReason(stopReason string) string {
+	switch stopReason {
+	case "tool_use":
+		return "tool_calls"
+	case "max_tokens", "model_context_window_exceeded":
+		return "length"
+	default:
+		return "stop"
+	}
+}
+
+func parseBedrockResponse(resp *bedrockConverseResponse) *ChatResponse {
+	result := &ChatResponse{FinishReason: bedrockFinishReason(resp.StopReason)}
+	thinkingChars := 0
+
+	for _, block := range resp.Output.Message.Content {
+		switch {
+		case block.ToolUse != nil:
+			args := make(map[string]any)
+			var parseErr string
+			if err := json.Unmarshal(block.ToolUse.Input, &args); err != nil && len(block.ToolUse.Input) > 0 {
+				parseErr = fmt.Sprintf("malformed JSON (%d chars): %v", len(block.ToolUse.Input), err)
+			}
+			result.ToolCalls = append(result.ToolCalls, ToolCall{
+				ID:         block.ToolUse.ToolUseID,
+				Name:       strings.TrimSpace(block.ToolUse.Name),
+				Arguments:  args,
+				ParseError: parseErr,
+			})
+		case block.ReasoningContent != nil:
+			if rt := block.ReasoningContent.ReasoningText; rt != nil {
+				result.Thinking += rt.Text
+				thinkingChars += len(rt.Text)
+				if rt.Signature != "" {
+					result.ThinkingSignature = rt.Signature
+				}
+			}
+		default:
+			result.Content += block.Text
+		}
+	}
+
+	result.Usage = resp.Usage.toUsage()
+	if thinkingChars > 0 {
+		result.Usage.ThinkingTokens = thinkingChars / 4
+	}
+
+	// Preserve Converse content blocks (reasoning + signature) for tool passback.
+	if len(result.ToolCalls) > 0 {
+		if b, err := json.Marshal(resp.Output.Message.Content); err == nil {
+			result.RawAssistantContent = b
+		}
+	}
+	return result
+}
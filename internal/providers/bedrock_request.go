@@ -0,0 +1,199 @@
+package providers
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// bedrockCachePoint is the Converse prompt-caching marker (the Bedrock
+// equivalent of Anthropic's cache_control: ephemeral).
+var bedrockCachePoint = map[string]any{"cachePoint": map[string]any{"type": "default"}}
+
+// bedrockImageFormats maps MIME types to Converse image formats.
+var bedrockImageFormats = map[string]string{
+	"image/png":  "png",
+	"image/jpeg": "jpeg",
+	"image/jpg":  "jpeg",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+// bedrockSystemBlocks splits the system prompt at CacheBoundaryMarker the same
+// way splitSystemPromptForCache does, placing a cachePoint after the stable part.
+func bedrockSystemBlocks(content string, cache bool) []map[string]any {
+	if !cache {
+		return []map[string]any{{"text": strings.ReplaceAll(content, CacheBoundaryMarker, "")}}
+	}
+	before, after, ok := strings.Cut(content, CacheBoundaryMarker)
+	if !ok {
+		return []map[string]any{{"text": content}, bedrockCachePoint}
+	}
+	blocks := []map[string]any{{"text": strings.TrimSpace(before)}, bedrockCachePoint}
+	if dynamic := strings.TrimSpace(after); dynamic != "" {
+		blocks = append(blocks, map[string]any{"text": dynamic})
+	}
+	return blocks
+}
+
+// buildRequestBody converts a ChatRequest into a Converse/ConverseStream body.
+// The model ID goes in the URL path, not the body.
+func (p *BedrockProvider) buildRequestBody(model string, req ChatRequest) map[string]any {
+	cache := bedrockSupportsPromptCache(model)
+	claude := bedrockClaudeBaseModel(model)
+
+	var system []map[string]any
+	var messages []map[string]any
+
+	// Converse requires strictly alternating roles, so consecutive user-side
+	// turns (e.g. several tool results) are merged into one message.
+	appendBlocks := func(role string, blocks []any) {
+		if len(blocks) == 0 {
+			return
+		}
+		if n := len(messages); n > 0 && messages[n-1]["role"] == role {
+			messages[n-1]["content"] = append(messages[n-1]["content"].([]any), blocks...)
+			return
+		}
+		messages = append(messages, map[string]any{"role": role, "content": blocks})
+	}
+
+	for _, msg := range req.Messages {
+		switch msg.Role {
+		case "system":
+			system = append(system, bedrockSystemBlocks(msg.Content, cache)...)
+
+		case "user":
+			var blocks []any
+			for _, img := range msg.Images {
+				format, ok := bedrockImageFormats[strings.ToLower(img.MimeType)]
+				if !ok || img.Data == "" {
+					continue // URL-only images and unsupported formats are skipped
+				}
+				blocks = append(blocks, map[string]any{
+					"image": map[string]any{
+						"format": format,
+						"source": map[string]any{"bytes": img.Data},
+					},
+				})
+			}
+			if msg.Content != "" {
+				blocks = append(blocks, map[string]any{"text": msg.Content})
+			}
+			appendBlocks("user", blocks)
+
+		case "assistant":
+			// Raw Converse blocks preserve reasoning signatures for tool passback.
+			if msg.RawAssistantContent != nil {
+				var raw []json.RawMessage
+				if json.Unmarshal(msg.RawAssistantContent, &raw) == nil && len(raw) > 0 {
+					blocks := make([]any, len(raw))
+					for i, b := range raw {
+						blocks[i] = b
+					}
+					appendBlocks("assistant", blocks)
+					continue
+				}
+			}
+			var blocks []any
+			if msg.Content != "" {
+				blocks = append(blocks, map[string]any{"text": msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				args := tc.Arguments
+				if args == nil {
+					args = map[string]any{}
+				}
+				blocks = append(blocks, map[string]any{
+					"toolUse": map[string]any{"toolUseId": tc.ID, "name": tc.Name, "input": args},
+				})
+			}
+			appendBlocks("assistant", blocks)
+
+		case "tool":
+			result := map[string]any{
+				"toolUseId": msg.ToolCallID,
+				"content":   []map[string]any{{"text": msg.Content}},
+			}
+			if msg.IsError {
+				result["status"] = "error"
+			}
+			appendBlocks("user", []any{map[string]any{"toolResult": result}})
+		}
+	}
+
+	body := map[string]any{"messages": messages}
+	if len(system) > 0 {
+		body["system"] = system
+	}
+
+	inference := map[string]any{"maxTokens": 4096}
+	if v, ok := req.Options[OptMaxTokens]; ok {
+		inference["maxTokens"] = v
+	}
+	if v, ok := req.Options[OptTemperature]; ok && (claude == "" || !anthropicSkipsTemperature(claude)) {
+		inference["temperature"] = v
+	}
+	body["inferenceConfig"] = inference
+
+	var tools []map[string]any
+	for _, t := range req.Tools {
+		if t.Function == nil {
+			continue
+		}
+		tools = append(tools, map[string]any{
+			"toolSpec": map[string]any{
+				"name":        t.Function.Name,
+				"description": t.Function.Description,
+				"inputSchema": map[string]any{"json": CleanSchemaForProvider("anthropic", t.Function.Parameters)},
+			},
+		})
+	}
+	if len(tools) > 0 && cache {
+		tools = append(tools, bedrockCachePoint)
+	}
+
+	// Structured output: same forced-tool strategy as the Anthropic provider.
+	// Chat/ChatStream fold the tool input back into Content.
+	var toolChoice map[string]any
+	if rf := req.ResponseFormat; rf != nil {
+		schema := map[string]any{"type": "object"}
+		if rf.HasSchema() {
+			schema = CleanSchemaForProvider("anthropic", rf.Schema)
+		}
+		desc := rf.Description
+		if desc == "" {
+			desc = "Return the final answer as structured JSON by calling this tool."
+		}
+		tools = append(tools, map[string]any{
+			"toolSpec": map[string]any{
+				"name":        rf.SchemaName(),
+				"description": desc,
+				"inputSchema": map[string]any{"json": schema},
+			},
+		})
+		toolChoice = map[string]any{"tool": map[string]any{"name": rf.SchemaName()}}
+	}
+	if len(tools) > 0 {
+		toolConfig := map[string]any{"tools": tools}
+		if toolChoice != nil {
+			toolConfig["toolChoice"] = toolChoice
+		}
+		body["toolConfig"] = toolConfig
+	}
+
+	// Extended thinking is a Claude-only model field. Like the Anthropic provider,
+	// it's skipped under a forced tool_choice, which Claude rejects with thinking on.
+	if level, ok := req.Options[OptThinkingLevel].(string); ok && claude != "" &&
+		level != "" && level != "off" && req.ResponseFormat == nil {
+		budget := anthropicThinkingBudget(level)
+		body["additionalModelRequestFields"] = map[string]any{
+			"thinking": map[string]any{"type": "enabled", "budget_tokens": budget},
+		}
+		delete(inference, "temperature")
+		if maxTok, ok := inference["maxTokens"].(int); !ok || maxTok < budget+4096 {
+			inference["maxTokens"] = budget + 8192
+		}
+	}
+
+	return body
+}
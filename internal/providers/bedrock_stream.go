@@ -0,0 +1,230 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+)
+
+// ConverseStream event payloads (application/vnd.amazon.eventstream frames;
+// the event name is carried in the ":event-type" header).
+
+type bedrockContentBlockStartEvent struct {
+	ContentBlockIndex int `json:"contentBlockIndex"`
+	Start             struct {
+		ToolUse *bedrockToolUse `json:"toolUse,omitempty"`
+	} `json:"start"`
+}
+
+type bedrockContentBlockDeltaEvent struct {
+	ContentBlockIndex int `json:"contentBlockIndex"`
+	Delta             struct {
+		Text    *string `json:"text,omitempty"`
+		ToolUse *struct {
+			Input string `json:"input"`
+		} `json:"toolUse,omitempty"`
+		ReasoningContent *struct {
+			Text            string `json:"text,omitempty"`
+			Signature       string `json:"signature,omitempty"`
+			RedactedContent string `json:"redactedContent,omitempty"`
+		} `json:"reasoningContent,omitempty"`
+	} `json:"delta"`
+}
+
+type bedrockMessageStopEvent struct {
+	StopReason string `json:"stopReason"`
+}
+
+type bedrockMetadataEvent struct {
+	Usage bedrockUsage `json:"usage"`
+}
+
+// bedrockStreamBlock accumulates one content block by contentBlockIndex so the
+// full Converse content array can be rebuilt for RawAssistantContent.
+type bedrockStreamBlock struct {
+	text      strings.Builder
+	reasoning strings.Builder
+	signature string
+	redacted  string
+	toolIdx   int // index into result.ToolCalls; -1 when not a tool block
+	toolJSON  strings.Builder
+}
+
+func (p *BedrockProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	model := p.resolveModel(req.Model)
+	stripThinking, _ := req.Options[OptStripThinking].(bool)
+	body := p.buildRequestBody(model, req)
+
+	// Retry only the connection phase; once streaming starts, no retry.
+	respBody, err := RetryDo(ctx, p.retryConfig, func() (io.ReadCloser, error) {
+		return p.doRequest(ctx, model, "converse-stream", body)
+	})
+	if err != nil {
+		return nil, err
+	}
+	cb := NewCtxBody(ctx, respBody)
+	defer cb.Close()
+
+	result := &ChatResponse{FinishReason: "stop"}
+	blocks := make(map[int]*bedrockStreamBlock)
+	var order []int
+	blockAt := func(idx int) *bedrockStreamBlock {
+		if b, ok := blocks[idx]; ok {
+			return b
+		}
+		b := &bedrockStreamBlock{toolIdx: -1}
+		blocks[idx] = b
+		order = append(order, idx)
+		return b
+	}
+	thinkingChars := 0
+
+	dec := eventstream.NewDecoder()
+	payloadBuf := make([]byte, 0, 16*1024)
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		msg, err := dec.Decode(cb, payloadBuf)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return result, fmt.Errorf("bedrock stream read error: %w", err)
+		}
+
+		switch headerString(msg.Headers, ":message-type") {
+		case "exception", "error":
+			name := headerString(msg.Headers, ":exception-type")
+			if name == "" {
+				name = headerString(msg.Headers, ":error-code")
+			}
+			return nil, fmt.Errorf("bedrock stream error: %s", bedrockErrorMessage(name, msg.Payload))
+		}
+
+		switch headerString(msg.Headers, ":event-type") {
+		case "contentBlockStart":
+			var ev bedrockContentBlockStartEvent
+			if json.Unmarshal(msg.Payload, &ev) == nil && ev.Start.ToolUse != nil {
+				b := blockAt(ev.ContentBlockIndex)
+				b.toolIdx = len(result.ToolCalls)
+				result.ToolCalls = append(result.ToolCalls, ToolCall{
+					ID:        ev.Start.ToolUse.ToolUseID,
+					Name:      strings.TrimSpace(ev.Start.ToolUse.Name),
+					Arguments: make(map[string]any),
+				})
+			}
+
+		case "contentBlockDelta":
+			var ev bedrockContentBlockDeltaEvent
+			if json.Unmarshal(msg.Payload, &ev) != nil {
+				continue
+			}
+			b := blockAt(ev.ContentBlockIndex)
+			switch d := ev.Delta; {
+			case d.Text != nil:
+				b.text.WriteString(*d.Text)
+				result.Content += *d.Text
+				if onChunk != nil && *d.Text != "" {
+					onChunk(StreamChunk{Content: *d.Text})
+				}
+			case d.ToolUse != nil:
+				b.toolJSON.WriteString(d.ToolUse.Input)
+			case d.ReasoningContent != nil:
+				rc := d.ReasoningContent
+				b.reasoning.WriteString(rc.Text)
+				if rc.Signature != "" {
+					b.signature += rc.Signature
+				}
+				if rc.RedactedContent != "" {
+					b.redacted += rc.RedactedContent
+				}
+				thinkingChars += len(rc.Text)
+				if rc.Text != "" && !stripThinking {
+					result.Thinking += rc.Text
+					if onChunk != nil {
+						onChunk(StreamChunk{Thinking: rc.Text})
+					}
+				}
+			}
+
+		case "messageStop":
+			var ev bedrockMessageStopEvent
+			if json.Unmarshal(msg.Payload, &ev) == nil && ev.StopReason != "" {
+				result.FinishReason = bedrockFinishReason(ev.StopReason)
+			}
+
+		case "metadata":
+			var ev bedrockMetadataEvent
+			if json.Unmarshal(msg.Payload, &ev) == nil {
+				result.Usage = ev.Usage.toUsage()
+			}
+		}
+	}
+
+	// Finalize tool arguments and rebuild the Converse content array.
+	raw := make([]bedrockContentBlock, 0, len(order))
+	for _, idx := range order {
+		b := blocks[idx]
+		switch {
+		case b.toolIdx >= 0:
+			tc := &result.ToolCalls[b.toolIdx]
+			input := b.toolJSON.String()
+			if input != "" {
+				args := make(map[string]any)
+				if err := json.Unmarshal([]byte(input), &args); err != nil {
+					tc.ParseError = fmt.Sprintf("malformed JSON (%d chars): %v", len(input), err)
+				}
+				tc.Arguments = args
+			}
+			argsJSON, _ := json.Marshal(tc.Arguments)
+			raw = append(raw, bedrockContentBlock{ToolUse: &bedrockToolUse{
+				ToolUseID: tc.ID, Name: tc.Name, Input: argsJSON,
+			}})
+		case b.reasoning.Len() > 0 || b.signature != "" || b.redacted != "":
+			rc := &bedrockReasoningContent{RedactedContent: b.redacted}
+			if b.redacted == "" {
+				rc.ReasoningText = &bedrockReasoningText{Text: b.reasoning.String(), Signature: b.signature}
+			}
+			if b.signature != "" {
+				result.ThinkingSignature = b.signature
+			}
+			raw = append(raw, bedrockContentBlock{ReasoningContent: rc})
+		case b.text.Len() > 0:
+			raw = append(raw, bedrockContentBlock{Text: b.text.String()})
+		}
+	}
+
+	if thinkingChars > 0 {
+		if result.Usage == nil {
+			result.Usage = &Usage{}
+		}
+		result.Usage.ThinkingTokens = thinkingChars / 4
+	}
+	if len(result.ToolCalls) > 0 {
+		if b, err := json.Marshal(raw); err == nil {
+			result.RawAssistantContent = b
+		}
+	}
+	applyAnthropicResponseFormat(req.ResponseFormat, result)
+
+	if onChunk != nil {
+		onChunk(StreamChunk{Done: true})
+	}
+	return result, nil
+}
+
+// headerString returns a string-valued event-stream header, or "".
+func headerString(h eventstream.Headers, name string) string {
+	if v := h.Get(name); v != nil {
+		if s, ok := v.Get().(string); ok {
+			return s
+		}
+	}
+	return ""
+}
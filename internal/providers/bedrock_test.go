@@ -0,0 +1,202 @@
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func newTestBedrockProvider() *BedrockProvider {
+	return &BedrockProvider{name: "bedrock", region: "us-east-1", defaultModel: BedrockDefaultModel}
+}
+
+func TestBedrockBuildRequestBody_ClaudeCachingAndMerging(t *testing.T) {
+	p := newTestBedrockProvider()
+	body := p.buildRequestBody(BedrockDefaultModel, ChatRequest{
+		Messages: []Message{
+			{Role: "system", Content: "stable part" + CacheBoundaryMarker + "dynamic part"},
+			{Role: "user", Content: "look", Images: []ImageContent{{MimeType: "image/png", Data: "iVBORw0"}}},
+			{Role: "assistant", ToolCalls: []ToolCall{
+				{ID: "t1", Name: "a", Arguments: map[string]any{"x": 1}},
+				{ID: "t2", Name: "b"},
+			}},
+			{Role: "tool", ToolCallID: "t1", Content: "ok"},
+			{Role: "tool", ToolCallID: "t2", Content: "boom", IsError: true},
+		},
+		Tools: []ToolDefinition{{Type: "function", Function: &ToolFunctionSchema{
+			Name: "a", Parameters: map[string]any{"type": "object"},
+		}}},
+		Options: map[string]any{OptTemperature: 0.2, OptMaxTokens: 1000},
+	})
+
+	system := body["system"].([]map[string]any)
+	if len(system) != 3 || system[0]["text"] != "stable part" || system[1]["cachePoint"] == nil || system[2]["text"] != "dynamic part" {
+		t.Errorf("system = %#v", system)
+	}
+
+	msgs := body["messages"].([]map[string]any)
+	if len(msgs) != 3 {
+		t.Fatalf("messages = %d, want 3 (tool results merged into one user turn)", len(msgs))
+	}
+	userBlocks := msgs[0]["content"].([]any)
+	img := userBlocks[0].(map[string]any)["image"].(map[string]any)
+	if img["format"] != "png" {
+		t.Errorf("image block = %#v", img)
+	}
+	results := msgs[2]["content"].([]any)
+	if len(results) != 2 {
+		t.Fatalf("tool results = %#v", results)
+	}
+	if r := results[1].(map[string]any)["toolResult"].(map[string]any); r["status"] != "error" || r["toolUseId"] != "t2" {
+		t.Errorf("error tool result = %#v", r)
+	}
+
+	tools := body["toolConfig"].(map[string]any)["tools"].([]map[string]any)
+	if len(tools) != 2 || tools[1]["cachePoint"] == nil {
+		t.Errorf("tools should end with a cachePoint: %#v", tools)
+	}
+	inference := body["inferenceConfig"].(map[string]any)
+	if inference["maxTokens"] != 1000 || inference["temperature"] != 0.2 {
+		t.Errorf("inferenceConfig = %#v", inference)
+	}
+}
+
+func TestBedrockBuildRequestBody_NonClaudeSkipsExtras(t *testing.T) {
+	p := newTestBedrockProvider()
+	body := p.buildRequestBody("mistral.mistral-large-2407-v1:0", ChatRequest{
+		Messages: []Message{{Role: "system", Content: "sys"}, {Role: "user", Content: "hi"}},
+		Options:  map[string]any{OptThinkingLevel: "high"},
+	})
+	if system := body["system"].([]map[string]any); len(system) != 1 {
+		t.Errorf("non-caching model must not get cachePoint: %#v", system)
+	}
+	if _, ok := body["additionalModelRequestFields"]; ok {
+		t.Error("thinking must only be sent to Claude models")
+	}
+}
+
+func TestBedrockBuildRequestBody_Thinking(t *testing.T) {
+	p := newTestBedrockProvider()
+	body := p.buildRequestBody(BedrockDefaultModel, ChatRequest{
+		Messages: []Message{{Role: "user", Content: "hi"}},
+		Options:  map[string]any{OptThinkingLevel: "medium", OptTemperature: 0.5},
+	})
+	extra := body["additionalModelRequestFields"].(map[string]any)
+	thinking := extra["thinking"].(map[string]any)
+	if thinking["type"] != "enabled" || thinking["budget_tokens"] != 10000 {
+		t.Errorf("thinking = %#v", thinking)
+	}
+	inference := body["inferenceConfig"].(map[string]any)
+	if _, ok := inference["temperature"]; ok {
+		t.Error("temperature must be dropped with thinking enabled")
+	}
+	if inference["maxTokens"] != 10000+8192 {
+		t.Errorf("maxTokens = %v", inference["maxTokens"])
+	}
+}
+
+func TestBedrockBuildRequestBody_ResponseFormatForcesTool(t *testing.T) {
+	p := newTestBedrockProvider()
+	body := p.buildRequestBody(BedrockDefaultModel, ChatRequest{
+		Messages:       []Message{{Role: "user", Content: "hi"}},
+		Options:        map[string]any{OptThinkingLevel: "high"},
+		ResponseFormat: NewJSONSchemaFormat("person", testPersonSchema),
+	})
+	tc := body["toolConfig"].(map[string]any)
+	choice := tc["toolChoice"].(map[string]any)["tool"].(map[string]any)
+	if choice["name"] != "person" {
+		t.Errorf("toolChoice = %#v", tc["toolChoice"])
+	}
+	if _, ok := body["additionalModelRequestFields"]; ok {
+		t.Error("thinking must be disabled when tool choice is forced")
+	}
+}
+
+func TestBedrockBuildRequestBody_RawAssistantPassback(t *testing.T) {
+	p := newTestBedrockProvider()
+	raw := json.RawMessage(`[{"reasoningContent":{"reasoningText":{"text":"hm","signature":"sig"}}},{"toolUse":{"toolUseId":"t1","name":"a","input":{}}}]`)
+	body := p.buildRequestBody(BedrockDefaultModel, ChatRequest{
+		Messages: []Message{
+			{Role: "user", Content: "hi"},
+			{Role: "assistant", RawAssistantContent: raw, ToolCalls: []ToolCall{{ID: "t1", Name: "a"}}},
+		},
+	})
+	data, _ := json.Marshal(body["messages"])
+	var msgs []struct {
+		Content []map[string]json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &msgs); err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 || len(msgs[1].Content) != 2 || msgs[1].Content[0]["reasoningContent"] == nil {
+		t.Errorf("assistant passback = %s", data)
+	}
+}
+
+func TestBedrockClaudeBaseModel(t *testing.T) {
+	tests := map[string]string{
+		"us.anthropic.claude-sonnet-4-5-20250929-v1:0": "claude-sonnet-4-5-20250929",
+		"anthropic.claude-3-haiku-20240307-v1:0":       "claude-3-haiku-20240307",
+		"amazon.nova-pro-v1:0":                         "",
+	}
+	for in, want := range tests {
+		if got := bedrockClaudeBaseModel(in); got != want {
+			t.Errorf("bedrockClaudeBaseModel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBedrockEscapeModelID(t *testing.T) {
+	got := bedrockEscapeModelID("arn:aws:bedrock:us-east-1:123:inference-profile/us.anthropic.claude-v1:0")
+	want := "arn%3Aaws%3Abedrock%3Aus-east-1%3A123%3Ainference-profile%2Fus.anthropic.claude-v1%3A0"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestBedrockConfigValidation(t *testing.T) {
+	for _, region := range []string{"us-east-1", "eu-central-1", "us-gov-west-1", "ap-southeast-2"} {
+		if err := validateBedrockRegion(region); err != nil {
+			t.Errorf("region %q: %v", region, err)
+		}
+	}
+	for _, region := range []string{"", "us-east", "evil.com/x", "US-EAST-1"} {
+		if err := validateBedrockRegion(region); err == nil {
+			t.Errorf("region %q should be rejected", region)
+		}
+	}
+	if err := validateBedrockAPIBaseOverride("https://vpce-123.bedrock-runtime.us-east-1.vpce.amazonaws.com"); err != nil {
+		t.Errorf("VPC endpoint rejected: %v", err)
+	}
+	if err := validateBedrockAPIBaseOverride("https://attacker.example.com"); err == nil {
+		t.Error("non-AWS host should be rejected")
+	}
+}
+
+func TestBedrockAdapter(t *testing.T) {
+	adapter, err := DefaultAdapterRegistry().Get("bedrock", ProviderConfig{
+		APIKey:    "bedrock-key",
+		ExtraOpts: map[string]any{"region": "eu-west-1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ba := adapter.(*BedrockAdapter)
+	if got := ba.Endpoint(ChatRequest{Model: "amazon.nova-pro-v1:0"}, true); got !=
+		"https://bedrock-runtime.eu-west-1.amazonaws.com/model/amazon.nova-pro-v1%3A0/converse-stream" {
+		t.Errorf("Endpoint = %q", got)
+	}
+	_, headers, err := adapter.ToRequest(ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertHeader(t, headers, "Authorization", "Bearer bedrock-key")
+
+	chunk, _ := adapter.FromStreamChunk([]byte(`{"contentBlockIndex":0,"delta":{"text":"Hi"},"p":"abc"}`))
+	if chunk == nil || chunk.Content != "Hi" {
+		t.Errorf("text chunk = %#v", chunk)
+	}
+	chunk, _ = adapter.FromStreamChunk([]byte(`{"stopReason":"end_turn"}`))
+	if chunk == nil || !chunk.Done {
+		t.Errorf("stop chunk = %#v", chunk)
+	}
+}
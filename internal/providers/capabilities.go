@@ -12,6 +12,7 @@ type ProviderCapabilities struct {
 	Vision           bool   // supports image inputs
 	CacheControl     bool   // supports cache_control blocks (Anthropic)
 	ImageGeneration  bool   // supports native image_generation tool (Codex/OpenAI Responses API)
+	StructuredOutput bool   // enforces ChatRequest.ResponseFormat natively
 	MaxContextWindow int    // default context window for default model
 	TokenizerID      string // for tokencount package mapping
 }
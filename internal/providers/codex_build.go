@@ -140,6 +140,11 @@ func (p *CodexProvider) buildRequestBody(req ChatRequest, stream bool) map[strin
 		body["reasoning"] = map[string]any{"effort": level}
 	}
 
+	// Structured output — Responses API carries the schema under text.format.
+	if rf := req.ResponseFormat; rf != nil {
+		body["text"] = map[string]any{"format": rf.responsesTextFormat()}
+	}
+
 	return body
 }
 
@@ -105,6 +105,33 @@ func (t *CooldownTracker) RecordFailure(key string, reason FailoverReason) {
 	entry.cooldownUntil = now.Add(duration)
 }
 
+// RecordFailureFor is RecordFailure with a minimum cooldown, e.g. from a
+// Retry-After header. Zero minimum behaves like RecordFailure.
+func (t *CooldownTracker) RecordFailureFor(key string, reason FailoverReason, minimum time.Duration) {
+	t.RecordFailure(key, reason)
+	if minimum <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if entry, ok := t.entries[key]; ok {
+		if until := t.nowFn().Add(minimum); until.After(entry.cooldownUntil) {
+			entry.cooldownUntil = until
+		}
+	}
+}
+
+// CooldownUntil returns when the key's active cooldown ends (zero if none).
+func (t *CooldownTracker) CooldownUntil(key string) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.entries[key]
+	if !ok || !t.nowFn().Before(entry.cooldownUntil) {
+		return time.Time{}
+	}
+	return entry.cooldownUntil
+}
+
 // IsAvailable returns true if the key is not in active cooldown.
 func (t *CooldownTracker) IsAvailable(key string) bool {
 	t.mu.Lock()
@@ -486,3 +486,24 @@ func TestUnknownFailoverReason(t *testing.T) {
 		t.Error("should be available after default 30s cooldown")
 	}
 }
+
+func TestRecordFailureFor_RetryAfterExtendsCooldown(t *testing.T) {
+	tracker := NewCooldownTracker(512)
+	now := time.Now()
+	tracker.nowFn = func() time.Time { return now }
+
+	tracker.RecordFailureFor("k", FailoverRateLimit, 2*time.Minute)
+	if got := tracker.CooldownUntil("k"); !got.Equal(now.Add(2 * time.Minute)) {
+		t.Errorf("CooldownUntil = %v, want Retry-After based %v", got, now.Add(2*time.Minute))
+	}
+
+	// A shorter minimum never shortens the reason's default cooldown.
+	tracker.RecordFailureFor("k2", FailoverRateLimit, time.Second)
+	if got := tracker.CooldownUntil("k2"); !got.Equal(now.Add(cooldownDurations[FailoverRateLimit])) {
+		t.Errorf("CooldownUntil = %v, want default rate-limit cooldown", got)
+	}
+
+	if got := tracker.CooldownUntil("unknown"); !got.IsZero() {
+		t.Errorf("CooldownUntil for unknown key = %v, want zero", got)
+	}
+}
@@ -61,6 +61,7 @@ func (p *DashScopeProvider) Capabilities() ProviderCapabilities {
 		Thinking:         true,
 		Vision:           true,
 		CacheControl:     false,
+		StructuredOutput: true,
 		MaxContextWindow: 128_000,
 		TokenizerID:      "cl100k_base",
 	}
@@ -0,0 +1,470 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Google Gemini (generativelanguage API) constants. Like the Vertex constants,
+// these live in providers (not store) to keep the providers package free of a
+// store import.
+const (
+	// GeminiDefaultModel is used when neither the request nor the provider sets a model.
+	GeminiDefaultModel = "gemini-2.5-flash"
+
+	// GeminiDefaultAPIBase is the native generativelanguage endpoint (no /openai suffix).
+	GeminiDefaultAPIBase = "https://generativelanguage.googleapis.com/v1beta"
+
+	// ProviderTypeGeminiNative mirrors store.ProviderGeminiNative; kept in sync by convention.
+	ProviderTypeGeminiNative = "gemini_native"
+)
+
+// NormalizeGeminiAPIBase converts a stored api_base into the native endpoint.
+// Rows created before the native adapter point at the OpenAI-compat shim
+// (".../v1beta/openai"); the suffix is stripped so both forms keep working.
+func NormalizeGeminiAPIBase(base string) string {
+	base = strings.TrimRight(strings.TrimSpace(base), "/")
+	base = strings.TrimSuffix(base, "/openai")
+	if base == "" {
+		return GeminiDefaultAPIBase
+	}
+	return base
+}
+
+// GeminiProvider implements Provider against the native Gemini
+// generateContent / streamGenerateContent API. Unlike the OpenAI-compat shim
+// it preserves thought signatures, grounding metadata, cachedContent and
+// native multimodal parts (PDF, audio, video).
+type GeminiProvider struct {
+	name         string
+	apiKey       string
+	apiBase      string
+	defaultModel string
+	client       *http.Client
+	retryConfig  RetryConfig
+	keyPool      *APIKeyPool // nil = single API key
+
+	// readFile loads MediaRef paths for native multimodal parts (os.ReadFile; swapped in tests).
+	readFile func(string) ([]byte, error)
+}
+
+// NewGeminiProvider creates a native Gemini provider. apiBase may be empty
+// (GeminiDefaultAPIBase) or the legacy OpenAI-compat base.
+func NewGeminiProvider(name, apiKey, apiBase, defaultModel string) *GeminiProvider {
+	if name == "" {
+		name = "gemini"
+	}
+	if defaultModel == "" {
+		defaultModel = GeminiDefaultModel
+	}
+	return &GeminiProvider{
+		name:         name,
+		apiKey:       apiKey,
+		apiBase:      NormalizeGeminiAPIBase(apiBase),
+		defaultModel: defaultModel,
+		client:       NewDefaultHTTPClient(),
+		retryConfig:  DefaultRetryConfig(),
+		readFile:     os.ReadFile,
+	}
+}
+
+// WithHTTPClient replaces the HTTP client (tests, custom proxies).
+func (p *GeminiProvider) WithHTTPClient(c *http.Client) *GeminiProvider {
+	if c != nil {
+		p.client = c
+	}
+	return p
+}
+
+func (p *GeminiProvider) setKeyPool(pool *APIKeyPool) {
+	p.keyPool = pool
+	p.client = pool.WrapClient(p.client, "x-goog-api-key", "")
+}
+
+// KeyPool returns the API key pool, or nil for a single key.
+func (p *GeminiProvider) KeyPool() *APIKeyPool { return p.keyPool }
+
+// WithRetryConfig overrides the retry policy.
+func (p *GeminiProvider) WithRetryConfig(cfg RetryConfig) *GeminiProvider {
+	p.retryConfig = cfg
+	return p
+}
+
+func (p *GeminiProvider) Name() string           { return p.name }
+func (p *GeminiProvider) DefaultModel() string   { return p.defaultModel }
+func (p *GeminiProvider) SupportsThinking() bool { return true }
+
+// APIKey and APIBase expose credentials to media tools (create_image,
+// create_video, read_document), which call other native Gemini endpoints.
+func (p *GeminiProvider) APIKey() string  { return p.apiKey }
+func (p *GeminiProvider) APIBase() string { return p.apiBase }
+
+// ProviderType reports the DB provider_type for media routing.
+func (p *GeminiProvider) ProviderType() string { return ProviderTypeGeminiNative }
+
+// Capabilities implements CapabilitiesAware. Context caching is implicit (or
+// explicit via OptCachedContent), so CacheControl blocks are not used.
+func (p *GeminiProvider) Capabilities() ProviderCapabilities {
+	return ProviderCapabilities{
+		Streaming:        true,
+		ToolCalling:      true,
+		StreamWithTools:  true,
+		Thinking:         true,
+		Vision:           true,
+		CacheControl:     false,
+		StructuredOutput: true,
+		MaxContextWindow: 1_048_576,
+		TokenizerID:      "cl100k_base",
+	}
+}
+
+func (p *GeminiProvider) resolveModel(model string) string {
+	if model == "" {
+		model = p.defaultModel
+	}
+	// Model IDs from the models API carry a "models/" prefix; the path adds its own.
+	return strings.TrimPrefix(model, "models/")
+}
+
+func (p *GeminiProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	model := p.resolveModel(req.Model)
+	body := p.buildRequestBody(model, req)
+
+	resp, err := RetryDo(ctx, p.retryConfig, func() (*ChatResponse, error) {
+		respBody, err := p.doRequest(ctx, model, false, body)
+		if err != nil {
+			return nil, err
+		}
+		defer respBody.Close()
+
+		var parsed geminiResponse
+		if err := json.NewDecoder(respBody).Decode(&parsed); err != nil {
+			return nil, fmt.Errorf("%s: decode response: %w", p.name, err)
+		}
+		return parseGeminiResponse(&parsed), nil
+	})
+	if resp != nil {
+		if strip, _ := req.Options[OptStripThinking].(bool); strip {
+			resp.Thinking = ""
+		}
+	}
+	return resp, err
+}
+
+// endpoint returns the generateContent (or SSE streamGenerateContent) URL.
+func (p *GeminiProvider) endpoint(model string, stream bool) string {
+	if stream {
+		return p.apiBase + "/models/" + url.PathEscape(model) + ":streamGenerateContent?alt=sse"
+	}
+	return p.apiBase + "/models/" + url.PathEscape(model) + ":generateContent"
+}
+
+// doRequest POSTs body and returns the response body on 200. The API key goes
+// in the x-goog-api-key header rather than the query string so it never ends
+// up in proxy or error logs.
+func (p *GeminiProvider) doRequest(ctx context.Context, model string, stream bool, body any) (io.ReadCloser, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: marshal request: %w", p.name, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.endpoint(model, stream), bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: create request: %w", p.name, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-goog-api-key", p.apiKey)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s: request failed: %w", p.name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		retryAfter := ParseRetryAfter(resp.Header.Get("Retry-After"))
+		if retryAfter == 0 {
+			retryAfter = geminiRetryDelay(respBody)
+		}
+		return nil, &HTTPError{
+			Status:     resp.StatusCode,
+			Body:       fmt.Sprintf("%s: %s", p.name, geminiErrorMessage(respBody)),
+			RetryAfter: retryAfter,
+		}
+	}
+	return resp.Body, nil
+}
+
+// geminiErrorBody is the google.rpc.Status error envelope.
+type geminiErrorBody struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			Type       string `json:"@type"`
+			RetryDelay string `json:"retryDelay"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// geminiErrorMessage formats {"error":{"status","message"}} as "STATUS: message".
+func geminiErrorMessage(body []byte) string {
+	var parsed geminiErrorBody
+	if json.Unmarshal(body, &parsed) != nil || parsed.Error.Message == "" {
+		return string(body)
+	}
+	if parsed.Error.Status == "" {
+		return parsed.Error.Message
+	}
+	return parsed.Error.Status + ": " + parsed.Error.Message
+}
+
+// geminiRetryDelay extracts google.rpc.RetryInfo.retryDelay (e.g. "31s"),
+// which Gemini sends on 429 instead of a Retry-After header.
+func geminiRetryDelay(body []byte) time.Duration {
+	var parsed geminiErrorBody
+	if json.Unmarshal(body, &parsed) != nil {
+		return 0
+	}
+	for _, d := range parsed.Error.Details {
+		if d.RetryDelay == "" {
+			continue
+		}
+		if delay, err := time.ParseDuration(d.RetryDelay); err == nil && delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
+
+// --- generateContent API types (internal) ---
+
+// geminiResponse is a GenerateContentResponse (or one SSE chunk of it).
+// Parts stay raw so fields GoClaw doesn't model (executableCode, fileData,
+// ...) survive tool-loop passback untouched.
+type geminiResponse struct {
+	Candidates    []geminiCandidate `json:"candidates"`
+	UsageMetadata *geminiUsage      `json:"usageMetadata,omitempty"`
+}
+
+type geminiCandidate struct {
+	Content struct {
+		Parts []json.RawMessage `json:"parts"`
+	} `json:"content"`
+	FinishReason      string             `json:"finishReason,omitempty"`
+	GroundingMetadata *GroundingMetadata `json:"groundingMetadata,omitempty"`
+}
+
+// geminiPart is the typed view of a response part (only the fields GoClaw reads).
+type geminiPart struct {
+	Text             string              `json:"text,omitempty"`
+	Thought          bool                `json:"thought,omitempty"`
+	ThoughtSignature string              `json:"thoughtSignature,omitempty"`
+	FunctionCall     *geminiFunctionCall `json:"functionCall,omitempty"`
+	InlineData       *geminiBlob         `json:"inlineData,omitempty"`
+}
+
+type geminiFunctionCall struct {
+	ID   string         `json:"id,omitempty"`
+	Name string         `json:"name"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+type geminiBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+type geminiUsage struct {
+	PromptTokenCount        int `json:"promptTokenCount"`
+	CandidatesTokenCount    int `json:"candidatesTokenCount"`
+	TotalTokenCount         int `json:"totalTokenCount"`
+	CachedContentTokenCount int `json:"cachedContentTokenCount,omitempty"`
+	ThoughtsTokenCount      int `json:"thoughtsTokenCount,omitempty"`
+	ToolUsePromptTokenCount int `json:"toolUsePromptTokenCount,omitempty"`
+}
+
+// toUsage maps usageMetadata to Usage.
+//
+// promptTokenCount includes cached tokens, while tracing.CalculateCost bills
+// PromptTokens at the full input rate and CacheReadTokens at the cache rate,
+// so the cached share is moved out of PromptTokens (Anthropic convention).
+// candidatesTokenCount excludes thoughts; CompletionTokens includes them so
+// ThinkingTokens stays a sub-count, as CalculateCost expects.
+func (u *geminiUsage) toUsage() *Usage {
+	if u == nil {
+		return nil
+	}
+	prompt := u.PromptTokenCount + u.ToolUsePromptTokenCount - u.CachedContentTokenCount
+	if prompt < 0 {
+		prompt = 0
+	}
+	completion := u.CandidatesTokenCount + u.ThoughtsTokenCount
+	total := u.TotalTokenCount
+	if total == 0 {
+		total = u.PromptTokenCount + u.ToolUsePromptTokenCount + completion
+	}
+	return &Usage{
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		TotalTokens:      total,
+		CacheReadTokens:  u.CachedContentTokenCount,
+		ThinkingTokens:   u.ThoughtsTokenCount,
+		RequestCount:     1,
+	}
+}
+
+// GroundingMetadata is the Google Search grounding attached to a Gemini
+// candidate: the queries the model ran, the web sources and which answer
+// spans each source supports. Populated only by the native Gemini provider.
+type GroundingMetadata struct {
+	WebSearchQueries  []string           `json:"webSearchQueries,omitempty"`
+	GroundingChunks   []GroundingChunk   `json:"groundingChunks,omitempty"`
+	GroundingSupports []GroundingSupport `json:"groundingSupports,omitempty"`
+	SearchEntryPoint  *struct {
+		RenderedContent string `json:"renderedContent,omitempty"`
+	} `json:"searchEntryPoint,omitempty"`
+}
+
+// GroundingChunk is one grounding source.
+type GroundingChunk struct {
+	Web *struct {
+		URI   string `json:"uri"`
+		Title string `json:"title,omitempty"`
+	} `json:"web,omitempty"`
+}
+
+// GroundingSupport links a span of the answer to grounding chunks.
+type GroundingSupport struct {
+	Segment struct {
+		StartIndex int    `json:"startIndex,omitempty"`
+		EndIndex   int    `json:"endIndex,omitempty"`
+		Text       string `json:"text,omitempty"`
+	} `json:"segment"`
+	GroundingChunkIndices []int `json:"groundingChunkIndices,omitempty"`
+}
+
+func geminiFinishReason(reason string, hasToolCalls bool) string {
+	if hasToolCalls {
+		return "tool_calls"
+	}
+	switch reason {
+	case "MAX_TOKENS":
+		return "length"
+	default:
+		return "stop"
+	}
+}
+
+// geminiToolCallID returns the function call's ID, generating one when the
+// API omits it (the Developer API usually does) so tool results can be matched.
+func geminiToolCallID(id string) string {
+	if id != "" {
+		return id
+	}
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "call_" + hex.EncodeToString(b)
+}
+
+// geminiPartAccumulator folds response parts (whole or streamed) into a
+// ChatResponse, keeping the model's parts for tool-loop passback.
+type geminiPartAccumulator struct {
+	result   *ChatResponse
+	rawParts []json.RawMessage
+}
+
+// add appends one part and returns the visible text and thinking it carried.
+func (a *geminiPartAccumulator) add(raw json.RawMessage, part geminiPart) (content, thinking string) {
+	switch {
+	case part.FunctionCall != nil:
+		tc := ToolCall{
+			ID:        geminiToolCallID(part.FunctionCall.ID),
+			Name:      strings.TrimSpace(part.FunctionCall.Name),
+			Arguments: part.FunctionCall.Args,
+		}
+		if tc.Arguments == nil {
+			tc.Arguments = map[string]any{}
+		}
+		if part.ThoughtSignature != "" {
+			tc.Metadata = map[string]string{"thought_signature": part.ThoughtSignature}
+		}
+		a.result.ToolCalls = append(a.result.ToolCalls, tc)
+		// Pin the generated ID into the raw part so passback matches the tool result.
+		if part.FunctionCall.ID == "" {
+			part.FunctionCall.ID = tc.ID
+			if b, err := json.Marshal(part); err == nil {
+				raw = b
+			}
+		}
+	case part.Thought:
+		thinking = part.Text
+		a.result.Thinking += thinking
+	case part.InlineData != nil && strings.HasPrefix(part.InlineData.MimeType, "image/"):
+		a.result.Images = append(a.result.Images, ImageContent{
+			MimeType: part.InlineData.MimeType,
+			Data:     part.InlineData.Data,
+		})
+	default:
+		content = part.Text
+		a.result.Content += content
+	}
+	if part.ThoughtSignature != "" {
+		a.result.ThinkingSignature = part.ThoughtSignature
+	}
+	a.rawParts = append(a.rawParts, raw)
+	return content, thinking
+}
+
+// finish sets the finish reason and RawAssistantContent (the model's parts,
+// including thought signatures on text parts, for tool passback).
+func (a *geminiPartAccumulator) finish(finishReason string) {
+	a.result.FinishReason = geminiFinishReason(finishReason, len(a.result.ToolCalls) > 0)
+	if len(a.result.ToolCalls) > 0 && len(a.rawParts) > 0 {
+		if b, err := json.Marshal(a.rawParts); err == nil {
+			a.result.RawAssistantContent = b
+		}
+	}
+}
+
+// applyGeminiGrounding attaches grounding metadata and counts billed search queries.
+func applyGeminiGrounding(result *ChatResponse, gm *GroundingMetadata) {
+	if gm == nil {
+		return
+	}
+	result.Grounding = gm
+	if result.Usage != nil && len(gm.WebSearchQueries) > 0 {
+		result.Usage.WebSearchCount = len(gm.WebSearchQueries)
+	}
+}
+
+func parseGeminiResponse(resp *geminiResponse) *ChatResponse {
+	acc := geminiPartAccumulator{result: &ChatResponse{}}
+	acc.result.Usage = resp.UsageMetadata.toUsage()
+	if len(resp.Candidates) == 0 {
+		acc.finish("")
+		return acc.result
+	}
+	cand := resp.Candidates[0]
+	for _, raw := range cand.Content.Parts {
+		var part geminiPart
+		if json.Unmarshal(raw, &part) != nil {
+			continue
+		}
+		acc.add(raw, part)
+	}
+	acc.finish(cand.FinishReason)
+	applyGeminiGrounding(acc.result, cand.GroundingMetadata)
+	return acc.result
+}
@@ -0,0 +1,350 @@
+package providers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// geminiSkipSignature is Google's documented placeholder for function calls
+// that carry no thought signature (history from another provider, or older
+// sessions). Gemini 3 rejects unsigned function calls in the current turn.
+const geminiSkipSignature = "skip_thought_signature_validator"
+
+// geminiInlineMediaBudget caps the raw bytes of MediaRef files inlined per
+// request. generateContent rejects bodies over 20MB, and base64 adds a third.
+const geminiInlineMediaBudget = 14 << 20
+
+// geminiNativeTools maps native ToolDefinition types to Gemini tool objects.
+var geminiNativeTools = map[string]string{
+	"google_search":  "googleSearch",
+	"url_context":    "urlContext",
+	"code_execution": "codeExecution",
+}
+
+// geminiInlineMime reports whether Gemini accepts the MIME type as an inline
+// document/audio/video part. Images are excluded: the agent loop already
+// loads those into Message.Images (or routes them to read_image).
+func geminiInlineMime(mime string) bool {
+	mime = strings.ToLower(mime)
+	return mime == "application/pdf" || mime == "text/plain" ||
+		strings.HasPrefix(mime, "audio/") || strings.HasPrefix(mime, "video/")
+}
+
+// loadMediaRefs reads non-image MediaRefs on user messages, newest first,
+// until geminiInlineMediaBudget is spent. Returns base64 data keyed by ref ID.
+// Unreadable or oversized refs are skipped; the agent's read_* tools still
+// cover them through the media tags in the message text.
+func (p *GeminiProvider) loadMediaRefs(msgs []Message) map[string]string {
+	if p.readFile == nil {
+		return nil
+	}
+	out := make(map[string]string)
+	budget := geminiInlineMediaBudget
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role != "user" {
+			continue
+		}
+		for _, ref := range msgs[i].MediaRefs {
+			if ref.Kind == "image" || ref.Path == "" || !geminiInlineMime(ref.MimeType) {
+				continue
+			}
+			if _, seen := out[ref.ID]; seen {
+				continue
+			}
+			data, err := p.readFile(ref.Path)
+			if err != nil || len(data) == 0 || len(data) > budget {
+				continue
+			}
+			budget -= len(data)
+			out[ref.ID] = base64.StdEncoding.EncodeToString(data)
+		}
+	}
+	return out
+}
+
+// geminiMediaPart returns an inlineData part for base64 data, or a fileData
+// part for a URL (Files API URIs, gs:// and YouTube links).
+func geminiMediaPart(mime, data, uri string) map[string]any {
+	if data != "" {
+		return map[string]any{"inlineData": map[string]any{"mimeType": mime, "data": data}}
+	}
+	if uri != "" {
+		part := map[string]any{"fileUri": uri}
+		if mime != "" {
+			part["mimeType"] = mime
+		}
+		return map[string]any{"fileData": part}
+	}
+	return nil
+}
+
+// geminiRawParts returns the passback parts stored in RawAssistantContent by
+// this provider, or nil when the raw content came from another provider
+// (e.g. Anthropic blocks after a mid-loop failover).
+func geminiRawParts(raw json.RawMessage) []any {
+	var parts []map[string]json.RawMessage
+	if json.Unmarshal(raw, &parts) != nil || len(parts) == 0 {
+		return nil
+	}
+	hasCall := false
+	for _, part := range parts {
+		if _, foreign := part["type"]; foreign {
+			return nil
+		}
+		if _, ok := part["functionCall"]; ok {
+			hasCall = true
+		}
+	}
+	if !hasCall {
+		return nil
+	}
+	out := make([]any, len(parts))
+	for i, part := range parts {
+		out[i] = part
+	}
+	return out
+}
+
+// buildRequestBody converts a ChatRequest into a generateContent body.
+// The model goes in the URL path, not the body.
+func (p *GeminiProvider) buildRequestBody(model string, req ChatRequest) map[string]any {
+	toolNames := buildToolNameIndex(req.Messages)
+	media := p.loadMediaRefs(req.Messages)
+
+	var system []string
+	var contents []map[string]any
+
+	// Parallel function responses must share one content, so consecutive
+	// same-role turns are merged (mirrors the Bedrock Converse mapping).
+	appendParts := func(role string, parts []any) {
+		if len(parts) == 0 {
+			return
+		}
+		if n := len(contents); n > 0 && contents[n-1]["role"] == role {
+			contents[n-1]["parts"] = append(contents[n-1]["parts"].([]any), parts...)
+			return
+		}
+		contents = append(contents, map[string]any{"role": role, "parts": parts})
+	}
+
+	for _, msg := range req.Messages {
+		switch msg.Role {
+		case "system":
+			if text := strings.TrimSpace(strings.ReplaceAll(msg.Content, CacheBoundaryMarker, "")); text != "" {
+				system = append(system, text)
+			}
+
+		case "user":
+			var parts []any
+			for _, img := range msg.Images {
+				if part := geminiMediaPart(img.MimeType, img.Data, img.URL); part != nil {
+					parts = append(parts, part)
+				}
+			}
+			for _, vid := range msg.Videos {
+				if part := geminiMediaPart(vid.MimeType, vid.Data, vid.URL); part != nil {
+					parts = append(parts, part)
+				}
+			}
+			for _, ref := range msg.MediaRefs {
+				if data, ok := media[ref.ID]; ok {
+					parts = append(parts, geminiMediaPart(ref.MimeType, data, ""))
+				}
+			}
+			if msg.Content != "" {
+				parts = append(parts, map[string]any{"text": msg.Content})
+			}
+			appendParts("user", parts)
+
+		case "assistant":
+			// Raw parts keep thought signatures on text parts, not just on calls.
+			if msg.RawAssistantContent != nil {
+				if parts := geminiRawParts(msg.RawAssistantContent); parts != nil {
+					appendParts("model", parts)
+					continue
+				}
+			}
+			var parts []any
+			if msg.Content != "" {
+				parts = append(parts, map[string]any{"text": msg.Content})
+			}
+			for i, tc := range msg.ToolCalls {
+				args := tc.Arguments
+				if args == nil {
+					args = map[string]any{}
+				}
+				part := map[string]any{
+					"functionCall": map[string]any{"id": tc.ID, "name": tc.Name, "args": args},
+				}
+				sig := tc.Metadata["thought_signature"]
+				if sig == "" {
+					sig = tc.Metadata["thoughtSignature"]
+				}
+				// Only the first call of a step is signed; parallel calls carry none.
+				if sig == "" && i == 0 {
+					sig = geminiSkipSignature
+				}
+				if sig != "" {
+					part["thoughtSignature"] = sig
+				}
+				parts = append(parts, part)
+			}
+			appendParts("model", parts)
+
+		case "tool":
+			key := "output"
+			if msg.IsError {
+				key = "error"
+			}
+			appendParts("user", []any{map[string]any{
+				"functionResponse": map[string]any{
+					"id":       msg.ToolCallID,
+					"name":     toolNames[msg.ToolCallID],
+					"response": map[string]any{key: msg.Content},
+				},
+			}})
+		}
+	}
+
+	body := map[string]any{"contents": contents}
+
+	// An explicit context cache already holds the system instruction and tools;
+	// generateContent rejects requests that repeat them.
+	cachedContent, _ := req.Options[OptCachedContent].(string)
+	if cachedContent != "" {
+		body["cachedContent"] = cachedContent
+	} else {
+		if len(system) > 0 {
+			body["systemInstruction"] = map[string]any{
+				"parts": []any{map[string]any{"text": strings.Join(system, "\n\n")}},
+			}
+		}
+		if tools := geminiTools(req.Tools); len(tools) > 0 {
+			body["tools"] = tools
+			if tc := geminiToolConfig(req.Options[OptToolChoice]); tc != nil {
+				body["toolConfig"] = tc
+			}
+		}
+	}
+
+	gen := map[string]any{}
+	if v, ok := req.Options[OptMaxTokens]; ok {
+		gen["maxOutputTokens"] = v
+	}
+	if v, ok := req.Options[OptTemperature]; ok {
+		gen["temperature"] = v
+	}
+	if level, ok := req.Options[OptThinkingLevel].(string); ok && level != "" {
+		if tc := geminiThinkingConfig(model, level); tc != nil {
+			gen["thinkingConfig"] = tc
+		}
+	}
+	if rf := req.ResponseFormat; rf != nil {
+		gen["responseMimeType"] = "application/json"
+		if rf.HasSchema() {
+			gen["responseSchema"] = CleanSchemaForProvider("gemini", rf.Schema)
+		}
+	}
+	if len(gen) > 0 {
+		body["generationConfig"] = gen
+	}
+	return body
+}
+
+// geminiTools builds the tools array: one functionDeclarations entry plus
+// native tools (googleSearch grounding, urlContext, codeExecution).
+func geminiTools(defs []ToolDefinition) []map[string]any {
+	var decls []map[string]any
+	var tools []map[string]any
+	for _, t := range defs {
+		if native, ok := geminiNativeTools[t.Type]; ok {
+			tools = append(tools, map[string]any{native: map[string]any{}})
+			continue
+		}
+		if t.Function == nil {
+			continue
+		}
+		decl := map[string]any{"name": t.Function.Name, "description": t.Function.Description}
+		// Gemini rejects OBJECT schemas with no properties; omit them entirely.
+		if props, _ := t.Function.Parameters["properties"].(map[string]any); len(props) > 0 {
+			decl["parameters"] = CleanSchemaForProvider("gemini", t.Function.Parameters)
+		}
+		decls = append(decls, decl)
+	}
+	if len(decls) > 0 {
+		tools = append([]map[string]any{{"functionDeclarations": decls}}, tools...)
+	}
+	return tools
+}
+
+// geminiToolConfig maps OpenAI-style tool_choice values ("auto", "none",
+// "required", or {"type":"function","function":{"name":...}}) to
+// functionCallingConfig. Returns nil for the default (AUTO).
+func geminiToolConfig(choice any) map[string]any {
+	mode := ""
+	var allowed []string
+	switch v := choice.(type) {
+	case string:
+		switch v {
+		case "none":
+			mode = "NONE"
+		case "required", "any":
+			mode = "ANY"
+		}
+	case map[string]any:
+		if fn, ok := v["function"].(map[string]any); ok {
+			if name, _ := fn["name"].(string); name != "" {
+				mode = "ANY"
+				allowed = []string{name}
+			}
+		}
+	}
+	if mode == "" {
+		return nil
+	}
+	cfg := map[string]any{"mode": mode}
+	if len(allowed) > 0 {
+		cfg["allowedFunctionNames"] = allowed
+	}
+	return map[string]any{"functionCallingConfig": cfg}
+}
+
+// geminiThinkingConfig maps a thinking level to thinkingConfig. Gemini 3
+// takes a discrete thinkingLevel (same mapping as the OpenAI-compat path);
+// Gemini 2.5 takes a token budget. Older models don't think and get nil.
+func geminiThinkingConfig(model, level string) map[string]any {
+	m := strings.ToLower(model)
+	switch {
+	case strings.Contains(m, "gemini-1") || strings.Contains(m, "gemini-2.0"):
+		return nil
+	case strings.Contains(m, "gemini-2.5"):
+		budget := geminiThinkingBudget(m, level)
+		return map[string]any{"thinkingBudget": budget, "includeThoughts": budget != 0}
+	}
+	mapped, ok := mapGeminiReasoningEffort(level)
+	if !ok {
+		return nil
+	}
+	return map[string]any{"thinkingLevel": mapped, "includeThoughts": level != "off"}
+}
+
+// geminiThinkingBudget returns the Gemini 2.5 thinkingBudget for a level.
+// 2.5 Pro cannot disable thinking, so "off" becomes its minimum (128).
+func geminiThinkingBudget(model, level string) int {
+	switch level {
+	case "off":
+		if strings.Contains(model, "pro") {
+			return 128
+		}
+		return 0
+	case "low", "minimal":
+		return 1024
+	case "medium":
+		return 8192
+	case "high":
+		return 24576
+	default:
+		return -1 // dynamic
+	}
+}
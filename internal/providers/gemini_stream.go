@@ -0,0 +1,85 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ChatStream calls streamGenerateContent?alt=sse. Each SSE event is a full
+// GenerateContentResponse carrying only new parts; function calls always
+// arrive whole, and usageMetadata is cumulative (the last one wins).
+func (p *GeminiProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	model := p.resolveModel(req.Model)
+	stripThinking, _ := req.Options[OptStripThinking].(bool)
+	body := p.buildRequestBody(model, req)
+
+	// Retry only the connection phase; once streaming starts, no retry.
+	respBody, err := RetryDo(ctx, p.retryConfig, func() (io.ReadCloser, error) {
+		return p.doRequest(ctx, model, true, body)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Wrap respBody so ctx cancellation closes the socket, unblocking bufio.Scanner.
+	cb := NewCtxBody(ctx, respBody)
+	defer cb.Close()
+
+	acc := geminiPartAccumulator{result: &ChatResponse{}}
+	finishReason := ""
+	var grounding *GroundingMetadata
+
+	sse := NewSSEScanner(cb)
+	for sse.Next() {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var chunk geminiResponse
+		if err := json.Unmarshal([]byte(sse.Data()), &chunk); err != nil {
+			continue
+		}
+		if chunk.UsageMetadata != nil {
+			acc.result.Usage = chunk.UsageMetadata.toUsage()
+		}
+		if len(chunk.Candidates) == 0 {
+			continue
+		}
+		cand := chunk.Candidates[0]
+		if cand.FinishReason != "" {
+			finishReason = cand.FinishReason
+		}
+		if cand.GroundingMetadata != nil {
+			grounding = cand.GroundingMetadata
+		}
+		for _, raw := range cand.Content.Parts {
+			var part geminiPart
+			if json.Unmarshal(raw, &part) != nil {
+				continue
+			}
+			content, thinking := acc.add(raw, part)
+			if onChunk == nil {
+				continue
+			}
+			if thinking != "" && !stripThinking {
+				onChunk(StreamChunk{Thinking: thinking})
+			}
+			if content != "" {
+				onChunk(StreamChunk{Content: content})
+			}
+		}
+	}
+	if err := sse.Err(); err != nil {
+		return nil, fmt.Errorf("%s: stream read error: %w", p.name, err)
+	}
+
+	acc.finish(finishReason)
+	applyGeminiGrounding(acc.result, grounding)
+	if stripThinking {
+		acc.result.Thinking = ""
+	}
+	if onChunk != nil {
+		onChunk(StreamChunk{Done: true})
+	}
+	return acc.result, nil
+}
@@ -0,0 +1,306 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestGeminiServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request, body map[string]any)) (*GeminiProvider, *httptest.Server) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(raw, &body)
+		handler(w, r, body)
+	}))
+	t.Cleanup(srv.Close)
+	p := NewGeminiProvider("gemini", "test-key", srv.URL+"/v1beta/openai", "gemini-2.5-flash").
+		WithRetryConfig(RetryConfig{Attempts: 1})
+	return p, srv
+}
+
+const geminiToolCallResponse = `{
+  "candidates": [{
+    "content": {"role": "model", "parts": [
+      {"text": "Checking the forecast.", "thought": true},
+      {"text": "Let me check."},
+      {"functionCall": {"name": "get_weather", "args": {"city": "Hanoi"}}, "thoughtSignature": "sig-abc"}
+    ]},
+    "finishReason": "STOP",
+    "groundingMetadata": {
+      "webSearchQueries": ["hanoi weather"],
+      "groundingChunks": [{"web": {"uri": "https://example.com/w", "title": "Weather"}}]
+    }
+  }],
+  "usageMetadata": {"promptTokenCount": 1200, "candidatesTokenCount": 40, "cachedContentTokenCount": 1000, "thoughtsTokenCount": 25, "totalTokenCount": 1265}
+}`
+
+func TestGeminiProvider_ChatParsesNativeResponse(t *testing.T) {
+	p, _ := newTestGeminiServer(t, func(w http.ResponseWriter, r *http.Request, body map[string]any) {
+		if r.URL.Path != "/v1beta/models/gemini-2.5-flash:generateContent" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.Header.Get("x-goog-api-key"); got != "test-key" {
+			t.Errorf("x-goog-api-key = %q", got)
+		}
+		if r.URL.Query().Get("key") != "" {
+			t.Error("API key must not be sent in the query string")
+		}
+		_, _ = w.Write([]byte(geminiToolCallResponse))
+	})
+
+	resp, err := p.Chat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Weather in Hanoi?"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "Let me check." || resp.Thinking != "Checking the forecast." || resp.FinishReason != "tool_calls" {
+		t.Errorf("resp = %+v", resp)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Arguments["city"] != "Hanoi" ||
+		resp.ToolCalls[0].Metadata["thought_signature"] != "sig-abc" || resp.ToolCalls[0].ID == "" {
+		t.Fatalf("ToolCalls = %+v", resp.ToolCalls)
+	}
+	u := resp.Usage
+	if u.PromptTokens != 200 || u.CacheReadTokens != 1000 || u.CompletionTokens != 65 || u.ThinkingTokens != 25 || u.TotalTokens != 1265 {
+		t.Errorf("Usage = %+v", u)
+	}
+	if resp.Grounding == nil || resp.Grounding.GroundingChunks[0].Web.URI != "https://example.com/w" || u.WebSearchCount != 1 {
+		t.Errorf("Grounding = %+v, WebSearchCount = %d", resp.Grounding, u.WebSearchCount)
+	}
+
+	// The raw parts pin the generated call ID so passback matches the tool result.
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal(resp.RawAssistantContent, &raw); err != nil || len(raw) != 3 {
+		t.Fatalf("RawAssistantContent = %s", resp.RawAssistantContent)
+	}
+	if !strings.Contains(string(raw[2]["functionCall"]), resp.ToolCalls[0].ID) {
+		t.Errorf("raw functionCall = %s, want id %s", raw[2]["functionCall"], resp.ToolCalls[0].ID)
+	}
+}
+
+func TestGeminiProvider_ChatStream(t *testing.T) {
+	p, _ := newTestGeminiServer(t, func(w http.ResponseWriter, r *http.Request, body map[string]any) {
+		if !strings.HasSuffix(r.URL.Path, ":streamGenerateContent") || r.URL.Query().Get("alt") != "sse" {
+			t.Errorf("url = %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, ev := range []string{
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hmm","thought":true}]}}],"usageMetadata":{"promptTokenCount":10}}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hello "}]}}]}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"world","thoughtSignature":"sig-1"}]},"finishReason":"MAX_TOKENS"}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":2,"totalTokenCount":12}}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", ev)
+		}
+	})
+
+	var content, thinking strings.Builder
+	done := false
+	resp, err := p.ChatStream(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}}, func(c StreamChunk) {
+		content.WriteString(c.Content)
+		thinking.WriteString(c.Thinking)
+		done = done || c.Done
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content.String() != "Hello world" || thinking.String() != "Hmm" || !done {
+		t.Errorf("chunks: content=%q thinking=%q done=%v", content.String(), thinking.String(), done)
+	}
+	if resp.Content != "Hello world" || resp.FinishReason != "length" || resp.ThinkingSignature != "sig-1" {
+		t.Errorf("resp = %+v", resp)
+	}
+	if resp.Usage == nil || resp.Usage.CompletionTokens != 2 {
+		t.Errorf("Usage = %+v", resp.Usage)
+	}
+}
+
+func TestGeminiProvider_RetryDelayFromErrorBody(t *testing.T) {
+	p, _ := newTestGeminiServer(t, func(w http.ResponseWriter, r *http.Request, body map[string]any) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED",
+			"details":[{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":"31s"}]}}`))
+	})
+	_, err := p.Chat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("err = %v, want *HTTPError", err)
+	}
+	if httpErr.RetryAfter != 31*time.Second || !strings.Contains(httpErr.Body, "RESOURCE_EXHAUSTED: Quota exceeded") {
+		t.Errorf("HTTPError = %+v", httpErr)
+	}
+}
+
+func TestGeminiBuildRequestBody_MessagesAndMedia(t *testing.T) {
+	p := NewGeminiProvider("gemini", "k", "", "")
+	p.readFile = func(path string) ([]byte, error) {
+		if path == "/ws/report.pdf" {
+			return []byte("%PDF-1.7"), nil
+		}
+		return nil, errors.New("not found")
+	}
+	body := p.buildRequestBody("gemini-2.5-flash", ChatRequest{
+		Messages: []Message{
+			{Role: "system", Content: "stable" + CacheBoundaryMarker + "dynamic"},
+			{Role: "user", Content: "summarize", Images: []ImageContent{{MimeType: "image/png", Data: "iVBOR"}},
+				MediaRefs: []MediaRef{
+					{ID: "d1", Kind: "document", MimeType: "application/pdf", Path: "/ws/report.pdf"},
+					{ID: "d2", Kind: "document", MimeType: "application/pdf", Path: "/ws/missing.pdf"},
+				}},
+			{Role: "assistant", ToolCalls: []ToolCall{
+				{ID: "c1", Name: "a", Metadata: map[string]string{"thought_signature": "sig"}},
+				{ID: "c2", Name: "b"},
+			}},
+			{Role: "tool", ToolCallID: "c1", Content: "ok"},
+			{Role: "tool", ToolCallID: "c2", Content: "boom", IsError: true},
+			{Role: "assistant", ToolCalls: []ToolCall{{ID: "c3", Name: "a"}}},
+		},
+	})
+
+	sys := body["systemInstruction"].(map[string]any)["parts"].([]any)[0].(map[string]any)
+	if strings.Contains(sys["text"].(string), CacheBoundaryMarker) {
+		t.Errorf("system text kept the cache marker: %q", sys["text"])
+	}
+
+	contents := body["contents"].([]map[string]any)
+	if len(contents) != 4 {
+		t.Fatalf("contents = %d, want 4 (tool results merged)", len(contents))
+	}
+	userParts := contents[0]["parts"].([]any)
+	if len(userParts) != 3 {
+		t.Fatalf("user parts = %#v", userParts)
+	}
+	pdf := userParts[1].(map[string]any)["inlineData"].(map[string]any)
+	if pdf["mimeType"] != "application/pdf" || pdf["data"] != "JVBERi0xLjc=" {
+		t.Errorf("pdf part = %#v", pdf)
+	}
+
+	calls := contents[1]["parts"].([]any)
+	if contents[1]["role"] != "model" || calls[0].(map[string]any)["thoughtSignature"] != "sig" {
+		t.Errorf("model turn = %#v", contents[1])
+	}
+	if _, ok := calls[1].(map[string]any)["thoughtSignature"]; ok {
+		t.Error("parallel calls after the first must stay unsigned")
+	}
+	results := contents[2]["parts"].([]any)
+	errResp := results[1].(map[string]any)["functionResponse"].(map[string]any)
+	if errResp["name"] != "b" || errResp["response"].(map[string]any)["error"] != "boom" {
+		t.Errorf("error functionResponse = %#v", errResp)
+	}
+	unsigned := contents[3]["parts"].([]any)[0].(map[string]any)
+	if unsigned["thoughtSignature"] != geminiSkipSignature {
+		t.Errorf("unsigned call = %#v, want skip signature", unsigned)
+	}
+}
+
+func TestGeminiBuildRequestBody_ToolsAndConfig(t *testing.T) {
+	p := NewGeminiProvider("gemini", "k", "", "")
+	req := ChatRequest{
+		Messages: []Message{{Role: "system", Content: "sys"}, {Role: "user", Content: "hi"}},
+		Tools: []ToolDefinition{
+			{Type: "function", Function: &ToolFunctionSchema{Name: "a", Parameters: map[string]any{
+				"type": "object", "properties": map[string]any{"x": map[string]any{"type": "string"}},
+			}}},
+			{Type: "function", Function: &ToolFunctionSchema{Name: "noargs", Parameters: map[string]any{"type": "object"}}},
+			{Type: "google_search"},
+		},
+		Options: map[string]any{OptToolChoice: "required", OptThinkingLevel: "medium", OptMaxTokens: 512},
+	}
+
+	body := p.buildRequestBody("gemini-2.5-pro", req)
+	tools := body["tools"].([]map[string]any)
+	decls := tools[0]["functionDeclarations"].([]map[string]any)
+	if len(tools) != 2 || len(decls) != 2 || tools[1]["googleSearch"] == nil {
+		t.Fatalf("tools = %#v", tools)
+	}
+	if _, ok := decls[1]["parameters"]; ok {
+		t.Error("empty OBJECT schema must be omitted")
+	}
+	if mode := body["toolConfig"].(map[string]any)["functionCallingConfig"].(map[string]any)["mode"]; mode != "ANY" {
+		t.Errorf("mode = %v", mode)
+	}
+	gen := body["generationConfig"].(map[string]any)
+	if gen["maxOutputTokens"] != 512 || gen["thinkingConfig"].(map[string]any)["thinkingBudget"] != 8192 {
+		t.Errorf("generationConfig = %#v", gen)
+	}
+
+	// Gemini 3 takes a discrete level; "medium" maps to "high" like the compat path.
+	body = p.buildRequestBody("gemini-3-pro-preview", req)
+	if lvl := body["generationConfig"].(map[string]any)["thinkingConfig"].(map[string]any)["thinkingLevel"]; lvl != "high" {
+		t.Errorf("thinkingLevel = %v", lvl)
+	}
+
+	// An explicit cache already holds system + tools.
+	req.Options = map[string]any{OptCachedContent: "cachedContents/abc"}
+	req.ResponseFormat = NewJSONSchemaFormat("person", map[string]any{"type": "object", "properties": map[string]any{"name": map[string]any{"type": "string"}}})
+	body = p.buildRequestBody("gemini-2.5-flash", req)
+	if body["cachedContent"] != "cachedContents/abc" || body["systemInstruction"] != nil || body["tools"] != nil {
+		t.Errorf("cachedContent body = %#v", body)
+	}
+	gen = body["generationConfig"].(map[string]any)
+	if gen["responseMimeType"] != "application/json" || gen["responseSchema"] == nil {
+		t.Errorf("structured output = %#v", gen)
+	}
+}
+
+func TestGeminiBuildRequestBody_RawPassback(t *testing.T) {
+	p := NewGeminiProvider("gemini", "k", "", "")
+	native := json.RawMessage(`[{"text":"hi","thoughtSignature":"t-sig"},{"functionCall":{"id":"c1","name":"a","args":{}}}]`)
+	foreign := json.RawMessage(`[{"type":"thinking","thinking":"x","signature":"s"},{"type":"tool_use","id":"c2","name":"a","input":{}}]`)
+	body := p.buildRequestBody("gemini-2.5-flash", ChatRequest{Messages: []Message{
+		{Role: "user", Content: "go"},
+		{Role: "assistant", RawAssistantContent: native, ToolCalls: []ToolCall{{ID: "c1", Name: "a"}}},
+		{Role: "tool", ToolCallID: "c1", Content: "ok"},
+		{Role: "assistant", RawAssistantContent: foreign, ToolCalls: []ToolCall{{ID: "c2", Name: "a"}}},
+	}})
+	contents := body["contents"].([]map[string]any)
+	data, _ := json.Marshal(contents[1]["parts"])
+	if !strings.Contains(string(data), `"thoughtSignature":"t-sig"`) {
+		t.Errorf("native raw parts not passed back: %s", data)
+	}
+	data, _ = json.Marshal(contents[3]["parts"])
+	if strings.Contains(string(data), "tool_use") || !strings.Contains(string(data), `"functionCall"`) {
+		t.Errorf("foreign raw content must be rebuilt from ToolCalls: %s", data)
+	}
+}
+
+func TestNormalizeGeminiAPIBase(t *testing.T) {
+	tests := map[string]string{
+		"": GeminiDefaultAPIBase,
+		"https://generativelanguage.googleapis.com/v1beta/openai/": GeminiDefaultAPIBase,
+		"https://proxy.example.com/v1beta":                         "https://proxy.example.com/v1beta",
+	}
+	for in, want := range tests {
+		if got := NormalizeGeminiAPIBase(in); got != want {
+			t.Errorf("NormalizeGeminiAPIBase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGeminiAdapter(t *testing.T) {
+	adapter, err := DefaultAdapterRegistry().Get("gemini", ProviderConfig{APIKey: "k", Model: "gemini-2.5-pro"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := adapter.(*GeminiAdapter).Endpoint(ChatRequest{}, true); got !=
+		GeminiDefaultAPIBase+"/models/gemini-2.5-pro:streamGenerateContent?alt=sse" {
+		t.Errorf("Endpoint = %q", got)
+	}
+	_, headers, err := adapter.ToRequest(ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertHeader(t, headers, "x-goog-api-key", "k")
+
+	chunk, _ := adapter.FromStreamChunk([]byte(`{"candidates":[{"content":{"parts":[{"text":"Hi"}]},"finishReason":"STOP"}]}`))
+	if chunk == nil || chunk.Content != "Hi" || !chunk.Done {
+		t.Errorf("chunk = %#v", chunk)
+	}
+}
@@ -0,0 +1,411 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// API key selection strategies for APIKeyPool.
+const (
+	KeyPoolRoundRobin = "round_robin"
+	KeyPoolLeastUsed  = "least_used"
+)
+
+// keyPoolClassifier classifies upstream statuses per key. Only the status is
+// used: bodies are left unread so callers see the original response.
+var keyPoolClassifier = NewDefaultClassifier()
+
+// APIKeyPool spreads requests for one provider across several API keys.
+// Each key has its own cooldown (fed by Retry-After and 429/auth/billing
+// classification) and usage counters. It works at the HTTP layer: the pool's
+// transport writes the selected key into the provider's auth header on every
+// request, so every API-key provider type can use it.
+type APIKeyPool struct {
+	provider string
+	strategy string
+	keys     []*pooledKey
+	cooldown *CooldownTracker
+	next     atomic.Uint64
+
+	primaryMissing bool // the provider itself has no key; every request needs one from the pool
+}
+
+type pooledKey struct {
+	secret string
+	label  string // masked form, safe to expose
+
+	mu                sync.Mutex
+	inFlight          int
+	requests          int64
+	successes         int64
+	rateLimited       int64
+	failures          int64
+	lastUsedAt        time.Time
+	lastStatus        int
+	remainingRequests int // -1 = unknown (from x-ratelimit-remaining-* headers)
+	remainingTokens   int
+}
+
+// APIKeyUsage is a point-in-time view of one pooled key.
+type APIKeyUsage struct {
+	Key               string     `json:"key"` // masked
+	Available         bool       `json:"available"`
+	CooldownUntil     *time.Time `json:"cooldown_until,omitempty"`
+	InFlight          int        `json:"in_flight"`
+	Requests          int64      `json:"requests"`
+	Successes         int64      `json:"successes"`
+	RateLimited       int64      `json:"rate_limited"`
+	Failures          int64      `json:"failures"`
+	LastUsedAt        *time.Time `json:"last_used_at,omitempty"`
+	LastStatus        int        `json:"last_status,omitempty"`
+	RemainingRequests *int       `json:"remaining_requests,omitempty"`
+	RemainingTokens   *int       `json:"remaining_tokens,omitempty"`
+}
+
+// APIKeyPoolStatus is the pool summary reported by the providers API.
+type APIKeyPoolStatus struct {
+	Strategy string        `json:"strategy"`
+	Keys     []APIKeyUsage `json:"keys"`
+}
+
+// NewAPIKeyPool creates a pool over keys; keys[0] must be the key the
+// provider was constructed with, which may be empty. Empty and duplicate keys
+// are dropped and an unknown strategy falls back to round-robin.
+func NewAPIKeyPool(provider, strategy string, keys []string) *APIKeyPool {
+	if strategy != KeyPoolLeastUsed {
+		strategy = KeyPoolRoundRobin
+	}
+	pool := &APIKeyPool{
+		provider:       provider,
+		strategy:       strategy,
+		cooldown:       NewCooldownTracker(0),
+		primaryMissing: len(keys) > 0 && keys[0] == "",
+	}
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		pool.keys = append(pool.keys, &pooledKey{
+			secret:            k,
+			label:             maskPooledKey(k),
+			remainingRequests: -1,
+			remainingTokens:   -1,
+		})
+	}
+	return pool
+}
+
+// Len returns the number of distinct keys in the pool.
+func (p *APIKeyPool) Len() int { return len(p.keys) }
+
+// Strategy returns the selection strategy.
+func (p *APIKeyPool) Strategy() string { return p.strategy }
+
+// Status returns per-key usage in pool order.
+func (p *APIKeyPool) Status() APIKeyPoolStatus {
+	status := APIKeyPoolStatus{Strategy: p.strategy, Keys: make([]APIKeyUsage, 0, len(p.keys))}
+	for i, k := range p.keys {
+		until := p.cooldown.CooldownUntil(p.cooldownKey(i))
+		k.mu.Lock()
+		u := APIKeyUsage{
+			Key:         k.label,
+			Available:   until.IsZero(),
+			InFlight:    k.inFlight,
+			Requests:    k.requests,
+			Successes:   k.successes,
+			RateLimited: k.rateLimited,
+			Failures:    k.failures,
+			LastStatus:  k.lastStatus,
+		}
+		if !until.IsZero() {
+			u.CooldownUntil = &until
+		}
+		if !k.lastUsedAt.IsZero() {
+			last := k.lastUsedAt
+			u.LastUsedAt = &last
+		}
+		if k.remainingRequests >= 0 {
+			n := k.remainingRequests
+			u.RemainingRequests = &n
+		}
+		if k.remainingTokens >= 0 {
+			n := k.remainingTokens
+			u.RemainingTokens = &n
+		}
+		k.mu.Unlock()
+		status.Keys = append(status.Keys, u)
+	}
+	return status
+}
+
+// WrapClient returns a copy of c whose transport rotates pool keys, setting
+// header to prefix+key on each request. A nil client wraps a default one.
+func (p *APIKeyPool) WrapClient(c *http.Client, header, prefix string) *http.Client {
+	if c == nil {
+		c = NewDefaultHTTPClient()
+	}
+	wrapped := *c
+	base := c.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrapped.Transport = &keyPoolTransport{pool: p, base: base, header: header, prefix: prefix}
+	return &wrapped
+}
+
+func (p *APIKeyPool) cooldownKey(i int) string {
+	return CooldownKey(p.provider, strconv.Itoa(i))
+}
+
+// order returns key indexes to try: available keys by strategy, then keys
+// in cooldown by soonest expiry (so a fully limited pool still makes progress).
+func (p *APIKeyPool) order() []int {
+	available := make([]int, 0, len(p.keys))
+	var cooling []int
+	for i := range p.keys {
+		if p.cooldown.IsAvailable(p.cooldownKey(i)) {
+			available = append(available, i)
+		} else {
+			cooling = append(cooling, i)
+		}
+	}
+
+	switch {
+	case len(available) <= 1:
+	case p.strategy == KeyPoolLeastUsed:
+		load := func(i int) (int, int64) {
+			k := p.keys[i]
+			k.mu.Lock()
+			defer k.mu.Unlock()
+			return k.inFlight, k.requests
+		}
+		// Insertion sort: pools are small and ties keep pool order.
+		for i := 1; i < len(available); i++ {
+			for j := i; j > 0; j-- {
+				fa, ra := load(available[j])
+				fb, rb := load(available[j-1])
+				if fa < fb || (fa == fb && ra < rb) {
+					available[j], available[j-1] = available[j-1], available[j]
+					continue
+				}
+				break
+			}
+		}
+	default:
+		start := int(p.next.Add(1)-1) % len(available)
+		available = append(available[start:], available[:start]...)
+	}
+
+	for i := 1; i < len(cooling); i++ {
+		for j := i; j > 0; j-- {
+			a := p.cooldown.CooldownUntil(p.cooldownKey(cooling[j]))
+			b := p.cooldown.CooldownUntil(p.cooldownKey(cooling[j-1]))
+			if !a.Before(b) {
+				break
+			}
+			cooling[j], cooling[j-1] = cooling[j-1], cooling[j]
+		}
+	}
+	return append(available, cooling...)
+}
+
+// keyPoolPinnedKey marks a request context whose calls must all use the same
+// key. See WithPinnedPoolKey.
+type keyPoolPinnedKey struct{}
+
+// WithPinnedPoolKey makes every call under ctx use the pool's first key, with
+// no rotation or failover. Multi-call workflows need this when the upstream
+// scopes objects to the key's project: a file uploaded with one key is not
+// visible to another (OpenAI Files and Batches).
+func WithPinnedPoolKey(ctx context.Context) context.Context {
+	return context.WithValue(ctx, keyPoolPinnedKey{}, true)
+}
+
+// keyPoolTransport sets the selected key on each request and fails over to
+// the next key when the upstream rejects a key (rate limit, auth, billing).
+type keyPoolTransport struct {
+	pool   *APIKeyPool
+	base   http.RoundTripper
+	header string // auth header the provider carries its key in
+	prefix string // value prefix, e.g. "Bearer "
+}
+
+func (t *keyPoolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := t.pool
+	if len(p.keys) == 0 {
+		return t.base.RoundTrip(req)
+	}
+	// Requests whose body can't be replayed get a single attempt.
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	order := p.order()
+	if pinned, _ := req.Context().Value(keyPoolPinnedKey{}).(bool); pinned {
+		order = []int{0}
+	}
+	for attempt, idx := range order {
+		outReq, err := t.withKey(req, idx, attempt > 0)
+		if err != nil {
+			return nil, err
+		}
+		k := p.keys[idx]
+		k.begin()
+		resp, err := t.base.RoundTrip(outReq)
+		if err != nil {
+			k.finish(0, nil)
+			k.release()
+			return nil, err
+		}
+		k.finish(resp.StatusCode, resp.Header)
+		// Streams stay in flight until the caller closes the body.
+		resp.Body = &pooledKeyBody{ReadCloser: resp.Body, key: k}
+
+		reason, keyScoped := keyPoolFailureReason(resp.StatusCode)
+		if !keyScoped {
+			if resp.StatusCode < 400 {
+				p.cooldown.RecordSuccess(p.cooldownKey(idx))
+			}
+			return resp, nil
+		}
+		p.cooldown.RecordFailureFor(p.cooldownKey(idx), reason, ParseRetryAfter(resp.Header.Get("Retry-After")))
+
+		last := attempt == len(order)-1
+		nextAvailable := !last && p.cooldown.IsAvailable(p.cooldownKey(order[attempt+1]))
+		if !replayable || !nextAvailable {
+			return resp, nil
+		}
+		resp.Body.Close()
+	}
+	return nil, fmt.Errorf("%s: no API key attempted", p.provider)
+}
+
+// withKey clones req with its auth header set to key idx. rewind re-reads the
+// body for a failover attempt.
+func (t *keyPoolTransport) withKey(req *http.Request, idx int, rewind bool) (*http.Request, error) {
+	out := req.Clone(req.Context())
+	if rewind && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		out.Body = body
+	}
+	out.Header.Set(t.header, t.prefix+t.pool.keys[idx].secret)
+	return out, nil
+}
+
+// keyPoolFailureReason reports whether a status rejects the key itself (as
+// opposed to the request or the upstream), using the shared classifier.
+func keyPoolFailureReason(status int) (FailoverReason, bool) {
+	if status < 400 {
+		return "", false
+	}
+	c := keyPoolClassifier.Classify(nil, status, "")
+	switch c.Reason {
+	case FailoverRateLimit, FailoverAuth, FailoverAuthPermanent, FailoverBilling:
+		return c.Reason, true
+	}
+	return "", false
+}
+
+func (k *pooledKey) begin() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.inFlight++
+	k.requests++
+	k.lastUsedAt = time.Now()
+}
+
+func (k *pooledKey) release() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.inFlight--
+}
+
+// finish records the outcome; status 0 means a transport error.
+func (k *pooledKey) finish(status int, header http.Header) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.lastStatus = status
+	switch {
+	case status == http.StatusTooManyRequests:
+		k.rateLimited++
+	case status == 0 || status >= 400:
+		k.failures++
+	default:
+		k.successes++
+	}
+	if header == nil {
+		return
+	}
+	if n, ok := headerInt(header, "x-ratelimit-remaining-requests", "anthropic-ratelimit-requests-remaining"); ok {
+		k.remainingRequests = n
+	}
+	if n, ok := headerInt(header, "x-ratelimit-remaining-tokens", "anthropic-ratelimit-tokens-remaining"); ok {
+		k.remainingTokens = n
+	}
+}
+
+// pooledKeyBody releases the key's in-flight slot once, on Close.
+type pooledKeyBody struct {
+	io.ReadCloser
+	key  *pooledKey
+	once sync.Once
+}
+
+func (b *pooledKeyBody) Close() error {
+	b.once.Do(b.key.release)
+	return b.ReadCloser.Close()
+}
+
+// headerInt returns the first of names present as an integer header.
+func headerInt(h http.Header, names ...string) (int, bool) {
+	for _, name := range names {
+		if v := h.Get(name); v != "" {
+			if n, err := strconv.Atoi(v); err == nil {
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// maskPooledKey keeps the last 4 characters of a key.
+func maskPooledKey(k string) string {
+	if len(k) <= 8 {
+		return "***"
+	}
+	return "***" + k[len(k)-4:]
+}
+
+// KeyPooled is implemented by providers that can rotate API keys.
+type KeyPooled interface {
+	KeyPool() *APIKeyPool
+}
+
+// keyPoolSetter installs a pool on a provider's HTTP client.
+type keyPoolSetter interface {
+	setKeyPool(pool *APIKeyPool)
+}
+
+// AttachKeyPool installs pool on p when p supports key rotation and the pool
+// has more than one key, or supplies the only key when p has none. Returns
+// whether it was installed.
+func AttachKeyPool(p Provider, pool *APIKeyPool) bool {
+	if pool == nil || pool.Len() == 0 || (pool.Len() < 2 && !pool.primaryMissing) {
+		return false
+	}
+	s, ok := p.(keyPoolSetter)
+	if !ok {
+		return false
+	}
+	s.setKeyPool(pool)
+	return true
+}
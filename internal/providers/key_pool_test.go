@@ -0,0 +1,199 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const keyPoolChatOK = `{"choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`
+
+// newKeyPoolServer answers chat completions and records the bearer key of
+// each request. limited keys get a 429 with Retry-After.
+func newKeyPoolServer(t *testing.T, limited map[string]bool) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		mu.Lock()
+		seen = append(seen, key)
+		mu.Unlock()
+		if limited[key] {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"rate limited"}}`))
+			return
+		}
+		w.Header().Set("x-ratelimit-remaining-requests", "41")
+		_, _ = w.Write([]byte(keyPoolChatOK))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), seen...)
+	}
+}
+
+func newKeyPoolProvider(t *testing.T, url, strategy string, keys ...string) (*OpenAIProvider, *APIKeyPool) {
+	t.Helper()
+	prov := NewOpenAIProvider("pooled", keys[0], url, "gpt-test")
+	prov.retryConfig = RetryConfig{Attempts: 1}
+	pool := NewAPIKeyPool("pooled", strategy, keys)
+	if !AttachKeyPool(prov, pool) {
+		t.Fatal("AttachKeyPool returned false")
+	}
+	return prov, pool
+}
+
+func chatOnce(t *testing.T, p Provider) error {
+	t.Helper()
+	_, err := p.Chat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	return err
+}
+
+func TestAPIKeyPool_RoundRobinRotatesKeys(t *testing.T) {
+	srv, seen := newKeyPoolServer(t, nil)
+	prov, pool := newKeyPoolProvider(t, srv.URL, KeyPoolRoundRobin, "key-aaaa-1111", "key-bbbb-2222", "key-cccc-3333")
+
+	for range 3 {
+		if err := chatOnce(t, prov); err != nil {
+			t.Fatalf("Chat: %v", err)
+		}
+	}
+	got := seen()
+	want := []string{"key-aaaa-1111", "key-bbbb-2222", "key-cccc-3333"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("keys used = %v, want %v", got, want)
+	}
+
+	status := pool.Status()
+	if status.Strategy != KeyPoolRoundRobin || len(status.Keys) != 3 {
+		t.Fatalf("status = %+v", status)
+	}
+	for _, k := range status.Keys {
+		if k.Requests != 1 || k.Successes != 1 || k.InFlight != 0 {
+			t.Errorf("key %s usage = %+v", k.Key, k)
+		}
+		if k.RemainingRequests == nil || *k.RemainingRequests != 41 {
+			t.Errorf("key %s remaining requests = %v", k.Key, k.RemainingRequests)
+		}
+		if strings.Contains(k.Key, "aaaa") || strings.Contains(k.Key, "bbbb") {
+			t.Errorf("status leaks key material: %q", k.Key)
+		}
+	}
+}
+
+func TestAPIKeyPool_RateLimitedKeyFailsOverAndCoolsDown(t *testing.T) {
+	srv, seen := newKeyPoolServer(t, map[string]bool{"key-aaaa-1111": true})
+	prov, pool := newKeyPoolProvider(t, srv.URL, KeyPoolRoundRobin, "key-aaaa-1111", "key-bbbb-2222")
+
+	if err := chatOnce(t, prov); err != nil {
+		t.Fatalf("Chat should fail over to the second key: %v", err)
+	}
+	if err := chatOnce(t, prov); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	// First call: limited key then failover; second call skips the cooled-down key.
+	got := seen()
+	want := []string{"key-aaaa-1111", "key-bbbb-2222", "key-bbbb-2222"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("keys used = %v, want %v", got, want)
+	}
+
+	limited := pool.Status().Keys[0]
+	if limited.Available || limited.CooldownUntil == nil || limited.RateLimited != 1 {
+		t.Errorf("limited key status = %+v", limited)
+	}
+}
+
+func TestAPIKeyPool_AllKeysLimitedReturnsUpstreamError(t *testing.T) {
+	srv, seen := newKeyPoolServer(t, map[string]bool{"key-aaaa-1111": true, "key-bbbb-2222": true})
+	prov, _ := newKeyPoolProvider(t, srv.URL, KeyPoolLeastUsed, "key-aaaa-1111", "key-bbbb-2222")
+
+	err := chatOnce(t, prov)
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Fatalf("expected 429 error, got %v", err)
+	}
+	if n := len(seen()); n != 2 {
+		t.Errorf("expected one attempt per key, got %d", n)
+	}
+}
+
+func TestAPIKeyPool_LeastUsedPrefersIdleKey(t *testing.T) {
+	pool := NewAPIKeyPool("pooled", KeyPoolLeastUsed, []string{"k1-xxxxxxxx", "k2-xxxxxxxx", "k2-xxxxxxxx", ""})
+	if pool.Len() != 2 {
+		t.Fatalf("duplicate/empty keys should be dropped, got %d", pool.Len())
+	}
+	pool.keys[0].begin()
+	pool.keys[0].finish(http.StatusOK, nil)
+	if order := pool.order(); order[0] != 1 {
+		t.Errorf("least_used should pick the unused key first, got order %v", order)
+	}
+}
+
+func TestAttachKeyPool_SingleKeyIsNoop(t *testing.T) {
+	prov := NewOpenAIProvider("single", "only-key", "http://example.invalid", "m")
+	if AttachKeyPool(prov, NewAPIKeyPool("single", "", []string{"only-key"})) {
+		t.Error("single-key pool should not be attached")
+	}
+	if prov.KeyPool() != nil {
+		t.Error("KeyPool should stay nil")
+	}
+}
+
+func TestAPIKeyPool_EmptyPrimaryUsesPoolKeys(t *testing.T) {
+	srv, seen := newKeyPoolServer(t, nil)
+	prov, _ := newKeyPoolProvider(t, srv.URL, KeyPoolRoundRobin, "", "key-bbbb-2222", "key-cccc-3333")
+
+	for range 3 {
+		if err := chatOnce(t, prov); err != nil {
+			t.Fatalf("Chat: %v", err)
+		}
+	}
+	got := seen()
+	want := []string{"key-bbbb-2222", "key-cccc-3333", "key-bbbb-2222"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("keys used = %v, want %v", got, want)
+	}
+
+	// A single extra key is still attached when the provider has none.
+	srv2, seen2 := newKeyPoolServer(t, nil)
+	single, _ := newKeyPoolProvider(t, srv2.URL, "", "", "key-only-9999")
+	if err := chatOnce(t, single); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if got := seen2(); len(got) != 1 || got[0] != "key-only-9999" {
+		t.Errorf("keys used = %v, want [key-only-9999]", got)
+	}
+}
+
+func TestAPIKeyPool_SetsProviderAuthHeader(t *testing.T) {
+	var mu sync.Mutex
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		got = append(got, r.Header.Get("x-api-key"))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	prov := NewAnthropicProvider("", WithAnthropicBaseURL(srv.URL))
+	prov.retryConfig = RetryConfig{Attempts: 1}
+	if !AttachKeyPool(prov, NewAPIKeyPool("anthropic", "", []string{"", "sk-ant-1", "sk-ant-2"})) {
+		t.Fatal("AttachKeyPool returned false")
+	}
+	for range 2 {
+		if err := chatOnce(t, prov); err != nil {
+			t.Fatalf("Chat: %v", err)
+		}
+	}
+	if strings.Join(got, ",") != "sk-ant-1,sk-ant-2" {
+		t.Errorf("x-api-key values = %v", got)
+	}
+}
@@ -0,0 +1,338 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// llama.cpp server (llama-server) constants.
+const (
+	// LlamaCppDefaultAPIBase is a local llama-server (API root, no /v1).
+	LlamaCppDefaultAPIBase = "http://localhost:8080"
+
+	// LlamaCppDefaultModel is sent when neither the request nor the provider
+	// sets a model. A single-model llama-server answers with its loaded model
+	// whatever the name; set a real model when the server routes between several.
+	LlamaCppDefaultModel = "default"
+
+	// llamaCppDefaultNumCtx is llama-server's context size when started without -c
+	// and /props has not been read yet.
+	llamaCppDefaultNumCtx = 4096
+)
+
+// NormalizeLlamaCppAPIBase converts a stored api_base into the server root.
+// Chat goes through the OpenAI-compat routes under /v1; /health and /props
+// live at the root, so a trailing /v1 is stripped.
+func NormalizeLlamaCppAPIBase(base string) string {
+	base = strings.TrimRight(strings.TrimSpace(base), "/")
+	base = strings.TrimRight(strings.TrimSuffix(base, "/v1"), "/")
+	if base == "" {
+		return LlamaCppDefaultAPIBase
+	}
+	return base
+}
+
+// LlamaCppProvider implements Provider against a llama.cpp server. Chat uses
+// its OpenAI-compatible /v1/chat/completions; on top of that it checks
+// /health and discovers the served models (with the context size the server
+// actually runs, from /props) into the ModelRegistry. llama-server loads its
+// models at startup, so there is no pull.
+type LlamaCppProvider struct {
+	chat     *OpenAIProvider
+	name     string
+	apiKey   string // optional, matches llama-server --api-key
+	apiBase  string
+	client   *http.Client
+	registry ModelRegistry // discovered models are registered here (nil = skip)
+
+	numCtx atomic.Int64 // last n_ctx read from /props; 0 = not read yet
+}
+
+// NewLlamaCppProvider creates a llama.cpp provider. apiBase may be empty
+// (LlamaCppDefaultAPIBase) or the stored OpenAI-compat base ending in /v1.
+func NewLlamaCppProvider(name, apiBase, defaultModel string) *LlamaCppProvider {
+	if name == "" {
+		name = "llamacpp"
+	}
+	if defaultModel == "" {
+		defaultModel = LlamaCppDefaultModel
+	}
+	root := NormalizeLlamaCppAPIBase(apiBase)
+	return &LlamaCppProvider{
+		chat:    NewOpenAIProvider(name, "", root+"/v1", defaultModel),
+		name:    name,
+		apiBase: root,
+		client:  NewDefaultHTTPClient(),
+	}
+}
+
+// WithAPIKey sets the Bearer token for a server started with --api-key.
+func (p *LlamaCppProvider) WithAPIKey(key string) *LlamaCppProvider {
+	p.apiKey = key
+	p.chat.apiKey = key
+	return p
+}
+
+// WithHTTPClient replaces the HTTP client (tests, custom proxies).
+func (p *LlamaCppProvider) WithHTTPClient(c *http.Client) *LlamaCppProvider {
+	if c != nil {
+		p.client = c
+		p.chat.WithHTTPClient(c)
+	}
+	return p
+}
+
+// WithRetryConfig overrides the retry policy for chat calls.
+func (p *LlamaCppProvider) WithRetryConfig(cfg RetryConfig) *LlamaCppProvider {
+	p.chat.retryConfig = cfg
+	return p
+}
+
+// WithRegistry sets the ModelRegistry that discovered models are written to.
+// Registries that accept forward-compat resolvers also get the provider as
+// one, so models the server starts serving later resolve on first use.
+func (p *LlamaCppProvider) WithRegistry(r ModelRegistry) *LlamaCppProvider {
+	p.registry = r
+	p.chat.WithRegistry(r)
+	if rr, ok := r.(interface {
+		RegisterResolver(string, ForwardCompatResolver)
+	}); ok {
+		rr.RegisterResolver(p.name, p)
+	}
+	return p
+}
+
+func (p *LlamaCppProvider) Name() string           { return p.name }
+func (p *LlamaCppProvider) DefaultModel() string   { return p.chat.DefaultModel() }
+func (p *LlamaCppProvider) SupportsThinking() bool { return true }
+
+// APIBase returns the server root.
+func (p *LlamaCppProvider) APIBase() string { return p.apiBase }
+
+// Capabilities implements CapabilitiesAware. The context window is the
+// server's n_ctx once /props has been read.
+func (p *LlamaCppProvider) Capabilities() ProviderCapabilities {
+	window := int(p.numCtx.Load())
+	if window == 0 {
+		window = llamaCppDefaultNumCtx
+	}
+	return ProviderCapabilities{
+		Streaming:        true,
+		ToolCalling:      true,
+		StreamWithTools:  true,
+		Thinking:         true,
+		Vision:           true,
+		StructuredOutput: true,
+		MaxContextWindow: window,
+		TokenizerID:      "cl100k_base",
+	}
+}
+
+func (p *LlamaCppProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	return p.chat.Chat(ctx, req)
+}
+
+func (p *LlamaCppProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	return p.chat.ChatStream(ctx, req, onChunk)
+}
+
+// LlamaCppModelInfo is the subset of /props used for discovery.
+type LlamaCppModelInfo struct {
+	NumCtx      int  // context size the server runs the model with (-c)
+	NumCtxTrain int  // model's trained context, from /v1/models meta
+	Vision      bool // a multimodal projector is loaded
+}
+
+// Health checks /health and returns the server build from /props ("" when
+// the server doesn't report it). llama-server answers /health with 503 while
+// the model is still loading, which is reported as an error.
+func (p *LlamaCppProvider) Health(ctx context.Context) (string, error) {
+	if _, err := p.get(ctx, "/health"); err != nil {
+		return "", err
+	}
+	data, err := p.get(ctx, "/props")
+	if err != nil {
+		return "", nil
+	}
+	var v struct {
+		BuildInfo string `json:"build_info"`
+	}
+	_ = json.Unmarshal(data, &v)
+	return v.BuildInfo, nil
+}
+
+// LlamaCppModel is a served model from /v1/models.
+type LlamaCppModel struct {
+	ID          string `json:"id"`
+	NumCtxTrain int    `json:"n_ctx_train,omitempty"` // trained context length, 0 if not reported
+}
+
+// ListModels returns the served models from /v1/models.
+func (p *LlamaCppProvider) ListModels(ctx context.Context) ([]LlamaCppModel, error) {
+	data, err := p.get(ctx, "/v1/models")
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Data []struct {
+			ID   string `json:"id"`
+			Meta struct {
+				NumCtxTrain int `json:"n_ctx_train"`
+			} `json:"meta"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("%s: decode models: %w", p.name, err)
+	}
+	models := make([]LlamaCppModel, 0, len(result.Data))
+	for _, m := range result.Data {
+		if m.ID != "" {
+			models = append(models, LlamaCppModel{ID: m.ID, NumCtxTrain: m.Meta.NumCtxTrain})
+		}
+	}
+	return models, nil
+}
+
+// ShowModel reads the context size and modalities the server runs model
+// with from /props. Single-model servers ignore the model parameter.
+func (p *LlamaCppProvider) ShowModel(ctx context.Context, model string) (*LlamaCppModelInfo, error) {
+	data, err := p.get(ctx, "/props?model="+url.QueryEscape(model))
+	if err != nil {
+		return nil, err
+	}
+	var props struct {
+		NumCtx                    int `json:"n_ctx"`
+		DefaultGenerationSettings struct {
+			NumCtx int `json:"n_ctx"`
+		} `json:"default_generation_settings"`
+		Modalities struct {
+			Vision bool `json:"vision"`
+		} `json:"modalities"`
+	}
+	if err := json.Unmarshal(data, &props); err != nil {
+		return nil, fmt.Errorf("%s: decode props: %w", p.name, err)
+	}
+	info := &LlamaCppModelInfo{NumCtx: props.DefaultGenerationSettings.NumCtx, Vision: props.Modalities.Vision}
+	if info.NumCtx == 0 {
+		info.NumCtx = props.NumCtx
+	}
+	return info, nil
+}
+
+// EffectiveContextWindow is the server's n_ctx (llama.cpp's default when
+// unknown), capped at the model's trained context length.
+func (p *LlamaCppProvider) EffectiveContextWindow(info *LlamaCppModelInfo) int {
+	n := info.NumCtx
+	if n == 0 {
+		n = llamaCppDefaultNumCtx
+	}
+	if info.NumCtxTrain > 0 && n > info.NumCtxTrain {
+		n = info.NumCtxTrain
+	}
+	return n
+}
+
+// modelSpec builds the registry entry for a shown model.
+func (p *LlamaCppProvider) modelSpec(model string, info *LlamaCppModelInfo) ModelSpec {
+	return ModelSpec{
+		ID:            model,
+		Provider:      p.name,
+		ContextWindow: p.EffectiveContextWindow(info),
+		Vision:        info.Vision,
+		TokenizerID:   "cl100k_base",
+	}
+}
+
+// DiscoverModels lists the served models and registers each one's spec in
+// the ModelRegistry. Models whose /props fails are skipped.
+func (p *LlamaCppProvider) DiscoverModels(ctx context.Context) ([]ModelSpec, error) {
+	models, err := p.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	specs := make([]ModelSpec, 0, len(models))
+	for _, m := range models {
+		info, err := p.ShowModel(ctx, m.ID)
+		if err != nil {
+			slog.Debug("llamacpp: props failed", "provider", p.name, "model", m.ID, "error", err)
+			continue
+		}
+		info.NumCtxTrain = m.NumCtxTrain
+		spec := p.modelSpec(m.ID, info)
+		p.numCtx.Store(int64(spec.ContextWindow))
+		if p.registry != nil {
+			p.registry.Register(spec)
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// ResolveForwardCompat implements ForwardCompatResolver: models not seen at
+// discovery (server restarted with another model) are looked up on first use.
+func (p *LlamaCppProvider) ResolveForwardCompat(modelID string, _ ModelRegistry) *ModelSpec {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	models, err := p.ListModels(ctx)
+	if err != nil {
+		return nil
+	}
+	idx := slices.IndexFunc(models, func(m LlamaCppModel) bool { return m.ID == modelID })
+	if idx < 0 {
+		return nil
+	}
+	info, err := p.ShowModel(ctx, modelID)
+	if err != nil {
+		return nil
+	}
+	info.NumCtxTrain = models[idx].NumCtxTrain
+	spec := p.modelSpec(modelID, info)
+	return &spec
+}
+
+// get performs a GET against the server root and returns the body on 200.
+func (p *LlamaCppProvider) get(ctx context.Context, path string) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiBase+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: create request: %w", p.name, err)
+	}
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s: request failed: %w", p.name, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: read response: %w", p.name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPError{Status: resp.StatusCode, Body: fmt.Sprintf("%s: %s", p.name, llamaCppErrorMessage(data))}
+	}
+	return data, nil
+}
+
+// llamaCppErrorMessage extracts error.message from a llama-server error body,
+// falling back to the raw body.
+func llamaCppErrorMessage(data []byte) string {
+	var e struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &e) == nil && e.Error.Message != "" {
+		return e.Error.Message
+	}
+	return strings.TrimSpace(string(data))
+}
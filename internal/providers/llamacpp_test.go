@@ -0,0 +1,126 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestLlamaCppServer(t *testing.T, handler http.HandlerFunc) *LlamaCppProvider {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return NewLlamaCppProvider("llamacpp", srv.URL+"/v1", "").
+		WithRetryConfig(RetryConfig{Attempts: 1})
+}
+
+func TestNormalizeLlamaCppAPIBase(t *testing.T) {
+	cases := map[string]string{
+		"":                     LlamaCppDefaultAPIBase,
+		"http://host:8080/v1":  "http://host:8080",
+		"http://host:8080/v1/": "http://host:8080",
+		" http://host:8080/ ":  "http://host:8080",
+		"https://proxy/llama":  "https://proxy/llama",
+	}
+	for in, want := range cases {
+		if got := NormalizeLlamaCppAPIBase(in); got != want {
+			t.Errorf("NormalizeLlamaCppAPIBase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLlamaCppProvider_ChatUsesOpenAIRoute(t *testing.T) {
+	p := newTestLlamaCppServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],
+			"usage":{"prompt_tokens":5,"completion_tokens":1}}`))
+	})
+	p.WithAPIKey("secret")
+
+	resp, err := p.Chat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "hello"}}})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if resp.Content != "hi" {
+		t.Errorf("content = %q", resp.Content)
+	}
+	if p.DefaultModel() != LlamaCppDefaultModel {
+		t.Errorf("DefaultModel() = %q", p.DefaultModel())
+	}
+}
+
+func TestLlamaCppProvider_DiscoverModelsRegistersContextWindow(t *testing.T) {
+	p := newTestLlamaCppServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/models":
+			_, _ = w.Write([]byte(`{"object":"list","data":[
+				{"id":"qwen3-8b","meta":{"n_ctx_train":40960}},
+				{"id":"gemma-3-4b","meta":{"n_ctx_train":8192}}]}`))
+		case "/props":
+			switch r.URL.Query().Get("model") {
+			case "qwen3-8b":
+				_, _ = w.Write([]byte(`{"default_generation_settings":{"n_ctx":32768}}`))
+			case "gemma-3-4b":
+				// Server context larger than the trained one: capped.
+				_, _ = w.Write([]byte(`{"default_generation_settings":{"n_ctx":16384},"modalities":{"vision":true}}`))
+			}
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	})
+	reg := NewInMemoryRegistry()
+	p.WithRegistry(reg)
+
+	specs, err := p.DiscoverModels(context.Background())
+	if err != nil {
+		t.Fatalf("DiscoverModels: %v", err)
+	}
+	if len(specs) != 2 {
+		t.Fatalf("specs = %+v", specs)
+	}
+	if spec := reg.Resolve("llamacpp", "qwen3-8b"); spec == nil || spec.ContextWindow != 32768 || spec.Vision {
+		t.Errorf("qwen3-8b spec = %+v", spec)
+	}
+	if spec := reg.Resolve("llamacpp", "gemma-3-4b"); spec == nil || spec.ContextWindow != 8192 || !spec.Vision {
+		t.Errorf("gemma-3-4b spec = %+v", spec)
+	}
+	if got := p.Capabilities().MaxContextWindow; got == llamaCppDefaultNumCtx {
+		t.Errorf("MaxContextWindow still the default after discovery")
+	}
+}
+
+func TestLlamaCppProvider_HealthReportsLoading(t *testing.T) {
+	loading := true
+	p := newTestLlamaCppServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/props" {
+			_, _ = w.Write([]byte(`{"build_info":"b6000-abcdef"}`))
+			return
+		}
+		if loading {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"code":503,"message":"Loading model","type":"unavailable_error"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	})
+
+	_, err := p.Health(context.Background())
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Status != http.StatusServiceUnavailable || httpErr.Body != "llamacpp: Loading model" {
+		t.Fatalf("Health while loading: err = %v", err)
+	}
+
+	loading = false
+	version, err := p.Health(context.Background())
+	if err != nil || version != "b6000-abcdef" {
+		t.Fatalf("Health = %q, %v", version, err)
+	}
+}
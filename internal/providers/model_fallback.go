@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"time"
 )
 
 // FallbackCandidate is one runtime provider/model fallback option.
@@ -12,17 +13,20 @@ type FallbackCandidate struct {
 }
 
 // ModelFallbackProvider wraps a primary provider with ordered fallback
-// provider/model candidates. The primary candidate is always tried first.
+// provider/model candidates. Without a router the primary candidate is always
+// tried first; a router reorders candidates per request.
 type ModelFallbackProvider struct {
 	primary     FallbackCandidate
 	fallbacks   []FallbackCandidate
 	classifier  ErrorClassifier
 	tracker     *CooldownTracker
+	router      *ModelRouter
 	maxAttempts int
 }
 
 type FallbackCallInfo struct {
 	Streamed bool
+	Route    *RouteDecision // nil when no router is set
 }
 
 type FallbackAfterCall func(*ChatResponse, error, FallbackCallInfo)
@@ -42,6 +46,11 @@ func NewModelFallbackProvider(primary FallbackCandidate, fallbacks []FallbackCan
 	}
 }
 
+// SetRouter installs a routing policy. A nil router keeps the configured order.
+func (p *ModelFallbackProvider) SetRouter(router *ModelRouter) {
+	p.router = router
+}
+
 func (p *ModelFallbackProvider) PrimaryProvider() Provider {
 	return p.primary.Provider
 }
@@ -64,7 +73,7 @@ func (p *ModelFallbackProvider) DefaultModel() string {
 }
 
 func (p *ModelFallbackProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
-	return p.runOrdered(ctx, req, func(ctx context.Context, entry FallbackCandidate, req ChatRequest) (*ChatResponse, error) {
+	return p.runOrdered(ctx, req, func(ctx context.Context, entry FallbackCandidate, req ChatRequest, _ *RouteDecision) (*ChatResponse, error) {
 		nextReq := req
 		nextReq.Model = entry.Model
 		return entry.Provider.Chat(ctx, nextReq)
@@ -72,7 +81,7 @@ func (p *ModelFallbackProvider) Chat(ctx context.Context, req ChatRequest) (*Cha
 }
 
 func (p *ModelFallbackProvider) ChatWithHook(ctx context.Context, req ChatRequest, before FallbackBeforeCall) (*ChatResponse, error) {
-	return p.runOrdered(ctx, req, func(ctx context.Context, entry FallbackCandidate, req ChatRequest) (*ChatResponse, error) {
+	return p.runOrdered(ctx, req, func(ctx context.Context, entry FallbackCandidate, req ChatRequest, route *RouteDecision) (*ChatResponse, error) {
 		nextReq := req
 		nextReq.Model = entry.Model
 		after, err := before(ctx, entry, nextReq)
@@ -81,14 +90,14 @@ func (p *ModelFallbackProvider) ChatWithHook(ctx context.Context, req ChatReques
 		}
 		resp, err := entry.Provider.Chat(ctx, nextReq)
 		if after != nil {
-			after(resp, err, FallbackCallInfo{})
+			after(resp, err, FallbackCallInfo{Route: route})
 		}
 		return resp, err
 	})
 }
 
 func (p *ModelFallbackProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
-	return p.runOrdered(ctx, req, func(ctx context.Context, entry FallbackCandidate, req ChatRequest) (*ChatResponse, error) {
+	return p.runOrdered(ctx, req, func(ctx context.Context, entry FallbackCandidate, req ChatRequest, _ *RouteDecision) (*ChatResponse, error) {
 		nextReq := req
 		nextReq.Model = entry.Model
 		streamed := false
@@ -106,7 +115,7 @@ func (p *ModelFallbackProvider) ChatStream(ctx context.Context, req ChatRequest,
 }
 
 func (p *ModelFallbackProvider) ChatStreamWithHook(ctx context.Context, req ChatRequest, onChunk func(StreamChunk), before FallbackBeforeCall) (*ChatResponse, error) {
-	return p.runOrdered(ctx, req, func(ctx context.Context, entry FallbackCandidate, req ChatRequest) (*ChatResponse, error) {
+	return p.runOrdered(ctx, req, func(ctx context.Context, entry FallbackCandidate, req ChatRequest, route *RouteDecision) (*ChatResponse, error) {
 		nextReq := req
 		nextReq.Model = entry.Model
 		after, err := before(ctx, entry, nextReq)
@@ -121,7 +130,7 @@ func (p *ModelFallbackProvider) ChatStreamWithHook(ctx context.Context, req Chat
 			onChunk(chunk)
 		})
 		if after != nil {
-			after(resp, err, FallbackCallInfo{Streamed: streamed})
+			after(resp, err, FallbackCallInfo{Streamed: streamed, Route: route})
 		}
 		if streamed && err != nil {
 			return nil, noFallbackAfterStreamError{err: err}
@@ -133,9 +142,9 @@ func (p *ModelFallbackProvider) ChatStreamWithHook(ctx context.Context, req Chat
 func (p *ModelFallbackProvider) runOrdered(
 	ctx context.Context,
 	req ChatRequest,
-	call func(context.Context, FallbackCandidate, ChatRequest) (*ChatResponse, error),
+	call func(context.Context, FallbackCandidate, ChatRequest, *RouteDecision) (*ChatResponse, error),
 ) (*ChatResponse, error) {
-	candidates := p.orderedCandidates(req.Model)
+	candidates, route := p.routedCandidates(req)
 	var attempts []FailoverAttempt
 	for i, entry := range candidates {
 		if ctx.Err() != nil {
@@ -148,7 +157,15 @@ func (p *ModelFallbackProvider) runOrdered(
 		if p.tracker != nil && !p.tracker.IsAvailable(key) && !p.tracker.ShouldProbe(key) {
 			continue
 		}
-		resp, err := call(ctx, entry, req)
+		started := time.Now()
+		resp, err := call(ctx, entry, req, route)
+		if p.router != nil && ctx.Err() == nil {
+			outputTokens := 0
+			if resp != nil && resp.Usage != nil {
+				outputTokens = resp.Usage.CompletionTokens
+			}
+			p.router.Observe(entry.ProviderName, entry.Model, time.Since(started), outputTokens, err != nil)
+		}
 		if err == nil {
 			if p.tracker != nil {
 				p.tracker.RecordSuccess(key)
@@ -174,6 +191,26 @@ func (p *ModelFallbackProvider) runOrdered(
 	return nil, &FailoverSummaryError{Attempts: attempts}
 }
 
+// routedCandidates applies the router (if any) to the configured order.
+func (p *ModelFallbackProvider) routedCandidates(req ChatRequest) ([]FallbackCandidate, *RouteDecision) {
+	candidates := p.orderedCandidates(req.Model)
+	if p.router == nil || len(candidates) < 2 {
+		return candidates, nil
+	}
+	byKey := make(map[string]FallbackCandidate, len(candidates))
+	models := make([]ModelCandidate, len(candidates))
+	for i, c := range candidates {
+		byKey[CooldownKey(c.ProviderName, c.Model)] = c
+		models[i] = ModelCandidate{Provider: c.ProviderName, Model: c.Model}
+	}
+	ordered, decision := p.router.Order(req, models)
+	out := make([]FallbackCandidate, len(ordered))
+	for i, m := range ordered {
+		out[i] = byKey[CooldownKey(m.Provider, m.Model)]
+	}
+	return out, &decision
+}
+
 func (p *ModelFallbackProvider) orderedCandidates(requestModel string) []FallbackCandidate {
 	primary := p.primary
 	if requestModel != "" {
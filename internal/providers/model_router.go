@@ -0,0 +1,339 @@
+package providers
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Routing policies for ordering ModelFallbackProvider candidates.
+const (
+	RoutePolicyPriority            = "priority_order"        // configured order (default)
+	RoutePolicyCheapestFitsContext = "cheapest_fits_context" // lowest estimated cost among candidates whose window fits
+	RoutePolicyLowestLatency       = "lowest_latency"        // lowest p50 latency per output token, penalized by error rate
+	RoutePolicyWeightedRoundRobin  = "weighted_round_robin"  // smooth weighted round-robin on the first pick
+)
+
+const (
+	routeStatsWindow     = 50 // latency samples kept per provider/model
+	routeStatsMinSamples = 3  // below this a candidate counts as unmeasured
+	routeStatsMinOutput  = 64 // output tokens assumed for short or unreported replies
+	routeDefaultOutput   = 1024
+)
+
+// ValidRoutePolicy reports whether policy is a known routing policy.
+func ValidRoutePolicy(policy string) bool {
+	switch policy {
+	case RoutePolicyPriority, RoutePolicyCheapestFitsContext, RoutePolicyLowestLatency, RoutePolicyWeightedRoundRobin:
+		return true
+	}
+	return false
+}
+
+// CandidateProfile is the static data a routing policy scores a candidate on.
+type CandidateProfile struct {
+	ContextWindow int     // 0 = unknown (assumed to fit)
+	InputPer1M    float64 // USD per 1M input tokens
+	OutputPer1M   float64 // USD per 1M output tokens
+	Priced        bool    // false when no pricing is known
+	Weight        int     // weighted_round_robin share (<= 0 means 1)
+}
+
+// RouteStats keeps latency and error samples per tenant and provider/model.
+// One instance is shared process-wide so every agent of a tenant benefits
+// from the same data; provider names are tenant-scoped, so samples never
+// cross tenants. Seed preloads it from recent LLM spans so it survives
+// restarts.
+//
+// Latency is normalized per output token: a model that writes longer answers
+// isn't penalized for it.
+type RouteStats struct {
+	mu      sync.Mutex
+	samples map[string]*routeSample
+}
+
+type routeSample struct {
+	latencies []time.Duration // ring buffer of successful call latencies per output token
+	next      int
+	calls     int
+	errors    int
+}
+
+func NewRouteStats() *RouteStats {
+	return &RouteStats{samples: make(map[string]*routeSample)}
+}
+
+// RouteObservation is one past call outcome for Seed.
+type RouteObservation struct {
+	TenantID     uuid.UUID
+	Provider     string
+	Model        string
+	Latency      time.Duration
+	OutputTokens int
+	Failed       bool
+}
+
+// Observe records one call outcome. Failed calls count toward the error rate
+// but not latency (a fast 429 would otherwise look attractive).
+func (s *RouteStats) Observe(tenantID uuid.UUID, provider, model string, latency time.Duration, outputTokens int, failed bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observeLocked(RouteObservation{TenantID: tenantID, Provider: provider, Model: model, Latency: latency, OutputTokens: outputTokens, Failed: failed})
+}
+
+// Seed records past outcomes, oldest first.
+func (s *RouteStats) Seed(observations []RouteObservation) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, o := range observations {
+		s.observeLocked(o)
+	}
+}
+
+func (s *RouteStats) observeLocked(o RouteObservation) {
+	key := routeStatsKey(o.TenantID, o.Provider, o.Model)
+	sample := s.samples[key]
+	if sample == nil {
+		sample = &routeSample{}
+		s.samples[key] = sample
+	}
+	// Decay counts so old outages stop dominating the error rate.
+	if sample.calls >= routeStatsWindow {
+		sample.calls /= 2
+		sample.errors /= 2
+	}
+	sample.calls++
+	if o.Failed {
+		sample.errors++
+		return
+	}
+	latency := o.Latency / time.Duration(max(o.OutputTokens, routeStatsMinOutput))
+	if len(sample.latencies) < routeStatsWindow {
+		sample.latencies = append(sample.latencies, latency)
+		return
+	}
+	sample.latencies[sample.next] = latency
+	sample.next = (sample.next + 1) % routeStatsWindow
+}
+
+// Snapshot returns the p50 latency per output token, error rate and latency
+// sample count.
+func (s *RouteStats) Snapshot(tenantID uuid.UUID, provider, model string) (p50 time.Duration, errorRate float64, samples int) {
+	if s == nil {
+		return 0, 0, 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sample := s.samples[routeStatsKey(tenantID, provider, model)]
+	if sample == nil {
+		return 0, 0, 0
+	}
+	if sample.calls > 0 {
+		errorRate = float64(sample.errors) / float64(sample.calls)
+	}
+	if n := len(sample.latencies); n > 0 {
+		sorted := slices.Clone(sample.latencies)
+		slices.Sort(sorted)
+		p50 = sorted[n/2]
+	}
+	return p50, errorRate, len(sample.latencies)
+}
+
+func routeStatsKey(tenantID uuid.UUID, provider, model string) string {
+	return tenantID.String() + ":" + CooldownKey(provider, model)
+}
+
+// RouteDecision records how a router ordered candidates for one request.
+type RouteDecision struct {
+	Policy   string       `json:"policy"`
+	Selected string       `json:"selected"`
+	Reason   string       `json:"reason"`
+	Scores   []RouteScore `json:"scores,omitempty"`
+}
+
+// RouteScore is one candidate's score. Lower is better; Detail is human-readable.
+type RouteScore struct {
+	Candidate string  `json:"candidate"`
+	Score     float64 `json:"score"`
+	Detail    string  `json:"detail,omitempty"`
+}
+
+// ModelRouter orders one tenant's candidates under a policy. Profiles are
+// keyed by CooldownKey(provider, model); unknown candidates get a zero profile.
+type ModelRouter struct {
+	tenantID uuid.UUID
+	policy   string
+	profiles map[string]CandidateProfile
+	stats    *RouteStats
+
+	mu      sync.Mutex
+	current map[string]int // smooth weighted round-robin state
+}
+
+func NewModelRouter(tenantID uuid.UUID, policy string, profiles map[string]CandidateProfile, stats *RouteStats) *ModelRouter {
+	if !ValidRoutePolicy(policy) {
+		policy = RoutePolicyPriority
+	}
+	return &ModelRouter{tenantID: tenantID, policy: policy, profiles: profiles, stats: stats, current: make(map[string]int)}
+}
+
+func (r *ModelRouter) Policy() string { return r.policy }
+
+// Stats returns the live stats the router reads.
+func (r *ModelRouter) Stats() *RouteStats { return r.stats }
+
+// Observe records one call outcome under the router's tenant.
+func (r *ModelRouter) Observe(provider, model string, latency time.Duration, outputTokens int, failed bool) {
+	r.stats.Observe(r.tenantID, provider, model, latency, outputTokens, failed)
+}
+
+// Order returns candidates sorted by the policy plus the decision. The
+// result is a new slice; ties keep the configured order.
+func (r *ModelRouter) Order(req ChatRequest, candidates []ModelCandidate) ([]ModelCandidate, RouteDecision) {
+	ordered := slices.Clone(candidates)
+	decision := RouteDecision{Policy: r.policy}
+	if len(ordered) == 0 {
+		return ordered, decision
+	}
+	switch r.policy {
+	case RoutePolicyCheapestFitsContext:
+		decision = r.orderByCost(req, ordered)
+	case RoutePolicyLowestLatency:
+		decision = r.orderByLatency(ordered)
+	case RoutePolicyWeightedRoundRobin:
+		decision = r.orderByWeight(ordered)
+	default:
+		decision.Reason = "configured order"
+	}
+	decision.Policy = r.policy
+	decision.Selected = candidateLabel(ordered[0])
+	return ordered, decision
+}
+
+func (r *ModelRouter) profile(c ModelCandidate) CandidateProfile {
+	return r.profiles[CooldownKey(c.Provider, c.Model)]
+}
+
+func (r *ModelRouter) orderByCost(req ChatRequest, candidates []ModelCandidate) RouteDecision {
+	input := EstimateRequestTokens(req)
+	output := routeDefaultOutput
+	if v, ok := req.Options[OptMaxTokens].(int); ok && v > 0 {
+		output = v
+	}
+	needed := input + output
+
+	const unpriced, overflow = 1e9, 2e9 // sort keys after any real cost
+	scores := make([]RouteScore, len(candidates))
+	for i, c := range candidates {
+		p := r.profile(c)
+		score := RouteScore{Candidate: candidateLabel(c)}
+		switch {
+		case p.ContextWindow > 0 && p.ContextWindow < needed:
+			score.Score = overflow
+			score.Detail = fmt.Sprintf("window %d < ~%d tokens", p.ContextWindow, needed)
+		case !p.Priced:
+			score.Score = unpriced
+			score.Detail = "no pricing"
+		default:
+			score.Score = (float64(input)*p.InputPer1M + float64(output)*p.OutputPer1M) / 1_000_000
+			score.Detail = fmt.Sprintf("~$%.5f", score.Score)
+		}
+		scores[i] = score
+	}
+	sortCandidatesByScore(candidates, scores)
+
+	reason := fmt.Sprintf("cheapest fitting ~%d tokens: %s", needed, scores[0].Detail)
+	if scores[0].Score >= unpriced {
+		reason = "no priced candidate fits; configured order"
+	}
+	return RouteDecision{Reason: reason, Scores: scores}
+}
+
+func (r *ModelRouter) orderByLatency(candidates []ModelCandidate) RouteDecision {
+	scores := make([]RouteScore, len(candidates))
+	for i, c := range candidates {
+		p50, errRate, n := r.stats.Snapshot(r.tenantID, c.Provider, c.Model)
+		score := RouteScore{Candidate: candidateLabel(c)}
+		if n < routeStatsMinSamples {
+			// Unmeasured candidates go first so they collect samples.
+			score.Detail = fmt.Sprintf("unmeasured (%d samples)", n)
+		} else {
+			ms := float64(p50.Microseconds()) / 1000
+			score.Score = ms * (1 + 2*errRate)
+			score.Detail = fmt.Sprintf("p50 %.1fms/token, %.0f%% errors, %d samples", ms, errRate*100, n)
+		}
+		scores[i] = score
+	}
+	sortCandidatesByScore(candidates, scores)
+	return RouteDecision{Reason: "lowest latency: " + scores[0].Detail, Scores: scores}
+}
+
+// orderByWeight picks the first candidate by smooth weighted round-robin
+// (nginx-style); the rest keep the configured order as fallbacks.
+func (r *ModelRouter) orderByWeight(candidates []ModelCandidate) RouteDecision {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	total, best := 0, -1
+	for i, c := range candidates {
+		key := CooldownKey(c.Provider, c.Model)
+		w := max(r.profile(c).Weight, 1)
+		total += w
+		r.current[key] += w
+		if best < 0 || r.current[key] > r.current[CooldownKey(candidates[best].Provider, candidates[best].Model)] {
+			best = i
+		}
+	}
+	pick := candidates[best]
+	r.current[CooldownKey(pick.Provider, pick.Model)] -= total
+	copy(candidates[1:best+1], candidates[:best])
+	candidates[0] = pick
+	return RouteDecision{Reason: fmt.Sprintf("weighted round robin: weight %d of %d", max(r.profile(pick).Weight, 1), total)}
+}
+
+// sortCandidatesByScore stable-sorts candidates and scores together, ascending.
+func sortCandidatesByScore(candidates []ModelCandidate, scores []RouteScore) {
+	idx := make([]int, len(candidates))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return scores[idx[a]].Score < scores[idx[b]].Score })
+	c2 := make([]ModelCandidate, len(candidates))
+	s2 := make([]RouteScore, len(scores))
+	for i, j := range idx {
+		c2[i], s2[i] = candidates[j], scores[j]
+	}
+	copy(candidates, c2)
+	copy(scores, s2)
+}
+
+func candidateLabel(c ModelCandidate) string {
+	return c.Provider + "/" + c.Model
+}
+
+// EstimateRequestTokens is a rough chars/4 estimate of a request's input
+// tokens (messages plus tool schemas), used for routing only.
+func EstimateRequestTokens(req ChatRequest) int {
+	chars := 0
+	for _, m := range req.Messages {
+		chars += len(m.Content) + len(m.Thinking)
+		for _, tc := range m.ToolCalls {
+			chars += len(tc.Name) + 64
+		}
+	}
+	for _, t := range req.Tools {
+		if t.Function != nil {
+			chars += len(t.Function.Name) + len(t.Function.Description) + 200
+		}
+	}
+	return chars / 4
+}
@@ -0,0 +1,24 @@
+package providers
+
+import (
+	"encoding/json"
+)
+
+const ModelRoutingMetadataKey = "model_routing"
+
+// MergeRouteDecisionMetadata records why the router chose the first candidate.
+func MergeRouteDecisionMetadata(existing json.RawMessage, decision RouteDecision) json.RawMessage {
+	if decision.Policy == "" {
+		return existing
+	}
+	payload := map[string]any{}
+	if len(existing) > 0 {
+		_ = json.Unmarshal(existing, &payload)
+	}
+	payload[ModelRoutingMetadataKey] = decision
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return existing
+	}
+	return json.RawMessage(data)
+}
@@ -0,0 +1,192 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var routeTenant = uuid.MustParse("00000000-0000-0000-0000-0000000000a1")
+
+func routeCandidates(labels ...string) []ModelCandidate {
+	out := make([]ModelCandidate, len(labels))
+	for i, label := range labels {
+		provider, model, _ := strings.Cut(label, "/")
+		out[i] = ModelCandidate{Provider: provider, Model: model}
+	}
+	return out
+}
+
+func orderLabels(candidates []ModelCandidate) string {
+	labels := make([]string, len(candidates))
+	for i, c := range candidates {
+		labels[i] = candidateLabel(c)
+	}
+	return strings.Join(labels, ",")
+}
+
+func TestModelRouterCheapestFitsContext(t *testing.T) {
+	router := NewModelRouter(routeTenant, RoutePolicyCheapestFitsContext, map[string]CandidateProfile{
+		CooldownKey("a", "big"):   {ContextWindow: 200_000, InputPer1M: 3, OutputPer1M: 15, Priced: true},
+		CooldownKey("b", "small"): {ContextWindow: 2_000, InputPer1M: 0.1, OutputPer1M: 0.4, Priced: true},
+		CooldownKey("c", "mid"):   {ContextWindow: 128_000, InputPer1M: 0.5, OutputPer1M: 1.5, Priced: true},
+	}, NewRouteStats())
+
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: strings.Repeat("x", 8_000)}}}
+	ordered, decision := router.Order(req, routeCandidates("a/big", "b/small", "c/mid", "d/unknown"))
+
+	// small doesn't fit ~3000 tokens; unknown has no pricing.
+	if got := orderLabels(ordered); got != "c/mid,a/big,d/unknown,b/small" {
+		t.Fatalf("order = %s", got)
+	}
+	if decision.Selected != "c/mid" || !strings.Contains(decision.Reason, "cheapest") {
+		t.Fatalf("decision = %#v", decision)
+	}
+	if len(decision.Scores) != 4 || decision.Scores[0].Candidate != "c/mid" {
+		t.Fatalf("scores = %#v", decision.Scores)
+	}
+}
+
+func TestModelRouterLowestLatency(t *testing.T) {
+	stats := NewRouteStats()
+	for range 5 {
+		stats.Observe(routeTenant, "a", "m", 900*time.Millisecond, 100, false)
+		stats.Observe(routeTenant, "b", "m", 300*time.Millisecond, 100, false)
+	}
+	router := NewModelRouter(routeTenant, RoutePolicyLowestLatency, nil, stats)
+
+	ordered, decision := router.Order(ChatRequest{}, routeCandidates("a/m", "b/m"))
+	if got := orderLabels(ordered); got != "b/m,a/m" {
+		t.Fatalf("order = %s", got)
+	}
+	if !strings.Contains(decision.Reason, "p50 3.0ms/token") {
+		t.Fatalf("reason = %q", decision.Reason)
+	}
+
+	// Unmeasured candidates are probed first.
+	ordered, _ = router.Order(ChatRequest{}, routeCandidates("a/m", "b/m", "c/m"))
+	if got := orderLabels(ordered); got != "c/m,b/m,a/m" {
+		t.Fatalf("order with unmeasured = %s", got)
+	}
+
+	// Errors penalize an otherwise fast candidate.
+	for range 10 {
+		stats.Observe(routeTenant, "b", "m", 0, 0, true)
+		stats.Observe(routeTenant, "c", "m", 500*time.Millisecond, 100, false)
+	}
+	ordered, _ = router.Order(ChatRequest{}, routeCandidates("b/m", "c/m"))
+	if got := orderLabels(ordered); got != "c/m,b/m" {
+		t.Fatalf("order after errors = %s", got)
+	}
+}
+
+func TestRouteStatsNormalizesByOutputAndSeeds(t *testing.T) {
+	stats := NewRouteStats()
+	// "long" takes longer per call but writes far more; "short" is slower per token.
+	var seed []RouteObservation
+	for range 5 {
+		seed = append(seed,
+			RouteObservation{TenantID: routeTenant, Provider: "long", Model: "m", Latency: 4 * time.Second, OutputTokens: 1000},
+			RouteObservation{TenantID: routeTenant, Provider: "short", Model: "m", Latency: time.Second, OutputTokens: 100},
+		)
+	}
+	stats.Seed(seed)
+	router := NewModelRouter(routeTenant, RoutePolicyLowestLatency, nil, stats)
+
+	ordered, decision := router.Order(ChatRequest{}, routeCandidates("short/m", "long/m"))
+	if got := orderLabels(ordered); got != "long/m,short/m" {
+		t.Fatalf("order = %s", got)
+	}
+	if !strings.Contains(decision.Reason, "p50 4.0ms/token") {
+		t.Fatalf("reason = %q", decision.Reason)
+	}
+
+	// Tiny or unreported outputs count as routeStatsMinOutput tokens.
+	stats.Observe(routeTenant, "tiny", "m", 640*time.Millisecond, 0, false)
+	if p50, _, _ := stats.Snapshot(routeTenant, "tiny", "m"); p50 != 10*time.Millisecond {
+		t.Fatalf("p50 = %v, want 10ms", p50)
+	}
+}
+
+func TestModelRouterWeightedRoundRobin(t *testing.T) {
+	router := NewModelRouter(routeTenant, RoutePolicyWeightedRoundRobin, map[string]CandidateProfile{
+		CooldownKey("a", "m"): {Weight: 3},
+		CooldownKey("b", "m"): {Weight: 1},
+	}, nil)
+
+	picks := map[string]int{}
+	for range 8 {
+		ordered, decision := router.Order(ChatRequest{}, routeCandidates("a/m", "b/m"))
+		if len(ordered) != 2 || decision.Selected != candidateLabel(ordered[0]) {
+			t.Fatalf("ordered = %v, decision = %#v", ordered, decision)
+		}
+		picks[decision.Selected]++
+	}
+	if picks["a/m"] != 6 || picks["b/m"] != 2 {
+		t.Fatalf("picks = %v, want 6/2", picks)
+	}
+}
+
+func TestRouteStatsSeparateTenants(t *testing.T) {
+	stats := NewRouteStats()
+	other := uuid.New()
+	for range 5 {
+		stats.Observe(routeTenant, "openai", "m", 900*time.Millisecond, 100, false)
+		stats.Observe(other, "openai", "m", 0, 0, true)
+	}
+	if p50, errRate, n := stats.Snapshot(routeTenant, "openai", "m"); p50 != 9*time.Millisecond || errRate != 0 || n != 5 {
+		t.Fatalf("tenant snapshot = %v, %v, %d", p50, errRate, n)
+	}
+	if _, errRate, n := stats.Snapshot(other, "openai", "m"); errRate != 1 || n != 0 {
+		t.Fatalf("other tenant snapshot = %v, %d; want only its own failures", errRate, n)
+	}
+
+	// Another tenant's router treats the same provider name as unmeasured.
+	router := NewModelRouter(uuid.New(), RoutePolicyLowestLatency, nil, stats)
+	if _, decision := router.Order(ChatRequest{}, routeCandidates("openai/m")); !strings.Contains(decision.Reason, "unmeasured") {
+		t.Fatalf("reason = %q", decision.Reason)
+	}
+}
+
+func TestModelFallbackProviderRouterReordersAndReportsDecision(t *testing.T) {
+	primary := &testFallbackProvider{name: "primary"}
+	backup := &testFallbackProvider{name: "backup"}
+	p := NewModelFallbackProvider(
+		FallbackCandidate{ProviderName: "primary", Model: "primary-model", Provider: primary},
+		[]FallbackCandidate{{ProviderName: "backup", Model: "backup-model", Provider: backup}},
+		0, false,
+	)
+	p.SetRouter(NewModelRouter(routeTenant, RoutePolicyCheapestFitsContext, map[string]CandidateProfile{
+		CooldownKey("primary", "primary-model"): {InputPer1M: 10, OutputPer1M: 30, Priced: true},
+		CooldownKey("backup", "backup-model"):   {InputPer1M: 1, OutputPer1M: 3, Priced: true},
+	}, NewRouteStats()))
+
+	var route *RouteDecision
+	resp, err := p.ChatWithHook(context.Background(), ChatRequest{}, func(context.Context, FallbackCandidate, ChatRequest) (FallbackAfterCall, error) {
+		return func(_ *ChatResponse, _ error, info FallbackCallInfo) { route = info.Route }, nil
+	})
+	if err != nil {
+		t.Fatalf("ChatWithHook() error = %v", err)
+	}
+	if resp.Content != "backup-model" || primary.calls != 0 {
+		t.Fatalf("content = %q, primary calls = %d", resp.Content, primary.calls)
+	}
+	if route == nil || route.Selected != "backup/backup-model" {
+		t.Fatalf("route = %#v", route)
+	}
+	if _, _, n := p.router.Stats().Snapshot(routeTenant, "backup", "backup-model"); n != 1 {
+		t.Fatalf("latency samples = %d, want 1", n)
+	}
+
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(MergeRouteDecisionMetadata(nil, *route), &payload); err != nil {
+		t.Fatalf("metadata JSON invalid: %v", err)
+	}
+	if !strings.Contains(string(payload[ModelRoutingMetadataKey]), `"policy":"cheapest_fits_context"`) {
+		t.Fatalf("metadata = %s", payload[ModelRoutingMetadataKey])
+	}
+}
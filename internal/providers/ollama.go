@@ -0,0 +1,334 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Ollama native API constants.
+const (
+	// OllamaDefaultAPIBase is a local Ollama server (native API root, no /v1).
+	OllamaDefaultAPIBase = "http://localhost:11434"
+
+	// OllamaDefaultModel is used when neither the request nor the provider sets a model.
+	OllamaDefaultModel = "llama3.3"
+
+	// ollamaDefaultNumCtx is the context Ollama allocates when neither the
+	// request nor the Modelfile sets num_ctx.
+	ollamaDefaultNumCtx = 4096
+)
+
+// NormalizeOllamaAPIBase converts a stored api_base into the native API root.
+// Provider rows store the OpenAI-compat form (".../v1"); the native endpoints
+// (/api/chat, /api/tags, ...) live at the root, so the suffix is stripped.
+func NormalizeOllamaAPIBase(base string) string {
+	base = strings.TrimRight(strings.TrimSpace(base), "/")
+	base = strings.TrimRight(strings.TrimSuffix(base, "/v1"), "/")
+	if base == "" {
+		return OllamaDefaultAPIBase
+	}
+	return base
+}
+
+// OllamaProvider implements Provider against Ollama's native /api/chat.
+// Compared with the OpenAI-compat endpoint it controls keep_alive and
+// num_ctx, discovers installed models (with their real context window) into
+// the ModelRegistry, and pulls missing models on demand.
+type OllamaProvider struct {
+	name         string
+	apiKey       string // optional Bearer token for authenticated proxies
+	apiBase      string
+	defaultModel string
+	client       *http.Client
+	retryConfig  RetryConfig
+	registry     ModelRegistry // discovered models are registered here (nil = skip)
+
+	keepAlive string // e.g. "5m", "-1" (keep loaded); empty = server default
+	numCtx    int    // context length to request; 0 = Modelfile/server default
+	autoPull  bool   // pull a missing model in the background on "model not found"
+
+	pulls *OllamaPullTracker
+}
+
+// NewOllamaProvider creates a native Ollama provider. apiBase may be empty
+// (OllamaDefaultAPIBase) or the stored OpenAI-compat base ending in /v1.
+func NewOllamaProvider(name, apiBase, defaultModel string) *OllamaProvider {
+	if name == "" {
+		name = "ollama"
+	}
+	if defaultModel == "" {
+		defaultModel = OllamaDefaultModel
+	}
+	return &OllamaProvider{
+		name:         name,
+		apiBase:      NormalizeOllamaAPIBase(apiBase),
+		defaultModel: defaultModel,
+		client:       NewDefaultHTTPClient(),
+		retryConfig:  DefaultRetryConfig(),
+		autoPull:     true,
+		pulls:        NewOllamaPullTracker(),
+	}
+}
+
+// WithAPIKey sets a Bearer token (Ollama behind an authenticating proxy).
+func (p *OllamaProvider) WithAPIKey(key string) *OllamaProvider {
+	p.apiKey = key
+	return p
+}
+
+// WithHTTPClient replaces the HTTP client (tests, custom proxies).
+func (p *OllamaProvider) WithHTTPClient(c *http.Client) *OllamaProvider {
+	if c != nil {
+		p.client = c
+	}
+	return p
+}
+
+// WithRetryConfig overrides the retry policy.
+func (p *OllamaProvider) WithRetryConfig(cfg RetryConfig) *OllamaProvider {
+	p.retryConfig = cfg
+	return p
+}
+
+// WithRegistry sets the ModelRegistry that discovered models are written to.
+// Registries that accept forward-compat resolvers also get the provider as
+// one, so models installed after discovery resolve on first use.
+func (p *OllamaProvider) WithRegistry(r ModelRegistry) *OllamaProvider {
+	p.registry = r
+	if rr, ok := r.(interface {
+		RegisterResolver(string, ForwardCompatResolver)
+	}); ok {
+		rr.RegisterResolver(p.name, p)
+	}
+	return p
+}
+
+// WithKeepAlive sets how long the server keeps a model loaded after a request
+// (Ollama duration string, e.g. "10m"; "-1" keeps it loaded indefinitely).
+func (p *OllamaProvider) WithKeepAlive(d string) *OllamaProvider {
+	p.keepAlive = strings.TrimSpace(d)
+	return p
+}
+
+// WithNumCtx sets the context length requested for every call. 0 keeps the
+// Modelfile or server default.
+func (p *OllamaProvider) WithNumCtx(n int) *OllamaProvider {
+	if n > 0 {
+		p.numCtx = n
+	}
+	return p
+}
+
+// WithAutoPull toggles pulling missing models on demand (default on).
+func (p *OllamaProvider) WithAutoPull(enabled bool) *OllamaProvider {
+	p.autoPull = enabled
+	return p
+}
+
+func (p *OllamaProvider) Name() string           { return p.name }
+func (p *OllamaProvider) DefaultModel() string   { return p.defaultModel }
+func (p *OllamaProvider) SupportsThinking() bool { return true }
+
+// APIBase returns the native API root.
+func (p *OllamaProvider) APIBase() string { return p.apiBase }
+
+// Pulls exposes model pull progress.
+func (p *OllamaProvider) Pulls() *OllamaPullTracker { return p.pulls }
+
+// Capabilities implements CapabilitiesAware. Vision and thinking depend on
+// the model; per-model context windows come from the ModelRegistry.
+func (p *OllamaProvider) Capabilities() ProviderCapabilities {
+	window := p.numCtx
+	if window == 0 {
+		window = ollamaDefaultNumCtx
+	}
+	return ProviderCapabilities{
+		Streaming:        true,
+		ToolCalling:      true,
+		StreamWithTools:  true,
+		Thinking:         true,
+		Vision:           true,
+		StructuredOutput: true,
+		MaxContextWindow: window,
+		TokenizerID:      "cl100k_base",
+	}
+}
+
+func (p *OllamaProvider) resolveModel(model string) string {
+	if model == "" {
+		return p.defaultModel
+	}
+	return model
+}
+
+func (p *OllamaProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	model := p.resolveModel(req.Model)
+	body := p.buildRequestBody(model, req, false)
+
+	resp, err := RetryDo(ctx, p.retryConfig, func() (*ChatResponse, error) {
+		respBody, err := p.doRequest(ctx, model, body)
+		if err != nil {
+			return nil, err
+		}
+		defer respBody.Close()
+
+		var parsed ollamaChatResponse
+		if err := json.NewDecoder(respBody).Decode(&parsed); err != nil {
+			return nil, fmt.Errorf("%s: decode response: %w", p.name, err)
+		}
+		if parsed.Error != "" {
+			return nil, fmt.Errorf("%s: %s", p.name, parsed.Error)
+		}
+		acc := ollamaAccumulator{result: &ChatResponse{}}
+		acc.add(&parsed)
+		acc.finish(&parsed)
+		return acc.result, nil
+	})
+	if resp != nil {
+		if strip, _ := req.Options[OptStripThinking].(bool); strip {
+			resp.Thinking = ""
+		}
+	}
+	return resp, err
+}
+
+// ChatStream reads /api/chat NDJSON. Each line carries only new content;
+// tool calls arrive whole and the final (done) line carries token counts.
+func (p *OllamaProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	model := p.resolveModel(req.Model)
+	stripThinking, _ := req.Options[OptStripThinking].(bool)
+	body := p.buildRequestBody(model, req, true)
+
+	// Retry only the connection phase; once streaming starts, no retry.
+	respBody, err := RetryDo(ctx, p.retryConfig, func() (io.ReadCloser, error) {
+		return p.doRequest(ctx, model, body)
+	})
+	if err != nil {
+		return nil, err
+	}
+	cb := NewCtxBody(ctx, respBody)
+	defer cb.Close()
+
+	acc := ollamaAccumulator{result: &ChatResponse{}}
+	var last ollamaChatResponse
+	sc := bufio.NewScanner(cb)
+	sc.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+	for sc.Scan() {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var chunk ollamaChatResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			continue
+		}
+		if chunk.Error != "" {
+			return nil, fmt.Errorf("%s: %s", p.name, chunk.Error)
+		}
+		content, thinking := acc.add(&chunk)
+		if onChunk != nil {
+			if thinking != "" && !stripThinking {
+				onChunk(StreamChunk{Thinking: thinking})
+			}
+			if content != "" {
+				onChunk(StreamChunk{Content: content})
+			}
+		}
+		if chunk.Done {
+			last = chunk
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%s: stream read error: %w", p.name, err)
+	}
+
+	acc.finish(&last)
+	if stripThinking {
+		acc.result.Thinking = ""
+	}
+	if onChunk != nil {
+		onChunk(StreamChunk{Done: true})
+	}
+	return acc.result, nil
+}
+
+// doRequest POSTs to /api/chat. A "model not found" 404 starts a background
+// pull (when enabled) and is returned as-is so model fallback can move on.
+func (p *OllamaProvider) doRequest(ctx context.Context, model string, body any) (io.ReadCloser, error) {
+	resp, err := p.post(ctx, "/api/chat", body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		msg := ollamaErrorMessage(respBody)
+		if resp.StatusCode == http.StatusNotFound && p.autoPull && strings.Contains(msg, "not found") {
+			st := p.StartPull(model)
+			msg = fmt.Sprintf("%s; pulling it in the background (%s)", msg, st.Status)
+		}
+		return nil, &HTTPError{
+			Status:     resp.StatusCode,
+			Body:       fmt.Sprintf("%s: %s", p.name, msg),
+			RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return resp.Body, nil
+}
+
+// post sends a JSON body to a native endpoint and returns the raw response.
+func (p *OllamaProvider) post(ctx context.Context, path string, body any) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: marshal request: %w", p.name, err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.apiBase+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: create request: %w", p.name, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	p.setAuth(httpReq)
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s: request failed: %w", p.name, err)
+	}
+	return resp, nil
+}
+
+func (p *OllamaProvider) setAuth(httpReq *http.Request) {
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+}
+
+// ollamaErrorMessage extracts {"error": "..."} or returns the raw body.
+func ollamaErrorMessage(body []byte) string {
+	var e struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &e) == nil && e.Error != "" {
+		return e.Error
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// ollamaToolCallID generates an ID for a tool call; Ollama doesn't assign one.
+func ollamaToolCallID(id string) string {
+	if id != "" {
+		return id
+	}
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "call_" + hex.EncodeToString(b)
+}
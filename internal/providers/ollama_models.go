@@ -0,0 +1,348 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OllamaModel is an installed model from /api/tags, enriched by /api/show
+// when discovered.
+type OllamaModel struct {
+	Name              string `json:"name"`
+	Family            string `json:"family,omitempty"`
+	ParameterSize     string `json:"parameter_size,omitempty"`
+	QuantizationLevel string `json:"quantization_level,omitempty"`
+	Size              int64  `json:"size,omitempty"`
+}
+
+// OllamaModelInfo is the subset of /api/show used for discovery.
+type OllamaModelInfo struct {
+	ContextLength int      // model's trained context (<arch>.context_length)
+	NumCtx        int      // num_ctx from the Modelfile parameters, 0 if unset
+	Capabilities  []string // e.g. completion, tools, vision, thinking
+}
+
+// Health checks that the server answers /api/version and returns its version.
+func (p *OllamaProvider) Health(ctx context.Context) (string, error) {
+	data, err := p.get(ctx, "/api/version")
+	if err != nil {
+		return "", err
+	}
+	var v struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return "", fmt.Errorf("%s: decode version: %w", p.name, err)
+	}
+	return v.Version, nil
+}
+
+// ListModels returns the installed models from /api/tags.
+func (p *OllamaProvider) ListModels(ctx context.Context) ([]OllamaModel, error) {
+	data, err := p.get(ctx, "/api/tags")
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Models []struct {
+			Name    string `json:"name"`
+			Size    int64  `json:"size"`
+			Details struct {
+				Family            string `json:"family"`
+				ParameterSize     string `json:"parameter_size"`
+				QuantizationLevel string `json:"quantization_level"`
+			} `json:"details"`
+		} `json:"models"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("%s: decode tags: %w", p.name, err)
+	}
+	models := make([]OllamaModel, 0, len(result.Models))
+	for _, m := range result.Models {
+		models = append(models, OllamaModel{
+			Name:              m.Name,
+			Family:            m.Details.Family,
+			ParameterSize:     m.Details.ParameterSize,
+			QuantizationLevel: m.Details.QuantizationLevel,
+			Size:              m.Size,
+		})
+	}
+	return models, nil
+}
+
+// ShowModel reads a model's context length, Modelfile num_ctx and capabilities.
+func (p *OllamaProvider) ShowModel(ctx context.Context, model string) (*OllamaModelInfo, error) {
+	resp, err := p.post(ctx, "/api/show", map[string]any{"model": model})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPError{Status: resp.StatusCode, Body: fmt.Sprintf("%s: %s", p.name, ollamaErrorMessage(data))}
+	}
+	var show struct {
+		Parameters   string         `json:"parameters"`
+		ModelInfo    map[string]any `json:"model_info"`
+		Capabilities []string       `json:"capabilities"`
+	}
+	if err := json.Unmarshal(data, &show); err != nil {
+		return nil, fmt.Errorf("%s: decode show: %w", p.name, err)
+	}
+	info := &OllamaModelInfo{Capabilities: show.Capabilities}
+	for key, v := range show.ModelInfo {
+		if strings.HasSuffix(key, ".context_length") {
+			if n, ok := v.(float64); ok {
+				info.ContextLength = int(n)
+			}
+		}
+	}
+	for line := range strings.SplitSeq(show.Parameters, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "num_ctx" {
+			info.NumCtx, _ = strconv.Atoi(fields[1])
+		}
+	}
+	return info, nil
+}
+
+// EffectiveContextWindow is the num_ctx the server will run the model with:
+// the provider's num_ctx, else the Modelfile's, else Ollama's default, capped
+// at the model's trained context length.
+func (p *OllamaProvider) EffectiveContextWindow(info *OllamaModelInfo) int {
+	n := p.numCtx
+	if n == 0 {
+		n = info.NumCtx
+	}
+	if n == 0 {
+		n = ollamaDefaultNumCtx
+	}
+	if info.ContextLength > 0 && n > info.ContextLength {
+		n = info.ContextLength
+	}
+	return n
+}
+
+// modelSpec builds the registry entry for a shown model.
+func (p *OllamaProvider) modelSpec(model string, info *OllamaModelInfo) ModelSpec {
+	return ModelSpec{
+		ID:            model,
+		Provider:      p.name,
+		ContextWindow: p.EffectiveContextWindow(info),
+		Reasoning:     slices.Contains(info.Capabilities, "thinking"),
+		Vision:        slices.Contains(info.Capabilities, "vision"),
+		TokenizerID:   "cl100k_base",
+	}
+}
+
+// DiscoverModels lists installed models and registers each one's spec in the
+// ModelRegistry. Models whose /api/show fails are skipped.
+func (p *OllamaProvider) DiscoverModels(ctx context.Context) ([]ModelSpec, error) {
+	models, err := p.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	specs := make([]ModelSpec, 0, len(models))
+	for _, m := range models {
+		info, err := p.ShowModel(ctx, m.Name)
+		if err != nil {
+			slog.Debug("ollama: show failed", "provider", p.name, "model", m.Name, "error", err)
+			continue
+		}
+		spec := p.modelSpec(m.Name, info)
+		if p.registry != nil {
+			p.registry.Register(spec)
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// ResolveForwardCompat implements ForwardCompatResolver: models installed after
+// discovery are looked up with /api/show on first use.
+func (p *OllamaProvider) ResolveForwardCompat(modelID string, _ ModelRegistry) *ModelSpec {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	info, err := p.ShowModel(ctx, modelID)
+	if err != nil {
+		return nil
+	}
+	spec := p.modelSpec(modelID, info)
+	return &spec
+}
+
+// get performs a GET against a native endpoint and returns the body on 200.
+func (p *OllamaProvider) get(ctx context.Context, path string) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiBase+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: create request: %w", p.name, err)
+	}
+	p.setAuth(httpReq)
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s: request failed: %w", p.name, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: read response: %w", p.name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPError{Status: resp.StatusCode, Body: fmt.Sprintf("%s: %s", p.name, ollamaErrorMessage(data))}
+	}
+	return data, nil
+}
+
+// ollamaPullTimeout bounds one model download.
+const ollamaPullTimeout = 2 * time.Hour
+
+// OllamaPullStatus is the progress of one model pull.
+type OllamaPullStatus struct {
+	Model     string    `json:"model"`
+	Status    string    `json:"status"` // Ollama's status line, e.g. "pulling manifest", "success"
+	Completed int64     `json:"completed,omitempty"`
+	Total     int64     `json:"total,omitempty"`
+	Done      bool      `json:"done"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OllamaPullTracker records pull progress per model. Finished pulls stay
+// listed for an hour so UIs can show the outcome.
+type OllamaPullTracker struct {
+	mu    sync.Mutex
+	pulls map[string]*OllamaPullStatus
+}
+
+func NewOllamaPullTracker() *OllamaPullTracker {
+	return &OllamaPullTracker{pulls: make(map[string]*OllamaPullStatus)}
+}
+
+// List returns a snapshot of recent pulls, sorted by model.
+func (t *OllamaPullTracker) List() []OllamaPullStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]OllamaPullStatus, 0, len(t.pulls))
+	for model, st := range t.pulls {
+		if st.Done && time.Since(st.UpdatedAt) > time.Hour {
+			delete(t.pulls, model)
+			continue
+		}
+		out = append(out, *st)
+	}
+	slices.SortFunc(out, func(a, b OllamaPullStatus) int { return strings.Compare(a.Model, b.Model) })
+	return out
+}
+
+// begin registers a pull unless one is already running. ok is false (with the
+// running status) when it is.
+func (t *OllamaPullTracker) begin(model string) (OllamaPullStatus, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if st, exists := t.pulls[model]; exists && !st.Done {
+		return *st, false
+	}
+	st := &OllamaPullStatus{Model: model, Status: "queued", UpdatedAt: time.Now()}
+	t.pulls[model] = st
+	return *st, true
+}
+
+func (t *OllamaPullTracker) update(model string, fn func(*OllamaPullStatus)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if st, ok := t.pulls[model]; ok {
+		fn(st)
+		st.UpdatedAt = time.Now()
+	}
+}
+
+// StartPull pulls model in the background (no-op if already pulling) and
+// returns its current status. On success the model is registered in the
+// ModelRegistry.
+func (p *OllamaProvider) StartPull(model string) OllamaPullStatus {
+	st, started := p.pulls.begin(model)
+	if !started {
+		return st
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), ollamaPullTimeout)
+		defer cancel()
+		err := p.Pull(ctx, model, func(prog OllamaPullStatus) {
+			p.pulls.update(model, func(s *OllamaPullStatus) {
+				s.Status, s.Completed, s.Total = prog.Status, prog.Completed, prog.Total
+			})
+		})
+		p.pulls.update(model, func(s *OllamaPullStatus) {
+			s.Done = true
+			if err != nil {
+				s.Error = err.Error()
+			}
+		})
+		if err != nil {
+			slog.Warn("ollama: pull failed", "provider", p.name, "model", model, "error", err)
+			return
+		}
+		slog.Info("ollama: pulled model", "provider", p.name, "model", model)
+		if p.registry != nil {
+			if info, err := p.ShowModel(ctx, model); err == nil {
+				p.registry.Register(p.modelSpec(model, info))
+			}
+		}
+	}()
+	return st
+}
+
+// Pull downloads model via /api/pull, reporting each progress line.
+func (p *OllamaProvider) Pull(ctx context.Context, model string, onProgress func(OllamaPullStatus)) error {
+	resp, err := p.post(ctx, "/api/pull", map[string]any{"model": model, "stream": true})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return &HTTPError{Status: resp.StatusCode, Body: fmt.Sprintf("%s: %s", p.name, ollamaErrorMessage(data))}
+	}
+	sc := bufio.NewScanner(resp.Body)
+	status := ""
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var prog struct {
+			Status    string `json:"status"`
+			Completed int64  `json:"completed"`
+			Total     int64  `json:"total"`
+			Error     string `json:"error"`
+		}
+		if err := json.Unmarshal(line, &prog); err != nil {
+			continue
+		}
+		if prog.Error != "" {
+			return fmt.Errorf("%s: pull %s: %s", p.name, model, prog.Error)
+		}
+		status = prog.Status
+		if onProgress != nil {
+			onProgress(OllamaPullStatus{Model: model, Status: prog.Status, Completed: prog.Completed, Total: prog.Total})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%s: pull %s: %w", p.name, model, err)
+	}
+	if status != "success" {
+		return fmt.Errorf("%s: pull %s ended without success (last status %q)", p.name, model, status)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package providers
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type ollamaChatResponse struct {
+	Message struct {
+		Role      string           `json:"role"`
+		Content   string           `json:"content"`
+		Thinking  string           `json:"thinking"`
+		ToolCalls []ollamaToolCall `json:"tool_calls"`
+	} `json:"message"`
+	Done            bool   `json:"done"`
+	DoneReason      string `json:"done_reason"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+	Error           string `json:"error"`
+}
+
+type ollamaToolCall struct {
+	ID       string `json:"id,omitempty"`
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+// buildRequestBody maps a ChatRequest onto /api/chat. Tool results carry
+// tool_name (Ollama has no call IDs); images go inline as base64.
+func (p *OllamaProvider) buildRequestBody(model string, req ChatRequest, stream bool) map[string]any {
+	toolNames := buildToolNameIndex(req.Messages)
+	msgs := make([]map[string]any, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		m := map[string]any{"role": msg.Role, "content": msg.Content}
+		switch msg.Role {
+		case "system":
+			m["content"] = strings.ReplaceAll(msg.Content, CacheBoundaryMarker, "")
+		case "user":
+			var images []string
+			for _, img := range msg.Images {
+				if img.Data != "" {
+					images = append(images, img.Data)
+				}
+			}
+			if len(images) > 0 {
+				m["images"] = images
+			}
+		case "assistant":
+			if msg.Thinking != "" {
+				m["thinking"] = msg.Thinking
+			}
+			if len(msg.ToolCalls) > 0 {
+				calls := make([]map[string]any, 0, len(msg.ToolCalls))
+				for _, tc := range msg.ToolCalls {
+					args := tc.Arguments
+					if args == nil {
+						args = map[string]any{}
+					}
+					calls = append(calls, map[string]any{
+						"function": map[string]any{"name": tc.Name, "arguments": args},
+					})
+				}
+				m["tool_calls"] = calls
+			}
+		case "tool":
+			if name := toolNames[msg.ToolCallID]; name != "" {
+				m["tool_name"] = name
+			}
+		}
+		msgs = append(msgs, m)
+	}
+
+	body := map[string]any{
+		"model":    model,
+		"messages": msgs,
+		"stream":   stream,
+	}
+	if p.keepAlive != "" {
+		body["keep_alive"] = p.keepAlive
+	}
+
+	var tools []map[string]any
+	for _, t := range CleanToolSchemas(p.name, req.Tools) {
+		if t.Type != "function" || t.Function == nil {
+			continue
+		}
+		tools = append(tools, map[string]any{
+			"type": "function",
+			"function": map[string]any{
+				"name":        t.Function.Name,
+				"description": t.Function.Description,
+				"parameters":  t.Function.Parameters,
+			},
+		})
+	}
+	if len(tools) > 0 {
+		body["tools"] = tools
+	}
+
+	if rf := req.ResponseFormat; rf != nil {
+		if rf.HasSchema() {
+			body["format"] = rf.Schema
+		} else {
+			body["format"] = "json"
+		}
+	}
+
+	if level, ok := req.Options[OptThinkingLevel].(string); ok && level != "" {
+		switch {
+		case level == "off":
+			body["think"] = false
+		case strings.Contains(model, "gpt-oss"):
+			// gpt-oss takes an effort level instead of a boolean.
+			body["think"] = level
+		default:
+			body["think"] = true
+		}
+	}
+
+	options := map[string]any{}
+	if p.numCtx > 0 {
+		options["num_ctx"] = p.numCtx
+	}
+	if v, ok := req.Options[OptMaxTokens]; ok {
+		options["num_predict"] = v
+	}
+	if v, ok := req.Options[OptTemperature]; ok {
+		options["temperature"] = v
+	}
+	if len(options) > 0 {
+		body["options"] = options
+	}
+	return body
+}
+
+// ollamaAccumulator folds response lines (one, or a stream) into a ChatResponse.
+type ollamaAccumulator struct {
+	result *ChatResponse
+}
+
+// add appends one line and returns its new content and thinking text.
+func (a *ollamaAccumulator) add(chunk *ollamaChatResponse) (content, thinking string) {
+	msg := chunk.Message
+	a.result.Content += msg.Content
+	a.result.Thinking += msg.Thinking
+	for _, tc := range msg.ToolCalls {
+		call := ToolCall{ID: ollamaToolCallID(tc.ID), Name: tc.Function.Name, Arguments: map[string]any{}}
+		raw := tc.Function.Arguments
+		// Some models emit arguments as a JSON-encoded string.
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			raw = json.RawMessage(s)
+		}
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &call.Arguments); err != nil {
+				call.ParseError = err.Error()
+			}
+		}
+		a.result.ToolCalls = append(a.result.ToolCalls, call)
+	}
+	return msg.Content, msg.Thinking
+}
+
+// finish sets the finish reason and usage from the final (done) line.
+func (a *ollamaAccumulator) finish(last *ollamaChatResponse) {
+	switch {
+	case len(a.result.ToolCalls) > 0:
+		a.result.FinishReason = "tool_calls"
+	case last.DoneReason == "length":
+		a.result.FinishReason = "length"
+	default:
+		a.result.FinishReason = "stop"
+	}
+	if last.PromptEvalCount > 0 || last.EvalCount > 0 {
+		a.result.Usage = &Usage{
+			PromptTokens:     last.PromptEvalCount,
+			CompletionTokens: last.EvalCount,
+			TotalTokens:      last.PromptEvalCount + last.EvalCount,
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestOllamaServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request, body map[string]any)) (*OllamaProvider, *httptest.Server) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(raw, &body)
+		handler(w, r, body)
+	}))
+	t.Cleanup(srv.Close)
+	p := NewOllamaProvider("ollama", srv.URL+"/v1", "llama3.3").
+		WithRetryConfig(RetryConfig{Attempts: 1})
+	return p, srv
+}
+
+func TestNormalizeOllamaAPIBase(t *testing.T) {
+	cases := map[string]string{
+		"":                             OllamaDefaultAPIBase,
+		"http://host:11434/v1":         "http://host:11434",
+		"http://host:11434/v1/":        "http://host:11434",
+		" http://host:11434/ ":         "http://host:11434",
+		"https://proxy.example/ollama": "https://proxy.example/ollama",
+	}
+	for in, want := range cases {
+		if got := NormalizeOllamaAPIBase(in); got != want {
+			t.Errorf("NormalizeOllamaAPIBase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestOllamaProvider_ChatToolsAndJSONMode(t *testing.T) {
+	p, _ := newTestOllamaServer(t, func(w http.ResponseWriter, r *http.Request, body map[string]any) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if body["stream"] != false || body["format"] != "json" || body["keep_alive"] != "10m" {
+			t.Errorf("body = %v", body)
+		}
+		if opts, _ := body["options"].(map[string]any); opts["num_ctx"] != float64(16384) {
+			t.Errorf("options = %v", body["options"])
+		}
+		if tools, _ := body["tools"].([]any); len(tools) != 1 {
+			t.Errorf("tools = %v", body["tools"])
+		}
+		msgs, _ := body["messages"].([]any)
+		last, _ := msgs[len(msgs)-1].(map[string]any)
+		if last["role"] != "tool" || last["tool_name"] != "get_weather" {
+			t.Errorf("tool result message = %v", last)
+		}
+		_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"","tool_calls":[
+			{"function":{"name":"get_weather","arguments":{"city":"Hanoi"}}},
+			{"function":{"name":"get_time","arguments":"{\"tz\":\"UTC\"}"}}]},
+			"done":true,"done_reason":"stop","prompt_eval_count":30,"eval_count":12}`))
+	})
+	p.WithKeepAlive("10m").WithNumCtx(16384)
+
+	resp, err := p.Chat(context.Background(), ChatRequest{
+		Messages: []Message{
+			{Role: "user", Content: "Weather?"},
+			{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Name: "get_weather", Arguments: map[string]any{"city": "Hanoi"}}}},
+			{Role: "tool", ToolCallID: "call_1", Content: "sunny"},
+		},
+		Tools: []ToolDefinition{{Type: "function", Function: &ToolFunctionSchema{
+			Name: "get_weather", Parameters: map[string]any{"type": "object"},
+		}}},
+		ResponseFormat: &ResponseFormat{Type: "json_object"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.FinishReason != "tool_calls" || len(resp.ToolCalls) != 2 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if resp.ToolCalls[0].ID == "" || resp.ToolCalls[0].Arguments["city"] != "Hanoi" || resp.ToolCalls[1].Arguments["tz"] != "UTC" {
+		t.Errorf("ToolCalls = %+v", resp.ToolCalls)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens != 30 || resp.Usage.CompletionTokens != 12 || resp.Usage.TotalTokens != 42 {
+		t.Errorf("Usage = %+v", resp.Usage)
+	}
+}
+
+func TestOllamaProvider_ChatStream(t *testing.T) {
+	p, _ := newTestOllamaServer(t, func(w http.ResponseWriter, r *http.Request, body map[string]any) {
+		if body["think"] != true {
+			t.Errorf("think = %v", body["think"])
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, line := range []string{
+			`{"message":{"role":"assistant","content":"","thinking":"hmm"},"done":false}`,
+			`{"message":{"role":"assistant","content":"Hel"},"done":false}`,
+			`{"message":{"role":"assistant","content":"lo"},"done":false}`,
+			`{"message":{"role":"assistant","content":""},"done":true,"done_reason":"length","prompt_eval_count":5,"eval_count":2}`,
+		} {
+			_, _ = io.WriteString(w, line+"\n")
+		}
+	})
+
+	var content, thinking strings.Builder
+	done := false
+	resp, err := p.ChatStream(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "hi"}},
+		Options:  map[string]any{OptThinkingLevel: "medium"},
+	}, func(c StreamChunk) {
+		content.WriteString(c.Content)
+		thinking.WriteString(c.Thinking)
+		done = done || c.Done
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "Hello" || content.String() != "Hello" || thinking.String() != "hmm" || !done {
+		t.Errorf("resp = %+v, streamed = %q/%q, done = %v", resp, content.String(), thinking.String(), done)
+	}
+	if resp.FinishReason != "length" || resp.Usage.TotalTokens != 7 {
+		t.Errorf("FinishReason = %q, Usage = %+v", resp.FinishReason, resp.Usage)
+	}
+}
+
+func TestOllamaProvider_DiscoverModelsRegistersContextWindow(t *testing.T) {
+	p, _ := newTestOllamaServer(t, func(w http.ResponseWriter, r *http.Request, body map[string]any) {
+		switch r.URL.Path {
+		case "/api/tags":
+			_, _ = w.Write([]byte(`{"models":[{"name":"qwen3:8b","details":{"family":"qwen3"}},{"name":"broken:latest"}]}`))
+		case "/api/show":
+			if body["model"] != "qwen3:8b" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write([]byte(`{"parameters":"stop \"<|im_end|>\"\nnum_ctx 32768","model_info":{"qwen3.context_length":40960},"capabilities":["completion","tools","thinking"]}`))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	})
+	reg := NewInMemoryRegistry()
+	p.WithRegistry(reg)
+
+	specs, err := p.DiscoverModels(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) != 1 {
+		t.Fatalf("specs = %+v", specs)
+	}
+	spec := reg.Resolve("ollama", "qwen3:8b")
+	if spec == nil || spec.ContextWindow != 32768 || !spec.Reasoning || spec.Vision {
+		t.Fatalf("registered spec = %+v", spec)
+	}
+
+	// A configured num_ctx wins but is capped at the trained context length.
+	p.WithNumCtx(65536)
+	if got := p.EffectiveContextWindow(&OllamaModelInfo{ContextLength: 40960, NumCtx: 32768}); got != 40960 {
+		t.Errorf("EffectiveContextWindow = %d, want 40960", got)
+	}
+}
+
+func TestOllamaProvider_MissingModelStartsPull(t *testing.T) {
+	pulled := make(chan struct{})
+	p, _ := newTestOllamaServer(t, func(w http.ResponseWriter, r *http.Request, body map[string]any) {
+		switch r.URL.Path {
+		case "/api/chat":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"model \"phi4\" not found, try pulling it first"}`))
+		case "/api/pull":
+			if body["model"] != "phi4" {
+				t.Errorf("pull model = %v", body["model"])
+			}
+			for _, line := range []string{
+				`{"status":"pulling manifest"}`,
+				`{"status":"pulling abc","total":100,"completed":100}`,
+				`{"status":"success"}`,
+			} {
+				_, _ = io.WriteString(w, line+"\n")
+			}
+			close(pulled)
+		case "/api/show":
+			_, _ = w.Write([]byte(`{"model_info":{"phi3.context_length":16384},"capabilities":["completion"]}`))
+		}
+	})
+	reg := NewInMemoryRegistry()
+	p.WithRegistry(reg)
+
+	_, err := p.Chat(context.Background(), ChatRequest{Model: "phi4", Messages: []Message{{Role: "user", Content: "hi"}}})
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Status != http.StatusNotFound || !strings.Contains(httpErr.Body, "pulling it in the background") {
+		t.Fatalf("err = %v", err)
+	}
+
+	select {
+	case <-pulled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("pull was not started")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		pulls := p.Pulls().List()
+		if len(pulls) == 1 && pulls[0].Done {
+			if pulls[0].Error != "" || pulls[0].Status != "success" {
+				t.Fatalf("pull = %+v", pulls[0])
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pull did not finish: %+v", pulls)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if spec := reg.Resolve("ollama", "phi4"); spec == nil || spec.ContextWindow != ollamaDefaultNumCtx {
+		t.Fatalf("pulled model spec = %+v", spec)
+	}
+}
@@ -14,15 +14,17 @@ type OpenAIProvider struct {
 	chatPath     string // defaults to "/chat/completions"
 	authPrefix   string // auth header prefix, defaults to "Bearer " if empty
 	defaultModel string
-	providerType string // DB provider_type (e.g. "gemini_native", "openai", "minimax_native")
-	siteURL      string // optional site URL for provider identification (e.g. OpenRouter HTTP-Referer)
-	siteTitle    string // optional site title for provider identification (e.g. OpenRouter X-Title)
+	providerType string            // DB provider_type (e.g. "gemini_native", "openai", "minimax_native")
+	siteURL      string            // optional site URL for provider identification (e.g. OpenRouter HTTP-Referer)
+	siteTitle    string            // optional site title for provider identification (e.g. OpenRouter X-Title)
 	extraHeaders map[string]string // static headers set on every outgoing request (e.g. fixed User-Agent for kimi_coding)
 	client       *http.Client
 	retryConfig  RetryConfig
 	middlewares  RequestMiddleware // composed middleware chain (nil = no-op)
-	registry     ModelRegistry    // model resolution registry (nil = skip)
-	noAuthHeader bool             // when true, doRequest() skips setting Authorization (e.g. Vertex OAuth transport injects its own)
+	registry     ModelRegistry     // model resolution registry (nil = skip)
+	noAuthHeader bool              // when true, doRequest() skips setting Authorization (e.g. Vertex OAuth transport injects its own)
+	batchAPI     bool              // endpoint serves the Files + Batches API even though it isn't api.openai.com
+	keyPool      *APIKeyPool       // nil = single API key
 }
 
 func NewOpenAIProvider(name, apiKey, apiBase, defaultModel string) *OpenAIProvider {
@@ -120,6 +122,15 @@ func (p *OpenAIProvider) WithHTTPClient(c *http.Client) *OpenAIProvider {
 	return p
 }
 
+func (p *OpenAIProvider) setKeyPool(pool *APIKeyPool) {
+	if p.noAuthHeader {
+		return // the transport authenticates; there is no API key to rotate
+	}
+	header, prefix := p.authHeader()
+	p.keyPool = pool
+	p.client = pool.WrapClient(p.client, header, prefix)
+}
+
 // WithoutAuthHeader disables the Authorization header in doRequest(). Used by Vertex where
 // the oauth2.Transport injects Authorization itself.
 func (p *OpenAIProvider) WithoutAuthHeader() *OpenAIProvider {
@@ -133,6 +144,7 @@ func (p *OpenAIProvider) SupportsThinking() bool { return true }
 func (p *OpenAIProvider) APIKey() string         { return p.apiKey }
 func (p *OpenAIProvider) APIBase() string        { return p.apiBase }
 func (p *OpenAIProvider) AuthPrefix() string     { return p.authPrefix }
+func (p *OpenAIProvider) KeyPool() *APIKeyPool   { return p.keyPool }
 func (p *OpenAIProvider) ProviderType() string   { return p.providerType }
 
 // Capabilities implements CapabilitiesAware for pipeline code-path selection.
@@ -144,6 +156,7 @@ func (p *OpenAIProvider) Capabilities() ProviderCapabilities {
 		Thinking:         true,
 		Vision:           true,
 		CacheControl:     false,
+		StructuredOutput: p.supportsJSONSchema() || p.dashScopePassthroughKeys(),
 		MaxContextWindow: 128_000,
 		TokenizerID:      "o200k_base",
 	}
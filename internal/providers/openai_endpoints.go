@@ -35,6 +35,15 @@ func (p *OpenAIProvider) isTogetherEndpoint() bool {
 	return false
 }
 
+// supportsJSONSchema returns true for endpoints that enforce response_format
+// json_schema. Other OpenAI-compatible backends (Groq, OpenRouter, local
+// servers, ...) reject or ignore it, so they get json_object mode with the
+// schema in the system prompt instead.
+func (p *OpenAIProvider) supportsJSONSchema() bool {
+	b := strings.ToLower(p.apiBase)
+	return isOpenAINativeEndpoint(b) || strings.Contains(b, "azure.com") || strings.Contains(b, "generativelanguage")
+}
+
 // isDashScopeAPIBase returns true for Alibaba DashScope OpenAI-compatible endpoints.
 func isDashScopeAPIBase(apiBase string) bool {
 	return strings.Contains(strings.ToLower(apiBase), "dashscope")
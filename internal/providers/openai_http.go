@@ -26,30 +26,7 @@ func (p *OpenAIProvider) doRequest(ctx context.Context, body any) (io.ReadCloser
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	switch {
-	case p.noAuthHeader:
-		// Caller-supplied transport (e.g. Vertex oauth2.Transport) injects Authorization itself.
-	case strings.Contains(strings.ToLower(p.apiBase), "azure.com"):
-		httpReq.Header.Set("api-key", p.apiKey)
-	default:
-		prefix := p.authPrefix
-		if prefix == "" {
-			prefix = "Bearer "
-		}
-		httpReq.Header.Set("Authorization", prefix+p.apiKey)
-	}
-	// OpenRouter identification headers for rankings/analytics
-	if p.siteURL != "" {
-		httpReq.Header.Set("HTTP-Referer", p.siteURL)
-	}
-	if p.siteTitle != "" {
-		httpReq.Header.Set("X-Title", p.siteTitle)
-	}
-	// Static per-provider headers (e.g. fixed User-Agent for kimi_coding).
-	// Applied after the standard headers so providers can override them if needed.
-	for k, v := range p.extraHeaders {
-		httpReq.Header.Set(k, v)
-	}
+	p.setRequestHeaders(httpReq)
 
 	resp, err := p.client.Do(httpReq)
 	if err != nil {
@@ -70,6 +47,38 @@ func (p *OpenAIProvider) doRequest(ctx context.Context, body any) (io.ReadCloser
 	return resp.Body, nil
 }
 
+// authHeader returns the header the API key goes in and its value prefix.
+func (p *OpenAIProvider) authHeader() (header, prefix string) {
+	if strings.Contains(strings.ToLower(p.apiBase), "azure.com") {
+		return "api-key", ""
+	}
+	if p.authPrefix != "" {
+		return "Authorization", p.authPrefix
+	}
+	return "Authorization", "Bearer "
+}
+
+// setRequestHeaders applies auth and per-provider identification headers.
+func (p *OpenAIProvider) setRequestHeaders(httpReq *http.Request) {
+	// Caller-supplied transport (e.g. Vertex oauth2.Transport) injects Authorization itself.
+	if !p.noAuthHeader {
+		header, prefix := p.authHeader()
+		httpReq.Header.Set(header, prefix+p.apiKey)
+	}
+	// OpenRouter identification headers for rankings/analytics
+	if p.siteURL != "" {
+		httpReq.Header.Set("HTTP-Referer", p.siteURL)
+	}
+	if p.siteTitle != "" {
+		httpReq.Header.Set("X-Title", p.siteTitle)
+	}
+	// Static per-provider headers (e.g. fixed User-Agent for kimi_coding).
+	// Applied after the standard headers so providers can override them if needed.
+	for k, v := range p.extraHeaders {
+		httpReq.Header.Set(k, v)
+	}
+}
+
 func (p *OpenAIProvider) parseResponse(resp *openAIResponse) *ChatResponse {
 	result := &ChatResponse{FinishReason: "stop"}
 
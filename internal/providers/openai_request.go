@@ -185,6 +185,18 @@ func (p *OpenAIProvider) buildRequestBody(model string, req ChatRequest, stream
 		}
 	}
 
+	// Structured output. Endpoints that enforce json_schema get it; DashScope
+	// (Qwen) and other OpenAI-compatible backends only get json_object mode, so
+	// the schema travels in the system prompt there.
+	if rf := req.ResponseFormat; rf != nil {
+		if p.supportsJSONSchema() {
+			body["response_format"] = rf.openAIResponseFormat()
+		} else {
+			body["response_format"] = map[string]any{"type": ResponseFormatJSONObject}
+			body["messages"] = appendSystemInstruction(msgs, rf.promptInstruction())
+		}
+	}
+
 	// Together returns HTTP 400 on some requests when stream_options is present.
 	if stream && !p.isTogetherEndpoint() {
 		body["stream_options"] = map[string]any{
@@ -387,3 +399,23 @@ func openAIWireAssistantReasoningContent(model string) bool {
 	}
 	return false
 }
+
+// appendSystemInstruction appends instr to the first system/developer wire message,
+// or prepends a new system message when none exists. json_object mode requires
+// the word "JSON" to appear in the prompt, which the instruction guarantees. A
+// prompt that already carries instr (ChatStructured's fallback) is left as is.
+func appendSystemInstruction(msgs []map[string]any, instr string) []map[string]any {
+	for _, m := range msgs {
+		role, _ := m["role"].(string)
+		if role != "system" && role != "developer" {
+			continue
+		}
+		if content, ok := m["content"].(string); ok {
+			if !strings.Contains(content, instr) {
+				m["content"] = strings.TrimRight(content, "\n") + "\n\n" + instr
+			}
+			return msgs
+		}
+	}
+	return append([]map[string]any{{"role": "system", "content": instr}}, msgs...)
+}
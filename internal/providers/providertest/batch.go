@@ -0,0 +1,332 @@
+package providertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// BatchReply produces the assistant text for one batched request body, or an
+// error to report that request as failed.
+type BatchReply func(body map[string]any) (string, error)
+
+// BatchServer is a stand-in for the OpenAI Files + Batches API and the
+// Anthropic Message Batches API. Batches stay in progress for PollsUntilDone
+// status reads, then complete with one result per request from Reply.
+//
+// OpenAI routes: POST /files, POST /batches, GET /batches/{id},
+// GET /files/{id}/content. Anthropic routes: POST /messages/batches,
+// GET /messages/batches/{id}, GET /messages/batches/{id}/results.
+type BatchServer struct {
+	*httptest.Server
+
+	PollsUntilDone int
+	Reply          BatchReply
+
+	mu          sync.Mutex
+	seq         int
+	files       map[string][]byte
+	batches     map[string]*fakeBatch
+	credentials []string
+}
+
+type fakeBatch struct {
+	anthropic bool
+	lines     []fakeBatchLine
+	polls     int
+	outputID  string
+}
+
+type fakeBatchLine struct {
+	CustomID string
+	Body     map[string]any
+}
+
+// NewBatchServer starts a server that echoes the last user message. Callers
+// must Close it.
+func NewBatchServer() *BatchServer {
+	s := &BatchServer{
+		Reply:   echoLastUser,
+		files:   make(map[string][]byte),
+		batches: make(map[string]*fakeBatch),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Submitted returns the number of batches created so far.
+func (s *BatchServer) Submitted() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.batches)
+}
+
+// Credentials returns the API key each request carried, in arrival order.
+func (s *BatchServer) Credentials() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.credentials...)
+}
+
+// BatchSizes returns the request count of every batch created so far.
+func (s *BatchServer) BatchSizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sizes := make([]int, 0, len(s.batches))
+	for i := 1; i <= s.seq; i++ {
+		if b, ok := s.batches[fmt.Sprintf("batch_%d", i)]; ok {
+			sizes = append(sizes, len(b.lines))
+		}
+	}
+	return sizes
+}
+
+func (s *BatchServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") == "" && r.Header.Get("x-api-key") == "" {
+		http.Error(w, `{"error":{"message":"missing credentials"}}`, http.StatusUnauthorized)
+		return
+	}
+	s.mu.Lock()
+	s.credentials = append(s.credentials, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")+r.Header.Get("x-api-key"))
+	s.mu.Unlock()
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodPost && path == "/files":
+		s.uploadFile(w, r)
+	case r.Method == http.MethodPost && path == "/batches":
+		s.createOpenAIBatch(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/batches/"):
+		s.getOpenAIBatch(w, strings.TrimPrefix(path, "/batches/"))
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/files/") && strings.HasSuffix(path, "/content"):
+		s.getFile(w, strings.TrimSuffix(strings.TrimPrefix(path, "/files/"), "/content"))
+	case r.Method == http.MethodPost && path == "/messages/batches":
+		s.createAnthropicBatch(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/messages/batches/") && strings.HasSuffix(path, "/results"):
+		s.getAnthropicResults(w, strings.TrimSuffix(strings.TrimPrefix(path, "/messages/batches/"), "/results"))
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/messages/batches/"):
+		s.getAnthropicBatch(w, strings.TrimPrefix(path, "/messages/batches/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *BatchServer) uploadFile(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("purpose") != "batch" {
+		http.Error(w, `{"error":{"message":"purpose must be batch"}}`, http.StatusBadRequest)
+		return
+	}
+	f, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer f.Close()
+	data, _ := io.ReadAll(f)
+
+	s.mu.Lock()
+	s.seq++
+	id := fmt.Sprintf("file_%d", s.seq)
+	s.files[id] = data
+	s.mu.Unlock()
+	writeJSON(w, map[string]any{"id": id, "object": "file", "purpose": "batch"})
+}
+
+func (s *BatchServer) createOpenAIBatch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		InputFileID string `json:"input_file_id"`
+		Endpoint    string `json:"endpoint"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[body.InputFileID]
+	if !ok || body.Endpoint != "/v1/chat/completions" {
+		http.Error(w, `{"error":{"message":"invalid batch"}}`, http.StatusBadRequest)
+		return
+	}
+	var lines []fakeBatchLine
+	for raw := range bytes.SplitSeq(bytes.TrimSpace(data), []byte("\n")) {
+		var line struct {
+			CustomID string         `json:"custom_id"`
+			Body     map[string]any `json:"body"`
+		}
+		if err := json.Unmarshal(raw, &line); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lines = append(lines, fakeBatchLine{CustomID: line.CustomID, Body: line.Body})
+	}
+	id := s.addBatch(&fakeBatch{lines: lines})
+	writeJSON(w, map[string]any{"id": id, "status": "validating"})
+}
+
+func (s *BatchServer) getOpenAIBatch(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.batches[id]
+	if !ok || b.anthropic {
+		http.NotFound(w, nil)
+		return
+	}
+	if !s.advance(b) {
+		writeJSON(w, map[string]any{"id": id, "status": "in_progress"})
+		return
+	}
+	if b.outputID == "" {
+		var out bytes.Buffer
+		enc := json.NewEncoder(&out)
+		for _, line := range b.lines {
+			text, err := s.Reply(line.Body)
+			if err != nil {
+				_ = enc.Encode(map[string]any{"custom_id": line.CustomID, "response": map[string]any{
+					"status_code": http.StatusBadRequest,
+					"body":        map[string]any{"error": map[string]any{"message": err.Error()}},
+				}})
+				continue
+			}
+			_ = enc.Encode(map[string]any{"custom_id": line.CustomID, "response": map[string]any{
+				"status_code": http.StatusOK,
+				"body": map[string]any{
+					"choices": []any{map[string]any{"message": map[string]any{"role": "assistant", "content": text}, "finish_reason": "stop"}},
+					"usage":   map[string]any{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+				},
+			}})
+		}
+		s.seq++
+		b.outputID = fmt.Sprintf("file_%d", s.seq)
+		s.files[b.outputID] = out.Bytes()
+	}
+	writeJSON(w, map[string]any{"id": id, "status": "completed", "output_file_id": b.outputID})
+}
+
+func (s *BatchServer) getFile(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	data, ok := s.files[id]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, nil)
+		return
+	}
+	w.Header().Set("Content-Type", "application/jsonl")
+	_, _ = w.Write(data)
+}
+
+func (s *BatchServer) createAnthropicBatch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Requests []struct {
+			CustomID string         `json:"custom_id"`
+			Params   map[string]any `json:"params"`
+		} `json:"requests"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Requests) == 0 {
+		http.Error(w, `{"error":{"message":"invalid batch"}}`, http.StatusBadRequest)
+		return
+	}
+	lines := make([]fakeBatchLine, len(body.Requests))
+	for i, req := range body.Requests {
+		lines[i] = fakeBatchLine{CustomID: req.CustomID, Body: req.Params}
+	}
+	s.mu.Lock()
+	id := s.addBatch(&fakeBatch{anthropic: true, lines: lines})
+	s.mu.Unlock()
+	writeJSON(w, map[string]any{"id": id, "type": "message_batch", "processing_status": "in_progress"})
+}
+
+func (s *BatchServer) getAnthropicBatch(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.batches[id]
+	if !ok || !b.anthropic {
+		http.NotFound(w, nil)
+		return
+	}
+	if !s.advance(b) {
+		writeJSON(w, map[string]any{"id": id, "processing_status": "in_progress"})
+		return
+	}
+	writeJSON(w, map[string]any{
+		"id":                id,
+		"processing_status": "ended",
+		"results_url":       s.URL + "/messages/batches/" + id + "/results",
+	})
+}
+
+func (s *BatchServer) getAnthropicResults(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.batches[id]
+	if !ok || !b.anthropic {
+		http.NotFound(w, nil)
+		return
+	}
+	w.Header().Set("Content-Type", "application/jsonl")
+	enc := json.NewEncoder(w)
+	for _, line := range b.lines {
+		text, err := s.Reply(line.Body)
+		if err != nil {
+			_ = enc.Encode(map[string]any{"custom_id": line.CustomID, "result": map[string]any{
+				"type":  "errored",
+				"error": map[string]any{"type": "error", "error": map[string]any{"type": "invalid_request_error", "message": err.Error()}},
+			}})
+			continue
+		}
+		_ = enc.Encode(map[string]any{"custom_id": line.CustomID, "result": map[string]any{
+			"type": "succeeded",
+			"message": map[string]any{
+				"content":     []any{map[string]any{"type": "text", "text": text}},
+				"stop_reason": "end_turn",
+				"usage":       map[string]any{"input_tokens": 10, "output_tokens": 5},
+			},
+		}})
+	}
+}
+
+// addBatch registers b and returns its ID. Caller holds s.mu.
+func (s *BatchServer) addBatch(b *fakeBatch) string {
+	s.seq++
+	id := fmt.Sprintf("batch_%d", s.seq)
+	s.batches[id] = b
+	return id
+}
+
+// advance counts a status read and reports whether b is finished. Caller holds s.mu.
+func (s *BatchServer) advance(b *fakeBatch) bool {
+	b.polls++
+	return b.polls > s.PollsUntilDone
+}
+
+// echoLastUser replies with the text of the last user message.
+func echoLastUser(body map[string]any) (string, error) {
+	msgs, _ := body["messages"].([]any)
+	for i := len(msgs) - 1; i >= 0; i-- {
+		m, _ := msgs[i].(map[string]any)
+		if m["role"] != "user" {
+			continue
+		}
+		switch c := m["content"].(type) {
+		case string:
+			return c, nil
+		case []any:
+			for _, part := range c {
+				if p, _ := part.(map[string]any); p["type"] == "text" {
+					text, _ := p["text"].(string)
+					return text, nil
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,212 @@
+package providertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+)
+
+func newBatchTestQueue(t *testing.T) *providers.BatchQueue {
+	t.Helper()
+	q := providers.NewBatchQueue(providers.BatchQueueConfig{
+		FlushInterval: 20 * time.Millisecond,
+		PollInterval:  10 * time.Millisecond,
+		MaxWait:       5 * time.Second,
+	})
+	q.Start(context.Background())
+	t.Cleanup(q.Stop)
+	return q
+}
+
+func batchUserRequest(text string) providers.ChatRequest {
+	return providers.ChatRequest{Messages: []providers.Message{{Role: "user", Content: text}}}
+}
+
+func TestBatchQueueGroupsRequestsPerProvider(t *testing.T) {
+	srv := NewBatchServer()
+	srv.PollsUntilDone = 2
+	srv.Reply = func(body map[string]any) (string, error) {
+		text, _ := echoLastUser(body)
+		if text == "fail" {
+			return "", errors.New("bad request")
+		}
+		return "re: " + text, nil
+	}
+	t.Cleanup(srv.Close)
+
+	cases := []struct {
+		name     string
+		provider providers.BatchCapable
+	}{
+		{"openai", providers.NewOpenAIProvider("openai", "sk-test", srv.URL, "gpt-4o-mini").WithBatchAPI()},
+		{"anthropic", providers.NewAnthropicProvider("sk-ant", providers.WithAnthropicBaseURL(srv.URL), providers.WithAnthropicBatchAPI())},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, ok := providers.AsBatchCapable(tc.provider); !ok {
+				t.Fatal("provider should support batch")
+			}
+			q := newBatchTestQueue(t)
+			before := srv.Submitted()
+
+			inputs := []string{"one", "two", "fail"}
+			resps := make([]*providers.ChatResponse, len(inputs))
+			errs := make([]error, len(inputs))
+			var wg sync.WaitGroup
+			for i, in := range inputs {
+				wg.Go(func() {
+					resps[i], errs[i] = q.Chat(context.Background(), tc.provider, batchUserRequest(in))
+				})
+			}
+			wg.Wait()
+
+			if got := srv.Submitted() - before; got != 1 {
+				t.Fatalf("batches submitted = %d, want 1", got)
+			}
+			for i, in := range inputs[:2] {
+				if errs[i] != nil {
+					t.Fatalf("%s: error = %v", in, errs[i])
+				}
+				if resps[i].Content != "re: "+in {
+					t.Fatalf("%s: content = %q", in, resps[i].Content)
+				}
+				if resps[i].Usage == nil || !resps[i].Usage.Batch {
+					t.Fatalf("%s: usage = %#v, want Batch", in, resps[i].Usage)
+				}
+			}
+			if errs[2] == nil || errors.Is(errs[2], providers.ErrBatchSubmit) {
+				t.Fatalf("failed request error = %v", errs[2])
+			}
+		})
+	}
+}
+
+func TestBatchQueueSplitsAtMaxBatchSize(t *testing.T) {
+	srv := NewBatchServer()
+	t.Cleanup(srv.Close)
+	q := providers.NewBatchQueue(providers.BatchQueueConfig{
+		FlushInterval: time.Hour,
+		PollInterval:  10 * time.Millisecond,
+		MaxBatchSize:  2,
+	})
+	q.Start(context.Background())
+	t.Cleanup(q.Stop)
+	p := providers.NewOpenAIProvider("openai", "sk-test", srv.URL, "gpt-4o-mini").WithBatchAPI()
+
+	var wg sync.WaitGroup
+	for i := range 2 {
+		wg.Go(func() {
+			if _, err := q.Chat(context.Background(), p, batchUserRequest(fmt.Sprint(i))); err != nil {
+				t.Errorf("Chat() error = %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if sizes := srv.BatchSizes(); len(sizes) != 1 || sizes[0] != 2 {
+		t.Fatalf("batch sizes = %v, want [2]", sizes)
+	}
+}
+
+func TestBatchQueueSubmitFailureIsRetryable(t *testing.T) {
+	srv := NewBatchServer()
+	srv.Close() // submits fail to connect
+	q := newBatchTestQueue(t)
+	p := providers.NewOpenAIProvider("openai", "sk-test", srv.URL, "gpt-4o-mini").WithBatchAPI()
+
+	_, err := q.Chat(context.Background(), p, batchUserRequest("hi"))
+	if !errors.Is(err, providers.ErrBatchSubmit) {
+		t.Fatalf("error = %v, want ErrBatchSubmit", err)
+	}
+}
+
+func TestOpenAISupportsBatchOnlyForNativeEndpoint(t *testing.T) {
+	if !providers.NewOpenAIProvider("openai", "k", "https://api.openai.com/v1", "").SupportsBatch() {
+		t.Fatal("api.openai.com should support batch")
+	}
+	if providers.NewOpenAIProvider("groq", "k", "https://api.groq.com/openai/v1", "").SupportsBatch() {
+		t.Fatal("compatible endpoints should not support batch by default")
+	}
+}
+
+func TestBatchQueueResumesSubmittedBatchesAfterRestart(t *testing.T) {
+	srv := NewBatchServer()
+	srv.PollsUntilDone = 1
+	t.Cleanup(srv.Close)
+	p := providers.NewOpenAIProvider("openai", "sk-test", srv.URL, "gpt-4o-mini").WithBatchAPI()
+	reg := providers.NewRegistry(nil)
+	reg.Register(p)
+	cfg := providers.BatchQueueConfig{
+		FlushInterval: 10 * time.Millisecond,
+		PollInterval:  time.Hour, // the first queue never sees the batch finish
+		MaxWait:       5 * time.Second,
+		StatePath:     filepath.Join(t.TempDir(), "batches.json"),
+		Registry:      reg,
+	}
+
+	first := providers.NewBatchQueue(cfg)
+	first.Start(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, err := first.Chat(context.Background(), p, batchUserRequest("hello"))
+		errc <- err
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.Submitted() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	first.Stop()
+	if err := <-errc; err == nil {
+		t.Fatal("caller of a stopped queue should fail")
+	}
+
+	cfg.PollInterval = 10 * time.Millisecond
+	second := providers.NewBatchQueue(cfg)
+	second.Start(context.Background())
+	t.Cleanup(second.Stop)
+
+	// The worker redoes its work; the resumed batch answers it.
+	resp, err := second.Chat(context.Background(), p, batchUserRequest("hello"))
+	if err != nil {
+		t.Fatalf("Chat() after restart error = %v", err)
+	}
+	if resp.Content != "hello" {
+		t.Fatalf("content = %q, want hello", resp.Content)
+	}
+	if got := srv.Submitted(); got != 1 {
+		t.Fatalf("batches submitted = %d, want 1 (resumed, not resubmitted)", got)
+	}
+}
+
+func TestBatchCallsPinOnePoolKey(t *testing.T) {
+	srv := NewBatchServer()
+	srv.PollsUntilDone = 1
+	t.Cleanup(srv.Close)
+	p := providers.NewOpenAIProvider("openai", "", srv.URL, "gpt-4o-mini").WithBatchAPI()
+	if !providers.AttachKeyPool(p, providers.NewAPIKeyPool("openai", providers.KeyPoolRoundRobin, []string{"", "sk-a", "sk-b"})) {
+		t.Fatal("AttachKeyPool returned false")
+	}
+
+	// Upload, create, polls and the output download must all use one key:
+	// files and batches belong to that key's project.
+	q := newBatchTestQueue(t)
+	for _, in := range []string{"one", "two"} {
+		if _, err := q.Chat(context.Background(), p, batchUserRequest(in)); err != nil {
+			t.Fatalf("Chat(%s) error = %v", in, err)
+		}
+	}
+	creds := srv.Credentials()
+	if len(creds) < 8 {
+		t.Fatalf("credentials = %v, want every batch call recorded", creds)
+	}
+	for _, c := range creds {
+		if c != "sk-a" {
+			t.Fatalf("credentials = %v, want all sk-a", creds)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package providertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+)
+
+// BedrockRecording is a recorded Bedrock runtime exchange: the JSON body
+// returned by Converse and the ordered events returned by ConverseStream.
+type BedrockRecording struct {
+	Converse json.RawMessage      `json:"converse,omitempty"`
+	Stream   []BedrockStreamEvent `json:"stream,omitempty"`
+}
+
+// BedrockStreamEvent is one ConverseStream frame. Type is the ":event-type"
+// header (e.g. "contentBlockDelta"); when Exception is set the frame is sent
+// as an exception (":exception-type") instead.
+type BedrockStreamEvent struct {
+	Type      string          `json:"type,omitempty"`
+	Exception string          `json:"exception,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// LoadBedrockRecording reads a recording fixture from a JSON file.
+func LoadBedrockRecording(path string) (BedrockRecording, error) {
+	var rec BedrockRecording
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rec, err
+	}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return rec, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return rec, nil
+}
+
+// BedrockRequest is a request captured by BedrockServer.
+type BedrockRequest struct {
+	Path   string
+	Header http.Header
+	Body   map[string]any
+}
+
+// BedrockServer is a stand-in for the Bedrock runtime that replays a
+// BedrockRecording for /model/{id}/converse and /model/{id}/converse-stream.
+// Requests without a SigV4 or Bearer Authorization header get a 403, like
+// the real service.
+type BedrockServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	rec      BedrockRecording
+	requests []BedrockRequest
+}
+
+// NewBedrockServer starts a server replaying rec. Callers must Close it.
+func NewBedrockServer(rec BedrockRecording) *BedrockServer {
+	s := &BedrockServer{rec: rec}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Requests returns the requests received so far.
+func (s *BedrockServer) Requests() []BedrockRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]BedrockRequest(nil), s.requests...)
+}
+
+func (s *BedrockServer) handle(w http.ResponseWriter, r *http.Request) {
+	raw, _ := io.ReadAll(r.Body)
+	var body map[string]any
+	_ = json.Unmarshal(raw, &body)
+	s.mu.Lock()
+	s.requests = append(s.requests, BedrockRequest{Path: r.URL.EscapedPath(), Header: r.Header.Clone(), Body: body})
+	s.mu.Unlock()
+
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 ") && !strings.HasPrefix(auth, "Bearer ") {
+		w.Header().Set("X-Amzn-Errortype", "AccessDeniedException:http://internal.amazon.com/coral/com.amazon.coral.service/")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"Missing Authentication Token"}`))
+		return
+	}
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/converse"):
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(s.rec.Converse)
+	case strings.HasSuffix(r.URL.Path, "/converse-stream"):
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		w.WriteHeader(http.StatusOK)
+		enc := eventstream.NewEncoder()
+		for _, ev := range s.rec.Stream {
+			var buf bytes.Buffer
+			if err := enc.Encode(&buf, bedrockFrame(ev)); err != nil {
+				return
+			}
+			_, _ = w.Write(buf.Bytes())
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func bedrockFrame(ev BedrockStreamEvent) eventstream.Message {
+	var h eventstream.Headers
+	if ev.Exception != "" {
+		h.Set(":message-type", eventstream.StringValue("exception"))
+		h.Set(":exception-type", eventstream.StringValue(ev.Exception))
+	} else {
+		h.Set(":message-type", eventstream.StringValue("event"))
+		h.Set(":event-type", eventstream.StringValue(ev.Type))
+	}
+	h.Set(":content-type", eventstream.StringValue("application/json"))
+	return eventstream.Message{Headers: h, Payload: ev.Payload}
+}
+
+// NewBedrockProviderFast returns a *providers.BedrockProvider pointed at srv
+// with static test credentials and Attempts=1.
+func NewBedrockProviderFast(name string, srv *BedrockServer) *providers.BedrockProvider {
+	p, err := providers.NewBedrockProvider(context.Background(), providers.BedrockConfig{
+		Name:            name,
+		Region:          "us-east-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	})
+	if err != nil {
+		panic(err) // static credentials + fixed region cannot fail
+	}
+	return p.WithBaseURL(srv.URL).WithRetryConfig(providers.RetryConfig{Attempts: 1})
+}
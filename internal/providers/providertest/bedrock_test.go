@@ -0,0 +1,159 @@
+package providertest
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+)
+
+func newBedrockTestServer(t *testing.T, fixture string) *BedrockServer {
+	t.Helper()
+	rec, err := LoadBedrockRecording("testdata/" + fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewBedrockServer(rec)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+var bedrockWeatherRequest = providers.ChatRequest{
+	Messages: []providers.Message{
+		{Role: "system", Content: "You are a weather bot."},
+		{Role: "user", Content: "Weather in Hanoi?"},
+	},
+	Tools: []providers.ToolDefinition{{
+		Type: "function",
+		Function: &providers.ToolFunctionSchema{
+			Name:        "get_weather",
+			Description: "Current weather",
+			Parameters: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"city": map[string]any{"type": "string"}},
+				"required":   []any{"city"},
+			},
+		},
+	}},
+	Options: map[string]any{providers.OptThinkingLevel: "low"},
+}
+
+func assertBedrockToolUse(t *testing.T, resp *providers.ChatResponse) {
+	t.Helper()
+	if resp.FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q, want tool_calls", resp.FinishReason)
+	}
+	if resp.Content != "Let me check." {
+		t.Errorf("Content = %q", resp.Content)
+	}
+	if resp.Thinking != "The user wants the weather in Hanoi." {
+		t.Errorf("Thinking = %q", resp.Thinking)
+	}
+	if resp.ThinkingSignature != "EqQBCkgIARABGAIiQL" {
+		t.Errorf("ThinkingSignature = %q", resp.ThinkingSignature)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "get_weather" ||
+		resp.ToolCalls[0].Arguments["city"] != "Hanoi" {
+		t.Fatalf("ToolCalls = %#v", resp.ToolCalls)
+	}
+	u := resp.Usage
+	if u == nil || u.PromptTokens != 412 || u.CompletionTokens != 58 || u.CacheReadTokens != 2048 {
+		t.Errorf("Usage = %#v", u)
+	}
+
+	// Raw Converse blocks must round-trip the reasoning signature for passback.
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal(resp.RawAssistantContent, &raw); err != nil || len(raw) != 3 {
+		t.Fatalf("RawAssistantContent = %s (%v)", resp.RawAssistantContent, err)
+	}
+	if !strings.Contains(string(raw[0]["reasoningContent"]), `"signature":"EqQBCkgIARABGAIiQL"`) {
+		t.Errorf("reasoning block = %s", raw[0]["reasoningContent"])
+	}
+}
+
+func TestBedrockProvider_ChatReplaysConverse(t *testing.T) {
+	srv := newBedrockTestServer(t, "bedrock_tool_use.json")
+	p := NewBedrockProviderFast("bedrock", srv)
+
+	resp, err := p.Chat(context.Background(), bedrockWeatherRequest)
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	assertBedrockToolUse(t, resp)
+
+	reqs := srv.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("requests = %d", len(reqs))
+	}
+	req := reqs[0]
+	if want := "/model/us.anthropic.claude-sonnet-4-5-20250929-v1%3A0/converse"; req.Path != want {
+		t.Errorf("path = %q, want %q", req.Path, want)
+	}
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") ||
+		!strings.Contains(auth, "/us-east-1/bedrock/aws4_request") {
+		t.Errorf("Authorization = %q, want SigV4 for bedrock/us-east-1", auth)
+	}
+	if req.Header.Get("X-Amz-Date") == "" {
+		t.Error("missing X-Amz-Date")
+	}
+	if _, ok := req.Body["additionalModelRequestFields"]; !ok {
+		t.Error("thinking should be sent as additionalModelRequestFields for Claude")
+	}
+}
+
+func TestBedrockProvider_ChatStreamReplaysEvents(t *testing.T) {
+	srv := newBedrockTestServer(t, "bedrock_tool_use.json")
+	p := NewBedrockProviderFast("bedrock", srv)
+
+	var content, thinking strings.Builder
+	done := false
+	resp, err := p.ChatStream(context.Background(), bedrockWeatherRequest, func(c providers.StreamChunk) {
+		content.WriteString(c.Content)
+		thinking.WriteString(c.Thinking)
+		done = done || c.Done
+	})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	assertBedrockToolUse(t, resp)
+	if content.String() != "Let me check." || thinking.String() != resp.Thinking || !done {
+		t.Errorf("chunks: content=%q thinking=%q done=%v", content.String(), thinking.String(), done)
+	}
+	if got := srv.Requests()[0].Path; !strings.HasSuffix(got, "/converse-stream") {
+		t.Errorf("path = %q", got)
+	}
+}
+
+func TestBedrockProvider_ChatStreamException(t *testing.T) {
+	srv := newBedrockTestServer(t, "bedrock_throttled_stream.json")
+	p := NewBedrockProviderFast("bedrock", srv)
+
+	_, err := p.ChatStream(context.Background(), providers.ChatRequest{
+		Messages: []providers.Message{{Role: "user", Content: "hi"}},
+	}, nil)
+	if err == nil || !strings.Contains(err.Error(), "throttlingException: Too many tokens") {
+		t.Fatalf("err = %v, want throttlingException", err)
+	}
+}
+
+func TestBedrockProvider_APIKeyUsesBearer(t *testing.T) {
+	srv := newBedrockTestServer(t, "bedrock_tool_use.json")
+	p, err := providers.NewBedrockProvider(context.Background(), providers.BedrockConfig{
+		Region: "us-east-1",
+		APIKey: "bedrock-api-key",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.WithBaseURL(srv.URL).WithRetryConfig(providers.RetryConfig{Attempts: 1})
+
+	if _, err := p.Chat(context.Background(), bedrockWeatherRequest); err != nil {
+		t.Fatalf("Chat with API key: %v", err)
+	}
+	if auth := srv.Requests()[0].Header.Get("Authorization"); auth != "Bearer bedrock-api-key" {
+		t.Errorf("Authorization = %q", auth)
+	}
+}
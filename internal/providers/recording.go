@@ -0,0 +1,310 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recordingLineLimit bounds one archived exchange. Requests carry the whole
+// conversation, so long runs produce large lines.
+const recordingLineLimit = 64 << 20
+
+// ErrRecordingNotFound is returned by RecordingArchive.Load when no archive
+// exists for the trace.
+var ErrRecordingNotFound = errors.New("recording not found")
+
+// RecordedRun is the archive header: the input that started the run.
+type RecordedRun struct {
+	Input      string    `json:"input"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// RecordedCall is one provider exchange captured by RecordingProvider.
+// Binary media (images, video) is not archived.
+type RecordedCall struct {
+	Provider    string        `json:"provider"`
+	Model       string        `json:"model,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
+	Fingerprint string        `json:"fingerprint"`
+	Request     ChatRequest   `json:"request"`
+	Response    *ChatResponse `json:"response,omitempty"`
+	Chunks      []StreamChunk `json:"chunks,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	StartedAt   time.Time     `json:"started_at"`
+	DurationMS  int           `json:"duration_ms"`
+
+	// Provider passback state that ChatResponse keeps out of JSON.
+	RawAssistantContent json.RawMessage `json:"raw_assistant_content,omitempty"`
+	ThinkingSignature   string          `json:"thinking_signature,omitempty"`
+}
+
+// Recording is a loaded trace archive, calls in completion order.
+type Recording struct {
+	TraceKey string         `json:"trace_key"`
+	Run      *RecordedRun   `json:"run,omitempty"`
+	Calls    []RecordedCall `json:"calls"`
+}
+
+// recordingLine is one JSONL entry; exactly one field is set.
+type recordingLine struct {
+	Run  *RecordedRun  `json:"run,omitempty"`
+	Call *RecordedCall `json:"call,omitempty"`
+}
+
+// RecordingArchive stores recordings as one JSONL file per trace.
+type RecordingArchive struct {
+	dir string
+	mu  sync.Mutex // serializes appends so lines never interleave
+}
+
+func NewRecordingArchive(dir string) *RecordingArchive {
+	return &RecordingArchive{dir: dir}
+}
+
+// Path returns the archive file for a trace, or "" when the key is not a
+// plain file name.
+func (a *RecordingArchive) Path(traceKey string) string {
+	if traceKey == "" || strings.HasPrefix(traceKey, ".") || filepath.Base(traceKey) != traceKey {
+		return ""
+	}
+	return filepath.Join(a.dir, traceKey+".jsonl")
+}
+
+// Append writes one call. The run header is written first when the archive is
+// new and run is non-nil.
+func (a *RecordingArchive) Append(traceKey string, run *RecordedRun, call RecordedCall) error {
+	path := a.Path(traceKey)
+	if path == "" {
+		return fmt.Errorf("invalid recording key %q", traceKey)
+	}
+	lines := make([][]byte, 0, 2)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) && run != nil {
+		data, err := json.Marshal(recordingLine{Run: run})
+		if err != nil {
+			return err
+		}
+		lines = append(lines, data)
+	}
+	data, err := json.Marshal(recordingLine{Call: &call})
+	if err != nil {
+		return err
+	}
+	lines = append(lines, data)
+
+	if err := os.MkdirAll(a.dir, 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if _, err := f.Write(append(line, '\n')); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// Load reads a trace archive. Returns ErrRecordingNotFound when absent.
+func (a *RecordingArchive) Load(traceKey string) (*Recording, error) {
+	path := a.Path(traceKey)
+	if path == "" {
+		return nil, ErrRecordingNotFound
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrRecordingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rec := &Recording{TraceKey: traceKey}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), recordingLineLimit)
+	for sc.Scan() {
+		var line recordingLine
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("recording %s: %w", traceKey, err)
+		}
+		switch {
+		case line.Run != nil && rec.Run == nil:
+			rec.Run = line.Run
+		case line.Call != nil:
+			rec.Calls = append(rec.Calls, *line.Call)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("recording %s: %w", traceKey, err)
+	}
+	return rec, nil
+}
+
+// PruneOlderThan deletes archives last written before cutoff.
+func (a *RecordingArchive) PruneOlderThan(cutoff time.Time) (int, error) {
+	entries, err := os.ReadDir(a.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".jsonl" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if os.Remove(filepath.Join(a.dir, e.Name())) == nil {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+type recordingInputKey struct{}
+
+// WithRecordingInput attaches the run input so the first recorded call can
+// write the archive header. The agent loop sets it once per run.
+func WithRecordingInput(ctx context.Context, input string) context.Context {
+	return context.WithValue(ctx, recordingInputKey{}, input)
+}
+
+func recordingInputFromContext(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(recordingInputKey{}).(string)
+	return v, ok
+}
+
+// RecordingProvider wraps a provider and archives every exchange under the
+// trace key taken from the call context. Calls without a trace key pass
+// through unrecorded; archive failures are logged and never fail the call.
+type RecordingProvider struct {
+	inner    Provider
+	archive  *RecordingArchive
+	traceKey func(context.Context) string
+}
+
+// NewRecordingProvider wraps inner. traceKey returns "" to skip recording;
+// it is injected so this package stays free of a tracing import.
+func NewRecordingProvider(inner Provider, archive *RecordingArchive, traceKey func(context.Context) string) *RecordingProvider {
+	return &RecordingProvider{inner: inner, archive: archive, traceKey: traceKey}
+}
+
+// Inner returns the wrapped provider.
+func (p *RecordingProvider) Inner() Provider { return p.inner }
+
+func (p *RecordingProvider) Name() string         { return p.inner.Name() }
+func (p *RecordingProvider) DefaultModel() string { return p.inner.DefaultModel() }
+
+// Capabilities forwards to the wrapped provider (zero value when it has none).
+func (p *RecordingProvider) Capabilities() ProviderCapabilities {
+	if ca, ok := p.inner.(CapabilitiesAware); ok {
+		return ca.Capabilities()
+	}
+	return ProviderCapabilities{}
+}
+
+func (p *RecordingProvider) SupportsThinking() bool {
+	tc, ok := p.inner.(ThinkingCapable)
+	return ok && tc.SupportsThinking()
+}
+
+func (p *RecordingProvider) PromptContribution() *PromptContribution {
+	if pc, ok := p.inner.(PromptContributor); ok {
+		return pc.PromptContribution()
+	}
+	return nil
+}
+
+func (p *RecordingProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	key := p.traceKey(ctx)
+	if key == "" {
+		return p.inner.Chat(ctx, req)
+	}
+	start := time.Now()
+	resp, err := p.inner.Chat(ctx, req)
+	p.record(ctx, key, req, false, nil, resp, err, start)
+	return resp, err
+}
+
+func (p *RecordingProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	key := p.traceKey(ctx)
+	if key == "" {
+		return p.inner.ChatStream(ctx, req, onChunk)
+	}
+	var chunks []StreamChunk
+	start := time.Now()
+	resp, err := p.inner.ChatStream(ctx, req, func(chunk StreamChunk) {
+		if chunk.Content != "" || chunk.Thinking != "" || chunk.Done {
+			chunks = append(chunks, StreamChunk{Content: chunk.Content, Thinking: chunk.Thinking, Done: chunk.Done})
+		}
+		if onChunk != nil {
+			onChunk(chunk)
+		}
+	})
+	p.record(ctx, key, req, true, chunks, resp, err, start)
+	return resp, err
+}
+
+func (p *RecordingProvider) record(ctx context.Context, key string, req ChatRequest, stream bool, chunks []StreamChunk, resp *ChatResponse, callErr error, start time.Time) {
+	model := req.Model
+	if model == "" {
+		model = p.inner.DefaultModel()
+	}
+	call := RecordedCall{
+		Provider:    p.inner.Name(),
+		Model:       model,
+		Stream:      stream,
+		Fingerprint: ReplayFingerprint(req),
+		Request:     req,
+		Response:    resp,
+		Chunks:      chunks,
+		StartedAt:   start.UTC(),
+		DurationMS:  int(time.Since(start).Milliseconds()),
+	}
+	if resp != nil {
+		call.RawAssistantContent = resp.RawAssistantContent
+		call.ThinkingSignature = resp.ThinkingSignature
+	}
+	if callErr != nil {
+		call.Error = callErr.Error()
+	}
+	var run *RecordedRun
+	if input, ok := recordingInputFromContext(ctx); ok {
+		run = &RecordedRun{Input: input, RecordedAt: start.UTC()}
+	}
+	if err := p.archive.Append(key, run, call); err != nil {
+		slog.Warn("recording: append failed", "trace", key, "provider", call.Provider, "error", err)
+	}
+}
+
+// ReplayFingerprint hashes the parts of a request that identify its place in
+// a run. System messages and the model are left out: prompts embed the
+// current time and fallback may change the model between runs.
+func ReplayFingerprint(req ChatRequest) string {
+	env := responseCacheEnvelope{Messages: make([]responseCacheMessage, 0, len(req.Messages))}
+	for _, msg := range req.Messages {
+		if msg.Role == "system" {
+			continue
+		}
+		env.Messages = append(env.Messages, normalizeCacheMessage(msg))
+	}
+	return hashCacheEnvelope(env)
+}
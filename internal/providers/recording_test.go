@@ -0,0 +1,182 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+type traceKeyCtx struct{}
+
+func recordingTraceKey(ctx context.Context) string {
+	v, _ := ctx.Value(traceKeyCtx{}).(string)
+	return v
+}
+
+// toolTurnProvider asks for a tool on the first call and answers on the next.
+type toolTurnProvider struct{ calls int }
+
+func (p *toolTurnProvider) Chat(_ context.Context, req ChatRequest) (*ChatResponse, error) {
+	p.calls++
+	if p.calls == 1 {
+		return &ChatResponse{
+			FinishReason:        "tool_calls",
+			ToolCalls:           []ToolCall{{ID: "call_1", Name: "read_file", Arguments: map[string]any{"path": "a.txt"}}},
+			RawAssistantContent: []byte(`[{"type":"tool_use"}]`),
+		}, nil
+	}
+	return &ChatResponse{Content: "done", FinishReason: "stop", Usage: &Usage{TotalTokens: 7}}, nil
+}
+
+func (p *toolTurnProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	resp, err := p.Chat(ctx, req)
+	if err == nil && onChunk != nil {
+		onChunk(StreamChunk{Content: resp.Content})
+		onChunk(StreamChunk{Done: true})
+	}
+	return resp, err
+}
+
+func (p *toolTurnProvider) DefaultModel() string { return "m1" }
+func (p *toolTurnProvider) Name() string         { return "scripted" }
+
+func toolRun() (first, second ChatRequest) {
+	first = ChatRequest{Messages: []Message{
+		{Role: "system", Content: "now: 10:00"},
+		{Role: "user", Content: "read a.txt"},
+	}}
+	second = first
+	second.Messages = append(append([]Message(nil), first.Messages...),
+		Message{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Name: "read_file", Arguments: map[string]any{"path": "a.txt"}}}},
+		Message{Role: "tool", ToolCallID: "call_1", Content: "hello"},
+	)
+	return first, second
+}
+
+func TestRecordingProviderArchivesAndReplays(t *testing.T) {
+	archive := NewRecordingArchive(t.TempDir())
+	rec := NewRecordingProvider(&toolTurnProvider{}, archive, recordingTraceKey)
+	ctx := context.WithValue(context.Background(), traceKeyCtx{}, "trace-1")
+	ctx = WithRecordingInput(ctx, "read a.txt")
+
+	first, second := toolRun()
+	if _, err := rec.Chat(ctx, first); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	var streamed []StreamChunk
+	if _, err := rec.ChatStream(ctx, second, func(c StreamChunk) { streamed = append(streamed, c) }); err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	if len(streamed) != 2 {
+		t.Fatalf("caller should still see chunks, got %d", len(streamed))
+	}
+
+	loaded, err := archive.Load("trace-1")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.Run == nil || loaded.Run.Input != "read a.txt" {
+		t.Fatalf("run header = %+v", loaded.Run)
+	}
+	if len(loaded.Calls) != 2 || !loaded.Calls[1].Stream || len(loaded.Calls[1].Chunks) != 2 {
+		t.Fatalf("calls = %+v", loaded.Calls)
+	}
+
+	// The replayed system prompt differs (new clock) but still matches.
+	replay := NewReplayProvider(loaded)
+	first.Messages[0].Content = "now: 11:30"
+	resp, err := replay.Chat(context.Background(), first)
+	if err != nil {
+		t.Fatalf("replay Chat: %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "read_file" || string(resp.RawAssistantContent) == "" {
+		t.Fatalf("replayed tool turn = %+v", resp)
+	}
+	// The tool output is served from the next recorded request, not re-executed.
+	if msg, ok := replay.ToolResult(resp.ToolCalls[0].ID); !ok || msg.Content != "hello" {
+		t.Fatalf("recorded tool result = %+v, %v", msg, ok)
+	}
+	if _, ok := replay.ToolResult("call_unknown"); ok {
+		t.Fatal("unrecorded tool call should have no result")
+	}
+	var chunks []StreamChunk
+	resp, err = replay.ChatStream(context.Background(), second, func(c StreamChunk) { chunks = append(chunks, c) })
+	if err != nil || resp.Content != "done" || len(chunks) != 2 {
+		t.Fatalf("replayed final turn = %+v, chunks %v, err %v", resp, chunks, err)
+	}
+	if _, err := replay.Chat(context.Background(), second); !errors.Is(err, ErrReplayExhausted) {
+		t.Fatalf("expected ErrReplayExhausted, got %v", err)
+	}
+	if st := replay.Stats(); st.Recorded != 2 || st.Served != 2 || st.Diverged != 0 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestReplayProviderMatchesByFingerprintThenOrder(t *testing.T) {
+	first, second := toolRun()
+	rec := &Recording{Calls: []RecordedCall{
+		{Fingerprint: ReplayFingerprint(first), Response: &ChatResponse{Content: "one"}},
+		{Fingerprint: ReplayFingerprint(second), Response: &ChatResponse{Content: "two"}},
+		{Fingerprint: "other", Error: "upstream 500"},
+	}}
+	replay := NewReplayProvider(rec)
+
+	// Out-of-order request still gets its own recording.
+	if resp, _ := replay.Chat(context.Background(), second); resp == nil || resp.Content != "two" {
+		t.Fatalf("second = %+v", resp)
+	}
+	// A diverged request takes the next unused call in archive order.
+	diverged := ChatRequest{Messages: []Message{{Role: "user", Content: "something else"}}}
+	if resp, _ := replay.Chat(context.Background(), diverged); resp == nil || resp.Content != "one" {
+		t.Fatalf("diverged = %+v", resp)
+	}
+	if _, err := replay.Chat(context.Background(), diverged); err == nil || err.Error() != "upstream 500" {
+		t.Fatalf("recorded error should replay, got %v", err)
+	}
+	if st := replay.Stats(); st.Served != 3 || st.Diverged != 2 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestRecordingProviderSkipsUntracedCalls(t *testing.T) {
+	dir := t.TempDir()
+	rec := NewRecordingProvider(&toolTurnProvider{}, NewRecordingArchive(dir), recordingTraceKey)
+	if _, err := rec.Chat(context.Background(), ChatRequest{}); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("untraced call was archived: %v", entries)
+	}
+}
+
+func TestRecordingArchiveRejectsPathKeysAndPrunes(t *testing.T) {
+	archive := NewRecordingArchive(t.TempDir())
+	for _, key := range []string{"", "../x", "a/b", ".hidden"} {
+		if archive.Path(key) != "" {
+			t.Errorf("Path(%q) should be rejected", key)
+		}
+	}
+	if _, err := archive.Load("../etc/passwd"); !errors.Is(err, ErrRecordingNotFound) {
+		t.Errorf("Load with path key = %v", err)
+	}
+
+	if err := archive.Append("old", nil, RecordedCall{Provider: "p"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	past := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(archive.Path("old"), past, past); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+	if err := archive.Append("fresh", nil, RecordedCall{Provider: "p"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	n, err := archive.PruneOlderThan(time.Now().Add(-24 * time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("PruneOlderThan = %d, %v", n, err)
+	}
+	if _, err := archive.Load("fresh"); err != nil {
+		t.Errorf("fresh archive pruned: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrReplayExhausted is returned when a replay run makes more provider calls
+// than the recording holds.
+var ErrReplayExhausted = errors.New("replay: recording has no more calls")
+
+// ReplayStats summarizes how a replay run consumed its recording.
+type ReplayStats struct {
+	Recorded int `json:"recorded"`
+	Served   int `json:"served"`
+	// Diverged counts calls whose request matched no unused recorded call;
+	// those were answered with the next unused call in archive order.
+	Diverged int `json:"diverged"`
+}
+
+// ReplayProvider serves a Recording back without calling any model. Each call
+// takes the first unused recorded exchange with the same fingerprint, else the
+// next unused one, so concurrent or reordered calls still replay
+// deterministically. Recorded errors are returned as errors.
+type ReplayProvider struct {
+	calls       []RecordedCall
+	toolResults map[string]Message // tool call ID → result the recorded run sent back
+
+	mu       sync.Mutex
+	used     []bool
+	served   int
+	diverged int
+}
+
+func NewReplayProvider(rec *Recording) *ReplayProvider {
+	p := &ReplayProvider{}
+	if rec != nil {
+		p.calls = rec.Calls
+	}
+	p.used = make([]bool, len(p.calls))
+	p.toolResults = make(map[string]Message)
+	for _, call := range p.calls {
+		for _, m := range call.Request.Messages {
+			if m.Role == "tool" && m.ToolCallID != "" {
+				p.toolResults[m.ToolCallID] = m
+			}
+		}
+	}
+	return p
+}
+
+// ToolResult returns the output the recorded run fed back to the model for a
+// tool call, read from the requests of later recorded calls. Replays answer
+// tools from here instead of executing them.
+func (p *ReplayProvider) ToolResult(callID string) (Message, bool) {
+	m, ok := p.toolResults[callID]
+	return m, ok
+}
+
+func (p *ReplayProvider) Name() string { return "replay" }
+
+// DefaultModel returns the model of the first recorded call.
+func (p *ReplayProvider) DefaultModel() string {
+	if len(p.calls) == 0 {
+		return ""
+	}
+	return p.calls[0].Model
+}
+
+// Stats returns the consumption counters so far.
+func (p *ReplayProvider) Stats() ReplayStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return ReplayStats{Recorded: len(p.calls), Served: p.served, Diverged: p.diverged}
+}
+
+func (p *ReplayProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	call, err := p.next(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return replayResponse(call)
+}
+
+func (p *ReplayProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	call, err := p.next(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := replayResponse(call)
+	if err != nil || onChunk == nil {
+		return resp, err
+	}
+	if len(call.Chunks) > 0 {
+		for _, chunk := range call.Chunks {
+			onChunk(chunk)
+		}
+		return resp, nil
+	}
+	// Recorded via Chat: synthesize the chunks a stream would have sent.
+	if resp.Thinking != "" {
+		onChunk(StreamChunk{Thinking: resp.Thinking})
+	}
+	onChunk(StreamChunk{Content: resp.Content})
+	onChunk(StreamChunk{Done: true})
+	return resp, nil
+}
+
+func (p *ReplayProvider) next(ctx context.Context, req ChatRequest) (*RecordedCall, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	fp := ReplayFingerprint(req)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pick := -1
+	for i := range p.calls {
+		if !p.used[i] && p.calls[i].Fingerprint == fp {
+			pick = i
+			break
+		}
+	}
+	if pick < 0 {
+		for i := range p.calls {
+			if !p.used[i] {
+				pick = i
+				p.diverged++
+				break
+			}
+		}
+	}
+	if pick < 0 {
+		return nil, ErrReplayExhausted
+	}
+	p.used[pick] = true
+	p.served++
+	return &p.calls[pick], nil
+}
+
+// replayResponse rebuilds the recorded result. The response is copied so
+// callers mutating it can't alter a later replay of the same recording.
+func replayResponse(call *RecordedCall) (*ChatResponse, error) {
+	if call.Error != "" {
+		return nil, errors.New(call.Error)
+	}
+	if call.Response == nil {
+		return nil, errors.New("replay: recorded call has no response")
+	}
+	resp := *call.Response
+	resp.ToolCalls = append([]ToolCall(nil), call.Response.ToolCalls...)
+	resp.RawAssistantContent = call.RawAssistantContent
+	resp.ThinkingSignature = call.ThinkingSignature
+	return &resp, nil
+}
@@ -0,0 +1,310 @@
+package providers
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/cache"
+)
+
+// Response cache modes.
+const (
+	ResponseCacheModeExact    = "exact"    // hash of the normalized request
+	ResponseCacheModeSemantic = "semantic" // exact, then embedding similarity on the final user turn
+)
+
+const (
+	DefaultResponseCacheTTL        = time.Hour
+	DefaultResponseCacheSimilarity = 0.95
+
+	// responseCacheIndexLimit caps the semantic entries kept per conversation
+	// context; the oldest are dropped first.
+	responseCacheIndexLimit = 64
+)
+
+// ResponseCacheEmbedder embeds text for semantic lookups. store.EmbeddingProvider
+// satisfies it; the narrow interface keeps this package free of a store import.
+type ResponseCacheEmbedder interface {
+	Model() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// CachedResponse is the stored form of a cacheable ChatResponse.
+type CachedResponse struct {
+	Content      string    `json:"content"`
+	Thinking     string    `json:"thinking,omitempty"`
+	FinishReason string    `json:"finish_reason,omitempty"`
+	StoredAt     time.Time `json:"stored_at"`
+}
+
+// SemanticCacheEntry points a final-user-turn embedding at a cached response.
+type SemanticCacheEntry struct {
+	Key       string    `json:"key"`
+	Vector    []float32 `json:"vector"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ResponseCacheStore holds the cache backends shared by every agent's
+// ResponseCacheProvider. Isolation comes from the per-provider scope prefix.
+type ResponseCacheStore struct {
+	responses cache.Cache[CachedResponse]
+	index     cache.Cache[[]SemanticCacheEntry]
+	embedder  ResponseCacheEmbedder
+
+	mu sync.Mutex // serializes semantic index read-modify-write in this process
+}
+
+// NewResponseCacheStore creates a store. index and embedder may be nil, in
+// which case semantic mode degrades to exact.
+func NewResponseCacheStore(responses cache.Cache[CachedResponse], index cache.Cache[[]SemanticCacheEntry], embedder ResponseCacheEmbedder) *ResponseCacheStore {
+	if responses == nil {
+		return nil
+	}
+	return &ResponseCacheStore{responses: responses, index: index, embedder: embedder}
+}
+
+// SupportsSemantic reports whether semantic lookups are possible.
+func (s *ResponseCacheStore) SupportsSemantic() bool {
+	return s != nil && s.index != nil && s.embedder != nil
+}
+
+// ResponseCacheOptions configures one ResponseCacheProvider.
+type ResponseCacheOptions struct {
+	Scope               string // tenant/agent prefix; entries never cross scopes
+	Mode                string
+	TTL                 time.Duration
+	SimilarityThreshold float64
+}
+
+// ResponseCacheProvider wraps a provider and serves repeated requests from
+// cache. Only final answers are cached: responses with tool calls, images or
+// a non-stop finish reason always go to the wrapped provider.
+type ResponseCacheProvider struct {
+	inner Provider
+	store *ResponseCacheStore
+	opts  ResponseCacheOptions
+}
+
+func NewResponseCacheProvider(inner Provider, store *ResponseCacheStore, opts ResponseCacheOptions) *ResponseCacheProvider {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultResponseCacheTTL
+	}
+	if opts.SimilarityThreshold <= 0 || opts.SimilarityThreshold > 1 {
+		opts.SimilarityThreshold = DefaultResponseCacheSimilarity
+	}
+	if opts.Mode != ResponseCacheModeSemantic {
+		opts.Mode = ResponseCacheModeExact
+	} else if !store.SupportsSemantic() {
+		slog.Warn("response cache: semantic mode needs an embedding provider, using exact", "scope", opts.Scope)
+		opts.Mode = ResponseCacheModeExact
+	}
+	return &ResponseCacheProvider{inner: inner, store: store, opts: opts}
+}
+
+// Inner returns the wrapped provider.
+func (p *ResponseCacheProvider) Inner() Provider { return p.inner }
+
+func (p *ResponseCacheProvider) Name() string         { return p.inner.Name() }
+func (p *ResponseCacheProvider) DefaultModel() string { return p.inner.DefaultModel() }
+
+// Capabilities forwards to the wrapped provider (zero value when it has none).
+func (p *ResponseCacheProvider) Capabilities() ProviderCapabilities {
+	if ca, ok := p.inner.(CapabilitiesAware); ok {
+		return ca.Capabilities()
+	}
+	return ProviderCapabilities{}
+}
+
+func (p *ResponseCacheProvider) SupportsThinking() bool {
+	tc, ok := p.inner.(ThinkingCapable)
+	return ok && tc.SupportsThinking()
+}
+
+func (p *ResponseCacheProvider) PromptContribution() *PromptContribution {
+	if pc, ok := p.inner.(PromptContributor); ok {
+		return pc.PromptContribution()
+	}
+	return nil
+}
+
+func (p *ResponseCacheProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	lk := p.prepare(req)
+	if resp := p.lookup(ctx, lk, req); resp != nil {
+		return resp, nil
+	}
+	resp, err := p.inner.Chat(ctx, req)
+	if err == nil {
+		p.save(ctx, lk, resp)
+	}
+	return resp, err
+}
+
+// ChatStream replays a hit as one thinking chunk, one content chunk and Done.
+func (p *ResponseCacheProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	lk := p.prepare(req)
+	if resp := p.lookup(ctx, lk, req); resp != nil {
+		if onChunk != nil {
+			if resp.Thinking != "" {
+				onChunk(StreamChunk{Thinking: resp.Thinking})
+			}
+			onChunk(StreamChunk{Content: resp.Content})
+			onChunk(StreamChunk{Done: true})
+		}
+		return resp, nil
+	}
+	resp, err := p.inner.ChatStream(ctx, req, onChunk)
+	if err == nil {
+		p.save(ctx, lk, resp)
+	}
+	return resp, err
+}
+
+// responseCacheLookup carries the keys computed once per call. The embedding
+// is only computed when the exact key misses.
+type responseCacheLookup struct {
+	exactKey   string
+	contextKey string    // semantic index key; empty when the request isn't eligible
+	finalUser  string    // text to embed for the semantic lookup
+	vector     []float32 // final user turn embedding; set by lookup on an exact miss
+}
+
+func (p *ResponseCacheProvider) prefix() string {
+	return "resp:" + p.opts.Scope + ":"
+}
+
+func (p *ResponseCacheProvider) prepare(req ChatRequest) *responseCacheLookup {
+	if req.Model == "" {
+		req.Model = p.inner.DefaultModel()
+	}
+	keys := responseCacheKeys(req)
+	lk := &responseCacheLookup{exactKey: p.prefix() + "x:" + keys.exact}
+	if p.opts.Mode == ResponseCacheModeSemantic && keys.context != "" {
+		lk.contextKey = p.prefix() + "s:" + keys.context
+		lk.finalUser = keys.finalUser
+	}
+	return lk
+}
+
+// embed computes the final user turn embedding, clearing contextKey when it
+// fails so save skips the semantic index.
+func (p *ResponseCacheProvider) embed(ctx context.Context, lk *responseCacheLookup) {
+	vectors, err := p.store.embedder.Embed(ctx, []string{lk.finalUser})
+	if err != nil || len(vectors) == 0 || len(vectors[0]) == 0 {
+		if err != nil {
+			slog.Debug("response cache: embed failed", "scope", p.opts.Scope, "error", err)
+		}
+		lk.contextKey = ""
+		return
+	}
+	lk.vector = vectors[0]
+}
+
+func (p *ResponseCacheProvider) lookup(ctx context.Context, lk *responseCacheLookup, req ChatRequest) *ChatResponse {
+	if cached, ok := p.store.responses.Get(ctx, lk.exactKey); ok {
+		return cachedChatResponse(cached, ResponseCacheHit{Mode: ResponseCacheModeExact, Similarity: 1}, req)
+	}
+	if lk.contextKey == "" {
+		return nil
+	}
+	p.embed(ctx, lk)
+	if lk.contextKey == "" {
+		return nil
+	}
+	entries, _ := p.store.index.Get(ctx, lk.contextKey)
+	now := time.Now()
+	bestKey, best := "", 0.0
+	for _, e := range entries {
+		if now.After(e.ExpiresAt) {
+			continue
+		}
+		if sim := cosineSimilarity(lk.vector, e.Vector); sim >= p.opts.SimilarityThreshold && sim > best {
+			bestKey, best = e.Key, sim
+		}
+	}
+	if bestKey == "" {
+		return nil
+	}
+	cached, ok := p.store.responses.Get(ctx, bestKey)
+	if !ok {
+		return nil
+	}
+	return cachedChatResponse(cached, ResponseCacheHit{Mode: ResponseCacheModeSemantic, Similarity: best}, req)
+}
+
+func (p *ResponseCacheProvider) save(ctx context.Context, lk *responseCacheLookup, resp *ChatResponse) {
+	if !responseCacheable(resp) {
+		return
+	}
+	p.store.responses.Set(ctx, lk.exactKey, CachedResponse{
+		Content:      resp.Content,
+		Thinking:     resp.Thinking,
+		FinishReason: resp.FinishReason,
+		StoredAt:     time.Now().UTC(),
+	}, p.opts.TTL)
+	if lk.contextKey == "" {
+		return
+	}
+
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+	existing, _ := p.store.index.Get(ctx, lk.contextKey)
+	now := time.Now()
+	entries := make([]SemanticCacheEntry, 0, len(existing)+1)
+	for _, e := range existing {
+		if e.Key != lk.exactKey && now.Before(e.ExpiresAt) {
+			entries = append(entries, e)
+		}
+	}
+	entries = append(entries, SemanticCacheEntry{Key: lk.exactKey, Vector: lk.vector, ExpiresAt: now.Add(p.opts.TTL)})
+	if len(entries) > responseCacheIndexLimit {
+		entries = entries[len(entries)-responseCacheIndexLimit:]
+	}
+	p.store.index.Set(ctx, lk.contextKey, entries, p.opts.TTL)
+}
+
+// responseCacheable reports whether a response is a complete final answer.
+func responseCacheable(resp *ChatResponse) bool {
+	if resp == nil || resp.Content == "" || len(resp.ToolCalls) > 0 || len(resp.Images) > 0 {
+		return false
+	}
+	return resp.FinishReason == "" || resp.FinishReason == "stop"
+}
+
+// cachedChatResponse builds the response for a hit. Usage is zero so no cost
+// is billed; CacheHit marks the LLM span.
+func cachedChatResponse(cached CachedResponse, hit ResponseCacheHit, req ChatRequest) *ChatResponse {
+	hit.AgeMS = time.Since(cached.StoredAt).Milliseconds()
+	resp := &ChatResponse{
+		Content:      cached.Content,
+		Thinking:     cached.Thinking,
+		FinishReason: cached.FinishReason,
+		Usage:        &Usage{},
+		CacheHit:     &hit,
+	}
+	if resp.FinishReason == "" {
+		resp.FinishReason = "stop"
+	}
+	if strip, _ := req.Options[OptStripThinking].(bool); strip {
+		resp.Thinking = ""
+	}
+	return resp
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,113 @@
+package providers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// responseCacheOptions are the request options that change model output.
+// Routing and identity options (session, user, prompt cache key, service
+// tier) are left out so they don't fragment the cache.
+var responseCacheOptions = []string{
+	OptMaxTokens, OptTemperature, OptToolChoice, OptThinkingLevel, OptReasoningEffort,
+	OptEnableThinking, OptThinkingBudget, OptStripThinking, OptCachedContent,
+}
+
+type responseCacheToolCall struct {
+	Name      string         `json:"n"`
+	Arguments map[string]any `json:"a,omitempty"`
+}
+
+type responseCacheMessage struct {
+	Role      string                  `json:"r"`
+	Content   string                  `json:"c,omitempty"`
+	ToolCalls []responseCacheToolCall `json:"t,omitempty"`
+	IsError   bool                    `json:"e,omitempty"`
+	Media     []string                `json:"m,omitempty"`
+}
+
+type responseCacheEnvelope struct {
+	Model          string                 `json:"model"`
+	Messages       []responseCacheMessage `json:"messages"`
+	Tools          []ToolDefinition       `json:"tools,omitempty"`
+	Options        map[string]any         `json:"options,omitempty"`
+	ResponseFormat *ResponseFormat        `json:"response_format,omitempty"`
+}
+
+// responseCacheKeySet holds the hashes for one request. context and finalUser
+// are empty unless the request ends with a text-only user turn.
+type responseCacheKeySet struct {
+	exact     string // whole normalized request
+	context   string // everything except the final user turn
+	finalUser string
+}
+
+// responseCacheKeys normalizes a request and hashes it. Volatile fields
+// (timestamps, tool call IDs, assistant thinking, raw provider blocks) are
+// dropped so identical conversations hash identically across runs.
+func responseCacheKeys(req ChatRequest) responseCacheKeySet {
+	env := responseCacheEnvelope{
+		Model:          req.Model,
+		Messages:       make([]responseCacheMessage, 0, len(req.Messages)),
+		Tools:          req.Tools,
+		ResponseFormat: req.ResponseFormat,
+	}
+	for _, name := range responseCacheOptions {
+		if v, ok := req.Options[name]; ok {
+			if env.Options == nil {
+				env.Options = make(map[string]any)
+			}
+			env.Options[name] = v
+		}
+	}
+	for _, msg := range req.Messages {
+		env.Messages = append(env.Messages, normalizeCacheMessage(msg))
+	}
+
+	keys := responseCacheKeySet{exact: hashCacheEnvelope(env)}
+	if n := len(env.Messages); n > 0 {
+		last := env.Messages[n-1]
+		if last.Role == "user" && last.Content != "" && len(last.Media) == 0 {
+			env.Messages = env.Messages[:n-1]
+			keys.context = hashCacheEnvelope(env)
+			keys.finalUser = last.Content
+		}
+	}
+	return keys
+}
+
+func normalizeCacheMessage(msg Message) responseCacheMessage {
+	out := responseCacheMessage{
+		Role:    msg.Role,
+		Content: strings.TrimSpace(strings.ReplaceAll(msg.Content, CacheBoundaryMarker, "")),
+		IsError: msg.IsError,
+	}
+	for _, tc := range msg.ToolCalls {
+		out.ToolCalls = append(out.ToolCalls, responseCacheToolCall{Name: tc.Name, Arguments: tc.Arguments})
+	}
+	for _, img := range msg.Images {
+		out.Media = append(out.Media, hashCacheString(img.MimeType+"\x00"+img.Data+"\x00"+img.URL))
+	}
+	for _, vid := range msg.Videos {
+		out.Media = append(out.Media, hashCacheString(vid.MimeType+"\x00"+vid.Data+"\x00"+vid.URL))
+	}
+	for _, ref := range msg.MediaRefs {
+		out.Media = append(out.Media, hashCacheString(ref.MimeType+"\x00"+ref.Path))
+	}
+	return out
+}
+
+func hashCacheEnvelope(env responseCacheEnvelope) string {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return ""
+	}
+	return hashCacheString(string(data))
+}
+
+func hashCacheString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
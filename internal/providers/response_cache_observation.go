@@ -0,0 +1,32 @@
+package providers
+
+import (
+	"encoding/json"
+)
+
+const ResponseCacheMetadataKey = "response_cache"
+
+// ResponseCacheHit describes a response served by ResponseCacheProvider.
+type ResponseCacheHit struct {
+	Mode       string  `json:"mode"`
+	Similarity float64 `json:"similarity,omitempty"`
+	AgeMS      int64   `json:"age_ms"`
+}
+
+func MergeResponseCacheMetadata(existing json.RawMessage, hit ResponseCacheHit) json.RawMessage {
+	payload := map[string]any{}
+	if len(existing) > 0 {
+		_ = json.Unmarshal(existing, &payload)
+	}
+	payload[ResponseCacheMetadataKey] = map[string]any{
+		"hit":        true,
+		"mode":       hit.Mode,
+		"similarity": hit.Similarity,
+		"age_ms":     hit.AgeMS,
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return existing
+	}
+	return json.RawMessage(data)
+}
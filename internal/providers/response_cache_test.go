@@ -0,0 +1,180 @@
+package providers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/cache"
+)
+
+// testKeywordEmbedder maps text to a 2-d vector: questions mentioning
+// "weather" point one way, everything else the other.
+type testKeywordEmbedder struct{ calls int }
+
+func (e *testKeywordEmbedder) Model() string { return "test-embed" }
+func (e *testKeywordEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		if strings.Contains(strings.ToLower(text), "weather") {
+			out[i] = []float32{1, 0.05}
+		} else {
+			out[i] = []float32{0, 1}
+		}
+	}
+	return out, nil
+}
+
+func newTestResponseCacheStore(embedder ResponseCacheEmbedder) *ResponseCacheStore {
+	return NewResponseCacheStore(
+		cache.NewInMemoryCache[CachedResponse](),
+		cache.NewInMemoryCache[[]SemanticCacheEntry](),
+		embedder,
+	)
+}
+
+func userRequest(system, text string) ChatRequest {
+	now := time.Now()
+	return ChatRequest{
+		Model: "m1",
+		Messages: []Message{
+			{Role: "system", Content: system},
+			{Role: "user", Content: text, CreatedAt: &now},
+		},
+	}
+}
+
+func TestResponseCacheProviderExactHit(t *testing.T) {
+	inner := &testFallbackProvider{name: "primary", model: "m1"}
+	store := newTestResponseCacheStore(nil)
+	p := NewResponseCacheProvider(inner, store, ResponseCacheOptions{Scope: "t1:a1"})
+	ctx := context.Background()
+
+	if _, err := p.Chat(ctx, userRequest("sys", "hello")); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	// Timestamps and cache boundary markers don't change the key.
+	resp, err := p.Chat(ctx, userRequest("sys"+CacheBoundaryMarker, "hello "))
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if inner.calls != 1 {
+		t.Fatalf("inner calls = %d, want 1", inner.calls)
+	}
+	if resp.CacheHit == nil || resp.CacheHit.Mode != ResponseCacheModeExact {
+		t.Fatalf("CacheHit = %#v, want exact hit", resp.CacheHit)
+	}
+	if resp.Content != "m1" || resp.Usage == nil || resp.Usage.PromptTokens != 0 {
+		t.Fatalf("hit response = %#v", resp)
+	}
+
+	// A different scope (tenant/agent) never sees the entry.
+	other := NewResponseCacheProvider(inner, store, ResponseCacheOptions{Scope: "t2:a1"})
+	if resp, _ := other.Chat(ctx, userRequest("sys", "hello")); resp.CacheHit != nil {
+		t.Fatal("cache hit crossed scopes")
+	}
+	if inner.calls != 2 {
+		t.Fatalf("inner calls = %d, want 2", inner.calls)
+	}
+}
+
+func TestResponseCacheProviderSkipsToolCalls(t *testing.T) {
+	store := newTestResponseCacheStore(nil)
+	inner := &testToolCallProvider{}
+	p := NewResponseCacheProvider(inner, store, ResponseCacheOptions{Scope: "t1:a1"})
+	for range 2 {
+		if _, err := p.Chat(context.Background(), userRequest("sys", "run it")); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+	}
+	if inner.calls != 2 {
+		t.Fatalf("inner calls = %d, want 2 (tool call responses are not cached)", inner.calls)
+	}
+}
+
+func TestResponseCacheProviderSemanticHit(t *testing.T) {
+	embedder := &testKeywordEmbedder{}
+	inner := &testFallbackProvider{name: "primary", model: "m1"}
+	p := NewResponseCacheProvider(inner, newTestResponseCacheStore(embedder), ResponseCacheOptions{
+		Scope:               "t1:a1",
+		Mode:                ResponseCacheModeSemantic,
+		SimilarityThreshold: 0.9,
+	})
+	ctx := context.Background()
+
+	if _, err := p.Chat(ctx, userRequest("sys", "What's the weather today?")); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	var chunks []StreamChunk
+	resp, err := p.ChatStream(ctx, userRequest("sys", "weather today please"), func(c StreamChunk) { chunks = append(chunks, c) })
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if inner.calls != 1 {
+		t.Fatalf("inner calls = %d, want 1", inner.calls)
+	}
+	if resp.CacheHit == nil || resp.CacheHit.Mode != ResponseCacheModeSemantic || resp.CacheHit.Similarity < 0.9 {
+		t.Fatalf("CacheHit = %#v, want semantic hit", resp.CacheHit)
+	}
+	if len(chunks) != 2 || chunks[0].Content != "m1" || !chunks[1].Done {
+		t.Fatalf("chunks = %#v", chunks)
+	}
+
+	// Unrelated question misses; so does the same question under another system prompt.
+	if resp, _ := p.Chat(ctx, userRequest("sys", "tell me a joke")); resp.CacheHit != nil {
+		t.Fatal("unrelated question hit the cache")
+	}
+	if resp, _ := p.Chat(ctx, userRequest("other sys", "weather today please")); resp.CacheHit != nil {
+		t.Fatal("different context hit the cache")
+	}
+	if inner.calls != 3 {
+		t.Fatalf("inner calls = %d, want 3", inner.calls)
+	}
+
+	// An exact repeat is answered before any embedding is computed.
+	embeds := embedder.calls
+	if resp, _ := p.Chat(ctx, userRequest("sys", "tell me a joke")); resp.CacheHit == nil || resp.CacheHit.Mode != ResponseCacheModeExact {
+		t.Fatalf("CacheHit = %#v, want exact hit", resp.CacheHit)
+	}
+	if embedder.calls != embeds {
+		t.Errorf("exact hit embedded %d times, want 0", embedder.calls-embeds)
+	}
+}
+
+func TestResponseCacheProviderSemanticFallsBackToExact(t *testing.T) {
+	p := NewResponseCacheProvider(&testFallbackProvider{}, newTestResponseCacheStore(nil), ResponseCacheOptions{Mode: ResponseCacheModeSemantic})
+	if p.opts.Mode != ResponseCacheModeExact {
+		t.Fatalf("mode = %q, want exact without an embedder", p.opts.Mode)
+	}
+}
+
+func TestMergeResponseCacheMetadata(t *testing.T) {
+	merged := MergeResponseCacheMetadata([]byte(`{"reasoning":{"effort":"low"}}`), ResponseCacheHit{Mode: "semantic", Similarity: 0.97, AgeMS: 1200})
+	got := string(merged)
+	for _, want := range []string{`"reasoning"`, `"response_cache"`, `"hit":true`, `"mode":"semantic"`} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("metadata %s missing %s", got, want)
+		}
+	}
+}
+
+type testToolCallProvider struct{ calls int }
+
+func (p *testToolCallProvider) Chat(context.Context, ChatRequest) (*ChatResponse, error) {
+	p.calls++
+	return &ChatResponse{
+		Content:      "calling",
+		ToolCalls:    []ToolCall{{ID: "c1", Name: "exec"}},
+		FinishReason: "tool_calls",
+	}, nil
+}
+
+func (p *testToolCallProvider) ChatStream(ctx context.Context, req ChatRequest, _ func(StreamChunk)) (*ChatResponse, error) {
+	return p.Chat(ctx, req)
+}
+
+func (p *testToolCallProvider) DefaultModel() string { return "m1" }
+func (p *testToolCallProvider) Name() string         { return "tools" }
@@ -0,0 +1,384 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Response format types for ChatRequest.ResponseFormat.
+const (
+	// ResponseFormatJSONObject asks for any syntactically valid JSON value.
+	ResponseFormatJSONObject = "json_object"
+	// ResponseFormatJSONSchema asks for JSON conforming to ResponseFormat.Schema.
+	ResponseFormatJSONSchema = "json_schema"
+)
+
+// defaultResponseFormatName is used when the caller leaves ResponseFormat.Name empty.
+// OpenAI requires a name matching ^[a-zA-Z0-9_-]{1,64}$; Anthropic uses it as the forced tool name.
+const defaultResponseFormatName = "structured_output"
+
+// ResponseFormat requests a schema-constrained response from the provider.
+// Each adapter maps it to its native mechanism:
+//   - OpenAI, Azure OpenAI, Gemini OpenAI-compat: response_format {type: json_schema}
+//   - Codex (Responses API): text.format {type: json_schema}
+//   - Anthropic: a single forced tool whose input_schema is Schema
+//   - DashScope and other OpenAI-compatible backends: response_format
+//     {type: json_object} + schema in the system prompt
+//   - Gemini (native): generationConfig.responseMimeType + responseSchema
+//
+// Providers that cannot enforce it ignore the field; ChatStructured adds a
+// prompt instruction and validates/retries for those.
+type ResponseFormat struct {
+	Type        string         `json:"type"`                  // "json_object" | "json_schema"
+	Name        string         `json:"name,omitempty"`        // schema name (OpenAI) / tool name (Anthropic)
+	Description string         `json:"description,omitempty"` // optional hint shown to the model
+	Schema      map[string]any `json:"schema,omitempty"`      // JSON Schema; required for json_schema
+	Strict      bool           `json:"strict,omitempty"`      // OpenAI strict mode — constrained decoding
+}
+
+// NewJSONSchemaFormat builds a json_schema ResponseFormat with strict decoding.
+func NewJSONSchemaFormat(name string, schema map[string]any) *ResponseFormat {
+	return &ResponseFormat{Type: ResponseFormatJSONSchema, Name: name, Schema: schema, Strict: true}
+}
+
+// SchemaName returns the configured name or the default.
+func (f *ResponseFormat) SchemaName() string {
+	if f == nil || f.Name == "" {
+		return defaultResponseFormatName
+	}
+	return f.Name
+}
+
+// HasSchema reports whether a JSON Schema constraint is present.
+func (f *ResponseFormat) HasSchema() bool {
+	return f != nil && f.Type == ResponseFormatJSONSchema && len(f.Schema) > 0
+}
+
+// wireSchema returns the schema as sent to OpenAI-family APIs. Strict mode
+// requires every property listed in "required" and additionalProperties:false,
+// so the caller's schema is copied and run through applyStrictMode.
+func (f *ResponseFormat) wireSchema() map[string]any {
+	if !f.Strict {
+		return f.Schema
+	}
+	return applyStrictMode(copySchema(f.Schema), 0)
+}
+
+// openAIResponseFormat serializes the format for Chat Completions response_format.
+func (f *ResponseFormat) openAIResponseFormat() map[string]any {
+	if !f.HasSchema() {
+		return map[string]any{"type": ResponseFormatJSONObject}
+	}
+	js := map[string]any{
+		"name":   f.SchemaName(),
+		"schema": f.wireSchema(),
+	}
+	if f.Description != "" {
+		js["description"] = f.Description
+	}
+	if f.Strict {
+		js["strict"] = true
+	}
+	return map[string]any{"type": ResponseFormatJSONSchema, "json_schema": js}
+}
+
+// responsesTextFormat serializes the format for Responses API text.format (Codex).
+func (f *ResponseFormat) responsesTextFormat() map[string]any {
+	if !f.HasSchema() {
+		return map[string]any{"type": ResponseFormatJSONObject}
+	}
+	out := map[string]any{
+		"type":   ResponseFormatJSONSchema,
+		"name":   f.SchemaName(),
+		"schema": f.wireSchema(),
+	}
+	if f.Description != "" {
+		out["description"] = f.Description
+	}
+	if f.Strict {
+		out["strict"] = true
+	}
+	return out
+}
+
+// promptInstruction renders the format as a plain-text instruction for providers
+// that cannot enforce it natively (or only support json_object mode).
+func (f *ResponseFormat) promptInstruction() string {
+	var b strings.Builder
+	b.WriteString("Respond with ONLY a single valid JSON value — no markdown fences, no commentary.")
+	if f.Description != "" {
+		b.WriteString("\n")
+		b.WriteString(f.Description)
+	}
+	if f.HasSchema() {
+		if schemaJSON, err := json.Marshal(f.Schema); err == nil {
+			b.WriteString("\nThe JSON MUST conform to this JSON Schema:\n")
+			b.Write(schemaJSON)
+		}
+	}
+	return b.String()
+}
+
+// withSystemInstruction returns a copy of msgs with the format instruction
+// appended to the first system message (or prepended as a new system message).
+func (f *ResponseFormat) withSystemInstruction(msgs []Message) []Message {
+	instr := f.promptInstruction()
+	out := make([]Message, len(msgs), len(msgs)+1)
+	copy(out, msgs)
+	for i := range out {
+		if out[i].Role == "system" {
+			out[i].Content = strings.TrimRight(out[i].Content, "\n") + "\n\n" + instr
+			return out
+		}
+	}
+	return append([]Message{{Role: "system", Content: instr}}, out...)
+}
+
+// ExtractJSON pulls a JSON value out of free-form model output: strips
+// markdown code fences and, failing that, slices from the first '{' or '['
+// to the matching last '}' or ']'. Returns the trimmed input when nothing
+// JSON-like is found so the caller's decode error stays informative.
+func ExtractJSON(content string) string {
+	s := strings.TrimSpace(content)
+	if strings.HasPrefix(s, "```") {
+		s = strings.TrimPrefix(s, "```json")
+		s = strings.TrimPrefix(s, "```JSON")
+		s = strings.TrimPrefix(s, "```")
+		if idx := strings.LastIndex(s, "```"); idx >= 0 {
+			s = s[:idx]
+		}
+		s = strings.TrimSpace(s)
+	}
+	if json.Valid([]byte(s)) {
+		return s
+	}
+	start := strings.IndexAny(s, "{[")
+	if start < 0 {
+		return s
+	}
+	closer := byte('}')
+	if s[start] == '[' {
+		closer = ']'
+	}
+	end := strings.LastIndexByte(s, closer)
+	if end <= start {
+		return s
+	}
+	return s[start : end+1]
+}
+
+// ValidateJSONSchema checks a decoded JSON value against the subset of JSON Schema
+// used by structured-output callers: type, properties, required, items, enum,
+// additionalProperties=false, minItems/maxItems, minimum/maximum and anyOf.
+// Unknown keywords are ignored. Returns the first violation with its JSON path.
+func ValidateJSONSchema(schema map[string]any, value any) error {
+	return validateSchemaAt("$", schema, value)
+}
+
+func validateSchemaAt(path string, schema map[string]any, value any) error {
+	if len(schema) == 0 {
+		return nil
+	}
+
+	if anyOf, ok := schema["anyOf"].([]any); ok && len(anyOf) > 0 {
+		var firstErr error
+		for _, alt := range anyOf {
+			m, _ := alt.(map[string]any)
+			err := validateSchemaAt(path, m, value)
+			if err == nil {
+				firstErr = nil
+				break
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		if firstErr != nil {
+			return fmt.Errorf("%s: does not match any allowed schema (%w)", path, firstErr)
+		}
+	}
+
+	if enum := toAnySlice(schema["enum"]); len(enum) > 0 {
+		matched := false
+		for _, e := range enum {
+			if jsonEqual(e, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: value %v is not one of the allowed enum values", path, value)
+		}
+	}
+
+	if t, ok := schema["type"]; ok && !matchesSchemaType(t, value) {
+		return fmt.Errorf("%s: expected type %v, got %s", path, t, jsonTypeName(value))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		required := make(map[string]bool)
+		for _, r := range toStringSlice(schema["required"]) {
+			required[r] = true
+			if _, ok := v[r]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, r)
+			}
+		}
+		for k, child := range v {
+			if ps, ok := props[k].(map[string]any); ok {
+				// Strict mode turns optional properties into nullable ones, so a
+				// null optional property is accepted against the caller's schema.
+				if child == nil && !required[k] {
+					continue
+				}
+				if err := validateSchemaAt(path+"."+k, ps, child); err != nil {
+					return err
+				}
+				continue
+			}
+			switch ap := schema["additionalProperties"].(type) {
+			case bool:
+				if !ap {
+					return fmt.Errorf("%s: unexpected property %q", path, k)
+				}
+			case map[string]any:
+				if err := validateSchemaAt(path+"."+k, ap, child); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		if n, ok := schemaNumber(schema["minItems"]); ok && float64(len(v)) < n {
+			return fmt.Errorf("%s: expected at least %v items, got %d", path, n, len(v))
+		}
+		if n, ok := schemaNumber(schema["maxItems"]); ok && float64(len(v)) > n {
+			return fmt.Errorf("%s: expected at most %v items, got %d", path, n, len(v))
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, child := range v {
+				if err := validateSchemaAt(fmt.Sprintf("%s[%d]", path, i), items, child); err != nil {
+					return err
+				}
+			}
+		}
+	case float64:
+		if n, ok := schemaNumber(schema["minimum"]); ok && v < n {
+			return fmt.Errorf("%s: %v is below minimum %v", path, v, n)
+		}
+		if n, ok := schemaNumber(schema["maximum"]); ok && v > n {
+			return fmt.Errorf("%s: %v is above maximum %v", path, v, n)
+		}
+	}
+	return nil
+}
+
+// matchesSchemaType handles both "type": "x" and "type": ["x", "null"].
+func matchesSchemaType(t any, value any) bool {
+	switch tv := t.(type) {
+	case string:
+		return matchesSingleType(tv, value)
+	case []any, []string:
+		for _, s := range toStringSlice(tv) {
+			if matchesSingleType(s, value) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func matchesSingleType(t string, value any) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == float64(int64(f))
+	}
+	return true
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func jsonEqual(a, b any) bool {
+	aj, errA := json.Marshal(a)
+	bj, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aj) == string(bj)
+}
+
+func schemaNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// toAnySlice accepts both decoded JSON arrays and Go-literal []string schemas.
+func toAnySlice(v any) []any {
+	switch s := v.(type) {
+	case []any:
+		return s
+	case []string:
+		out := make([]any, len(s))
+		for i, item := range s {
+			out[i] = item
+		}
+		return out
+	}
+	return nil
+}
+
+func toStringSlice(v any) []string {
+	switch s := v.(type) {
+	case []string:
+		return s
+	case []any:
+		out := make([]string, 0, len(s))
+		for _, item := range s {
+			if str, ok := item.(string); ok {
+				out = append(out, str)
+			}
+		}
+		return out
+	}
+	return nil
+}
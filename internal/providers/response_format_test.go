@@ -0,0 +1,275 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testPersonSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"name": map[string]any{"type": "string"},
+		"age":  map[string]any{"type": "integer", "minimum": 0},
+		"tags": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		"role": map[string]any{"type": "string", "enum": []any{"admin", "user"}},
+	},
+	"required": []any{"name", "age"},
+}
+
+func TestValidateJSONSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"valid", `{"name":"a","age":3,"tags":["x"],"role":"user"}`, ""},
+		{"optional null allowed", `{"name":"a","age":3,"role":null}`, ""},
+		{"missing required", `{"name":"a"}`, `missing required property "age"`},
+		{"wrong type", `{"name":"a","age":"3"}`, "$.age: expected type integer"},
+		{"non-integer", `{"name":"a","age":3.5}`, "$.age: expected type integer"},
+		{"below minimum", `{"name":"a","age":-1}`, "below minimum"},
+		{"enum violation", `{"name":"a","age":1,"role":"root"}`, "$.role"},
+		{"nested array item", `{"name":"a","age":1,"tags":["x",2]}`, "$.tags[1]"},
+		{"root type", `[1,2]`, "$: expected type object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v any
+			if err := json.Unmarshal([]byte(tt.input), &v); err != nil {
+				t.Fatal(err)
+			}
+			err := ValidateJSONSchema(testPersonSchema, v)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`{"a":1}`, `{"a":1}`},
+		{"```json\n{\"a\":1}\n```", `{"a":1}`},
+		{"Here you go:\n[1,2]\nHope that helps", `[1,2]`},
+		{"no json here", "no json here"},
+	}
+	for _, tt := range tests {
+		if got := ExtractJSON(tt.in); got != tt.want {
+			t.Errorf("ExtractJSON(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestOpenAIBuildRequestBody_ResponseFormat(t *testing.T) {
+	p := NewOpenAIProvider("openai", "k", "https://api.openai.com/v1", "gpt-4o")
+	body := p.buildRequestBody("gpt-4o", ChatRequest{
+		Messages:       []Message{{Role: "user", Content: "hi"}},
+		ResponseFormat: NewJSONSchemaFormat("person", testPersonSchema),
+	}, false)
+
+	rf, ok := body["response_format"].(map[string]any)
+	if !ok {
+		t.Fatalf("response_format missing: %#v", body)
+	}
+	if rf["type"] != ResponseFormatJSONSchema {
+		t.Errorf("type = %v, want json_schema", rf["type"])
+	}
+	js := rf["json_schema"].(map[string]any)
+	if js["name"] != "person" || js["strict"] != true {
+		t.Errorf("json_schema = %#v", js)
+	}
+	// Strict mode: every property required, additionalProperties false.
+	schema := js["schema"].(map[string]any)
+	if schema["additionalProperties"] != false {
+		t.Errorf("strict schema missing additionalProperties:false")
+	}
+	if req, _ := schema["required"].([]any); len(req) != 4 {
+		t.Errorf("strict schema required = %v, want all 4 properties", schema["required"])
+	}
+	// Caller's schema must not be mutated.
+	if _, mutated := testPersonSchema["additionalProperties"]; mutated {
+		t.Error("strict transform mutated caller schema")
+	}
+}
+
+func TestDashScopeBuildRequestBody_ResponseFormatUsesJSONObject(t *testing.T) {
+	p := NewDashScopeProvider("dashscope", "k", "", "")
+	body := p.buildRequestBody("qwen3-max", ChatRequest{
+		Messages: []Message{
+			{Role: "system", Content: "You extract people."},
+			{Role: "user", Content: "hi"},
+		},
+		ResponseFormat: NewJSONSchemaFormat("person", testPersonSchema),
+	}, false)
+
+	rf := body["response_format"].(map[string]any)
+	if rf["type"] != ResponseFormatJSONObject {
+		t.Errorf("type = %v, want json_object", rf["type"])
+	}
+	msgs := body["messages"].([]map[string]any)
+	sys, _ := msgs[0]["content"].(string)
+	if !strings.Contains(sys, "You extract people.") || !strings.Contains(sys, "JSON Schema") {
+		t.Errorf("system prompt missing schema instruction: %q", sys)
+	}
+}
+
+func TestOpenAICompatBuildRequestBody_ResponseFormatFallsBackToJSONObject(t *testing.T) {
+	p := NewOpenAIProvider("groq", "k", "https://api.groq.com/openai/v1", "llama-3.3-70b")
+	if SupportsStructuredOutput(p) {
+		t.Error("OpenAI-compatible backend should not claim json_schema support")
+	}
+
+	// ChatStructured has already put the schema in the prompt; it must not be repeated.
+	rf := NewJSONSchemaFormat("person", testPersonSchema)
+	body := p.buildRequestBody("llama-3.3-70b", ChatRequest{
+		Messages:       rf.withSystemInstruction([]Message{{Role: "user", Content: "hi"}}),
+		ResponseFormat: rf,
+	}, false)
+
+	if got := body["response_format"].(map[string]any)["type"]; got != ResponseFormatJSONObject {
+		t.Errorf("type = %v, want json_object", got)
+	}
+	msgs := body["messages"].([]map[string]any)
+	sys, _ := msgs[0]["content"].(string)
+	if n := strings.Count(sys, "JSON Schema"); n != 1 {
+		t.Errorf("schema instruction appears %d times in %q", n, sys)
+	}
+}
+
+func TestAnthropicBuildRequestBody_ResponseFormatForcesTool(t *testing.T) {
+	p := NewAnthropicProvider("k")
+	body := p.buildRequestBody("claude-sonnet-4-5", ChatRequest{
+		Messages:       []Message{{Role: "user", Content: "hi"}},
+		Options:        map[string]any{OptThinkingLevel: "high"},
+		ResponseFormat: NewJSONSchemaFormat("person", testPersonSchema),
+	}, false)
+
+	tools := body["tools"].([]map[string]any)
+	if len(tools) != 1 || tools[0]["name"] != "person" {
+		t.Fatalf("tools = %#v", tools)
+	}
+	choice := body["tool_choice"].(map[string]any)
+	if choice["type"] != "tool" || choice["name"] != "person" {
+		t.Errorf("tool_choice = %#v", choice)
+	}
+	if _, ok := body["thinking"]; ok {
+		t.Error("thinking must be disabled when tool_choice is forced")
+	}
+}
+
+func TestApplyAnthropicResponseFormat(t *testing.T) {
+	resp := &ChatResponse{
+		FinishReason: "tool_calls",
+		ToolCalls: []ToolCall{{
+			ID:        "toolu_1",
+			Name:      "person",
+			Arguments: map[string]any{"name": "a", "age": float64(3)},
+		}},
+		RawAssistantContent: json.RawMessage(`[]`),
+	}
+	applyAnthropicResponseFormat(NewJSONSchemaFormat("person", testPersonSchema), resp)
+
+	if len(resp.ToolCalls) != 0 {
+		t.Errorf("forced tool call should be removed, got %d", len(resp.ToolCalls))
+	}
+	if resp.FinishReason != "stop" {
+		t.Errorf("FinishReason = %q, want stop", resp.FinishReason)
+	}
+	if resp.Content != `{"age":3,"name":"a"}` {
+		t.Errorf("Content = %q", resp.Content)
+	}
+}
+
+func TestCodexBuildRequestBody_ResponseFormat(t *testing.T) {
+	p := NewCodexProvider("codex", nil, "", "gpt-5.3-codex")
+	body := p.buildRequestBody(ChatRequest{
+		Messages:       []Message{{Role: "user", Content: "hi"}},
+		ResponseFormat: NewJSONSchemaFormat("person", testPersonSchema),
+	}, false)
+
+	text := body["text"].(map[string]any)
+	format := text["format"].(map[string]any)
+	if format["type"] != ResponseFormatJSONSchema || format["name"] != "person" {
+		t.Errorf("text.format = %#v", format)
+	}
+}
+
+// scriptedProvider returns canned contents in order; no CapabilitiesAware, so
+// ChatStructured treats it as a provider without native structured output.
+type scriptedProvider struct {
+	contents []string
+	reqs     []ChatRequest
+}
+
+func (p *scriptedProvider) Chat(_ context.Context, req ChatRequest) (*ChatResponse, error) {
+	p.reqs = append(p.reqs, req)
+	idx := len(p.reqs) - 1
+	if idx >= len(p.contents) {
+		idx = len(p.contents) - 1
+	}
+	return &ChatResponse{Content: p.contents[idx], FinishReason: "stop"}, nil
+}
+
+func (p *scriptedProvider) ChatStream(ctx context.Context, req ChatRequest, _ func(StreamChunk)) (*ChatResponse, error) {
+	return p.Chat(ctx, req)
+}
+func (p *scriptedProvider) DefaultModel() string { return "scripted" }
+func (p *scriptedProvider) Name() string         { return "scripted" }
+
+func TestChatStructured_RetriesUntilValid(t *testing.T) {
+	p := &scriptedProvider{contents: []string{
+		"sure! here it is",
+		`{"name":"a"}`,
+		"```json\n{\"name\":\"a\",\"age\":3}\n```",
+	}}
+	resp, err := ChatStructured(context.Background(), p, ChatRequest{
+		Messages:       []Message{{Role: "user", Content: "who?"}},
+		ResponseFormat: NewJSONSchemaFormat("person", testPersonSchema),
+	}, nil)
+	if err != nil {
+		t.Fatalf("ChatStructured: %v", err)
+	}
+	if resp.Content != `{"name":"a","age":3}` {
+		t.Errorf("Content = %q", resp.Content)
+	}
+	if len(p.reqs) != 3 {
+		t.Fatalf("calls = %d, want 3", len(p.reqs))
+	}
+	// Prompt fallback: schema injected as a system message.
+	if first := p.reqs[0].Messages[0]; first.Role != "system" || !strings.Contains(first.Content, "JSON Schema") {
+		t.Errorf("expected injected schema instruction, got %#v", first)
+	}
+	// Retry carries the validation error back to the model.
+	last := p.reqs[2].Messages[len(p.reqs[2].Messages)-1]
+	if !strings.Contains(last.Content, `missing required property "age"`) {
+		t.Errorf("retry feedback = %q", last.Content)
+	}
+}
+
+func TestChatStructured_ExhaustedReturnsLastResponse(t *testing.T) {
+	p := &scriptedProvider{contents: []string{"nope"}}
+	resp, err := ChatStructured(context.Background(), p, ChatRequest{
+		ResponseFormat: &ResponseFormat{Type: ResponseFormatJSONObject},
+	}, nil)
+	if !errors.Is(err, ErrStructuredOutputInvalid) {
+		t.Fatalf("err = %v, want ErrStructuredOutputInvalid", err)
+	}
+	if resp == nil || resp.Content != "nope" {
+		t.Errorf("expected last response to be returned, got %#v", resp)
+	}
+	if len(p.reqs) != structuredOutputMaxAttempts {
+		t.Errorf("calls = %d, want %d", len(p.reqs), structuredOutputMaxAttempts)
+	}
+}
@@ -0,0 +1,101 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// structuredOutputMaxAttempts bounds validate-and-retry rounds in ChatStructured.
+// One initial call plus two corrective retries keeps background workers cheap.
+const structuredOutputMaxAttempts = 3
+
+// ErrStructuredOutputInvalid is returned (wrapped) when every attempt produced
+// output that failed to decode or validate against the requested schema.
+var ErrStructuredOutputInvalid = errors.New("structured output invalid")
+
+// ChatFunc performs a single chat call. Lets ChatStructured callers route
+// through usage caps or other wrappers instead of calling Provider.Chat directly.
+type ChatFunc func(ctx context.Context, req ChatRequest) (*ChatResponse, error)
+
+// SupportsStructuredOutput reports whether the provider enforces
+// ChatRequest.ResponseFormat natively (checked via CapabilitiesAware).
+func SupportsStructuredOutput(p Provider) bool {
+	if ca, ok := p.(CapabilitiesAware); ok {
+		return ca.Capabilities().StructuredOutput
+	}
+	return false
+}
+
+// ChatStructured sends req with req.ResponseFormat and returns a response whose
+// Content is a single JSON value that decodes (and, for json_schema, validates).
+//
+// Providers with native support receive the format as-is. Others get the schema
+// as a system-prompt instruction. Either way the output is validated and, on
+// failure, the model is shown the error and asked again (validate-and-retry).
+// Each attempt goes through call, so per-call usage accounting (usage caps,
+// tracing) sees every retry. call may be nil, in which case p.Chat is used.
+//
+// On validation failure the last response is returned alongside an error
+// wrapping ErrStructuredOutputInvalid, so callers can inspect FinishReason
+// (e.g. shrink the input after a "length" truncation).
+func ChatStructured(ctx context.Context, p Provider, req ChatRequest, call ChatFunc) (*ChatResponse, error) {
+	if req.ResponseFormat == nil {
+		return nil, fmt.Errorf("chat structured: ResponseFormat is required")
+	}
+	if call == nil {
+		call = p.Chat
+	}
+	if !SupportsStructuredOutput(p) {
+		req.Messages = req.ResponseFormat.withSystemInstruction(req.Messages)
+	}
+
+	var last *ChatResponse
+	var lastErr error
+	for attempt := 1; attempt <= structuredOutputMaxAttempts; attempt++ {
+		resp, err := call(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		content, verr := validateStructuredContent(req.ResponseFormat, resp.Content)
+		if verr == nil {
+			resp.Content = content
+			return resp, nil
+		}
+		last, lastErr = resp, verr
+		if resp.FinishReason == "length" {
+			// Retrying truncated output with a longer transcript only makes it worse.
+			break
+		}
+		slog.Debug("structured output: invalid response, retrying",
+			"provider", p.Name(), "attempt", attempt, "error", verr)
+
+		msgs := make([]Message, len(req.Messages), len(req.Messages)+2)
+		copy(msgs, req.Messages)
+		req.Messages = append(msgs,
+			Message{Role: "assistant", Content: resp.Content},
+			Message{Role: "user", Content: fmt.Sprintf(
+				"Your previous reply was not valid: %v\nReply again with ONLY the corrected JSON.", verr)},
+		)
+	}
+	return last, fmt.Errorf("%w: %v", ErrStructuredOutputInvalid, lastErr)
+}
+
+// validateStructuredContent extracts the JSON value from content and checks it
+// against the format. Returns the normalized JSON text on success.
+func validateStructuredContent(f *ResponseFormat, content string) (string, error) {
+	raw := ExtractJSON(content)
+	var value any
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return "", fmt.Errorf("not valid JSON: %w", err)
+	}
+	if f.HasSchema() {
+		if err := ValidateJSONSchema(f.Schema, value); err != nil {
+			return "", err
+		}
+	}
+	return raw, nil
+}
@@ -25,6 +25,11 @@ const (
 	OptFastMode             = "fast_mode"
 	OptPromptCacheKey       = "prompt_cache_key"
 	OptPromptCacheRetention = "prompt_cache_retention"
+
+	// OptCachedContent (string) names a Gemini context cache ("cachedContents/...")
+	// to read the request prefix from. The cache already holds the system
+	// instruction and tools, so the native Gemini provider omits them.
+	OptCachedContent = "cached_content"
 )
 
 // TokenSource provides an OAuth access token (with auto-refresh).
@@ -78,6 +83,10 @@ type ChatRequest struct {
 	Tools    []ToolDefinition `json:"tools,omitempty"`
 	Model    string           `json:"model,omitempty"`
 	Options  map[string]any   `json:"options,omitempty"`
+
+	// ResponseFormat requests schema-constrained JSON output (nil = free text).
+	// See ChatStructured for the validate-and-retry fallback.
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
 }
 
 // ChatResponse is the result from an LLM call.
@@ -103,6 +112,14 @@ type ChatResponse struct {
 	// Images holds generated images returned by image_generation_call tools (Codex).
 	// Not persisted to DB; populated at runtime from provider response.
 	Images []ImageContent `json:"-"`
+
+	// Grounding carries Google Search grounding (queries, sources, supports)
+	// from the native Gemini provider. Nil for other providers.
+	Grounding *GroundingMetadata `json:"grounding,omitempty"`
+
+	// CacheHit is set when ResponseCacheProvider served the response from
+	// cache instead of calling the model. Runtime only.
+	CacheHit *ResponseCacheHit `json:"-"`
 }
 
 // StreamChunk is a piece of a streaming response.
@@ -210,4 +227,5 @@ type Usage struct {
 	RequestCount                      int  `json:"request_count,omitempty"`
 	ImageCount                        int  `json:"image_count,omitempty"`
 	WebSearchCount                    int  `json:"web_search_count,omitempty"`
+	Batch                             bool `json:"batch,omitempty"` // billed at batch API rates (BatchDiscountPercent off)
 }
@@ -145,6 +145,25 @@ func (b *FsBridge) Stat(ctx context.Context, path string) (string, error) {
 	return stdout, nil
 }
 
+// Remove deletes a file inside the container. A missing file is not an error.
+func (b *FsBridge) Remove(ctx context.Context, path string) error {
+	resolved := b.resolvePath(path)
+
+	if err := b.validateExistingTargetIfPresent(ctx, resolved); err != nil {
+		return err
+	}
+
+	_, stderr, exitCode, err := b.dockerExec(ctx, nil, "rm", "-f", "--", resolved)
+	if err != nil {
+		return fmt.Errorf("fsbridge remove: %w", err)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("remove failed: %s", strings.TrimSpace(stderr))
+	}
+
+	return nil
+}
+
 // resolvePath resolves a path relative to the container workdir.
 // Validates that absolute paths stay within the workdir (defense in depth).
 func (b *FsBridge) resolvePath(path string) string {
@@ -0,0 +1,107 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// Process is a long-lived command attached to a sandbox, used by stateful
+// tools (code_interpreter) that keep an interpreter running between calls.
+//
+// Stdout and Stderr must be drained by the caller. Closing Stdin signals EOF
+// to the command; Kill stops the client side of the attachment.
+type Process struct {
+	Stdin  io.WriteCloser
+	Stdout io.ReadCloser
+	Stderr io.ReadCloser
+
+	cmd   *exec.Cmd
+	touch func()
+}
+
+// StartProcess wires pipes to cmd and starts it. touch, if non-nil, is called
+// by Touch to mark the owning sandbox as in use.
+func StartProcess(cmd *exec.Cmd, touch func()) (*Process, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &Process{Stdin: stdin, Stdout: stdout, Stderr: stderr, cmd: cmd, touch: touch}, nil
+}
+
+// Wait waits for the command to exit. It must be called after the caller has
+// finished reading Stdout and Stderr.
+func (p *Process) Wait() error { return p.cmd.Wait() }
+
+// Kill closes stdin and kills the command.
+func (p *Process) Kill() error {
+	p.Stdin.Close()
+	if p.cmd.Process == nil {
+		return nil
+	}
+	return p.cmd.Process.Kill()
+}
+
+// Touch marks the owning sandbox as in use so idle pruning does not remove
+// a container whose process is still being driven.
+func (p *Process) Touch() {
+	if p.touch != nil {
+		p.touch()
+	}
+}
+
+// ProcessStarter is implemented by sandboxes that can run long-lived
+// processes. The process outlives ctx; ctx only bounds the start itself.
+type ProcessStarter interface {
+	StartProcess(ctx context.Context, command []string, workDir string, opts ...ExecOption) (*Process, error)
+}
+
+// StartProcess runs command via "docker exec -i" with stdin attached.
+//
+// Killing the returned Process stops the docker client only; the command
+// inside the container exits when its stdin closes. Callers that need to
+// stop a busy process signal it by PID through Exec.
+func (s *DockerSandbox) StartProcess(ctx context.Context, command []string, workDir string, opts ...ExecOption) (*Process, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.touch()
+
+	o := ApplyExecOpts(opts)
+	args := []string{"exec", "-i"}
+	for k, v := range o.Env {
+		args = append(args, "-e", k+"="+v)
+	}
+	if workDir != "" {
+		args = append(args, "-w", workDir)
+	}
+	args = append(args, s.containerID)
+	args = append(args, command...)
+
+	// Not CommandContext: the process lives across tool calls.
+	p, err := StartProcess(exec.Command("docker", args...), s.touch)
+	if err != nil {
+		return nil, fmt.Errorf("docker exec: %w", err)
+	}
+	return p, nil
+}
+
+func (s *DockerSandbox) touch() {
+	s.mu.Lock()
+	s.lastUsed = time.Now()
+	s.mu.Unlock()
+}
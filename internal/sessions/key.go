@@ -17,6 +17,7 @@
 //	Forum topic: {channel}:group:{groupId}:topic:{topicId}
 //	Subagent:    subagent:{label}
 //	Cron:        cron:{jobId}
+//	Merged user: user:{userId}
 //
 // Examples:
 //
@@ -25,6 +26,7 @@
 //	agent:default:telegram:group:-100123456:topic:99
 //	agent:default:subagent:my-task
 //	agent:default:cron:reminder-job-id
+//	agent:default:user:alice
 package sessions
 
 import (
@@ -95,6 +97,21 @@ func IsTeamSession(key string) bool {
 	return strings.HasPrefix(rest, "team:")
 }
 
+// BuildUserSessionKey builds the cross-channel DM session key for a merged
+// contact. All channel identities merged into the same tenant user share it,
+// so a conversation started on one channel continues on another.
+//
+//	agent:{agentId}:user:{userID}
+func BuildUserSessionKey(agentID, userID string) string {
+	return fmt.Sprintf("agent:%s:user:%s", agentID, userID)
+}
+
+// IsUserSession checks if a session key is a cross-channel DM session.
+func IsUserSession(key string) bool {
+	_, rest := ParseSessionKey(key)
+	return strings.HasPrefix(rest, "user:")
+}
+
 // BuildCronSessionKey builds the session key for a cron job.
 // Each cron job gets one persistent session (all runs share the same history).
 //
@@ -108,6 +108,34 @@ func TestIsTeamSession(t *testing.T) {
 	}
 }
 
+func TestBuildUserSessionKey(t *testing.T) {
+	got := BuildUserSessionKey("my-agent", "alice")
+	want := "agent:my-agent:user:alice"
+	if got != want {
+		t.Errorf("BuildUserSessionKey = %q, want %q", got, want)
+	}
+}
+
+// TestIsUserSession distinguishes cross-channel DM keys from per-channel ones.
+func TestIsUserSession(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"agent:my-agent:user:alice", true},
+		{"agent:my-agent:telegram:direct:user", false},
+		{"agent:my-agent:team:t1:c1", false},
+		{"not-a-session-key", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := IsUserSession(tt.key); got != tt.want {
+				t.Errorf("IsUserSession(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
 // TestBuildCronSessionKey_DoublePrefix guards against double-prefixing.
 func TestBuildCronSessionKey_DoublePrefix(t *testing.T) {
 	// If jobID is already a canonical session key, only the rest part is used.
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nextlevelbuilder/goclaw/internal/config"
@@ -508,13 +509,18 @@ func (a *AgentData) ParseChatGPTOAuthRouting() *ChatGPTOAuthRoutingConfig {
 	return routing
 }
 
+// Model fallback strategies. Values match the providers.RoutePolicy* constants.
 const (
-	ModelFallbackStrategyPriority = "priority_order"
+	ModelFallbackStrategyPriority            = "priority_order"
+	ModelFallbackStrategyCheapestFitsContext = "cheapest_fits_context"
+	ModelFallbackStrategyLowestLatency       = "lowest_latency"
+	ModelFallbackStrategyWeightedRoundRobin  = "weighted_round_robin"
 )
 
 type ModelFallbackCandidate struct {
 	Provider string `json:"provider,omitempty" db:"-"`
 	Model    string `json:"model,omitempty" db:"-"`
+	Weight   int    `json:"weight,omitempty" db:"-"` // weighted_round_robin only
 }
 
 type ModelFallbackConfig struct {
@@ -523,6 +529,7 @@ type ModelFallbackConfig struct {
 	Candidates      []ModelFallbackCandidate `json:"candidates,omitempty" db:"-"`
 	MaxAttempts     int                      `json:"max_attempts,omitempty" db:"-"`
 	CooldownEnabled *bool                    `json:"cooldown_enabled,omitempty" db:"-"`
+	PrimaryWeight   int                      `json:"primary_weight,omitempty" db:"-"` // weighted_round_robin only
 }
 
 func (a *AgentData) ParseModelFallback() *ModelFallbackConfig {
@@ -549,11 +556,12 @@ func NormalizeModelFallbackConfig(cfg *ModelFallbackConfig) *ModelFallbackConfig
 		Strategy:        cfg.Strategy,
 		MaxAttempts:     cfg.MaxAttempts,
 		CooldownEnabled: cfg.CooldownEnabled,
+		PrimaryWeight:   max(cfg.PrimaryWeight, 0),
 	}
-	if out.Strategy == "" {
-		out.Strategy = ModelFallbackStrategyPriority
-	}
-	if out.Strategy != ModelFallbackStrategyPriority {
+	switch out.Strategy {
+	case ModelFallbackStrategyPriority, ModelFallbackStrategyCheapestFitsContext,
+		ModelFallbackStrategyLowestLatency, ModelFallbackStrategyWeightedRoundRobin:
+	default:
 		out.Strategy = ModelFallbackStrategyPriority
 	}
 	seen := make(map[string]bool, len(cfg.Candidates))
@@ -563,6 +571,7 @@ func NormalizeModelFallbackConfig(cfg *ModelFallbackConfig) *ModelFallbackConfig
 		if c.Provider == "" || c.Model == "" {
 			continue
 		}
+		c.Weight = max(c.Weight, 0)
 		key := c.Provider + "\x00" + c.Model
 		if seen[key] {
 			continue
@@ -692,6 +701,57 @@ func normalizeProviderNames(names []string) []string {
 	return out
 }
 
+// ResponseCacheConfig controls the per-agent LLM response cache, read from
+// other_config.response_cache. No DB column — opt-in, off by default.
+type ResponseCacheConfig struct {
+	Enabled             bool    `json:"enabled" db:"-"`
+	Mode                string  `json:"mode,omitempty" db:"-"` // "exact" (default) or "semantic"
+	TTLSeconds          int     `json:"ttl_seconds,omitempty" db:"-"`
+	SimilarityThreshold float64 `json:"similarity_threshold,omitempty" db:"-"` // semantic only, (0,1]
+}
+
+// ParseResponseCache returns the normalized response cache config, or nil when
+// not set, disabled or malformed.
+func (a *AgentData) ParseResponseCache() *ResponseCacheConfig {
+	if len(a.OtherConfig) <= 2 {
+		return nil
+	}
+	var bag struct {
+		ResponseCache *ResponseCacheConfig `json:"response_cache"`
+	}
+	if json.Unmarshal(a.OtherConfig, &bag) != nil || bag.ResponseCache == nil || !bag.ResponseCache.Enabled {
+		return nil
+	}
+	cfg := *bag.ResponseCache
+	if cfg.Mode != providers.ResponseCacheModeSemantic {
+		cfg.Mode = providers.ResponseCacheModeExact
+	}
+	if cfg.TTLSeconds <= 0 {
+		cfg.TTLSeconds = int(providers.DefaultResponseCacheTTL / time.Second)
+	}
+	if cfg.SimilarityThreshold <= 0 || cfg.SimilarityThreshold > 1 {
+		cfg.SimilarityThreshold = providers.DefaultResponseCacheSimilarity
+	}
+	return &cfg
+}
+
+// RecordingConfig controls provider call recording for trace replay, read from
+// other_config.recording. No DB column — opt-in, off by default.
+type RecordingConfig struct {
+	Enabled bool `json:"enabled" db:"-"`
+}
+
+// ParseRecording reports whether provider calls should be archived for replay.
+func (a *AgentData) ParseRecording() bool {
+	if len(a.OtherConfig) <= 2 {
+		return false
+	}
+	var bag struct {
+		Recording *RecordingConfig `json:"recording"`
+	}
+	return json.Unmarshal(a.OtherConfig, &bag) == nil && bag.Recording != nil && bag.Recording.Enabled
+}
+
 // ParseShellDenyGroups reads shell deny group toggles from the dedicated column.
 // Returns nil if not configured (all defaults apply).
 func (a *AgentData) ParseShellDenyGroups() map[string]bool {
@@ -775,8 +835,10 @@ type AgentContextStore interface {
 	SetUserContextFile(ctx context.Context, agentID uuid.UUID, userID, fileName, content string) error
 	DeleteUserContextFile(ctx context.Context, agentID uuid.UUID, userID, fileName string) error
 	// MigrateUserDataOnMerge moves per-user data from oldUserIDs to newUserID when contacts are merged.
-	// Covers: user_context_files, user_agent_overrides, user_agent_profiles, memory_documents/chunks.
-	// On conflict, keeps the newest by updated_at. Best-effort per table.
+	// Covers: user_context_files, user_agent_overrides, user_agent_profiles, memory_documents/chunks,
+	// knowledge graph entities/relations and episodic summaries.
+	// On conflict the tenant user's row wins; relations of dropped duplicate KG entities
+	// are re-pointed to the survivor. Runs in one transaction: on error nothing moves.
 	MigrateUserDataOnMerge(ctx context.Context, oldUserIDs []string, newUserID string) error
 	GetUserOverride(ctx context.Context, agentID uuid.UUID, userID string) (*UserAgentOverrideData, error)
 	SetUserOverride(ctx context.Context, override *UserAgentOverrideData) error
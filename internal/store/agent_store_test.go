@@ -460,3 +460,51 @@ func TestParseToolsConfigWaitPolicy(t *testing.T) {
 		t.Fatalf("ToolCallPrefix = %q", got.ToolCallPrefix)
 	}
 }
+
+func TestParseResponseCache(t *testing.T) {
+	t.Parallel()
+	if got := (&AgentData{OtherConfig: json.RawMessage(`{"response_cache":{"enabled":false,"mode":"semantic"}}`)}).ParseResponseCache(); got != nil {
+		t.Fatalf("disabled config = %#v, want nil", got)
+	}
+
+	agent := AgentData{OtherConfig: json.RawMessage(`{"response_cache":{"enabled":true,"mode":"fuzzy","similarity_threshold":1.5}}`)}
+	got := agent.ParseResponseCache()
+	if got == nil {
+		t.Fatal("ParseResponseCache() = nil")
+	}
+	if got.Mode != "exact" || got.TTLSeconds != 3600 || got.SimilarityThreshold != 0.95 {
+		t.Fatalf("normalized config = %#v", got)
+	}
+
+	agent.OtherConfig = json.RawMessage(`{"response_cache":{"enabled":true,"mode":"semantic","ttl_seconds":60,"similarity_threshold":0.9}}`)
+	got = agent.ParseResponseCache()
+	if got == nil || got.Mode != "semantic" || got.TTLSeconds != 60 || got.SimilarityThreshold != 0.9 {
+		t.Fatalf("semantic config = %#v", got)
+	}
+}
+
+func TestNormalizeModelFallbackConfigStrategies(t *testing.T) {
+	t.Parallel()
+	for _, strategy := range []string{
+		ModelFallbackStrategyCheapestFitsContext,
+		ModelFallbackStrategyLowestLatency,
+		ModelFallbackStrategyWeightedRoundRobin,
+	} {
+		got := NormalizeModelFallbackConfig(&ModelFallbackConfig{Strategy: strategy})
+		if got.Strategy != strategy {
+			t.Fatalf("Strategy = %q, want %q", got.Strategy, strategy)
+		}
+	}
+
+	got := NormalizeModelFallbackConfig(&ModelFallbackConfig{
+		Strategy:      "random",
+		PrimaryWeight: -1,
+		Candidates:    []ModelFallbackCandidate{{Provider: "p", Model: "m", Weight: -3}},
+	})
+	if got.Strategy != ModelFallbackStrategyPriority {
+		t.Fatalf("Strategy = %q, want %q", got.Strategy, ModelFallbackStrategyPriority)
+	}
+	if got.PrimaryWeight != 0 || got.Candidates[0].Weight != 0 {
+		t.Fatalf("weights = %d/%d, want 0/0", got.PrimaryWeight, got.Candidates[0].Weight)
+	}
+}
@@ -55,6 +55,31 @@ func (c *ContactCollector) EnsureContact(ctx context.Context, channelType, chann
 	c.seen.Set(ctx, key, true, contactSeenTTL)
 }
 
+// RecordDirectChat remembers the DM chat a sender talks to the bot in, so
+// the agent can reach them there later (message handoff). Call after
+// EnsureContact; skips the DB while the same chat was recently recorded.
+func (c *ContactCollector) RecordDirectChat(ctx context.Context, channelType, channelInstance, senderID, chatID string) {
+	if senderID == "" || chatID == "" {
+		return
+	}
+	tid := TenantIDFromContext(ctx)
+	key := "dm:" + tid.String() + ":" + channelType + ":" + channelInstance + ":" + senderID + ":" + chatID
+	if _, ok := c.seen.Get(ctx, key); ok {
+		return
+	}
+	if err := c.store.SetDirectChat(ctx, channelType, channelInstance, senderID, chatID); err != nil {
+		slog.Warn("contact_collector.set_direct_chat_failed",
+			"error", err,
+			"tenant_id", tid,
+			"channel", channelType,
+			"instance", channelInstance,
+			"sender", senderID,
+		)
+		return
+	}
+	c.seen.Set(ctx, key, true, contactSeenTTL)
+}
+
 // ResolveTenantUserID delegates to the underlying ContactStore.
 func (c *ContactCollector) ResolveTenantUserID(ctx context.Context, channelType, senderID string) (string, error) {
 	return c.store.ResolveTenantUserID(ctx, channelType, senderID)
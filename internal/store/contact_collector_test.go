@@ -30,6 +30,10 @@ type mockUpsertCall struct {
 	threadType      string
 }
 
+func (m *mockContactStore) SetDirectChat(context.Context, string, string, string, string) error {
+	return nil
+}
+
 func (m *mockContactStore) UpsertContact(ctx context.Context, channelType, channelInstance, senderID, userID, displayName, username, peerKind, contactType, threadID, threadType string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -76,6 +80,9 @@ func (m *mockContactStore) UnmergeContacts(_ context.Context, _ []uuid.UUID) err
 func (m *mockContactStore) GetContactsByMergedID(_ context.Context, _ uuid.UUID) ([]ChannelContact, error) {
 	return nil, nil
 }
+func (m *mockContactStore) GetContactsByTenantUserID(_ context.Context, _ string) ([]ChannelContact, error) {
+	return nil, nil
+}
 
 func (m *mockContactStore) upsertCount() int {
 	m.mu.Lock()
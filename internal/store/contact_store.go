@@ -22,6 +22,7 @@ type ChannelContact struct {
 	ContactType     string     `json:"contact_type" db:"contact_type"` // "user", "group", or "topic"
 	ThreadID        *string    `json:"thread_id,omitempty" db:"thread_id"`
 	ThreadType      *string    `json:"thread_type,omitempty" db:"thread_type"`
+	DMChatID        *string    `json:"dm_chat_id,omitempty" db:"dm_chat_id"` // direct chat with the bot on ChannelInstance
 	MergedID        *uuid.UUID `json:"merged_id,omitempty" db:"merged_id"`
 	FirstSeenAt     time.Time  `json:"first_seen_at" db:"first_seen_at"`
 	LastSeenAt      time.Time  `json:"last_seen_at" db:"last_seen_at"`
@@ -45,6 +46,11 @@ type ContactStore interface {
 	// Pass empty threadID/threadType for base contacts (DM, group root).
 	UpsertContact(ctx context.Context, channelType, channelInstance, senderID, userID, displayName, username, peerKind, contactType, threadID, threadType string) error
 
+	// SetDirectChat records the direct-message chat ID for a base contact and
+	// the channel instance it belongs to. On some platforms (Discord, Slack,
+	// Feishu) the DM chat ID differs from the sender ID.
+	SetDirectChat(ctx context.Context, channelType, channelInstance, senderID, chatID string) error
+
 	// ListContacts searches contacts with pagination and filters.
 	ListContacts(ctx context.Context, opts ContactListOpts) ([]ChannelContact, error)
 
@@ -73,6 +79,10 @@ type ContactStore interface {
 	// Tenant-scoped via context.
 	GetContactsByMergedID(ctx context.Context, mergedID uuid.UUID) ([]ChannelContact, error)
 
+	// GetContactsByTenantUserID returns all contacts merged into the tenant_user
+	// whose user_id is userID, most recently seen first. Tenant-scoped via context.
+	GetContactsByTenantUserID(ctx context.Context, userID string) ([]ChannelContact, error)
+
 	// ResolveTenantUserID looks up a contact by (channelType, senderID) and, if
 	// the contact has been merged, returns the linked tenant_user's user_id.
 	// Returns ("", nil) when the contact is not found or not merged.
@@ -39,6 +39,22 @@ type EmbeddingProvider interface {
 	Embed(ctx context.Context, texts []string) ([][]float32, error)
 }
 
+// Reranker re-scores first-stage search candidates against the query
+// (second-stage retrieval). Results are ordered by relevance, best first,
+// and hold at most topN entries (topN <= 0 = all).
+type Reranker interface {
+	Name() string
+	Model() string
+	Rerank(ctx context.Context, query string, docs []string, topN int) ([]RerankResult, error)
+}
+
+// RerankResult is one reranked candidate: its index in the input docs and
+// a relevance score (higher is better; 0-1 for the HTTP rerankers).
+type RerankResult struct {
+	Index int
+	Score float64
+}
+
 // DocumentDetail provides full document info including chunk/embedding stats.
 type DocumentDetail struct {
 	Path          string `json:"path" db:"path"`
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log/slog"
 	"path/filepath"
 	"strings"
 	"time"
@@ -156,63 +155,132 @@ func (s *PGAgentStore) MigrateUserDataOnMerge(ctx context.Context, oldUserIDs []
 	delArgs = append(delArgs, baseArgs[:len(oldUserIDs)]...)
 	delArgs = append(delArgs, tArgs...)
 
-	// Helper: migrate + delete for one table. DO NOTHING on conflict —
-	// existing tenant user data always wins (canonical identity).
-	migrate := func(insertQ, deleteQ string) {
-		if _, err := s.db.ExecContext(ctx, insertQ, baseArgs...); err != nil {
-			slog.Warn("merge.migrate", "error", err)
-		}
-		if _, err := s.db.ExecContext(ctx, deleteQ, delArgs...); err != nil {
-			slog.Warn("merge.cleanup", "error", err)
+	// Each step runs in one transaction so a failed merge leaves both users'
+	// data untouched. Inserts use DO NOTHING on conflict — existing tenant
+	// user data always wins (canonical identity).
+	type mergeStep struct {
+		name  string
+		query string
+		args  []any
+	}
+	moveAndClean := func(name, insertQ, deleteQ string) []mergeStep {
+		return []mergeStep{
+			{name, insertQ, baseArgs},
+			{name + "_cleanup", deleteQ, delArgs},
 		}
 	}
+	var steps []mergeStep
 
 	// 1. user_context_files: UNIQUE(agent_id, user_id, file_name)
-	migrate(
+	steps = append(steps, moveAndClean("user_context_files",
 		fmt.Sprintf(`INSERT INTO user_context_files (id, agent_id, user_id, file_name, content, updated_at, tenant_id)
 			SELECT gen_random_uuid(), agent_id, %s, file_name, content, updated_at, tenant_id
 			FROM user_context_files WHERE user_id IN (%s)%s
 			ON CONFLICT (agent_id, user_id, file_name) DO NOTHING`, newP, inClause, tClauseIns),
 		fmt.Sprintf(`DELETE FROM user_context_files WHERE user_id IN (%s)%s`, inClause, tClauseDel),
-	)
+	)...)
 
 	// 2. user_agent_overrides: UNIQUE(agent_id, user_id)
-	migrate(
+	steps = append(steps, moveAndClean("user_agent_overrides",
 		fmt.Sprintf(`INSERT INTO user_agent_overrides (id, agent_id, user_id, provider, model, settings, created_at, updated_at, tenant_id)
 			SELECT gen_random_uuid(), agent_id, %s, provider, model, settings, created_at, updated_at, tenant_id
 			FROM user_agent_overrides WHERE user_id IN (%s)%s
 			ON CONFLICT (agent_id, user_id) DO NOTHING`, newP, inClause, tClauseIns),
 		fmt.Sprintf(`DELETE FROM user_agent_overrides WHERE user_id IN (%s)%s`, inClause, tClauseDel),
-	)
+	)...)
 
 	// 3. user_agent_profiles: PK(agent_id, user_id)
-	migrate(
+	steps = append(steps, moveAndClean("user_agent_profiles",
 		fmt.Sprintf(`INSERT INTO user_agent_profiles (agent_id, user_id, workspace, first_seen_at, last_seen_at, metadata, tenant_id)
 			SELECT agent_id, %s, workspace, first_seen_at, last_seen_at, metadata, tenant_id
 			FROM user_agent_profiles WHERE user_id IN (%s)%s
 			ON CONFLICT (agent_id, user_id) DO NOTHING`, newP, inClause, tClauseIns),
 		fmt.Sprintf(`DELETE FROM user_agent_profiles WHERE user_id IN (%s)%s`, inClause, tClauseDel),
-	)
+	)...)
 
 	// 4. memory_documents: UNIQUE(agent_id, COALESCE(user_id,''), path)
-	migrate(
+	steps = append(steps, moveAndClean("memory_documents",
 		fmt.Sprintf(`INSERT INTO memory_documents (id, agent_id, user_id, path, content, hash, updated_at, created_at, tenant_id)
 			SELECT gen_random_uuid(), agent_id, %s, path, content, hash, updated_at, created_at, tenant_id
 			FROM memory_documents WHERE user_id IN (%s)%s
 			ON CONFLICT (agent_id, COALESCE(user_id,''), path) DO NOTHING`, newP, inClause, tClauseIns),
 		fmt.Sprintf(`DELETE FROM memory_documents WHERE user_id IN (%s)%s`, inClause, tClauseDel),
-	)
+	)...)
 
 	// 5. memory_chunks: FK on document_id — cascade from memory_documents delete handles this.
 	// But orphan chunks (where document was already migrated) need cleanup.
 	// Simply re-point remaining chunks whose document still has old user_id.
 	// Uses INSERT-style arg layout (newUserID at N+1, tenant at N+2).
-	repoint := fmt.Sprintf(`UPDATE memory_chunks SET user_id = %s WHERE user_id IN (%s)%s`, newP, inClause, tClauseIns)
-	if _, err := s.db.ExecContext(ctx, repoint, baseArgs...); err != nil {
-		slog.Warn("merge.migrate_chunks", "error", err)
+	steps = append(steps, mergeStep{"memory_chunks",
+		fmt.Sprintf(`UPDATE memory_chunks SET user_id = %s WHERE user_id IN (%s)%s`, newP, inClause, tClauseIns), baseArgs})
+
+	// 6. kg_entities: UNIQUE(agent_id, user_id, external_id). Moved in place so
+	// relations keep their endpoints; the newest entity per external_id moves
+	// unless the tenant user already has one.
+	steps = append(steps, mergeStep{"kg_entities", fmt.Sprintf(`UPDATE kg_entities e SET user_id = %s
+		WHERE e.id IN (
+			SELECT DISTINCT ON (agent_id, external_id) id FROM kg_entities
+			WHERE user_id IN (%s)%s
+			ORDER BY agent_id, external_id, updated_at DESC)
+		AND NOT EXISTS (SELECT 1 FROM kg_entities d
+			WHERE d.agent_id = e.agent_id AND d.user_id = %s AND d.external_id = e.external_id)`,
+		newP, inClause, tClauseIns, newP), baseArgs})
+
+	// 7. kg_relations: every entity left behind now has a survivor with the same
+	// (agent_id, external_id) under the tenant user. Re-point relation endpoints
+	// to it before the duplicates are deleted, otherwise the delete cascades.
+	for _, col := range []string{"source_entity_id", "target_entity_id"} {
+		steps = append(steps, mergeStep{"kg_relations_" + col, fmt.Sprintf(`WITH dup AS (
+			SELECT d.id, w.id AS survivor
+			FROM (SELECT id, agent_id, external_id FROM kg_entities WHERE user_id IN (%s)%s) d
+			JOIN kg_entities w ON w.agent_id = d.agent_id AND w.external_id = d.external_id AND w.user_id = %s)
+			UPDATE kg_relations r SET %s = dup.survivor FROM dup WHERE r.%s = dup.id`,
+			inClause, tClauseIns, newP, col, col), baseArgs})
 	}
+	// Relations follow their owner; the newest of any that collapsed onto the
+	// same edge moves and the rest are dropped.
+	steps = append(steps, mergeStep{"kg_relations", fmt.Sprintf(`UPDATE kg_relations r SET user_id = %s
+		WHERE r.id IN (
+			SELECT DISTINCT ON (agent_id, source_entity_id, relation_type, target_entity_id) id FROM kg_relations
+			WHERE user_id IN (%s)%s
+			ORDER BY agent_id, source_entity_id, relation_type, target_entity_id, created_at DESC)
+		AND NOT EXISTS (SELECT 1 FROM kg_relations d
+			WHERE d.agent_id = r.agent_id AND d.user_id = %s AND d.source_entity_id = r.source_entity_id
+			AND d.relation_type = r.relation_type AND d.target_entity_id = r.target_entity_id)`,
+		newP, inClause, tClauseIns, newP), baseArgs})
+	steps = append(steps,
+		mergeStep{"kg_relations_cleanup",
+			fmt.Sprintf(`DELETE FROM kg_relations WHERE user_id IN (%s)%s`, inClause, tClauseDel), delArgs},
+		// Entities left behind duplicate the tenant user's and are no longer
+		// referenced; dropping them cascades only to their dedup candidates.
+		mergeStep{"kg_entities_cleanup",
+			fmt.Sprintf(`DELETE FROM kg_entities WHERE user_id IN (%s)%s`, inClause, tClauseDel), delArgs},
+		mergeStep{"kg_dedup",
+			fmt.Sprintf(`UPDATE kg_dedup_candidates SET user_id = %s WHERE user_id IN (%s)%s`, newP, inClause, tClauseIns), baseArgs},
+	)
 
-	return nil
+	// 8. episodic_summaries: unique (agent_id, user_id, source_id).
+	steps = append(steps,
+		mergeStep{"episodic", fmt.Sprintf(`UPDATE episodic_summaries e SET user_id = %s
+		WHERE e.user_id IN (%s)%s
+		AND NOT EXISTS (SELECT 1 FROM episodic_summaries d
+			WHERE d.agent_id = e.agent_id AND d.user_id = %s AND d.source_id = e.source_id)`,
+			newP, inClause, tClauseIns, newP), baseArgs},
+		mergeStep{"episodic_cleanup",
+			fmt.Sprintf(`DELETE FROM episodic_summaries WHERE user_id IN (%s)%s`, inClause, tClauseDel), delArgs},
+	)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+	for _, st := range steps {
+		if _, err := tx.ExecContext(ctx, st.query, st.args...); err != nil {
+			return fmt.Errorf("merge %s: %w", st.name, err)
+		}
+	}
+	return tx.Commit()
 }
 
 // --- User-Agent Profiles ---
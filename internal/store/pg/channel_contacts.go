@@ -44,6 +44,19 @@ func (s *PGContactStore) UpsertContact(ctx context.Context, channelType, channel
 	return err
 }
 
+func (s *PGContactStore) SetDirectChat(ctx context.Context, channelType, channelInstance, senderID, chatID string) error {
+	tenantID := store.TenantIDFromContext(ctx)
+	if tenantID == uuid.Nil {
+		tenantID = store.MasterTenantID
+	}
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE channel_contacts SET dm_chat_id = $1, channel_instance = COALESCE(NULLIF($2,''), channel_instance)
+		WHERE tenant_id = $3 AND channel_type = $4 AND sender_id = $5 AND thread_id IS NULL`,
+		chatID, channelInstance, tenantID, channelType, senderID,
+	)
+	return err
+}
+
 func contactWhereClause(ctx context.Context, opts store.ContactListOpts) (string, []any, int) {
 	var conditions []string
 	var args []any
@@ -100,7 +113,7 @@ func (s *PGContactStore) ListContacts(ctx context.Context, opts store.ContactLis
 	where, args, argIdx := contactWhereClause(ctx, opts)
 
 	query := `SELECT id, channel_type, channel_instance, sender_id, user_id,
-		display_name, username, avatar_url, peer_kind, contact_type, thread_id, thread_type, merged_id,
+		display_name, username, avatar_url, peer_kind, contact_type, thread_id, thread_type, dm_chat_id, merged_id,
 		first_seen_at, last_seen_at
 		FROM channel_contacts` + where + " ORDER BY last_seen_at DESC"
 
@@ -128,7 +141,7 @@ func (s *PGContactStore) ListContacts(ctx context.Context, opts store.ContactLis
 		var c store.ChannelContact
 		if err := rows.Scan(
 			&c.ID, &c.ChannelType, &c.ChannelInstance, &c.SenderID, &c.UserID,
-			&c.DisplayName, &c.Username, &c.AvatarURL, &c.PeerKind, &c.ContactType, &c.ThreadID, &c.ThreadType, &c.MergedID,
+			&c.DisplayName, &c.Username, &c.AvatarURL, &c.PeerKind, &c.ContactType, &c.ThreadID, &c.ThreadType, &c.DMChatID, &c.MergedID,
 			&c.FirstSeenAt, &c.LastSeenAt,
 		); err != nil {
 			return nil, err
@@ -165,7 +178,7 @@ func (s *PGContactStore) GetContactsBySenderIDs(ctx context.Context, senderIDs [
 
 	query := fmt.Sprintf(`SELECT DISTINCT ON (sender_id)
 		id, channel_type, channel_instance, sender_id, user_id,
-		display_name, username, avatar_url, peer_kind, contact_type, thread_id, thread_type, merged_id,
+		display_name, username, avatar_url, peer_kind, contact_type, thread_id, thread_type, dm_chat_id, merged_id,
 		first_seen_at, last_seen_at
 		FROM channel_contacts
 		WHERE sender_id IN (%s) AND tenant_id = %s
@@ -182,7 +195,7 @@ func (s *PGContactStore) GetContactsBySenderIDs(ctx context.Context, senderIDs [
 		var c store.ChannelContact
 		if err := rows.Scan(
 			&c.ID, &c.ChannelType, &c.ChannelInstance, &c.SenderID, &c.UserID,
-			&c.DisplayName, &c.Username, &c.AvatarURL, &c.PeerKind, &c.ContactType, &c.ThreadID, &c.ThreadType, &c.MergedID,
+			&c.DisplayName, &c.Username, &c.AvatarURL, &c.PeerKind, &c.ContactType, &c.ThreadID, &c.ThreadType, &c.DMChatID, &c.MergedID,
 			&c.FirstSeenAt, &c.LastSeenAt,
 		); err != nil {
 			return nil, err
@@ -197,14 +210,14 @@ func (s *PGContactStore) GetContactByID(ctx context.Context, id uuid.UUID) (*sto
 	row := s.db.QueryRowContext(ctx,
 		`SELECT id, channel_type, channel_instance, sender_id, user_id,
 			display_name, username, avatar_url, peer_kind, contact_type,
-			thread_id, thread_type, merged_id,
+			thread_id, thread_type, dm_chat_id, merged_id,
 			first_seen_at, last_seen_at
 		FROM channel_contacts WHERE id = $1 AND tenant_id = $2`, id, tid)
 	var c store.ChannelContact
 	if err := row.Scan(
 		&c.ID, &c.ChannelType, &c.ChannelInstance, &c.SenderID, &c.UserID,
 		&c.DisplayName, &c.Username, &c.AvatarURL, &c.PeerKind, &c.ContactType,
-		&c.ThreadID, &c.ThreadType, &c.MergedID,
+		&c.ThreadID, &c.ThreadType, &c.DMChatID, &c.MergedID,
 		&c.FirstSeenAt, &c.LastSeenAt,
 	); err != nil {
 		return nil, err
@@ -299,7 +312,7 @@ func (s *PGContactStore) GetContactsByMergedID(ctx context.Context, mergedID uui
 	tid := store.TenantIDFromContext(ctx)
 
 	q := `SELECT id, channel_type, channel_instance, sender_id, user_id,
-		display_name, username, avatar_url, peer_kind, contact_type, thread_id, thread_type, merged_id,
+		display_name, username, avatar_url, peer_kind, contact_type, thread_id, thread_type, dm_chat_id, merged_id,
 		first_seen_at, last_seen_at
 		FROM channel_contacts WHERE merged_id = $1 AND tenant_id = $2
 		ORDER BY last_seen_at DESC`
@@ -315,7 +328,39 @@ func (s *PGContactStore) GetContactsByMergedID(ctx context.Context, mergedID uui
 		var c store.ChannelContact
 		if err := rows.Scan(
 			&c.ID, &c.ChannelType, &c.ChannelInstance, &c.SenderID, &c.UserID,
-			&c.DisplayName, &c.Username, &c.AvatarURL, &c.PeerKind, &c.ContactType, &c.ThreadID, &c.ThreadType, &c.MergedID,
+			&c.DisplayName, &c.Username, &c.AvatarURL, &c.PeerKind, &c.ContactType, &c.ThreadID, &c.ThreadType, &c.DMChatID, &c.MergedID,
+			&c.FirstSeenAt, &c.LastSeenAt,
+		); err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, c)
+	}
+	return contacts, rows.Err()
+}
+
+func (s *PGContactStore) GetContactsByTenantUserID(ctx context.Context, userID string) ([]store.ChannelContact, error) {
+	tid := store.TenantIDFromContext(ctx)
+
+	q := `SELECT cc.id, cc.channel_type, cc.channel_instance, cc.sender_id, cc.user_id,
+		cc.display_name, cc.username, cc.avatar_url, cc.peer_kind, cc.contact_type, cc.thread_id, cc.thread_type, cc.dm_chat_id, cc.merged_id,
+		cc.first_seen_at, cc.last_seen_at
+		FROM channel_contacts cc
+		JOIN tenant_users tu ON cc.merged_id = tu.id
+		WHERE tu.user_id = $1 AND cc.tenant_id = $2
+		ORDER BY cc.last_seen_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, q, userID, tid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contacts []store.ChannelContact
+	for rows.Next() {
+		var c store.ChannelContact
+		if err := rows.Scan(
+			&c.ID, &c.ChannelType, &c.ChannelInstance, &c.SenderID, &c.UserID,
+			&c.DisplayName, &c.Username, &c.AvatarURL, &c.PeerKind, &c.ContactType, &c.ThreadID, &c.ThreadType, &c.DMChatID, &c.MergedID,
 			&c.FirstSeenAt, &c.LastSeenAt,
 		); err != nil {
 			return nil, err
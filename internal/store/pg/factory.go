@@ -34,6 +34,7 @@ func NewPGStores(cfg store.StoreConfig) (*store.Stores, error) {
 		Providers:              NewPGProviderStore(db, cfg.EncryptionKey),
 		Tracing:                NewPGTracingStore(db),
 		RunTimeline:            NewPGRunTimelineStore(db),
+		SentMessages:           NewPGSentMessageStore(db),
 		MCP:                    NewPGMCPServerStore(db, cfg.EncryptionKey),
 		ChannelInstances:       NewPGChannelInstanceStore(db, cfg.EncryptionKey),
 		ConfigSecrets:          NewPGConfigSecretsStore(db, cfg.EncryptionKey),
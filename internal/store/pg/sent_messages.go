@@ -0,0 +1,169 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// PGSentMessageStore implements store.SentMessageStore backed by PostgreSQL.
+type PGSentMessageStore struct {
+	db *sql.DB
+}
+
+func NewPGSentMessageStore(db *sql.DB) *PGSentMessageStore {
+	return &PGSentMessageStore{db: db}
+}
+
+// RecordSentMessage inserts a sent message. Recording the same platform
+// message twice (e.g. a placeholder edited into the final reply) is a no-op.
+func (s *PGSentMessageStore) RecordSentMessage(ctx context.Context, msg *store.SentMessage) error {
+	if msg.ID == uuid.Nil {
+		msg.ID = store.GenNewID()
+	}
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = time.Now()
+	}
+	msg.UpdatedAt = msg.CreatedAt
+	if msg.Status == "" {
+		msg.Status = store.SentMessageStatusSent
+	}
+	tenantID := tenantIDForInsert(ctx)
+	msg.TenantID = tenantID
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO sent_messages
+		 (id, tenant_id, run_id, agent_id, channel, chat_id, message_id, preview, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		 ON CONFLICT (tenant_id, channel, chat_id, message_id) DO NOTHING`,
+		msg.ID, tenantID, msg.RunID, nilUUID(msg.AgentID), msg.Channel, msg.ChatID, msg.MessageID,
+		nilStr(msg.Preview), msg.Status, msg.CreatedAt, msg.UpdatedAt,
+	)
+	return err
+}
+
+func (s *PGSentMessageStore) GetSentMessage(ctx context.Context, id uuid.UUID) (*store.SentMessage, error) {
+	q := `SELECT id, tenant_id, run_id, agent_id, channel, chat_id, message_id, preview, status, created_at, updated_at
+		 FROM sent_messages WHERE id = $1`
+	args := []any{id}
+	if !store.IsCrossTenant(ctx) {
+		tid, err := requireTenantID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		q += " AND tenant_id = $2"
+		args = append(args, tid)
+	}
+	var row sentMessageRow
+	if err := pkgSqlxDB.GetContext(ctx, &row, q, args...); err != nil {
+		return nil, err
+	}
+	msg := row.toStore()
+	return &msg, nil
+}
+
+func (s *PGSentMessageStore) ListSentMessages(ctx context.Context, opts store.SentMessageListOpts) ([]store.SentMessage, error) {
+	where, args := buildSentMessageWhere(ctx, opts)
+	limit := opts.Limit
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	q := `SELECT id, tenant_id, run_id, agent_id, channel, chat_id, message_id, preview, status, created_at, updated_at
+		 FROM sent_messages` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC LIMIT %d", limit)
+
+	var rows []sentMessageRow
+	if err := pkgSqlxDB.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, err
+	}
+	msgs := make([]store.SentMessage, len(rows))
+	for i, row := range rows {
+		msgs[i] = row.toStore()
+	}
+	return msgs, nil
+}
+
+func (s *PGSentMessageStore) UpdateSentMessageStatus(ctx context.Context, id uuid.UUID, status string) error {
+	q := `UPDATE sent_messages SET status = $1, updated_at = $2 WHERE id = $3`
+	args := []any{status, time.Now(), id}
+	if !store.IsCrossTenant(ctx) {
+		tid, err := requireTenantID(ctx)
+		if err != nil {
+			return err
+		}
+		q += " AND tenant_id = $4"
+		args = append(args, tid)
+	}
+	res, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func buildSentMessageWhere(ctx context.Context, opts store.SentMessageListOpts) (string, []any) {
+	var conditions []string
+	var args []any
+	argIdx := 1
+	if !store.IsCrossTenant(ctx) {
+		tenantID := store.TenantIDFromContext(ctx)
+		if tenantID == uuid.Nil {
+			return " WHERE 1=0", nil
+		}
+		conditions = append(conditions, fmt.Sprintf("tenant_id = $%d", argIdx))
+		args = append(args, tenantID)
+		argIdx++
+	}
+	scoped := false
+	if opts.RunID != "" {
+		conditions = append(conditions, fmt.Sprintf("run_id = $%d", argIdx))
+		args = append(args, opts.RunID)
+		argIdx++
+		scoped = true
+	}
+	if opts.Channel != "" && opts.ChatID != "" {
+		conditions = append(conditions, fmt.Sprintf("channel = $%d AND chat_id = $%d", argIdx, argIdx+1))
+		args = append(args, opts.Channel, opts.ChatID)
+		argIdx += 2
+		scoped = true
+	}
+	if opts.AgentID != uuid.Nil {
+		conditions = append(conditions, fmt.Sprintf("agent_id = $%d", argIdx))
+		args = append(args, opts.AgentID)
+	}
+	if !scoped {
+		return " WHERE 1=0", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+type sentMessageRow struct {
+	ID        uuid.UUID  `db:"id"`
+	TenantID  uuid.UUID  `db:"tenant_id"`
+	RunID     string     `db:"run_id"`
+	AgentID   *uuid.UUID `db:"agent_id"`
+	Channel   string     `db:"channel"`
+	ChatID    string     `db:"chat_id"`
+	MessageID string     `db:"message_id"`
+	Preview   *string    `db:"preview"`
+	Status    string     `db:"status"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+}
+
+func (r sentMessageRow) toStore() store.SentMessage {
+	return store.SentMessage{
+		ID: r.ID, TenantID: r.TenantID, RunID: r.RunID, AgentID: r.AgentID,
+		Channel: r.Channel, ChatID: r.ChatID, MessageID: r.MessageID,
+		Preview: derefStr(r.Preview), Status: r.Status,
+		CreatedAt: r.CreatedAt, UpdatedAt: r.UpdatedAt,
+	}
+}
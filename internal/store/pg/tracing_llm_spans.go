@@ -0,0 +1,56 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const listRecentLLMSpansQuery = `
+SELECT
+	sp.tenant_id,
+	sp.start_time,
+	COALESCE(sp.duration_ms, 0),
+	COALESCE(sp.output_tokens, 0),
+	sp.status,
+	COALESCE(sp.provider, ''),
+	COALESCE(sp.model, '')
+FROM spans sp
+WHERE sp.span_type = 'llm_call'
+  AND sp.start_time > $1
+  AND sp.status IN ('completed', 'error')
+  AND sp.provider IS NOT NULL
+  AND sp.metadata->'response_cache' IS NULL
+ORDER BY sp.start_time DESC
+LIMIT $2`
+
+// ListRecentLLMSpans returns finished LLM call spans started after since, newest first.
+func (s *PGTracingStore) ListRecentLLMSpans(ctx context.Context, since time.Time, limit int) ([]store.LLMSpanSample, error) {
+	rows, err := s.db.QueryContext(ctx, listRecentLLMSpansQuery, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	spans := make([]store.LLMSpanSample, 0, limit)
+	for rows.Next() {
+		var item store.LLMSpanSample
+		if err := rows.Scan(
+			&item.TenantID,
+			&item.StartedAt,
+			&item.DurationMS,
+			&item.OutputTokens,
+			&item.Status,
+			&item.Provider,
+			&item.Model,
+		); err != nil {
+			return nil, err
+		}
+		spans = append(spans, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return spans, nil
+}
@@ -210,6 +210,13 @@ func usagePricingModelCandidates(providerName, providerType, modelID string) []s
 		return nil
 	}
 	out := []string{modelID}
+	if providerType == store.ProviderBedrock {
+		// "us.anthropic.claude-sonnet-4-5-20250929-v1:0" → "anthropic/claude-sonnet-4-5-20250929"
+		if vendorModel := bedrockPricingModel(modelID); vendorModel != "" {
+			out = appendUniqueString(out, vendorModel)
+		}
+		return out
+	}
 	if strings.Contains(modelID, "/") {
 		return out
 	}
@@ -219,6 +226,39 @@ func usagePricingModelCandidates(providerName, providerType, modelID string) []s
 	return out
 }
 
+// bedrockPricingModel maps a Bedrock model or inference-profile ID to the
+// OpenRouter-style "vendor/model" key used by the pricing catalog. The
+// optional geo prefix ("us.", "eu.", "apac.", "global.") and the
+// "-v1:0" version suffix are dropped. ARNs are returned unmapped ("").
+func bedrockPricingModel(modelID string) string {
+	if strings.HasPrefix(modelID, "arn:") {
+		return ""
+	}
+	parts := strings.Split(modelID, ".")
+	if len(parts) < 2 {
+		return ""
+	}
+	switch parts[0] {
+	case "us", "eu", "apac", "global", "us-gov", "jp", "au", "ca":
+		parts = parts[1:]
+	}
+	if len(parts) < 2 {
+		return ""
+	}
+	vendor := parts[0]
+	model := strings.Join(parts[1:], ".")
+	if idx := strings.LastIndex(model, "-v"); idx > 0 {
+		model = model[:idx]
+	}
+	switch vendor {
+	case "meta":
+		vendor = "meta-llama"
+	case "mistral":
+		vendor = "mistralai"
+	}
+	return vendor + "/" + model
+}
+
 func openRouterProviderPrefixes(providerName, providerType string) []string {
 	switch providerType {
 	case store.ProviderAnthropicNative:
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// KeyPoolSettings holds API key rotation config stored in llm_providers.settings
+// under "key_pool". The extra keys themselves live encrypted in config_secrets
+// (see ProviderAPIKeysSecretKey); api_key stays the primary key.
+type KeyPoolSettings struct {
+	Strategy string `json:"strategy,omitempty"` // "round_robin" (default) or "least_used"
+}
+
+// ParseKeyPoolSettings extracts key pool config from settings JSONB.
+// Missing or malformed settings yield the zero value.
+func ParseKeyPoolSettings(settings json.RawMessage) KeyPoolSettings {
+	var s struct {
+		KeyPool KeyPoolSettings `json:"key_pool"`
+	}
+	if len(settings) > 0 {
+		_ = json.Unmarshal(settings, &s)
+	}
+	return s.KeyPool
+}
+
+// ProviderAPIKeysSecretKey is the config_secrets key holding a provider's
+// extra API keys (JSON array). Keyed by ID so renames keep the pool.
+func ProviderAPIKeysSecretKey(providerID uuid.UUID) string {
+	return "provider." + providerID.String() + ".api_keys"
+}
+
+// LoadProviderAPIKeys returns the provider's primary key followed by its extra
+// keys from config_secrets. Read errors (including "no extra keys") yield just
+// the primary key. ctx must carry the provider's tenant.
+func LoadProviderAPIKeys(ctx context.Context, secrets ConfigSecretsStore, p *LLMProviderData) []string {
+	keys := []string{p.APIKey}
+	if secrets == nil || p.ID == uuid.Nil {
+		return keys
+	}
+	raw, err := secrets.Get(ctx, ProviderAPIKeysSecretKey(p.ID))
+	if err != nil || raw == "" {
+		return keys
+	}
+	var extra []string
+	if json.Unmarshal([]byte(raw), &extra) != nil {
+		return keys
+	}
+	return append(keys, extra...)
+}
+
+// SaveProviderAPIKeys replaces a provider's extra API keys. Blank entries are
+// dropped; an empty list deletes the secret.
+func SaveProviderAPIKeys(ctx context.Context, secrets ConfigSecretsStore, providerID uuid.UUID, extra []string) error {
+	keys := make([]string, 0, len(extra))
+	for _, k := range extra {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return secrets.Delete(ctx, ProviderAPIKeysSecretKey(providerID))
+	}
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	return secrets.Set(ctx, ProviderAPIKeysSecretKey(providerID), string(data))
+}
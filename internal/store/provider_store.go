@@ -35,6 +35,8 @@ const (
 	ProviderBytePlusCoding  = "byteplus_coding" // BytePlus ModelArk Coding Plan
 	ProviderVertex          = "vertex"          // Google Cloud Vertex AI (OAuth2 service account + ADC)
 	ProviderKimiCoding      = "kimi_coding"     // Moonshot Kimi Coding (OpenAI-compat, requires fixed User-Agent)
+	ProviderBedrock         = "bedrock"         // AWS Bedrock Converse API (SigV4, API key or default credential chain)
+	ProviderLlamaCpp        = "llamacpp"        // local llama.cpp server (llama-server, API key optional)
 
 	// Novita AI defaults.
 	NovitaDefaultAPIBase = "https://api.novita.ai/openai"
@@ -85,6 +87,8 @@ var ValidProviderTypes = map[string]bool{
 	ProviderBytePlusCoding:  true,
 	ProviderVertex:          true,
 	ProviderKimiCoding:      true,
+	ProviderBedrock:         true,
+	ProviderLlamaCpp:        true,
 }
 
 // VertexProviderSettings holds Vertex-specific config stored in llm_providers.settings JSONB.
@@ -110,6 +114,50 @@ func ParseVertexProviderSettings(settings json.RawMessage) *VertexProviderSettin
 	return &s
 }
 
+// BedrockProviderSettings holds Bedrock-specific config stored in llm_providers.settings JSONB.
+// When AccessKeyID is set, the provider's api_key holds the matching secret access key;
+// otherwise api_key (if any) is a Bedrock API key, and an empty api_key falls back to the
+// AWS default credential chain (env, shared profile, instance/task role).
+type BedrockProviderSettings struct {
+	Region      string `json:"region"`
+	AccessKeyID string `json:"access_key_id,omitempty"`
+	Model       string `json:"model,omitempty"` // optional default model or inference profile ID
+}
+
+// ParseBedrockProviderSettings extracts Bedrock config from settings JSONB.
+// Returns nil if region is missing (required).
+func ParseBedrockProviderSettings(settings json.RawMessage) *BedrockProviderSettings {
+	if len(settings) == 0 {
+		return nil
+	}
+	var s BedrockProviderSettings
+	if json.Unmarshal(settings, &s) != nil {
+		return nil
+	}
+	if s.Region == "" {
+		return nil
+	}
+	return &s
+}
+
+// OllamaProviderSettings holds optional local-Ollama tuning stored in llm_providers.settings JSONB.
+type OllamaProviderSettings struct {
+	Model     string `json:"model,omitempty"`      // default model (default "llama3.3")
+	KeepAlive string `json:"keep_alive,omitempty"` // how long models stay loaded, e.g. "10m", "-1"
+	NumCtx    int    `json:"num_ctx,omitempty"`    // context length requested per call (0 = Modelfile default)
+	AutoPull  *bool  `json:"auto_pull,omitempty"`  // pull missing models on demand (default true)
+}
+
+// ParseOllamaProviderSettings extracts Ollama tuning from settings JSONB.
+// Missing or invalid settings yield the zero value (all defaults).
+func ParseOllamaProviderSettings(settings json.RawMessage) OllamaProviderSettings {
+	var s OllamaProviderSettings
+	if len(settings) > 0 {
+		_ = json.Unmarshal(settings, &s)
+	}
+	return s
+}
+
 // LLMProviderData represents an LLM provider configuration.
 type LLMProviderData struct {
 	BaseModel
@@ -218,6 +266,7 @@ var NoEmbeddingTypes = map[string]bool{
 	ProviderClaudeCLI:       true,
 	ProviderChatGPTOAuth:    true,
 	ProviderVertex:          true, // Vertex embeddings live on a different native endpoint, not on /endpoints/openapi
+	ProviderBedrock:         true, // Bedrock embeddings use InvokeModel, not an OpenAI-compatible /embeddings
 }
 
 // ProviderStore manages LLM providers.
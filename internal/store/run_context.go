@@ -50,6 +50,7 @@ type RunContext struct {
 	MemoryCfg           *config.MemoryConfig
 	SandboxCfg          *sandbox.Config
 	WaitToolCfg         *config.WaitToolPolicy
+	HTTPRequestToolCfg  *config.HTTPRequestToolPolicy
 	ShellDenyGroups     map[string]bool
 
 	// Workspace
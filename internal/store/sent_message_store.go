@@ -0,0 +1,48 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SentMessageStatusSent      = "sent"
+	SentMessageStatusEdited    = "edited"
+	SentMessageStatusRetracted = "retracted"
+)
+
+// SentMessage records one platform message delivered on behalf of an agent
+// run, so it can later be edited or retracted.
+type SentMessage struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	TenantID  uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	RunID     string     `json:"run_id" db:"run_id"`
+	AgentID   *uuid.UUID `json:"agent_id,omitempty" db:"agent_id"`
+	Channel   string     `json:"channel" db:"channel"`
+	ChatID    string     `json:"chat_id" db:"chat_id"`
+	MessageID string     `json:"message_id" db:"message_id"` // platform ID (Telegram message_id, Slack ts, Matrix event ID, ...)
+	Preview   string     `json:"preview,omitempty" db:"preview"`
+	Status    string     `json:"status" db:"status"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// SentMessageListOpts scopes a sent message read. Either RunID or
+// Channel+ChatID must be set; results are newest first.
+type SentMessageListOpts struct {
+	RunID   string
+	Channel string
+	ChatID  string
+	AgentID uuid.UUID // optional: only messages sent by this agent
+	Limit   int
+}
+
+// SentMessageStore tracks outbound messages per run for edit and retraction.
+type SentMessageStore interface {
+	RecordSentMessage(ctx context.Context, msg *SentMessage) error
+	GetSentMessage(ctx context.Context, id uuid.UUID) (*SentMessage, error)
+	ListSentMessages(ctx context.Context, opts SentMessageListOpts) ([]SentMessage, error)
+	UpdateSentMessageStatus(ctx context.Context, id uuid.UUID, status string) error
+}
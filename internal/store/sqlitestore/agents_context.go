@@ -8,7 +8,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log/slog"
 	"path/filepath"
 	"strings"
 	"time"
@@ -185,18 +184,26 @@ func (s *SQLiteAgentStore) MigrateUserDataOnMerge(ctx context.Context, oldUserID
 
 	uuid := `lower(hex(randomblob(4))||'-'||hex(randomblob(2))||'-'||hex(randomblob(2))||'-'||hex(randomblob(2))||'-'||hex(randomblob(6)))`
 
-	// DO NOTHING on conflict — existing tenant user data always wins.
-	migrate := func(insertQ, deleteQ string) {
-		if _, err := s.db.ExecContext(ctx, insertQ, baseArgs...); err != nil {
-			slog.Warn("merge.migrate", "error", err)
-		}
-		if _, err := s.db.ExecContext(ctx, deleteQ, delArgs...); err != nil {
-			slog.Warn("merge.cleanup", "error", err)
-		}
+	// Each step runs in one transaction so a failed merge leaves both users'
+	// data untouched. DO NOTHING on conflict — existing tenant user data always wins.
+	type mergeStep struct {
+		name  string
+		query string
+		args  []any
+	}
+	var steps []mergeStep
+	migrate := func(name, insertQ, deleteQ string) {
+		steps = append(steps,
+			mergeStep{name, insertQ, baseArgs},
+			mergeStep{name + "_cleanup", deleteQ, delArgs})
+	}
+	cleanup := func(name, table string) {
+		steps = append(steps, mergeStep{name,
+			fmt.Sprintf(`DELETE FROM %s WHERE user_id IN (%s)%s`, table, inClause, tClause), delArgs})
 	}
 
 	// 1. user_context_files
-	migrate(
+	migrate("user_context_files",
 		fmt.Sprintf(`INSERT INTO user_context_files (id, agent_id, user_id, file_name, content, updated_at, tenant_id)
 			SELECT %s, agent_id, ?, file_name, content, updated_at, tenant_id
 			FROM user_context_files WHERE user_id IN (%s)%s
@@ -205,7 +212,7 @@ func (s *SQLiteAgentStore) MigrateUserDataOnMerge(ctx context.Context, oldUserID
 	)
 
 	// 2. user_agent_overrides
-	migrate(
+	migrate("user_agent_overrides",
 		fmt.Sprintf(`INSERT INTO user_agent_overrides (id, agent_id, user_id, provider, model, settings, created_at, updated_at, tenant_id)
 			SELECT %s, agent_id, ?, provider, model, settings, created_at, updated_at, tenant_id
 			FROM user_agent_overrides WHERE user_id IN (%s)%s
@@ -214,7 +221,7 @@ func (s *SQLiteAgentStore) MigrateUserDataOnMerge(ctx context.Context, oldUserID
 	)
 
 	// 3. user_agent_profiles
-	migrate(
+	migrate("user_agent_profiles",
 		fmt.Sprintf(`INSERT INTO user_agent_profiles (agent_id, user_id, workspace, first_seen_at, last_seen_at, metadata, tenant_id)
 			SELECT agent_id, ?, workspace, first_seen_at, last_seen_at, metadata, tenant_id
 			FROM user_agent_profiles WHERE user_id IN (%s)%s
@@ -223,7 +230,7 @@ func (s *SQLiteAgentStore) MigrateUserDataOnMerge(ctx context.Context, oldUserID
 	)
 
 	// 4. memory_documents
-	migrate(
+	migrate("memory_documents",
 		fmt.Sprintf(`INSERT INTO memory_documents (id, agent_id, user_id, path, content, hash, updated_at, created_at, tenant_id)
 			SELECT %s, agent_id, ?, path, content, hash, updated_at, created_at, tenant_id
 			FROM memory_documents WHERE user_id IN (%s)%s
@@ -232,12 +239,78 @@ func (s *SQLiteAgentStore) MigrateUserDataOnMerge(ctx context.Context, oldUserID
 	)
 
 	// 5. memory_chunks: re-point remaining chunks.
-	repoint := fmt.Sprintf(`UPDATE memory_chunks SET user_id = ? WHERE user_id IN (%s)%s`, inClause, tClause)
-	if _, err := s.db.ExecContext(ctx, repoint, baseArgs...); err != nil {
-		slog.Warn("merge.migrate_chunks", "error", err)
+	steps = append(steps, mergeStep{"memory_chunks",
+		fmt.Sprintf(`UPDATE memory_chunks SET user_id = ? WHERE user_id IN (%s)%s`, inClause, tClause), baseArgs})
+
+	// In-place moves below take positional args: [newUserID, oldIDs..., tArgs..., extra...].
+	oldArgs := baseArgs[:len(oldUserIDs)]
+	update := func(name, q string, extra ...any) {
+		args := append([]any{newUserID}, oldArgs...)
+		args = append(args, tArgs...)
+		args = append(args, extra...)
+		steps = append(steps, mergeStep{name, q, args})
+	}
+
+	// 6. kg_entities: moved in place so relations keep their endpoints; the
+	// newest entity per external_id moves unless the tenant user already has one.
+	update("kg_entities", fmt.Sprintf(`UPDATE kg_entities SET user_id = ?
+		WHERE user_id IN (%s)%s
+		AND id = (SELECT x.id FROM kg_entities x
+			WHERE x.agent_id = kg_entities.agent_id AND x.external_id = kg_entities.external_id
+			AND x.user_id IN (%s) ORDER BY x.updated_at DESC LIMIT 1)
+		AND NOT EXISTS (SELECT 1 FROM kg_entities d
+			WHERE d.agent_id = kg_entities.agent_id AND d.user_id = ? AND d.external_id = kg_entities.external_id)`,
+		inClause, tClause, inClause), append(append([]any{}, oldArgs...), newUserID)...)
+
+	// 7. kg_relations: every entity left behind now has a survivor with the same
+	// (agent_id, external_id) under the tenant user. Re-point relation endpoints
+	// to it before the duplicates are deleted, otherwise the delete cascades.
+	for _, col := range []string{"source_entity_id", "target_entity_id"} {
+		update("kg_relations_"+col, fmt.Sprintf(`UPDATE kg_relations SET %s = COALESCE((
+			SELECT w.id FROM kg_entities d
+			JOIN kg_entities w ON w.agent_id = d.agent_id AND w.external_id = d.external_id AND w.user_id = ?
+			WHERE d.id = kg_relations.%s), %s)
+		WHERE %s IN (SELECT id FROM kg_entities WHERE user_id IN (%s)%s)`,
+			col, col, col, col, inClause, tClause))
+	}
+	// Relations follow their owner; the newest of any that collapsed onto the
+	// same edge moves and the rest are dropped.
+	update("kg_relations", fmt.Sprintf(`UPDATE kg_relations SET user_id = ?
+		WHERE user_id IN (%s)%s
+		AND id = (SELECT x.id FROM kg_relations x
+			WHERE x.agent_id = kg_relations.agent_id AND x.source_entity_id = kg_relations.source_entity_id
+			AND x.relation_type = kg_relations.relation_type AND x.target_entity_id = kg_relations.target_entity_id
+			AND x.user_id IN (%s) ORDER BY x.created_at DESC LIMIT 1)
+		AND NOT EXISTS (SELECT 1 FROM kg_relations d
+			WHERE d.agent_id = kg_relations.agent_id AND d.user_id = ? AND d.source_entity_id = kg_relations.source_entity_id
+			AND d.relation_type = kg_relations.relation_type AND d.target_entity_id = kg_relations.target_entity_id)`,
+		inClause, tClause, inClause), append(append([]any{}, oldArgs...), newUserID)...)
+	cleanup("kg_relations_cleanup", "kg_relations")
+
+	// Entities left behind duplicate the tenant user's and are no longer
+	// referenced; dropping them cascades only to their dedup candidates.
+	cleanup("kg_entities_cleanup", "kg_entities")
+	update("kg_dedup", fmt.Sprintf(`UPDATE kg_dedup_candidates SET user_id = ? WHERE user_id IN (%s)%s`, inClause, tClause))
+
+	// 8. episodic_summaries: unique (agent_id, user_id, source_id).
+	update("episodic", fmt.Sprintf(`UPDATE episodic_summaries SET user_id = ?
+		WHERE user_id IN (%s)%s
+		AND NOT EXISTS (SELECT 1 FROM episodic_summaries d
+			WHERE d.agent_id = episodic_summaries.agent_id AND d.user_id = ? AND d.source_id = episodic_summaries.source_id)`,
+		inClause, tClause), newUserID)
+	cleanup("episodic_cleanup", "episodic_summaries")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
 	}
-
-	return nil
+	defer tx.Rollback() //nolint:errcheck
+	for _, st := range steps {
+		if _, err := tx.ExecContext(ctx, st.query, st.args...); err != nil {
+			return fmt.Errorf("merge %s: %w", st.name, err)
+		}
+	}
+	return tx.Commit()
 }
 
 // --- User-Agent Profiles ---
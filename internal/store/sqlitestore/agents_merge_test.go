@@ -0,0 +1,161 @@
+//go:build sqlite || sqliteonly
+
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+func TestMigrateUserDataOnMergeMovesKnowledgeGraph(t *testing.T) {
+	db := openTestDB(t)
+	if err := EnsureSchema(db); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+	ctx := store.WithTenantID(context.Background(), store.MasterTenantID)
+	tid := store.MasterTenantID.String()
+	agentID := uuid.NewString()
+	mustExec(t, db, `INSERT INTO agents (id, tenant_id, agent_key, agent_type, status, provider, model, owner_id)
+		VALUES (?, ?, 'merge', 'predefined', 'active', 'p', 'm', 'o')`, agentID, tid)
+
+	entity := func(id, userID, externalID string) {
+		mustExec(t, db, `INSERT INTO kg_entities (id, agent_id, user_id, external_id, name, entity_type, tenant_id)
+			VALUES (?, ?, ?, ?, ?, 'person', ?)`, id, agentID, userID, externalID, externalID, tid)
+	}
+	relation := func(userID, src, tgt string) {
+		mustExec(t, db, `INSERT INTO kg_relations (id, agent_id, user_id, source_entity_id, relation_type, target_entity_id, tenant_id)
+			VALUES (?, ?, ?, ?, 'knows', ?, ?)`, uuid.NewString(), agentID, userID, src, tgt, tid)
+	}
+	// Telegram self knows alice and bob; the tenant user already has alice.
+	entity("tg-alice", "tg:1", "alice")
+	entity("tg-bob", "tg:1", "bob")
+	entity("tu-alice", "user-1", "alice")
+	relation("tg:1", "tg-alice", "tg-bob")
+	mustExec(t, db, `INSERT INTO episodic_summaries (id, tenant_id, agent_id, user_id, session_key, summary, source_id)
+		VALUES (?, ?, ?, 'tg:1', 'agent:merge:telegram:direct:1', 'talked about bob', 'sess-1')`, uuid.NewString(), tid, agentID)
+
+	if err := NewSQLiteAgentStore(db).MigrateUserDataOnMerge(ctx, []string{"tg:1"}, "user-1"); err != nil {
+		t.Fatalf("MigrateUserDataOnMerge: %v", err)
+	}
+
+	count := func(q string, args ...any) int {
+		t.Helper()
+		var n int
+		if err := db.QueryRow(q, args...).Scan(&n); err != nil && err != sql.ErrNoRows {
+			t.Fatalf("%s: %v", q, err)
+		}
+		return n
+	}
+	if n := count(`SELECT COUNT(*) FROM kg_entities WHERE user_id = 'tg:1'`); n != 0 {
+		t.Fatalf("old-user entities left = %d, want 0", n)
+	}
+	if n := count(`SELECT COUNT(*) FROM kg_entities WHERE user_id = 'user-1'`); n != 2 {
+		t.Fatalf("tenant-user entities = %d, want 2 (existing alice + moved bob)", n)
+	}
+	if n := count(`SELECT COUNT(*) FROM kg_entities WHERE id = 'tg-bob' AND user_id = 'user-1'`); n != 1 {
+		t.Fatal("bob should keep its ID when moved")
+	}
+	// The relation's source was a duplicate of the tenant user's alice, so it
+	// is re-pointed to the surviving entity rather than cascaded away.
+	if n := count(`SELECT COUNT(*) FROM kg_relations
+		WHERE user_id = 'user-1' AND source_entity_id = 'tu-alice' AND target_entity_id = 'tg-bob'`); n != 1 {
+		t.Fatalf("re-pointed relations = %d, want 1", n)
+	}
+	if n := count(`SELECT COUNT(*) FROM kg_relations`); n != 1 {
+		t.Fatalf("relations = %d, want 1", n)
+	}
+	if n := count(`SELECT COUNT(*) FROM episodic_summaries WHERE user_id = 'user-1'`); n != 1 {
+		t.Fatalf("episodic summaries moved = %d, want 1", n)
+	}
+}
+
+func TestMigrateUserDataOnMergeKeepsRelationsBetweenMovedEntities(t *testing.T) {
+	db := openTestDB(t)
+	if err := EnsureSchema(db); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+	ctx := store.WithTenantID(context.Background(), store.MasterTenantID)
+	tid := store.MasterTenantID.String()
+	agentID := uuid.NewString()
+	mustExec(t, db, `INSERT INTO agents (id, tenant_id, agent_key, agent_type, status, provider, model, owner_id)
+		VALUES (?, ?, 'merge', 'predefined', 'active', 'p', 'm', 'o')`, agentID, tid)
+	for _, id := range []string{"a", "b"} {
+		mustExec(t, db, `INSERT INTO kg_entities (id, agent_id, user_id, external_id, name, entity_type, tenant_id)
+			VALUES (?, ?, 'slack:U1', ?, ?, 'person', ?)`, id, agentID, id, id, tid)
+	}
+	mustExec(t, db, `INSERT INTO kg_relations (id, agent_id, user_id, source_entity_id, relation_type, target_entity_id, tenant_id)
+		VALUES (?, ?, 'slack:U1', 'a', 'knows', 'b', ?)`, uuid.NewString(), agentID, tid)
+
+	if err := NewSQLiteAgentStore(db).MigrateUserDataOnMerge(ctx, []string{"slack:U1"}, "user-1"); err != nil {
+		t.Fatalf("MigrateUserDataOnMerge: %v", err)
+	}
+
+	var userID string
+	if err := db.QueryRow(`SELECT user_id FROM kg_relations`).Scan(&userID); err != nil {
+		t.Fatalf("relation lost: %v", err)
+	}
+	if userID != "user-1" {
+		t.Fatalf("relation user_id = %q, want user-1", userID)
+	}
+}
+
+func TestMigrateUserDataOnMergeCollapsesCollidingRelations(t *testing.T) {
+	db := openTestDB(t)
+	if err := EnsureSchema(db); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+	ctx := store.WithTenantID(context.Background(), store.MasterTenantID)
+	tid := store.MasterTenantID.String()
+	agentID := uuid.NewString()
+	mustExec(t, db, `INSERT INTO agents (id, tenant_id, agent_key, agent_type, status, provider, model, owner_id)
+		VALUES (?, ?, 'merge', 'predefined', 'active', 'p', 'm', 'o')`, agentID, tid)
+	entity := func(id, userID, externalID string) {
+		mustExec(t, db, `INSERT INTO kg_entities (id, agent_id, user_id, external_id, name, entity_type, tenant_id)
+			VALUES (?, ?, ?, ?, ?, 'person', ?)`, id, agentID, userID, externalID, externalID, tid)
+	}
+	relation := func(userID, src, tgt string) {
+		mustExec(t, db, `INSERT INTO kg_relations (id, agent_id, user_id, source_entity_id, relation_type, target_entity_id, tenant_id)
+			VALUES (?, ?, ?, ?, 'knows', ?, ?)`, uuid.NewString(), agentID, userID, src, tgt, tid)
+	}
+	// Both the tenant user and two merged contacts know alice→bob; the
+	// discord contact also knows carol, whom only it has seen.
+	entity("tu-alice", "user-1", "alice")
+	entity("tu-bob", "user-1", "bob")
+	relation("user-1", "tu-alice", "tu-bob")
+	entity("tg-alice", "tg:1", "alice")
+	entity("tg-bob", "tg:1", "bob")
+	relation("tg:1", "tg-alice", "tg-bob")
+	entity("dc-alice", "dc:1", "alice")
+	entity("dc-carol", "dc:1", "carol")
+	relation("dc:1", "dc-alice", "dc-carol")
+
+	if err := NewSQLiteAgentStore(db).MigrateUserDataOnMerge(ctx, []string{"tg:1", "dc:1"}, "user-1"); err != nil {
+		t.Fatalf("MigrateUserDataOnMerge: %v", err)
+	}
+
+	rows, err := db.Query(`SELECT s.external_id, t.external_id, r.user_id FROM kg_relations r
+		JOIN kg_entities s ON s.id = r.source_entity_id
+		JOIN kg_entities t ON t.id = r.target_entity_id
+		ORDER BY t.external_id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var src, tgt, userID string
+		if err := rows.Scan(&src, &tgt, &userID); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, src+"->"+tgt+"@"+userID)
+	}
+	want := []string{"alice->bob@user-1", "alice->carol@user-1"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("relations = %v, want %v", got, want)
+	}
+}
@@ -55,6 +55,19 @@ func (s *SQLiteContactStore) UpsertContact(ctx context.Context, channelType, cha
 	return err
 }
 
+func (s *SQLiteContactStore) SetDirectChat(ctx context.Context, channelType, channelInstance, senderID, chatID string) error {
+	tenantID := store.TenantIDFromContext(ctx)
+	if tenantID == uuid.Nil {
+		tenantID = store.MasterTenantID
+	}
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE channel_contacts SET dm_chat_id = ?, channel_instance = COALESCE(NULLIF(?,''), channel_instance)
+		WHERE tenant_id = ? AND channel_type = ? AND sender_id = ? AND thread_id IS NULL`,
+		chatID, channelInstance, tenantID, channelType, senderID,
+	)
+	return err
+}
+
 func contactWhereSQLite(ctx context.Context, opts store.ContactListOpts) (string, []any) {
 	var conditions []string
 	var args []any
@@ -98,14 +111,14 @@ func contactWhereSQLite(ctx context.Context, opts store.ContactListOpts) (string
 }
 
 const contactSelectCols = `id, channel_type, channel_instance, sender_id, user_id,
-		display_name, username, avatar_url, peer_kind, contact_type, thread_id, thread_type, merged_id,
+		display_name, username, avatar_url, peer_kind, contact_type, thread_id, thread_type, dm_chat_id, merged_id,
 		first_seen_at, last_seen_at`
 
 func scanContact(rows *sql.Rows) (store.ChannelContact, error) {
 	var c store.ChannelContact
 	err := rows.Scan(
 		&c.ID, &c.ChannelType, &c.ChannelInstance, &c.SenderID, &c.UserID,
-		&c.DisplayName, &c.Username, &c.AvatarURL, &c.PeerKind, &c.ContactType, &c.ThreadID, &c.ThreadType, &c.MergedID,
+		&c.DisplayName, &c.Username, &c.AvatarURL, &c.PeerKind, &c.ContactType, &c.ThreadID, &c.ThreadType, &c.DMChatID, &c.MergedID,
 		&c.FirstSeenAt, &c.LastSeenAt,
 	)
 	return c, err
@@ -197,7 +210,7 @@ func (s *SQLiteContactStore) GetContactByID(ctx context.Context, id uuid.UUID) (
 	if err := row.Scan(
 		&c.ID, &c.ChannelType, &c.ChannelInstance, &c.SenderID, &c.UserID,
 		&c.DisplayName, &c.Username, &c.AvatarURL, &c.PeerKind, &c.ContactType,
-		&c.ThreadID, &c.ThreadType, &c.MergedID,
+		&c.ThreadID, &c.ThreadType, &c.DMChatID, &c.MergedID,
 		&c.FirstSeenAt, &c.LastSeenAt,
 	); err != nil {
 		return nil, err
@@ -299,6 +312,29 @@ func (s *SQLiteContactStore) GetContactsByMergedID(ctx context.Context, mergedID
 	return contacts, rows.Err()
 }
 
+func (s *SQLiteContactStore) GetContactsByTenantUserID(ctx context.Context, userID string) ([]store.ChannelContact, error) {
+	tid := store.TenantIDFromContext(ctx)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+contactSelectCols+`
+		FROM channel_contacts
+		WHERE merged_id IN (SELECT id FROM tenant_users WHERE user_id = ? AND tenant_id = ?) AND tenant_id = ?
+		ORDER BY last_seen_at DESC`, userID, tid, tid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contacts []store.ChannelContact
+	for rows.Next() {
+		c, err := scanContact(rows)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, c)
+	}
+	return contacts, rows.Err()
+}
+
 func (s *SQLiteContactStore) ResolveTenantUserID(ctx context.Context, channelType, senderID string) (string, error) {
 	tid := store.TenantIDFromContext(ctx)
 	var tenantUserID string
@@ -16,7 +16,7 @@ var schemaSQL string
 
 // SchemaVersion is the current SQLite schema version.
 // Bump this when adding new migration steps below.
-const SchemaVersion = 49
+const SchemaVersion = 51
 
 // migrations maps version → SQL to apply when upgrading FROM that version.
 // schema.sql always represents the LATEST full schema (for fresh DBs).
@@ -852,6 +852,27 @@ CREATE INDEX IF NOT EXISTS idx_skill_user_grants_tenant ON skill_user_grants(ten
 	47: addSkillSelfEvolutionTables,
 	// Version 48 → 49: append-only usage event analytics.
 	48: addUsageEventAnalyticsTables,
+	// Version 49 → 50: outbound messages per run for edit and retraction.
+	49: `CREATE TABLE IF NOT EXISTS sent_messages (
+    id         TEXT NOT NULL PRIMARY KEY,
+    tenant_id  TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    run_id     TEXT NOT NULL,
+    agent_id   TEXT REFERENCES agents(id) ON DELETE SET NULL,
+    channel    TEXT NOT NULL,
+    chat_id    TEXT NOT NULL,
+    message_id TEXT NOT NULL,
+    preview    TEXT,
+    status     TEXT NOT NULL DEFAULT 'sent',
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    UNIQUE (tenant_id, channel, chat_id, message_id)
+);
+CREATE INDEX IF NOT EXISTS idx_sent_messages_run
+    ON sent_messages (tenant_id, run_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_sent_messages_chat_time
+    ON sent_messages (tenant_id, channel, chat_id, created_at DESC);`,
+	// Version 50 → 51: direct-message chat ID per contact for handoff.
+	50: `ALTER TABLE channel_contacts ADD COLUMN dm_chat_id VARCHAR(255);`,
 }
 
 const addUsageEventAnalyticsTables = `
@@ -1450,6 +1471,8 @@ func idempotentColumnMigration(version int) (string, string, bool) {
 		return "secure_cli_user_credentials", "host_scope", true
 	case 41:
 		return "secure_cli_binaries", "adapter_name", true
+	case 50:
+		return "channel_contacts", "dm_chat_id", true
 	default:
 		return "", "", false
 	}
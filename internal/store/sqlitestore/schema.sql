@@ -627,6 +627,30 @@ CREATE INDEX IF NOT EXISTS idx_run_timeline_trace
     ON run_timeline_items (tenant_id, trace_id)
     WHERE trace_id IS NOT NULL;
 
+-- ============================================================
+-- Table: sent_messages
+-- ============================================================
+
+CREATE TABLE IF NOT EXISTS sent_messages (
+    id         TEXT NOT NULL PRIMARY KEY,
+    tenant_id  TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    run_id     TEXT NOT NULL,
+    agent_id   TEXT REFERENCES agents(id) ON DELETE SET NULL,
+    channel    TEXT NOT NULL,
+    chat_id    TEXT NOT NULL,
+    message_id TEXT NOT NULL,
+    preview    TEXT,
+    status     TEXT NOT NULL DEFAULT 'sent',
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    UNIQUE (tenant_id, channel, chat_id, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sent_messages_run
+    ON sent_messages (tenant_id, run_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_sent_messages_chat_time
+    ON sent_messages (tenant_id, channel, chat_id, created_at DESC);
+
 -- ============================================================
 -- Table: spans
 -- ============================================================
@@ -1265,6 +1289,7 @@ CREATE TABLE IF NOT EXISTS channel_contacts (
     contact_type     VARCHAR(20) NOT NULL DEFAULT 'user',
     thread_id        VARCHAR(100),
     thread_type      VARCHAR(20),
+    dm_chat_id       VARCHAR(255),
     metadata         TEXT DEFAULT '{}',
     merged_id        TEXT,
     tenant_id        TEXT NOT NULL REFERENCES tenants(id),
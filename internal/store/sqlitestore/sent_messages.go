@@ -0,0 +1,168 @@
+//go:build sqlite || sqliteonly
+
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// SQLiteSentMessageStore implements store.SentMessageStore backed by SQLite.
+type SQLiteSentMessageStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteSentMessageStore(db *sql.DB) *SQLiteSentMessageStore {
+	return &SQLiteSentMessageStore{db: db}
+}
+
+// RecordSentMessage inserts a sent message. Recording the same platform
+// message twice (e.g. a placeholder edited into the final reply) is a no-op.
+func (s *SQLiteSentMessageStore) RecordSentMessage(ctx context.Context, msg *store.SentMessage) error {
+	if msg.ID == uuid.Nil {
+		msg.ID = store.GenNewID()
+	}
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = time.Now()
+	}
+	msg.UpdatedAt = msg.CreatedAt
+	if msg.Status == "" {
+		msg.Status = store.SentMessageStatusSent
+	}
+	tenantID := tenantIDForInsert(ctx)
+	msg.TenantID = tenantID
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO sent_messages
+		 (id, tenant_id, run_id, agent_id, channel, chat_id, message_id, preview, status, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (tenant_id, channel, chat_id, message_id) DO NOTHING`,
+		msg.ID, tenantID, msg.RunID, nilUUID(msg.AgentID), msg.Channel, msg.ChatID, msg.MessageID,
+		nilStr(msg.Preview), msg.Status, msg.CreatedAt, msg.UpdatedAt,
+	)
+	return err
+}
+
+func (s *SQLiteSentMessageStore) GetSentMessage(ctx context.Context, id uuid.UUID) (*store.SentMessage, error) {
+	q := `SELECT id, tenant_id, run_id, agent_id, channel, chat_id, message_id, preview, status, created_at, updated_at
+		 FROM sent_messages WHERE id = ?`
+	args := []any{id}
+	if !store.IsCrossTenant(ctx) {
+		tid, err := requireTenantID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		q += " AND tenant_id = ?"
+		args = append(args, tid)
+	}
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	msgs, err := scanSentMessageRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &msgs[0], nil
+}
+
+func (s *SQLiteSentMessageStore) ListSentMessages(ctx context.Context, opts store.SentMessageListOpts) ([]store.SentMessage, error) {
+	where, args := buildSentMessageWhere(ctx, opts)
+	limit := opts.Limit
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	q := `SELECT id, tenant_id, run_id, agent_id, channel, chat_id, message_id, preview, status, created_at, updated_at
+		 FROM sent_messages` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC LIMIT %d", limit)
+
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanSentMessageRows(rows)
+}
+
+func (s *SQLiteSentMessageStore) UpdateSentMessageStatus(ctx context.Context, id uuid.UUID, status string) error {
+	q := `UPDATE sent_messages SET status = ?, updated_at = ? WHERE id = ?`
+	args := []any{status, time.Now(), id}
+	if !store.IsCrossTenant(ctx) {
+		tid, err := requireTenantID(ctx)
+		if err != nil {
+			return err
+		}
+		q += " AND tenant_id = ?"
+		args = append(args, tid)
+	}
+	res, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func buildSentMessageWhere(ctx context.Context, opts store.SentMessageListOpts) (string, []any) {
+	var conditions []string
+	var args []any
+	if !store.IsCrossTenant(ctx) {
+		tenantID := store.TenantIDFromContext(ctx)
+		if tenantID == uuid.Nil {
+			return " WHERE 1=0", nil
+		}
+		conditions = append(conditions, "tenant_id = ?")
+		args = append(args, tenantID)
+	}
+	scoped := false
+	if opts.RunID != "" {
+		conditions = append(conditions, "run_id = ?")
+		args = append(args, opts.RunID)
+		scoped = true
+	}
+	if opts.Channel != "" && opts.ChatID != "" {
+		conditions = append(conditions, "channel = ? AND chat_id = ?")
+		args = append(args, opts.Channel, opts.ChatID)
+		scoped = true
+	}
+	if opts.AgentID != uuid.Nil {
+		conditions = append(conditions, "agent_id = ?")
+		args = append(args, opts.AgentID)
+	}
+	if !scoped {
+		return " WHERE 1=0", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func scanSentMessageRows(rows *sql.Rows) ([]store.SentMessage, error) {
+	var msgs []store.SentMessage
+	for rows.Next() {
+		var msg store.SentMessage
+		var agentID *uuid.UUID
+		var preview sql.NullString
+		var createdAt, updatedAt sqliteTime
+		if err := rows.Scan(&msg.ID, &msg.TenantID, &msg.RunID, &agentID, &msg.Channel, &msg.ChatID,
+			&msg.MessageID, &preview, &msg.Status, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		msg.AgentID = agentID
+		msg.Preview = preview.String
+		msg.CreatedAt = createdAt.Time
+		msg.UpdatedAt = updatedAt.Time
+		msgs = append(msgs, msg)
+	}
+	return msgs, rows.Err()
+}
@@ -0,0 +1,139 @@
+//go:build sqlite || sqliteonly
+
+package sqlitestore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+func TestSQLiteSentMessageStoreRecordListAndStatus(t *testing.T) {
+	db := openTestDB(t)
+	if err := EnsureSchema(db); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+
+	sent := NewSQLiteSentMessageStore(db)
+	ctx := store.WithTenantID(context.Background(), store.MasterTenantID)
+
+	msgs := []store.SentMessage{
+		{RunID: "run-1", Channel: "telegram", ChatID: "100", MessageID: "11", Preview: "first"},
+		{RunID: "run-1", Channel: "telegram", ChatID: "100", MessageID: "12", Preview: "second"},
+		{RunID: "run-2", Channel: "telegram", ChatID: "100", MessageID: "13"},
+	}
+	for i := range msgs {
+		if err := sent.RecordSentMessage(ctx, &msgs[i]); err != nil {
+			t.Fatalf("RecordSentMessage(%d): %v", i, err)
+		}
+	}
+	// Same platform message recorded again (placeholder edited twice) is ignored.
+	dup := store.SentMessage{RunID: "run-1", Channel: "telegram", ChatID: "100", MessageID: "11"}
+	if err := sent.RecordSentMessage(ctx, &dup); err != nil {
+		t.Fatalf("RecordSentMessage(dup): %v", err)
+	}
+
+	byRun, err := sent.ListSentMessages(ctx, store.SentMessageListOpts{RunID: "run-1"})
+	if err != nil {
+		t.Fatalf("ListSentMessages(run): %v", err)
+	}
+	if len(byRun) != 2 {
+		t.Fatalf("run-1 len = %d, want 2", len(byRun))
+	}
+
+	byChat, err := sent.ListSentMessages(ctx, store.SentMessageListOpts{Channel: "telegram", ChatID: "100", Limit: 1})
+	if err != nil {
+		t.Fatalf("ListSentMessages(chat): %v", err)
+	}
+	if len(byChat) != 1 || byChat[0].MessageID != "13" {
+		t.Fatalf("latest in chat = %+v, want message 13", byChat)
+	}
+
+	if err := sent.UpdateSentMessageStatus(ctx, msgs[0].ID, store.SentMessageStatusRetracted); err != nil {
+		t.Fatalf("UpdateSentMessageStatus: %v", err)
+	}
+	got, err := sent.GetSentMessage(ctx, msgs[0].ID)
+	if err != nil {
+		t.Fatalf("GetSentMessage: %v", err)
+	}
+	if got.Status != store.SentMessageStatusRetracted || got.Preview != "first" {
+		t.Fatalf("got status=%q preview=%q, want retracted/first", got.Status, got.Preview)
+	}
+
+	unscoped, err := sent.ListSentMessages(ctx, store.SentMessageListOpts{})
+	if err != nil {
+		t.Fatalf("ListSentMessages(unscoped): %v", err)
+	}
+	if len(unscoped) != 0 {
+		t.Fatalf("unscoped len = %d, want 0", len(unscoped))
+	}
+}
+
+func TestSQLiteSentMessageStoreTenantScope(t *testing.T) {
+	db := openTestDB(t)
+	if err := EnsureSchema(db); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+
+	sent := NewSQLiteSentMessageStore(db)
+	tenantA := uuid.Must(uuid.NewV7())
+	tenantB := uuid.Must(uuid.NewV7())
+	seedSQLiteRunTimelineTenant(t, db, tenantA)
+	seedSQLiteRunTimelineTenant(t, db, tenantB)
+	ctxA := store.WithTenantID(context.Background(), tenantA)
+	ctxB := store.WithTenantID(context.Background(), tenantB)
+
+	msg := store.SentMessage{RunID: "run-shared", Channel: "slack", ChatID: "C1", MessageID: "1700000000.000100"}
+	if err := sent.RecordSentMessage(ctxA, &msg); err != nil {
+		t.Fatalf("RecordSentMessage: %v", err)
+	}
+
+	if _, err := sent.GetSentMessage(ctxB, msg.ID); err == nil {
+		t.Fatal("tenant B read tenant A's message")
+	}
+	if err := sent.UpdateSentMessageStatus(ctxB, msg.ID, store.SentMessageStatusRetracted); err == nil {
+		t.Fatal("tenant B updated tenant A's message")
+	}
+	gotB, err := sent.ListSentMessages(ctxB, store.SentMessageListOpts{RunID: "run-shared"})
+	if err != nil {
+		t.Fatalf("List tenant B: %v", err)
+	}
+	if len(gotB) != 0 {
+		t.Fatalf("tenant B len = %d, want 0", len(gotB))
+	}
+}
+
+func TestSQLiteSentMessageStoreAgentFilter(t *testing.T) {
+	db := openTestDB(t)
+	if err := EnsureSchema(db); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+	tenantID, agentA := seedHookTenantAgent(t, db)
+	agentB := uuid.Must(uuid.NewV7())
+	if _, err := db.Exec(
+		`INSERT INTO agents (id, tenant_id, agent_key, agent_type, status, provider, model, owner_id)
+		 VALUES (?,?,?,'predefined','active','test','test-model','owner')`,
+		agentB.String(), tenantID.String(), "hb-"+agentB.String()[:8]); err != nil {
+		t.Fatalf("seed agent: %v", err)
+	}
+
+	sent := NewSQLiteSentMessageStore(db)
+	ctx := sqliteTenantCtx(tenantID)
+	for i, agentID := range []uuid.UUID{agentA, agentB} {
+		msg := store.SentMessage{RunID: "run", AgentID: &agentID, Channel: "telegram", ChatID: "group", MessageID: string(rune('1' + i))}
+		if err := sent.RecordSentMessage(ctx, &msg); err != nil {
+			t.Fatalf("RecordSentMessage: %v", err)
+		}
+	}
+
+	got, err := sent.ListSentMessages(ctx, store.SentMessageListOpts{Channel: "telegram", ChatID: "group", AgentID: agentA})
+	if err != nil {
+		t.Fatalf("ListSentMessages: %v", err)
+	}
+	if len(got) != 1 || got[0].AgentID == nil || *got[0].AgentID != agentA {
+		t.Fatalf("agent A messages = %+v, want only its own", got)
+	}
+}
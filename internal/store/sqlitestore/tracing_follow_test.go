@@ -97,3 +97,49 @@ func TestBuildTraceWhereAdvancedFilters(t *testing.T) {
 		t.Fatalf("tool arg = %#v, want escaped contains pattern", args[28])
 	}
 }
+
+func TestListRecentLLMSpansSkipsCacheHitsAndRunning(t *testing.T) {
+	db := openTestDB(t)
+	if err := EnsureSchema(db); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+	ts := NewSQLiteTracingStore(db)
+	ctx := t.Context()
+	traceID := uuid.New()
+	now := time.Now().UTC()
+
+	add := func(age time.Duration, status, spanType string, metadata []byte) {
+		t.Helper()
+		if err := ts.CreateSpan(ctx, &store.SpanData{
+			TraceID:      traceID,
+			SpanType:     spanType,
+			StartTime:    now.Add(-age),
+			CreatedAt:    now,
+			DurationMS:   1200,
+			OutputTokens: 300,
+			Status:       status,
+			Provider:     "openai",
+			Model:        "gpt-test",
+			Metadata:     metadata,
+		}); err != nil {
+			t.Fatalf("CreateSpan: %v", err)
+		}
+	}
+	add(time.Minute, store.SpanStatusCompleted, "llm_call", nil)
+	add(2*time.Minute, store.SpanStatusError, "llm_call", nil)
+	add(3*time.Minute, store.SpanStatusRunning, "llm_call", nil)
+	add(4*time.Minute, store.SpanStatusCompleted, "tool_call", nil)
+	add(5*time.Minute, store.SpanStatusCompleted, "llm_call", []byte(`{"response_cache":{"hit":true}}`))
+	add(48*time.Hour, store.SpanStatusCompleted, "llm_call", nil)
+
+	spans, err := ts.ListRecentLLMSpans(ctx, now.Add(-time.Hour), 10)
+	if err != nil {
+		t.Fatalf("ListRecentLLMSpans: %v", err)
+	}
+	if len(spans) != 2 || spans[0].Status != store.SpanStatusCompleted || spans[1].Status != store.SpanStatusError {
+		t.Fatalf("spans = %+v, want completed then error", spans)
+	}
+	if got := spans[0]; got.TenantID != store.MasterTenantID || got.Provider != "openai" || got.Model != "gpt-test" || got.DurationMS != 1200 || got.OutputTokens != 300 {
+		t.Fatalf("span = %+v", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -206,3 +207,30 @@ func (s *SQLiteTracingStore) BatchUpdateTraceAggregates(ctx context.Context, tra
 	return err
 }
 
+// ListRecentLLMSpans returns finished LLM call spans started after since, newest first.
+func (s *SQLiteTracingStore) ListRecentLLMSpans(ctx context.Context, since time.Time, limit int) ([]store.LLMSpanSample, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT tenant_id, start_time, COALESCE(duration_ms, 0), COALESCE(output_tokens, 0), status,
+		 COALESCE(provider, ''), COALESCE(model, '')
+		 FROM spans
+		 WHERE span_type = 'llm_call' AND start_time > ? AND status IN ('completed', 'error')
+		   AND provider IS NOT NULL AND json_extract(metadata, '$.response_cache') IS NULL
+		 ORDER BY start_time DESC LIMIT ?`, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	spans := make([]store.LLMSpanSample, 0, limit)
+	for rows.Next() {
+		var item store.LLMSpanSample
+		var startTime sqliteTime
+		if err := rows.Scan(&item.TenantID, &startTime, &item.DurationMS, &item.OutputTokens, &item.Status,
+			&item.Provider, &item.Model); err != nil {
+			return nil, err
+		}
+		item.StartedAt = startTime.Time
+		spans = append(spans, item)
+	}
+	return spans, rows.Err()
+}
@@ -14,6 +14,7 @@ type Stores struct {
 	Providers             ProviderStore
 	Tracing               TracingStore
 	RunTimeline           RunTimelineStore
+	SentMessages          SentMessageStore
 	MCP                   MCPServerStore
 	ChannelInstances      ChannelInstanceStore
 	ConfigSecrets         ConfigSecretsStore
@@ -150,6 +150,18 @@ type CodexPoolSpan struct {
 	Metadata   json.RawMessage
 }
 
+// LLMSpanSample holds the timing of one finished LLM call span. Used to seed
+// lowest-latency routing after a restart.
+type LLMSpanSample struct {
+	TenantID     uuid.UUID
+	StartedAt    time.Time
+	DurationMS   int
+	OutputTokens int
+	Status       string
+	Provider     string
+	Model        string
+}
+
 // TracingStore manages LLM traces and spans.
 type TracingStore interface {
 	CreateTrace(ctx context.Context, trace *TraceData) error
@@ -183,6 +195,10 @@ type TracingStore interface {
 	// ListCodexPoolSpansByProviders returns recent LLM call spans across all agents
 	// that used any of the given pool providers. Used for provider-scoped activity monitoring.
 	ListCodexPoolSpansByProviders(ctx context.Context, tenantID uuid.UUID, poolProviders []string, limit int) ([]CodexPoolProviderSpan, error)
+
+	// ListRecentLLMSpans returns finished LLM call spans started after since,
+	// across all tenants, newest first. Response-cache hits are excluded.
+	ListRecentLLMSpans(ctx context.Context, since time.Time, limit int) ([]LLMSpanSample, error)
 }
 
 // CodexPoolProviderSpan extends CodexPoolSpan with the agent ID for provider-scoped aggregation.
@@ -0,0 +1,528 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/nextlevelbuilder/goclaw/internal/sandbox"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// ApplyPatchTool applies unified diffs or "*** Begin Patch" envelopes across
+// multiple files. Every hunk is located before anything is written, so a patch
+// either applies completely or leaves the workspace untouched.
+type ApplyPatchTool struct {
+	workspace       string
+	restrict        bool
+	allowedPrefixes []string // extra allowed path prefixes (cross-drive on Windows)
+	deniedPrefixes  []string // path prefixes to deny access to (e.g. .goclaw)
+	sandboxMgr      sandbox.Manager
+	contextFileIntc *ContextFileInterceptor
+	memIntc         *MemoryInterceptor
+	vaultIntc       *VaultInterceptor
+	permStore       store.ConfigPermissionStore // nil = no group write restriction
+}
+
+func (t *ApplyPatchTool) SetVaultInterceptor(v *VaultInterceptor) { t.vaultIntc = v }
+
+// AllowPaths adds extra path prefixes that apply_patch is allowed to access
+// even when restrict_to_workspace is true (e.g. cross-drive on Windows).
+func (t *ApplyPatchTool) AllowPaths(prefixes ...string) {
+	t.allowedPrefixes = append(t.allowedPrefixes, prefixes...)
+}
+
+// DenyPaths adds path prefixes that apply_patch must reject.
+func (t *ApplyPatchTool) DenyPaths(prefixes ...string) {
+	t.deniedPrefixes = append(t.deniedPrefixes, prefixes...)
+}
+
+func (t *ApplyPatchTool) SetContextFileInterceptor(intc *ContextFileInterceptor) {
+	t.contextFileIntc = intc
+}
+
+func (t *ApplyPatchTool) SetMemoryInterceptor(intc *MemoryInterceptor) {
+	t.memIntc = intc
+}
+
+// SetConfigPermStore enables group write permission checks.
+func (t *ApplyPatchTool) SetConfigPermStore(s store.ConfigPermissionStore) {
+	t.permStore = s
+}
+
+func NewApplyPatchTool(workspace string, restrict bool) *ApplyPatchTool {
+	return &ApplyPatchTool{workspace: workspace, restrict: restrict}
+}
+
+func NewSandboxedApplyPatchTool(workspace string, restrict bool, mgr sandbox.Manager) *ApplyPatchTool {
+	return &ApplyPatchTool{workspace: workspace, restrict: restrict, sandboxMgr: mgr}
+}
+
+func (t *ApplyPatchTool) SetSandboxKey(key string) {}
+
+func (t *ApplyPatchTool) Name() string { return "apply_patch" }
+func (t *ApplyPatchTool) Description() string {
+	return "Apply a multi-file patch: a unified diff (git diff / diff -u) or a *** Begin Patch block with *** Add File, *** Update File, *** Delete File and *** Move to sections. " +
+		"Hunks are matched tolerantly (line offsets, whitespace differences); if any hunk fails nothing is written and the conflicts are reported. Use dry_run to check a patch first."
+}
+
+func (t *ApplyPatchTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"patch": map[string]any{
+				"type":        "string",
+				"description": "Patch text. Paths are relative to the workspace.",
+			},
+			"dry_run": map[string]any{
+				"type":        "boolean",
+				"description": "Check that the patch applies and report what would change, without writing (default: false)",
+			},
+		},
+		"required": []string{"patch"},
+	}
+}
+
+// patchVirtual marks files served by an interceptor instead of the filesystem.
+type patchVirtual int
+
+const (
+	patchVirtualNone patchVirtual = iota
+	patchVirtualContext
+	patchVirtualMemory
+)
+
+// patchChange is one file's planned change.
+type patchChange struct {
+	fp       *filePatch
+	src, dst string // resolved paths; dst == src unless moved
+	virtual  patchVirtual
+	original string
+	content  string // new content; unused for deletes
+	result   *patchedFile
+}
+
+func (t *ApplyPatchTool) Execute(ctx context.Context, args map[string]any) *Result {
+	patchText, _ := args["patch"].(string)
+	dryRun, _ := args["dry_run"].(bool)
+
+	if strings.TrimSpace(patchText) == "" {
+		return ErrorResult("patch is required")
+	}
+
+	// Group write permission check
+	if !dryRun && t.permStore != nil {
+		if err := store.CheckFileWriterPermission(ctx, t.permStore); err != nil {
+			return ErrorResult(err.Error())
+		}
+	}
+
+	files, err := parsePatch(patchText)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("invalid patch: %v", err))
+	}
+
+	fsys, err := t.patchFS(ctx)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	changes, err := t.plan(ctx, fsys, files)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	var conflicts []string
+	for _, c := range changes {
+		if c.result == nil {
+			continue
+		}
+		for _, hc := range c.result.conflicts {
+			conflicts = append(conflicts, hc.format(c.fp.path))
+		}
+	}
+	if len(conflicts) > 0 {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Patch does not apply: %d hunk(s) failed. No files were changed.\n\n", len(conflicts))
+		b.WriteString(strings.Join(conflicts, "\n"))
+		b.WriteString("\nRe-read the affected files and regenerate the failing hunks against their current content.")
+		return ErrorResult(b.String())
+	}
+
+	if dryRun {
+		return SilentResult(fmt.Sprintf("Dry run: patch applies cleanly to %d file(s); nothing was written.\n%s", len(changes), summarizePatch(changes)))
+	}
+
+	if err := t.commit(ctx, fsys, changes); err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	if t.vaultIntc != nil {
+		for _, c := range changes {
+			if c.virtual == patchVirtualNone && c.fp.op != patchDelete && fsys.host() {
+				go t.vaultIntc.AfterWrite(context.WithoutCancel(ctx), c.dst, c.content)
+			}
+		}
+	}
+
+	return SilentResult(fmt.Sprintf("Patch applied to %d file(s):\n%s", len(changes), summarizePatch(changes)))
+}
+
+// plan resolves every path, reads the originals and computes new contents.
+// Nothing is written here.
+func (t *ApplyPatchTool) plan(ctx context.Context, fsys patchFS, files []*filePatch) ([]*patchChange, error) {
+	changes := make([]*patchChange, 0, len(files))
+	seen := make(map[string]bool, len(files))
+	for _, fp := range files {
+		c := &patchChange{fp: fp}
+
+		virtual, content, err := t.readVirtual(ctx, fp.path)
+		if err != nil {
+			return nil, err
+		}
+		if virtual != patchVirtualNone {
+			if fp.op != patchUpdate || fp.moveTo != "" {
+				return nil, fmt.Errorf("%s: context and memory files can only be updated in place", fp.path)
+			}
+			if content == "" {
+				return nil, fmt.Errorf("%s: file not found", fp.path)
+			}
+			c.virtual, c.original = virtual, content
+			c.result = applyHunks(content, fp.hunks)
+			c.content = c.result.content
+			changes = append(changes, c)
+			continue
+		}
+
+		if c.src, err = fsys.resolve(fp.path); err != nil {
+			return nil, fmt.Errorf("%s: %v", fp.path, err)
+		}
+		c.dst = c.src
+		if seen[c.src] {
+			return nil, fmt.Errorf("%s appears more than once in the patch; combine its changes into one section", fp.path)
+		}
+		seen[c.src] = true
+		if fp.moveTo != "" {
+			if v, _, err := t.readVirtual(ctx, fp.moveTo); err != nil {
+				return nil, err
+			} else if v != patchVirtualNone {
+				return nil, fmt.Errorf("%s: cannot move a file onto a context or memory file", fp.moveTo)
+			}
+			if c.dst, err = fsys.resolve(fp.moveTo); err != nil {
+				return nil, fmt.Errorf("%s: %v", fp.moveTo, err)
+			}
+			if seen[c.dst] {
+				return nil, fmt.Errorf("%s appears more than once in the patch; combine its changes into one section", fp.moveTo)
+			}
+			seen[c.dst] = true
+			if _, exists, err := fsys.read(ctx, c.dst); err != nil {
+				return nil, fmt.Errorf("%s: %v", fp.moveTo, err)
+			} else if exists {
+				return nil, fmt.Errorf("%s: move target already exists", fp.moveTo)
+			}
+		}
+
+		content, exists, err := fsys.read(ctx, c.src)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fp.path, err)
+		}
+		switch fp.op {
+		case patchAdd:
+			if exists {
+				return nil, fmt.Errorf("%s: file already exists; use an update section to change it", fp.path)
+			}
+			c.content = fp.addContent
+		case patchDelete:
+			if !exists {
+				return nil, fmt.Errorf("%s: cannot delete, file not found", fp.path)
+			}
+			c.original = content
+		default:
+			if !exists {
+				return nil, fmt.Errorf("%s: file not found", fp.path)
+			}
+			c.original = content
+			c.result = applyHunks(content, fp.hunks)
+			c.content = c.result.content
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// readVirtual checks whether path is a context or memory file.
+func (t *ApplyPatchTool) readVirtual(ctx context.Context, path string) (patchVirtual, string, error) {
+	if t.contextFileIntc != nil {
+		if content, handled, err := t.contextFileIntc.ReadFile(ctx, path); handled {
+			if err != nil {
+				return 0, "", fmt.Errorf("failed to read context file %s: %v", path, err)
+			}
+			return patchVirtualContext, content, nil
+		}
+	}
+	if t.memIntc != nil {
+		if content, handled, err := t.memIntc.ReadFile(ctx, path); handled {
+			if err != nil {
+				return 0, "", fmt.Errorf("failed to read memory file %s: %v", path, err)
+			}
+			return patchVirtualMemory, content, nil
+		}
+	}
+	return patchVirtualNone, "", nil
+}
+
+func (t *ApplyPatchTool) writeVirtual(ctx context.Context, c *patchChange, content string) error {
+	if c.virtual == patchVirtualContext {
+		_, err := t.contextFileIntc.WriteFile(ctx, c.fp.path, content)
+		return err
+	}
+	_, err := t.memIntc.WriteFile(ctx, c.fp.path, content, false)
+	return err
+}
+
+// commit writes the planned changes. Filesystem changes go first, then
+// context/memory files; on any failure everything already written is
+// restored from the originals.
+func (t *ApplyPatchTool) commit(ctx context.Context, fsys patchFS, changes []*patchChange) error {
+	var undo []func()
+	rollback := func(cause error) error {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+		return fmt.Errorf("%v (changes already made by this patch were rolled back)", cause)
+	}
+	// Rollback must not be cut short by a cancelled tool context.
+	bg := context.WithoutCancel(ctx)
+
+	for _, c := range changes {
+		if c.virtual != patchVirtualNone {
+			continue
+		}
+		switch c.fp.op {
+		case patchAdd:
+			if err := fsys.write(ctx, c.dst, c.content); err != nil {
+				return rollback(fmt.Errorf("%s: failed to write file: %v", c.fp.path, err))
+			}
+			undo = append(undo, func() { _ = fsys.remove(bg, c.dst) })
+		case patchDelete:
+			if err := fsys.remove(ctx, c.src); err != nil {
+				return rollback(fmt.Errorf("%s: failed to delete file: %v", c.fp.path, err))
+			}
+			undo = append(undo, func() { _ = fsys.write(bg, c.src, c.original) })
+		default:
+			if err := fsys.write(ctx, c.dst, c.content); err != nil {
+				return rollback(fmt.Errorf("%s: failed to write file: %v", c.fp.path, err))
+			}
+			if c.dst != c.src {
+				undo = append(undo, func() { _ = fsys.remove(bg, c.dst) })
+				if err := fsys.remove(ctx, c.src); err != nil {
+					return rollback(fmt.Errorf("%s: failed to remove after move: %v", c.fp.path, err))
+				}
+			}
+			undo = append(undo, func() { _ = fsys.write(bg, c.src, c.original) })
+		}
+	}
+
+	for _, c := range changes {
+		if c.virtual == patchVirtualNone {
+			continue
+		}
+		if err := t.writeVirtual(ctx, c, c.content); err != nil {
+			return rollback(fmt.Errorf("%s: failed to write file: %v", c.fp.path, err))
+		}
+		undo = append(undo, func() { _ = t.writeVirtual(bg, c, c.original) })
+	}
+	return nil
+}
+
+// summarizePatch lists each file with its line counts and hunk notes.
+func summarizePatch(changes []*patchChange) string {
+	var b strings.Builder
+	for _, c := range changes {
+		switch {
+		case c.fp.op == patchAdd:
+			fmt.Fprintf(&b, "  A %s (+%d)\n", c.fp.path, len(splitPatchLines(c.content)))
+		case c.fp.op == patchDelete:
+			fmt.Fprintf(&b, "  D %s (-%d)\n", c.fp.path, len(splitPatchLines(c.original)))
+		case c.fp.moveTo != "":
+			fmt.Fprintf(&b, "  R %s -> %s (+%d -%d)\n", c.fp.path, c.fp.moveTo, c.result.added, c.result.removed)
+		default:
+			fmt.Fprintf(&b, "  M %s (+%d -%d)\n", c.fp.path, c.result.added, c.result.removed)
+		}
+		if c.result != nil {
+			for _, n := range c.result.notes {
+				fmt.Fprintf(&b, "      %s\n", n)
+			}
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// patchFS is where patched files live: the host workspace or a sandbox
+// container. Paths passed to read/write/remove come from resolve.
+type patchFS interface {
+	resolve(path string) (string, error)
+	read(ctx context.Context, resolved string) (content string, exists bool, err error)
+	write(ctx context.Context, resolved, content string) error
+	remove(ctx context.Context, resolved string) error
+	host() bool
+}
+
+func (t *ApplyPatchTool) patchFS(ctx context.Context) (patchFS, error) {
+	// Sandbox routing (sandboxKey from ctx — thread-safe)
+	if sandboxKey := ToolSandboxKeyFromCtx(ctx); t.sandboxMgr != nil && sandboxKey != "" {
+		mountWorkspace, err := effectiveSandboxWorkspace(ctx, t.workspace)
+		if err != nil {
+			return nil, err
+		}
+		containerCwd, err := sandboxCwdForHostPath(mountWorkspace, mountWorkspace, sandbox.DefaultContainerWorkdir)
+		if err != nil {
+			return nil, fmt.Errorf("sandbox path mapping: %v", err)
+		}
+		sb, err := t.sandboxMgr.Get(ctx, sandboxKey, mountWorkspace, SandboxConfigFromCtx(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("sandbox error: %v", err)
+		}
+		return &sandboxPatchFS{
+			bridge: sandbox.NewFsBridge(sb.ID(), containerCwd),
+			cwd:    path.Clean(containerCwd),
+			denied: t.deniedPrefixes,
+		}, nil
+	}
+
+	// Host execution — use per-user workspace from context if available
+	workspace := ToolWorkspaceFromCtx(ctx)
+	if workspace == "" {
+		workspace = t.workspace
+	}
+	return &hostPatchFS{
+		workspace:     workspace,
+		denyWorkspace: t.workspace,
+		restrict:      effectiveRestrict(ctx, t.restrict),
+		allowed:       allowedWriteWithTeamWorkspace(ctx, t.allowedPrefixes),
+		denied:        t.deniedPrefixes,
+	}, nil
+}
+
+type hostPatchFS struct {
+	workspace     string
+	denyWorkspace string
+	restrict      bool
+	allowed       []string
+	denied        []string
+}
+
+func (h *hostPatchFS) host() bool { return true }
+
+func (h *hostPatchFS) resolve(p string) (string, error) {
+	resolved, err := resolvePathWithAllowed(p, h.workspace, h.restrict, h.allowed)
+	if err != nil {
+		return "", err
+	}
+	if err := checkDeniedPath(resolved, h.denyWorkspace, h.denied); err != nil {
+		return "", err
+	}
+	return resolved, nil
+}
+
+func (h *hostPatchFS) read(_ context.Context, resolved string) (string, bool, error) {
+	data, err := os.ReadFile(resolved)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read file: %v", err)
+	}
+	return string(data), true, nil
+}
+
+// write replaces the file through a temp file and rename so readers never
+// see a half-written file; the original permissions are kept.
+func (h *hostPatchFS) write(_ context.Context, resolved, content string) error {
+	dir := filepath.Dir(resolved)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	mode := fs.FileMode(0644)
+	if fi, err := os.Stat(resolved); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(resolved)+".patch-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), resolved)
+}
+
+func (h *hostPatchFS) remove(_ context.Context, resolved string) error {
+	if err := os.Remove(resolved); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+type sandboxPatchFS struct {
+	bridge *sandbox.FsBridge
+	cwd    string
+	denied []string
+}
+
+func (s *sandboxPatchFS) host() bool { return false }
+
+// resolve maps the path into the container workdir. ResolveSandboxPath
+// clamps escapes to the workdir itself, which is never a valid file target.
+func (s *sandboxPatchFS) resolve(p string) (string, error) {
+	resolved := ResolveSandboxPath(p, s.cwd)
+	if resolved == s.cwd {
+		return "", fmt.Errorf("access denied: path outside the sandbox workspace")
+	}
+	rel := strings.TrimPrefix(resolved, s.cwd+"/")
+	for _, prefix := range s.denied {
+		prefix = strings.Trim(filepath.ToSlash(prefix), "/")
+		if rel == prefix || strings.HasPrefix(rel, prefix+"/") {
+			return "", fmt.Errorf("access denied: path %s is restricted", prefix)
+		}
+	}
+	return resolved, nil
+}
+
+func (s *sandboxPatchFS) read(ctx context.Context, resolved string) (string, bool, error) {
+	if _, err := s.bridge.Stat(ctx, resolved); err != nil {
+		return "", false, nil
+	}
+	content, err := s.bridge.ReadFile(ctx, resolved)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read file: %v%s", err, MaybeFsBridgeHint(err))
+	}
+	return content, true, nil
+}
+
+func (s *sandboxPatchFS) write(ctx context.Context, resolved, content string) error {
+	if err := s.bridge.WriteFile(ctx, resolved, content, false); err != nil {
+		return fmt.Errorf("%v%s", err, MaybeFsBridgeHint(err))
+	}
+	return nil
+}
+
+func (s *sandboxPatchFS) remove(ctx context.Context, resolved string) error {
+	if err := s.bridge.Remove(ctx, resolved); err != nil {
+		return fmt.Errorf("%v%s", err, MaybeFsBridgeHint(err))
+	}
+	return nil
+}
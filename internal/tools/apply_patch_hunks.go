@@ -0,0 +1,313 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Fuzz levels tried in order when locating a hunk. Each level relaxes how
+// lines are compared; the first level with any match wins.
+const (
+	fuzzExact = iota
+	fuzzTrimRight
+	fuzzTrimSpace
+	fuzzNormalized
+	fuzzLevels
+)
+
+var fuzzNotes = [fuzzLevels]string{
+	"",
+	"ignoring trailing whitespace",
+	"ignoring indentation",
+	"ignoring whitespace and punctuation variants",
+}
+
+// hunkConflict describes a hunk that could not be placed.
+type hunkConflict struct {
+	index    int // 1-based within the file
+	header   string
+	reason   string
+	expected []string
+	// Closest window in the file, for the report. bestLine is 1-based;
+	// 0 means the file had nothing comparable.
+	bestLine    int
+	bestMatched int
+	bestLines   []string
+}
+
+// hunkEdit is a placed hunk: replace lines[start:start+oldLen] with newLines.
+type hunkEdit struct {
+	start     int
+	oldLen    int
+	newLines  []string
+	noNewline bool
+}
+
+// patchedFile is the outcome of applying one file's hunks in memory.
+type patchedFile struct {
+	content   string
+	added     int
+	removed   int
+	notes     []string
+	conflicts []hunkConflict
+}
+
+// applyHunks places every hunk against the original content and returns the
+// new content. Hunks are located in order; all of them are tried even after
+// a conflict so the report covers the whole file.
+func applyHunks(content string, hunks []patchHunk) *patchedFile {
+	crlf := strings.Contains(content, "\r\n")
+	if crlf {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+	trailingNL := content == "" || strings.HasSuffix(content, "\n")
+	lines := splitPatchLines(content)
+
+	out := &patchedFile{}
+	var edits []hunkEdit
+	cursor := 0
+	for i := range hunks {
+		h := &hunks[i]
+		old := h.oldLines()
+		from := cursor
+		if h.anchor != "" {
+			at, _ := seekLines(lines, []string{h.anchor}, from, -1, false)
+			if at < 0 {
+				out.conflicts = append(out.conflicts, hunkConflict{
+					index: i + 1, header: h.header, reason: fmt.Sprintf("anchor line %q not found", h.anchor),
+					expected: []string{h.anchor},
+				})
+				continue
+			}
+			from = at + 1
+		}
+
+		hint := -1
+		if h.oldStart >= 0 {
+			hint = h.oldStart
+			if len(old) > 0 && hint > 0 {
+				hint--
+			}
+		}
+
+		var at, fuzz int
+		if len(old) == 0 {
+			// Pure insertion: no context to match, trust the header.
+			switch {
+			case h.eof || hint < 0:
+				at = len(lines)
+			default:
+				at = min(max(hint, from), len(lines))
+			}
+		} else {
+			at, fuzz = seekLines(lines, old, from, hint, h.eof)
+			if at < 0 {
+				out.conflicts = append(out.conflicts, closestConflict(lines, old, i+1, h.header, hint))
+				continue
+			}
+		}
+
+		if note := placementNote(i+1, at, hint, fuzz); note != "" {
+			out.notes = append(out.notes, note)
+		}
+		edits = append(edits, hunkEdit{start: at, oldLen: len(old), newLines: mergeContext(h, lines[at:at+len(old)]), noNewline: h.noNewline})
+		cursor = at + len(old)
+		for _, l := range h.lines {
+			switch l.op {
+			case '+':
+				out.added++
+			case '-':
+				out.removed++
+			}
+		}
+	}
+	if len(out.conflicts) > 0 {
+		return out
+	}
+
+	var b []string
+	prev := 0
+	for _, e := range edits {
+		b = append(b, lines[prev:e.start]...)
+		b = append(b, e.newLines...)
+		prev = e.start + e.oldLen
+	}
+	b = append(b, lines[prev:]...)
+	if n := len(edits); n > 0 && edits[n-1].start+edits[n-1].oldLen == len(lines) {
+		// The last hunk touched the end of the file; its marker decides.
+		trailingNL = !edits[n-1].noNewline
+	}
+
+	result := strings.Join(b, "\n")
+	if trailingNL && len(b) > 0 {
+		result += "\n"
+	}
+	if crlf {
+		result = strings.ReplaceAll(result, "\n", "\r\n")
+	}
+	out.content = result
+	return out
+}
+
+// mergeContext builds the replacement lines for a placed hunk. Context lines
+// keep the file's own text, so a fuzzy match does not rewrite whitespace or
+// punctuation the patch did not mean to change.
+func mergeContext(h *patchHunk, matched []string) []string {
+	var out []string
+	j := 0
+	for _, l := range h.lines {
+		switch l.op {
+		case '+':
+			out = append(out, l.text)
+		case '-':
+			j++
+		default:
+			out = append(out, matched[j])
+			j++
+		}
+	}
+	return out
+}
+
+// splitPatchLines splits content into lines without the final empty element
+// a trailing newline would produce.
+func splitPatchLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// seekLines finds want in lines at or after from. At each fuzz level the
+// candidate nearest to hint wins (the first one when hint < 0). With eof the
+// match must end at the last line. Returns -1 when nothing matches.
+func seekLines(lines, want []string, from, hint int, eof bool) (int, int) {
+	last := len(lines) - len(want)
+	if last < from {
+		return -1, 0
+	}
+	first := from
+	if eof {
+		first = last
+	}
+	for level := fuzzExact; level < fuzzLevels; level++ {
+		best := -1
+		for i := first; i <= last; i++ {
+			if !linesMatch(lines[i:i+len(want)], want, level) {
+				continue
+			}
+			if hint < 0 {
+				return i, level
+			}
+			if best < 0 || absInt(i-hint) < absInt(best-hint) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			return best, level
+		}
+	}
+	return -1, 0
+}
+
+func linesMatch(got, want []string, level int) bool {
+	for i := range want {
+		if normalizePatchLine(got[i], level) != normalizePatchLine(want[i], level) {
+			return false
+		}
+	}
+	return true
+}
+
+// patchPunctReplacer folds typographic characters models tend to emit in
+// place of their ASCII forms.
+var patchPunctReplacer = strings.NewReplacer(
+	"\u2018", "'", "\u2019", "'", "\u201a", "'", "\u201b", "'",
+	"\u201c", `"`, "\u201d", `"`, "\u201e", `"`, "\u201f", `"`,
+	"\u2010", "-", "\u2011", "-", "\u2012", "-", "\u2013", "-", "\u2014", "-", "\u2212", "-",
+	"\u00a0", " ", "\u2007", " ", "\u202f", " ", "\u2026", "...",
+)
+
+func normalizePatchLine(s string, level int) string {
+	switch level {
+	case fuzzExact:
+		return s
+	case fuzzTrimRight:
+		return strings.TrimRight(s, " \t\r")
+	case fuzzTrimSpace:
+		return strings.TrimSpace(s)
+	default:
+		return strings.Join(strings.Fields(patchPunctReplacer.Replace(s)), " ")
+	}
+}
+
+func placementNote(index, at, hint, fuzz int) string {
+	var parts []string
+	if hint >= 0 && at != hint {
+		parts = append(parts, fmt.Sprintf("offset %+d lines", at-hint))
+	}
+	if fuzz > fuzzExact {
+		parts = append(parts, fuzzNotes[fuzz])
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("hunk %d applied at line %d (%s)", index, at+1, strings.Join(parts, ", "))
+}
+
+// closestConflict scores every window of the file against the expected
+// lines so the report can point at what the model probably meant.
+func closestConflict(lines, want []string, index int, header string, hint int) hunkConflict {
+	c := hunkConflict{index: index, header: header, reason: "expected lines not found", expected: want}
+	size := min(len(want), len(lines))
+	if size == 0 {
+		return c
+	}
+	best, bestScore := -1, 0
+	for i := 0; i+size <= len(lines); i++ {
+		score := 0
+		for j := 0; j < size; j++ {
+			if normalizePatchLine(lines[i+j], fuzzNormalized) == normalizePatchLine(want[j], fuzzNormalized) {
+				score++
+			}
+		}
+		if score > bestScore || (score == bestScore && score > 0 && hint >= 0 && absInt(i-hint) < absInt(best-hint)) {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return c
+	}
+	c.bestLine = best + 1
+	c.bestMatched = bestScore
+	c.bestLines = lines[best : best+size]
+	return c
+}
+
+// format renders the conflict for the tool result.
+func (c hunkConflict) format(path string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: hunk %d", path, c.index)
+	if c.header != "" && c.header != "@@" {
+		fmt.Fprintf(&b, " (%s)", c.header)
+	}
+	fmt.Fprintf(&b, ": %s\n", c.reason)
+	b.WriteString("  expected:\n")
+	for _, l := range c.expected {
+		fmt.Fprintf(&b, "    | %s\n", l)
+	}
+	if c.bestLine > 0 {
+		fmt.Fprintf(&b, "  closest match at line %d (%d of %d lines match):\n", c.bestLine, c.bestMatched, len(c.expected))
+		for j, l := range c.bestLines {
+			fmt.Fprintf(&b, "  %4d | %s\n", c.bestLine+j, l)
+		}
+	}
+	return b.String()
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
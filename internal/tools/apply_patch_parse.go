@@ -0,0 +1,363 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// patchOp is what a file section of a patch does to its file.
+type patchOp int
+
+const (
+	patchUpdate patchOp = iota
+	patchAdd
+	patchDelete
+)
+
+// filePatch is one file's changes, normalized from either patch format.
+type filePatch struct {
+	op     patchOp
+	path   string
+	moveTo string // rename target for updates; "" = stays in place
+	hunks  []patchHunk
+	// addContent is the full content for patchAdd.
+	addContent string
+}
+
+// patchHunk is a run of context, removed and added lines.
+type patchHunk struct {
+	header   string // shown in reports ("@@ -10,4 +10,5 @@", "@@ func main()")
+	anchor   string // Codex "@@ <line>": seek this line before matching
+	oldStart int    // start line from a unified header; -1 = unknown
+	lines    []hunkLine
+	eof      bool // must match at the end of the file
+	// noNewline records "\ No newline at end of file" for the new side.
+	noNewline bool
+}
+
+type hunkLine struct {
+	op   byte // ' ', '-', '+'
+	text string
+}
+
+// oldLines returns the lines the hunk expects to find.
+func (h *patchHunk) oldLines() []string {
+	var out []string
+	for _, l := range h.lines {
+		if l.op != '+' {
+			out = append(out, l.text)
+		}
+	}
+	return out
+}
+
+// parsePatch detects the patch format and parses it into file patches.
+func parsePatch(text string) ([]*filePatch, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	var (
+		files []*filePatch
+		err   error
+	)
+	if strings.Contains(text, "*** Begin Patch") {
+		files, err = parseCodexPatch(lines)
+	} else {
+		files, err = parseUnifiedDiff(lines)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("patch contains no file changes")
+	}
+	seen := map[string]bool{}
+	for _, f := range files {
+		for _, p := range []string{f.path, f.moveTo} {
+			if p == "" {
+				continue
+			}
+			if seen[p] {
+				return nil, fmt.Errorf("%s appears more than once in the patch; combine its changes into one section", p)
+			}
+			seen[p] = true
+		}
+	}
+	return files, nil
+}
+
+// parseCodexPatch parses the "*** Begin Patch" envelope format.
+func parseCodexPatch(lines []string) ([]*filePatch, error) {
+	i := 0
+	for i < len(lines) && strings.TrimSpace(lines[i]) != "*** Begin Patch" {
+		i++
+	}
+	i++
+	var files []*filePatch
+	var cur *filePatch
+	var hunk *patchHunk
+	flushHunk := func() {
+		if cur != nil && hunk != nil && len(hunk.lines) > 0 {
+			cur.hunks = append(cur.hunks, *hunk)
+		}
+		hunk = nil
+	}
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		lineNo := i + 1
+		switch {
+		case strings.TrimSpace(line) == "*** End Patch":
+			flushHunk()
+			for _, f := range files {
+				if f.op == patchUpdate && len(f.hunks) == 0 && f.moveTo == "" {
+					return nil, fmt.Errorf("%s: no changes in *** Update File section", f.path)
+				}
+			}
+			return files, nil
+		case strings.HasPrefix(line, "*** Add File: "):
+			flushHunk()
+			cur = &filePatch{op: patchAdd, path: strings.TrimSpace(strings.TrimPrefix(line, "*** Add File: "))}
+			files = append(files, cur)
+			var content []string
+			for i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+") {
+				i++
+				content = append(content, lines[i][1:])
+			}
+			if len(content) > 0 {
+				cur.addContent = strings.Join(content, "\n") + "\n"
+			}
+		case strings.HasPrefix(line, "*** Delete File: "):
+			flushHunk()
+			cur = &filePatch{op: patchDelete, path: strings.TrimSpace(strings.TrimPrefix(line, "*** Delete File: "))}
+			files = append(files, cur)
+		case strings.HasPrefix(line, "*** Update File: "):
+			flushHunk()
+			cur = &filePatch{op: patchUpdate, path: strings.TrimSpace(strings.TrimPrefix(line, "*** Update File: "))}
+			files = append(files, cur)
+		case strings.HasPrefix(line, "*** Move to: "):
+			if cur == nil || cur.op != patchUpdate || len(cur.hunks) > 0 || hunk != nil {
+				return nil, fmt.Errorf("line %d: *** Move to must directly follow *** Update File", lineNo)
+			}
+			cur.moveTo = strings.TrimSpace(strings.TrimPrefix(line, "*** Move to: "))
+		case strings.TrimSpace(line) == "*** End of File":
+			if hunk == nil {
+				return nil, fmt.Errorf("line %d: *** End of File outside a hunk", lineNo)
+			}
+			hunk.eof = true
+		case strings.HasPrefix(line, "@@"):
+			if cur == nil || cur.op != patchUpdate {
+				return nil, fmt.Errorf("line %d: hunk outside an *** Update File section", lineNo)
+			}
+			flushHunk()
+			hunk = &patchHunk{header: strings.TrimSpace(line), oldStart: -1}
+			if m := unifiedHunkHeaderRe.FindStringSubmatch(line); m != nil {
+				// Unified-style header inside the envelope: a position, not an anchor.
+				hunk.oldStart, _ = strconv.Atoi(m[1])
+			} else if anchor := strings.TrimSpace(strings.TrimPrefix(line, "@@")); anchor != "" {
+				hunk.anchor = anchor
+			}
+		case cur != nil && cur.op == patchUpdate && (line == "" || strings.ContainsRune(" +-", rune(line[0]))):
+			if hunk == nil {
+				hunk = &patchHunk{header: "@@", oldStart: -1}
+			}
+			if line == "" {
+				hunk.lines = append(hunk.lines, hunkLine{op: ' '})
+			} else {
+				hunk.lines = append(hunk.lines, hunkLine{op: line[0], text: line[1:]})
+			}
+		case strings.TrimSpace(line) == "":
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q", lineNo, truncateStr(line, 60))
+		}
+	}
+	return nil, fmt.Errorf("patch is missing *** End Patch")
+}
+
+var unifiedHunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@(.*)$`)
+
+// parseUnifiedDiff parses unified diffs, including git extended headers
+// (new/deleted file, rename from/to). Hunk line counts in headers are not
+// trusted: model-written diffs often miscount, so a hunk ends at the next
+// header instead.
+func parseUnifiedDiff(lines []string) ([]*filePatch, error) {
+	var files []*filePatch
+	var cur *filePatch
+	var hunk *patchHunk
+	var gitOld, gitNew string
+	inGit := false
+	bareBlanks := 0 // trailing empty lines written without the " " prefix
+	flushHunk := func() {
+		if cur != nil && hunk != nil {
+			// Bare blank lines at the end of a hunk are usually the
+			// separator between sections, not context.
+			hunk.lines = hunk.lines[:len(hunk.lines)-bareBlanks]
+			if len(hunk.lines) > 0 {
+				cur.hunks = append(cur.hunks, *hunk)
+			}
+		}
+		hunk = nil
+		bareBlanks = 0
+	}
+	finishFile := func() error {
+		flushHunk()
+		if cur == nil {
+			return nil
+		}
+		f := cur
+		cur = nil
+		switch {
+		case f.op == patchAdd:
+			var b strings.Builder
+			for _, h := range f.hunks {
+				for _, l := range h.lines {
+					if l.op != '+' {
+						return fmt.Errorf("%s: a new file's hunks may only add lines", f.path)
+					}
+					b.WriteString(l.text)
+					b.WriteByte('\n')
+				}
+			}
+			f.addContent = b.String()
+			if n := len(f.hunks); n > 0 && f.hunks[n-1].noNewline {
+				f.addContent = strings.TrimSuffix(f.addContent, "\n")
+			}
+			f.hunks = nil
+		case f.op == patchUpdate && len(f.hunks) == 0 && f.moveTo == "":
+			// Mode-only git sections carry no content change.
+			return nil
+		}
+		files = append(files, f)
+		return nil
+	}
+	startGitFile := func() {
+		cur = &filePatch{op: patchUpdate, path: gitNew}
+		if gitOld != gitNew {
+			cur.path, cur.moveTo = gitOld, gitNew
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		lineNo := i + 1
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			if err := finishFile(); err != nil {
+				return nil, err
+			}
+			inGit = true
+			gitOld, gitNew = parseGitDiffLine(strings.TrimPrefix(line, "diff --git "))
+			startGitFile()
+		case inGit && hunk == nil && strings.HasPrefix(line, "new file mode"):
+			cur.op = patchAdd
+		case inGit && hunk == nil && strings.HasPrefix(line, "deleted file mode"):
+			cur.op = patchDelete
+		case inGit && hunk == nil && strings.HasPrefix(line, "rename from "):
+			cur.path = strings.TrimPrefix(line, "rename from ")
+		case inGit && hunk == nil && strings.HasPrefix(line, "rename to "):
+			cur.moveTo = strings.TrimPrefix(line, "rename to ")
+		case inGit && hunk == nil && (strings.HasPrefix(line, "index ") || strings.HasPrefix(line, "similarity index") ||
+			strings.HasPrefix(line, "dissimilarity index") || strings.HasPrefix(line, "old mode") || strings.HasPrefix(line, "new mode") ||
+			strings.HasPrefix(line, "copy from") || strings.HasPrefix(line, "copy to")):
+		case strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch":
+			return nil, fmt.Errorf("line %d: binary patches are not supported", lineNo)
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			oldPath := parseDiffPath(strings.TrimPrefix(line, "--- "))
+			newPath := parseDiffPath(strings.TrimPrefix(lines[i+1], "+++ "))
+			i++
+			if cur == nil || hunk != nil || len(cur.hunks) > 0 {
+				if err := finishFile(); err != nil {
+					return nil, err
+				}
+				inGit = false
+			}
+			if !inGit {
+				oldPath, newPath = stripDiffPrefixes(oldPath, newPath)
+			} else {
+				oldPath, newPath = stripGitPrefix(oldPath, "a/"), stripGitPrefix(newPath, "b/")
+			}
+			switch {
+			case oldPath == "/dev/null" && newPath == "/dev/null":
+				return nil, fmt.Errorf("line %d: both sides are /dev/null", lineNo)
+			case oldPath == "/dev/null":
+				cur = &filePatch{op: patchAdd, path: newPath}
+			case newPath == "/dev/null":
+				cur = &filePatch{op: patchDelete, path: oldPath}
+			default:
+				cur = &filePatch{op: patchUpdate, path: oldPath}
+				if newPath != oldPath {
+					cur.moveTo = newPath
+				}
+			}
+			inGit = false
+		case strings.HasPrefix(line, "@@"):
+			if cur == nil {
+				return nil, fmt.Errorf("line %d: hunk before any file header", lineNo)
+			}
+			flushHunk()
+			m := unifiedHunkHeaderRe.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("line %d: malformed hunk header %q", lineNo, truncateStr(line, 60))
+			}
+			start, _ := strconv.Atoi(m[1])
+			hunk = &patchHunk{header: strings.TrimSpace(line), oldStart: start}
+		case hunk != nil && line == `\ No newline at end of file`:
+			if n := len(hunk.lines); n > 0 && hunk.lines[n-1].op != '-' {
+				hunk.noNewline = true
+			}
+		case hunk != nil && (line == "" || strings.ContainsRune(" +-", rune(line[0]))):
+			if line == "" {
+				hunk.lines = append(hunk.lines, hunkLine{op: ' '})
+				bareBlanks++
+			} else {
+				hunk.lines = append(hunk.lines, hunkLine{op: line[0], text: line[1:]})
+				bareBlanks = 0
+			}
+		default:
+			// Commit messages and other preamble outside hunks are ignored,
+			// like git apply does.
+			if hunk != nil {
+				flushHunk()
+			}
+		}
+	}
+	if err := finishFile(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// parseGitDiffLine splits "a/x b/x" from a diff --git line. Paths with
+// spaces are ambiguous there; the ---/+++ or rename lines refine them.
+func parseGitDiffLine(s string) (string, string) {
+	if i := strings.Index(s, " b/"); i >= 0 {
+		return stripGitPrefix(s[:i], "a/"), s[i+3:]
+	}
+	old, new, _ := strings.Cut(s, " ")
+	return old, new
+}
+
+// parseDiffPath drops the timestamp some diff tools append after a tab.
+func parseDiffPath(s string) string {
+	if i := strings.IndexByte(s, '\t'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+func stripGitPrefix(p, prefix string) string {
+	if p == "/dev/null" {
+		return p
+	}
+	return strings.TrimPrefix(p, prefix)
+}
+
+// stripDiffPrefixes removes git's a/ and b/ prefixes when both sides use them.
+func stripDiffPrefixes(oldPath, newPath string) (string, string) {
+	oldOK := oldPath == "/dev/null" || strings.HasPrefix(oldPath, "a/")
+	newOK := newPath == "/dev/null" || strings.HasPrefix(newPath, "b/")
+	if oldOK && newOK {
+		return stripGitPrefix(oldPath, "a/"), stripGitPrefix(newPath, "b/")
+	}
+	return oldPath, newPath
+}
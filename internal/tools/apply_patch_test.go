@@ -0,0 +1,253 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestApplyPatchUnifiedMultiFile(t *testing.T) {
+	ws := t.TempDir()
+	writeTestFiles(t, ws, map[string]string{
+		"main.go":    "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n",
+		"pkg/lib.go": "package pkg\n\n// extra line shifts the hunk\n\nfunc A() int { return 1 }\n",
+	})
+	patch := `diff --git a/main.go b/main.go
+index 111..222 100644
+--- a/main.go
++++ b/main.go
+@@ -3,3 +3,4 @@
+ func main() {
+ 	println("hi")
++	println("bye")
+ }
+--- a/pkg/lib.go
++++ b/pkg/lib.go
+@@ -2,2 +2,2 @@
+
+-func A() int { return 1 }
++func A() int { return 2 }
+`
+	res := NewApplyPatchTool(ws, true).Execute(context.Background(), map[string]any{"patch": patch})
+	if res.IsError {
+		t.Fatalf("result = %s", res.ForLLM)
+	}
+	if got := readTestFile(t, ws, "main.go"); !strings.Contains(got, "println(\"bye\")\n}\n") {
+		t.Errorf("main.go = %q", got)
+	}
+	if got := readTestFile(t, ws, "pkg/lib.go"); !strings.HasSuffix(got, "return 2 }\n") {
+		t.Errorf("lib.go = %q", got)
+	}
+	if !strings.Contains(res.ForLLM, "M main.go (+1 -0)") || !strings.Contains(res.ForLLM, "offset +2 lines") {
+		t.Errorf("summary = %s", res.ForLLM)
+	}
+}
+
+func TestApplyPatchCodexFormat(t *testing.T) {
+	ws := t.TempDir()
+	writeTestFiles(t, ws, map[string]string{
+		"a.py":   "def f():\n    return 1\n\ndef g():\n    return 1\n",
+		"old.md": "# old\n",
+		"gone":   "bye\n",
+	})
+	patch := `*** Begin Patch
+*** Update File: a.py
+@@ def g():
+-    return 1
++    return 2
+*** Update File: old.md
+*** Move to: docs/new.md
+@@
+-# old
++# new
+*** Add File: notes/todo.txt
++one
++two
+*** Delete File: gone
+*** End Patch`
+	res := NewApplyPatchTool(ws, true).Execute(context.Background(), map[string]any{"patch": patch})
+	if res.IsError {
+		t.Fatalf("result = %s", res.ForLLM)
+	}
+	// The anchor picks the second "return 1", not the first.
+	if got := readTestFile(t, ws, "a.py"); got != "def f():\n    return 1\n\ndef g():\n    return 2\n" {
+		t.Errorf("a.py = %q", got)
+	}
+	if got := readTestFile(t, ws, "docs/new.md"); got != "# new\n" {
+		t.Errorf("docs/new.md = %q", got)
+	}
+	if got := readTestFile(t, ws, "notes/todo.txt"); got != "one\ntwo\n" {
+		t.Errorf("todo.txt = %q", got)
+	}
+	for _, name := range []string{"old.md", "gone"} {
+		if _, err := os.Stat(filepath.Join(ws, name)); !os.IsNotExist(err) {
+			t.Errorf("%s still exists", name)
+		}
+	}
+}
+
+func TestApplyPatchFuzzyMatch(t *testing.T) {
+	ws := t.TempDir()
+	writeTestFiles(t, ws, map[string]string{"f.txt": "  alpha  \nsay \u201chello\u201d\ngamma\n"})
+	patch := "--- f.txt\n+++ f.txt\n@@ -1,3 +1,3 @@\n alpha\n-say \"hello\"\n+say \"bye\"\n gamma\n"
+	res := NewApplyPatchTool(ws, true).Execute(context.Background(), map[string]any{"patch": patch})
+	if res.IsError {
+		t.Fatalf("result = %s", res.ForLLM)
+	}
+	if got := readTestFile(t, ws, "f.txt"); got != "  alpha  \nsay \"bye\"\ngamma\n" {
+		t.Errorf("f.txt = %q", got)
+	}
+	if !strings.Contains(res.ForLLM, "punctuation variants") {
+		t.Errorf("summary = %s", res.ForLLM)
+	}
+}
+
+func TestApplyPatchConflictWritesNothing(t *testing.T) {
+	ws := t.TempDir()
+	writeTestFiles(t, ws, map[string]string{
+		"ok.txt":  "one\ntwo\n",
+		"bad.txt": "red\ngreen\nblue\n",
+	})
+	patch := `--- a/ok.txt
++++ b/ok.txt
+@@ -1,2 +1,2 @@
+ one
+-two
++TWO
+--- a/bad.txt
++++ b/bad.txt
+@@ -1,3 +1,3 @@
+ red
+-yellow
++purple
+ blue
+`
+	res := NewApplyPatchTool(ws, true).Execute(context.Background(), map[string]any{"patch": patch})
+	if !res.IsError {
+		t.Fatalf("expected conflict, got %s", res.ForLLM)
+	}
+	for _, want := range []string{"bad.txt: hunk 1 (@@ -1,3 +1,3 @@)", "| yellow", "closest match at line 1 (2 of 3 lines match)", "No files were changed"} {
+		if !strings.Contains(res.ForLLM, want) {
+			t.Errorf("report missing %q:\n%s", want, res.ForLLM)
+		}
+	}
+	if got := readTestFile(t, ws, "ok.txt"); got != "one\ntwo\n" {
+		t.Errorf("ok.txt modified: %q", got)
+	}
+}
+
+func TestApplyPatchDryRun(t *testing.T) {
+	ws := t.TempDir()
+	writeTestFiles(t, ws, map[string]string{"x.txt": "a\nb\n"})
+	patch := "*** Begin Patch\n*** Update File: x.txt\n a\n-b\n+c\n*** Add File: y.txt\n+new\n*** End Patch\n"
+	res := NewApplyPatchTool(ws, true).Execute(context.Background(), map[string]any{"patch": patch, "dry_run": true})
+	if res.IsError || !strings.Contains(res.ForLLM, "nothing was written") || !strings.Contains(res.ForLLM, "A y.txt (+1)") {
+		t.Fatalf("result = %s", res.ForLLM)
+	}
+	if got := readTestFile(t, ws, "x.txt"); got != "a\nb\n" {
+		t.Errorf("x.txt modified: %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(ws, "y.txt")); !os.IsNotExist(err) {
+		t.Error("y.txt created by dry run")
+	}
+}
+
+func TestApplyPatchPathChecks(t *testing.T) {
+	ws := t.TempDir()
+	writeTestFiles(t, ws, map[string]string{".goclaw/secret": "x\n", "a.txt": "a\n"})
+	tool := NewApplyPatchTool(ws, true)
+	tool.DenyPaths(".goclaw")
+
+	cases := []struct{ patch, want string }{
+		{"*** Begin Patch\n*** Update File: .goclaw/secret\n-x\n+y\n*** End Patch", "restricted"},
+		{"*** Begin Patch\n*** Add File: ../escape.txt\n+y\n*** End Patch", "access denied"},
+		{"*** Begin Patch\n*** Update File: a.txt\n*** Move to: .goclaw/a.txt\n*** End Patch", "restricted"},
+		{"*** Begin Patch\n*** Add File: a.txt\n+y\n*** End Patch", "already exists"},
+		{"*** Begin Patch\n*** Update File: a.txt\n-a\n+b\n*** Update File: ./a.txt\n-a\n+c\n*** End Patch", "more than once"},
+	}
+	for _, tc := range cases {
+		res := tool.Execute(context.Background(), map[string]any{"patch": tc.patch})
+		if !res.IsError || !strings.Contains(res.ForLLM, tc.want) {
+			t.Errorf("patch %q: result = %q, want %q", tc.patch, res.ForLLM, tc.want)
+		}
+	}
+	if got := readTestFile(t, ws, "a.txt"); got != "a\n" {
+		t.Errorf("a.txt modified: %q", got)
+	}
+}
+
+func TestApplyHunksNewlineHandling(t *testing.T) {
+	files, err := parsePatch("--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n\\ No newline at end of file\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := applyHunks("a\r\nb\r\n", files[0].hunks).content; got != "a\r\nc" {
+		t.Errorf("content = %q", got)
+	}
+}
+
+func TestParsePatchGitHeaders(t *testing.T) {
+	patch := `From 1234 Mon Sep 17 00:00:00 2001
+Subject: [PATCH] rename
+
+diff --git a/old.txt b/new.txt
+similarity index 100%
+rename from old.txt
+rename to new.txt
+diff --git a/added.txt b/added.txt
+new file mode 100644
+index 0000000..e69de29
+--- /dev/null
++++ b/added.txt
+@@ -0,0 +1,2 @@
++x
++y
+diff --git a/mode.sh b/mode.sh
+old mode 100644
+new mode 100755
+diff --git a/del.txt b/del.txt
+deleted file mode 100644
+--- a/del.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`
+	files, err := parsePatch(patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("files = %d", len(files))
+	}
+	if f := files[0]; f.op != patchUpdate || f.path != "old.txt" || f.moveTo != "new.txt" {
+		t.Errorf("rename = %+v", f)
+	}
+	if f := files[1]; f.op != patchAdd || f.path != "added.txt" || f.addContent != "x\ny\n" {
+		t.Errorf("add = %+v", f)
+	}
+	if f := files[2]; f.op != patchDelete || f.path != "del.txt" {
+		t.Errorf("delete = %+v", f)
+	}
+
+	if _, err := parsePatch("Binary files a/x.png and b/x.png differ\n"); err == nil || !strings.Contains(err.Error(), "binary") {
+		t.Errorf("binary err = %v", err)
+	}
+	if _, err := parsePatch("*** Begin Patch\n*** Update File: a\n-x\n"); err == nil || !strings.Contains(err.Error(), "End Patch") {
+		t.Errorf("unterminated err = %v", err)
+	}
+}
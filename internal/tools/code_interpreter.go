@@ -0,0 +1,280 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/sandbox"
+)
+
+const (
+	codeInterpreterDefaultTimeout = 60 * time.Second
+	codeInterpreterMaxTimeout     = 10 * time.Minute
+	codeInterpreterDefaultIdle    = 30 * time.Minute
+	// codeInterpreterOutDir is where rich outputs (plots, tables) are written,
+	// relative to the workspace.
+	codeInterpreterOutDir = "interpreter"
+)
+
+// CodeInterpreterTool runs code cells in a long-lived Python or Node.js
+// kernel inside the session's sandbox container. Variables, imports and
+// loaded data persist between calls until the kernel is restarted, crashes
+// or sits idle past its timeout.
+type CodeInterpreterTool struct {
+	workspace  string
+	sandboxMgr sandbox.Manager
+	kernels    *kernelPool
+}
+
+// NewCodeInterpreterTool creates a code interpreter backed by sandbox
+// containers. There is no host fallback: without a sandbox the tool errors.
+func NewCodeInterpreterTool(workspace string, mgr sandbox.Manager) *CodeInterpreterTool {
+	return &CodeInterpreterTool{workspace: workspace, sandboxMgr: mgr, kernels: newKernelPool()}
+}
+
+func (t *CodeInterpreterTool) Name() string { return "code_interpreter" }
+
+func (t *CodeInterpreterTool) Description() string {
+	return "Run code in a persistent Python (default) or JavaScript kernel inside the sandbox. " +
+		"State (variables, imports, loaded data) persists across calls, like notebook cells. " +
+		"The value of the last expression is shown. Python matplotlib figures are saved as PNG and " +
+		"pandas DataFrame results as CSV under interpreter/ in the workspace; share them with send_file or set deliver=true. " +
+		"Use action=restart to reset the kernel."
+}
+
+func (t *CodeInterpreterTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"code": map[string]any{
+				"type":        "string",
+				"description": "Code to execute as one cell",
+			},
+			"language": map[string]any{
+				"type":        "string",
+				"enum":        []string{"python", "javascript"},
+				"description": "Kernel language (default python). Each language has its own kernel.",
+			},
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"execute", "restart"},
+				"description": "execute (default) runs code; restart discards the kernel state and starts fresh",
+			},
+			"timeout": map[string]any{
+				"type":        "number",
+				"description": "Cell timeout in seconds (default 60, max 600). The cell is interrupted on timeout; the kernel state is kept when possible.",
+			},
+			"deliver": map[string]any{
+				"type":        "boolean",
+				"description": "Send the files produced by this cell (plots, tables) to the user as attachments",
+			},
+		},
+	}
+}
+
+// codeInterpreterSettings is the "code_interpreter" entry of builtin tool
+// settings.
+type codeInterpreterSettings struct {
+	TimeoutSeconds     int `json:"timeout_seconds,omitempty"`
+	IdleTimeoutMinutes int `json:"idle_timeout_minutes,omitempty"`
+}
+
+func (t *CodeInterpreterTool) settings(ctx context.Context) (timeout, idle time.Duration) {
+	timeout, idle = codeInterpreterDefaultTimeout, codeInterpreterDefaultIdle
+	if settings := BuiltinToolSettingsFromCtx(ctx); settings != nil {
+		if raw, ok := settings["code_interpreter"]; ok && len(raw) > 0 {
+			var s codeInterpreterSettings
+			if err := json.Unmarshal(raw, &s); err != nil {
+				slog.Warn("code_interpreter: invalid settings, using defaults", "error", err)
+			} else {
+				if s.TimeoutSeconds > 0 {
+					timeout = time.Duration(s.TimeoutSeconds) * time.Second
+				}
+				if s.IdleTimeoutMinutes > 0 {
+					idle = time.Duration(s.IdleTimeoutMinutes) * time.Minute
+				}
+			}
+		}
+	}
+	return min(timeout, codeInterpreterMaxTimeout), idle
+}
+
+func (t *CodeInterpreterTool) Execute(ctx context.Context, args map[string]any) *Result {
+	code, _ := args["code"].(string)
+	language, _ := args["language"].(string)
+	if language == "" {
+		language = "python"
+	}
+	if _, ok := kernelCommands[language]; !ok {
+		return ErrorResult(fmt.Sprintf("unsupported language %q (use python or javascript)", language))
+	}
+	action, _ := args["action"].(string)
+	if action == "" {
+		action = "execute"
+	}
+	if action != "execute" && action != "restart" {
+		return ErrorResult(fmt.Sprintf("unknown action %q (use execute or restart)", action))
+	}
+	if action == "execute" && strings.TrimSpace(code) == "" {
+		return ErrorResult("code is required")
+	}
+	timeout, idle := t.settings(ctx)
+	if v, ok := args["timeout"].(float64); ok && v > 0 {
+		timeout = min(time.Duration(v*float64(time.Second)), codeInterpreterMaxTimeout)
+	}
+	deliver, _ := args["deliver"].(bool)
+
+	sandboxKey := ToolSandboxKeyFromCtx(ctx)
+	if t.sandboxMgr == nil || sandboxKey == "" {
+		return ErrorResult("code_interpreter requires a sandboxed session")
+	}
+	key := kernelKey(sandboxKey, language)
+	if action == "restart" {
+		if !t.kernels.drop(key, "") {
+			return SilentResult(fmt.Sprintf("No %s kernel was running; the next cell starts a fresh one.", language))
+		}
+		return SilentResult(fmt.Sprintf("The %s kernel was restarted. All variables and imports were cleared.", language))
+	}
+
+	mountWorkspace, err := effectiveSandboxWorkspace(ctx, t.workspace)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	containerCwd, err := sandboxCwdForHostPath(mountWorkspace, mountWorkspace, sandbox.DefaultContainerWorkdir)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("sandbox path mapping: %v", err))
+	}
+	sb, err := t.sandboxMgr.Get(ctx, sandboxKey, mountWorkspace, SandboxConfigFromCtx(ctx))
+	if err != nil {
+		if errors.Is(err, sandbox.ErrSandboxDisabled) {
+			return ErrorResult("code_interpreter requires a sandboxed session (sandbox is disabled)")
+		}
+		return ErrorResult(fmt.Sprintf("sandbox unavailable: %v (will not fall back to unsandboxed host execution)", err))
+	}
+
+	kernel, note, err := t.kernels.get(ctx, key, sb, language, containerCwd, idle)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("start %s kernel: %v", language, err))
+	}
+	resp, err := kernel.execute(ctx, kernelRequest{
+		Code:      code,
+		OutDir:    path.Join(containerCwd, codeInterpreterOutDir),
+		MaxOutput: execMaxOutputChars,
+	}, timeout)
+	switch {
+	case errors.Is(err, errKernelTimedOut):
+		if !kernel.alive() {
+			t.kernels.drop(key, "the previous kernel was killed after a cell timed out")
+			resp.Error = fmt.Sprintf("cell timed out after %s and did not respond to the interrupt; the kernel was killed and its state lost", timeout)
+		} else {
+			resp.Error = fmt.Sprintf("cell timed out after %s and was interrupted; kernel state is kept\n%s", timeout, resp.Error)
+		}
+	case errors.Is(err, errKernelDied):
+		t.kernels.drop(key, "the previous kernel crashed")
+		resp.Error = "the kernel process exited while running this cell (out of memory or a crash); its state was lost"
+		if out := kernel.startupOutput(); out != "no output" {
+			resp.Error += "\n" + out
+		}
+	case err != nil:
+		return ErrorResult(fmt.Sprintf("code_interpreter: %v", err))
+	}
+
+	files := t.hostFiles(resp.Files, containerCwd, mountWorkspace)
+	output := formatInterpreterOutput(note, resp, files)
+	if resp.Error != "" {
+		return ErrorResult(output)
+	}
+	result := SilentResult(output)
+	if deliver && len(files) > 0 {
+		dm := DeliveredMediaFromCtx(ctx)
+		for _, f := range files {
+			if dm != nil && dm.IsDelivered(f.host) {
+				continue
+			}
+			result.Media = append(result.Media, bus.MediaFile{
+				Path:     f.host,
+				Filename: filepath.Base(f.host),
+				MimeType: mimeFromPath(f.host),
+			})
+			if dm != nil {
+				dm.Mark(f.host)
+			}
+		}
+	}
+	return result
+}
+
+// interpreterFile is a rich output visible in the workspace.
+type interpreterFile struct {
+	rel  string // workspace-relative path, as send_file accepts it
+	host string
+}
+
+// hostFiles maps container output paths to host files, dropping any that
+// are outside the workspace mount or were not written (read-only access).
+// The list comes from the kernel, which runs untrusted code, so symlinks are
+// resolved and anything that leads out of the mount is dropped.
+func (t *CodeInterpreterTool) hostFiles(paths []string, containerCwd, mountWorkspace string) []interpreterFile {
+	root, err := filepath.EvalSymlinks(mountWorkspace)
+	if err != nil {
+		return nil
+	}
+	var files []interpreterFile
+	for _, p := range paths {
+		rel, ok := strings.CutPrefix(path.Clean(p), containerCwd+"/")
+		if !ok {
+			continue
+		}
+		host := filepath.Join(mountWorkspace, filepath.FromSlash(rel))
+		real, err := filepath.EvalSymlinks(host)
+		if err != nil || !isPathInside(real, root) {
+			continue
+		}
+		if fi, err := os.Lstat(host); err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, interpreterFile{rel: rel, host: host})
+	}
+	return files
+}
+
+// formatInterpreterOutput lays out a cell result like exec output, with the
+// expression value and produced files after the streams.
+func formatInterpreterOutput(note string, resp kernelResponse, files []interpreterFile) string {
+	var sections []string
+	if note != "" {
+		sections = append(sections, "[new kernel: "+note+"]")
+	}
+	if out := resp.Stray + resp.Stdout; strings.TrimSpace(out) != "" {
+		sections = append(sections, strings.TrimRight(out, "\n"))
+	}
+	if strings.TrimSpace(resp.Stderr) != "" {
+		sections = append(sections, "STDERR:\n"+strings.TrimRight(resp.Stderr, "\n"))
+	}
+	if resp.Result != "" {
+		sections = append(sections, "Out:\n"+resp.Result)
+	}
+	if resp.Error != "" {
+		sections = append(sections, resp.Error)
+	}
+	if len(files) > 0 {
+		lines := []string{"Files:"}
+		for _, f := range files {
+			lines = append(lines, "- "+f.rel)
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	if len(sections) == 0 || (note != "" && len(sections) == 1) {
+		sections = append(sections, "(cell completed with no output)")
+	}
+	return capExecOutput(strings.Join(sections, "\n\n"), execMaxOutputChars)
+}
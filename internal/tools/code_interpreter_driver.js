@@ -0,0 +1,145 @@
+// code_interpreter kernel driver (Node.js).
+//
+// Started as "node -e <this file>". Same line protocol as the Python driver:
+// one JSON request per stdin line, one "\x1e" + GOCLAW_KERNEL_TOKEN prefixed
+// JSON response per request. Cells share one vm context; a cell whose last
+// expression is a promise is awaited. Rich outputs are not produced.
+"use strict";
+
+const readline = require("readline");
+const util = require("util");
+const vm = require("vm");
+
+const PREFIX = "\x1e" + (process.env.GOCLAW_KERNEL_TOKEN || "");
+const realWrite = process.stdout.write.bind(process.stdout);
+
+function send(msg) {
+  realWrite(PREFIX + JSON.stringify(msg) + "\n");
+}
+
+function capped(limit) {
+  let text = "";
+  let truncated = false;
+  return {
+    write(s) {
+      if (text.length >= limit) {
+        truncated = true;
+        return;
+      }
+      if (text.length + s.length > limit) {
+        truncated = true;
+        s = s.slice(0, limit - text.length);
+      }
+      text += s;
+    },
+    text() {
+      return truncated ? text + "\n...[output truncated]" : text;
+    },
+  };
+}
+
+let out = capped(100000);
+let err = capped(100000);
+const format = (args) => util.format(...args) + "\n";
+const sandboxConsole = {
+  log: (...a) => out.write(format(a)),
+  info: (...a) => out.write(format(a)),
+  debug: (...a) => out.write(format(a)),
+  warn: (...a) => err.write(format(a)),
+  error: (...a) => err.write(format(a)),
+  dir: (o) => out.write(util.inspect(o) + "\n"),
+  table: (o) => out.write(util.inspect(o) + "\n"),
+};
+
+const context = vm.createContext({
+  console: sandboxConsole,
+  require,
+  process: { env: process.env, argv: [], cwd: process.cwd },
+  Buffer,
+  setTimeout,
+  clearTimeout,
+  setInterval,
+  clearInterval,
+  TextEncoder,
+  TextDecoder,
+  URL,
+  fetch: globalThis.fetch,
+});
+
+let cell = 0;
+
+async function run(req) {
+  cell++;
+  out = capped(req.max_output || 100000);
+  err = capped(req.max_output || 100000);
+  const resp = { id: req.id, result: "", error: "", files: [], interrupted: false };
+  try {
+    let value = vm.runInContext(req.code || "", context, {
+      filename: "<cell-" + cell + ">",
+      breakOnSigint: true,
+    });
+    if (value && typeof value.then === "function") {
+      value = await value;
+    }
+    if (value !== undefined) {
+      context._ = value;
+      resp.result = util.inspect(value, { depth: 4, maxArrayLength: 100 });
+    }
+  } catch (e) {
+    if (e && e.code === "ERR_SCRIPT_EXECUTION_INTERRUPTED") {
+      resp.interrupted = true;
+      resp.error = "Interrupted: cell interrupted";
+    } else {
+      resp.error = cellStack(e);
+    }
+  }
+  resp.stdout = out.text();
+  resp.stderr = err.text();
+  send(resp);
+}
+
+// cellStack drops the driver's own frames from an error stack.
+function cellStack(e) {
+  if (!e || !e.stack) return String(e);
+  const lines = String(e.stack).split("\n");
+  const cut = lines.findIndex((l) => /^\s+at .*(node:vm|\[eval\])/.test(l));
+  return (cut >= 0 ? lines.slice(0, cut) : lines).join("\n");
+}
+
+// Without a listener SIGINT kills the process; breakOnSigint handles it
+// while a cell runs synchronously.
+process.on("SIGINT", () => {});
+
+const queue = [];
+let busy = false;
+
+async function drain() {
+  if (busy) return;
+  busy = true;
+  while (queue.length > 0) {
+    const line = queue.shift();
+    let req;
+    try {
+      req = JSON.parse(line);
+    } catch (e) {
+      send({ id: null, error: "bad request: " + e.message });
+      continue;
+    }
+    await run(req);
+  }
+  busy = false;
+}
+
+send({ ready: true, pid: process.pid, version: process.versions.node });
+const rl = readline.createInterface({ input: process.stdin, terminal: false });
+rl.on("line", (line) => {
+  if (line.trim() === "") return;
+  queue.push(line);
+  drain();
+});
+rl.on("close", async () => {
+  while (busy || queue.length > 0) {
+    await new Promise((r) => setTimeout(r, 10));
+  }
+  process.exit(0);
+});
@@ -0,0 +1,164 @@
+# code_interpreter kernel driver (Python).
+#
+# Started as "python3 -u -c <this file>". Reads one JSON request per line on
+# stdin and executes it in a persistent namespace. Each response is a single
+# line on stdout prefixed with "\x1e" + GOCLAW_KERNEL_TOKEN; any other stdout
+# line was written outside a cell (e.g. by a background thread) and is shown
+# with the next result.
+#
+# Request:  {"id", "code", "out_dir", "max_output"}
+# Response: {"id", "stdout", "stderr", "result", "error", "files", "interrupted"}
+
+import ast
+import contextlib
+import io
+import json
+import os
+import signal
+import sys
+import traceback
+
+TOKEN = os.environ.get("GOCLAW_KERNEL_TOKEN", "")
+PREFIX = "\x1e" + TOKEN
+
+os.environ.setdefault("MPLBACKEND", "Agg")
+os.environ.setdefault("MPLCONFIGDIR", "/tmp/matplotlib")
+
+_real_stdout = sys.stdout
+_namespace = {"__name__": "__main__", "__builtins__": __builtins__}
+_cell = 0
+
+
+def _send(msg):
+    _real_stdout.write(PREFIX + json.dumps(msg) + "\n")
+    _real_stdout.flush()
+
+
+class _Capped(io.StringIO):
+    """StringIO that stops growing after max characters."""
+
+    def __init__(self, limit):
+        super().__init__()
+        self.limit = limit
+        self.truncated = False
+
+    def write(self, s):
+        room = self.limit - self.tell()
+        if room <= 0:
+            self.truncated = True
+            return len(s)
+        if len(s) > room:
+            self.truncated = True
+            super().write(s[:room])
+            return len(s)
+        return super().write(s)
+
+    def text(self):
+        out = self.getvalue()
+        if self.truncated:
+            out += "\n...[output truncated]"
+        return out
+
+
+def _no_input(*_args, **_kwargs):
+    raise RuntimeError("input() is not available in code_interpreter")
+
+
+def _save_figures(out_dir):
+    plt = sys.modules.get("matplotlib.pyplot")
+    if plt is None or not out_dir:
+        return []
+    files = []
+    for n, num in enumerate(plt.get_fignums(), 1):
+        fig = plt.figure(num)
+        path = os.path.join(out_dir, "cell-%d-figure-%d.png" % (_cell, n))
+        try:
+            os.makedirs(out_dir, exist_ok=True)
+            fig.savefig(path, dpi=110, bbox_inches="tight")
+            files.append(path)
+        except OSError as e:
+            sys.stderr.write("could not save figure: %s\n" % e)
+    plt.close("all")
+    return files
+
+
+def _format_result(value, out_dir, files):
+    pd = sys.modules.get("pandas")
+    if pd is not None and isinstance(value, (pd.DataFrame, pd.Series)):
+        if out_dir:
+            path = os.path.join(out_dir, "cell-%d-table.csv" % _cell)
+            try:
+                os.makedirs(out_dir, exist_ok=True)
+                value.to_csv(path)
+                files.append(path)
+            except OSError as e:
+                sys.stderr.write("could not save table: %s\n" % e)
+        return value.to_string(max_rows=40, max_cols=20)
+    return repr(value)
+
+
+def _run(req):
+    global _cell
+    _cell += 1
+    out_dir = req.get("out_dir") or ""
+    stdout = _Capped(req.get("max_output") or 100000)
+    stderr = _Capped(req.get("max_output") or 100000)
+    resp = {"id": req.get("id"), "result": "", "error": "", "files": [], "interrupted": False}
+    try:
+        with contextlib.redirect_stdout(stdout), contextlib.redirect_stderr(stderr):
+            tree = ast.parse(req.get("code", ""), "<cell-%d>" % _cell, "exec")
+            last = None
+            if tree.body and isinstance(tree.body[-1], ast.Expr):
+                last = ast.Expression(tree.body.pop().value)
+            exec(compile(tree, "<cell-%d>" % _cell, "exec"), _namespace)
+            if last is not None:
+                value = eval(compile(last, "<cell-%d>" % _cell, "eval"), _namespace)
+                if value is not None:
+                    _namespace["_"] = value
+                    resp["result"] = _format_result(value, out_dir, resp["files"])
+    except KeyboardInterrupt:
+        resp["interrupted"] = True
+        resp["error"] = "KeyboardInterrupt: cell interrupted"
+    except BaseException as e:  # SystemExit included: the kernel keeps running
+        tb = e.__traceback__
+        # Drop driver frames so the traceback starts at the cell.
+        while tb is not None and tb.tb_frame.f_code.co_filename == _run.__code__.co_filename:
+            tb = tb.tb_next
+        resp["error"] = "".join(traceback.format_exception(type(e), e, tb)).rstrip()
+    with contextlib.redirect_stderr(stderr):
+        resp["files"] = _save_figures(out_dir) + resp["files"]
+    resp["stdout"] = stdout.text()
+    resp["stderr"] = stderr.text()
+    _send(resp)
+
+
+def main():
+    import builtins
+
+    builtins.input = _no_input
+    sys.stdin = io.StringIO()
+    stdin = io.TextIOWrapper(sys.__stdin__.buffer, encoding="utf-8")
+    _send({"ready": True, "pid": os.getpid(), "version": sys.version.split()[0]})
+    while True:
+        # SIGINT only interrupts a running cell; a late one is ignored.
+        signal.signal(signal.SIGINT, signal.SIG_IGN)
+        line = stdin.readline()
+        if not line:
+            return
+        line = line.strip()
+        if not line:
+            continue
+        try:
+            req = json.loads(line)
+        except ValueError as e:
+            _send({"id": None, "error": "bad request: %s" % e})
+            continue
+        try:
+            signal.signal(signal.SIGINT, signal.default_int_handler)
+            _run(req)
+        except KeyboardInterrupt:
+            _send({"id": req.get("id"), "error": "KeyboardInterrupt: cell interrupted", "interrupted": True})
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,445 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/sandbox"
+)
+
+//go:embed code_interpreter_driver.py
+var pythonKernelDriver string
+
+//go:embed code_interpreter_driver.js
+var nodeKernelDriver string
+
+// kernelCommands maps a code_interpreter language to the command starting
+// its driver inside the sandbox.
+var kernelCommands = map[string][]string{
+	"python":     {"python3", "-u", "-c", pythonKernelDriver},
+	"javascript": {"node", "-e", nodeKernelDriver},
+}
+
+const (
+	kernelStartTimeout   = 30 * time.Second
+	kernelInterruptGrace = 5 * time.Second
+	kernelCloseGrace     = 2 * time.Second
+	kernelStrayMax       = 64 * 1024
+	kernelStderrMax      = 8 * 1024
+)
+
+var (
+	errKernelDied     = errors.New("kernel process exited")
+	errKernelTimedOut = errors.New("cell timed out")
+)
+
+// kernelRequest is one cell sent to a driver.
+type kernelRequest struct {
+	ID        int    `json:"id"`
+	Code      string `json:"code"`
+	OutDir    string `json:"out_dir,omitempty"`
+	MaxOutput int    `json:"max_output"`
+}
+
+// kernelResponse is a driver message: the ready handshake or a cell result.
+type kernelResponse struct {
+	ID          *int     `json:"id"`
+	Ready       bool     `json:"ready"`
+	PID         int      `json:"pid"`
+	Version     string   `json:"version"`
+	Stdout      string   `json:"stdout"`
+	Stderr      string   `json:"stderr"`
+	Result      string   `json:"result"`
+	Error       string   `json:"error"`
+	Files       []string `json:"files"`
+	Interrupted bool     `json:"interrupted"`
+
+	// Stray is stdout written outside the protocol since the last cell.
+	Stray string `json:"-"`
+}
+
+// interpreterKernel is one running driver process. Cells run one at a time.
+type interpreterKernel struct {
+	language    string
+	sb          sandbox.Sandbox
+	proc        *sandbox.Process
+	cwd         string
+	pid         int
+	version     string
+	token       string
+	responses   chan kernelResponse
+	done        chan struct{} // closed when the driver's stdout ends
+	run         sync.Mutex    // held while a cell executes
+	nextID      int
+	idleTimeout time.Duration
+
+	mu       sync.Mutex // protects the fields below
+	stray    strings.Builder
+	stderr   strings.Builder
+	lastUsed time.Time
+	closed   bool
+}
+
+// startKernel starts a driver in sb and waits for its ready handshake.
+func startKernel(ctx context.Context, sb sandbox.Sandbox, language, cwd string) (*interpreterKernel, error) {
+	starter, ok := sb.(sandbox.ProcessStarter)
+	if !ok {
+		return nil, fmt.Errorf("sandbox does not support long-lived processes")
+	}
+	command, ok := kernelCommands[language]
+	if !ok {
+		return nil, fmt.Errorf("unsupported language %q", language)
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(buf)
+
+	proc, err := starter.StartProcess(ctx, command, cwd, sandbox.WithEnv(map[string]string{"GOCLAW_KERNEL_TOKEN": token}))
+	if err != nil {
+		return nil, err
+	}
+	k := &interpreterKernel{
+		language:  language,
+		sb:        sb,
+		proc:      proc,
+		cwd:       cwd,
+		token:     token,
+		responses: make(chan kernelResponse, 4),
+		done:      make(chan struct{}),
+		lastUsed:  time.Now(),
+	}
+	go k.readStdout(proc.Stdout)
+	go k.readStderr(proc.Stderr)
+
+	timer := time.NewTimer(kernelStartTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case resp := <-k.responses:
+			if !resp.Ready {
+				continue
+			}
+			k.pid, k.version = resp.PID, resp.Version
+			return k, nil
+		case <-k.done:
+			k.kill()
+			return nil, fmt.Errorf("%s kernel failed to start: %s", language, k.startupOutput())
+		case <-timer.C:
+			k.kill()
+			return nil, fmt.Errorf("%s kernel did not start within %s", language, kernelStartTimeout)
+		case <-ctx.Done():
+			k.kill()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// readStdout splits driver stdout into protocol responses and stray output.
+func (k *interpreterKernel) readStdout(r io.Reader) {
+	defer close(k.done)
+	prefix := "\x1e" + k.token
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			var resp kernelResponse
+			if err := json.Unmarshal([]byte(rest), &resp); err == nil {
+				// Never block: after close nobody reads, and the reader must
+				// keep draining so the driver can exit.
+				select {
+				case k.responses <- resp:
+				default:
+					slog.Debug("code_interpreter: dropped unclaimed kernel response", "language", k.language)
+				}
+				continue
+			}
+		}
+		k.mu.Lock()
+		if k.stray.Len() < kernelStrayMax {
+			k.stray.WriteString(line)
+			k.stray.WriteByte('\n')
+		}
+		k.mu.Unlock()
+	}
+}
+
+// readStderr keeps the start of process-level stderr (interpreter crashes,
+// import-time warnings); cell stderr is captured by the driver.
+func (k *interpreterKernel) readStderr(r io.Reader) {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		k.mu.Lock()
+		if k.stderr.Len() < kernelStderrMax {
+			k.stderr.WriteString(sc.Text())
+			k.stderr.WriteByte('\n')
+		}
+		k.mu.Unlock()
+	}
+}
+
+func (k *interpreterKernel) startupOutput() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	out := strings.TrimSpace(k.stderr.String() + k.stray.String())
+	if out == "" {
+		return "no output"
+	}
+	return out
+}
+
+func (k *interpreterKernel) takeStray() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	s := k.stray.String()
+	k.stray.Reset()
+	return s
+}
+
+func (k *interpreterKernel) alive() bool {
+	select {
+	case <-k.done:
+		return false
+	default:
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return !k.closed
+}
+
+func (k *interpreterKernel) idleSince() time.Time {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.lastUsed
+}
+
+// execute runs one cell. On timeout or cancellation the cell is interrupted
+// (SIGINT); a kernel that ignores the interrupt is killed and errKernelTimedOut
+// returned. errKernelDied means the process exited mid-cell.
+func (k *interpreterKernel) execute(ctx context.Context, req kernelRequest, timeout time.Duration) (kernelResponse, error) {
+	k.run.Lock()
+	defer k.run.Unlock()
+	k.touch()
+	defer k.touch()
+
+	k.nextID++
+	req.ID = k.nextID
+	line, err := json.Marshal(req)
+	if err != nil {
+		return kernelResponse{}, err
+	}
+	stray := k.takeStray()
+	if _, err := k.proc.Stdin.Write(append(line, '\n')); err != nil {
+		return kernelResponse{}, errKernelDied
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	interrupted := false
+	for {
+		select {
+		case resp := <-k.responses:
+			if resp.ID == nil || *resp.ID != req.ID {
+				continue // late reply to an interrupted cell
+			}
+			resp.Stray = stray
+			if interrupted && resp.Interrupted {
+				return resp, errKernelTimedOut
+			}
+			return resp, nil
+		case <-k.done:
+			return kernelResponse{Stray: stray}, errKernelDied
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+		if interrupted {
+			// The interrupt was ignored (e.g. a blocking C call).
+			k.kill()
+			return kernelResponse{Stray: stray}, errKernelTimedOut
+		}
+		interrupted = true
+		k.signal("INT")
+		timer.Reset(kernelInterruptGrace)
+	}
+}
+
+func (k *interpreterKernel) touch() {
+	k.mu.Lock()
+	k.lastUsed = time.Now()
+	k.mu.Unlock()
+	k.proc.Touch()
+}
+
+// signal sends a signal to the driver by PID. The docker exec client does
+// not forward signals, so it goes through a separate exec in the sandbox.
+func (k *interpreterKernel) signal(sig string) {
+	if k.pid <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := k.sb.Exec(ctx, []string{"kill", "-" + sig, strconv.Itoa(k.pid)}, ""); err != nil {
+		slog.Debug("code_interpreter: signal kernel failed", "signal", sig, "pid", k.pid, "error", err)
+	}
+}
+
+// close asks the driver to exit by closing stdin, killing it if it does not.
+func (k *interpreterKernel) close() {
+	k.mu.Lock()
+	if k.closed {
+		k.mu.Unlock()
+		return
+	}
+	k.closed = true
+	k.mu.Unlock()
+	k.proc.Stdin.Close()
+	go func() {
+		select {
+		case <-k.done:
+		case <-time.After(kernelCloseGrace):
+			k.signal("KILL")
+		}
+		k.proc.Kill()
+		k.proc.Wait()
+	}()
+}
+
+// kill stops the driver immediately.
+func (k *interpreterKernel) kill() {
+	k.mu.Lock()
+	k.closed = true
+	k.mu.Unlock()
+	k.signal("KILL")
+	k.proc.Kill()
+	go k.proc.Wait()
+}
+
+// kernelPool holds the kernels of all sessions, keyed by sandbox key and
+// language, and closes idle ones.
+type kernelPool struct {
+	mu      sync.Mutex
+	kernels map[string]*interpreterKernel
+	// notes explains why a session's previous kernel is gone; shown once
+	// with the first result of its replacement.
+	notes   map[string]string
+	janitor sync.Once
+	tick    time.Duration
+}
+
+func newKernelPool() *kernelPool {
+	return &kernelPool{
+		kernels: make(map[string]*interpreterKernel),
+		notes:   make(map[string]string),
+		tick:    time.Minute,
+	}
+}
+
+func kernelKey(sandboxKey, language string) string {
+	return sandboxKey + "\x00" + language
+}
+
+// get returns the live kernel for key in sb, starting one if needed. note is
+// non-empty when a previous kernel's state was lost.
+func (p *kernelPool) get(ctx context.Context, key string, sb sandbox.Sandbox, language, cwd string, idle time.Duration) (k *interpreterKernel, note string, err error) {
+	p.janitor.Do(func() { go p.reapIdle() })
+
+	p.mu.Lock()
+	k = p.kernels[key]
+	if k != nil && (!k.alive() || k.sb.ID() != sb.ID() || k.cwd != cwd) {
+		if k.alive() {
+			p.notes[key] = "the sandbox or workspace changed, so the kernel was restarted"
+		} else if p.notes[key] == "" {
+			p.notes[key] = "the kernel exited, so it was restarted"
+		}
+		delete(p.kernels, key)
+		k.close()
+		k = nil
+	}
+	p.mu.Unlock()
+	if k != nil {
+		k.mu.Lock()
+		k.idleTimeout = idle
+		k.mu.Unlock()
+		return k, "", nil
+	}
+
+	// Start outside the lock: startup can take seconds.
+	k, err = startKernel(ctx, sb, language, cwd)
+	if err != nil {
+		return nil, "", err
+	}
+	k.idleTimeout = idle
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if existing := p.kernels[key]; existing != nil && existing.alive() {
+		// A concurrent call won the race; use its kernel.
+		k.close()
+		return existing, "", nil
+	}
+	p.kernels[key] = k
+	note = p.notes[key]
+	delete(p.notes, key)
+	return k, note, nil
+}
+
+// drop closes and forgets the kernel for key, recording why.
+func (p *kernelPool) drop(key, note string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k := p.kernels[key]
+	if k == nil {
+		return false
+	}
+	delete(p.kernels, key)
+	if note != "" {
+		p.notes[key] = note
+	}
+	k.close()
+	return true
+}
+
+func (p *kernelPool) reapIdle() {
+	ticker := time.NewTicker(p.tick)
+	defer ticker.Stop()
+	for range ticker.C {
+		p.closeIdle(time.Now())
+	}
+}
+
+// closeIdle closes kernels unused for longer than their idle timeout.
+// Kernels running a cell are skipped.
+func (p *kernelPool) closeIdle(now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, k := range p.kernels {
+		k.mu.Lock()
+		idle := k.idleTimeout
+		k.mu.Unlock()
+		if idle <= 0 || now.Sub(k.idleSince()) < idle {
+			continue
+		}
+		if !k.run.TryLock() {
+			continue
+		}
+		k.run.Unlock()
+		slog.Debug("code_interpreter: closing idle kernel", "language", k.language, "idle", idle)
+		delete(p.kernels, key)
+		p.notes[key] = fmt.Sprintf("the kernel was idle for over %s and was shut down", idle)
+		k.close()
+	}
+}
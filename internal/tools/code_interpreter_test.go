@@ -0,0 +1,227 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/sandbox"
+)
+
+// localProcessSandbox runs kernels as host processes, standing in for a
+// container so the real drivers can be exercised.
+type localProcessSandbox struct {
+	id string
+}
+
+func (s *localProcessSandbox) Exec(ctx context.Context, command []string, workDir string, opts ...sandbox.ExecOption) (*sandbox.ExecResult, error) {
+	out, err := exec.CommandContext(ctx, command[0], command[1:]...).CombinedOutput()
+	res := &sandbox.ExecResult{Stdout: string(out)}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		res.ExitCode = exitErr.ExitCode()
+	}
+	return res, nil
+}
+
+func (s *localProcessSandbox) StartProcess(ctx context.Context, command []string, workDir string, opts ...sandbox.ExecOption) (*sandbox.Process, error) {
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Env = os.Environ()
+	for k, v := range sandbox.ApplyExecOpts(opts).Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	return sandbox.StartProcess(cmd, nil)
+}
+
+func (s *localProcessSandbox) Destroy(context.Context) error { return nil }
+func (s *localProcessSandbox) ID() string                    { return s.id }
+
+type localProcessManager struct{ sb *localProcessSandbox }
+
+func (m *localProcessManager) Get(context.Context, string, string, *sandbox.Config) (sandbox.Sandbox, error) {
+	return m.sb, nil
+}
+func (m *localProcessManager) Release(context.Context, string) error { return nil }
+func (m *localProcessManager) ReleaseAll(context.Context) error      { return nil }
+func (m *localProcessManager) Stop()                                 {}
+func (m *localProcessManager) Stats() map[string]any                 { return nil }
+
+func newTestInterpreter(t *testing.T, binary string) (*CodeInterpreterTool, context.Context) {
+	t.Helper()
+	if _, err := exec.LookPath(binary); err != nil {
+		t.Skipf("%s not available", binary)
+	}
+	tool := NewCodeInterpreterTool("", &localProcessManager{sb: &localProcessSandbox{id: "local"}})
+	t.Cleanup(func() {
+		for key := range tool.kernels.kernels {
+			tool.kernels.drop(key, "")
+		}
+	})
+	ctx := WithToolWorkspace(context.Background(), t.TempDir())
+	ctx = WithToolSandboxKey(ctx, "agent:test:session")
+	return tool, ctx
+}
+
+func runCell(t *testing.T, tool *CodeInterpreterTool, ctx context.Context, args map[string]any) *Result {
+	t.Helper()
+	res := tool.Execute(ctx, args)
+	if res == nil {
+		t.Fatal("nil result")
+	}
+	return res
+}
+
+func TestCodeInterpreterPythonKeepsState(t *testing.T) {
+	tool, ctx := newTestInterpreter(t, "python3")
+
+	res := runCell(t, tool, ctx, map[string]any{"code": "x = 21\nprint('hello')"})
+	if res.IsError || res.ForLLM != "hello" {
+		t.Fatalf("first cell = %+v", res)
+	}
+	res = runCell(t, tool, ctx, map[string]any{"code": "import sys\nprint('warn', file=sys.stderr)\nx * 2"})
+	if res.IsError || res.ForLLM != "STDERR:\nwarn\n\nOut:\n42" {
+		t.Fatalf("second cell = %q", res.ForLLM)
+	}
+	res = runCell(t, tool, ctx, map[string]any{"code": "_ + 1"})
+	if !strings.Contains(res.ForLLM, "43") {
+		t.Fatalf("_ not kept: %q", res.ForLLM)
+	}
+}
+
+func TestCodeInterpreterPythonErrorKeepsKernel(t *testing.T) {
+	tool, ctx := newTestInterpreter(t, "python3")
+
+	runCell(t, tool, ctx, map[string]any{"code": "y = 5"})
+	res := runCell(t, tool, ctx, map[string]any{"code": "def f():\n    return 1 / 0\nf()"})
+	if !res.IsError || !strings.Contains(res.ForLLM, "ZeroDivisionError") {
+		t.Fatalf("error cell = %+v", res)
+	}
+	if strings.Contains(res.ForLLM, "<string>") {
+		t.Fatalf("traceback includes driver frames: %q", res.ForLLM)
+	}
+	res = runCell(t, tool, ctx, map[string]any{"code": "y"})
+	if res.IsError || res.ForLLM != "Out:\n5" {
+		t.Fatalf("state lost after error: %q", res.ForLLM)
+	}
+}
+
+func TestCodeInterpreterTimeoutInterruptsCell(t *testing.T) {
+	tool, ctx := newTestInterpreter(t, "python3")
+
+	runCell(t, tool, ctx, map[string]any{"code": "z = 'kept'"})
+	start := time.Now()
+	res := runCell(t, tool, ctx, map[string]any{"code": "import time\ntime.sleep(30)", "timeout": 1.0})
+	if !res.IsError || !strings.Contains(res.ForLLM, "interrupted") {
+		t.Fatalf("timeout cell = %+v", res)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Fatalf("interrupt took %s", time.Since(start))
+	}
+	res = runCell(t, tool, ctx, map[string]any{"code": "z"})
+	if res.IsError || res.ForLLM != "Out:\n'kept'" {
+		t.Fatalf("state lost after interrupt: %q", res.ForLLM)
+	}
+}
+
+func TestCodeInterpreterCrashRestartsKernel(t *testing.T) {
+	tool, ctx := newTestInterpreter(t, "python3")
+
+	runCell(t, tool, ctx, map[string]any{"code": "w = 1"})
+	res := runCell(t, tool, ctx, map[string]any{"code": "import os\nos._exit(3)"})
+	if !res.IsError || !strings.Contains(res.ForLLM, "state was lost") {
+		t.Fatalf("crash cell = %+v", res)
+	}
+	res = runCell(t, tool, ctx, map[string]any{"code": "'w' in globals()"})
+	if !strings.Contains(res.ForLLM, "[new kernel: the previous kernel crashed]") || !strings.Contains(res.ForLLM, "False") {
+		t.Fatalf("after crash = %q", res.ForLLM)
+	}
+}
+
+func TestCodeInterpreterRestartClearsState(t *testing.T) {
+	tool, ctx := newTestInterpreter(t, "python3")
+
+	runCell(t, tool, ctx, map[string]any{"code": "v = 1"})
+	res := runCell(t, tool, ctx, map[string]any{"action": "restart"})
+	if res.IsError || !strings.Contains(res.ForLLM, "restarted") {
+		t.Fatalf("restart = %+v", res)
+	}
+	res = runCell(t, tool, ctx, map[string]any{"code": "v"})
+	if !res.IsError || !strings.Contains(res.ForLLM, "NameError") {
+		t.Fatalf("state survived restart: %q", res.ForLLM)
+	}
+}
+
+func TestCodeInterpreterJavaScript(t *testing.T) {
+	tool, ctx := newTestInterpreter(t, "node")
+
+	res := runCell(t, tool, ctx, map[string]any{"language": "javascript", "code": "var n = 20; console.log('hi')"})
+	if res.IsError || res.ForLLM != "hi" {
+		t.Fatalf("first cell = %+v", res)
+	}
+	res = runCell(t, tool, ctx, map[string]any{"language": "javascript", "code": "Promise.resolve(n + 1)"})
+	if res.IsError || res.ForLLM != "Out:\n21" {
+		t.Fatalf("second cell = %q", res.ForLLM)
+	}
+}
+
+func TestCodeInterpreterRequiresSandbox(t *testing.T) {
+	tool := NewCodeInterpreterTool("", &localProcessManager{sb: &localProcessSandbox{id: "local"}})
+	res := tool.Execute(context.Background(), map[string]any{"code": "1"})
+	if !res.IsError || !strings.Contains(res.ForLLM, "sandboxed session") {
+		t.Fatalf("result = %+v", res)
+	}
+}
+
+func TestKernelPoolClosesIdleKernels(t *testing.T) {
+	tool, ctx := newTestInterpreter(t, "python3")
+
+	runCell(t, tool, ctx, map[string]any{"code": "a = 1"})
+	tool.kernels.closeIdle(time.Now().Add(codeInterpreterDefaultIdle + time.Minute))
+	if n := len(tool.kernels.kernels); n != 0 {
+		t.Fatalf("%d kernels left after idle close", n)
+	}
+	res := runCell(t, tool, ctx, map[string]any{"code": "'a' in globals()"})
+	if !strings.Contains(res.ForLLM, "idle for over 30m0s") || !strings.Contains(res.ForLLM, "False") {
+		t.Fatalf("after idle close = %q", res.ForLLM)
+	}
+}
+
+func TestCodeInterpreterHostFiles(t *testing.T) {
+	ws := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(ws, "interpreter"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ws, "interpreter", "cell-1-figure-1.png"), []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Cell code can plant symlinks to host files, directly or via a directory.
+	secret := filepath.Join(t.TempDir(), "shadow")
+	if err := os.WriteFile(secret, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secret, filepath.Join(ws, "out.png")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Dir(secret), filepath.Join(ws, "host")); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewCodeInterpreterTool(ws, nil)
+	files := tool.hostFiles([]string{
+		"/workspace/interpreter/cell-1-figure-1.png",
+		"/workspace/interpreter/missing.png",
+		"/tmp/elsewhere.png",
+		"/workspace/out.png",
+		"/workspace/host/shadow",
+	}, "/workspace", ws)
+	if len(files) != 1 || files[0].rel != "interpreter/cell-1-figure-1.png" {
+		t.Fatalf("files = %+v", files)
+	}
+
+	out := formatInterpreterOutput("", kernelResponse{Result: "1"}, files)
+	if out != "Out:\n1\n\nFiles:\n- interpreter/cell-1-figure-1.png" {
+		t.Fatalf("output = %q", out)
+	}
+}
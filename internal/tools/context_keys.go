@@ -376,6 +376,22 @@ func WaitToolConfigFromCtx(ctx context.Context) *config.WaitToolPolicy {
 	return nil
 }
 
+const ctxHTTPRequestToolCfg toolContextKey = "tool_http_request_config"
+
+func WithHTTPRequestToolConfig(ctx context.Context, cfg *config.HTTPRequestToolPolicy) context.Context {
+	return context.WithValue(ctx, ctxHTTPRequestToolCfg, cfg)
+}
+
+func HTTPRequestToolConfigFromCtx(ctx context.Context) *config.HTTPRequestToolPolicy {
+	if v, _ := ctx.Value(ctxHTTPRequestToolCfg).(*config.HTTPRequestToolPolicy); v != nil {
+		return v
+	}
+	if rc := store.RunContextFromCtx(ctx); rc != nil {
+		return rc.HTTPRequestToolCfg
+	}
+	return nil
+}
+
 // --- Team ID propagation (task dispatch → workspace tools) ---
 
 const ctxTeamID toolContextKey = "tool_team_id"
@@ -53,12 +53,9 @@ func (psqlAdapter) Prepare(_ context.Context, _ *store.SecureCLIBinary, cred *st
 		return &Injection{}, nil
 	}
 
-	var pg pgPasswordFile
-	if err := json.Unmarshal(cred.EncryptedEnv, &pg); err != nil {
-		return nil, fmt.Errorf("decode pg credential: %w", err)
-	}
-	if pg.Password == "" {
-		return nil, fmt.Errorf("pg credential missing password")
+	pg, err := decodePgCredential(cred.EncryptedEnv)
+	if err != nil {
+		return nil, err
 	}
 
 	line := fmt.Sprintf("%s:%s:%s:%s:%s\n",
@@ -83,6 +80,19 @@ func (psqlAdapter) Prepare(_ context.Context, _ *store.SecureCLIBinary, cred *st
 	}, nil
 }
 
+// decodePgCredential parses a pg_password_file credential. Shared with
+// sql_query, which connects with the same material instead of a pgpass file.
+func decodePgCredential(raw []byte) (*pgPasswordFile, error) {
+	var pg pgPasswordFile
+	if err := json.Unmarshal(raw, &pg); err != nil {
+		return nil, fmt.Errorf("decode pg credential: %w", err)
+	}
+	if pg.Password == "" {
+		return nil, fmt.Errorf("pg credential missing password")
+	}
+	return &pg, nil
+}
+
 // escapePgpass escapes backslash and colon per the libpq .pgpass spec so a
 // malicious password containing `:` cannot break the line format and inject a
 // second entry.
@@ -19,6 +19,18 @@ type CLIPreset struct {
 	// adapter lookup reads the DB column, not this field — so operator
 	// overrides post-create take precedence.
 	AdapterName string `json:"adapter_name,omitempty"`
+	// HTTP lets the http_request tool use this credential as an auth
+	// profile. Nil → only the generic HTTP_* env conventions apply.
+	HTTP *HTTPAuthTemplate `json:"http,omitempty"`
+}
+
+// HTTPAuthTemplate maps a preset's env vars onto HTTP requests. Header and
+// query values may reference env vars as {NAME}.
+type HTTPAuthTemplate struct {
+	// Hosts the credential may be sent to ("api.github.com", "*.rapidapi.com").
+	Hosts   []string          `json:"hosts"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Query   map[string]string `json:"query,omitempty"`
 }
 
 // EnvVarDef describes an environment variable required by a CLI tool.
@@ -39,6 +51,10 @@ var CLIPresets = map[string]CLIPreset{
 		DenyVerbose: []string{`--verbose`, `-v`},
 		Timeout:     30,
 		Tips:        "Use --json flag for structured output",
+		HTTP: &HTTPAuthTemplate{
+			Hosts:   []string{"api.github.com", "uploads.github.com"},
+			Headers: map[string]string{"Authorization": "Bearer {GH_TOKEN}"},
+		},
 	},
 	"gcloud": {
 		BinaryName:  "gcloud",
@@ -99,6 +115,10 @@ var CLIPresets = map[string]CLIPreset{
 		DenyVerbose: nil,
 		Timeout:     300,
 		Tips:        "Use -json flag for structured output",
+		HTTP: &HTTPAuthTemplate{
+			Hosts:   []string{"app.terraform.io"},
+			Headers: map[string]string{"Authorization": "Bearer {TF_TOKEN_app_terraform_io}"},
+		},
 	},
 	"git": {
 		BinaryName:  "git",
@@ -139,6 +159,36 @@ var CLIPresets = map[string]CLIPreset{
 		Tips:        "Use -A -t for plain output suitable for piping",
 		AdapterName: "psql",
 	},
+	"http-api": {
+		BinaryName:  "http-api",
+		Description: "REST API auth profile for the http_request tool (not a binary). Rename to the service, e.g. stripe",
+		EnvVars: []EnvVarDef{
+			{Name: "HTTP_ALLOWED_HOSTS", Desc: "Comma-separated hosts the credential may be sent to (api.example.com, *.example.com)"},
+			{Name: "HTTP_BEARER_TOKEN", Desc: "Sent as Authorization: Bearer <token>", Optional: true},
+			{Name: "HTTP_BASIC_USER", Desc: "HTTP basic auth user", Optional: true},
+			{Name: "HTTP_BASIC_PASSWORD", Desc: "HTTP basic auth password", Optional: true},
+		},
+		DenyArgs:    nil,
+		DenyVerbose: nil,
+		Timeout:     30,
+		Tips:        "Call with http_request auth=<name>. Extra HTTP_HEADER_<NAME> vars become headers (HTTP_HEADER_X_API_KEY → X-Api-Key) and HTTP_QUERY_<name> vars become query parameters.",
+	},
+	"postgres": {
+		BinaryName:  "postgres",
+		Description: "Postgres connection for the sql_query tool (not a binary). Rename to the database, e.g. warehouse",
+		EnvVars: []EnvVarDef{
+			{Name: "PGHOST", Desc: "Database host"},
+			{Name: "PGPORT", Desc: "Database port (default 5432)", Optional: true},
+			{Name: "PGDATABASE", Desc: "Database name"},
+			{Name: "PGUSER", Desc: "Database user; use a read-only role"},
+			{Name: "PGPASSWORD", Desc: "Database password"},
+			{Name: "PGSSLMODE", Desc: "disable, prefer (default), require, verify-full", Optional: true},
+		},
+		DenyArgs:    nil,
+		DenyVerbose: nil,
+		Timeout:     30,
+		Tips:        "Call with sql_query connection=<name>. Queries run in read-only transactions; a read-only database role is still recommended.",
+	},
 	"rapidapi": {
 		BinaryName:  "rapidapi",
 		Description: "RapidAPI CLI",
@@ -149,6 +199,10 @@ var CLIPresets = map[string]CLIPreset{
 		DenyVerbose: []string{`--verbose`, `--debug`, `-v`},
 		Timeout:     60,
 		Tips:        "Use read-only/search commands for scheduled jobs. Configure RAPIDAPI_KEY as a per-user credential and grant the target agent.",
+		HTTP: &HTTPAuthTemplate{
+			Hosts:   []string{"*.rapidapi.com"},
+			Headers: map[string]string{"X-RapidAPI-Key": "{RAPIDAPI_KEY}"},
+		},
 	},
 }
 
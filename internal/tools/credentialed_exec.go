@@ -509,6 +509,19 @@ func (t *ExecTool) executeCredentialed(ctx context.Context, cred *store.SecureCL
 	return t.executeCredentialedHost(ctx, absPath, args, cwd, envMap, timeout)
 }
 
+// lookupGrantedCredential loads a secure CLI credential by name for tools
+// that use credentials outside exec (http_request, sql_query). The agent
+// grant and per-user overlay rules are those of LookupByBinary; a nil result
+// means the credential does not exist or is not granted to the agent.
+func lookupGrantedCredential(ctx context.Context, s store.SecureCLIStore, name string) (*store.SecureCLIBinary, error) {
+	agentID := store.AgentIDFromContext(ctx)
+	var agentIDPtr *uuid.UUID
+	if agentID != uuid.Nil {
+		agentIDPtr = &agentID
+	}
+	return s.LookupByBinary(ctx, name, agentIDPtr, store.CredentialUserIDFromContext(ctx))
+}
+
 // userCredFromBinary synthesizes adapter credential input from LookupByBinary's
 // effective credential fields. The adapter contract still uses
 // SecureCLIUserCredential, but the material can come from user, context, or
@@ -1,12 +1,17 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
 // ExecSecurity determines the overall security mode for command execution.
@@ -87,13 +92,29 @@ const (
 
 // PendingApproval is an in-flight approval request.
 type PendingApproval struct {
-	ID        string    `json:"id"`
-	Command   string    `json:"command"`
-	AgentID   string    `json:"agentId"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID        string         `json:"id"`
+	Command   string         `json:"command"`
+	AgentID   string         `json:"agentId"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Origin    ApprovalOrigin `json:"origin"`
 	resultCh  chan ApprovalDecision
 }
 
+// ApprovalOrigin is the chat whose run asked for approval. Approval buttons
+// are posted there, and only SenderID may answer them from the same chat.
+type ApprovalOrigin struct {
+	Channel  string    `json:"channel,omitempty"`
+	ChatID   string    `json:"chatId,omitempty"`
+	SenderID string    `json:"senderId,omitempty"`
+	LocalKey string    `json:"localKey,omitempty"`
+	PeerKind string    `json:"peerKind,omitempty"`
+	TenantID uuid.UUID `json:"-"`
+}
+
+// ApprovalNotifier is called once per new approval request, outside the
+// manager lock. The gateway uses it to post approval buttons to the origin chat.
+type ApprovalNotifier func(pa *PendingApproval)
+
 // ExecApprovalManager manages pending approval requests and the dynamic allowlist.
 type ExecApprovalManager struct {
 	config       ExecApprovalConfig
@@ -101,6 +122,7 @@ type ExecApprovalManager struct {
 	alwaysAllow  map[string]bool // patterns added via "allow-always" decisions
 	mu           sync.Mutex
 	nextID       int
+	notifier     ApprovalNotifier
 }
 
 // NewExecApprovalManager creates an approval manager with the given config.
@@ -148,8 +170,16 @@ func (m *ExecApprovalManager) CheckCommand(command string) string {
 	return "allow"
 }
 
+// SetNotifier registers a callback for new approval requests.
+func (m *ExecApprovalManager) SetNotifier(fn ApprovalNotifier) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notifier = fn
+}
+
 // RequestApproval creates a pending approval and blocks until resolved or timeout.
-func (m *ExecApprovalManager) RequestApproval(command, agentID string, timeout time.Duration) (ApprovalDecision, error) {
+// The origin chat is taken from the tool context of the requesting run.
+func (m *ExecApprovalManager) RequestApproval(ctx context.Context, command, agentID string, timeout time.Duration) (ApprovalDecision, error) {
 	m.mu.Lock()
 	m.nextID++
 	id := fmt.Sprintf("exec-%d", m.nextID)
@@ -158,12 +188,24 @@ func (m *ExecApprovalManager) RequestApproval(command, agentID string, timeout t
 		Command:   command,
 		AgentID:   agentID,
 		CreatedAt: time.Now(),
-		resultCh:  make(chan ApprovalDecision, 1),
+		Origin: ApprovalOrigin{
+			Channel:  ToolChannelFromCtx(ctx),
+			ChatID:   ToolChatIDFromCtx(ctx),
+			SenderID: store.SenderIDFromContext(ctx),
+			LocalKey: ToolLocalKeyFromCtx(ctx),
+			PeerKind: ToolPeerKindFromCtx(ctx),
+			TenantID: store.TenantIDFromContext(ctx),
+		},
+		resultCh: make(chan ApprovalDecision, 1),
 	}
 	m.pending[id] = pa
+	notify := m.notifier
 	m.mu.Unlock()
 
 	slog.Info("exec approval requested", "id", id, "command", truncateCmd(command, 100))
+	if notify != nil {
+		notify(pa)
+	}
 
 	// Wait for resolution or timeout
 	select {
@@ -207,6 +249,14 @@ func (m *ExecApprovalManager) Resolve(id string, decision ApprovalDecision) erro
 	return nil
 }
 
+// Get returns a pending approval request by ID.
+func (m *ExecApprovalManager) Get(id string) (*PendingApproval, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pa, ok := m.pending[id]
+	return pa, ok
+}
+
 // ListPending returns all pending approval requests.
 func (m *ExecApprovalManager) ListPending() []*PendingApproval {
 	m.mu.Lock()
@@ -0,0 +1,544 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nextlevelbuilder/goclaw/internal/security"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	httpRequestDefaultTimeout = 30 * time.Second
+	httpRequestMaxTimeout     = 120 * time.Second
+	httpRequestMaxBodyBytes   = 2 << 20
+	httpRequestMaxChars       = 30000
+	httpRequestMaxRedirects   = 5
+	httpRequestDefaultPages   = 5
+	httpRequestMaxPages       = 20
+	httpRequestUserAgent      = "goclaw-http-request/1.0"
+)
+
+var httpRequestMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"}
+
+// httpResponseHeaders are the response headers shown to the model. Others
+// (cookies, server banners) are noise or sensitive.
+var httpResponseHeaders = []string{
+	"Content-Type", "Location", "Link", "Retry-After", "ETag",
+	"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset",
+	"X-Total-Count", "X-Next-Page",
+}
+
+// HTTPRequestTool calls REST APIs with any method, headers and body. Auth
+// comes from named profiles in the secure CLI credential store: secrets are
+// added server-side, never seen by the model and scrubbed from results.
+// Every hop goes through security.NewSafeClient (SSRF-safe, pinned DNS).
+type HTTPRequestTool struct {
+	client         *http.Client
+	secureCLIStore store.SecureCLIStore // nil = no auth profiles
+}
+
+func NewHTTPRequestTool() *HTTPRequestTool {
+	return &HTTPRequestTool{client: security.NewSafeClient(httpRequestMaxTimeout)}
+}
+
+// SetSecureCLIStore enables auth profiles backed by secure CLI credentials.
+func (t *HTTPRequestTool) SetSecureCLIStore(s store.SecureCLIStore) {
+	t.secureCLIStore = s
+}
+
+func (t *HTTPRequestTool) Name() string { return "http_request" }
+
+func (t *HTTPRequestTool) Description() string {
+	return "Call an HTTP/REST API with any method, headers, query and JSON body. " +
+		"For authenticated APIs pass auth=<profile name>; credentials are injected server-side and never appear in the result — " +
+		"do NOT put tokens in headers or use curl in exec. Supports pagination (Link header, cursor, page number). " +
+		"Use web_fetch instead to read web pages."
+}
+
+func (t *HTTPRequestTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"url": map[string]any{
+				"type":        "string",
+				"description": "Request URL (http or https)",
+			},
+			"method": map[string]any{
+				"type":        "string",
+				"enum":        httpRequestMethods,
+				"description": "HTTP method (default GET)",
+			},
+			"headers": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+				"description":          "Extra request headers",
+			},
+			"query": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+				"description":          "Query parameters merged into the URL",
+			},
+			"json": map[string]any{
+				"description": "JSON request body (sets Content-Type: application/json)",
+			},
+			"body": map[string]any{
+				"type":        "string",
+				"description": "Raw request body; use json for JSON payloads",
+			},
+			"auth": map[string]any{
+				"type":        "string",
+				"description": "Auth profile name (a secure CLI credential granted to this agent, e.g. gh)",
+			},
+			"paginate": map[string]any{
+				"type":        "object",
+				"description": "Fetch several pages of a GET request",
+				"properties": map[string]any{
+					"mode": map[string]any{
+						"type":        "string",
+						"enum":        []string{"link", "cursor", "page"},
+						"description": "link: follow Link rel=next; cursor: read next cursor from the body; page: increment a page parameter",
+					},
+					"cursor_path":  map[string]any{"type": "string", "description": "cursor mode: dot path of the next cursor in the JSON body (e.g. meta.next_cursor)"},
+					"cursor_param": map[string]any{"type": "string", "description": "cursor mode: query parameter receiving the cursor (default cursor)"},
+					"page_param":   map[string]any{"type": "string", "description": "page mode: query parameter holding the page number (default page)"},
+					"items_path":   map[string]any{"type": "string", "description": "Dot path of the item array in each page (empty = the body itself). Items of all pages are returned as one array."},
+					"max_pages":    map[string]any{"type": "number", "description": "Maximum pages to fetch (default 5, max 20)"},
+				},
+			},
+			"timeout": map[string]any{
+				"type":        "number",
+				"description": "Per-request timeout in seconds (default 30, max 120)",
+			},
+		},
+		"required": []string{"url"},
+	}
+}
+
+// httpCall is one request to send; redirects and pages derive new calls.
+type httpCall struct {
+	method  string
+	url     *url.URL
+	headers http.Header
+	body    []byte
+}
+
+// httpReply is a received response with its body read.
+type httpReply struct {
+	method string
+	url    string
+	status int
+	header http.Header
+	body   []byte
+	capped bool
+}
+
+type httpPagination struct {
+	mode        string
+	cursorPath  string
+	cursorParam string
+	pageParam   string
+	itemsPath   string
+	maxPages    int
+}
+
+func (t *HTTPRequestTool) Execute(ctx context.Context, args map[string]any) *Result {
+	rawURL, _ := args["url"].(string)
+	if rawURL == "" {
+		return ErrorResult("url is required")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrorResult("url must be an absolute http or https URL")
+	}
+	method := "GET"
+	if m, _ := args["method"].(string); m != "" {
+		method = strings.ToUpper(m)
+	}
+	if !slices.Contains(httpRequestMethods, method) {
+		return ErrorResult(fmt.Sprintf("unsupported method %q", method))
+	}
+
+	call := httpCall{method: method, url: u, headers: http.Header{}}
+	if q, ok := args["query"].(map[string]any); ok {
+		values := u.Query()
+		for k, v := range q {
+			values.Set(k, fmt.Sprint(v))
+		}
+		u.RawQuery = values.Encode()
+	}
+	if h, ok := args["headers"].(map[string]any); ok {
+		for k, v := range h {
+			call.headers.Set(k, fmt.Sprint(v))
+		}
+	}
+	bodyStr, hasBody := args["body"].(string)
+	jsonBody, hasJSON := args["json"]
+	switch {
+	case hasJSON && hasBody:
+		return ErrorResult("set either json or body, not both")
+	case hasJSON && jsonBody != nil:
+		data, err := json.Marshal(jsonBody)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("invalid json body: %v", err))
+		}
+		call.body = data
+		if call.headers.Get("Content-Type") == "" {
+			call.headers.Set("Content-Type", "application/json")
+		}
+	case hasBody:
+		call.body = []byte(bodyStr)
+	}
+	if call.headers.Get("Accept") == "" {
+		call.headers.Set("Accept", "application/json, */*;q=0.5")
+	}
+
+	timeout := httpRequestDefaultTimeout
+	if v, ok := args["timeout"].(float64); ok && v > 0 {
+		timeout = min(time.Duration(v*float64(time.Second)), httpRequestMaxTimeout)
+	}
+
+	var pg *httpPagination
+	if p, ok := args["paginate"].(map[string]any); ok {
+		if pg, err = parseHTTPPagination(p); err != nil {
+			return ErrorResult(err.Error())
+		}
+		if method != "GET" {
+			return ErrorResult("paginate is only supported for GET requests")
+		}
+	}
+
+	// Secrets live in a per-request scrub bag so they cannot leak into
+	// other requests' output through shared state.
+	ctx = WithScrubBag(ctx)
+	var profile *httpAuthProfile
+	if name, _ := args["auth"].(string); name != "" {
+		if profile, err = t.resolveAuthProfile(ctx, name); err != nil {
+			return ErrorResult(err.Error())
+		}
+		if !profile.appliesTo(u.Hostname()) {
+			return ErrorResult(fmt.Sprintf("auth profile %q may only be used with %s, not %s",
+				profile.name, strings.Join(profile.hosts, ", "), u.Hostname()))
+		}
+		AddScrubValuesCtx(ctx, profile.secrets...)
+	}
+
+	var out string
+	var failed bool
+	if pg == nil {
+		reply, err := t.send(ctx, call, profile, timeout)
+		if err != nil {
+			return ErrorResult(ScrubCredentialsCtx(ctx, fmt.Sprintf("request failed: %v", err)))
+		}
+		out = formatHTTPReply(reply)
+		failed = reply.status >= 400
+	} else {
+		out, failed, err = t.paginate(ctx, call, profile, timeout, pg)
+		if err != nil {
+			return ErrorResult(ScrubCredentialsCtx(ctx, err.Error()))
+		}
+	}
+
+	out = wrapExternalContent(truncateHTTPOutput(out), "HTTP Request", true)
+	out = ScrubCredentialsCtx(ctx, out)
+	if failed {
+		return ErrorResult(out)
+	}
+	return NewResult(out)
+}
+
+// checkHTTPHost applies the agent's host allowlist (per-agent tool policy).
+func checkHTTPHost(ctx context.Context, host string) error {
+	cfg := HTTPRequestToolConfigFromCtx(ctx)
+	if cfg == nil || len(cfg.AllowedHosts) == 0 {
+		return nil
+	}
+	if !matchDomainList(host, cfg.AllowedHosts) {
+		return fmt.Errorf("host %q is not in this agent's http_request allowlist", host)
+	}
+	return nil
+}
+
+// send performs call, following redirects. Every hop is checked against the
+// host allowlist and SSRF rules; auth is attached only on hops to the
+// profile's hosts, so a redirect elsewhere never carries the credential.
+func (t *HTTPRequestTool) send(ctx context.Context, call httpCall, profile *httpAuthProfile, timeout time.Duration) (*httpReply, error) {
+	for hop := 0; ; hop++ {
+		host := call.url.Hostname()
+		if err := checkHTTPHost(ctx, host); err != nil {
+			return nil, err
+		}
+		_, ip, err := security.Validate(call.url.String())
+		if err != nil {
+			return nil, err
+		}
+		reqCtx, cancel := context.WithTimeout(security.WithPinnedIP(ctx, ip), timeout)
+		var body io.Reader
+		if call.body != nil {
+			body = bytes.NewReader(call.body)
+		}
+		req, err := http.NewRequestWithContext(reqCtx, call.method, call.url.String(), body)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		req.Header = call.headers.Clone()
+		req.Header.Set("User-Agent", httpRequestUserAgent)
+		if profile != nil && profile.appliesTo(host) {
+			profile.apply(req)
+		}
+
+		resp, err := t.client.Do(req)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, httpRequestMaxBodyBytes+1))
+		resp.Body.Close()
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("read response: %w", err)
+		}
+		reply := &httpReply{
+			method: call.method,
+			url:    call.url.String(),
+			status: resp.StatusCode,
+			header: resp.Header,
+			body:   data,
+		}
+		if len(data) > httpRequestMaxBodyBytes {
+			reply.body, reply.capped = data[:httpRequestMaxBodyBytes], true
+		}
+
+		loc := resp.Header.Get("Location")
+		if !isHTTPRedirect(resp.StatusCode) || loc == "" || hop >= httpRequestMaxRedirects {
+			return reply, nil
+		}
+		next, err := call.url.Parse(loc)
+		if err != nil || (next.Scheme != "http" && next.Scheme != "https") {
+			return reply, nil
+		}
+		call.url = next
+		if resp.StatusCode == http.StatusSeeOther ||
+			(call.method == "POST" && (resp.StatusCode == http.StatusMovedPermanently || resp.StatusCode == http.StatusFound)) {
+			call.method, call.body = "GET", nil
+			call.headers = call.headers.Clone()
+			call.headers.Del("Content-Type")
+		}
+	}
+}
+
+func isHTTPRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+func parseHTTPPagination(p map[string]any) (*httpPagination, error) {
+	pg := &httpPagination{maxPages: httpRequestDefaultPages, cursorParam: "cursor", pageParam: "page"}
+	pg.mode, _ = p["mode"].(string)
+	if s, _ := p["cursor_path"].(string); s != "" {
+		pg.cursorPath = s
+	}
+	if s, _ := p["cursor_param"].(string); s != "" {
+		pg.cursorParam = s
+	}
+	if s, _ := p["page_param"].(string); s != "" {
+		pg.pageParam = s
+	}
+	pg.itemsPath, _ = p["items_path"].(string)
+	if v, ok := p["max_pages"].(float64); ok && v >= 1 {
+		pg.maxPages = min(int(v), httpRequestMaxPages)
+	}
+	switch pg.mode {
+	case "link", "page":
+	case "cursor":
+		if pg.cursorPath == "" {
+			return nil, fmt.Errorf("paginate.cursor_path is required for cursor mode")
+		}
+	default:
+		return nil, fmt.Errorf("paginate.mode must be link, cursor or page")
+	}
+	return pg, nil
+}
+
+// paginate fetches up to pg.maxPages pages. With items_path (or a body that
+// is an array) the items are merged into one JSON array; otherwise each page
+// is shown in turn. It stops at the first non-2xx page.
+func (t *HTTPRequestTool) paginate(ctx context.Context, call httpCall, profile *httpAuthProfile, timeout time.Duration, pg *httpPagination) (string, bool, error) {
+	var pages []string
+	var items []any
+	merge := true
+	page := 1
+	if pg.mode == "page" {
+		if n, err := strconv.Atoi(call.url.Query().Get(pg.pageParam)); err == nil {
+			page = n
+		}
+	}
+	fetched, more := 0, false
+	for fetched < pg.maxPages {
+		reply, err := t.send(ctx, call, profile, timeout)
+		if err != nil {
+			if fetched == 0 {
+				return "", false, fmt.Errorf("request failed: %v", err)
+			}
+			pages = append(pages, fmt.Sprintf("page %d failed: %v", fetched+1, err))
+			break
+		}
+		fetched++
+		if reply.status < 200 || reply.status >= 300 {
+			if fetched == 1 {
+				return formatHTTPReply(reply), true, nil
+			}
+			pages = append(pages, fmt.Sprintf("page %d: %s", fetched, formatHTTPReply(reply)))
+			break
+		}
+		pages = append(pages, fmt.Sprintf("--- page %d ---\n%s", fetched, formatHTTPReply(reply)))
+
+		var doc any
+		jsonErr := json.Unmarshal(reply.body, &doc)
+		pageItems, isList := []any(nil), false
+		if jsonErr == nil {
+			pageItems, isList = jsonLookup(doc, pg.itemsPath).([]any)
+		}
+		if !isList {
+			merge = false
+		}
+		items = append(items, pageItems...)
+
+		next := ""
+		switch pg.mode {
+		case "link":
+			next = nextLinkURL(reply.header.Values("Link"))
+		case "cursor":
+			if jsonErr == nil {
+				if c := jsonLookup(doc, pg.cursorPath); c != nil && fmt.Sprint(c) != "" && c != false {
+					q := call.url.Query()
+					q.Set(pg.cursorParam, fmt.Sprint(c))
+					u := *call.url
+					u.RawQuery = q.Encode()
+					next = u.String()
+				}
+			}
+		case "page":
+			if isList && len(pageItems) > 0 {
+				page++
+				q := call.url.Query()
+				q.Set(pg.pageParam, strconv.Itoa(page))
+				u := *call.url
+				u.RawQuery = q.Encode()
+				next = u.String()
+			}
+		}
+		if next == "" {
+			break
+		}
+		nu, err := call.url.Parse(next)
+		if err != nil {
+			break
+		}
+		call.url = nu
+		more = fetched == pg.maxPages
+	}
+
+	if merge && len(items) > 0 {
+		data, _ := json.MarshalIndent(items, "", "  ")
+		summary := fmt.Sprintf("Collected %d items from %d pages", len(items), fetched)
+		if more {
+			summary += " (max_pages reached; more pages are available)"
+		}
+		return summary + "\n\n" + string(data), false, nil
+	}
+	out := strings.Join(pages, "\n\n")
+	if more {
+		out += "\n\n(max_pages reached; more pages are available)"
+	}
+	return out, false, nil
+}
+
+// jsonLookup resolves a dot path ("data.items", "results.0.id"). An empty
+// path returns v itself.
+func jsonLookup(v any, path string) any {
+	if path == "" {
+		return v
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			v = node[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			v = node[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+var linkNextRe = regexp.MustCompile(`<([^>]+)>\s*;[^,]*\brel="?next"?`)
+
+// nextLinkURL returns the rel="next" target of RFC 8288 Link headers.
+func nextLinkURL(values []string) string {
+	for _, v := range values {
+		if m := linkNextRe.FindStringSubmatch(v); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// formatHTTPReply renders the status line, useful headers and the body.
+// JSON is pretty-printed; binary bodies are summarized.
+func formatHTTPReply(r *httpReply) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "HTTP %d %s\n%s %s\n", r.status, http.StatusText(r.status), r.method, r.url)
+	for _, h := range httpResponseHeaders {
+		if v := r.header.Get(h); v != "" {
+			fmt.Fprintf(&b, "%s: %s\n", h, v)
+		}
+	}
+	if len(r.body) == 0 {
+		return strings.TrimRight(b.String(), "\n")
+	}
+	b.WriteByte('\n')
+	var pretty bytes.Buffer
+	switch {
+	case json.Valid(r.body) && json.Indent(&pretty, r.body, "", "  ") == nil:
+		b.Write(pretty.Bytes())
+	case utf8.Valid(r.body):
+		b.Write(r.body)
+	default:
+		fmt.Fprintf(&b, "[binary body: %d bytes, %s]", len(r.body), r.header.Get("Content-Type"))
+	}
+	if r.capped {
+		fmt.Fprintf(&b, "\n[body truncated at %d bytes]", httpRequestMaxBodyBytes)
+	}
+	return b.String()
+}
+
+func truncateHTTPOutput(s string) string {
+	if utf8.RuneCountInString(s) <= httpRequestMaxChars {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:httpRequestMaxChars]) +
+		fmt.Sprintf("\n\n[Output truncated: %d chars total. Narrow the request with query parameters or paginate with smaller pages.]", len(runes))
+}
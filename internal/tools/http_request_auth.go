@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// Generic env conventions for http_request auth profiles. They apply to any
+// secure CLI credential, with or without a preset HTTP template.
+const (
+	httpEnvAllowedHosts  = "HTTP_ALLOWED_HOSTS"
+	httpEnvBearerToken   = "HTTP_BEARER_TOKEN"
+	httpEnvBasicUser     = "HTTP_BASIC_USER"
+	httpEnvBasicPassword = "HTTP_BASIC_PASSWORD"
+	httpEnvHeaderPrefix  = "HTTP_HEADER_"
+	httpEnvQueryPrefix   = "HTTP_QUERY_"
+)
+
+var httpTemplateVarRe = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// httpAuthProfile is a credential resolved for http_request. It is only
+// attached to requests whose host matches hosts.
+type httpAuthProfile struct {
+	name    string
+	hosts   []string
+	headers http.Header
+	query   url.Values
+	secrets []string // values to scrub from output
+}
+
+// appliesTo reports whether the profile may be sent to host.
+func (p *httpAuthProfile) appliesTo(host string) bool {
+	return matchDomainList(host, p.hosts)
+}
+
+// apply sets the profile's headers and query parameters on req, replacing
+// any the agent supplied under the same names.
+func (p *httpAuthProfile) apply(req *http.Request) {
+	for name, values := range p.headers {
+		req.Header[name] = append([]string(nil), values...)
+	}
+	if len(p.query) > 0 {
+		q := req.URL.Query()
+		for name, values := range p.query {
+			q[name] = append([]string(nil), values...)
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+}
+
+// resolveAuthProfile loads a named secure CLI credential and turns it into
+// request auth. The same grant rules as credentialed exec apply: the profile
+// must be global or granted to the calling agent.
+func (t *HTTPRequestTool) resolveAuthProfile(ctx context.Context, name string) (*httpAuthProfile, error) {
+	if t.secureCLIStore == nil {
+		return nil, fmt.Errorf("auth profiles are not available: no credential store configured")
+	}
+	name = normalizeBinaryName(name)
+	cred, err := lookupGrantedCredential(ctx, t.secureCLIStore, name)
+	if err != nil {
+		slog.Warn("http_request: auth profile lookup failed", "profile", name, "agent_id", store.AgentIDFromContext(ctx), "error", err)
+		return nil, fmt.Errorf("auth profile %q could not be loaded", name)
+	}
+	if cred == nil {
+		return nil, fmt.Errorf("auth profile %q not found or not granted to this agent", name)
+	}
+	env, err := mergeCredentialedEnv(cred)
+	if err != nil {
+		return nil, fmt.Errorf("auth profile %q: invalid env: %v", name, err)
+	}
+	if missing := missingRequiredCredentialEnv(name, env); len(missing) > 0 {
+		return nil, fmt.Errorf("auth profile %q is missing %s; configure them as a credential for this user or agent", name, strings.Join(missing, ", "))
+	}
+
+	p := &httpAuthProfile{name: name, headers: http.Header{}, query: url.Values{}}
+	if preset := GetPreset(name); preset != nil && preset.HTTP != nil {
+		p.hosts = append(p.hosts, preset.HTTP.Hosts...)
+		for header, tmpl := range preset.HTTP.Headers {
+			if v, ok := expandHTTPTemplate(tmpl, env); ok {
+				p.headers.Set(header, v)
+			}
+		}
+		for param, tmpl := range preset.HTTP.Query {
+			if v, ok := expandHTTPTemplate(tmpl, env); ok {
+				p.query.Set(param, v)
+			}
+		}
+	}
+	applyHTTPEnvConventions(p, env)
+	if scope := effectiveHostScope(cred); scope != nil && *scope != "" {
+		p.hosts = append(p.hosts, *scope)
+	}
+
+	if len(p.hosts) == 0 {
+		return nil, fmt.Errorf("auth profile %q is not bound to any host; set %s on the credential", name, httpEnvAllowedHosts)
+	}
+	if len(p.headers) == 0 && len(p.query) == 0 {
+		return nil, fmt.Errorf("auth profile %q has no HTTP credential; set %s, %s/%s or %s<NAME>",
+			name, httpEnvBearerToken, httpEnvBasicUser, httpEnvBasicPassword, httpEnvHeaderPrefix)
+	}
+
+	for key, v := range env {
+		if key != httpEnvAllowedHosts {
+			p.secrets = append(p.secrets, v)
+		}
+	}
+	for _, values := range p.headers {
+		p.secrets = append(p.secrets, values...)
+	}
+	for _, values := range p.query {
+		for _, v := range values {
+			p.secrets = append(p.secrets, url.QueryEscape(v)) // as it appears in URLs
+		}
+	}
+	return p, nil
+}
+
+// expandHTTPTemplate substitutes {NAME} references from env. It reports false
+// when a referenced variable is unset, so optional template entries are
+// skipped rather than sent half-filled.
+func expandHTTPTemplate(tmpl string, env map[string]string) (string, bool) {
+	ok := true
+	out := httpTemplateVarRe.ReplaceAllStringFunc(tmpl, func(m string) string {
+		v := env[m[1:len(m)-1]]
+		if v == "" {
+			ok = false
+		}
+		return v
+	})
+	return out, ok
+}
+
+func applyHTTPEnvConventions(p *httpAuthProfile, env map[string]string) {
+	if hosts := env[httpEnvAllowedHosts]; hosts != "" {
+		for _, h := range strings.Split(hosts, ",") {
+			if h = strings.TrimSpace(h); h != "" {
+				p.hosts = append(p.hosts, h)
+			}
+		}
+	}
+	if token := env[httpEnvBearerToken]; token != "" {
+		p.headers.Set("Authorization", "Bearer "+token)
+	}
+	if user := env[httpEnvBasicUser]; user != "" {
+		p.headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user+":"+env[httpEnvBasicPassword])))
+	}
+	// Sorted so a header set by two spellings resolves deterministically.
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := env[k]
+		if v == "" {
+			continue
+		}
+		if name, ok := strings.CutPrefix(k, httpEnvHeaderPrefix); ok && name != "" {
+			p.headers.Set(strings.ReplaceAll(name, "_", "-"), v)
+		} else if name, ok := strings.CutPrefix(k, httpEnvQueryPrefix); ok && name != "" {
+			p.query.Set(name, v)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/security"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+func newTestHTTPServer(t *testing.T, h http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	security.SetAllowLoopbackForTest(true)
+	t.Cleanup(func() { security.SetAllowLoopbackForTest(false) })
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newProfileTool returns a tool whose credential store holds one profile.
+func newProfileTool(t *testing.T, name string, env map[string]string) *HTTPRequestTool {
+	t.Helper()
+	blob, err := json.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli := newStubSecureCLIStore()
+	cli.byName[name] = &store.SecureCLIBinary{BinaryName: name, EncryptedEnv: blob, Enabled: true}
+	tool := NewHTTPRequestTool()
+	tool.SetSecureCLIStore(cli)
+	return tool
+}
+
+func TestHTTPRequestSendsJSONBody(t *testing.T) {
+	srv := newTestHTTPServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"method":%q,"type":%q,"q":%q,"got":%s}`, r.Method, r.Header.Get("Content-Type"), r.URL.Query().Get("q"), body)
+	})
+
+	res := NewHTTPRequestTool().Execute(context.Background(), map[string]any{
+		"url":    srv.URL + "/items",
+		"method": "post",
+		"query":  map[string]any{"q": "x y"},
+		"json":   map[string]any{"name": "widget"},
+	})
+	if res.IsError {
+		t.Fatalf("result = %s", res.ForLLM)
+	}
+	for _, want := range []string{"HTTP 200 OK", `"method": "POST"`, `"type": "application/json"`, `"q": "x y"`, `"name": "widget"`} {
+		if !strings.Contains(res.ForLLM, want) {
+			t.Errorf("output missing %q:\n%s", want, res.ForLLM)
+		}
+	}
+}
+
+func TestHTTPRequestErrorStatus(t *testing.T) {
+	srv := newTestHTTPServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusNotFound)
+	})
+	res := NewHTTPRequestTool().Execute(context.Background(), map[string]any{"url": srv.URL})
+	if !res.IsError || !strings.Contains(res.ForLLM, "HTTP 404") {
+		t.Fatalf("result = %+v", res)
+	}
+}
+
+func TestHTTPRequestAuthProfileInjectsAndScrubs(t *testing.T) {
+	const token = "tok_live_0123456789"
+	srv := newTestHTTPServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "auth=%s key=%s", r.Header.Get("Authorization"), r.Header.Get("X-Api-Key"))
+	})
+	tool := newProfileTool(t, "stripe", map[string]string{
+		httpEnvAllowedHosts:       "127.0.0.1",
+		httpEnvBearerToken:        token,
+		"HTTP_HEADER_X_API_KEY":   "key_abcdef123456",
+		"HTTP_HEADER_UNSET_VALUE": "",
+	})
+
+	res := tool.Execute(context.Background(), map[string]any{"url": srv.URL, "auth": "Stripe"})
+	if res.IsError {
+		t.Fatalf("result = %s", res.ForLLM)
+	}
+	if strings.Contains(res.ForLLM, token) || strings.Contains(res.ForLLM, "key_abcdef123456") {
+		t.Fatalf("secret leaked: %s", res.ForLLM)
+	}
+	if !strings.Contains(res.ForLLM, "auth=Bearer [REDACTED]") {
+		t.Fatalf("auth header not sent or not scrubbed: %s", res.ForLLM)
+	}
+}
+
+func TestHTTPRequestAuthProfileHostBinding(t *testing.T) {
+	tool := newProfileTool(t, "stripe", map[string]string{
+		httpEnvAllowedHosts: "api.stripe.com",
+		httpEnvBearerToken:  "tok_live_0123456789",
+	})
+	res := tool.Execute(context.Background(), map[string]any{"url": "https://evil.example.com/", "auth": "stripe"})
+	if !res.IsError || !strings.Contains(res.ForLLM, "may only be used with api.stripe.com") {
+		t.Fatalf("result = %+v", res)
+	}
+
+	res = tool.Execute(context.Background(), map[string]any{"url": "https://api.stripe.com/", "auth": "missing"})
+	if !res.IsError || !strings.Contains(res.ForLLM, "not found or not granted") {
+		t.Fatalf("missing profile result = %+v", res)
+	}
+}
+
+func TestHTTPRequestPresetTemplate(t *testing.T) {
+	tool := newProfileTool(t, "gh", map[string]string{"GH_TOKEN": "ghp_0123456789abcdef"})
+	p, err := tool.resolveAuthProfile(context.Background(), "gh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.headers.Get("Authorization"); got != "Bearer ghp_0123456789abcdef" {
+		t.Fatalf("Authorization = %q", got)
+	}
+	if !p.appliesTo("api.github.com") || p.appliesTo("github.com.evil.io") {
+		t.Fatalf("hosts = %v", p.hosts)
+	}
+}
+
+func TestHTTPRequestRedirectDropsAuth(t *testing.T) {
+	var otherAuth string
+	other := newTestHTTPServer(t, func(w http.ResponseWriter, r *http.Request) {
+		otherAuth = r.Header.Get("Authorization")
+		fmt.Fprint(w, "landed")
+	})
+	// The profile is bound to 127.0.0.1; redirect to the same server via
+	// "localhost" so the second hop is a different host.
+	otherURL, _ := url.Parse(other.URL)
+	target := "http://localhost:" + otherURL.Port() + "/"
+	origin := newTestHTTPServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target, http.StatusFound)
+	})
+	tool := newProfileTool(t, "svc", map[string]string{
+		httpEnvAllowedHosts: "127.0.0.1",
+		httpEnvBearerToken:  "tok_live_0123456789",
+	})
+
+	res := tool.Execute(context.Background(), map[string]any{"url": origin.URL, "auth": "svc"})
+	if res.IsError || !strings.Contains(res.ForLLM, "landed") {
+		t.Fatalf("result = %+v", res)
+	}
+	if otherAuth != "" {
+		t.Fatalf("credential forwarded across redirect: %q", otherAuth)
+	}
+}
+
+func TestHTTPRequestAgentAllowlist(t *testing.T) {
+	srv := newTestHTTPServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+	ctx := WithHTTPRequestToolConfig(context.Background(), &config.HTTPRequestToolPolicy{AllowedHosts: []string{"api.example.com"}})
+	res := NewHTTPRequestTool().Execute(ctx, map[string]any{"url": srv.URL})
+	if !res.IsError || !strings.Contains(res.ForLLM, "allowlist") {
+		t.Fatalf("result = %+v", res)
+	}
+
+	ctx = WithHTTPRequestToolConfig(context.Background(), &config.HTTPRequestToolPolicy{AllowedHosts: []string{"127.0.0.1"}})
+	if res := NewHTTPRequestTool().Execute(ctx, map[string]any{"url": srv.URL}); res.IsError {
+		t.Fatalf("allowed host rejected: %s", res.ForLLM)
+	}
+}
+
+func TestHTTPRequestPaginateLink(t *testing.T) {
+	var srv *httptest.Server
+	srv = newTestHTTPServer(t, func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		if page != "3" {
+			next := map[string]string{"1": "2", "2": "3"}[page]
+			w.Header().Set("Link", fmt.Sprintf(`<%s/items?page=%s>; rel="next", <%s/items?page=3>; rel="last"`, srv.URL, next, srv.URL))
+		}
+		fmt.Fprintf(w, `[{"id":"p%s"}]`, page)
+	})
+
+	res := NewHTTPRequestTool().Execute(context.Background(), map[string]any{
+		"url":      srv.URL + "/items",
+		"paginate": map[string]any{"mode": "link"},
+	})
+	if res.IsError || !strings.Contains(res.ForLLM, "Collected 3 items from 3 pages") {
+		t.Fatalf("result = %s", res.ForLLM)
+	}
+	for _, id := range []string{"p1", "p2", "p3"} {
+		if !strings.Contains(res.ForLLM, id) {
+			t.Errorf("missing %s", id)
+		}
+	}
+}
+
+func TestHTTPRequestPaginateCursor(t *testing.T) {
+	srv := newTestHTTPServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("after") {
+		case "":
+			fmt.Fprint(w, `{"data":[1,2],"meta":{"next":"c2"}}`)
+		case "c2":
+			fmt.Fprint(w, `{"data":[3],"meta":{"next":null}}`)
+		default:
+			http.Error(w, "bad cursor", http.StatusBadRequest)
+		}
+	})
+
+	res := NewHTTPRequestTool().Execute(context.Background(), map[string]any{
+		"url": srv.URL,
+		"paginate": map[string]any{
+			"mode": "cursor", "cursor_path": "meta.next", "cursor_param": "after", "items_path": "data", "max_pages": 2.0,
+		},
+	})
+	if res.IsError || !strings.Contains(res.ForLLM, "Collected 3 items from 2 pages") {
+		t.Fatalf("result = %s", res.ForLLM)
+	}
+	if strings.Contains(res.ForLLM, "max_pages reached") {
+		t.Fatalf("last page reported as truncated: %s", res.ForLLM)
+	}
+}
+
+func TestHTTPRequestValidation(t *testing.T) {
+	tool := NewHTTPRequestTool()
+	cases := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"url": "ftp://example.com"}, "absolute http"},
+		{map[string]any{"url": "https://example.com", "method": "TRACE"}, "unsupported method"},
+		{map[string]any{"url": "https://example.com", "json": map[string]any{}, "body": "x"}, "either json or body"},
+		{map[string]any{"url": "https://example.com", "method": "POST", "paginate": map[string]any{"mode": "link"}}, "only supported for GET"},
+		{map[string]any{"url": "https://example.com", "paginate": map[string]any{"mode": "cursor"}}, "cursor_path is required"},
+		{map[string]any{"url": "https://example.com", "auth": "gh"}, "no credential store"},
+	}
+	for _, tc := range cases {
+		res := tool.Execute(context.Background(), tc.args)
+		if !res.IsError || !strings.Contains(res.ForLLM, tc.want) {
+			t.Errorf("args %v: result = %q, want %q", tc.args, res.ForLLM, tc.want)
+		}
+	}
+}
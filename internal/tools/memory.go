@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/nextlevelbuilder/goclaw/internal/memory"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
@@ -19,6 +20,7 @@ type MemorySearchTool struct {
 	episodicStore store.EpisodicStore             // v3 episodic memory (nil = v2 fallback)
 	metricsStore  store.EvolutionMetricsStore     // evolution metrics (nil = disabled)
 	hasKG         bool                           // knowledge_graph_search tool is available
+	reranker      store.Reranker                 // second-stage reranker (nil = disabled)
 }
 
 func NewMemorySearchTool() *MemorySearchTool {
@@ -40,6 +42,12 @@ func (t *MemorySearchTool) SetEvolutionMetricsStore(ms store.EvolutionMetricsSto
 	t.metricsStore = ms
 }
 
+// SetReranker enables second-stage reranking for agents whose memory config
+// turns it on (memory.rerank.enabled).
+func (t *MemorySearchTool) SetReranker(r store.Reranker) {
+	t.reranker = r
+}
+
 // SetHasKG enables the KG hint in search results.
 func (t *MemorySearchTool) SetHasKG(has bool) {
 	t.hasKG = has
@@ -103,7 +111,8 @@ func (t *MemorySearchTool) Execute(ctx context.Context, args map[string]any) *Re
 		MinScore:   minScore,
 	}
 	// Apply per-agent memory config overrides if set
-	if mc := MemoryConfigFromCtx(ctx); mc != nil {
+	mc := MemoryConfigFromCtx(ctx)
+	if mc != nil {
 		if mc.MaxResults > 0 && searchOpts.MaxResults <= 0 {
 			searchOpts.MaxResults = mc.MaxResults
 		}
@@ -117,6 +126,19 @@ func (t *MemorySearchTool) Execute(ctx context.Context, args map[string]any) *Re
 			searchOpts.MinScore = mc.MinScore
 		}
 	}
+	// Reranking: widen the first stage to a candidate pool, then keep the
+	// per-agent top-k budget after reranking.
+	rerankTopK := 0
+	if t.reranker != nil {
+		rerankTopK = mc.RerankTopK()
+		if maxResults > 0 && maxResults < rerankTopK {
+			rerankTopK = maxResults
+		}
+	}
+	if rerankTopK > 0 {
+		searchOpts.MaxResults = memory.RerankCandidateCount(rerankTopK)
+		maxResults = searchOpts.MaxResults
+	}
 	agentStr := agentID.String()
 	results, err := t.memStore.Search(ctx, query, agentStr, userID, searchOpts)
 	if err != nil {
@@ -166,6 +188,21 @@ func (t *MemorySearchTool) Execute(ctx context.Context, args map[string]any) *Re
 		})
 	}
 
+	if rerankTopK > 0 {
+		combined = memory.ApplyRerank(ctx, t.reranker, query, combined, rerankTopK,
+			func(r *taggedResult) string { return r.Snippet },
+			func(r *taggedResult, score float64) { r.Score = score })
+		// Recall tracking only credits episodic hits that survived reranking.
+		episodicResults = episodicResults[:0]
+		for _, r := range combined {
+			if r.Tier == "episodic" {
+				episodicResults = append(episodicResults, store.EpisodicSearchResult{
+					EpisodicID: r.EpisodicID, L0Abstract: r.L0, Score: r.Score, SessionKey: strings.TrimPrefix(r.Path, "episodic:"),
+				})
+			}
+		}
+	}
+
 	output := map[string]any{
 		"results": combined,
 		"count":   len(combined),
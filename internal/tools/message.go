@@ -28,6 +28,10 @@ type MessageTool struct {
 	sender        ChannelSender
 	msgBus        *bus.MessageBus
 	tenantChecker ChannelTenantChecker
+	editor        MessageEditor
+	contacts      LinkedContactLister
+	sessions      store.SessionStore
+	dmSessionKey  DMSessionKeyResolver
 }
 
 func NewMessageTool(workspace string, restrict bool) *MessageTool {
@@ -37,10 +41,14 @@ func NewMessageTool(workspace string, restrict bool) *MessageTool {
 func (t *MessageTool) SetChannelSender(s ChannelSender)               { t.sender = s }
 func (t *MessageTool) SetMessageBus(b *bus.MessageBus)                { t.msgBus = b }
 func (t *MessageTool) SetChannelTenantChecker(c ChannelTenantChecker) { t.tenantChecker = c }
+func (t *MessageTool) SetMessageEditor(e MessageEditor)               { t.editor = e }
+func (t *MessageTool) SetLinkedContactLister(l LinkedContactLister)   { t.contacts = l }
+func (t *MessageTool) SetSessionStore(s store.SessionStore)           { t.sessions = s }
+func (t *MessageTool) SetDMSessionKeyResolver(r DMSessionKeyResolver) { t.dmSessionKey = r }
 
 func (t *MessageTool) Name() string { return "message" }
 func (t *MessageTool) Description() string {
-	return "Send a message to a channel (Telegram, Discord, Slack, Zalo, Feishu/Lark, WhatsApp, etc.). In a DM/group, omit `target` to reply to the current chat — DO NOT set a different target unless the user explicitly asked you to forward (then set `forward=true` + `forward_reason` quoting the request). In cron/heartbeat/subagent/team contexts, set `target` per job spec."
+	return "Send a message to a channel (Telegram, Discord, Slack, Zalo, Feishu/Lark, WhatsApp, etc.). In a DM/group, omit `target` to reply to the current chat — DO NOT set a different target unless the user explicitly asked you to forward (then set `forward=true` + `forward_reason` quoting the request). In cron/heartbeat/subagent/team contexts, set `target` per job spec. Use action=edit or action=delete to correct or retract a message you already sent to the current chat. Use action=handoff with `channel` to move a DM conversation to the same user's linked account on another channel when they ask to continue there."
 }
 
 func (t *MessageTool) Parameters() map[string]any {
@@ -49,12 +57,12 @@ func (t *MessageTool) Parameters() map[string]any {
 		"properties": map[string]any{
 			"action": map[string]any{
 				"type":        "string",
-				"description": "Action to perform: 'send'",
-				"enum":        []string{"send"},
+				"description": "Action to perform: 'send', 'edit' (replace the text of a sent message), 'delete' (retract a sent message) or 'handoff' (continue this DM on another of the user's channels)",
+				"enum":        []string{"send", "edit", "delete", "handoff"},
 			},
 			"channel": map[string]any{
 				"type":        "string",
-				"description": "Channel name (default: current channel from context)",
+				"description": "Channel name (default: current channel from context). For handoff: the channel to continue on.",
 			},
 			"target": map[string]any{
 				"type":        "string",
@@ -62,7 +70,11 @@ func (t *MessageTool) Parameters() map[string]any {
 			},
 			"message": map[string]any{
 				"type":        "string",
-				"description": "Message content to send. To send a file as attachment, use the prefix MEDIA: followed by the file path, e.g. 'MEDIA:docs/report.pdf' or 'MEDIA:/tmp/image.png'. The file will be uploaded as a document/photo/audio depending on its type.",
+				"description": "Message content to send (for edit: the replacement text; for handoff: the opening message on the new channel). To send a file as attachment, use the prefix MEDIA: followed by the file path, e.g. 'MEDIA:docs/report.pdf' or 'MEDIA:/tmp/image.png'. The file will be uploaded as a document/photo/audio depending on its type.",
+			},
+			"message_id": map[string]any{
+				"type":        "string",
+				"description": "edit/delete only: ID of a message you sent in the current chat. Default: your most recent message in this chat. Unknown IDs return a list of recent messages with their IDs.",
 			},
 			"forward": map[string]any{
 				"type":        "boolean",
@@ -72,15 +84,35 @@ func (t *MessageTool) Parameters() map[string]any {
 				"type":        "string",
 				"description": "Quote the user's literal request when forward=true (e.g. 'gửi báo cáo này sang group dev'). Required when forward=true.",
 			},
+			"actions": map[string]any{
+				"type":        "array",
+				"description": "Optional buttons shown under the message (max 10). A click arrives as a new message \"[Selected option: \"<label>\" (action_id: <id>)]\". Channels without buttons show them as a text list. Allowed in the current chat to let the user pick an option.",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"id":    map[string]any{"type": "string", "description": "Identifier returned on click (max 56 chars). Omit for link buttons."},
+						"label": map[string]any{"type": "string", "description": "Button text (max 40 chars)"},
+						"style": map[string]any{"type": "string", "enum": []string{"primary", "danger"}},
+						"url":   map[string]any{"type": "string", "description": "Open this http(s) link instead of sending a click"},
+					},
+					"required": []string{"label"},
+				},
+			},
 		},
-		"required": []string{"action", "message"},
+		"required": []string{"action"},
 	}
 }
 
 func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *Result {
 	action := argString(args, "action")
-	if action != "send" {
-		return ErrorResult(fmt.Sprintf("unsupported action: %s (only 'send' is supported)", action))
+	switch action {
+	case "send":
+	case "edit", "delete":
+		return t.editSent(ctx, action, args)
+	case "handoff":
+		return t.handoff(ctx, args)
+	default:
+		return ErrorResult(fmt.Sprintf("unsupported action: %s (use 'send', 'edit', 'delete' or 'handoff')", action))
 	}
 
 	message := argString(args, "message")
@@ -110,10 +142,17 @@ func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *Result
 	// delivery (i.e. write_file was called with deliver=false). This prevents both
 	// duplicate delivery (deliver=true then message MEDIA:) and runaway retry loops
 	// (deliver=false then message MEDIA: blocked unconditionally).
+	actions, err := parseMessageActions(args["actions"])
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
 	ctxChannel := ToolChannelFromCtx(ctx)
 	ctxChatID := ToolChatIDFromCtx(ctx)
 	isSelfSend := ctxChannel != "" && ctxChatID != "" && channel == ctxChannel && target == ctxChatID
-	if isSelfSend {
+	// Buttons can't ride on the automatic response, so a self-send with
+	// actions is the intended way to offer choices in the current chat.
+	if isSelfSend && len(actions) == 0 {
 		isMediaSend := embeddedMediaPattern.MatchString(message)
 		if !isMediaSend {
 			return ErrorResult("You are already responding to this chat. Your response text will be delivered automatically. Do not use the message tool to send text to your own chat — just include the content in your response text. To deliver files, use write_file with deliver=true instead.")
@@ -179,6 +218,10 @@ func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *Result
 		return res
 	}
 
+	if len(actions) > 0 {
+		return noticeOnSuccess(t.sendWithActions(ctx, channel, target, message, actions, isSelfSend))
+	}
+
 	// Handle MEDIA: prefix — send file as attachment instead of text.
 	if filePath, ok := t.resolveMediaPath(ctx, message); ok {
 		return noticeOnSuccess(t.sendMedia(ctx, channel, target, filePath))
@@ -245,6 +288,73 @@ func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *Result
 	return ErrorResult("no channel sender or message bus available")
 }
 
+// sendWithActions publishes a message with buttons via the bus. Sends to the
+// current chat keep its topic/thread routing so the buttons land next to the
+// conversation.
+func (t *MessageTool) sendWithActions(ctx context.Context, channel, target, message string, actions []bus.MessageAction, isSelfSend bool) *Result {
+	if t.msgBus == nil {
+		return ErrorResult("sending actions requires message bus")
+	}
+	message, embeddedMedia := t.extractEmbeddedMedia(ctx, message)
+	meta := map[string]string{}
+	if isGroupContext(ctx) {
+		meta["group_id"] = target
+	}
+	if localKey := ToolLocalKeyFromCtx(ctx); isSelfSend && localKey != "" {
+		meta["local_key"] = localKey
+		if idx := strings.Index(localKey, ":topic:"); idx > 0 {
+			meta[MetaMessageThreadID] = localKey[idx+len(":topic:"):]
+		} else if idx := strings.Index(localKey, ":thread:"); idx > 0 {
+			meta[MetaMessageThreadID] = localKey[idx+len(":thread:"):]
+		}
+	}
+	if len(meta) == 0 {
+		meta = nil
+	}
+	t.msgBus.PublishOutbound(bus.OutboundMessage{
+		Channel:  channel,
+		ChatID:   target,
+		Content:  message,
+		Media:    embeddedMedia,
+		Actions:  actions,
+		Metadata: meta,
+	})
+	if dm := DeliveredMediaFromCtx(ctx); dm != nil {
+		for _, att := range embeddedMedia {
+			dm.Mark(att.URL)
+		}
+	}
+	return SilentResult(fmt.Sprintf(`{"status":"sent","channel":"%s","target":"%s","actions":%d}`, channel, target, len(actions)))
+}
+
+// parseMessageActions decodes the "actions" argument and validates it.
+func parseMessageActions(raw any) ([]bus.MessageAction, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("actions must be an array of {id, label, style, url}")
+	}
+	actions := make([]bus.MessageAction, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("actions must be an array of {id, label, style, url}")
+		}
+		actions = append(actions, bus.MessageAction{
+			ID:    argString(m, "id"),
+			Label: argString(m, "label"),
+			Style: argString(m, "style"),
+			URL:   argString(m, "url"),
+		})
+	}
+	if err := bus.ValidateActions(actions); err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
 // validateChannelTenant checks the target channel belongs to the current tenant.
 // Returns an error Result if the send should be blocked, nil if allowed.
 func (t *MessageTool) validateChannelTenant(ctx context.Context, channel, target string) *Result {
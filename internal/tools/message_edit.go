@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// editSent handles the edit and delete actions. Both are limited to messages
+// this agent delivered to the current chat; the agent cannot reach into other
+// chats or other agents' messages.
+func (t *MessageTool) editSent(ctx context.Context, action string, args map[string]any) *Result {
+	if t.editor == nil {
+		return ErrorResult(fmt.Sprintf("%s is not available: sent message tracking is disabled", action))
+	}
+	channel := ToolChannelFromCtx(ctx)
+	chatID := ToolChatIDFromCtx(ctx)
+	if channel == "" || chatID == "" {
+		return ErrorResult(fmt.Sprintf("%s requires a current chat in context", action))
+	}
+	content := argString(args, "message")
+	if action == "edit" && content == "" {
+		return ErrorResult("message is required for edit")
+	}
+
+	// Group chats can hold several agents; each may only touch its own messages.
+	agentID := store.AgentIDFromContext(ctx)
+	if agentID == uuid.Nil {
+		return ErrorResult(fmt.Sprintf("%s requires an agent in context", action))
+	}
+	recent, err := t.editor.ListSentMessages(ctx, store.SentMessageListOpts{Channel: channel, ChatID: chatID, AgentID: agentID})
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to list sent messages: %v", err))
+	}
+	target, errRes := pickSentMessage(recent, argString(args, "message_id"))
+	if errRes != nil {
+		return errRes
+	}
+
+	var sm *store.SentMessage
+	if action == "edit" {
+		sm, err = t.editor.EditSentMessage(ctx, target.ID, content)
+	} else {
+		sm, err = t.editor.RetractSentMessage(ctx, target.ID)
+	}
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to %s message: %v", action, err))
+	}
+	out, _ := json.Marshal(map[string]string{
+		"status":     sm.Status,
+		"message_id": sm.ID.String(),
+		"channel":    sm.Channel,
+		"target":     sm.ChatID,
+	})
+	return SilentResult(string(out))
+}
+
+// pickSentMessage resolves message_id against the chat's recent messages
+// (newest first). An empty id selects the latest message still visible.
+func pickSentMessage(recent []store.SentMessage, id string) (*store.SentMessage, *Result) {
+	if id == "" {
+		for i := range recent {
+			if recent[i].Status != store.SentMessageStatusRetracted {
+				return &recent[i], nil
+			}
+		}
+		return nil, ErrorResult("no sent messages found in the current chat")
+	}
+	if want, err := uuid.Parse(id); err == nil {
+		for i := range recent {
+			if recent[i].ID == want {
+				return &recent[i], nil
+			}
+		}
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "message %q not found in the current chat.", id)
+	if len(recent) > 0 {
+		sb.WriteString(" Recent messages:")
+		for i, m := range recent {
+			if i == 10 {
+				break
+			}
+			fmt.Fprintf(&sb, "\n- %s [%s] %s", m.ID, m.Status, m.Preview)
+		}
+	}
+	return nil, ErrorResult(sb.String())
+}
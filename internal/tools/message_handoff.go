@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/sessions"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// handoffHistoryLimit caps how many recent turns are carried into the
+// target chat's session.
+const handoffHistoryLimit = 20
+
+// handoff moves the current DM conversation to the user's contact on another
+// channel. Only contacts merged into the same tenant user qualify, so the
+// agent cannot reach anyone but the person it is talking to. The opening
+// message is sent there and, unless the session is already shared across
+// channels, the recent history is carried into that chat's session.
+func (t *MessageTool) handoff(ctx context.Context, args map[string]any) *Result {
+	if t.contacts == nil {
+		return ErrorResult("handoff is not available: contact linking is disabled")
+	}
+	if isGroupContext(ctx) {
+		return ErrorResult("handoff is only available in direct messages")
+	}
+	fromChannel := ToolChannelFromCtx(ctx)
+	if fromChannel == "" || ToolChatIDFromCtx(ctx) == "" {
+		return ErrorResult("handoff requires a current chat in context")
+	}
+	want := argString(args, "channel")
+	if want == "" || want == fromChannel {
+		return ErrorResult("channel is required for handoff and must differ from the current channel")
+	}
+	message := argString(args, "message")
+	if message == "" {
+		return ErrorResult("message is required for handoff")
+	}
+
+	contacts, err := t.contacts(ctx, store.UserIDFromContext(ctx))
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to list linked contacts: %v", err))
+	}
+	channel, chatID, available := pickHandoffContact(contacts, fromChannel, want)
+	if chatID == "" {
+		if len(available) == 0 {
+			return ErrorResult("this user has no linked accounts on other channels that they have messaged the agent from directly. An admin can merge their contacts to enable handoff.")
+		}
+		return ErrorResult(fmt.Sprintf("no linked account on %q. Available channels: %s", want, strings.Join(available, ", ")))
+	}
+	if res := t.validateChannelTenant(ctx, channel, chatID); res != nil {
+		return res
+	}
+
+	switch {
+	case t.msgBus != nil:
+		t.msgBus.PublishOutbound(bus.OutboundMessage{Channel: channel, ChatID: chatID, Content: message})
+	case t.sender != nil:
+		if err := t.sender(ctx, channel, chatID, message); err != nil {
+			return ErrorResult(fmt.Sprintf("failed to send message: %v", err))
+		}
+	default:
+		return ErrorResult("no channel sender or message bus available")
+	}
+
+	carried := t.carryHistory(ctx, channel, chatID, message)
+	slog.Info("message.handoff",
+		"session", ToolSessionKeyFromCtx(ctx),
+		"from_channel", fromChannel, "to_channel", channel, "to", chatID,
+		"carried", carried)
+
+	out, _ := json.Marshal(map[string]any{
+		"status":          "handed_off",
+		"channel":         channel,
+		"target":          chatID,
+		"carried_history": carried,
+	})
+	return SilentResult(string(out))
+}
+
+// pickHandoffContact finds the user's DM contact on the wanted channel,
+// matching the channel instance name first and the platform second. Only
+// contacts with a recorded DM chat qualify: the DM chat ID is not the sender
+// ID on every platform, and bots generally cannot open a DM the user never
+// started. It also returns the channels the user can be handed off to.
+func pickHandoffContact(contacts []store.ChannelContact, fromChannel, want string) (channel, chatID string, available []string) {
+	var byType store.ChannelContact
+	for _, c := range contacts {
+		if c.ContactType != "user" || c.ThreadID != nil || c.ChannelInstance == nil || *c.ChannelInstance == fromChannel {
+			continue
+		}
+		if c.DMChatID == nil || *c.DMChatID == "" {
+			continue
+		}
+		if !slices.Contains(available, *c.ChannelInstance) {
+			available = append(available, *c.ChannelInstance)
+		}
+		if *c.ChannelInstance == want {
+			return *c.ChannelInstance, *c.DMChatID, available
+		}
+		if byType.DMChatID == nil && c.ChannelType == want {
+			byType = c
+		}
+	}
+	if byType.DMChatID != nil {
+		return *byType.ChannelInstance, *byType.DMChatID, available
+	}
+	return "", "", available
+}
+
+// carryHistory appends the recent conversation and the opening message to
+// the target chat's DM session so the agent continues there with context.
+// Returns the number of carried messages; cross-channel sessions need none.
+func (t *MessageTool) carryHistory(ctx context.Context, channel, chatID, opening string) int {
+	sessionKey := ToolSessionKeyFromCtx(ctx)
+	agentKey, _ := sessions.ParseSessionKey(sessionKey)
+	if t.sessions == nil || agentKey == "" || sessions.IsUserSession(sessionKey) {
+		return 0
+	}
+	targetKey := sessions.BuildScopedSessionKey(agentKey, channel, sessions.PeerDirect, chatID)
+	if t.dmSessionKey != nil {
+		targetKey = t.dmSessionKey(agentKey, channel, chatID, store.UserIDFromContext(ctx))
+	}
+	if targetKey == sessionKey {
+		return 0
+	}
+
+	var turns []providers.Message
+	for _, m := range t.sessions.GetHistory(ctx, sessionKey) {
+		// Tool calls and results only make sense together with the run that
+		// produced them; the visible exchange is enough to continue.
+		if (m.Role == "user" || m.Role == "assistant") && m.Content != "" && len(m.ToolCalls) == 0 {
+			turns = append(turns, providers.Message{Role: m.Role, Content: m.Content})
+		}
+	}
+	if len(turns) > handoffHistoryLimit {
+		turns = turns[len(turns)-handoffHistoryLimit:]
+	}
+	turns = append(turns, providers.Message{Role: "assistant", Content: opening})
+
+	// Same key the consumer resolves for the DM's chat ID, so the next reply
+	// there continues this session.
+	t.sessions.GetOrCreate(ctx, targetKey)
+	for _, m := range turns {
+		t.sessions.AddMessage(ctx, targetKey, m)
+	}
+	t.sessions.SetAgentInfo(ctx, targetKey, store.AgentIDFromContext(ctx), store.UserIDFromContext(ctx))
+	if err := t.sessions.Save(ctx, targetKey); err != nil {
+		slog.Warn("message.handoff: save target session failed", "session", targetKey, "error", err)
+	}
+	return len(turns) - 1
+}
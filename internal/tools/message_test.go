@@ -8,6 +8,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -15,6 +16,8 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/sessions"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
@@ -862,3 +865,289 @@ func TestMessageTargetEnforced(t *testing.T) {
 		}
 	}
 }
+
+func TestMessageToolActions(t *testing.T) {
+	mb := bus.New()
+	defer mb.Close()
+	tool := NewMessageTool(t.TempDir(), true)
+	tool.SetMessageBus(mb)
+
+	ctx := context.Background()
+	ctx = WithToolChannel(ctx, "telegram")
+	ctx = WithToolChatID(ctx, "-100")
+	ctx = WithToolPeerKind(ctx, "group")
+	ctx = WithToolLocalKey(ctx, "-100:topic:7")
+
+	t.Run("self-send with actions allowed", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{
+			"action":  "send",
+			"message": "Pick one",
+			"actions": []any{
+				map[string]any{"id": "a", "label": "A", "style": "primary"},
+				map[string]any{"label": "Docs", "url": "https://example.com"},
+			},
+		})
+		if result.IsError {
+			t.Fatalf("expected send to succeed, got: %s", result.ForLLM)
+		}
+		outCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		out, ok := mb.SubscribeOutbound(outCtx)
+		if !ok {
+			t.Fatal("expected outbound message")
+		}
+		if len(out.Actions) != 2 || out.Actions[0].ID != "a" {
+			t.Fatalf("actions = %+v", out.Actions)
+		}
+		if out.Metadata["local_key"] != "-100:topic:7" || out.Metadata[MetaMessageThreadID] != "7" {
+			t.Fatalf("metadata = %v, want topic routing", out.Metadata)
+		}
+	})
+
+	t.Run("reserved action id rejected", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{
+			"action":  "send",
+			"message": "Approve?",
+			"actions": []any{map[string]any{"id": "exec:allow-once:exec-1", "label": "Approve"}},
+		})
+		if !result.IsError || !strings.Contains(result.ForLLM, "reserved") {
+			t.Fatalf("expected reserved id error, got: %s", result.ForLLM)
+		}
+	})
+
+	t.Run("malformed actions rejected", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{
+			"action":  "send",
+			"message": "Pick",
+			"actions": "yes,no",
+		})
+		if !result.IsError {
+			t.Fatal("expected malformed actions to be rejected")
+		}
+	})
+}
+
+type fakeMessageEditor struct {
+	msgs      []store.SentMessage
+	edited    map[uuid.UUID]string
+	retracted []uuid.UUID
+}
+
+func (f *fakeMessageEditor) ListSentMessages(_ context.Context, opts store.SentMessageListOpts) ([]store.SentMessage, error) {
+	var out []store.SentMessage
+	for _, m := range f.msgs {
+		if m.Channel == opts.Channel && m.ChatID == opts.ChatID && m.AgentID != nil && *m.AgentID == opts.AgentID {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeMessageEditor) EditSentMessage(_ context.Context, id uuid.UUID, content string) (*store.SentMessage, error) {
+	f.edited[id] = content
+	return &store.SentMessage{ID: id, Status: store.SentMessageStatusEdited}, nil
+}
+
+func (f *fakeMessageEditor) RetractSentMessage(_ context.Context, id uuid.UUID) (*store.SentMessage, error) {
+	f.retracted = append(f.retracted, id)
+	return &store.SentMessage{ID: id, Status: store.SentMessageStatusRetracted}, nil
+}
+
+func TestMessageToolEditAndDelete(t *testing.T) {
+	newest, older, elsewhere, otherAgent := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	agentID, peerID := uuid.New(), uuid.New()
+	editor := &fakeMessageEditor{
+		// Newest first, as returned by the store.
+		msgs: []store.SentMessage{
+			{ID: otherAgent, AgentID: &peerID, Channel: "telegram", ChatID: "42", Status: store.SentMessageStatusSent},
+			{ID: newest, AgentID: &agentID, Channel: "telegram", ChatID: "42", Status: store.SentMessageStatusRetracted},
+			{ID: older, AgentID: &agentID, Channel: "telegram", ChatID: "42", Status: store.SentMessageStatusSent, Preview: "old answer"},
+			{ID: elsewhere, AgentID: &agentID, Channel: "telegram", ChatID: "99", Status: store.SentMessageStatusSent},
+		},
+		edited: map[uuid.UUID]string{},
+	}
+	tool := NewMessageTool(t.TempDir(), true)
+	tool.SetMessageEditor(editor)
+
+	ctx := WithToolChannel(context.Background(), "telegram")
+	ctx = WithToolChatID(ctx, "42")
+	ctx = store.WithAgentID(ctx, agentID)
+
+	t.Run("edit defaults to latest visible message", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{"action": "edit", "message": "fixed answer"})
+		if result.IsError {
+			t.Fatalf("edit failed: %s", result.ForLLM)
+		}
+		if editor.edited[older] != "fixed answer" {
+			t.Fatalf("edited = %v, want %s", editor.edited, older)
+		}
+	})
+
+	t.Run("edit requires message", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{"action": "edit"})
+		if !result.IsError {
+			t.Fatal("expected error without replacement text")
+		}
+	})
+
+	t.Run("delete by id", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{"action": "delete", "message_id": older.String()})
+		if result.IsError {
+			t.Fatalf("delete failed: %s", result.ForLLM)
+		}
+		if len(editor.retracted) != 1 || editor.retracted[0] != older {
+			t.Fatalf("retracted = %v, want [%s]", editor.retracted, older)
+		}
+	})
+
+	t.Run("other chat is out of reach", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{"action": "delete", "message_id": elsewhere.String()})
+		if !result.IsError || !strings.Contains(result.ForLLM, "not found") {
+			t.Fatalf("expected not found, got: %s", result.ForLLM)
+		}
+		if !strings.Contains(result.ForLLM, older.String()) {
+			t.Fatalf("expected recent message list, got: %s", result.ForLLM)
+		}
+	})
+
+	t.Run("other agent's message is out of reach", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{"action": "delete", "message_id": otherAgent.String()})
+		if !result.IsError || !strings.Contains(result.ForLLM, "not found") {
+			t.Fatalf("expected not found, got: %s", result.ForLLM)
+		}
+	})
+
+	t.Run("unavailable without editor", func(t *testing.T) {
+		plain := NewMessageTool(t.TempDir(), true)
+		result := plain.Execute(ctx, map[string]any{"action": "delete"})
+		if !result.IsError {
+			t.Fatal("expected error without editor")
+		}
+	})
+}
+
+func TestMessageToolHandoff(t *testing.T) {
+	mb := bus.New()
+	defer mb.Close()
+	tool := NewMessageTool(t.TempDir(), true)
+	tool.SetMessageBus(mb)
+
+	str := func(s string) *string { return &s }
+	var gotUser string
+	tool.SetLinkedContactLister(func(_ context.Context, userID string) ([]store.ChannelContact, error) {
+		gotUser = userID
+		return []store.ChannelContact{
+			{ChannelType: "telegram", ChannelInstance: str("tg-bot"), SenderID: "386", ContactType: "user", DMChatID: str("386")},
+			// Slack DMs live in a "D…" conversation, not under the user ID.
+			{ChannelType: "slack", ChannelInstance: str("slack-main"), SenderID: "U42", ContactType: "user", DMChatID: str("D042")},
+			{ChannelType: "slack", ChannelInstance: str("slack-main"), SenderID: "U42", ContactType: "topic", ThreadID: str("1.2")},
+			// Seen in a Discord server but never messaged the bot directly.
+			{ChannelType: "discord", ChannelInstance: str("discord-main"), SenderID: "777", ContactType: "user"},
+		}, nil
+	})
+
+	sess := newMockSessionStore()
+	sourceKey := sessions.BuildScopedSessionKey("bot", "tg-bot", sessions.PeerDirect, "386")
+	sess.seed(sourceKey, []providers.Message{
+		{Role: "user", Content: "can we move to slack?"},
+		{Role: "assistant", Content: "sure"},
+	}, "")
+	tool.SetSessionStore(sess)
+
+	ctx := store.WithUserID(context.Background(), "alice")
+	ctx = WithToolChannel(ctx, "tg-bot")
+	ctx = WithToolChatID(ctx, "386")
+	ctx = WithToolPeerKind(ctx, "direct")
+	ctx = WithToolSessionKey(ctx, sourceKey)
+
+	t.Run("platform name resolves to linked account", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{"action": "handoff", "channel": "slack", "message": "Continuing here from Telegram."})
+		if result.IsError {
+			t.Fatalf("handoff failed: %s", result.ForLLM)
+		}
+		if gotUser != "alice" {
+			t.Fatalf("contacts listed for %q, want alice", gotUser)
+		}
+		out := drainBusNow(mb)
+		if len(out) != 1 || out[0].Channel != "slack-main" || out[0].ChatID != "D042" {
+			t.Fatalf("outbound = %+v, want one message to slack-main/D042", out)
+		}
+		// History lands in the session the consumer keys on the DM chat ID.
+		target := sessions.BuildScopedSessionKey("bot", "slack-main", sessions.PeerDirect, "D042")
+		if got := sess.GetHistory(context.Background(), target); len(got) != 3 || got[2].Content != "Continuing here from Telegram." {
+			t.Fatalf("carried history = %+v", got)
+		}
+	})
+
+	t.Run("unknown channel lists alternatives", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{"action": "handoff", "channel": "discord", "message": "hi"})
+		if !result.IsError || !strings.Contains(result.ForLLM, "slack-main") ||
+			strings.Contains(result.ForLLM, "tg-bot") || strings.Contains(result.ForLLM, "discord-main") {
+			t.Fatalf("expected error listing slack-main only, got: %s", result.ForLLM)
+		}
+	})
+
+	t.Run("current channel rejected", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{"action": "handoff", "channel": "tg-bot", "message": "hi"})
+		if !result.IsError {
+			t.Fatal("expected handoff to the current channel to be rejected")
+		}
+	})
+
+	t.Run("group chats rejected", func(t *testing.T) {
+		gctx := WithToolPeerKind(ctx, "group")
+		result := tool.Execute(gctx, map[string]any{"action": "handoff", "channel": "slack", "message": "hi"})
+		if !result.IsError || !strings.Contains(result.ForLLM, "direct messages") {
+			t.Fatalf("expected group rejection, got: %s", result.ForLLM)
+		}
+	})
+	if out := drainBusNow(mb); len(out) != 0 {
+		t.Fatalf("rejected handoffs sent %d messages", len(out))
+	}
+}
+
+func TestMessageToolHandoffUsesDMSessionKeyResolver(t *testing.T) {
+	mb := bus.New()
+	defer mb.Close()
+	tool := NewMessageTool(t.TempDir(), true)
+	tool.SetMessageBus(mb)
+
+	str := func(s string) *string { return &s }
+	tool.SetLinkedContactLister(func(context.Context, string) ([]store.ChannelContact, error) {
+		return []store.ChannelContact{
+			{ChannelType: "slack", ChannelInstance: str("slack-main"), SenderID: "U42", ContactType: "user", DMChatID: str("D042")},
+		}, nil
+	})
+	var resolved []string
+	tool.SetDMSessionKeyResolver(func(agentKey, channel, chatID, userID string) string {
+		resolved = []string{agentKey, channel, chatID, userID}
+		return sessions.BuildUserSessionKey(agentKey, userID)
+	})
+
+	// A DM thread keeps its own session even when DMs are shared across
+	// channels, so the history still has to be carried.
+	sess := newMockSessionStore()
+	sourceKey := sessions.BuildDMThreadSessionKey("bot", "tg-bot", "386", 7)
+	sess.seed(sourceKey, []providers.Message{{Role: "user", Content: "move to slack"}}, "")
+	tool.SetSessionStore(sess)
+
+	ctx := store.WithUserID(context.Background(), "alice")
+	ctx = WithToolChannel(ctx, "tg-bot")
+	ctx = WithToolChatID(ctx, "386")
+	ctx = WithToolPeerKind(ctx, "direct")
+	ctx = WithToolSessionKey(ctx, sourceKey)
+
+	result := tool.Execute(ctx, map[string]any{"action": "handoff", "channel": "slack", "message": "Continuing here."})
+	if result.IsError {
+		t.Fatalf("handoff failed: %s", result.ForLLM)
+	}
+	if want := []string{"bot", "slack-main", "D042", "alice"}; !slices.Equal(resolved, want) {
+		t.Fatalf("resolver called with %v, want %v", resolved, want)
+	}
+	if got := sess.GetHistory(context.Background(), sessions.BuildUserSessionKey("bot", "alice")); len(got) != 2 {
+		t.Fatalf("carried history = %+v, want it in the resolved session", got)
+	}
+	if got := sess.GetHistory(context.Background(), sessions.BuildScopedSessionKey("bot", "slack-main", sessions.PeerDirect, "D042")); len(got) != 0 {
+		t.Fatalf("history written to the per-channel key: %+v", got)
+	}
+}
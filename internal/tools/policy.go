@@ -15,8 +15,8 @@ import (
 var builtinToolGroups = map[string][]string{
 	"memory":     {"memory_search", "memory_get"},
 	"web":        {"web_search", "web_fetch"},
-	"fs":         {"read_file", "write_file", "list_files", "edit"},
-	"runtime":    {"exec", "wait"},
+	"fs":         {"read_file", "write_file", "list_files", "search_files", "edit", "apply_patch"},
+	"runtime":    {"exec", "wait", "code_interpreter"},
 	"sessions":   {"sessions_list", "sessions_history", "sessions_send", "spawn", "session_status"},
 	"ui":         {"browser"},
 	"automation": {"cron"},
@@ -25,8 +25,8 @@ var builtinToolGroups = map[string][]string{
 	"vault":      {"vault_search", "vault_read"},
 	// Composite group: all goclaw native tools (excludes MCP/custom plugins).
 	"goclaw": {
-		"read_file", "write_file", "list_files", "edit", "exec", "wait",
-		"web_search", "web_fetch", "browser",
+		"read_file", "write_file", "list_files", "search_files", "edit", "apply_patch", "exec", "wait", "code_interpreter",
+		"web_search", "web_fetch", "http_request", "sql_query", "browser",
 		"memory_search", "memory_get", "memory_expand",
 		"knowledge_graph_search", "vault_search", "vault_read",
 		"sessions_list", "sessions_history", "sessions_send", "spawn", "session_status",
@@ -47,7 +47,7 @@ var builtinToolGroups = map[string][]string{
 // Tool profiles define preset allow sets.
 var toolProfiles = map[string][]string{
 	"minimal":   {"session_status"},
-	"coding":    {"group:fs", "group:runtime", "group:sessions", "group:memory", "group:web", "group:vault", "read_image", "create_image", "skill_search"},
+	"coding":    {"group:fs", "group:runtime", "group:sessions", "group:memory", "group:web", "http_request", "sql_query", "group:vault", "read_image", "create_image", "skill_search"},
 	"messaging": {"group:messaging", "wait", "group:web", "group:vault", "sessions_list", "sessions_history", "sessions_send", "session_status", "read_image", "skill_search"},
 	"full":      {}, // empty = no restrictions
 }
@@ -69,6 +69,7 @@ func LegacyToolAliases() map[string]string {
 // Subagent deny lists — tools subagents cannot use.
 var subagentDenyList = []string{
 	"exec", // subagents should not shell out — main agent can still exec
+	"code_interpreter",
 	"gateway", "agents_list", "whatsapp_login", "session_status",
 	"cron", "memory_search", "memory_get", "sessions_send",
 }
@@ -0,0 +1,518 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nextlevelbuilder/goclaw/internal/sandbox"
+)
+
+const (
+	searchDefaultResults = 100
+	searchMaxResults     = 1000
+	searchMaxContext     = 10
+	searchMaxOutputChars = 30000
+	searchMaxLineChars   = 300
+	searchMaxFileBytes   = 10 << 20 // larger files are skipped
+	searchMaxLineBytes   = 1 << 20  // minified bundles and the like
+	searchBinarySniff    = 8000
+)
+
+const (
+	searchModeContent = "content"
+	searchModeFiles   = "files_with_matches"
+	searchModeCount   = "count"
+)
+
+// SearchFilesTool searches file contents and names in the workspace natively
+// (no grep/rg binary), so it behaves the same on every platform and edition.
+// It skips hidden files, .gitignore'd paths, binary files and denied prefixes.
+type SearchFilesTool struct {
+	workspace       string
+	restrict        bool
+	allowedPrefixes []string // extra allowed path prefixes (e.g. skills dirs)
+	deniedPrefixes  []string // path prefixes to deny access to (e.g. .goclaw)
+	sandboxMgr      sandbox.Manager
+}
+
+// AllowPaths adds extra path prefixes that search_files is allowed to access
+// even when restrict_to_workspace is true (e.g. skills directories).
+func (t *SearchFilesTool) AllowPaths(prefixes ...string) {
+	t.allowedPrefixes = append(t.allowedPrefixes, prefixes...)
+}
+
+// DenyPaths adds path prefixes that search_files must reject/filter.
+func (t *SearchFilesTool) DenyPaths(prefixes ...string) {
+	t.deniedPrefixes = append(t.deniedPrefixes, prefixes...)
+}
+
+func NewSearchFilesTool(workspace string, restrict bool) *SearchFilesTool {
+	return &SearchFilesTool{workspace: workspace, restrict: restrict}
+}
+
+// NewSandboxedSearchFilesTool searches the host side of the sandbox's
+// workspace mount, confined to that mount.
+func NewSandboxedSearchFilesTool(workspace string, restrict bool, mgr sandbox.Manager) *SearchFilesTool {
+	return &SearchFilesTool{workspace: workspace, restrict: restrict, sandboxMgr: mgr}
+}
+
+// SetSandboxKey is a no-op; sandbox key is now read from ctx (thread-safe).
+func (t *SearchFilesTool) SetSandboxKey(key string) {}
+
+func (t *SearchFilesTool) Name() string { return "search_files" }
+func (t *SearchFilesTool) Description() string {
+	return "Search file contents with a regular expression (like ripgrep), or find files by glob. " +
+		"Skips hidden files, .gitignore'd paths and binary files. Returns path:line:text matches, matching file paths, or per-file counts."
+}
+
+func (t *SearchFilesTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"pattern": map[string]any{
+				"type":        "string",
+				"description": "Regular expression (RE2 syntax) to search for. Omit with glob to just list matching files.",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "File or directory to search (relative to workspace; omit for workspace root)",
+			},
+			"glob": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": `File filters, e.g. ["*.go"], ["src/**/*.{ts,tsx}"], ["!vendor/"]. Globs without "/" match file names at any depth; "!" excludes.`,
+			},
+			"output_mode": map[string]any{
+				"type":        "string",
+				"enum":        []string{searchModeContent, searchModeFiles, searchModeCount},
+				"description": "content (matching lines, default), files_with_matches (paths only) or count (matches per file)",
+			},
+			"context": map[string]any{
+				"type":        "number",
+				"description": "Lines of context before and after each match (content mode, max 10)",
+			},
+			"ignore_case": map[string]any{
+				"type":        "boolean",
+				"description": "Case-insensitive match",
+			},
+			"fixed_strings": map[string]any{
+				"type":        "boolean",
+				"description": "Treat pattern as a literal string, not a regex",
+			},
+			"max_results": map[string]any{
+				"type":        "number",
+				"description": "Maximum matching lines (content mode) or files (other modes). Default 100, max 1000.",
+			},
+			"include_hidden": map[string]any{
+				"type":        "boolean",
+				"description": "Also search hidden files and directories (dotfiles)",
+			},
+			"no_ignore": map[string]any{
+				"type":        "boolean",
+				"description": "Do not apply .gitignore rules",
+			},
+		},
+	}
+}
+
+// fileSearch holds the state of one search_files call.
+type fileSearch struct {
+	re        *regexp.Regexp // nil = list files matching the globs
+	mode      string
+	context   int
+	max       int
+	globs     *searchGlobs
+	hidden    bool
+	noIgnore  bool
+	skip      func(abs string) bool // deny-path filter
+	workspace string                // display paths are relative to this when inside it
+
+	out       strings.Builder
+	matches   int
+	files     int
+	scanned   int
+	truncated bool
+}
+
+func (t *SearchFilesTool) Execute(ctx context.Context, args map[string]any) *Result {
+	pattern, _ := args["pattern"].(string)
+	searchPath, _ := args["path"].(string)
+	if searchPath == "" {
+		searchPath = "."
+	}
+	globs := searchGlobArgs(args["glob"])
+	if pattern == "" && len(globs) == 0 {
+		return ErrorResult("pattern or glob is required")
+	}
+
+	s := &fileSearch{
+		mode:     searchModeContent,
+		context:  min(max(intArg(args, "context", 0), 0), searchMaxContext),
+		max:      min(max(intArg(args, "max_results", searchDefaultResults), 1), searchMaxResults),
+		hidden:   args["include_hidden"] == true,
+		noIgnore: args["no_ignore"] == true,
+	}
+	if m, _ := args["output_mode"].(string); m != "" {
+		if m != searchModeContent && m != searchModeFiles && m != searchModeCount {
+			return ErrorResult(fmt.Sprintf("unsupported output_mode %q", m))
+		}
+		s.mode = m
+	}
+	if pattern != "" {
+		if args["fixed_strings"] == true {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		if args["ignore_case"] == true {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("invalid pattern: %v", err))
+		}
+		s.re = re
+	} else {
+		s.mode = searchModeFiles
+	}
+	var err error
+	if s.globs, err = newSearchGlobs(globs); err != nil {
+		return ErrorResult(fmt.Sprintf("invalid glob: %v", err))
+	}
+
+	root, workspace, err := t.resolveRoot(ctx, searchPath)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	s.workspace = workspace
+	s.skip = func(abs string) bool { return checkDeniedPath(abs, t.workspace, t.deniedPrefixes) != nil }
+
+	fi, err := os.Stat(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			msg := fmt.Sprintf("Path does not exist: %s", searchPath)
+			if teamWs := ToolTeamWorkspaceFromCtx(ctx); teamWs != "" && !strings.HasPrefix(root, teamWs) {
+				msg += fmt.Sprintf("\nHint: try the team workspace path: search_files(path=\"%s/%s\")", teamWs, searchPath)
+			}
+			return SilentResult(msg)
+		}
+		return ErrorResult(fmt.Sprintf("failed to access path: %v", err))
+	}
+
+	if fi.IsDir() {
+		err = s.walk(ctx, root, "", s.ancestorIgnores(root))
+	} else {
+		// An explicitly named file is searched even if ignored, like rg.
+		err = s.searchFile(root)
+	}
+	if err != nil && !errors.Is(err, errSearchDone) {
+		return ErrorResult(fmt.Sprintf("search failed: %v", err))
+	}
+	return SilentResult(s.result())
+}
+
+// resolveRoot maps the search path to a host path and returns it with the
+// workspace used for display. In sandbox mode the search runs on the host
+// side of the workspace mount and may not leave it.
+func (t *SearchFilesTool) resolveRoot(ctx context.Context, searchPath string) (string, string, error) {
+	if t.sandboxMgr != nil && ToolSandboxKeyFromCtx(ctx) != "" {
+		mountWorkspace, err := effectiveSandboxWorkspace(ctx, t.workspace)
+		if err != nil {
+			return "", "", err
+		}
+		containerCwd, err := sandboxCwdForHostPath(mountWorkspace, mountWorkspace, sandbox.DefaultContainerWorkdir)
+		if err != nil {
+			return "", "", fmt.Errorf("sandbox path mapping: %v", err)
+		}
+		// Container paths (/workspace/...) map back onto the mount.
+		if p := path.Clean(filepath.ToSlash(searchPath)); p == containerCwd || strings.HasPrefix(p, containerCwd+"/") {
+			searchPath = "." + strings.TrimPrefix(p, containerCwd)
+		}
+		resolved, err := resolvePath(searchPath, mountWorkspace, true)
+		if err != nil {
+			return "", "", err
+		}
+		if err := checkDeniedPath(resolved, t.workspace, t.deniedPrefixes); err != nil {
+			return "", "", err
+		}
+		return resolved, mountWorkspace, nil
+	}
+
+	// Host execution — use per-user workspace from context if available
+	workspace := ToolWorkspaceFromCtx(ctx)
+	if workspace == "" {
+		workspace = t.workspace
+	}
+	allowed := allowedWithTeamWorkspace(ctx, t.allowedPrefixes)
+	resolved, err := resolvePathWithAllowed(searchPath, workspace, effectiveRestrict(ctx, t.restrict), allowed)
+	if err != nil {
+		return "", "", err
+	}
+	if err := checkDeniedPath(resolved, t.workspace, t.deniedPrefixes); err != nil {
+		return "", "", err
+	}
+	return resolved, canonicalSandboxWorkspace(workspace), nil
+}
+
+// ancestorIgnores loads .gitignore files between the workspace and root so
+// searching a subdirectory honours rules declared above it.
+func (s *fileSearch) ancestorIgnores(root string) ignoreStack {
+	if s.noIgnore {
+		return nil
+	}
+	rel, err := filepath.Rel(s.workspace, root)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	var stack ignoreStack
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i := range parts {
+		dir := filepath.Join(append([]string{s.workspace}, parts[:i]...)...)
+		if r := loadIgnoreFile(filepath.Join(dir, ".gitignore"), ""); r != nil {
+			r.prefix = strings.Join(parts[i:], "/")
+			stack = append(stack, r)
+		}
+	}
+	return stack
+}
+
+var errSearchDone = errors.New("search limit reached")
+
+// walk searches dir recursively in name order. rel is dir relative to the
+// search root, slash-separated ("" for the root).
+func (s *fileSearch) walk(ctx context.Context, dir, rel string, ignores ignoreStack) error {
+	if !s.noIgnore {
+		if r := loadIgnoreFile(filepath.Join(dir, ".gitignore"), rel); r != nil {
+			ignores = append(ignores[:len(ignores):len(ignores)], r)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if rel == "" {
+			return err
+		}
+		return nil // unreadable subdirectory: skip, like rg
+	}
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		name := e.Name()
+		abs := filepath.Join(dir, name)
+		entryRel := path.Join(rel, name)
+		if e.Type()&os.ModeSymlink != 0 {
+			continue // not followed: a link could point outside the workspace
+		}
+		if name == ".git" || (!s.hidden && strings.HasPrefix(name, ".")) || s.skip(abs) {
+			continue
+		}
+		if e.IsDir() {
+			if (!s.noIgnore && ignores.ignored(entryRel, true)) || s.globs.skipDir(entryRel) {
+				continue
+			}
+			if err := s.walk(ctx, abs, entryRel, ignores); err != nil {
+				return err
+			}
+			continue
+		}
+		if !e.Type().IsRegular() || (!s.noIgnore && ignores.ignored(entryRel, false)) || !s.globs.allowFile(entryRel) {
+			continue
+		}
+		if binaryFileExts[strings.ToLower(filepath.Ext(name))] {
+			continue
+		}
+		if err := s.searchFile(abs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// searchFile scans one file. It returns errSearchDone once a cap is hit.
+func (s *fileSearch) searchFile(abs string) error {
+	display := s.displayPath(abs)
+	if s.re == nil {
+		s.scanned++
+		return s.addFile(display)
+	}
+
+	f, err := os.Open(abs)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	if fi, err := f.Stat(); err != nil || fi.Size() > searchMaxFileBytes {
+		return nil
+	}
+	br := bufio.NewReader(f)
+	if head, _ := br.Peek(searchBinarySniff); bytes.IndexByte(head, 0) >= 0 {
+		return nil
+	}
+	s.scanned++
+
+	sc := bufio.NewScanner(br)
+	sc.Buffer(make([]byte, 64*1024), searchMaxLineBytes)
+	type ctxLine struct {
+		n    int
+		text string
+	}
+	var before []ctxLine
+	count, afterLeft, lastPrinted := 0, 0, 0
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if s.re.MatchString(line) {
+			count++
+			switch s.mode {
+			case searchModeFiles:
+				return s.addFile(display)
+			case searchModeCount:
+				continue
+			}
+			if s.matches >= s.max {
+				s.truncated = true
+				return errSearchDone
+			}
+			if count == 1 {
+				s.files++
+			}
+			if lastPrinted == 0 && s.context > 0 && s.out.Len() > 0 {
+				s.out.WriteString("--\n")
+			}
+			for _, b := range before {
+				if b.n > lastPrinted {
+					s.writeLine(display, b.n, '-', b.text, &lastPrinted)
+				}
+			}
+			s.writeLine(display, n, ':', line, &lastPrinted)
+			s.matches++
+			afterLeft = s.context
+		} else if afterLeft > 0 && s.mode == searchModeContent {
+			s.writeLine(display, n, '-', line, &lastPrinted)
+			afterLeft--
+		}
+		if s.context > 0 {
+			before = append(before, ctxLine{n, line})
+			if len(before) > s.context {
+				before = before[1:]
+			}
+		}
+		if s.out.Len() > searchMaxOutputChars {
+			s.truncated = true
+			return errSearchDone
+		}
+	}
+	// sc.Err() (usually an over-long line) ends the file; earlier matches stand.
+	if count > 0 && s.mode == searchModeCount {
+		if s.files >= s.max {
+			s.truncated = true
+			return errSearchDone
+		}
+		s.files++
+		s.matches += count
+		fmt.Fprintf(&s.out, "%s:%d\n", display, count)
+	}
+	return nil
+}
+
+func (s *fileSearch) addFile(display string) error {
+	if s.files >= s.max {
+		s.truncated = true
+		return errSearchDone
+	}
+	s.files++
+	s.out.WriteString(display)
+	s.out.WriteByte('\n')
+	return nil
+}
+
+// writeLine emits a ripgrep-style line: path:N:text for matches and
+// path-N-text for context, with "--" between non-adjacent groups.
+func (s *fileSearch) writeLine(display string, n int, sep byte, text string, lastPrinted *int) {
+	if *lastPrinted > 0 && n > *lastPrinted+1 && s.context > 0 {
+		s.out.WriteString("--\n")
+	}
+	fmt.Fprintf(&s.out, "%s%c%d%c%s\n", display, sep, n, sep, clipSearchLine(text))
+	*lastPrinted = n
+}
+
+func (s *fileSearch) displayPath(abs string) string {
+	if rel, err := filepath.Rel(s.workspace, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(abs)
+}
+
+func (s *fileSearch) result() string {
+	if s.out.Len() == 0 {
+		if s.re == nil {
+			return "No files matched the glob."
+		}
+		return fmt.Sprintf("No matches found (%d files searched).", s.scanned)
+	}
+	footer := fmt.Sprintf("[%d matches in %d files]", s.matches, s.files)
+	if s.mode == searchModeFiles {
+		footer = fmt.Sprintf("[%d files]", s.files)
+	}
+	if s.truncated {
+		footer = strings.TrimSuffix(footer, "]") + "; results truncated — narrow the pattern, path or glob, or raise max_results]"
+	}
+	return s.out.String() + "\n" + footer
+}
+
+// clipSearchLine keeps long lines (minified code, data) from flooding the
+// result.
+func clipSearchLine(s string) string {
+	if len(s) <= searchMaxLineChars {
+		return s
+	}
+	cut := searchMaxLineChars
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
+
+// searchGlobArgs accepts glob as an array or a single string. Strings are
+// split on commas outside braces so "*.go,*.md" works but "*.{ts,tsx}" stays
+// whole.
+func searchGlobArgs(v any) []string {
+	var raw []string
+	switch g := v.(type) {
+	case string:
+		raw = []string{g}
+	case []any:
+		for _, item := range g {
+			if str, ok := item.(string); ok {
+				raw = append(raw, str)
+			}
+		}
+	case []string:
+		raw = g
+	}
+	var out []string
+	for _, r := range raw {
+		depth, start := 0, 0
+		for i := 0; i < len(r); i++ {
+			switch r[i] {
+			case '{':
+				depth++
+			case '}':
+				depth = max(depth-1, 0)
+			case ',':
+				if depth == 0 {
+					out = append(out, r[start:i])
+					start = i + 1
+				}
+			}
+		}
+		out = append(out, r[start:])
+	}
+	return out
+}
@@ -0,0 +1,245 @@
+package tools
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// globToRegexp compiles a gitignore-style glob to an anchored regexp over
+// slash-separated paths: "*" and "?" stay within one segment, "**" spans
+// segments, "[...]" is a character class and "{a,b}" an alternation.
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	braces := 0
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				switch {
+				case i+1 < len(glob) && glob[i+1] == '/':
+					i++
+					b.WriteString("(?:.*/)?")
+				default:
+					b.WriteString(".*")
+				}
+				continue
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '{':
+			braces++
+			b.WriteString("(?:")
+		case '}':
+			if braces == 0 {
+				b.WriteString(`\}`)
+				continue
+			}
+			braces--
+			b.WriteString(")")
+		case ',':
+			if braces > 0 {
+				b.WriteString("|")
+			} else {
+				b.WriteString(",")
+			}
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// pathGlob is one glob filter. Globs without a slash match the base name at
+// any depth; globs with one match the path relative to the search root.
+type pathGlob struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+	base    bool // match against the base name only
+}
+
+func compilePathGlob(pattern string) (*pathGlob, error) {
+	g := &pathGlob{}
+	if strings.HasPrefix(pattern, "!") {
+		g.negate = true
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		g.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	anchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	g.base = !anchored && !strings.Contains(pattern, "/")
+	re, err := globToRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	g.re = re
+	return g, nil
+}
+
+// match reports whether rel (slash-separated, relative to the glob's root)
+// matches. A directory also matches a "dir/**" glob so whole trees prune.
+func (g *pathGlob) match(rel string, isDir bool) bool {
+	if g.dirOnly && !isDir {
+		return false
+	}
+	if g.base {
+		return g.re.MatchString(path.Base(rel))
+	}
+	if g.re.MatchString(rel) {
+		return true
+	}
+	return isDir && g.re.MatchString(rel+"/")
+}
+
+// searchGlobs applies the user's glob filters like ripgrep's -g: a file must
+// match at least one positive glob (when any are given) and no negated one.
+type searchGlobs struct {
+	include []*pathGlob
+	exclude []*pathGlob
+}
+
+func newSearchGlobs(patterns []string) (*searchGlobs, error) {
+	s := &searchGlobs{}
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		g, err := compilePathGlob(p)
+		if err != nil {
+			return nil, err
+		}
+		if g.negate {
+			s.exclude = append(s.exclude, g)
+		} else {
+			s.include = append(s.include, g)
+		}
+	}
+	return s, nil
+}
+
+// skipDir reports whether a directory is excluded by a negated glob.
+func (s *searchGlobs) skipDir(rel string) bool {
+	for _, g := range s.exclude {
+		if g.match(rel, true) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *searchGlobs) allowFile(rel string) bool {
+	for _, g := range s.exclude {
+		if g.match(rel, false) {
+			return false
+		}
+	}
+	if len(s.include) == 0 {
+		return true
+	}
+	for _, g := range s.include {
+		if g.match(rel, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreRules holds the patterns of one .gitignore file. dir is the file's
+// directory relative to the search root ("" for the root itself). For a
+// .gitignore above the search root, prefix is the root's path below it.
+type ignoreRules struct {
+	dir    string
+	prefix string
+	globs  []*pathGlob
+}
+
+// loadIgnoreFile parses a .gitignore. Missing or unreadable files yield nil.
+func loadIgnoreFile(file, dir string) *ignoreRules {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	rules := &ignoreRules{dir: dir}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// "\#" and "\!" stay escaped; globToRegexp reads them as literals.
+		negate := false
+		if strings.HasPrefix(line, "!") {
+			negate, line = true, line[1:]
+		}
+		// A slash anywhere but the end anchors the pattern to this directory.
+		if !strings.HasPrefix(line, "/") && strings.Contains(strings.TrimSuffix(line, "/"), "/") {
+			line = "/" + line
+		}
+		if negate {
+			line = "!" + line
+		}
+		if g, err := compilePathGlob(line); err == nil {
+			rules.globs = append(rules.globs, g)
+		}
+	}
+	if len(rules.globs) == 0 {
+		return nil
+	}
+	return rules
+}
+
+// ignoreStack is the chain of .gitignore files from the search root down to
+// the directory being walked. Later (deeper) rules override earlier ones and
+// the last matching pattern wins, as in git.
+type ignoreStack []*ignoreRules
+
+func (s ignoreStack) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, r := range s {
+		sub := rel
+		if r.prefix != "" {
+			sub = r.prefix + "/" + rel
+		} else if r.dir != "" {
+			if !strings.HasPrefix(rel, r.dir+"/") {
+				continue
+			}
+			sub = rel[len(r.dir)+1:]
+		}
+		for _, g := range r.globs {
+			if g.match(sub, isDir) {
+				ignored = !g.negate
+			}
+		}
+	}
+	return ignored
+}
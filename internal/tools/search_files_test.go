@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newSearchWorkspace(t *testing.T) (string, context.Context) {
+	t.Helper()
+	ws := t.TempDir()
+	writeTestFiles(t, ws, map[string]string{
+		".gitignore":        "build/\n*.log\n!keep.log\n",
+		"main.go":           "package main\n\nfunc main() {\n\tHandleRequest()\n}\n",
+		"api/handler.go":    "package api\n\n// HandleRequest serves.\nfunc HandleRequest() {}\n",
+		"api/handler.ts":    "export function handleRequest() {}\n",
+		"api/.gitignore":    "gen/\n",
+		"api/gen/stub.go":   "func HandleRequest() {}\n",
+		"api/trace.log":     "HandleRequest\n",
+		"build/out.go":      "func HandleRequest() {}\n",
+		"debug.log":         "HandleRequest failed\n",
+		"keep.log":          "HandleRequest ok\n",
+		".hidden/notes.txt": "HandleRequest\n",
+		".goclaw/state.txt": "HandleRequest\n",
+		"vendor/lib/x.go":   "func HandleRequest() {}\n",
+	})
+	if err := os.WriteFile(filepath.Join(ws, "blob.dat"), []byte("HandleRequest\x00\x01"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return ws, WithToolWorkspace(context.Background(), ws)
+}
+
+func TestSearchFilesContentRespectsIgnores(t *testing.T) {
+	ws, ctx := newSearchWorkspace(t)
+	tool := NewSearchFilesTool(ws, true)
+	tool.DenyPaths(".goclaw")
+
+	res := tool.Execute(ctx, map[string]any{"pattern": `HandleRequest\(`, "include_hidden": true})
+	if res.IsError {
+		t.Fatalf("result = %s", res.ForLLM)
+	}
+	for _, want := range []string{"api/handler.go:4:func HandleRequest() {}", "main.go:4:\tHandleRequest()", "vendor/lib/x.go:1:"} {
+		if !strings.Contains(res.ForLLM, want) {
+			t.Errorf("missing %q:\n%s", want, res.ForLLM)
+		}
+	}
+	for _, absent := range []string{"build/", "api/gen/", ".goclaw", "blob.dat", "debug.log"} {
+		if strings.Contains(res.ForLLM, absent) {
+			t.Errorf("unexpected %q:\n%s", absent, res.ForLLM)
+		}
+	}
+
+	res = tool.Execute(ctx, map[string]any{"pattern": "HandleRequest", "output_mode": "files_with_matches"})
+	if !strings.Contains(res.ForLLM, "keep.log") || strings.Contains(res.ForLLM, ".hidden") {
+		t.Errorf("negated ignore / hidden handling:\n%s", res.ForLLM)
+	}
+
+	res = tool.Execute(ctx, map[string]any{"pattern": "HandleRequest", "no_ignore": true, "output_mode": "files_with_matches"})
+	for _, want := range []string{"build/out.go", "api/gen/stub.go", "debug.log"} {
+		if !strings.Contains(res.ForLLM, want) {
+			t.Errorf("no_ignore missing %q:\n%s", want, res.ForLLM)
+		}
+	}
+}
+
+func TestSearchFilesGlobsAndModes(t *testing.T) {
+	ws, ctx := newSearchWorkspace(t)
+	tool := NewSearchFilesTool(ws, true)
+
+	res := tool.Execute(ctx, map[string]any{"pattern": "handlerequest", "ignore_case": true, "glob": []any{"*.{go,ts}", "!vendor/"}, "output_mode": "count"})
+	if res.IsError {
+		t.Fatalf("result = %s", res.ForLLM)
+	}
+	want := "api/handler.go:2\napi/handler.ts:1\nmain.go:1\n\n[4 matches in 3 files]"
+	if res.ForLLM != want {
+		t.Errorf("count output:\n%s\nwant:\n%s", res.ForLLM, want)
+	}
+
+	// Glob without a pattern lists files.
+	res = tool.Execute(ctx, map[string]any{"glob": "api/*.go,*.ts"})
+	if res.ForLLM != "api/handler.go\napi/handler.ts\n\n[2 files]" {
+		t.Errorf("glob listing:\n%s", res.ForLLM)
+	}
+}
+
+func TestSearchFilesContextAndCaps(t *testing.T) {
+	ws := t.TempDir()
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, "line")
+	}
+	lines[4], lines[6], lines[15] = "hit a", "hit b", "hit c"
+	writeTestFiles(t, ws, map[string]string{"f.txt": strings.Join(lines, "\n") + "\n"})
+	tool := NewSearchFilesTool(ws, true)
+	ctx := WithToolWorkspace(context.Background(), ws)
+
+	res := tool.Execute(ctx, map[string]any{"pattern": "hit", "context": 1.0})
+	want := "f.txt-4-line\nf.txt:5:hit a\nf.txt-6-line\nf.txt:7:hit b\nf.txt-8-line\n--\nf.txt-15-line\nf.txt:16:hit c\nf.txt-17-line\n\n[3 matches in 1 files]"
+	if res.ForLLM != want {
+		t.Errorf("context output:\n%s\nwant:\n%s", res.ForLLM, want)
+	}
+
+	res = tool.Execute(ctx, map[string]any{"pattern": "hit", "max_results": 2.0})
+	if strings.Contains(res.ForLLM, "hit c") || !strings.Contains(res.ForLLM, "results truncated") {
+		t.Errorf("cap output:\n%s", res.ForLLM)
+	}
+
+	res = tool.Execute(ctx, map[string]any{"pattern": "a.b", "fixed_strings": true})
+	if !strings.HasPrefix(res.ForLLM, "No matches found") {
+		t.Errorf("fixed_strings output:\n%s", res.ForLLM)
+	}
+}
+
+func TestSearchFilesPathChecks(t *testing.T) {
+	ws, ctx := newSearchWorkspace(t)
+	tool := NewSearchFilesTool(ws, true)
+	tool.DenyPaths(".goclaw")
+
+	cases := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"pattern": "x", "path": ".goclaw"}, "restricted"},
+		{map[string]any{"pattern": "x", "path": "../"}, "access denied"},
+		{map[string]any{"pattern": "("}, "invalid pattern"},
+		{map[string]any{}, "pattern or glob is required"},
+	}
+	for _, tc := range cases {
+		res := tool.Execute(ctx, tc.args)
+		if !res.IsError || !strings.Contains(res.ForLLM, tc.want) {
+			t.Errorf("args %v: result = %q, want %q", tc.args, res.ForLLM, tc.want)
+		}
+	}
+
+	// Searching inside a subdirectory still honours the root .gitignore.
+	res := tool.Execute(ctx, map[string]any{"pattern": "HandleRequest", "path": "api", "output_mode": "files_with_matches"})
+	if !strings.Contains(res.ForLLM, "handler.go") || strings.Contains(res.ForLLM, "trace.log") {
+		t.Errorf("parent .gitignore ignored:\n%s", res.ForLLM)
+	}
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	cases := []struct {
+		glob, path string
+		want       bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "a/main.go", false},
+		{"src/**/*.ts", "src/a/b/c.ts", true},
+		{"src/**/*.ts", "src/c.ts", true},
+		{"**/test", "a/b/test", true},
+		{"a/**", "a/b/c", true},
+		{"*.{ts,tsx}", "x.tsx", true},
+		{"file[0-9].txt", "file7.txt", true},
+		{"file[!0-9].txt", "file7.txt", false},
+		{`\!bang`, "!bang", true},
+	}
+	for _, tc := range cases {
+		re, err := globToRegexp(tc.glob)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.glob, err)
+		}
+		if got := re.MatchString(tc.path); got != tc.want {
+			t.Errorf("%s ~ %s = %v, want %v", tc.glob, tc.path, got, tc.want)
+		}
+	}
+}
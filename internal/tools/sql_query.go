@@ -0,0 +1,407 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	sqlQueryDefaultRows    = 200
+	sqlQueryMaxRows        = 1000
+	sqlQueryDefaultTimeout = 30 * time.Second
+	sqlQueryMaxTimeout     = 120 * time.Second
+	sqlQueryMaxChars       = 30000
+)
+
+// SQLQueryTool runs read-only SQL against Postgres databases reached through
+// granted secure CLI credentials, or SQLite files in the workspace. Queries
+// run in a read-only transaction with a statement timeout and return typed
+// rows as JSON.
+type SQLQueryTool struct {
+	workspace      string
+	secureCLIStore store.SecureCLIStore // nil = SQLite only
+}
+
+func NewSQLQueryTool(workspace string) *SQLQueryTool {
+	return &SQLQueryTool{workspace: workspace}
+}
+
+// SetSecureCLIStore enables Postgres connections backed by secure CLI credentials.
+func (t *SQLQueryTool) SetSecureCLIStore(s store.SecureCLIStore) {
+	t.secureCLIStore = s
+}
+
+func (t *SQLQueryTool) Name() string { return "sql_query" }
+
+func (t *SQLQueryTool) Description() string {
+	return "Run read-only SQL against a Postgres database (connection=<granted credential name>) or a SQLite file in the workspace (database=<path>). " +
+		"Writes are rejected. Use action=list_tables and action=describe_schema to explore before querying. " +
+		"Results are JSON rows with column types, capped by max_rows. Pass values via params ($1, $2 for Postgres; ? for SQLite) instead of inlining them."
+}
+
+func (t *SQLQueryTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"query", "list_tables", "describe_schema"},
+				"description": "query (default) runs sql; list_tables lists tables and views; describe_schema lists columns",
+			},
+			"connection": map[string]any{
+				"type":        "string",
+				"description": "Postgres connection: name of a database credential granted to this agent",
+			},
+			"database": map[string]any{
+				"type":        "string",
+				"description": "SQLite database file path relative to the workspace (instead of connection)",
+			},
+			"sql": map[string]any{
+				"type":        "string",
+				"description": "One read-only statement (SELECT, WITH, EXPLAIN, VALUES, SHOW)",
+			},
+			"params": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{},
+				"description": "Positional query parameters",
+			},
+			"table": map[string]any{
+				"type":        "string",
+				"description": "describe_schema: limit to one table (Postgres accepts schema.table); empty = all tables",
+			},
+			"max_rows": map[string]any{
+				"type":        "number",
+				"description": "Maximum rows to return (default 200, max 1000)",
+			},
+			"timeout": map[string]any{
+				"type":        "number",
+				"description": "Statement timeout in seconds (default 30, max 120)",
+			},
+		},
+	}
+}
+
+// sqlQuerySettings is the "sql_query" entry of builtin tool settings.
+type sqlQuerySettings struct {
+	MaxRows        int `json:"max_rows,omitempty"`
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+func (t *SQLQueryTool) settings(ctx context.Context) (maxRows int, timeout time.Duration) {
+	maxRows, timeout = sqlQueryDefaultRows, sqlQueryDefaultTimeout
+	if settings := BuiltinToolSettingsFromCtx(ctx); settings != nil {
+		if raw, ok := settings["sql_query"]; ok && len(raw) > 0 {
+			var s sqlQuerySettings
+			if err := json.Unmarshal(raw, &s); err != nil {
+				slog.Warn("sql_query: invalid settings, using defaults", "error", err)
+			} else {
+				if s.MaxRows > 0 {
+					maxRows = s.MaxRows
+				}
+				if s.TimeoutSeconds > 0 {
+					timeout = time.Duration(s.TimeoutSeconds) * time.Second
+				}
+			}
+		}
+	}
+	return min(maxRows, sqlQueryMaxRows), min(timeout, sqlQueryMaxTimeout)
+}
+
+func (t *SQLQueryTool) Execute(ctx context.Context, args map[string]any) *Result {
+	action, _ := args["action"].(string)
+	if action == "" {
+		action = "query"
+	}
+	connection, _ := args["connection"].(string)
+	database, _ := args["database"].(string)
+	if (connection == "") == (database == "") {
+		return ErrorResult("set exactly one of connection (Postgres) or database (SQLite file)")
+	}
+	maxRows, timeout := t.settings(ctx)
+	if v, ok := args["max_rows"].(float64); ok && v >= 1 {
+		maxRows = min(int(v), sqlQueryMaxRows)
+	}
+	if v, ok := args["timeout"].(float64); ok && v > 0 {
+		timeout = min(time.Duration(v*float64(time.Second)), sqlQueryMaxTimeout)
+	}
+
+	var query string
+	var params []any
+	switch action {
+	case "query":
+		query, _ = args["sql"].(string)
+		if strings.TrimSpace(query) == "" {
+			return ErrorResult("sql is required")
+		}
+		if err := checkReadOnlySQL(query); err != nil {
+			return ErrorResult(err.Error())
+		}
+		params, _ = args["params"].([]any)
+	case "list_tables", "describe_schema":
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action %q (use query, list_tables or describe_schema)", action))
+	}
+
+	ctx = WithScrubBag(ctx)
+	var conn *sqlConn
+	var err error
+	if connection != "" {
+		conn, err = t.openPostgres(ctx, connection)
+	} else {
+		conn, err = t.openSQLite(ctx, database)
+	}
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	defer conn.db.Close()
+	AddScrubValuesCtx(ctx, conn.secrets...)
+
+	switch action {
+	case "list_tables":
+		query = listTablesSQL[conn.dialect]
+	case "describe_schema":
+		table, _ := args["table"].(string)
+		query, params = describeSchemaSQL(conn.dialect, table)
+		maxRows = sqlQueryMaxRows
+	}
+
+	res, err := runReadOnlyQuery(ctx, conn, query, params, maxRows, timeout)
+	if err != nil {
+		return ErrorResult(ScrubCredentialsCtx(ctx, fmt.Sprintf("query failed: %v", err)))
+	}
+	return SilentResult(ScrubCredentialsCtx(ctx, res.format()))
+}
+
+// readOnlyStatements are the statement keywords sql_query accepts. The
+// read-only transaction is the real guard; this gives a clear error up front
+// and rejects statements (COMMIT, SET, PRAGMA, ATTACH) that could end or
+// loosen it.
+var readOnlyStatements = map[string]bool{
+	"SELECT": true, "WITH": true, "EXPLAIN": true, "VALUES": true, "TABLE": true, "SHOW": true,
+}
+
+// checkReadOnlySQL requires a single statement starting with a read-only
+// keyword. Comments and quoted text are skipped when looking for a second
+// statement.
+func checkReadOnlySQL(query string) error {
+	body := stripSQLComments(query)
+	first := strings.ToUpper(strings.TrimLeft(body, " \t\r\n("))
+	if i := strings.IndexFunc(first, func(r rune) bool { return !('A' <= r && r <= 'Z') }); i >= 0 {
+		first = first[:i]
+	}
+	if !readOnlyStatements[first] {
+		return fmt.Errorf("only read-only statements are allowed (SELECT, WITH, EXPLAIN, VALUES, TABLE, SHOW); got %q", first)
+	}
+	if strings.Contains(strings.TrimRight(strings.TrimSpace(body), ";"), ";") {
+		return fmt.Errorf("only one statement per call is allowed")
+	}
+	return nil
+}
+
+// stripSQLComments removes -- and /* */ comments and blanks out string and
+// quoted-identifier contents so keyword and ';' checks ignore them.
+func stripSQLComments(query string) string {
+	var b strings.Builder
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+			b.WriteByte(' ')
+		case c == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			i += end + 3
+			b.WriteByte(' ')
+		case c == '\'' || c == '"':
+			b.WriteByte(c)
+			for i++; i < len(query); i++ {
+				if query[i] == c {
+					if i+1 < len(query) && query[i+1] == c { // doubled quote escape
+						i++
+						continue
+					}
+					break
+				}
+			}
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+var listTablesSQL = map[string]string{
+	sqlDialectPostgres: `SELECT table_schema, table_name, table_type
+FROM information_schema.tables
+WHERE table_schema NOT IN ('pg_catalog', 'information_schema')
+ORDER BY table_schema, table_name`,
+	sqlDialectSQLite: `SELECT name AS table_name, type AS table_type
+FROM sqlite_master
+WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%'
+ORDER BY name`,
+}
+
+// describeSchemaSQL lists columns with type, nullability, default and primary
+// key membership, optionally for one table.
+func describeSchemaSQL(dialect, table string) (string, []any) {
+	if dialect == sqlDialectSQLite {
+		q := `SELECT m.name AS table_name, p.name AS column_name, p.type AS data_type,
+  p."notnull" = 0 AS nullable, p.dflt_value AS column_default, p.pk > 0 AS primary_key
+FROM sqlite_master m JOIN pragma_table_info(m.name) p
+WHERE m.type IN ('table', 'view') AND m.name NOT LIKE 'sqlite_%'`
+		if table == "" {
+			return q + "\nORDER BY m.name, p.cid", nil
+		}
+		return q + " AND m.name = ?\nORDER BY m.name, p.cid", []any{table}
+	}
+	q := `SELECT c.table_schema, c.table_name, c.column_name, c.data_type,
+  c.is_nullable = 'YES' AS nullable, c.column_default,
+  EXISTS (
+    SELECT 1 FROM information_schema.table_constraints tc
+    JOIN information_schema.key_column_usage k
+      ON k.constraint_name = tc.constraint_name AND k.table_schema = tc.table_schema AND k.table_name = tc.table_name
+    WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_schema = c.table_schema
+      AND tc.table_name = c.table_name AND k.column_name = c.column_name
+  ) AS primary_key
+FROM information_schema.columns c
+WHERE c.table_schema NOT IN ('pg_catalog', 'information_schema')`
+	order := "\nORDER BY c.table_schema, c.table_name, c.ordinal_position"
+	if table == "" {
+		return q + order, nil
+	}
+	if schema, name, ok := strings.Cut(table, "."); ok {
+		return q + " AND c.table_schema = $1 AND c.table_name = $2" + order, []any{schema, name}
+	}
+	return q + " AND c.table_name = $1" + order, []any{table}
+}
+
+// sqlResult is a typed, row-capped query result.
+type sqlResult struct {
+	columns   []sqlColumn
+	rows      [][]any
+	truncated bool
+}
+
+type sqlColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+// runReadOnlyQuery runs query in a read-only transaction that is always
+// rolled back. Postgres also gets a server-side statement_timeout so a
+// cancelled client cannot leave the query running.
+func runReadOnlyQuery(ctx context.Context, conn *sqlConn, query string, params []any, maxRows int, timeout time.Duration) (*sqlResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	tx, err := conn.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if conn.dialect == sqlDialectPostgres {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.Milliseconds())); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	res := &sqlResult{}
+	for _, ct := range types {
+		res.columns = append(res.columns, sqlColumn{Name: ct.Name(), Type: strings.ToLower(ct.DatabaseTypeName())})
+	}
+
+	size := 0
+	for rows.Next() {
+		if len(res.rows) == maxRows {
+			res.truncated = true
+			break
+		}
+		values := make([]any, len(types))
+		ptrs := make([]any, len(types))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			values[i] = sqlJSONValue(v)
+		}
+		line, _ := json.Marshal(values)
+		if size += len(line); size > sqlQueryMaxChars && len(res.rows) > 0 {
+			res.truncated = true
+			break
+		}
+		res.rows = append(res.rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// sqlJSONValue converts a scanned driver value into a JSON-friendly one,
+// keeping numbers and booleans typed.
+func sqlJSONValue(v any) any {
+	switch x := v.(type) {
+	case []byte:
+		if utf8.Valid(x) {
+			return string(x)
+		}
+		return fmt.Sprintf("<%d bytes binary>", len(x))
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	default:
+		return x
+	}
+}
+
+// format renders the result as JSON with one row per line, which stays
+// readable for wide tables and cheap for the model to scan.
+func (r *sqlResult) format() string {
+	var b bytes.Buffer
+	cols, _ := json.Marshal(r.columns)
+	b.WriteString("{\n  \"columns\": ")
+	b.Write(cols)
+	b.WriteString(",\n  \"rows\": [")
+	for i, row := range r.rows {
+		line, _ := json.Marshal(row)
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString("\n    ")
+		b.Write(line)
+	}
+	if len(r.rows) > 0 {
+		b.WriteString("\n  ")
+	}
+	fmt.Fprintf(&b, "],\n  \"row_count\": %d,\n  \"truncated\": %t\n}", len(r.rows), r.truncated)
+	if r.truncated {
+		fmt.Fprintf(&b, "\n\n[More rows available; results were capped at %d rows. Add filters, LIMIT/OFFSET or aggregates.]", len(r.rows))
+	}
+	return b.String()
+}
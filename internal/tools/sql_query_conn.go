@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	sqlDialectPostgres = "postgres"
+	sqlDialectSQLite   = "sqlite"
+)
+
+// sqlConn is a database handle opened for a single sql_query call.
+type sqlConn struct {
+	db      *sql.DB
+	dialect string
+	secrets []string // values to scrub from output
+}
+
+// openPostgres connects with a named secure CLI credential. The credential is
+// either a pg_password_file typed credential (the psql adapter's shape) or env
+// vars: DATABASE_URL, or PGHOST/PGPORT/PGDATABASE/PGUSER/PGPASSWORD/PGSSLMODE.
+func (t *SQLQueryTool) openPostgres(ctx context.Context, name string) (*sqlConn, error) {
+	if t.secureCLIStore == nil {
+		return nil, fmt.Errorf("database connections are not available: no credential store configured")
+	}
+	name = normalizeBinaryName(name)
+	cred, err := lookupGrantedCredential(ctx, t.secureCLIStore, name)
+	if err != nil {
+		slog.Warn("sql_query: connection lookup failed", "connection", name, "agent_id", store.AgentIDFromContext(ctx), "error", err)
+		return nil, fmt.Errorf("connection %q could not be loaded", name)
+	}
+	if cred == nil {
+		return nil, fmt.Errorf("connection %q not found or not granted to this agent", name)
+	}
+
+	var dsn string
+	if uc := userCredFromBinary(ctx, cred); uc != nil && uc.CredentialType != nil && *uc.CredentialType == "pg_password_file" {
+		pg, err := decodePgCredential(uc.EncryptedEnv)
+		if err != nil {
+			return nil, fmt.Errorf("connection %q: %v", name, err)
+		}
+		dsn, err = postgresDSN(pg, "")
+		if err != nil {
+			return nil, fmt.Errorf("connection %q: %v", name, err)
+		}
+	} else {
+		env, err := mergeCredentialedEnv(cred)
+		if err != nil {
+			return nil, fmt.Errorf("connection %q: invalid env: %v", name, err)
+		}
+		dsn, err = postgresDSNFromEnv(env)
+		if err != nil {
+			return nil, fmt.Errorf("connection %q: %v", name, err)
+		}
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("connection %q: %v", name, err)
+	}
+	db.SetMaxOpenConns(1)
+	conn := &sqlConn{db: db, dialect: sqlDialectPostgres, secrets: []string{dsn}}
+	if u, err := url.Parse(dsn); err == nil && u.User != nil {
+		if pw, ok := u.User.Password(); ok {
+			conn.secrets = append(conn.secrets, pw, url.QueryEscape(pw))
+		}
+	}
+	return conn, nil
+}
+
+// postgresDSNFromEnv builds a DSN from credential env vars.
+func postgresDSNFromEnv(env map[string]string) (string, error) {
+	if raw := strings.TrimSpace(env["DATABASE_URL"]); raw != "" {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			return "", fmt.Errorf("DATABASE_URL must be a postgres:// URL")
+		}
+		pw, _ := u.User.Password()
+		return postgresDSN(&pgPasswordFile{
+			Host:     u.Hostname(),
+			Port:     u.Port(),
+			Database: strings.TrimPrefix(u.Path, "/"),
+			User:     u.User.Username(),
+			Password: pw,
+		}, u.Query().Get("sslmode"))
+	}
+	return postgresDSN(&pgPasswordFile{
+		Host:     env["PGHOST"],
+		Port:     env["PGPORT"],
+		Database: env["PGDATABASE"],
+		User:     env["PGUSER"],
+		Password: env["PGPASSWORD"],
+	}, env["PGSSLMODE"])
+}
+
+// postgresDSN renders connection settings as a URL. Every field must be set
+// explicitly: pgx fills gaps from the gateway's own PG* env and pgpass file,
+// which would let a partial credential reach the gateway's database.
+func postgresDSN(pg *pgPasswordFile, sslMode string) (string, error) {
+	var missing []string
+	for _, f := range []struct{ name, value string }{
+		{"host", pg.Host}, {"database", pg.Database}, {"user", pg.User}, {"password", pg.Password},
+	} {
+		if strings.TrimSpace(f.value) == "" {
+			missing = append(missing, f.name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+	port := pg.Port
+	if port == "" {
+		port = "5432"
+	}
+	if sslMode == "" {
+		sslMode = "prefer"
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(pg.User, pg.Password),
+		Host:     net.JoinHostPort(pg.Host, port),
+		Path:     "/" + pg.Database,
+		RawQuery: url.Values{"sslmode": {sslMode}, "application_name": {"goclaw-sql-query"}}.Encode(),
+	}
+	return u.String(), nil
+}
+
+// openSQLite opens a SQLite file inside the workspace read-only.
+func (t *SQLQueryTool) openSQLite(ctx context.Context, path string) (*sqlConn, error) {
+	workspace := ToolWorkspaceFromCtx(ctx)
+	if workspace == "" {
+		workspace = t.workspace
+	}
+	resolved, err := resolvePath(path, workspace, effectiveRestrict(ctx, true))
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("database file not found: %s", path)
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, fmt.Errorf("not a database file: %s", path)
+	}
+	// A file: URI so SQLite honours mode=ro; query_only also blocks writes
+	// through any path the URI flags miss.
+	dsn := "file:" + (&url.URL{Path: resolved}).EscapedPath() + "?mode=ro&_pragma=query_only(1)&_pragma=busy_timeout(5000)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	return &sqlConn{db: db, dialect: sqlDialectSQLite}, nil
+}
@@ -111,6 +111,38 @@ type ChannelTenantCheckerAware interface {
 	SetChannelTenantChecker(ChannelTenantChecker)
 }
 
+// MessageEditor edits or retracts messages already delivered on behalf of
+// agent runs. Implemented by channels.Manager.
+type MessageEditor interface {
+	ListSentMessages(ctx context.Context, opts store.SentMessageListOpts) ([]store.SentMessage, error)
+	EditSentMessage(ctx context.Context, id uuid.UUID, content string) (*store.SentMessage, error)
+	RetractSentMessage(ctx context.Context, id uuid.UUID) (*store.SentMessage, error)
+}
+
+// MessageEditorAware tools can receive a message editor.
+type MessageEditorAware interface {
+	SetMessageEditor(MessageEditor)
+}
+
+// LinkedContactLister returns the channel contacts merged into the tenant
+// user whose user_id is given. Implemented by store.ContactStore.
+type LinkedContactLister func(ctx context.Context, userID string) ([]store.ChannelContact, error)
+
+// LinkedContactListerAware tools can receive a linked contact lister.
+type LinkedContactListerAware interface {
+	SetLinkedContactLister(LinkedContactLister)
+}
+
+// DMSessionKeyResolver returns the session key an inbound DM from chatID on
+// channel lands in for the given agent and user. Implemented by the gateway's
+// inbound consumer so tools that write into a DM session use the same key.
+type DMSessionKeyResolver func(agentKey, channel, chatID, userID string) string
+
+// DMSessionKeyResolverAware tools can receive a DM session key resolver.
+type DMSessionKeyResolverAware interface {
+	SetDMSessionKeyResolver(DMSessionKeyResolver)
+}
+
 // ChannelAware is optionally implemented by tools that only work on specific channel types.
 // Tools implementing this are filtered out when the current channel type doesn't match.
 type ChannelAware interface {
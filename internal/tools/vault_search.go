@@ -114,6 +114,7 @@ func (t *VaultSearchTool) Execute(ctx context.Context, args map[string]any) *Res
 	if mr, ok := args["maxResults"].(float64); ok && mr > 0 {
 		opts.MaxResults = int(mr)
 	}
+	opts.RerankTopK = MemoryConfigFromCtx(ctx).RerankTopK()
 
 	results, err := t.searchSvc.Search(ctx, opts)
 	if err != nil {
@@ -102,6 +102,15 @@ type Collector struct {
 	// successful trace status write (SetTraceStatus / FinishTrace). Fires
 	// before the 5s OnFlush tick for low-latency status delivery.
 	broadcastStatus StatusBroadcaster
+
+	// recordings is pruned alongside traces (nil = no provider recordings).
+	recordings RecordingPruner
+}
+
+// RecordingPruner deletes provider call recordings older than a cutoff.
+// providers.RecordingArchive implements it.
+type RecordingPruner interface {
+	PruneOlderThan(cutoff time.Time) (int, error)
 }
 
 // NewCollector creates a new tracing collector backed by the given store.
@@ -146,6 +155,12 @@ func (c *Collector) SetStatusBroadcaster(b StatusBroadcaster) {
 	c.broadcastStatus = b
 }
 
+// SetRecordingPruner attaches the provider recording archive so its files
+// follow the trace retention window.
+func (c *Collector) SetRecordingPruner(p RecordingPruner) {
+	c.recordings = p
+}
+
 // Start begins the background flush loop and retry worker.
 //
 // NOTE: staleRecoveryLoop is intentionally NOT started. The current implementation
@@ -444,6 +459,13 @@ func (c *Collector) pruneOldTraces() {
 	if deleted > 0 {
 		slog.Info("tracing: pruned old traces", "deleted", deleted, "older_than", cutoff.Format(time.RFC3339))
 	}
+	if c.recordings != nil {
+		if n, err := c.recordings.PruneOlderThan(cutoff); err != nil {
+			slog.Warn("tracing: prune recordings failed", "error", err)
+		} else if n > 0 {
+			slog.Info("tracing: pruned provider recordings", "deleted", n)
+		}
+	}
 }
 
 func (c *Collector) flush() {
@@ -45,6 +45,9 @@ func CalculateCost(pricing *config.ModelPricing, usage *providers.Usage) float64
 	if pricing.CacheCreatePerMillion > 0 && usage.CacheCreationTokens > 0 {
 		cost += float64(usage.CacheCreationTokens) * pricing.CacheCreatePerMillion / 1_000_000
 	}
+	if usage.Batch {
+		cost *= float64(100-providers.BatchDiscountPercent) / 100
+	}
 	return cost
 }
 
@@ -2,6 +2,7 @@ package otelexport
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"time"
@@ -15,6 +16,7 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
@@ -154,6 +156,9 @@ func (e *Exporter) exportSpan(ctx context.Context, s store.SpanData) {
 	if s.AgentID != nil {
 		attrs = append(attrs, attribute.String("goclaw.agent_id", s.AgentID.String()))
 	}
+	if responseCacheHit(s.Metadata) {
+		attrs = append(attrs, attribute.Bool("goclaw.cache.hit", true))
+	}
 	if s.InputPreview != "" {
 		preview := s.InputPreview
 		if len(preview) > 500 {
@@ -243,3 +248,18 @@ func uuidToSpanID(id [16]byte) trace.SpanID {
 	copy(sid[:], id[8:16])
 	return sid
 }
+
+// responseCacheHit reports whether span metadata marks a response cache hit.
+func responseCacheHit(metadata json.RawMessage) bool {
+	if len(metadata) == 0 {
+		return false
+	}
+	var payload map[string]json.RawMessage
+	if json.Unmarshal(metadata, &payload) != nil {
+		return false
+	}
+	var section struct {
+		Hit bool `json:"hit"`
+	}
+	return json.Unmarshal(payload[providers.ResponseCacheMetadataKey], &section) == nil && section.Hit
+}
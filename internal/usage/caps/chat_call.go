@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nextlevelbuilder/goclaw/internal/providers"
@@ -19,6 +21,10 @@ type ChatOptions struct {
 	ReservationKey  string
 	Purpose         string
 	MaxOutputTokens int
+	// Batch routes the call through the provider's batch API when a batch
+	// queue is configured and the provider supports it. The call then blocks
+	// for up to ChatTimeout; use only for non-interactive work.
+	Batch bool
 }
 
 // Chat wraps Provider.Chat with the same usage-cap preflight and reconciliation
@@ -60,13 +66,53 @@ func (s *Service) Chat(ctx context.Context, provider providers.Provider, req pro
 	if err != nil {
 		return nil, err
 	}
-	resp, err := provider.Chat(scopedCtx, req)
+	resp, err := s.chatOnce(scopedCtx, provider, req, opts)
 	if reservation != nil {
 		reservation.Reconcile(scopedCtx, resp, err)
 	}
 	return resp, err
 }
 
+// Batching reports whether ChatOptions.Batch calls may go through a batch queue.
+func (s *Service) Batching() bool {
+	return s != nil && s.batch != nil
+}
+
+// ChatTimeout returns the deadline a caller should allow for Chat: the batch
+// queue's maximum wait when the call will be batched, else syncTimeout.
+func (s *Service) ChatTimeout(provider providers.Provider, opts ChatOptions, syncTimeout time.Duration) time.Duration {
+	if s.batchProvider(provider, opts) == nil {
+		return syncTimeout
+	}
+	return s.batch.MaxWait()
+}
+
+func (s *Service) batchProvider(provider providers.Provider, opts ChatOptions) providers.BatchCapable {
+	if s == nil || s.batch == nil || !opts.Batch {
+		return nil
+	}
+	bc, ok := providers.AsBatchCapable(provider)
+	if !ok {
+		return nil
+	}
+	return bc
+}
+
+// chatOnce sends req through the batch queue when opted in, retrying
+// synchronously if the batch could not be submitted.
+func (s *Service) chatOnce(ctx context.Context, provider providers.Provider, req providers.ChatRequest, opts ChatOptions) (*providers.ChatResponse, error) {
+	bc := s.batchProvider(provider, opts)
+	if bc == nil {
+		return provider.Chat(ctx, req)
+	}
+	resp, err := s.batch.Chat(ctx, bc, req)
+	if errors.Is(err, providers.ErrBatchSubmit) {
+		slog.Warn("usage cap chat: batch unavailable, calling synchronously", "provider", provider.Name(), "purpose", opts.Purpose, "error", err)
+		return provider.Chat(ctx, req)
+	}
+	return resp, err
+}
+
 func (s *Service) chatRequest(ctx context.Context, provider providers.Provider, req providers.ChatRequest, opts ChatOptions) Request {
 	tenantID := opts.TenantID
 	if tenantID == uuid.Nil {
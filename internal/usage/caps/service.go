@@ -24,6 +24,7 @@ var (
 type Service struct {
 	store     store.UsageCapStore
 	providers store.ProviderStore
+	batch     *providers.BatchQueue
 }
 
 func NewService(s store.UsageCapStore, providers store.ProviderStore) *Service {
@@ -33,6 +34,13 @@ func NewService(s store.UsageCapStore, providers store.ProviderStore) *Service {
 	return &Service{store: s, providers: providers}
 }
 
+// SetBatchQueue enables ChatOptions.Batch routing. A nil queue disables it.
+func (s *Service) SetBatchQueue(q *providers.BatchQueue) {
+	if s != nil {
+		s.batch = q
+	}
+}
+
 type Request struct {
 	TenantID        uuid.UUID
 	AgentID         uuid.UUID
@@ -222,6 +230,23 @@ func (r *Reservation) reconcile(ctx context.Context, resp *providers.ChatRespons
 	}
 }
 
+// TokenRates returns the resolved input/output price (USD per 1M tokens) for a
+// provider model, for cost-aware routing. ok is false when pricing is unknown.
+func (s *Service) TokenRates(ctx context.Context, tenantID uuid.UUID, providerName, modelID string) (input, output float64, ok bool) {
+	if s == nil {
+		return 0, 0, false
+	}
+	providerData, err := s.resolveProvider(ctx, tenantID, providerName)
+	if err != nil || providerData == nil {
+		return 0, 0, false
+	}
+	resolved, err := s.store.ResolvePricing(ctx, tenantID, providerData.ID, providerData.Name, providerData.ProviderType, modelID)
+	if err != nil || resolved == nil {
+		return 0, 0, false
+	}
+	return pricing.TokenRatesPer1M(resolved.Pricing)
+}
+
 func (s *Service) resolveProvider(ctx context.Context, tenantID uuid.UUID, name string) (*store.LLMProviderData, error) {
 	if s.providers == nil || strings.TrimSpace(name) == "" {
 		return nil, sql.ErrNoRows
@@ -240,7 +265,7 @@ func (s *Service) resolveProvider(ctx context.Context, tenantID uuid.UUID, name
 
 func ShouldEnforceProvider(providerType string, hasAPIKey bool) bool {
 	switch providerType {
-	case store.ProviderChatGPTOAuth, store.ProviderClaudeCLI, store.ProviderBailian, store.ProviderACP, store.ProviderOllama:
+	case store.ProviderChatGPTOAuth, store.ProviderClaudeCLI, store.ProviderBailian, store.ProviderACP, store.ProviderOllama, store.ProviderLlamaCpp:
 		return false
 	default:
 		return hasAPIKey
@@ -21,6 +21,7 @@ type BillableUsage struct {
 	RequestCount     int64
 	ImageCount       int64
 	WebSearchCount   int64
+	Batch            bool // billed at batch rates; token costs get providers.BatchDiscountPercent off
 }
 
 func FromProviderUsage(u *providers.Usage) BillableUsage {
@@ -45,6 +46,7 @@ func FromProviderUsage(u *providers.Usage) BillableUsage {
 		RequestCount:     int64(u.RequestCount),
 		ImageCount:       int64(u.ImageCount),
 		WebSearchCount:   int64(u.WebSearchCount),
+		Batch:            u.Batch,
 	}
 }
 
@@ -88,6 +90,10 @@ func CostMicros(fields store.UsagePricingFields, usage BillableUsage) (int64, er
 	if err := add("cache_write", fields.CacheWrite, usage.CacheWriteTokens); err != nil {
 		return 0, err
 	}
+	if usage.Batch && total > 0 {
+		// Round the discounted token cost up, like per-class micros.
+		total = (total*(100-providers.BatchDiscountPercent) + 99) / 100
+	}
 	if err := add("request", fields.Request, usage.RequestCount); err != nil {
 		return 0, err
 	}
@@ -117,3 +123,22 @@ func microsForUnits(price string, units int64) (int64, error) {
 	}
 	return q.Int64(), nil
 }
+
+// TokenRatesPer1M converts per-token decimal prices to USD per 1M tokens for
+// ranking. ok is false unless both input and output prices parse.
+func TokenRatesPer1M(fields store.UsagePricingFields) (input, output float64, ok bool) {
+	perMillion := func(price *string) (float64, bool) {
+		if price == nil {
+			return 0, false
+		}
+		r, valid := new(big.Rat).SetString(strings.TrimSpace(*price))
+		if !valid {
+			return 0, false
+		}
+		f, _ := r.Mul(r, big.NewRat(1_000_000, 1)).Float64()
+		return f, true
+	}
+	input, inOK := perMillion(fields.Input)
+	output, outOK := perMillion(fields.Output)
+	return input, output, inOK && outOK
+}
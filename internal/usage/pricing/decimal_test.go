@@ -37,6 +37,21 @@ func TestCostMicrosTokenAndFlatDimensions(t *testing.T) {
 	}
 }
 
+func TestCostMicrosBatchDiscountsTokensOnly(t *testing.T) {
+	got, err := CostMicros(store.UsagePricingFields{
+		Input:   strp("0.000001"),
+		Output:  strp("0.000002"),
+		Request: strp("0.01"),
+	}, FromProviderUsage(&providers.Usage{PromptTokens: 1001, CompletionTokens: 500, RequestCount: 1, Batch: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// (1001 + 1000) / 2 rounded up, plus the undiscounted request fee.
+	if want := int64(1001 + 10_000); got != want {
+		t.Fatalf("cost micros = %d, want %d", got, want)
+	}
+}
+
 func TestCostMicrosMissingRequiredPrice(t *testing.T) {
 	_, err := CostMicros(store.UsagePricingFields{Input: strp("0.000001")}, BillableUsage{
 		InputTokens:  1,
@@ -90,7 +90,7 @@ func (w *EnrichWorker) classifyLinks(ctx context.Context, provider providers.Pro
 			parsed, err := parseClassifyResponse(raw, len(chunk))
 			if err != nil {
 				slog.Warn("vault.classify: parse_failed_first", "doc", sourceDocID, "err", err, "raw_len", len(raw), "raw", raw)
-				hint := fmt.Sprintf("\n\nPrevious response was invalid JSON (error: %s). Output ONLY a valid JSON array.", err.Error())
+				hint := fmt.Sprintf("\n\nPrevious response was invalid JSON (error: %s). Output ONLY the valid JSON object.", err.Error())
 				raw2, err2 := w.callClassifyWithRetry(ctx, provider, model, system, user+hint)
 				if err2 != nil {
 					slog.Warn("vault.classify: retry_parse_failed", "doc", sourceDocID, "err", err2)
@@ -198,7 +198,8 @@ func (w *EnrichWorker) callClassifyWithRetry(ctx context.Context, provider provi
 			{Role: "system", Content: system},
 			{Role: "user", Content: user},
 		},
-		Model:   model,
-		Options: map[string]any{"max_tokens": classifyMaxTokens, "temperature": classifyTemperature},
+		Model:          model,
+		Options:        map[string]any{"max_tokens": classifyMaxTokens, "temperature": classifyTemperature},
+		ResponseFormat: providers.NewJSONSchemaFormat("vault_link_classification", classifyResponseSchema),
 	})
 }
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
 )
 
 // classifyResult is a single LLM classification output for a candidate doc.
@@ -25,13 +27,34 @@ const classifySystemPrompt = `You classify relationships between documents in a
 
 ## Rules
 - Respond with EXACTLY one JSON entry per candidate
-- Output ONLY raw JSON array, no markdown, no explanation
+- Output ONLY a raw JSON object with a "results" array, no markdown, no explanation
 - Use SKIP when no meaningful relationship exists
 - Prefer specific types over "related"
 - ctx MUST be under 50 characters (5-8 words max)
 
 ## Output Format
-[{"idx":1,"type":"reference","ctx":"cites OAuth spec"},{"idx":2,"type":"SKIP"},{"idx":3,"type":"extends","ctx":"adds error handling"},{"idx":4,"type":"SKIP"},{"idx":5,"type":"depends_on","ctx":"needs auth module"}]`
+{"results":[{"idx":1,"type":"reference","ctx":"cites OAuth spec"},{"idx":2,"type":"SKIP","ctx":""},{"idx":3,"type":"extends","ctx":"adds error handling"},{"idx":4,"type":"SKIP","ctx":""},{"idx":5,"type":"depends_on","ctx":"needs auth module"}]}`
+
+// classifyResponseSchema is sent as ChatRequest.ResponseFormat. Structured-output
+// APIs require an object at the root, so results are wrapped in {"results": [...]}.
+var classifyResponseSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"results": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"idx":  map[string]any{"type": "integer"},
+					"type": map[string]any{"type": "string"},
+					"ctx":  map[string]any{"type": "string"},
+				},
+				"required": []any{"idx", "type", "ctx"},
+			},
+		},
+	},
+	"required": []any{"results"},
+}
 
 // buildClassifyPrompt formats the system and user prompts for classify LLM call.
 func buildClassifyPrompt(source classifyDoc, candidates []classifyDoc) (system, user string) {
@@ -46,16 +69,22 @@ func buildClassifyPrompt(source classifyDoc, candidates []classifyDoc) (system,
 }
 
 // parseClassifyResponse parses LLM JSON output into classify results.
+// Accepts both the {"results": [...]} object (structured output) and a bare array
+// (legacy prompt output from providers without native schema support).
 // Uses partial success model: invalid entries filtered silently, error only on total unmarshal failure.
 func parseClassifyResponse(raw string, count int) ([]classifyResult, error) {
-	raw = strings.TrimSpace(raw)
-	raw = strings.TrimPrefix(raw, "```json")
-	raw = strings.TrimPrefix(raw, "```")
-	raw = strings.TrimSuffix(raw, "```")
-	raw = strings.TrimSpace(raw)
+	raw = providers.ExtractJSON(raw)
 
 	var results []classifyResult
-	if err := json.Unmarshal([]byte(raw), &results); err != nil {
+	if strings.HasPrefix(raw, "{") {
+		var wrapped struct {
+			Results []classifyResult `json:"results"`
+		}
+		if err := json.Unmarshal([]byte(raw), &wrapped); err != nil {
+			return nil, fmt.Errorf("json unmarshal: %w", err)
+		}
+		results = wrapped.Results
+	} else if err := json.Unmarshal([]byte(raw), &results); err != nil {
 		return nil, fmt.Errorf("json unmarshal: %w", err)
 	}
 
@@ -28,6 +28,7 @@ const (
 	enrichSimilarityLimit   = 10
 	enrichSimilarityMin     = 0.7
 	enrichMaxConcurrent     = 3    // max concurrent batch summarize calls across chunks
+	enrichMaxDetached       = 8    // max events awaiting batched LLM results in batch mode
 	enrichBatchSize         = 5    // docs per enrichment chunk (1 LLM call per chunk)
 	enrichBatchItemMaxRunes = 3000 // per-file content limit in batch summarize
 	enrichMaxRetries        = 3    // shared retry count for LLM calls (summarize + classify)
@@ -68,7 +69,12 @@ func RegisterEnrichWorker(deps EnrichWorkerDeps) (func(), *EnrichProgress, *Enri
 		progress:      progress,
 		cancelFuncs:   &sync.Map{},
 	}
-	unsub := deps.EventBus.Subscribe(eventbus.EventVaultDocUpserted, w.Handle)
+	handle := w.Handle
+	if deps.UsageCaps.Batching() {
+		// Batched summaries can take hours; keep them off the bus workers.
+		handle = eventbus.Detached(enrichMaxDetached, handle)
+	}
+	unsub := deps.EventBus.Subscribe(eventbus.EventVaultDocUpserted, handle)
 	return unsub, progress, w
 }
 
@@ -526,12 +532,14 @@ func (w *EnrichWorker) chatWithRetry(ctx context.Context, provider providers.Pro
 			case <-time.After(enrichRetryBackoffs[attempt]):
 			}
 		}
-		cctx, cancel := context.WithTimeout(ctx, enrichRetryTimeouts[attempt])
-		resp, err := w.usageCaps.Chat(cctx, provider, req, usagecaps.ChatOptions{
+		opts := usagecaps.ChatOptions{
 			ModelID:         req.Model,
 			Purpose:         logPrefix,
 			MaxOutputTokens: 4096,
-		})
+			Batch:           true,
+		}
+		cctx, cancel := context.WithTimeout(ctx, w.usageCaps.ChatTimeout(provider, opts, enrichRetryTimeouts[attempt]))
+		resp, err := w.usageCaps.Chat(cctx, provider, req, opts)
 		cancel()
 		if err != nil {
 			lastErr = err
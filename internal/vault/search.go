@@ -8,6 +8,7 @@ import (
 	"sort"
 	"sync"
 
+	"github.com/nextlevelbuilder/goclaw/internal/memory"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
@@ -47,6 +48,7 @@ type UnifiedSearchOptions struct {
 	MaxResults   int
 	MinScore     float64
 	Weights      SearchWeights
+	RerankTopK   int // > 0 = rerank merged results and keep this many (needs SetReranker)
 }
 
 // UnifiedSearchResult is a normalized result from any search source.
@@ -58,6 +60,8 @@ type UnifiedSearchResult struct {
 	Score   float64
 	DocType string
 	Snippet string
+
+	rerankText string // text scored by the reranker (title + summary/snippet)
 }
 
 // VaultSearchService coordinates fan-out search across all registered stores.
@@ -65,6 +69,7 @@ type VaultSearchService struct {
 	vaultStore    store.VaultStore          // may be nil if vault disabled
 	episodicStore store.EpisodicStore       // may be nil
 	kgStore       store.KnowledgeGraphStore // may be nil
+	reranker      store.Reranker            // may be nil (reranking disabled)
 }
 
 // NewVaultSearchService creates a search service. Any store may be nil (skipped).
@@ -72,6 +77,11 @@ func NewVaultSearchService(vs store.VaultStore, es store.EpisodicStore, kg store
 	return &VaultSearchService{vaultStore: vs, episodicStore: es, kgStore: kg}
 }
 
+// SetReranker enables second-stage reranking for searches with RerankTopK set.
+func (s *VaultSearchService) SetReranker(r store.Reranker) {
+	s.reranker = r
+}
+
 // Search executes parallel fan-out search, normalizes scores, applies weights, and deduplicates.
 func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptions) ([]UnifiedSearchResult, error) {
 	if opts.MaxResults <= 0 {
@@ -80,6 +90,11 @@ func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptio
 	if opts.Weights == (SearchWeights{}) {
 		opts.Weights = DefaultSearchWeights()
 	}
+	rerankTopK := 0
+	if s.reranker != nil && opts.RerankTopK > 0 {
+		rerankTopK = min(opts.RerankTopK, opts.MaxResults)
+		opts.MaxResults = memory.RerankCandidateCount(rerankTopK)
+	}
 
 	var mu sync.Mutex
 	var wg sync.WaitGroup
@@ -117,6 +132,8 @@ func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptio
 					Score:   r.Score,
 					DocType: r.Document.DocType,
 					Snippet: r.Document.Path, // path as snippet fallback
+
+					rerankText: r.Document.Title + "\n" + r.Document.Summary,
 				})
 			}
 			mu.Lock()
@@ -145,6 +162,8 @@ func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptio
 					Score:   r.Score,
 					DocType: "episodic",
 					Snippet: r.L0Abstract,
+
+					rerankText: r.L0Abstract,
 				})
 			}
 			mu.Lock()
@@ -170,6 +189,8 @@ func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptio
 					Score:   e.Confidence,
 					DocType: e.EntityType,
 					Snippet: e.Description,
+
+					rerankText: e.Name + "\n" + e.Description,
 				})
 			}
 			mu.Lock()
@@ -204,6 +225,12 @@ func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptio
 		return all[i].Score > all[j].Score
 	})
 
+	if rerankTopK > 0 {
+		return memory.ApplyRerank(ctx, s.reranker, opts.Query, all, rerankTopK,
+			func(r *UnifiedSearchResult) string { return r.rerankText },
+			func(r *UnifiedSearchResult, score float64) { r.Score = score }), nil
+	}
+
 	// Cap at maxResults
 	if len(all) > opts.MaxResults {
 		all = all[:opts.MaxResults]
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS sent_messages (
+    id         UUID PRIMARY KEY,
+    tenant_id  UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    run_id     TEXT NOT NULL,
+    agent_id   UUID REFERENCES agents(id) ON DELETE SET NULL,
+    channel    TEXT NOT NULL,
+    chat_id    TEXT NOT NULL,
+    message_id TEXT NOT NULL,
+    preview    TEXT,
+    status     TEXT NOT NULL DEFAULT 'sent',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, channel, chat_id, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sent_messages_run
+    ON sent_messages(tenant_id, run_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_sent_messages_chat_time
+    ON sent_messages(tenant_id, channel, chat_id, created_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE channel_contacts DROP COLUMN IF EXISTS dm_chat_id;
@@ -0,0 +1,3 @@
+-- Direct-message chat ID per contact, for channels where it differs from the
+-- sender ID (Discord, Slack, Feishu, ...). Used by the message handoff action.
+ALTER TABLE channel_contacts ADD COLUMN IF NOT EXISTS dm_chat_id VARCHAR(255);
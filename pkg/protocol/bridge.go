@@ -0,0 +1,155 @@
+package protocol
+
+// Bridge protocol: lets an external process act as a channel instance of type
+// "bridge". The plugin dials the gateway at BridgePath with an API key
+// ("Authorization: Bearer <key>") and exchanges the usual req/res/event
+// frames over the WebSocket. Requests flow both ways: the plugin sends
+// bridge.* methods, the gateway sends channel.* methods mirroring the
+// Channel, StreamingChannel, ReactionChannel and GroupMemberProvider
+// interfaces. Binary fields ([]byte) are base64 in JSON.
+
+// BridgeProtocolVersion is the version a plugin must send in bridge.hello.
+const BridgeProtocolVersion = 1
+
+// BridgePath is the WebSocket endpoint plugins connect to.
+const BridgePath = "/channels/bridge/ws"
+
+// Plugin → gateway requests.
+const (
+	BridgeMethodHello   = "bridge.hello"   // first frame: attach to an instance
+	BridgeMethodInbound = "bridge.inbound" // a user message from the platform
+	BridgeMethodHealth  = "bridge.health"  // platform-side health change
+)
+
+// Gateway → plugin requests and events.
+const (
+	BridgeMethodSend          = "channel.send"           // Channel.Send
+	BridgeMethodStreamCreate  = "channel.stream.create"  // StreamingChannel.CreateStream
+	BridgeEventStreamUpdate   = "channel.stream.update"  // ChannelStream.Update (event, no reply)
+	BridgeMethodStreamStop    = "channel.stream.stop"    // ChannelStream.Stop
+	BridgeMethodReaction      = "channel.reaction.set"   // ReactionChannel.OnReactionEvent
+	BridgeMethodReactionClear = "channel.reaction.clear" // ReactionChannel.ClearReaction
+	BridgeMethodMembersList   = "channel.members.list"   // GroupMemberProvider.ListGroupMembers
+)
+
+// BridgeHello attaches the connection to a bridge channel instance. The API
+// key must belong to the instance's tenant (or be a system key).
+type BridgeHello struct {
+	Instance     string             `json:"instance"`           // channel instance name
+	Protocol     int                `json:"protocol"`           // BridgeProtocolVersion
+	Platform     string             `json:"platform,omitempty"` // e.g. "line", "mattermost"; shown in health
+	Capabilities BridgeCapabilities `json:"capabilities"`
+}
+
+// BridgeCapabilities declares which optional interfaces the plugin serves.
+// The gateway never sends channel.* requests for undeclared capabilities.
+type BridgeCapabilities struct {
+	Streaming    bool `json:"streaming,omitempty"`
+	Reactions    bool `json:"reactions,omitempty"`
+	GroupMembers bool `json:"group_members,omitempty"`
+}
+
+// BridgeHelloResult is the reply to bridge.hello.
+type BridgeHelloResult struct {
+	Protocol int    `json:"protocol"`
+	Instance string `json:"instance"`
+}
+
+// BridgeInbound is one message received on the platform. PeerKind is
+// "direct" or "group"; for groups, Mentioned reports whether the bot was
+// addressed (mention or reply), which drives require_mention gating.
+type BridgeInbound struct {
+	SenderID    string            `json:"sender_id"`
+	ChatID      string            `json:"chat_id"`
+	PeerKind    string            `json:"peer_kind"`
+	MessageID   string            `json:"message_id,omitempty"`
+	Content     string            `json:"content,omitempty"`
+	DisplayName string            `json:"display_name,omitempty"`
+	Username    string            `json:"username,omitempty"`
+	Mentioned   bool              `json:"mentioned,omitempty"`
+	Media       []BridgeMedia     `json:"media,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"` // passed through to the agent
+}
+
+// BridgeMedia is a file carried inline in a frame.
+type BridgeMedia struct {
+	Data        []byte `json:"data"`
+	ContentType string `json:"content_type,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	Caption     string `json:"caption,omitempty"`
+}
+
+// BridgeHealth reports the plugin's view of the platform connection.
+// State is "healthy", "degraded" or "failed"; FailureKind is one of
+// "auth", "config", "network", "unknown".
+type BridgeHealth struct {
+	State       string `json:"state"`
+	Summary     string `json:"summary,omitempty"`
+	Detail      string `json:"detail,omitempty"`
+	FailureKind string `json:"failure_kind,omitempty"`
+	Retryable   bool   `json:"retryable,omitempty"`
+}
+
+// BridgeSend delivers a reply. When StreamID is set, the reply finishes that
+// stream (the gateway finalized it): the plugin should replace the streamed
+// preview with Content instead of posting a new message.
+type BridgeSend struct {
+	ChatID   string            `json:"chat_id"`
+	Content  string            `json:"content,omitempty"`
+	Media    []BridgeMedia     `json:"media,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	StreamID string            `json:"stream_id,omitempty"`
+}
+
+// BridgeSendResult lists the platform IDs of the messages sent.
+type BridgeSendResult struct {
+	MessageIDs []string `json:"message_ids,omitempty"`
+}
+
+// BridgeStreamCreate opens a streaming preview for one agent run.
+type BridgeStreamCreate struct {
+	ChatID      string `json:"chat_id"`
+	FirstStream bool   `json:"first_stream,omitempty"`
+}
+
+// BridgeStreamUpdate carries the full text accumulated so far.
+type BridgeStreamUpdate struct {
+	StreamID string `json:"stream_id"`
+	Text     string `json:"text"`
+}
+
+// BridgeStreamStop flushes the stream; no more updates follow.
+type BridgeStreamStop struct {
+	StreamID string `json:"stream_id"`
+}
+
+// BridgeStreamResult is the reply to channel.stream.create; channel.stream.stop
+// replies with an empty payload.
+type BridgeStreamResult struct {
+	StreamID string `json:"stream_id"`
+}
+
+// BridgeReaction sets (or, with channel.reaction.clear, removes) the agent
+// status reaction on an inbound message. Status is one of "thinking",
+// "tool", "done", "error", "stall"; the plugin picks the emoji.
+type BridgeReaction struct {
+	ChatID    string `json:"chat_id"`
+	MessageID string `json:"message_id"`
+	Status    string `json:"status,omitempty"`
+}
+
+// BridgeMembersList asks for the members of a group chat.
+type BridgeMembersList struct {
+	ChatID string `json:"chat_id"`
+}
+
+// BridgeMembersResult is the reply to channel.members.list.
+type BridgeMembersResult struct {
+	Members []BridgeMember `json:"members"`
+}
+
+// BridgeMember is one group member.
+type BridgeMember struct {
+	MemberID string `json:"member_id"`
+	Name     string `json:"name"`
+}
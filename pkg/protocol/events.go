@@ -105,6 +105,10 @@ const (
 	EventWhatsAppQRCode = "whatsapp.qr.code"
 	EventWhatsAppQRDone = "whatsapp.qr.done"
 
+	// Signal device linking events (client-scoped, not broadcast).
+	EventSignalQRCode = "signal.qr.code"
+	EventSignalQRDone = "signal.qr.done"
+
 	// Tenant access revocation — forces affected user's UI to logout.
 	EventTenantAccessRevoked = "tenant.access.revoked"
 
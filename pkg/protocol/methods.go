@@ -117,6 +117,12 @@ const (
 	MethodChannelInstancesDelete = "channels.instances.delete"
 )
 
+// Sent messages (agent replies tracked for edit/retraction)
+const (
+	MethodMessagesSentList = "messages.sent.list"
+	MethodMessagesRetract  = "messages.retract"
+)
+
 // Agent links (inter-agent delegation)
 const (
 	MethodAgentsLinksList   = "agents.links.list"
@@ -197,6 +203,9 @@ const (
 
 	// WhatsApp
 	MethodWhatsAppQRStart = "whatsapp.qr.start"
+
+	// Signal
+	MethodSignalQRStart = "signal.qr.start"
 )
 
 // Workstations (Standard edition only — gated at router)
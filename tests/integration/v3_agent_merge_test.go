@@ -0,0 +1,92 @@
+//go:build integration
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store/pg"
+)
+
+func TestStoreAgent_MergeRepointsCollidingKGEntities(t *testing.T) {
+	db := testDB(t)
+	tenantID, agentID := seedTenantAgent(t, db)
+	ctx := tenantCtx(tenantID)
+
+	entity := func(userID, externalID string) uuid.UUID {
+		t.Helper()
+		id := uuid.New()
+		if _, err := db.Exec(`INSERT INTO kg_entities (id, agent_id, user_id, external_id, name, entity_type, tenant_id)
+			VALUES ($1, $2, $3, $4, $4, 'person', $5)`, id, agentID, userID, externalID, tenantID); err != nil {
+			t.Fatalf("seed entity: %v", err)
+		}
+		return id
+	}
+	relation := func(userID string, src, tgt uuid.UUID) {
+		t.Helper()
+		if _, err := db.Exec(`INSERT INTO kg_relations (agent_id, user_id, source_entity_id, relation_type, target_entity_id, tenant_id)
+			VALUES ($1, $2, $3, 'knows', $4, $5)`, agentID, userID, src, tgt, tenantID); err != nil {
+			t.Fatalf("seed relation: %v", err)
+		}
+	}
+
+	// The tenant user already has alice and bob and knows alice→bob. Two merged
+	// contacts each have their own alice; one repeats alice→bob, the other
+	// knows carol, whom only it has seen.
+	tuAlice := entity("user-1", "alice")
+	tuBob := entity("user-1", "bob")
+	relation("user-1", tuAlice, tuBob)
+	tgAlice := entity("tg:1", "alice")
+	tgBob := entity("tg:1", "bob")
+	relation("tg:1", tgAlice, tgBob)
+	dcAlice := entity("dc:1", "alice")
+	dcCarol := entity("dc:1", "carol")
+	relation("dc:1", dcAlice, dcCarol)
+
+	if err := pg.NewPGAgentStore(db).MigrateUserDataOnMerge(ctx, []string{"tg:1", "dc:1"}, "user-1"); err != nil {
+		t.Fatalf("MigrateUserDataOnMerge: %v", err)
+	}
+
+	var left int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM kg_entities WHERE agent_id = $1 AND user_id IN ('tg:1', 'dc:1')`,
+		agentID).Scan(&left); err != nil {
+		t.Fatal(err)
+	}
+	if left != 0 {
+		t.Errorf("old-user entities left = %d, want 0", left)
+	}
+
+	rows, err := db.Query(`SELECT r.user_id, r.source_entity_id, r.target_entity_id FROM kg_relations r
+		JOIN kg_entities t ON t.id = r.target_entity_id
+		WHERE r.agent_id = $1 ORDER BY t.external_id`, agentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	type edge struct {
+		userID   string
+		src, tgt uuid.UUID
+	}
+	var got []edge
+	for rows.Next() {
+		var e edge
+		if err := rows.Scan(&e.userID, &e.src, &e.tgt); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, e)
+	}
+	want := []edge{
+		{"user-1", tuAlice, tuBob},   // duplicate of the tenant user's edge collapses
+		{"user-1", tuAlice, dcCarol}, // re-pointed to the surviving alice, carol moved
+	}
+	if len(got) != len(want) {
+		t.Fatalf("relations = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("relation %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -1,11 +1,17 @@
 export const CHANNEL_TYPES = [
   { value: "bitrix24", label: "Bitrix24" },
+  { value: "bridge", label: "Bridge (external plugin)" },
   { value: "discord", label: "Discord" },
+  { value: "email", label: "Email" },
   { value: "facebook", label: "Facebook" },
   { value: "feishu", label: "Feishu / Lark" },
+  { value: "matrix", label: "Matrix" },
   { value: "pancake", label: "Pancake (pages.fm)" },
+  { value: "signal", label: "Signal" },
   { value: "slack", label: "Slack" },
+  { value: "teams", label: "Microsoft Teams" },
   { value: "telegram", label: "Telegram" },
+  { value: "twilio", label: "Twilio (SMS / Voice)" },
   { value: "whatsapp", label: "WhatsApp" },
   { value: "zalo_oa", label: "Zalo OA" },
   { value: "zalo_personal", label: "Zalo Personal" },
@@ -267,13 +267,15 @@ function normalizeModelFallbackForPayload(config: ModelFallbackConfig): ModelFal
     .map((candidate) => ({
       provider: candidate.provider?.trim() ?? "",
       model: candidate.model?.trim() ?? "",
+      ...(candidate.weight && candidate.weight > 0 ? { weight: candidate.weight } : {}),
     }))
     .filter((candidate) => candidate.provider && candidate.model);
   return {
     enabled: Boolean(config.enabled && candidates.length > 0),
-    strategy: "priority_order",
+    strategy: config.strategy ?? "priority_order",
     candidates,
     ...(config.max_attempts && config.max_attempts > 0 ? { max_attempts: config.max_attempts } : {}),
     cooldown_enabled: config.cooldown_enabled ?? true,
+    ...(config.primary_weight && config.primary_weight > 0 ? { primary_weight: config.primary_weight } : {}),
   };
 }
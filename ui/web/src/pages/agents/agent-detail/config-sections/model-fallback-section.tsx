@@ -19,11 +19,29 @@ import { Plus } from "lucide-react";
 import { Badge } from "@/components/ui/badge";
 import { Button } from "@/components/ui/button";
 import { Label } from "@/components/ui/label";
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from "@/components/ui/select";
 import { Switch } from "@/components/ui/switch";
-import type { ModelFallbackCandidate, ModelFallbackConfig } from "@/types/agent";
+import type {
+  ModelFallbackCandidate,
+  ModelFallbackConfig,
+  ModelFallbackStrategy,
+} from "@/types/agent";
 import type { ProviderData } from "@/types/provider";
 import { SortableFallbackRow } from "./model-fallback-row";
 
+const STRATEGIES: ModelFallbackStrategy[] = [
+  "priority_order",
+  "cheapest_fits_context",
+  "lowest_latency",
+  "weighted_round_robin",
+];
+
 interface ModelFallbackSectionProps {
   primaryProvider: string;
   primaryModel: string;
@@ -127,6 +145,32 @@ export function ModelFallbackSection({
           </div>
         )}
 
+        <div className="space-y-1">
+          <Label className="text-xs text-muted-foreground">
+            {t("configSections.modelFallback.strategy")}
+          </Label>
+          <Select
+            value={value.strategy ?? "priority_order"}
+            onValueChange={(strategy) =>
+              onChange({ ...value, strategy: strategy as ModelFallbackStrategy })
+            }
+          >
+            <SelectTrigger className="w-full sm:w-72">
+              <SelectValue />
+            </SelectTrigger>
+            <SelectContent>
+              {STRATEGIES.map((strategy) => (
+                <SelectItem key={strategy} value={strategy}>
+                  {t(`configSections.modelFallback.strategies.${strategy}`)}
+                </SelectItem>
+              ))}
+            </SelectContent>
+          </Select>
+          <p className="text-xs text-muted-foreground">
+            {t(`configSections.modelFallback.strategyDesc.${value.strategy ?? "priority_order"}`)}
+          </p>
+        </div>
+
         <div className="flex flex-col gap-3 sm:flex-row sm:items-center sm:justify-between">
           <div className="flex items-center gap-2">
             <Switch
@@ -147,7 +191,7 @@ export function ModelFallbackSection({
               onChange({
                 ...value,
                 enabled: true,
-                strategy: "priority_order",
+                strategy: value.strategy ?? "priority_order",
                 candidates: [...candidates, { provider: "", model: "" }],
               })
             }
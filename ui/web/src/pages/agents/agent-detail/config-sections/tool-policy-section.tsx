@@ -1,3 +1,4 @@
+import { useState } from "react";
 import { useTranslation } from "react-i18next";
 import {
   Select,
@@ -21,6 +22,7 @@ interface ToolPolicySectionProps {
 export function ToolPolicySection({ enabled, value, onToggle, onChange }: ToolPolicySectionProps) {
   const { t } = useTranslation("agents");
   const s = "configSections.toolPolicy";
+  const [httpHosts, setHttpHosts] = useState(() => (value.http_request?.allowed_hosts ?? []).join(", "));
 
   const updateWaitLimit = (field: "min_ms" | "max_ms", raw: string) => {
     const nextWait = { ...(value.wait ?? {}) };
@@ -38,6 +40,15 @@ export function ToolPolicySection({ enabled, value, onToggle, onChange }: ToolPo
     });
   };
 
+  const updateHttpHosts = (raw: string) => {
+    setHttpHosts(raw);
+    const hosts = raw.split(/[\s,]+/).map((h) => h.trim().toLowerCase()).filter(Boolean);
+    onChange({
+      ...value,
+      http_request: hosts.length > 0 ? { allowed_hosts: hosts } : undefined,
+    });
+  };
+
   return (
     <ConfigSection
       title={t(`${s}.title`)}
@@ -96,6 +107,16 @@ export function ToolPolicySection({ enabled, value, onToggle, onChange }: ToolPo
         </div>
         <p className="text-xs text-muted-foreground">{t(`${s}.waitLimitsHint`)}</p>
       </div>
+      <div className="space-y-2">
+        <InfoLabel tip="Hosts the http_request tool may call for this agent, including redirect targets. Use *.example.com for subdomains. Leave empty to allow any public host.">{t(`${s}.httpAllowedHosts`)}</InfoLabel>
+        <Input
+          value={httpHosts}
+          onChange={(e) => updateHttpHosts(e.target.value)}
+          placeholder="api.github.com, *.example.com"
+          className="font-mono text-base md:text-sm"
+        />
+        <p className="text-xs text-muted-foreground">{t(`${s}.httpAllowedHostsHint`)}</p>
+      </div>
       <div className="space-y-2">
         <InfoLabel tip="Explicit allowlist. Only these tools will be available (overrides profile). Leave empty to use profile defaults.">{t(`${s}.allow`)}</InfoLabel>
         <ToolNameSelect
@@ -93,12 +93,29 @@ export const credentialsSchema: Record<string, FieldDef[]> = {
     { key: "encrypt_key", label: "Encrypt Key", type: "password", help: "For webhook event decryption", showWhen: { key: "connection_mode", value: "webhook" } },
     { key: "verification_token", label: "Verification Token", type: "password", help: "For webhook event verification", showWhen: { key: "connection_mode", value: "webhook" } },
   ],
+  matrix: [
+    { key: "access_token", label: "Access Token", type: "password", required: true, placeholder: "syt_...", help: "Access token of the bot's Matrix account (e.g. from an Element session or the login API)" },
+  ],
+  email: [
+    { key: "password", label: "Password", type: "password", required: true, help: "Mailbox password or app password (IMAP, and SMTP unless set below)" },
+    { key: "smtp_password", label: "SMTP Password", type: "password", help: "Only if the SMTP login differs from the mailbox login" },
+  ],
+  teams: [
+    { key: "app_password", label: "Client Secret", type: "password", required: true, help: "Client secret of the Azure bot's app registration (Certificates & secrets)" },
+  ],
   zalo_oa: [
     { key: "token", label: "OA Access Token", type: "password", required: true },
     { key: "webhook_secret", label: "Webhook Secret", type: "password" },
   ],
   zalo_personal: [],
   whatsapp: [],
+  signal: [],
+  // Bridge plugins authenticate with an API key, not instance credentials.
+  bridge: [],
+  twilio: [
+    { key: "account_sid", label: "Account SID", type: "text", required: true, placeholder: "AC..." },
+    { key: "auth_token", label: "Auth Token", type: "password", required: true, help: "Also used to verify webhook signatures" },
+  ],
   facebook: [
     { key: "page_access_token", label: "Page Access Token", type: "password", required: true, help: "From Facebook Developer Console → Your App → Messenger → Page Access Token" },
     { key: "app_secret", label: "App Secret", type: "password", required: true, help: "From Facebook Developer Console → Your App → Settings → Basic" },
@@ -198,6 +215,57 @@ export const configSchema: Record<string, FieldDef[]> = {
     { key: "group_allow_from", label: "Group Allowed Users", type: "tags", help: "Separate allowlist for group senders" },
     ...chatBehaviorOverrideFields,
   ],
+  matrix: [
+    { key: "homeserver", label: "Homeserver URL", type: "text", required: true, placeholder: "https://matrix.example.org", help: "Client-server API base URL of the bot's homeserver" },
+    { key: "dm_policy", label: "DM Policy", type: "select", options: dmPolicyOptions, defaultValue: "pairing", help: "Two-member rooms are treated as direct messages" },
+    { key: "group_policy", label: "Group Policy", type: "select", options: groupPolicyOptions, defaultValue: "pairing" },
+    { key: "require_mention", label: "Require @mention in rooms", type: "boolean", defaultValue: true },
+    { key: "history_limit", label: "Group History Limit", type: "number", defaultValue: 50, help: "Max pending room messages for context (0 = disabled)" },
+    { key: "dm_stream", label: "DM Streaming", type: "boolean", defaultValue: true, help: "Progressively edit the reply as the LLM generates (DMs)" },
+    { key: "group_stream", label: "Group Streaming", type: "boolean", defaultValue: false, help: "Progressively edit the reply as the LLM generates (rooms)" },
+    { key: "thread_replies", label: "Reply in Threads", type: "boolean", defaultValue: false, help: "Start a thread from the triggering message in rooms. Messages already in a thread are always answered in that thread." },
+    { key: "auto_join", label: "Auto-join Invites", type: "boolean", defaultValue: true, help: "Accept room invites automatically (room policy still applies)" },
+    { key: "reaction_level", label: "Reaction Level", type: "select", options: [{ value: "off", label: "Off" }, { value: "minimal", label: "Minimal (thinking + done)" }, { value: "full", label: "Full (all status emoji)" }], defaultValue: "off" },
+    { key: "media_max_mb", label: "Max Media Size (MB)", type: "number", defaultValue: 20 },
+    { key: "allow_from", label: "Allowed Users", type: "tags", help: "Matrix user IDs (@user:server)" },
+    ...chatBehaviorOverrideFields,
+  ],
+  email: [
+    { key: "username", label: "Username", type: "text", required: true, placeholder: "support@example.com", help: "Mailbox login, usually the address" },
+    { key: "from_address", label: "From Address", type: "text", help: "Defaults to the username" },
+    { key: "from_name", label: "From Name", type: "text", placeholder: "Support" },
+    { key: "imap_host", label: "IMAP Host", type: "text", required: true, placeholder: "imap.example.com" },
+    { key: "imap_port", label: "IMAP Port", type: "number", defaultValue: 993 },
+    { key: "imap_security", label: "IMAP Security", type: "select", options: [{ value: "tls", label: "TLS" }, { value: "starttls", label: "STARTTLS" }, { value: "none", label: "None" }], defaultValue: "tls" },
+    { key: "mailbox", label: "Mailbox", type: "text", defaultValue: "INBOX" },
+    { key: "smtp_host", label: "SMTP Host", type: "text", required: true, placeholder: "smtp.example.com" },
+    { key: "smtp_port", label: "SMTP Port", type: "number", defaultValue: 587 },
+    { key: "smtp_security", label: "SMTP Security", type: "select", options: [{ value: "starttls", label: "STARTTLS" }, { value: "tls", label: "TLS" }, { value: "none", label: "None" }], defaultValue: "starttls" },
+    { key: "smtp_username", label: "SMTP Username", type: "text", help: "Defaults to the username" },
+    { key: "poll_interval_sec", label: "Poll Interval (seconds)", type: "number", defaultValue: 60, help: "Used when the server lacks IMAP IDLE or IDLE is disabled" },
+    { key: "disable_idle", label: "Disable IDLE", type: "boolean", defaultValue: false },
+    { key: "default_subject", label: "Default Subject", type: "text", help: "Subject for messages that do not reply to an email" },
+    { key: "dm_policy", label: "Sender Policy", type: "select", options: dmPolicyOptions, defaultValue: "pairing" },
+    { key: "media_max_mb", label: "Max Attachment Size (MB)", type: "number", defaultValue: 20 },
+    { key: "allow_from", label: "Allowed Senders", type: "tags", help: "Email addresses or @domain" },
+    { key: "trusted_authserv_ids", label: "Trusted Authentication Servers", type: "tags", help: "authserv-id of your receiving mail server (e.g. mx.google.com). Mail must pass DMARC or aligned DKIM there" },
+    { key: "allow_unauthenticated", label: "Accept Unverified Senders", type: "boolean", defaultValue: false, help: "Insecure: trust the From header without DMARC/DKIM, so anyone can pose as an allowed sender" },
+    ...chatBehaviorOverrideFields,
+  ],
+  teams: [
+    { key: "app_id", label: "Microsoft App ID", type: "text", required: true, placeholder: "00000000-0000-0000-0000-000000000000", help: "Set the bot's messaging endpoint to https://<gateway>/channels/teams/messages" },
+    { key: "app_tenant_id", label: "App Tenant ID", type: "text", help: "Only for single-tenant bots; leave empty for multi-tenant" },
+    { key: "allowed_tenants", label: "Allowed Tenants", type: "tags", help: "Microsoft Entra tenant IDs allowed to use the bot (empty = any)" },
+    { key: "dm_policy", label: "DM Policy", type: "select", options: dmPolicyOptions, defaultValue: "pairing", help: "Applies to personal chats with the bot" },
+    { key: "group_policy", label: "Group Policy", type: "select", options: groupPolicyOptions, defaultValue: "pairing", help: "Applies to group chats and team channels" },
+    { key: "require_mention", label: "Require @mention in groups", type: "boolean", defaultValue: true },
+    { key: "history_limit", label: "Group History Limit", type: "number", defaultValue: 50, help: "Max pending group messages for context (0 = disabled)" },
+    { key: "dm_stream", label: "DM Streaming", type: "boolean", defaultValue: true, help: "Stream replies in personal chats (native Teams streaming)" },
+    { key: "group_stream", label: "Group Streaming", type: "boolean", defaultValue: false, help: "Progressively update the reply in group chats and channels" },
+    { key: "media_max_mb", label: "Max Media Size (MB)", type: "number", defaultValue: 20 },
+    { key: "allow_from", label: "Allowed Users", type: "tags", help: "Microsoft Entra object IDs" },
+    ...chatBehaviorOverrideFields,
+  ],
   zalo_oa: [
     { key: "dm_policy", label: "DM Policy", type: "select", options: dmPolicyOptions, defaultValue: "pairing" },
     { key: "webhook_url", label: "Webhook URL", type: "text", placeholder: "https://..." },
@@ -219,6 +287,46 @@ export const configSchema: Record<string, FieldDef[]> = {
     { key: "allow_from", label: "Allowed Users", type: "tags", help: "WhatsApp user IDs" },
     ...chatBehaviorOverrideFields,
   ],
+  signal: [
+    { key: "rpc_address", label: "signal-cli Address", type: "text", placeholder: "127.0.0.1:7583", help: "JSON-RPC socket of `signal-cli daemon`: host:port for --tcp or a unix socket path" },
+    { key: "account", label: "Account Number", type: "text", placeholder: "+15551234567", help: "Filled in by QR linking. Set it manually for an account already registered in signal-cli." },
+    { key: "dm_policy", label: "DM Policy", type: "select", options: dmPolicyOptions, defaultValue: "pairing" },
+    { key: "group_policy", label: "Group Policy", type: "select", options: groupPolicyOptions, defaultValue: "pairing" },
+    { key: "require_mention", label: "Require @mention in groups", type: "boolean", defaultValue: true, help: "Replies to the bot's messages also count as mentions" },
+    { key: "history_limit", label: "Group History Limit", type: "number", defaultValue: 50, help: "Max pending group messages for context (0 = disabled)" },
+    { key: "typing", label: "Typing Indicator", type: "boolean", defaultValue: true, help: "Show typing while the agent works" },
+    { key: "reaction_level", label: "Reaction Level", type: "select", options: [{ value: "off", label: "Off" }, { value: "minimal", label: "Minimal (thinking + done)" }, { value: "full", label: "Full (all status emoji)" }], defaultValue: "off" },
+    { key: "media_max_mb", label: "Max Media Size (MB)", type: "number", defaultValue: 20 },
+    { key: "allow_from", label: "Allowed Users", type: "tags", help: "Phone numbers (+15551234567) or Signal account UUIDs" },
+    ...chatBehaviorOverrideFields,
+  ],
+  bridge: [
+    { key: "platform", label: "Platform", type: "text", placeholder: "line", help: "Informational: the platform the plugin serves. Plugins connect to /channels/bridge/ws with an API key and this instance's name." },
+    { key: "dm_policy", label: "DM Policy", type: "select", options: dmPolicyOptions, defaultValue: "pairing" },
+    { key: "group_policy", label: "Group Policy", type: "select", options: groupPolicyOptions, defaultValue: "pairing" },
+    { key: "require_mention", label: "Require @mention in groups", type: "boolean", defaultValue: true, help: "The plugin reports mentions with each message" },
+    { key: "history_limit", label: "Group History Limit", type: "number", defaultValue: 50, help: "Max pending group messages for context (0 = disabled)" },
+    { key: "dm_stream", label: "DM Streaming", type: "boolean", defaultValue: true, help: "Only when the plugin declares streaming support" },
+    { key: "group_stream", label: "Group Streaming", type: "boolean", defaultValue: false },
+    { key: "reaction_level", label: "Reaction Level", type: "select", options: [{ value: "off", label: "Off" }, { value: "minimal", label: "Minimal (thinking + done)" }, { value: "full", label: "Full (all status emoji)" }], defaultValue: "off" },
+    { key: "media_max_mb", label: "Max Media Size (MB)", type: "number", defaultValue: 20 },
+    { key: "allow_from", label: "Allowed Users", type: "tags", help: "User IDs as reported by the plugin" },
+    ...chatBehaviorOverrideFields,
+  ],
+  twilio: [
+    { key: "phone_number", label: "Phone Number", type: "text", required: true, placeholder: "+14155550100", help: "Point the number's messaging webhook to https://<gateway>/channels/twilio/sms and its voice webhook to .../voice (HTTP POST)" },
+    { key: "public_url", label: "Public Gateway URL", type: "text", placeholder: "https://gateway.example.com", help: "Origin Twilio uses to reach the gateway. Needed for MMS attachments and when a proxy rewrites the Host header." },
+    { key: "dm_policy", label: "Sender Policy", type: "select", options: dmPolicyOptions, defaultValue: "pairing" },
+    { key: "max_segments", label: "Max Segments per SMS", type: "number", defaultValue: 10, help: "Longer replies are split into several messages (160 GSM-7 / 70 Unicode characters per segment)" },
+    { key: "media_max_mb", label: "Max Media Size (MB)", type: "number", defaultValue: 5 },
+    { key: "voice_enabled", label: "Answer Calls", type: "boolean", defaultValue: false, help: "Transcribes callers with the configured STT provider and speaks replies with TTS" },
+    { key: "voice_greeting", label: "Call Greeting", type: "text", placeholder: "Hello! How can I help you?", showWhen: { key: "voice_enabled", value: "true" } },
+    { key: "voice_language", label: "Call Language", type: "text", placeholder: "en-US", showWhen: { key: "voice_enabled", value: "true" } },
+    { key: "tts_voice", label: "TTS Voice", type: "text", help: "Provider voice ID for spoken replies (empty = provider default)", showWhen: { key: "voice_enabled", value: "true" } },
+    { key: "api_base", label: "API Base URL", type: "text", placeholder: "https://api.twilio.com", advanced: true },
+    { key: "allow_from", label: "Allowed Numbers", type: "tags", help: "Phone numbers in E.164 format" },
+    ...chatBehaviorOverrideFields,
+  ],
   facebook: [
     { key: "page_id", label: "Page ID", type: "text", required: true, help: "Facebook Page numeric ID" },
     { key: "features.comment_reply", label: "Comment Auto-Reply", type: "boolean", defaultValue: false },
@@ -364,4 +472,9 @@ export const wizardConfig: Partial<Record<string, WizardConfig>> = {
     createLabel: "wizard.whatsapp.createLabel",
     formBanner: "wizard.whatsapp.formBanner",
   },
+  signal: {
+    steps: ["auth"],
+    createLabel: "wizard.signal.createLabel",
+    formBanner: "wizard.signal.formBanner",
+  },
 };
@@ -50,12 +50,15 @@ import { ZaloAuthStep, ZaloConfigStep, ZaloEditConfig } from "./zalo/zalo-wizard
 import { ZaloPersonalQRDialog } from "./zalo/zalo-personal-qr-dialog";
 import { WhatsAppAuthStep } from "./whatsapp/whatsapp-wizard-steps";
 import { WhatsAppReauthDialog } from "./whatsapp/whatsapp-reauth-dialog";
+import { SignalAuthStep } from "./signal/signal-wizard-steps";
+import { SignalReauthDialog } from "./signal/signal-reauth-dialog";
 
 // --- Component registries ---
 
 export const wizardAuthSteps: Record<string, ComponentType<WizardAuthStepProps>> = {
   zalo_personal: ZaloAuthStep,
   whatsapp: WhatsAppAuthStep,
+  signal: SignalAuthStep,
 };
 
 export const wizardConfigSteps: Record<string, ComponentType<WizardConfigStepProps>> = {
@@ -70,6 +73,7 @@ export const wizardEditConfigs: Record<string, ComponentType<WizardEditConfigPro
 export const reauthDialogs: Record<string, ComponentType<ReauthDialogProps>> = {
   zalo_personal: ZaloPersonalQRDialog,
   whatsapp: WhatsAppReauthDialog,
+  signal: SignalReauthDialog,
 };
 
 /** Set of channel types that support re-authentication from the table */
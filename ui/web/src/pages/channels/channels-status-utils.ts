@@ -12,6 +12,12 @@ export const channelTypeLabels: Record<string, string> = {
   discord: "Discord",
   slack: "Slack",
   feishu: "Feishu / Lark",
+  matrix: "Matrix",
+  email: "Email",
+  teams: "Microsoft Teams",
+  signal: "Signal",
+  bridge: "Bridge",
+  twilio: "Twilio",
   zalo_oa: "Zalo OA",
   zalo_personal: "Zalo Personal",
   whatsapp: "WhatsApp",
@@ -0,0 +1,73 @@
+// Signal wizard step components for the channel create wizard.
+// The QR code encodes signal-cli's device link URI, delivered via WS events.
+// Registered in channel-wizard-registry.tsx.
+
+import { useEffect } from "react";
+import { useTranslation } from "react-i18next";
+import { Button } from "@/components/ui/button";
+import { DialogFooter } from "@/components/ui/dialog";
+import { useSignalLink } from "./use-signal-link";
+import type { WizardAuthStepProps } from "../channel-wizard-registry";
+
+/** Device linking step for Signal — displayed in create wizard after instance creation. */
+export function SignalAuthStep({ instanceId, onComplete, onSkip }: WizardAuthStepProps) {
+  const { t } = useTranslation("channels");
+  const { qrPng, status, errorMsg, loading, start, retry, reset } = useSignalLink(instanceId);
+
+  // Auto-start QR on mount
+  useEffect(() => {
+    start();
+    return () => reset();
+  }, [start, reset]);
+
+  // Notify the parent once signal-cli reports the linked account
+  // ("connected" when the account was entered in the form and is already linked)
+  useEffect(() => {
+    if (status === "done" || status === "connected") onComplete();
+  }, [status, onComplete]);
+
+  return (
+    <>
+      <div className="flex flex-col items-center gap-4 py-4 min-h-0">
+        {status === "done" && (
+          <p className="text-sm text-green-600 font-medium">
+            {t("signal.loginSuccessLoading")}
+          </p>
+        )}
+        {status === "error" && (
+          <p className="text-sm text-destructive">{errorMsg}</p>
+        )}
+        {status === "waiting" && !qrPng && (
+          <p className="text-sm text-muted-foreground">
+            {t("signal.waitingForQr")}
+          </p>
+        )}
+        {status === "waiting" && qrPng && (
+          <>
+            <img
+              src={`data:image/png;base64,${qrPng}`}
+              alt="Signal device link QR code"
+              className="w-52 h-52 border rounded"
+            />
+            <p className="text-xs text-muted-foreground text-center">
+              {t("signal.scanHint")}
+            </p>
+          </>
+        )}
+        {status === "idle" && (
+          <p className="text-sm text-muted-foreground">{t("signal.initializing")}</p>
+        )}
+      </div>
+      <DialogFooter>
+        <Button variant="outline" onClick={onSkip} disabled={loading}>
+          {t("signal.skip")}
+        </Button>
+        {status === "error" && (
+          <Button onClick={() => retry()} disabled={loading}>
+            {t("signal.retry")}
+          </Button>
+        )}
+      </DialogFooter>
+    </>
+  );
+}
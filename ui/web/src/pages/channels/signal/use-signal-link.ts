@@ -0,0 +1,71 @@
+import { useState, useCallback } from "react";
+import { useWsCall } from "@/hooks/use-ws-call";
+import { useWsEvent } from "@/hooks/use-ws-event";
+
+export type LinkStatus = "idle" | "waiting" | "done" | "connected" | "error";
+
+/** Links signal-cli as a device of the user's Signal account via signal.qr.start. */
+export function useSignalLink(instanceId: string | null) {
+  const [qrPng, setQrPng] = useState<string | null>(null);
+  const [account, setAccount] = useState("");
+  const [status, setStatus] = useState<LinkStatus>("idle");
+  const [errorMsg, setErrorMsg] = useState("");
+  const { call: startLink, loading } = useWsCall("signal.qr.start");
+
+  const start = useCallback(async (forceReauth = false) => {
+    if (!instanceId) return;
+    setStatus("waiting");
+    setQrPng(null);
+    setErrorMsg("");
+    try {
+      await startLink({ instance_id: instanceId, force_reauth: forceReauth });
+    } catch (err) {
+      setStatus("error");
+      setErrorMsg(err instanceof Error ? err.message : "Failed to start device linking");
+    }
+  }, [startLink, instanceId]);
+
+  /** Link a new device even though the configured account is already linked. */
+  const triggerReauth = useCallback(() => start(true), [start]);
+
+  const reset = useCallback(() => {
+    setStatus("idle");
+    setQrPng(null);
+    setErrorMsg("");
+  }, []);
+
+  useWsEvent(
+    "signal.qr.code",
+    useCallback(
+      (payload: unknown) => {
+        const p = payload as { instance_id: string; png_b64: string };
+        if (p.instance_id !== instanceId) return;
+        setQrPng(p.png_b64);
+        setStatus("waiting");
+      },
+      [instanceId],
+    ),
+  );
+
+  useWsEvent(
+    "signal.qr.done",
+    useCallback(
+      (payload: unknown) => {
+        const p = payload as { instance_id: string; success: boolean; already_connected?: boolean; account?: string; error?: string };
+        if (p.instance_id !== instanceId) return;
+        if (p.success) {
+          setAccount(p.account ?? "");
+          setStatus(p.already_connected ? "connected" : "done");
+        } else {
+          setStatus("error");
+          setErrorMsg(p.error ?? "Device linking failed");
+        }
+      },
+      [instanceId],
+    ),
+  );
+
+  return {
+    qrPng, account, status, errorMsg, loading, start, reset, retry: start, triggerReauth,
+  };
+}
@@ -31,6 +31,7 @@ export function useBootstrapStatus() {
       provider.api_key === "***"
       || provider.provider_type === "claude_cli"
       || provider.provider_type === "ollama"
+      || provider.provider_type === "llamacpp"
       || (provider.provider_type === "chatgpt_oauth" && readyOAuthProviders.has(provider.name))
     ));
     const hasAgent = agents.length > 0;
@@ -12,6 +12,9 @@ export interface ToolPolicyConfig {
     min_ms?: number;
     max_ms?: number;
   };
+  http_request?: {
+    allowed_hosts?: string[];
+  };
   toolCallPrefix?: string; // prefix to strip from model's tool call names
 }
 
@@ -145,17 +148,25 @@ export interface ChatGPTOAuthRoutingConfig {
   extra_provider_names?: string[];
 }
 
+export type ModelFallbackStrategy =
+  | "priority_order"
+  | "cheapest_fits_context"
+  | "lowest_latency"
+  | "weighted_round_robin";
+
 export interface ModelFallbackCandidate {
   provider?: string;
   model?: string;
+  weight?: number;
 }
 
 export interface ModelFallbackConfig {
   enabled?: boolean;
-  strategy?: "priority_order";
+  strategy?: ModelFallbackStrategy;
   candidates?: ModelFallbackCandidate[];
   max_attempts?: number;
   cooldown_enabled?: boolean;
+  primary_weight?: number;
 }
 
 export interface KgDedupConfig {